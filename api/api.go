@@ -12,11 +12,13 @@ import (
 
 	"google.golang.org/grpc/credentials/insecure"
 
+	"github.com/featureform/helpers/demo"
 	"github.com/featureform/metadata"
 	pb "github.com/featureform/metadata/proto"
 	srv "github.com/featureform/proto"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	grpcmeta "google.golang.org/grpc/metadata"
 )
 
 type ApiServer struct {
@@ -63,6 +65,26 @@ func (serv *MetadataServer) CreateUser(ctx context.Context, user *pb.User) (*pb.
 	return serv.meta.CreateUser(ctx, user)
 }
 
+func (serv *MetadataServer) LoadDemo(ctx context.Context, req *pb.DemoRequest) (*pb.DemoResult, error) {
+	serv.Logger.Infow("Loading Demo", "offline", req.OfflineProvider, "online", req.OnlineProvider, "variant", req.Variant)
+	result, err := demo.Load(ctx, serv.client, demo.Config{
+		OfflineProvider: req.OfflineProvider,
+		OnlineProvider:  req.OnlineProvider,
+		Variant:         req.Variant,
+		Rows:            int(req.Rows),
+		Seed:            req.Seed,
+		Schedule:        req.Schedule,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]*pb.ResourceID, len(result.Resources))
+	for i, id := range result.Resources {
+		resources[i] = &pb.ResourceID{Resource: id.Proto(), ResourceType: pb.ResourceType(id.Type)}
+	}
+	return &pb.DemoResult{Table: result.Table, Resources: resources}, nil
+}
+
 func (serv *MetadataServer) GetUsers(stream pb.Api_GetUsersServer) error {
 	for {
 		name, err := stream.Recv()
@@ -450,6 +472,106 @@ func (serv *MetadataServer) RequestScheduleChange(ctx context.Context, req *pb.S
 	return serv.meta.RequestScheduleChange(ctx, req)
 }
 
+func (serv *MetadataServer) UpdateProviderConfig(ctx context.Context, req *pb.ProviderConfigUpdate) (*pb.Empty, error) {
+	serv.Logger.Infow("Updating Provider Config", "name", req.Name, "requester", req.Requester)
+	return serv.meta.UpdateProviderConfig(ctx, req)
+}
+
+func (serv *MetadataServer) BulkRerunFailed(ctx context.Context, req *pb.BulkRerunRequest) (*pb.BulkOperationResult, error) {
+	serv.Logger.Infow("Rerunning Failed Resources", "filter", req.Filter, "dry run", req.DryRun, "requester", req.Requester)
+	return serv.meta.BulkRerunFailed(ctx, req)
+}
+
+func (serv *MetadataServer) BulkPauseSchedules(ctx context.Context, req *pb.BulkPauseSchedulesRequest) (*pb.BulkOperationResult, error) {
+	serv.Logger.Infow("Pausing Provider Schedules", "provider", req.Provider, "dry run", req.DryRun, "requester", req.Requester)
+	return serv.meta.BulkPauseSchedules(ctx, req)
+}
+
+func (serv *MetadataServer) BulkResumeSchedules(ctx context.Context, req *pb.BulkResumeSchedulesRequest) (*pb.BulkOperationResult, error) {
+	serv.Logger.Infow("Resuming Provider Schedules", "provider", req.Provider, "dry run", req.DryRun, "requester", req.Requester)
+	return serv.meta.BulkResumeSchedules(ctx, req)
+}
+
+func (serv *MetadataServer) BulkSetStatus(ctx context.Context, req *pb.BulkSetStatusRequest) (*pb.BulkOperationResult, error) {
+	serv.Logger.Infow("Setting Resource Statuses", "resources", len(req.Resources), "status", req.Status, "dry run", req.DryRun, "requester", req.Requester)
+	return serv.meta.BulkSetStatus(ctx, req)
+}
+
+func (serv *MetadataServer) CancelJob(ctx context.Context, req *pb.CancelJobRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Cancelling Job", "resource", req.Resource, "requester", req.Requester)
+	return serv.meta.CancelJob(ctx, req)
+}
+
+func (serv *MetadataServer) PauseSchedule(ctx context.Context, req *pb.PauseScheduleRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Pausing Schedule", "resource", req.Resource, "reason", req.Reason, "requester", req.Requester)
+	return serv.meta.PauseSchedule(ctx, req)
+}
+
+func (serv *MetadataServer) ResumeSchedule(ctx context.Context, req *pb.ResumeScheduleRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Resuming Schedule", "resource", req.Resource, "requester", req.Requester)
+	return serv.meta.ResumeSchedule(ctx, req)
+}
+
+func (serv *MetadataServer) TriggerRun(ctx context.Context, req *pb.TriggerRunRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Triggering Run", "resource", req.Resource, "requester", req.Requester)
+	return serv.meta.TriggerRun(ctx, req)
+}
+
+func (serv *MetadataServer) GetJobRuns(ctx context.Context, req *pb.ResourceID) (*pb.JobRunList, error) {
+	serv.Logger.Infow("Getting Job Runs", "resource", req.Resource)
+	return serv.meta.GetJobRuns(ctx, req)
+}
+
+func (serv *MetadataServer) RegisterNotification(ctx context.Context, req *pb.RegisterNotificationRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Registering Notification", "name", req.GetNotification().GetName(), "kind", req.GetNotification().GetKind(), "requester", req.Requester)
+	return serv.meta.RegisterNotification(ctx, req)
+}
+
+func (serv *MetadataServer) DeleteNotification(ctx context.Context, req *pb.DeleteNotificationRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Deleting Notification", "name", req.Name, "requester", req.Requester)
+	return serv.meta.DeleteNotification(ctx, req)
+}
+
+func (serv *MetadataServer) ListNotifications(ctx context.Context, req *pb.Empty) (*pb.NotificationList, error) {
+	serv.Logger.Infow("Listing Notifications")
+	return serv.meta.ListNotifications(ctx, req)
+}
+
+func (serv *MetadataServer) ListDeadLetters(ctx context.Context, req *pb.Empty) (*pb.DeadLetterList, error) {
+	serv.Logger.Infow("Listing Dead Letters")
+	return serv.meta.ListDeadLetters(ctx, req)
+}
+
+func (serv *MetadataServer) ReplayDeadLetter(ctx context.Context, req *pb.ReplayDeadLetterRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Replaying Dead Letter", "resource", req.Resource, "requester", req.Requester)
+	return serv.meta.ReplayDeadLetter(ctx, req)
+}
+
+func (serv *MetadataServer) MigrateOnlineStore(ctx context.Context, req *pb.OnlineMigrationRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Migrating Online Store", "features", req.Features, "destination", req.Destination, "requester", req.Requester)
+	return serv.meta.MigrateOnlineStore(ctx, req)
+}
+
+func (serv *MetadataServer) PlanJobs(ctx context.Context, req *pb.PlanRequest) (*pb.JobPlanList, error) {
+	serv.Logger.Infow("Planning Jobs", "sources", len(req.Sources), "features", len(req.Features), "labels", len(req.Labels), "training_sets", len(req.TrainingSets))
+	return serv.meta.PlanJobs(ctx, req)
+}
+
+func (serv *MetadataServer) DeleteOrphanedTables(ctx context.Context, req *pb.OrphanDeletionRequest) (*pb.Empty, error) {
+	serv.Logger.Infow("Deleting Orphaned Tables", "provider", req.Provider, "tables", len(req.Tables), "requester", req.Requester)
+	return serv.meta.DeleteOrphanedTables(ctx, req)
+}
+
+func (serv *MetadataServer) VerifyTrainingSetCutoff(ctx context.Context, req *pb.TrainingSetCutoffRequest) (*pb.TrainingSetCutoffResult, error) {
+	serv.Logger.Infow("Verifying Training Set Cutoff", "training_set", req.TrainingSet, "cutoff", req.Cutoff)
+	return serv.meta.VerifyTrainingSetCutoff(ctx, req)
+}
+
+func (serv *MetadataServer) UpdateFeatureVariantProvider(ctx context.Context, req *pb.FeatureProviderUpdate) (*pb.Empty, error) {
+	serv.Logger.Infow("Updating Feature Variant Provider", "feature", req.Feature, "provider", req.Provider, "requester", req.Requester)
+	return serv.meta.UpdateFeatureVariantProvider(ctx, req)
+}
+
 func (serv *MetadataServer) CreateFeatureVariant(ctx context.Context, feature *pb.FeatureVariant) (*pb.Empty, error) {
 	serv.Logger.Infow("Creating Feature Variant", "name", feature.Name, "variant", feature.Variant)
 	return serv.meta.CreateFeatureVariant(ctx, feature)
@@ -484,14 +606,48 @@ func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, train
 	return serv.meta.CreateTrainingSetVariant(ctx, train)
 }
 
+// credentialHeaders authenticate callers to serving, which checks the scopes
+// of the features they request.
+var credentialHeaders = []string{"authorization", "x-api-key"}
+
+// forwardCredentials adds the credential headers of the incoming request to
+// the outgoing context built on ctx.
+func forwardCredentials(ctx, incoming context.Context) context.Context {
+	md, ok := grpcmeta.FromIncomingContext(incoming)
+	if !ok {
+		return ctx
+	}
+	for _, header := range credentialHeaders {
+		for _, value := range md.Get(header) {
+			ctx = grpcmeta.AppendToOutgoingContext(ctx, header, value)
+		}
+	}
+	return ctx
+}
+
 func (serv *OnlineServer) FeatureServe(ctx context.Context, req *srv.FeatureServeRequest) (*srv.FeatureRow, error) {
 	serv.Logger.Infow("Serving Features", "request", req.String())
-	return serv.client.FeatureServe(ctx, req)
+	return serv.client.FeatureServe(forwardCredentials(ctx, ctx), req)
+}
+
+func (serv *OnlineServer) LabelServe(ctx context.Context, req *srv.LabelServeRequest) (*srv.LabelRow, error) {
+	serv.Logger.Infow("Serving Labels", "request", req.String())
+	return serv.client.LabelServe(forwardCredentials(ctx, ctx), req)
+}
+
+func (serv *OnlineServer) MultiEntityFeatureServe(ctx context.Context, req *srv.MultiEntityFeatureServeRequest) (*srv.MultiEntityFeatureRows, error) {
+	serv.Logger.Infow("Serving Features for Multiple Entities", "features", len(req.GetFeatures()), "rows", len(req.GetRows()))
+	return serv.client.MultiEntityFeatureServe(forwardCredentials(ctx, ctx), req)
+}
+
+func (serv *OnlineServer) ValueLineage(ctx context.Context, req *srv.ValueLineageRequest) (*srv.ValueLineage, error) {
+	serv.Logger.Infow("Reporting Value Lineage", "feature", req.GetFeature().String(), "entity", req.GetEntity().GetName())
+	return serv.client.ValueLineage(forwardCredentials(ctx, ctx), req)
 }
 
 func (serv *OnlineServer) TrainingData(req *srv.TrainingDataRequest, stream srv.Feature_TrainingDataServer) error {
 	serv.Logger.Infow("Serving Training Data", "id", req.Id.String())
-	client, err := serv.client.TrainingData(context.Background(), req)
+	client, err := serv.client.TrainingData(forwardCredentials(context.Background(), stream.Context()), req)
 	if err != nil {
 		return fmt.Errorf("training data: %w", err)
 	}
@@ -223,7 +223,9 @@ class SQLTransformationDecorator:
                  provider: str,
                  name: str = "",
                  schedule: str = "",
-                 description: str = ""):
+                 description: str = "",
+                 parameters: Union[dict, None] = None,
+                 storage: str = "table"):
         self.registrar = registrar,
         self.name = name
         self.variant = variant
@@ -231,6 +233,8 @@ class SQLTransformationDecorator:
         self.schedule = schedule
         self.provider = provider
         self.description = description
+        self.parameters = parameters
+        self.storage = storage
 
     def __call__(self, fn: Callable[[], str]):
         if self.description == "":
@@ -251,7 +255,8 @@ class SQLTransformationDecorator:
         return Source(
             name=self.name,
             variant=self.variant,
-            definition=SQLTransformation(self.query),
+            definition=SQLTransformation(self.query, self.parameters,
+                                         self.storage),
             owner=self.owner,
             schedule=self.schedule,
             provider=self.provider,
@@ -577,7 +582,9 @@ class Registrar:
                                     provider: Union[str, OfflineProvider],
                                     owner: Union[str, UserRegistrar] = "",
                                     description: str = "",
-                                    schedule: str = ""):
+                                    schedule: str = "",
+                                    parameters: Union[dict, None] = None,
+                                    storage: str = "table"):
         if not isinstance(owner, str):
             owner = owner.name()
         if owner == "":
@@ -587,7 +594,7 @@ class Registrar:
         source = Source(
             name=name,
             variant=variant,
-            definition=SQLTransformation(query),
+            definition=SQLTransformation(query, parameters, storage),
             owner=owner,
             schedule=schedule,
             provider=provider,
@@ -602,7 +609,9 @@ class Registrar:
                            name: str = "",
                            schedule: str = "",
                            owner: Union[str, UserRegistrar] = "",
-                           description: str = ""):
+                           description: str = "",
+                           parameters: Union[dict, None] = None,
+                           storage: str = "table"):
         if not isinstance(owner, str):
             owner = owner.name()
         if owner == "":
@@ -617,6 +626,8 @@ class Registrar:
             schedule=schedule,
             owner=owner,
             description=description,
+            parameters=parameters,
+            storage=storage,
         )
         self.__resources.append(decorator)
         return decorator
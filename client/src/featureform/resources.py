@@ -247,6 +247,9 @@ class Transformation:
 @dataclass
 class SQLTransformation(Transformation):
     query: str
+    parameters: Union[dict, None] = None
+    # storage is "table", "view" or "materialized_view".
+    storage: str = "table"
 
     def type():
         "SQL"
@@ -254,8 +257,14 @@ class SQLTransformation(Transformation):
     def kwargs(self):
         return {
             "transformation":
-                pb.Transformation(SQLTransformation=pb.SQLTransformation(
-                    query=self.query, ), ),
+                pb.Transformation(
+                    SQLTransformation=pb.SQLTransformation(
+                        query=self.query,
+                        parameters=self.parameters or {},
+                    ),
+                    storage=pb.Transformation.Storage.Value(
+                        self.storage.upper()),
+                ),
         }
 
 
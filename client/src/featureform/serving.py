@@ -1,6 +1,7 @@
 # This Source Code Form is subject to the terms of the Mozilla Public
 # License, v. 2.0. If a copy of the MPL was not distributed with this
 # file, You can obtain one at https://mozilla.org/MPL/2.0/.
+import decimal
 import json
 import marshal
 
@@ -194,8 +195,24 @@ class Client:
             channel = grpc.insecure_channel(host, options=(('grpc.enable_http_proxy', 0),))
         self._stub = serving_pb2_grpc.FeatureStub(channel)
 
-    def dataset(self, name, version):
-        return Dataset(self._stub, name, version)
+    def dataset(self, name, version, features=None):
+        """Streams a training set. If features is a list of (name, version)
+        tuples, rows only hold those features, in that order."""
+        return Dataset.from_stub(self._stub, name, version, features)
+
+    def spool_dataset(self, name, version, features=None):
+        """Writes a training set to object storage as Parquet files and
+        returns a manifest with a URL for each file, so that large training
+        sets can be read in parallel. Columns are named <name>__<version>,
+        followed by a label column."""
+        req = serving_pb2.TrainingDataRequest()
+        req.id.name = name
+        req.id.version = version
+        for (feature_name, feature_version) in features or []:
+            feature_id = req.features.add()
+            feature_id.name = feature_name
+            feature_id.version = feature_version
+        return self._stub.SpoolTrainingData(req)
 
     def features(self, features, entities):
         req = serving_pb2.FeatureServeRequest()
@@ -213,15 +230,20 @@ class Client:
 
 class Stream:
 
-    def __init__(self, stub, name, version):
+    def __init__(self, stub, name, version, features=None):
         req = serving_pb2.TrainingDataRequest()
         req.id.name = name
         req.id.version = version
+        req.include_schema = True
+        for (feature_name, feature_version) in features or []:
+            feature_id = req.features.add()
+            feature_id.name = feature_name
+            feature_id.version = feature_version
         self.name = name
         self.version = version
         self._stub = stub
         self._req = req
-        self._iter = stub.TrainingData(req)
+        self.restart()
 
     def __iter__(self):
         return self
@@ -231,6 +253,9 @@ class Stream:
 
     def restart(self):
         self._iter = self._stub.TrainingData(self._req)
+        # The first message only holds the schema: the features in the
+        # order they appear in every row, followed by the label.
+        self.schema = next(self._iter).schema
 
 
 class LocalStream:
@@ -337,8 +362,8 @@ class Dataset:
     def __init__(self, stream):
         self._stream = stream
 
-    def from_stub(stub, name, version):
-        stream = Stream(stub, name, version)
+    def from_stub(stub, name, version, features=None):
+        stream = Stream(stub, name, version, features)
         return Dataset(stream)
 
     def from_list(datalist):
@@ -415,4 +440,7 @@ class LocalRow:
 def parse_proto_value(value):
     """ parse_proto_value is used to parse the one of Value message
 	"""
-    return getattr(value, value.WhichOneof("value"))
+    value_type = value.WhichOneof("value")
+    if value_type == "decimal_value":
+        return decimal.Decimal(value.decimal_value)
+    return getattr(value, value_type)
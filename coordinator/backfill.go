@@ -0,0 +1,453 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	re "github.com/avast/retry-go/v4"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/runner"
+	mvccpb "go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// BackfillPrefix is the etcd prefix that backfilled runs are queued under.
+const BackfillPrefix = "BACKFILL__"
+
+// backfillTimeFormat sorts in time order, so that a resource's runs are
+// listed oldest first.
+const backfillTimeFormat = "20060102T150405Z"
+
+// BackfillRun is a historical run of a scheduled resource, covering the part
+// of its history from Start to End.
+type BackfillRun struct {
+	Resource metadata.ResourceID
+	Start    time.Time
+	End      time.Time
+	// Error is set once the run has failed all of its attempts. The
+	// resource's later runs wait until it's backfilled again.
+	Error string
+}
+
+func (r *BackfillRun) Serialize() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *BackfillRun) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, r)
+}
+
+func backfillResourceKey(id metadata.ResourceID) string {
+	return fmt.Sprintf("%s%s__%s__%s__", BackfillPrefix, id.Type, id.Name, id.Variant)
+}
+
+func backfillKey(id metadata.ResourceID, start time.Time) string {
+	return backfillResourceKey(id) + start.UTC().Format(backfillTimeFormat)
+}
+
+// backfillWindows splits the range from start to end at each of the
+// schedule's runs within it, so that each window is what one scheduled run
+// would have covered.
+func backfillWindows(schedule string, start, end time.Time) ([][2]time.Time, error) {
+	parsed, err := metadata.ParseSchedule(schedule)
+	if err != nil {
+		return nil, err
+	}
+	var windows [][2]time.Time
+	from := start
+	for run := parsed.Next(start); !run.IsZero() && run.Before(end); run = parsed.Next(run) {
+		windows = append(windows, [2]time.Time{from, run})
+		from = run
+	}
+	return append(windows, [2]time.Time{from, end}), nil
+}
+
+// Backfill queues historical runs of a scheduled feature or transformation
+// over the range from start to end. A feature's range is split into a run for
+// each interval of its schedule, each materializing the values as of the end
+// of its interval, and the runs are made oldest first. A transformation is
+// rebuilt from its sources as a whole, so its range is a single run.
+func (c *Coordinator) Backfill(id metadata.ResourceID, start, end time.Time) ([]BackfillRun, error) {
+	if !start.Before(end) {
+		return nil, fmt.Errorf("backfill start %s is not before its end %s", start, end)
+	}
+	ctx := context.Background()
+	nv := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	var windows [][2]time.Time
+	switch id.Type {
+	case metadata.FEATURE_VARIANT:
+		feature, err := c.store().GetFeatureVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get feature variant: %w", err)
+		}
+		if feature.Schedule() == "" {
+			return nil, fmt.Errorf("feature %s (%s) is not scheduled", id.Name, id.Variant)
+		}
+		if windows, err = backfillWindows(feature.Schedule(), start, end); err != nil {
+			return nil, err
+		}
+	case metadata.SOURCE_VARIANT:
+		source, err := c.store().GetSourceVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get source variant: %w", err)
+		}
+		if !source.IsSQLTransformation() || source.Schedule() == "" {
+			return nil, fmt.Errorf("source %s (%s) is not a scheduled transformation", id.Name, id.Variant)
+		}
+		windows = [][2]time.Time{{start, end}}
+	default:
+		return nil, fmt.Errorf("%s resources can't be backfilled", id.Type)
+	}
+	runs := make([]BackfillRun, len(windows))
+	for i, window := range windows {
+		runs[i] = BackfillRun{Resource: id, Start: window[0], End: window[1]}
+		serialized, err := runs[i].Serialize()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := (*c.KVClient).Put(ctx, backfillKey(id, window[0]), string(serialized)); err != nil {
+			return nil, fmt.Errorf("queue backfill run: %w", err)
+		}
+	}
+	c.Logger.Infow("Queued backfill", "resource", id, "start", start, "end", end, "runs", len(runs))
+	return runs, nil
+}
+
+// WatchForBackfills runs queued backfills until the coordinator shuts down.
+// Each resource's runs are made one at a time, oldest first.
+func (c *Coordinator) WatchForBackfills() error {
+	claims := c.shutdown.claims
+	var mtx sync.Mutex
+	// running holds the resources whose runs are being made, and whether
+	// more were queued since they were last listed.
+	running := make(map[metadata.ResourceID]bool)
+	start := func(id metadata.ResourceID) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		if _, busy := running[id]; busy {
+			running[id] = true
+			return
+		}
+		running[id] = false
+		go func() {
+			for {
+				if err := c.runBackfills(id); err != nil {
+					c.Logger.Errorw("Error running backfill", "resource", id, "error", err)
+				}
+				mtx.Lock()
+				if !running[id] {
+					delete(running, id)
+					mtx.Unlock()
+					return
+				}
+				running[id] = false
+				mtx.Unlock()
+			}
+		}()
+	}
+	for claims.Err() == nil {
+		resp, err := (*c.KVClient).Get(claims, BackfillPrefix, clientv3.WithPrefix())
+		if err != nil {
+			return fmt.Errorf("get queued backfills: %w", err)
+		}
+		for _, kv := range resp.Kvs {
+			run := &BackfillRun{}
+			if err := run.Deserialize(kv.Value); err != nil {
+				c.Logger.Errorw("Could not deserialize backfill run", "key", string(kv.Key), "error", err)
+				continue
+			}
+			start(run.Resource)
+		}
+		// A watch that fails, such as once the revision it's at is compacted,
+		// is started again after listing the queue again, so that runs queued
+		// in between aren't missed.
+		if err := c.watchBackfills(claims, resp.Header.Revision+1, start); err != nil && claims.Err() == nil {
+			c.Logger.Warnw("Backfill watch stopped, listing queued backfills again", "error", err)
+		}
+	}
+	return nil
+}
+
+func (c *Coordinator) watchBackfills(ctx context.Context, revision int64, start func(metadata.ResourceID)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	watch := c.EtcdClient.Watch(ctx, BackfillPrefix, clientv3.WithPrefix(), clientv3.WithRev(revision))
+	for wresp := range watch {
+		if err := wresp.Err(); err != nil {
+			return err
+		}
+		for _, ev := range wresp.Events {
+			if ev.Type != mvccpb.PUT {
+				continue
+			}
+			run := &BackfillRun{}
+			if err := run.Deserialize(ev.Kv.Value); err != nil {
+				c.Logger.Errorw("Could not deserialize backfill run", "key", string(ev.Kv.Key), "error", err)
+				continue
+			}
+			if run.Error == "" {
+				start(run.Resource)
+			}
+		}
+	}
+	return fmt.Errorf("backfill watch closed")
+}
+
+// runBackfills makes the queued runs of a resource in order, while holding a
+// lock so that no other coordinator runs them at the same time. It stops at a
+// run that fails, leaving it and the runs after it queued.
+func (c *Coordinator) runBackfills(id metadata.ResourceID) error {
+	key := backfillResourceKey(id)
+	if !c.ownsJob(key) {
+		return nil
+	}
+	if !c.shutdown.start() {
+		return nil
+	}
+	defer c.shutdown.finish()
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(c.lockTTL()))
+	if err != nil {
+		return fmt.Errorf("new session: %w", err)
+	}
+	defer s.Close()
+	mtx := concurrency.NewMutex(s, GetLockKey(key))
+	if err := mtx.Lock(c.shutdown.claims); err != nil {
+		if c.shutdown.claims.Err() != nil {
+			return nil
+		}
+		return fmt.Errorf("backfill lock: %w", err)
+	}
+	defer mtx.Unlock(context.Background())
+	ctx, cancel := c.leaseContext(s)
+	defer cancel()
+	for ctx.Err() == nil {
+		resp, err := (*c.KVClient).Get(ctx, key, clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend), clientv3.WithLimit(1))
+		if err != nil {
+			return fmt.Errorf("get next backfill run: %w", err)
+		}
+		if len(resp.Kvs) == 0 {
+			return nil
+		}
+		kv := resp.Kvs[0]
+		run := &BackfillRun{}
+		if err := run.Deserialize(kv.Value); err != nil {
+			return fmt.Errorf("deserialize backfill run: %w", err)
+		}
+		if run.Error != "" {
+			return nil
+		}
+		// Like the resource's other jobs, its backfill waits while it's locked
+		// for maintenance. UnlockResource picks the backfill up again.
+		lock, err := c.MaintenanceLock(id)
+		if err != nil {
+			return err
+		}
+		if lock != nil {
+			c.Logger.Infow("Resource is locked for maintenance, backfill will run once it's unlocked", "resource", id, "reason", lock.Reason)
+			return nil
+		}
+		c.Logger.Infow("Running backfill", "resource", id, "start", run.Start, "end", run.End)
+		policy := c.Retry
+		var made uint
+		runErr := re.Do(
+			func() error {
+				made++
+				return c.recordedRun(ctx, id, metadata.TriggerBackfill, made, func() error {
+					// Each attempt takes a job slot and is limited by the
+					// resource's max runtime, the same as the resource's jobs.
+					release, err := c.acquireJobSlot(ctx, id)
+					if err != nil {
+						return err
+					}
+					defer release()
+					return c.limitRuntime(ctx, id, func(runCtx context.Context) error {
+						return c.runBackfill(runCtx, run)
+					})
+				})
+			},
+			re.Context(ctx),
+			re.Attempts(policy.attempts()),
+			re.LastErrorOnly(true),
+			re.DelayType(func(n uint, _ error, _ *re.Config) time.Duration {
+				return policy.backoff(n)
+			}),
+		)
+		if interrupted(ctx) {
+			return nil
+		}
+		// The run is only dequeued if it wasn't queued again while it was
+		// running.
+		if runErr == nil {
+			cmp := clientv3.Compare(clientv3.ModRevision(string(kv.Key)), "=", kv.ModRevision)
+			if _, err := (*c.KVClient).Txn(ctx).If(cmp).Then(clientv3.OpDelete(string(kv.Key))).Commit(); err != nil {
+				return fmt.Errorf("dequeue backfill run: %w", err)
+			}
+			continue
+		}
+		run.Error = runErr.Error()
+		serialized, err := run.Serialize()
+		if err != nil {
+			return err
+		}
+		cmp := clientv3.Compare(clientv3.ModRevision(string(kv.Key)), "=", kv.ModRevision)
+		if _, err := (*c.KVClient).Txn(ctx).If(cmp).Then(clientv3.OpPut(string(kv.Key), string(serialized))).Commit(); err != nil {
+			return fmt.Errorf("record failed backfill run: %w", err)
+		}
+		return fmt.Errorf("backfill from %s to %s failed: %w", run.Start, run.End, runErr)
+	}
+	return nil
+}
+
+// resumeBackfill starts a resource's queued backfill again, such as once the
+// resource is unlocked, by writing its next run again so that the coordinators
+// watching the queue pick it up.
+func (c *Coordinator) resumeBackfill(ctx context.Context, id metadata.ResourceID) error {
+	resp, err := (*c.KVClient).Get(ctx, backfillResourceKey(id), clientv3.WithPrefix(), clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend), clientv3.WithLimit(1))
+	if err != nil {
+		return fmt.Errorf("get next backfill run: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return nil
+	}
+	kv := resp.Kvs[0]
+	cmp := clientv3.Compare(clientv3.ModRevision(string(kv.Key)), "=", kv.ModRevision)
+	if _, err := (*c.KVClient).Txn(ctx).If(cmp).Then(clientv3.OpPut(string(kv.Key), string(kv.Value))).Commit(); err != nil {
+		return fmt.Errorf("resume backfill: %w", err)
+	}
+	return nil
+}
+
+// runBackfill makes one backfilled run. The resource's status isn't changed,
+// since a backfill doesn't replace its current data.
+func (c *Coordinator) runBackfill(ctx context.Context, run *BackfillRun) error {
+	id := run.Resource
+	var jobName string
+	var serialized runner.Config
+	var err error
+	switch id.Type {
+	case metadata.FEATURE_VARIANT:
+		jobName = runner.MATERIALIZE
+		serialized, err = c.backfillMaterializeConfig(ctx, run)
+	case metadata.SOURCE_VARIANT:
+		jobName = runner.CREATE_TRANSFORMATION
+		serialized, err = c.backfillTransformationConfig(ctx, run)
+	default:
+		return re.Unrecoverable(fmt.Errorf("%s resources can't be backfilled", id.Type))
+	}
+	if err != nil {
+		return err
+	}
+	jobRunner, err := c.Spawner.GetJobRunner(jobName, serialized, c.etcdEndpoints(), id)
+	if err != nil {
+		return fmt.Errorf("create backfill runner: %w", err)
+	}
+	watcher, err := runner.RunWithContext(c.runContext(ctx, id), jobRunner)
+	if err != nil {
+		return fmt.Errorf("run backfill: %w", err)
+	}
+	return runner.WaitWithContext(ctx, watcher)
+}
+
+func (c *Coordinator) backfillMaterializeConfig(ctx context.Context, run *BackfillRun) (runner.Config, error) {
+	return c.featureUpdateConfig(ctx, run.Resource, &runner.BackfillWindow{Since: run.Start, Until: run.End})
+}
+
+// featureUpdateConfig is the config of a run that updates a feature's online
+// values, either as of now or, if window is set, as of the end of it.
+func (c *Coordinator) featureUpdateConfig(ctx context.Context, id metadata.ResourceID, window *runner.BackfillWindow) (runner.Config, error) {
+	feature, err := c.store().GetFeatureVariant(ctx, metadata.NameVariant{Name: id.Name, Variant: id.Variant})
+	if err != nil {
+		return nil, fmt.Errorf("get feature variant: %w", err)
+	}
+	source, err := c.store().GetSourceVariant(ctx, feature.Source())
+	if err != nil {
+		return nil, fmt.Errorf("get feature source variant: %w", err)
+	}
+	sourceProvider, err := c.store().GetProvider(ctx, source.Provider())
+	if err != nil {
+		return nil, fmt.Errorf("fetch offline provider: %w", err)
+	}
+	featureProvider, err := c.store().GetProvider(ctx, feature.Provider())
+	if err != nil {
+		return nil, fmt.Errorf("fetch online provider: %w", err)
+	}
+	onlineConfig, err := c.runnerProviderConfig(featureProvider)
+	if err != nil {
+		return nil, err
+	}
+	offlineConfig, err := c.runnerProviderConfig(sourceProvider)
+	if err != nil {
+		return nil, err
+	}
+	config := runner.MaterializedRunnerConfig{
+		OnlineType:    provider.Type(featureProvider.Type()),
+		OfflineType:   provider.Type(sourceProvider.Type()),
+		OnlineConfig:  onlineConfig,
+		OfflineConfig: offlineConfig,
+		ResourceID:    provider.ResourceID{Name: id.Name, Variant: id.Variant, Type: provider.Feature},
+		VType:         provider.ValueType(feature.Type()),
+		Cloud:         runner.LocalMaterializeRunner,
+		ChunkSizing:   c.ChunkSizing,
+		Parallelism:   c.MaterializeParallelism,
+		BulkLoadURI:   c.BulkLoadURI,
+		IsUpdate:      true,
+		Schedule:      feature.Schedule(),
+		Backfill:      window,
+	}
+	if window == nil {
+		// The windows of a backfill share a materialization, so they aren't
+		// given the lineage of the job that runs them.
+		config.Lineage = c.jobLineage(id)
+	}
+	return config.Serialize()
+}
+
+func (c *Coordinator) backfillTransformationConfig(ctx context.Context, run *BackfillRun) (runner.Config, error) {
+	return c.transformationUpdateConfig(ctx, run.Resource, &runner.BackfillWindow{Since: run.Start, Until: run.End})
+}
+
+// transformationUpdateConfig is the config of a run that updates a
+// transformation's table, either as of now or, if window is set, over it.
+func (c *Coordinator) transformationUpdateConfig(ctx context.Context, id metadata.ResourceID, window *runner.BackfillWindow) (runner.Config, error) {
+	source, err := c.store().GetSourceVariant(ctx, metadata.NameVariant{Name: id.Name, Variant: id.Variant})
+	if err != nil {
+		return nil, fmt.Errorf("get source variant: %w", err)
+	}
+	if !source.IsSQLTransformation() {
+		return nil, permanent(fmt.Errorf("source %s (%s) isn't a SQL transformation", id.Name, id.Variant))
+	}
+	sourceMap, err := c.mapNameVariantsToTables(source.SQLTransformationSources())
+	if err != nil {
+		return nil, fmt.Errorf("map transformation sources: %w", err)
+	}
+	sourceProvider, err := c.store().GetProvider(ctx, source.Provider())
+	if err != nil {
+		return nil, fmt.Errorf("fetch offline provider: %w", err)
+	}
+	query, err := templateReplace(source.SQLTransformationQuery(), sourceMap, source.SQLTransformationParameters(), providerSyntax(sourceProvider))
+	if err != nil {
+		return nil, re.Unrecoverable(fmt.Errorf("template replace: %w", err))
+	}
+	offlineConfig, err := c.runnerProviderConfig(sourceProvider)
+	if err != nil {
+		return nil, err
+	}
+	config := runner.CreateTransformationConfig{
+		OfflineType:   provider.Type(sourceProvider.Type()),
+		OfflineConfig: offlineConfig,
+		TransformationConfig: provider.TransformationConfig{
+			TargetTableID: provider.ResourceID{Name: id.Name, Variant: id.Variant, Type: provider.Transformation},
+			Query:         query,
+			Storage:       transformationStorage(source),
+		},
+		IsUpdate: true,
+		Schedule: source.Schedule(),
+		Backfill: window,
+	}
+	return config.Serialize()
+}
@@ -0,0 +1,69 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+
+	"github.com/featureform/metadata"
+	mvccpb "go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+var errJobCancelled = errors.New("job cancelled")
+
+// jobContext returns the context of the running job for id. It's cancelled if
+// the job is, and should be passed to anything the job runs.
+func (c *Coordinator) jobContext(id metadata.ResourceID) context.Context {
+	if ctx, ok := c.jobContexts.Load(id); ok {
+		return c.runContext(ctx.(context.Context), id)
+	}
+	return c.runContext(context.Background(), id)
+}
+
+// jobLineage returns the lineage of the running job for id, or "" if there
+// isn't one, like for a backfill.
+func (c *Coordinator) jobLineage(id metadata.ResourceID) string {
+	if lineage, ok := c.jobLineages.Load(id); ok {
+		return lineage.(string)
+	}
+	return ""
+}
+
+// watchCancellation calls cancel once a cancellation is requested for the job
+// of id, or returns once ctx is done.
+func (c *Coordinator) watchCancellation(ctx context.Context, id metadata.ResourceID, cancel context.CancelFunc) {
+	key := metadata.GetCancelKey(id)
+	// The watch is started first so that a cancellation written between the
+	// two calls isn't missed.
+	watch := c.EtcdClient.Watch(ctx, key)
+	if resp, err := (*c.KVClient).Get(ctx, key); err != nil {
+		c.Logger.Errorw("Could not check for job cancellation", "resource", id, "error", err)
+	} else if len(resp.Kvs) > 0 {
+		c.cancelJob(id, resp.Kvs[0].Value, cancel)
+		return
+	}
+	for resp := range watch {
+		for _, ev := range resp.Events {
+			if ev.Type == mvccpb.PUT {
+				c.cancelJob(id, ev.Kv.Value, cancel)
+				return
+			}
+		}
+	}
+}
+
+func (c *Coordinator) cancelJob(id metadata.ResourceID, serialized []byte, cancel context.CancelFunc) {
+	cancellation := &metadata.JobCancellation{}
+	if err := cancellation.Deserialize(serialized); err != nil {
+		c.Logger.Errorw("Could not deserialize job cancellation", "resource", id, "error", err)
+	}
+	c.Logger.Infow("Cancelling job", "resource", id, "requester", cancellation.Requester, "requested", cancellation.Requested)
+	cancel()
+}
+
+// clearCancellation removes the cancellation request for id once its job has
+// stopped, so that it doesn't cancel the next run.
+func (c *Coordinator) clearCancellation(id metadata.ResourceID) {
+	if _, err := (*c.KVClient).Delete(context.Background(), metadata.GetCancelKey(id)); err != nil {
+		c.Logger.Errorw("Could not clear job cancellation", "resource", id, "error", err)
+	}
+}
@@ -0,0 +1,113 @@
+package coordinator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// CatchUpMode is what the scheduler does with the runs of a job that were
+// missed, such as while no coordinator was leading the scheduler.
+type CatchUpMode string
+
+const (
+	// CatchUpOnce makes one run in place of all of the missed runs. It's the
+	// default.
+	CatchUpOnce CatchUpMode = "once"
+	// CatchUpSkip drops the missed runs and waits for the next scheduled one.
+	CatchUpSkip CatchUpMode = "skip"
+	// CatchUpBackfill backfills the windows of the most recent missed runs,
+	// oldest first. Resources that can't be backfilled run once instead.
+	CatchUpBackfill CatchUpMode = "backfill"
+)
+
+// maxMissedRuns bounds how many missed runs are counted, so a job that's
+// been missed every minute for months isn't walked through run by run.
+const maxMissedRuns = 10000
+
+// CatchUpPolicy decides what happens to a job's missed runs.
+type CatchUpPolicy struct {
+	Mode CatchUpMode
+	// MaxRuns is how many of the missed runs are backfilled. More recent
+	// runs are kept over older ones. It must be set to backfill.
+	MaxRuns int
+}
+
+// ParseCatchUpPolicy reads a policy written as "once", "skip" or
+// "backfill:<max runs>".
+func ParseCatchUpPolicy(value string) (CatchUpPolicy, error) {
+	mode, maxRuns := strings.TrimSpace(value), ""
+	if i := strings.Index(mode, ":"); i >= 0 {
+		mode, maxRuns = mode[:i], mode[i+1:]
+	}
+	switch CatchUpMode(mode) {
+	case "", CatchUpOnce, CatchUpSkip:
+		if maxRuns != "" {
+			return CatchUpPolicy{}, fmt.Errorf("catch-up mode %s doesn't take a run limit", mode)
+		}
+		return CatchUpPolicy{Mode: CatchUpMode(mode)}, nil
+	case CatchUpBackfill:
+		runs, err := strconv.Atoi(maxRuns)
+		if err != nil || runs < 1 {
+			return CatchUpPolicy{}, fmt.Errorf("backfill catch-up needs a run limit, like backfill:5")
+		}
+		return CatchUpPolicy{Mode: CatchUpBackfill, MaxRuns: runs}, nil
+	default:
+		return CatchUpPolicy{}, fmt.Errorf("unknown catch-up mode %q", mode)
+	}
+}
+
+// CatchUpPolicies picks the catch-up policy of each scheduled resource.
+// Resources without a policy of their own get Default.
+type CatchUpPolicies struct {
+	Default   CatchUpPolicy
+	Resources map[metadata.NameVariant]CatchUpPolicy
+}
+
+func (p CatchUpPolicies) For(id metadata.ResourceID) CatchUpPolicy {
+	if policy, has := p.Resources[metadata.NameVariant{Name: id.Name, Variant: id.Variant}]; has {
+		return policy
+	}
+	return p.Default
+}
+
+// missedRuns returns the times a schedule was due to run from next, the run
+// that the job was waiting for, until now. A job that's on time has one.
+func missedRuns(schedule metadata.Schedule, next, now time.Time) []time.Time {
+	var runs []time.Time
+	for run := next; !run.IsZero() && !run.After(now) && len(runs) < maxMissedRuns; run = schedule.Next(run) {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// catchUpPlan is what the scheduler does for a job that's due.
+type catchUpPlan struct {
+	// run is set if the job is run now.
+	run bool
+	// backfillFrom is set to the start of the missed runs to backfill.
+	backfillFrom time.Time
+}
+
+// plan decides what to do about the runs of a job that are due. Only a job
+// with more than one due run has missed any.
+func (p CatchUpPolicy) plan(due []time.Time) catchUpPlan {
+	if len(due) <= 1 {
+		return catchUpPlan{run: true}
+	}
+	switch p.Mode {
+	case CatchUpSkip:
+		return catchUpPlan{}
+	case CatchUpBackfill:
+		from := 0
+		if p.MaxRuns > 0 && len(due) > p.MaxRuns {
+			from = len(due) - p.MaxRuns
+		}
+		return catchUpPlan{backfillFrom: due[from]}
+	default:
+		return catchUpPlan{run: true}
+	}
+}
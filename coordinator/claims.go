@@ -0,0 +1,201 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/featureform/coordinator/queue"
+	"github.com/google/uuid"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// DefaultClaimBatchSize is how many job keys are read from etcd at a time,
+// if ClaimBatchSize isn't set.
+const DefaultClaimBatchSize = 500
+
+// ClaimLimiter bounds how fast a coordinator claims jobs. Each claim opens an
+// etcd session and waits on a lock, so when thousands of resources are
+// registered at once, claiming all of their jobs together makes etcd a hot
+// spot. Claims over the rate wait their turn, and up to burst claims can be
+// made at once after a quiet period.
+type ClaimLimiter struct {
+	interval time.Duration
+	burst    int
+	mtx      sync.Mutex
+	// next is when the next claim can be made if none are saved up, and
+	// bursts can't start before now minus burst intervals.
+	next time.Time
+}
+
+// NewClaimLimiter returns a limiter of perSecond claims. A burst of less than
+// one is one.
+func NewClaimLimiter(perSecond float64, burst int) *ClaimLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &ClaimLimiter{interval: time.Duration(float64(time.Second) / perSecond), burst: burst}
+}
+
+// reserve takes the next claim and returns how long its caller must wait
+// before making it.
+func (l *ClaimLimiter) reserve(now time.Time) time.Duration {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	earliest := now.Add(-time.Duration(l.burst-1) * l.interval)
+	if l.next.Before(earliest) {
+		l.next = earliest
+	}
+	at := l.next
+	l.next = l.next.Add(l.interval)
+	if at.Before(now) {
+		return 0
+	}
+	return at.Sub(now)
+}
+
+// Wait waits until a claim can be made, or ctx is done.
+func (l *ClaimLimiter) Wait(ctx context.Context) error {
+	wait := l.reserve(time.Now())
+	if wait == 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// waitToClaim waits for the coordinator's claim limiter, if it has one.
+func (c *Coordinator) waitToClaim(ctx context.Context) error {
+	if c.ClaimLimiter == nil {
+		return ctx.Err()
+	}
+	return c.ClaimLimiter.Wait(ctx)
+}
+
+func (c *Coordinator) claimBatchSize() int64 {
+	if c.ClaimBatchSize <= 0 {
+		return DefaultClaimBatchSize
+	}
+	return int64(c.ClaimBatchSize)
+}
+
+// listJobKeys calls handle with the key of each queued job, reading them in
+// batches of ClaimBatchSize keys so that a large queue isn't read in one
+// request. It returns the revision the first batch was read at, so that a
+// watch can start after it.
+func (c *Coordinator) listJobKeys(ctx context.Context, handle func(key string)) (int64, error) {
+	var revision int64
+	from := queue.JobPrefix
+	end := clientv3.GetPrefixRangeEnd(queue.JobPrefix)
+	for {
+		opts := []clientv3.OpOption{clientv3.WithRange(end), clientv3.WithKeysOnly(), clientv3.WithLimit(c.claimBatchSize())}
+		// Later batches are read at the first one's revision, so that the
+		// list is consistent.
+		if revision != 0 {
+			opts = append(opts, clientv3.WithRev(revision))
+		}
+		resp, err := (*c.KVClient).Get(ctx, from, opts...)
+		if err != nil {
+			return 0, fmt.Errorf("list jobs: %w", err)
+		}
+		if revision == 0 {
+			revision = resp.Header.Revision
+		}
+		for _, kv := range resp.Kvs {
+			handle(string(kv.Key))
+		}
+		if !resp.More || len(resp.Kvs) == 0 {
+			return revision, nil
+		}
+		from = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+}
+
+// CompactionElection is the etcd prefix that coordinators campaign under to
+// compact etcd's history, so that only one of them compacts it at a time.
+const CompactionElection = "COORDINATOR_COMPACTION"
+
+// CompactionRetainedRevisions is how many revisions before the one etcd was
+// at an interval ago are kept when compacting, so that a watch that's behind
+// by a burst of writes can still catch up on them.
+var CompactionRetainedRevisions int64 = 10000
+
+// CompactEtcdEvery compacts etcd's history every interval while this
+// coordinator is elected to, up to CompactionRetainedRevisions before the
+// revision it was at the interval before. Each job that's claimed and
+// completed leaves its job key, its attempts and its lock in etcd's history
+// after they're deleted, so without compaction the history grows with every
+// job. Watches that still fall behind what's compacted list their keys again.
+func (c *Coordinator) CompactEtcdEvery(ctx context.Context, interval time.Duration) {
+	for ctx.Err() == nil {
+		if err := c.leadCompaction(ctx, interval); err != nil && ctx.Err() == nil {
+			c.Logger.Errorw("Stopped compacting etcd history", "error", err)
+			time.Sleep(time.Second)
+		}
+	}
+}
+
+func (c *Coordinator) leadCompaction(ctx context.Context, interval time.Duration) error {
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(c.lockTTL()))
+	if err != nil {
+		return fmt.Errorf("new session: %w", err)
+	}
+	defer s.Close()
+	election := concurrency.NewElection(s, CompactionElection)
+	candidate, err := os.Hostname()
+	if err != nil {
+		candidate = uuid.New().String()
+	}
+	if err := election.Campaign(ctx, candidate); err != nil {
+		return fmt.Errorf("campaign for compaction: %w", err)
+	}
+	defer func() {
+		if err := election.Resign(context.Background()); err != nil {
+			c.Logger.Debugw("Error resigning compaction", "error", err)
+		}
+	}()
+	c.Logger.Infow("Compacting etcd history", "candidate", candidate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var previous int64
+	for {
+		resp, err := (*c.KVClient).Get(ctx, queue.JobPrefix, clientv3.WithCountOnly())
+		if err != nil {
+			c.Logger.Errorw("Could not get etcd revision to compact to", "error", err)
+		} else {
+			if revision := compactionRevision(previous); revision > 0 {
+				if _, err := (*c.KVClient).Compact(ctx, revision); err != nil {
+					c.Logger.Errorw("Could not compact etcd history", "revision", revision, "error", err)
+				} else {
+					c.Logger.Infow("Compacted etcd history", "revision", revision)
+				}
+			}
+			previous = resp.Header.Revision
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.Done():
+			return fmt.Errorf("compaction session expired")
+		case <-ticker.C:
+		}
+	}
+}
+
+// compactionRevision returns the revision to compact to, given the revision
+// etcd was at an interval ago, or 0 if nothing should be compacted yet.
+func compactionRevision(previous int64) int64 {
+	if previous <= CompactionRetainedRevisions {
+		return 0
+	}
+	return previous - CompactionRetainedRevisions
+}
@@ -0,0 +1,51 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+)
+
+// JobCleaner is implemented by spawners that leave jobs behind for a
+// resource, like Kubernetes cron jobs, so they can be deleted along with the
+// resource or its schedule.
+type JobCleaner interface {
+	DeleteJobs(id metadata.ResourceID) error
+}
+
+// DeleteJobs deletes the resource's cron job, the jobs it and the coordinator
+// started and their pods, which are found by their job label.
+func (k *KubernetesJobSpawner) DeleteJobs(id metadata.ResourceID) error {
+	jobClient, err := runner.NewKubernetesJobClient(runner.GetCronJobName(id), runner.Namespace)
+	if err != nil {
+		return fmt.Errorf("create new kubernetes job client: %w", err)
+	}
+	return jobClient.DeleteAll()
+}
+
+// deleteResourceJobs deletes the jobs that were spawned for a resource. It's
+// a no-op for spawners that don't leave any behind.
+func (c *Coordinator) deleteResourceJobs(id metadata.ResourceID) error {
+	cleaner, ok := c.Spawner.(JobCleaner)
+	if !ok {
+		return nil
+	}
+	if err := cleaner.DeleteJobs(id); err != nil {
+		return fmt.Errorf("delete jobs of %s (%s): %w", id.Name, id.Variant, err)
+	}
+	c.Logger.Infow("Deleted resource jobs", "resource", id)
+	return nil
+}
+
+// removeSchedule stops a resource's scheduled updates, both the ones run by
+// the scheduler and the cron jobs of the spawner.
+func (c *Coordinator) removeSchedule(ctx context.Context, id metadata.ResourceID) error {
+	if c.KVClient != nil {
+		if _, err := (*c.KVClient).Delete(ctx, scheduleKey(id)); err != nil {
+			return fmt.Errorf("delete scheduled job: %w", err)
+		}
+	}
+	return c.deleteResourceJobs(id)
+}
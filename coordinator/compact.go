@@ -0,0 +1,92 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/runner"
+)
+
+// CompactMaterializations drops the expired generations of the features'
+// materializations from an offline provider, archiving them to archiveURI
+// first if it's set. With a schedule the compaction is set up as a cron job,
+// otherwise it runs once and this waits for it.
+func (c *Coordinator) CompactMaterializations(providerName string, features []metadata.NameVariant, policy runner.RetentionPolicy, archiveURI, schedule string) error {
+	if len(features) == 0 {
+		return fmt.Errorf("no features to compact")
+	}
+	parsed, err := metadata.ParseSchedule(schedule)
+	if err != nil {
+		return err
+	}
+	offline, err := c.store().GetProvider(context.Background(), providerName)
+	if err != nil {
+		return fmt.Errorf("get offline provider: %w", err)
+	}
+	ids := make([]provider.ResourceID, len(features))
+	for i, feature := range features {
+		ids[i] = provider.ResourceID{Name: feature.Name, Variant: feature.Variant, Type: provider.Feature}
+	}
+	offlineConfig, err := c.runnerProviderConfig(offline)
+	if err != nil {
+		return err
+	}
+	compactionConfig := &runner.CompactionRunnerConfig{
+		OfflineType:   provider.Type(offline.Type()),
+		OfflineConfig: offlineConfig,
+		Features:      ids,
+		Policy:        policy,
+		ArchiveURI:    archiveURI,
+	}
+	serialized, err := compactionConfig.Serialize()
+	if err != nil {
+		return fmt.Errorf("serialize compaction config: %w", err)
+	}
+	jobID := metadata.ResourceID{Name: providerName, Variant: "compaction", Type: metadata.PROVIDER}
+	jobRunner, err := c.Spawner.GetJobRunner(runner.COMPACT_MATERIALIZATIONS, serialized, c.etcdEndpoints(), jobID)
+	if err != nil {
+		return fmt.Errorf("create compaction runner: %w", err)
+	}
+	if !parsed.IsZero() {
+		cronRunner := c.cronRunner(jobRunner, runner.COMPACT_MATERIALIZATIONS, serialized, jobID)
+		if err := cronRunner.ScheduleJob(runner.CronSchedule(parsed.Cron())); err != nil {
+			return fmt.Errorf("schedule compaction job: %w", err)
+		}
+		return nil
+	}
+	watcher, err := jobRunner.Run()
+	if err != nil {
+		return fmt.Errorf("run compaction: %w", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		return fmt.Errorf("compaction failed: %w", err)
+	}
+	return nil
+}
+
+// compactUpdatedFeature applies MaterializationRetention to a feature's
+// generations once an update of its materialization succeeds, since each
+// update adds one. The update has already succeeded, so a compaction that
+// fails is only logged, and tried again after the next update.
+func (c *Coordinator) compactUpdatedFeature(ctx context.Context, id metadata.ResourceID) {
+	if c.MaterializationRetention == nil || id.Type != metadata.FEATURE_VARIANT {
+		return
+	}
+	feature := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	featureVariant, err := c.store().GetFeatureVariant(ctx, feature)
+	if err != nil {
+		c.Logger.Errorw("Failed to compact feature generations", "resource", id, "error", err)
+		return
+	}
+	source, err := c.store().GetSourceVariant(ctx, featureVariant.Source())
+	if err != nil {
+		c.Logger.Errorw("Failed to compact feature generations", "resource", id, "error", err)
+		return
+	}
+	err = c.CompactMaterializations(source.Provider(), []metadata.NameVariant{feature}, *c.MaterializationRetention, c.GenerationArchiveURI, "")
+	if err != nil {
+		c.Logger.Errorw("Failed to compact feature generations", "resource", id, "error", err)
+	}
+}
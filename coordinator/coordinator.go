@@ -3,40 +3,95 @@ package coordinator
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	db "github.com/jackc/pgx/v4"
 	"go.uber.org/zap"
 
+	"github.com/featureform/coordinator/queue"
 	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
 	"github.com/featureform/provider"
 	"github.com/featureform/runner"
+	"github.com/google/uuid"
 	mvccpb "go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/client/v3/concurrency"
+	v1 "k8s.io/api/core/v1"
 )
 
 type Config []byte
 
-func templateReplace(template string, replacements map[string]string) (string, error) {
-	formattedString := ""
-	numEscapes := strings.Count(template, "{{")
-	for i := 0; i < numEscapes; i++ {
-		split := strings.SplitN(template, "{{", 2)
-		afterSplit := strings.SplitN(split[1], "}}", 2)
-		key := strings.TrimSpace(afterSplit[0])
+// templateReplace fills in the templates of a transformation's query, which
+// is lexed with syntax: each {{ name.variant }} with the table in
+// replacements, quoted the way syntax does, and each {{ param "name" }} with
+// the parameter in params. Run-time templates, like {{ schedule_start }},
+// are left for the runner to fill in when each run starts. Its errors have
+// the ErrorTemplateInvalid code.
+func templateReplace(template string, replacements, params map[string]string, syntax provider.QuerySyntax) (string, error) {
+	query, err := runner.ExpandTemplates(syntax, template, func(key string) (string, error) {
+		if runner.IsRunTimeTemplate(key) {
+			return fmt.Sprintf("{{ %s }}", key), nil
+		}
+		if strings.HasPrefix(key, "param ") {
+			return paramLiteral(strings.TrimSpace(strings.TrimPrefix(key, "param ")), params)
+		}
 		replacement, has := replacements[key]
 		if !has {
-			return "", fmt.Errorf("no key set")
+			return "", fmt.Errorf("no key set for template %s", key)
 		}
-		formattedString += fmt.Sprintf("%s%s", split[0], sanitize(replacement))
-		template = afterSplit[1]
+		return syntax.Quote(replacement), nil
+	})
+	return query, metadata.WithErrorCode(metadata.ErrorTemplateInvalid, err)
+}
+
+// numericLiteral matches the decimal numbers that every provider's SQL reads
+// as numeric literals. Values Go would parse as floats but SQL wouldn't, like
+// NaN, Inf or hex floats, aren't matched.
+var numericLiteral = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// paramLiteral is the SQL literal of the parameter named by a quoted string.
+// Decimal numbers are written as they are, so they can be compared with
+// numeric columns, and anything else is a string literal.
+func paramLiteral(quotedName string, params map[string]string) (string, error) {
+	name, err := strconv.Unquote(quotedName)
+	if err != nil {
+		return "", fmt.Errorf("parameter name %s is not a quoted string", quotedName)
+	}
+	value, has := params[name]
+	if !has {
+		return "", fmt.Errorf("no parameter set for %s", name)
+	}
+	if numericLiteral.MatchString(value) {
+		return value, nil
 	}
-	formattedString += template
-	return formattedString, nil
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", "''")), nil
+}
+
+// transformationStorage is how the provider stores a transformation's
+// result.
+func transformationStorage(source *metadata.SourceVariant) provider.TransformationStorage {
+	switch source.TransformationStorage() {
+	case metadata.TransformationView:
+		return provider.ViewStorage
+	case metadata.TransformationMaterializedView:
+		return provider.MaterializedViewStorage
+	default:
+		return provider.TableStorage
+	}
+}
+
+// providerSyntax is how the provider's store lexes queries and quotes
+// identifiers.
+func providerSyntax(p *metadata.Provider) provider.QuerySyntax {
+	return provider.TypeQuerySyntax(provider.Type(p.Type()))
 }
 
 type Coordinator struct {
@@ -46,6 +101,76 @@ type Coordinator struct {
 	KVClient   *clientv3.KV
 	Spawner    JobSpawner
 	Timeout    int32
+	Retry      RetryPolicy
+	Limiter    *JobLimiter
+	// Resources is where jobs look up resources and set their status, and
+	// Providers opens the providers they run on. They default to Metadata
+	// and the registered provider factories, and can be replaced, such as
+	// by the mocks package, to test jobs without metadata or providers.
+	Resources MetadataStore
+	Providers ProviderFactory
+	// MaxRuntime bounds how long an attempt at a job may run, by the type of
+	// resource it creates. A job that runs for longer is cancelled and its
+	// resource marked FAILED, or retried if RetryTimeouts is set.
+	MaxRuntime    map[metadata.ResourceType]time.Duration
+	RetryTimeouts bool
+	// LockTTL bounds how long a job is orphaned after its coordinator stops
+	// renewing the job's lock, before another coordinator takes it over.
+	LockTTL time.Duration
+	// Partition splits jobs between coordinator replicas. If it's nil, this
+	// coordinator claims every job.
+	Partition *Partitioner
+	// Leadership, if it's set, makes this coordinator one of a set of
+	// replicas that elect a leader, and only the leader claims jobs.
+	Leadership *Leadership
+	// ClaimLimiter, if it's set, bounds how fast the coordinator claims jobs,
+	// and ClaimBatchSize is how many job keys it reads from etcd at a time.
+	ClaimLimiter   *ClaimLimiter
+	ClaimBatchSize int
+	// SchedulerInterval is how often the scheduler checks for due jobs, when
+	// this coordinator leads it.
+	SchedulerInterval time.Duration
+	// CatchUp decides what the scheduler does with the runs of each job that
+	// were missed. By default one run is made in place of them.
+	CatchUp CatchUpPolicies
+	// Queue delivers new jobs. If it's nil, the coordinator watches etcd for
+	// them.
+	Queue queue.Queue
+	// Metrics follows the lifecycle of jobs. If it's nil, none are recorded.
+	Metrics *metrics.JobMetrics
+	// ChunkSizing decides how the rows of a materialization are split
+	// between chunk jobs. If it's nil, the runner's defaults are used.
+	ChunkSizing *runner.ChunkSizing
+	// ProgressInterval is how often the progress of a job that creates a
+	// resource is written to its status. It defaults to
+	// DefaultProgressInterval.
+	ProgressInterval time.Duration
+	// MaterializeParallelism is how many chunks a materialization copies at
+	// a time. It defaults to the runner's DefaultLocalParallelism.
+	MaterializeParallelism int
+	// BulkLoadURI is the object storage that materializations are written
+	// to, to be loaded in bulk by online stores with a native bulk import.
+	// If it's empty, every store is copied to row by row.
+	BulkLoadURI string
+	// MaterializationRetention, if it's set, is applied to the generations
+	// of a feature's materialization after each update that adds one, and
+	// expired generations are archived to GenerationArchiveURI if it's set.
+	MaterializationRetention *runner.RetentionPolicy
+	GenerationArchiveURI     string
+	// jobContexts holds the context of each running job, which is cancelled
+	// when the job is.
+	jobContexts sync.Map
+	// jobLineages holds the lineage of each running job, which its runners
+	// derive the IDs they checkpoint with from.
+	jobLineages sync.Map
+	// runLoggers holds the logger of each job run in progress, which
+	// captures what the run's runners log to be recorded with the run.
+	runLoggers sync.Map
+	// claiming holds the jobs this coordinator is claiming or running, so
+	// that the job's own updates, membership changes and elections don't
+	// claim them again.
+	claiming sync.Map
+	shutdown *shutdown
 }
 
 type ETCDConfig struct {
@@ -75,13 +200,13 @@ func (c *Coordinator) AwaitPendingSource(sourceNameVariant metadata.NameVariant)
 	start := time.Now()
 	elapsed := time.Since(start)
 	for sourceStatus != metadata.READY && elapsed < time.Duration(c.Timeout)*time.Second {
-		source, err := c.Metadata.GetSourceVariant(context.Background(), sourceNameVariant)
+		source, err := c.store().GetSourceVariant(context.Background(), sourceNameVariant)
 		if err != nil {
 			return nil, err
 		}
 		sourceStatus := source.Status()
 		if sourceStatus == metadata.FAILED {
-			return nil, fmt.Errorf("source of feature not ready: name: %s, variant: %s", sourceNameVariant.Name, sourceNameVariant.Variant)
+			return nil, metadata.WithErrorCode(metadata.ErrorSourceNotReady, fmt.Errorf("source of feature not ready: name: %s, variant: %s", sourceNameVariant.Name, sourceNameVariant.Variant))
 		}
 		if sourceStatus == metadata.READY {
 			return source, nil
@@ -89,14 +214,33 @@ func (c *Coordinator) AwaitPendingSource(sourceNameVariant metadata.NameVariant)
 		elapsed = time.Since(start)
 		time.Sleep(1 * time.Second)
 	}
-	return nil, fmt.Errorf("waited too long for source to become ready")
+	return nil, metadata.WithErrorCode(metadata.ErrorSourceNotReady, fmt.Errorf("waited too long for source to become ready"))
 }
 
 type JobSpawner interface {
 	GetJobRunner(jobName string, config runner.Config, etcdEndpoints []string, id metadata.ResourceID) (runner.Runner, error)
 }
 
-type KubernetesJobSpawner struct{}
+// KubernetesJobSpawner runs jobs as Kubernetes jobs. Its zero value runs
+// every job with the WORKER_IMAGE and Kubernetes' pod defaults.
+type KubernetesJobSpawner struct {
+	// Image is the worker image. It's WORKER_IMAGE if it isn't set.
+	Image string
+	// ImageDigest pins Image to a digest. It's WORKER_IMAGE_DIGEST if it
+	// isn't set, and Image is run by its tag if neither is.
+	ImageDigest string
+	// ProviderImages overrides the image of jobs against a type of provider,
+	// such as one with the dependencies of a Spark offline store. They're
+	// pinned by a digest in their own reference.
+	ProviderImages map[provider.Type]string
+	// Verifier, if it's set, checks a job's image before the job is
+	// started with it.
+	Verifier runner.ImageVerifier
+	// Pod is applied to the pods of every job, and JobResources replaces
+	// its resources for jobs by name, such as giving Materialize more memory.
+	Pod          runner.KubernetesPodConfig
+	JobResources map[string]v1.ResourceRequirements
+}
 
 type MemoryJobSpawner struct{}
 
@@ -104,17 +248,35 @@ func GetLockKey(jobKey string) string {
 	return fmt.Sprintf("LOCK_%s", jobKey)
 }
 
-func (k *KubernetesJobSpawner) GetJobRunner(jobName string, config runner.Config, etcdEndpoints []string, id metadata.ResourceID) (runner.Runner, error) {
+// workerEnvVars are the environment variables a worker runs a job with.
+func workerEnvVars(jobName string, config runner.Config, etcdEndpoints []string) (map[string]string, error) {
 	etcdConfig := &ETCDConfig{Endpoints: etcdEndpoints, Username: os.Getenv("ETCD_USERNAME"), Password: os.Getenv("ETCD_PASSWORD")}
 	serializedETCD, err := etcdConfig.Serialize()
 	if err != nil {
 		return nil, err
 	}
+	return map[string]string{"NAME": jobName, "CONFIG": string(config), "ETCD_CONFIG": string(serializedETCD)}, nil
+}
+
+func (k *KubernetesJobSpawner) GetJobRunner(jobName string, config runner.Config, etcdEndpoints []string, id metadata.ResourceID) (runner.Runner, error) {
+	image, config, err := k.jobImage(jobName, config)
+	if err != nil {
+		return nil, err
+	}
+	envVars, err := workerEnvVars(jobName, config, etcdEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	pod := k.Pod
+	if resources, has := k.JobResources[jobName]; has {
+		pod.Resources = resources
+	}
 	kubeConfig := runner.KubernetesRunnerConfig{
-		EnvVars:  map[string]string{"NAME": jobName, "CONFIG": string(config), "ETCD_CONFIG": string(serializedETCD)},
-		Image:    os.Getenv("WORKER_IMAGE"),
+		EnvVars:  envVars,
+		Image:    image,
 		NumTasks: 1,
 		Resource: id,
+		Pod:      pod,
 	}
 	jobRunner, err := runner.NewKubernetesRunner(kubeConfig)
 	if err != nil {
@@ -123,6 +285,228 @@ func (k *KubernetesJobSpawner) GetJobRunner(jobName string, config runner.Config
 	return jobRunner, nil
 }
 
+// workerImage returns image pinned to digest. If the spawner verifies
+// images, an image without a digest is pinned to the one that was verified,
+// so that the job doesn't run whatever its tag points to by the time it
+// starts.
+func (k *KubernetesJobSpawner) workerImage(image, digest string) (string, error) {
+	pinned, err := runner.PinImage(image, digest)
+	if err != nil {
+		return "", err
+	}
+	if k.Verifier == nil {
+		return pinned, nil
+	}
+	verified, err := k.Verifier.Verify(context.TODO(), pinned)
+	if err != nil {
+		return "", fmt.Errorf("verify worker image: %w", err)
+	}
+	return verified, nil
+}
+
+// jobImage returns the image a job with config runs, and config with what
+// its chunk jobs run with if it's a materialize job.
+func (k *KubernetesJobSpawner) jobImage(jobName string, config runner.Config) (string, runner.Config, error) {
+	image, err := k.workerImage(k.image(config), k.imageDigest(config))
+	if err != nil {
+		return "", nil, err
+	}
+	if jobName == runner.MATERIALIZE {
+		if config, err = k.chunkJobConfig(config, image); err != nil {
+			return "", nil, err
+		}
+	}
+	return image, config, nil
+}
+
+// chunkJobConfig sets what the chunk jobs of a materialize job run with.
+// They copy between the same providers as the materialize job, so they're
+// started from its image, including any provider's override, pinned to the
+// same verified digest. They get the spawner's pod config, with any
+// resources set for COPY_TO_ONLINE jobs.
+func (k *KubernetesJobSpawner) chunkJobConfig(config runner.Config, image string) (runner.Config, error) {
+	materialize := &runner.MaterializedRunnerConfig{}
+	if err := materialize.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("deserialize materialize config: %w", err)
+	}
+	materialize.Image = image
+	pod := k.Pod
+	if resources, has := k.JobResources[string(runner.COPY_TO_ONLINE)]; has {
+		pod.Resources = resources
+	}
+	materialize.Pod = &pod
+	return materialize.Serialize()
+}
+
+// image returns the image for a job with config, which is the override of
+// the first of its providers that has one.
+func (k *KubernetesJobSpawner) image(config runner.Config) string {
+	for _, t := range runner.ConfigProviderTypes(config) {
+		if image, has := k.ProviderImages[t]; has {
+			return image
+		}
+	}
+	return k.defaultImage()
+}
+
+func (k *KubernetesJobSpawner) defaultImage() string {
+	if k.Image != "" {
+		return k.Image
+	}
+	return os.Getenv("WORKER_IMAGE")
+}
+
+// imageDigest returns the digest that the image of a job with config is
+// pinned to, which is only set for the default image.
+func (k *KubernetesJobSpawner) imageDigest(config runner.Config) string {
+	for _, t := range runner.ConfigProviderTypes(config) {
+		if _, has := k.ProviderImages[t]; has {
+			return ""
+		}
+	}
+	return k.defaultImageDigest()
+}
+
+func (k *KubernetesJobSpawner) defaultImageDigest() string {
+	if k.ImageDigest != "" {
+		return k.ImageDigest
+	}
+	return os.Getenv("WORKER_IMAGE_DIGEST")
+}
+
+// ArgoJobSpawner runs jobs as Argo workflows, which Argo retries and keeps
+// the history and logs of.
+type ArgoJobSpawner struct {
+	// Retries is how many times Argo retries a failed job.
+	Retries        int32
+	ServiceAccount string
+	ArchiveLogs    bool
+}
+
+func (a *ArgoJobSpawner) GetJobRunner(jobName string, config runner.Config, etcdEndpoints []string, id metadata.ResourceID) (runner.Runner, error) {
+	envVars, err := workerEnvVars(jobName, config, etcdEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	argoConfig := runner.ArgoRunnerConfig{
+		EnvVars:        envVars,
+		Image:          os.Getenv("WORKER_IMAGE"),
+		NumTasks:       1,
+		Resource:       id,
+		Retries:        a.Retries,
+		ServiceAccount: a.ServiceAccount,
+		ArchiveLogs:    a.ArchiveLogs,
+	}
+	jobRunner, err := runner.NewArgoRunner(argoConfig)
+	if err != nil {
+		return nil, err
+	}
+	return jobRunner, nil
+}
+
+// AWSJobSpawner runs jobs on AWS Batch or ECS Fargate, whichever its client
+// submits to, for deployments outside of Kubernetes.
+type AWSJobSpawner struct {
+	Client runner.AWSJobClient
+}
+
+func (a *AWSJobSpawner) GetJobRunner(jobName string, config runner.Config, etcdEndpoints []string, id metadata.ResourceID) (runner.Runner, error) {
+	envVars, err := workerEnvVars(jobName, config, etcdEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	awsConfig := runner.AWSRunnerConfig{
+		EnvVars:  envVars,
+		NumTasks: 1,
+		Resource: id,
+	}
+	return runner.NewAWSRunner(a.Client, awsConfig), nil
+}
+
+// CloudRunJobSpawner runs jobs as Cloud Run jobs. Transformations and
+// materializations can be given more CPU and memory than other jobs with
+// JobResources, which is keyed by job name.
+type CloudRunJobSpawner struct {
+	Client         runner.CloudRunClient
+	Resources      runner.CloudRunResources
+	JobResources   map[string]runner.CloudRunResources
+	Retries        int32
+	ServiceAccount string
+	Timeout        time.Duration
+}
+
+func (c *CloudRunJobSpawner) GetJobRunner(jobName string, config runner.Config, etcdEndpoints []string, id metadata.ResourceID) (runner.Runner, error) {
+	envVars, err := workerEnvVars(jobName, config, etcdEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	resources, has := c.JobResources[jobName]
+	if !has {
+		resources = c.Resources
+	}
+	cloudRunConfig := runner.CloudRunRunnerConfig{
+		EnvVars:        envVars,
+		Image:          os.Getenv("WORKER_IMAGE"),
+		NumTasks:       1,
+		Resource:       id,
+		Resources:      resources,
+		Retries:        c.Retries,
+		ServiceAccount: c.ServiceAccount,
+		Timeout:        c.Timeout,
+	}
+	return runner.NewCloudRunRunner(c.Client, cloudRunConfig), nil
+}
+
+// NomadJobSpawner runs jobs as Nomad batch jobs, using the docker driver.
+type NomadJobSpawner struct {
+	Client    runner.NomadClient
+	Resources runner.NomadResources
+	Retries   int32
+}
+
+func (n *NomadJobSpawner) GetJobRunner(jobName string, config runner.Config, etcdEndpoints []string, id metadata.ResourceID) (runner.Runner, error) {
+	envVars, err := workerEnvVars(jobName, config, etcdEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	nomadConfig := runner.NomadRunnerConfig{
+		EnvVars:   envVars,
+		Image:     os.Getenv("WORKER_IMAGE"),
+		NumTasks:  1,
+		Resource:  id,
+		Resources: n.Resources,
+		Retries:   n.Retries,
+	}
+	return runner.NewNomadRunner(n.Client, nomadConfig), nil
+}
+
+// SubprocessJobSpawner runs each job in a process of its own, started from
+// the worker binary, so that a runner that panics can't take the
+// coordinator down with it.
+type SubprocessJobSpawner struct {
+	// Binary is the worker binary. It's "worker" on the PATH if it isn't
+	// set.
+	Binary string
+}
+
+func (s *SubprocessJobSpawner) GetJobRunner(jobName string, config runner.Config, etcdEndpoints []string, id metadata.ResourceID) (runner.Runner, error) {
+	envVars, err := workerEnvVars(jobName, config, etcdEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	binary := s.Binary
+	if binary == "" {
+		binary = "worker"
+	}
+	subprocessConfig := runner.SubprocessRunnerConfig{
+		EnvVars:  envVars,
+		Binary:   binary,
+		Resource: id,
+		Output:   os.Stdout,
+	}
+	return runner.NewSubprocessRunner(subprocessConfig), nil
+}
+
 func (k *MemoryJobSpawner) GetJobRunner(jobName string, config runner.Config, etcdEndpoints []string, id metadata.ResourceID) (runner.Runner, error) {
 	jobRunner, err := runner.Create(jobName, config)
 	if err != nil {
@@ -141,6 +525,9 @@ func NewCoordinator(meta *metadata.Client, logger *zap.SugaredLogger, cli *clien
 		KVClient:   &kvc,
 		Spawner:    spawner,
 		Timeout:    60,
+		Retry:      DefaultRetryPolicy,
+		LockTTL:    DefaultLockTTL,
+		shutdown:   newShutdown(),
 	}, nil
 }
 
@@ -148,33 +535,64 @@ const MAX_ATTEMPTS = 20
 
 func (c *Coordinator) WatchForNewJobs() error {
 	c.Logger.Info("Watching for new jobs")
-	getResp, err := (*c.KVClient).Get(context.Background(), "JOB_", clientv3.WithPrefix())
-	if err != nil {
-		return fmt.Errorf("get existing etcd jobs: %w", err)
-	}
-	for _, kv := range getResp.Kvs {
-		go func(kv *mvccpb.KeyValue) {
-			err := c.ExecuteJob(string(kv.Key))
-			if err != nil {
-				c.Logger.Errorw("Error executing job: Initial search", "error", err)
+	claims := c.shutdown.claims
+	for claims.Err() == nil {
+		subscription, cancel := context.WithCancel(claims)
+		subscribed := make(chan struct{})
+		done := make(chan error, 1)
+		go func() {
+			done <- c.jobQueue().Subscribe(subscription, func() { close(subscribed) }, func(key string) {
+				go func() {
+					err := c.ExecuteJob(key)
+					if err != nil {
+						c.Logger.Errorw("Error executing job: Polling search", "error", err)
+					}
+				}()
+			})
+		}()
+		var err error
+		select {
+		case <-subscribed:
+			// Jobs queued before the coordinator subscribed, or while it had
+			// lost its connection to the queue, are found in etcd. They're
+			// only listed once it's subscribed, so that a job queued in
+			// between is seen at least once.
+			_, listErr := c.listJobKeys(claims, func(key string) {
+				go func() {
+					err := c.ExecuteJob(key)
+					if err != nil {
+						c.Logger.Errorw("Error executing job: Initial search", "error", err)
+					}
+				}()
+			})
+			if listErr != nil {
+				cancel()
+				<-done
+				return fmt.Errorf("get existing etcd jobs: %w", listErr)
 			}
-		}(kv)
+			err = <-done
+		case err = <-done:
+		}
+		cancel()
+		if err != nil && claims.Err() == nil {
+			c.Logger.Errorw("Lost job queue subscription", "error", err)
+			time.Sleep(time.Second)
+		}
 	}
-	for {
-		rch := c.EtcdClient.Watch(context.Background(), "JOB_", clientv3.WithPrefix())
-		for wresp := range rch {
-			for _, ev := range wresp.Events {
-				if ev.Type == 0 {
-					go func(ev *clientv3.Event) {
-						err := c.ExecuteJob(string(ev.Kv.Key))
-						if err != nil {
-							c.Logger.Errorw("Error executing job: Polling search", "error", err)
-						}
-					}(ev)
-				}
+	return nil
+}
 
-			}
-		}
+func (c *Coordinator) jobQueue() queue.Queue {
+	if c.Queue == nil {
+		return queue.NewEtcdQueue(c.EtcdClient)
+	}
+	return c.Queue
+}
+
+// publishJob announces a job that was written to etcd under key.
+func (c *Coordinator) publishJob(ctx context.Context, key string) error {
+	if err := c.jobQueue().Publish(ctx, key); err != nil {
+		return fmt.Errorf("publish job %s: %w", key, err)
 	}
 	return nil
 }
@@ -236,31 +654,56 @@ func (c *Coordinator) WatchForScheduleChanges() error {
 func (c *Coordinator) mapNameVariantsToTables(sources []metadata.NameVariant) (map[string]string, error) {
 	sourceMap := make(map[string]string)
 	for _, nameVariant := range sources {
-		var tableName string
-		source, err := c.Metadata.GetSourceVariant(context.Background(), nameVariant)
+		source, err := c.store().GetSourceVariant(context.Background(), nameVariant)
 		if err != nil {
 			return nil, err
 		}
 		if source.Status() != metadata.READY {
-			return nil, fmt.Errorf("source in query not ready")
+			return nil, metadata.WithErrorCode(metadata.ErrorSourceNotReady, fmt.Errorf("source in query not ready"))
 		}
-		providerResourceID := provider.ResourceID{Name: source.Name(), Variant: source.Variant()}
-		if source.IsSQLTransformation() {
-			tableName, err = provider.GetTransformationName(providerResourceID)
-			if err != nil {
-				return nil, err
-			}
-		} else if source.IsPrimaryDataSQLTable() {
-			tableName, err = provider.GetPrimaryTableName(providerResourceID)
-			if err != nil {
-				return nil, err
-			}
+		tableName, err := sourceTableName(source)
+		if err != nil {
+			return nil, err
 		}
 		sourceMap[nameVariant.ClientString()] = tableName
 	}
 	return sourceMap, nil
 }
 
+// sourceTableName returns the table a source's data is in, which a
+// transformation's query reads it from.
+func sourceTableName(source *metadata.SourceVariant) (string, error) {
+	providerResourceID := provider.ResourceID{Name: source.Name(), Variant: source.Variant()}
+	if source.IsSQLTransformation() {
+		return provider.GetTransformationName(providerResourceID)
+	} else if source.IsPrimaryDataSQLTable() {
+		return provider.GetPrimaryTableName(providerResourceID)
+	}
+	return "", nil
+}
+
+// resourceSchema is the schema of a feature's or label's resource table,
+// which is read from srcName. The resources of a slowly changing dimension
+// source take their timestamps from its valid from column, so that each time
+// gets the value of the row that was valid then.
+func resourceSchema(source *metadata.SourceVariant, columns metadata.ResourceVariantColumns, srcName string) (provider.ResourceSchema, error) {
+	schema := provider.ResourceSchema{
+		Entity:      columns.Entity,
+		Value:       columns.Value,
+		TS:          columns.TS,
+		SourceTable: srcName,
+	}
+	scd := source.SlowlyChanging()
+	if scd == nil {
+		return schema, nil
+	}
+	if columns.TS != "" && columns.TS != scd.ValidFrom {
+		return provider.ResourceSchema{}, fmt.Errorf("timestamp column %s must be the valid from column %s of slowly changing dimension %s (%s)", columns.TS, scd.ValidFrom, source.Name(), source.Variant())
+	}
+	schema.TS, schema.ValidTo = scd.ValidFrom, scd.ValidTo
+	return schema, nil
+}
+
 func sanitize(ident string) string {
 	return db.Identifier{ident}.Sanitize()
 }
@@ -271,7 +714,7 @@ func (c *Coordinator) runSQLTransformationJob(transformSource *metadata.SourceVa
 	sources := transformSource.SQLTransformationSources()
 	allReady := false
 	for !allReady {
-		sourceVariants, err := c.Metadata.GetSourceVariants(context.Background(), sources)
+		sourceVariants, err := c.store().GetSourceVariants(context.Background(), sources)
 		if err != nil {
 			return fmt.Errorf("get source variant: %w ", err)
 		}
@@ -282,7 +725,7 @@ func (c *Coordinator) runSQLTransformationJob(transformSource *metadata.SourceVa
 				totalReady += 1
 			}
 			if sourceVariant.Status() == metadata.FAILED {
-				return fmt.Errorf("dependent source variant failed")
+				return permanent(metadata.WithErrorCode(metadata.ErrorSourceNotReady, fmt.Errorf("dependent source variant failed")))
 			}
 		}
 		allReady = total == totalReady
@@ -291,18 +734,23 @@ func (c *Coordinator) runSQLTransformationJob(transformSource *metadata.SourceVa
 	if err != nil {
 		return fmt.Errorf("map name: %w sources: %v", err, sources)
 	}
-	query, err := templateReplace(templateString, sourceMap)
+	query, err := templateReplace(templateString, sourceMap, transformSource.SQLTransformationParameters(), providerSyntax(sourceProvider))
 	if err != nil {
 		return fmt.Errorf("template replace: %w source map: %v, template: %s", err, sourceMap, templateString)
 	}
 	c.Logger.Debugw("Created transformation query", "query", query)
 	providerResourceID := provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Transformation}
-	transformationConfig := provider.TransformationConfig{TargetTableID: providerResourceID, Query: query}
+	transformationConfig := provider.TransformationConfig{TargetTableID: providerResourceID, Query: query, Storage: transformationStorage(transformSource)}
+	offlineConfig, err := c.runnerProviderConfig(sourceProvider)
+	if err != nil {
+		return err
+	}
 	createTransformationConfig := runner.CreateTransformationConfig{
 		OfflineType:          provider.Type(sourceProvider.Type()),
-		OfflineConfig:        sourceProvider.SerializedConfig(),
+		OfflineConfig:        offlineConfig,
 		TransformationConfig: transformationConfig,
 		IsUpdate:             false,
+		Schedule:             schedule,
 	}
 	c.Logger.Debugw("Transformation Serialize Config")
 	serialized, err := createTransformationConfig.Serialize()
@@ -310,47 +758,46 @@ func (c *Coordinator) runSQLTransformationJob(transformSource *metadata.SourceVa
 		return fmt.Errorf("serialize transformation config: %w", err)
 	}
 	c.Logger.Debugw("Transformation Get Job Runner")
-	jobRunner, err := c.Spawner.GetJobRunner(runner.CREATE_TRANSFORMATION, serialized, c.EtcdClient.Endpoints(), resID)
+	jobRunner, err := c.Spawner.GetJobRunner(runner.CREATE_TRANSFORMATION, serialized, c.etcdEndpoints(), resID)
 	if err != nil {
 		return fmt.Errorf("spawn create transformation job runner: %w", err)
 	}
 	c.Logger.Debugw("Transformation Run Job")
-	completionWatcher, err := jobRunner.Run()
+	completionWatcher, err := runner.RunWithContext(c.jobContext(resID), jobRunner)
 	if err != nil {
 		return fmt.Errorf("run transformation job runner: %w", err)
 	}
 	c.Logger.Debugw("Transformation Waiting For Completion")
-	if err := completionWatcher.Wait(); err != nil {
+	if err := c.waitReportingProgress(c.jobContext(resID), resID, completionWatcher); err != nil {
 		return fmt.Errorf("wait for transformation job runner completion: %w", err)
 	}
 	c.Logger.Debugw("Transformation Setting Status")
-	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+	if err := c.store().SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 		return fmt.Errorf("set transformation job runner done status: %w", err)
 	}
+	c.recordStats(resID)
 	c.Logger.Debugw("Transformation Complete")
 	if schedule != "" {
 		scheduleCreateTransformationConfig := runner.CreateTransformationConfig{
 			OfflineType:          provider.Type(sourceProvider.Type()),
-			OfflineConfig:        sourceProvider.SerializedConfig(),
+			OfflineConfig:        offlineConfig,
 			TransformationConfig: transformationConfig,
 			IsUpdate:             true,
+			Schedule:             schedule,
 		}
 		serializedUpdate, err := scheduleCreateTransformationConfig.Serialize()
 		if err != nil {
 			return fmt.Errorf("serialize schedule transformation config: %w", err)
 		}
-		jobRunnerUpdate, err := c.Spawner.GetJobRunner(runner.CREATE_TRANSFORMATION, serializedUpdate, c.EtcdClient.Endpoints(), resID)
+		jobRunnerUpdate, err := c.Spawner.GetJobRunner(runner.CREATE_TRANSFORMATION, serializedUpdate, c.etcdEndpoints(), resID)
 		if err != nil {
 			return fmt.Errorf("run ransformation schedule job runner: %w", err)
 		}
-		cronRunner, isCronRunner := jobRunnerUpdate.(runner.CronRunner)
-		if !isCronRunner {
-			return fmt.Errorf("kubernetes runner does not implement schedule")
-		}
+		cronRunner := c.cronRunner(jobRunnerUpdate, runner.CREATE_TRANSFORMATION, serializedUpdate, resID)
 		if err := cronRunner.ScheduleJob(runner.CronSchedule(schedule)); err != nil {
-			return fmt.Errorf("schedule transformation job in kubernetes: %w", err)
+			return fmt.Errorf("schedule transformation job: %w", err)
 		}
-		if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+		if err := c.store().SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 			return fmt.Errorf("set transformation succesful schedule status: %w", err)
 		}
 	}
@@ -364,29 +811,34 @@ func (c *Coordinator) runPrimaryTableJob(transformSource *metadata.SourceVariant
 	if sourceName == "" {
 		return fmt.Errorf("no source name set")
 	}
-	if _, err := offlineStore.RegisterPrimaryFromSourceTable(providerResourceID, sourceName); err != nil {
+	_, err := offlineStore.RegisterPrimaryFromSourceTable(providerResourceID, sourceName)
+	if err != nil {
 		return fmt.Errorf("register primary table from source table in offline store: %w", err)
 	}
-	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+	if err := c.store().SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 		return fmt.Errorf("set done status for registering primary table: %w", err)
 	}
+	c.recordStats(resID)
 	return nil
 }
 
 func (c *Coordinator) runRegisterSourceJob(resID metadata.ResourceID, schedule string) error {
 	c.Logger.Info("Running register source job on resource: ", resID)
-	source, err := c.Metadata.GetSourceVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
+	source, err := c.store().GetSourceVariant(context.Background(), metadata.NameVariant{Name: resID.Name, Variant: resID.Variant})
 	if err != nil {
 		return fmt.Errorf("get source variant from metadata: %w", err)
 	}
-	sourceProvider, err := source.FetchProvider(c.Metadata, context.Background())
+	sourceProvider, err := c.store().GetProvider(context.Background(), source.Provider())
 	if err != nil {
 		return fmt.Errorf("fetch source's dependent provider in metadata: %w", err)
 	}
-	p, err := provider.Get(provider.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
+	p, err := c.providers().Get(provider.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
 	if err != nil {
 		return fmt.Errorf("get source's dependent provider in offline store: %w", err)
 	}
+	if _, isStream := p.(provider.StreamingSource); isStream {
+		return c.runStreamSourceJob(source, resID)
+	}
 	sourceStore, err := p.AsOfflineStore()
 	if err != nil {
 		return fmt.Errorf("convert source provider to offline store interface: %w", err)
@@ -396,21 +848,21 @@ func (c *Coordinator) runRegisterSourceJob(resID metadata.ResourceID, schedule s
 	} else if source.IsPrimaryDataSQLTable() {
 		return c.runPrimaryTableJob(source, resID, sourceStore, schedule)
 	} else {
-		return fmt.Errorf("source type not implemented")
+		return permanent(fmt.Errorf("source type not implemented"))
 	}
 }
 
 func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule string) error {
 	c.Logger.Info("Running label register job: ", resID)
-	label, err := c.Metadata.GetLabelVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
+	label, err := c.store().GetLabelVariant(context.Background(), metadata.NameVariant{Name: resID.Name, Variant: resID.Variant})
 	if err != nil {
 		return fmt.Errorf("get label variant: %w", err)
 	}
 	status := label.Status()
 	if status == metadata.READY {
-		return fmt.Errorf("feature already set to %s", status.String())
+		return permanent(fmt.Errorf("feature already set to %s", status.String()))
 	}
-	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.PENDING, ""); err != nil {
+	if err := c.store().SetStatus(context.Background(), resID, metadata.PENDING, ""); err != nil {
 		return fmt.Errorf("set pending status for label variant: %w", err)
 	}
 
@@ -421,11 +873,11 @@ func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule st
 	if err != nil {
 		return fmt.Errorf("source of could not complete job: %w", err)
 	}
-	sourceProvider, err := source.FetchProvider(c.Metadata, context.Background())
+	sourceProvider, err := c.store().GetProvider(context.Background(), source.Provider())
 	if err != nil {
 		return fmt.Errorf("could not fetch online provider: %w", err)
 	}
-	p, err := provider.Get(provider.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
+	p, err := c.providers().Get(provider.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
 	if err != nil {
 		return fmt.Errorf("could not get offline provider config: %w", err)
 	}
@@ -447,12 +899,13 @@ func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule st
 		Variant: resID.Variant,
 		Type:    provider.Label,
 	}
-	tmpSchema := label.LocationColumns().(metadata.ResourceVariantColumns)
-	schema := provider.ResourceSchema{
-		Entity:      tmpSchema.Entity,
-		Value:       tmpSchema.Value,
-		TS:          tmpSchema.TS,
-		SourceTable: srcName,
+	columns := label.LocationColumns().(metadata.ResourceVariantColumns)
+	schema, err := resourceSchema(source, columns, srcName)
+	if err != nil {
+		return permanent(err)
+	}
+	if err := c.validateResourceSchema(sourceStore, source, columnUser{resID, label.Type(), columns}); err != nil {
+		return err
 	}
 	c.Logger.Debugw("Creating Label Resource Table", "id", labelID, "schema", schema)
 	_, err = sourceStore.RegisterResourceFromSourceTable(labelID, schema)
@@ -460,25 +913,84 @@ func (c *Coordinator) runLabelRegisterJob(resID metadata.ResourceID, schedule st
 		return fmt.Errorf("register from source: %w", err)
 	}
 	c.Logger.Debugw("Resource Table Created", "id", labelID, "schema", schema)
+	if label.ServedOnline() {
+		if err := c.materializeLabel(resID, label, sourceProvider); err != nil {
+			return err
+		}
+	}
 
-	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+	if err := c.store().SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 		return fmt.Errorf("set ready status for label variant: %w", err)
 	}
 	return nil
 }
 
+// materializeLabel copies the latest value of a label for each entity to
+// its online store, the same way features are materialized.
+func (c *Coordinator) materializeLabel(resID metadata.ResourceID, label *metadata.LabelVariant, sourceProvider *metadata.Provider) error {
+	labelProvider, err := c.store().GetProvider(context.Background(), label.OnlineProvider())
+	if err != nil {
+		return fmt.Errorf("could not fetch label online provider: %w", err)
+	}
+	onlineConfig, err := c.runnerProviderConfig(labelProvider)
+	if err != nil {
+		return err
+	}
+	offlineConfig, err := c.runnerProviderConfig(sourceProvider)
+	if err != nil {
+		return err
+	}
+	materializedRunnerConfig := runner.MaterializedRunnerConfig{
+		OnlineType:    provider.Type(labelProvider.Type()),
+		OfflineType:   provider.Type(sourceProvider.Type()),
+		OnlineConfig:  onlineConfig,
+		OfflineConfig: offlineConfig,
+		ResourceID:    provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Label},
+		VType:         provider.ValueType(label.Type()),
+		Cloud:         runner.LocalMaterializeRunner,
+		ChunkSizing:   c.ChunkSizing,
+		Parallelism:   c.MaterializeParallelism,
+		BulkLoadURI:   c.BulkLoadURI,
+		Lineage:       c.jobLineage(resID),
+	}
+	serialized, err := materializedRunnerConfig.Serialize()
+	if err != nil {
+		return fmt.Errorf("serialize label materialize config: %w", err)
+	}
+	jobRunner, err := c.Spawner.GetJobRunner(runner.MATERIALIZE, serialized, c.etcdEndpoints(), resID)
+	if err != nil {
+		return fmt.Errorf("create label materialize runner: %w", err)
+	}
+	completionWatcher, err := runner.RunWithContext(c.jobContext(resID), jobRunner)
+	if err != nil {
+		return fmt.Errorf("run label materialize runner: %w", err)
+	}
+	if err := c.waitReportingProgress(c.jobContext(resID), resID, completionWatcher); err != nil {
+		return fmt.Errorf("wait for label materialize runner: %w", err)
+	}
+	return nil
+}
+
 func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedule string) error {
 	c.Logger.Info("Running feature materialization job on resource: ", resID)
-	feature, err := c.Metadata.GetFeatureVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
+	feature, err := c.store().GetFeatureVariant(context.Background(), metadata.NameVariant{Name: resID.Name, Variant: resID.Variant})
 	if err != nil {
 		return fmt.Errorf("get feature variant from metadata: %w", err)
 	}
 	status := feature.Status()
 	featureType := feature.Type()
 	if status == metadata.READY {
-		return fmt.Errorf("feature already set to %s", status.String())
+		// Streamed features are ready while their stream runs, so a stream
+		// that stopped is started again.
+		streamed, err := c.isStreamed(feature.Source())
+		if err != nil {
+			return err
+		}
+		if !streamed {
+			return permanent(fmt.Errorf("feature already set to %s", status.String()))
+		}
 	}
-	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.PENDING, ""); err != nil {
+	if err := c.store().SetStatus(context.Background(), resID, metadata.PENDING, ""); err != nil {
 		return fmt.Errorf("set feature variant status to pending: %w", err)
 	}
 
@@ -489,31 +1001,46 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 	if err != nil {
 		return fmt.Errorf("source of could not complete job: %w", err)
 	}
-	sourceProvider, err := source.FetchProvider(c.Metadata, context.Background())
+	sourceProvider, err := c.store().GetProvider(context.Background(), source.Provider())
 	if err != nil {
 		return fmt.Errorf("could not fetch online provider: %w", err)
 	}
-	p, err := provider.Get(provider.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
+	p, err := c.providers().Get(provider.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
 	if err != nil {
 		return err
 	}
+	if _, isStream := p.(provider.StreamingSource); isStream {
+		return c.runStreamToOnlineJob(feature, source, sourceProvider, resID)
+	}
 	sourceStore, err := p.AsOfflineStore()
 	if err != nil {
 		return err
 	}
-	featureProvider, err := feature.FetchProvider(c.Metadata, context.Background())
+	featureProvider, err := c.store().GetProvider(context.Background(), feature.Provider())
 	if err != nil {
 		return fmt.Errorf("could not fetch  onlineprovider: %w", err)
 	}
+	onlineConfig, err := c.runnerProviderConfig(featureProvider)
+	if err != nil {
+		return err
+	}
+	offlineConfig, err := c.runnerProviderConfig(sourceProvider)
+	if err != nil {
+		return err
+	}
 	materializedRunnerConfig := runner.MaterializedRunnerConfig{
 		OnlineType:    provider.Type(featureProvider.Type()),
 		OfflineType:   provider.Type(sourceProvider.Type()),
-		OnlineConfig:  featureProvider.SerializedConfig(),
-		OfflineConfig: sourceProvider.SerializedConfig(),
+		OnlineConfig:  onlineConfig,
+		OfflineConfig: offlineConfig,
 		ResourceID:    provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
 		VType:         provider.ValueType(featureType),
 		Cloud:         runner.LocalMaterializeRunner,
+		ChunkSizing:   c.ChunkSizing,
+		Parallelism:   c.MaterializeParallelism,
+		BulkLoadURI:   c.BulkLoadURI,
 		IsUpdate:      false,
+		Lineage:       c.jobLineage(resID),
 	}
 	serialized, err := materializedRunnerConfig.Serialize()
 	if err != nil {
@@ -533,12 +1060,13 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 		Variant: resID.Variant,
 		Type:    provider.Feature,
 	}
-	tmpSchema := feature.LocationColumns().(metadata.ResourceVariantColumns)
-	schema := provider.ResourceSchema{
-		Entity:      tmpSchema.Entity,
-		Value:       tmpSchema.Value,
-		TS:          tmpSchema.TS,
-		SourceTable: srcName,
+	columns := feature.LocationColumns().(metadata.ResourceVariantColumns)
+	schema, err := resourceSchema(source, columns, srcName)
+	if err != nil {
+		return permanent(err)
+	}
+	if err := c.validateResourceSchema(sourceStore, source, columnUser{resID, featureType, columns}); err != nil {
+		return err
 	}
 	c.Logger.Debugw("Creating Resource Table", "id", featID, "schema", schema)
 	_, err = sourceStore.RegisterResourceFromSourceTable(featID, schema)
@@ -547,47 +1075,49 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 	}
 	c.Logger.Debugw("Resource Table Created", "id", featID, "schema", schema)
 	c.Logger.Info("Starting Materialize")
-	jobRunner, err := c.Spawner.GetJobRunner(runner.MATERIALIZE, serialized, c.EtcdClient.Endpoints(), resID)
+	jobRunner, err := c.Spawner.GetJobRunner(runner.MATERIALIZE, serialized, c.etcdEndpoints(), resID)
 	if err != nil {
 		return fmt.Errorf("could not use store as online store: %w", err)
 	}
-	completionWatcher, err := jobRunner.Run()
+	completionWatcher, err := runner.RunWithContext(c.jobContext(resID), jobRunner)
 	if err != nil {
 		return fmt.Errorf("creating watcher for completion runner: %w", err)
 	}
-	if err := completionWatcher.Wait(); err != nil {
+	if err := c.waitReportingProgress(c.jobContext(resID), resID, completionWatcher); err != nil {
 		return fmt.Errorf("completion watcher running: %w", err)
 	}
-	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+	if err := c.store().SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 		return fmt.Errorf("materialize set success: %w", err)
 	}
+	c.recordStats(resID)
 	if schedule != "" {
 		scheduleMaterializeRunnerConfig := runner.MaterializedRunnerConfig{
 			OnlineType:    provider.Type(featureProvider.Type()),
 			OfflineType:   provider.Type(sourceProvider.Type()),
-			OnlineConfig:  featureProvider.SerializedConfig(),
-			OfflineConfig: sourceProvider.SerializedConfig(),
+			OnlineConfig:  onlineConfig,
+			OfflineConfig: offlineConfig,
 			ResourceID:    provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
 			VType:         provider.ValueType(featureType),
 			Cloud:         runner.LocalMaterializeRunner,
+			ChunkSizing:   c.ChunkSizing,
+			Parallelism:   c.MaterializeParallelism,
+			BulkLoadURI:   c.BulkLoadURI,
 			IsUpdate:      true,
+			Schedule:      schedule,
 		}
 		serializedUpdate, err := scheduleMaterializeRunnerConfig.Serialize()
 		if err != nil {
 			return fmt.Errorf("serialize materialize runner config: %w", err)
 		}
-		jobRunnerUpdate, err := c.Spawner.GetJobRunner(runner.MATERIALIZE, serializedUpdate, c.EtcdClient.Endpoints(), resID)
+		jobRunnerUpdate, err := c.Spawner.GetJobRunner(runner.MATERIALIZE, serializedUpdate, c.etcdEndpoints(), resID)
 		if err != nil {
 			return fmt.Errorf("creating materialize job schedule job runner: %w", err)
 		}
-		cronRunner, isCronRunner := jobRunnerUpdate.(runner.CronRunner)
-		if !isCronRunner {
-			return fmt.Errorf("kubernetes runner does not implement schedule")
-		}
+		cronRunner := c.cronRunner(jobRunnerUpdate, runner.MATERIALIZE, serializedUpdate, resID)
 		if err := cronRunner.ScheduleJob(runner.CronSchedule(schedule)); err != nil {
-			return fmt.Errorf("schedule materialize job in kubernetes: %w", err)
+			return fmt.Errorf("schedule materialize job: %w", err)
 		}
-		if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+		if err := c.store().SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 			return fmt.Errorf("set succesful update status for materialize job in kubernetes: %w", err)
 		}
 	}
@@ -596,22 +1126,25 @@ func (c *Coordinator) runFeatureMaterializeJob(resID metadata.ResourceID, schedu
 
 func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule string) error {
 	c.Logger.Info("Running training set job on resource: ", resID)
-	ts, err := c.Metadata.GetTrainingSetVariant(context.Background(), metadata.NameVariant{resID.Name, resID.Variant})
+	ts, err := c.store().GetTrainingSetVariant(context.Background(), metadata.NameVariant{Name: resID.Name, Variant: resID.Variant})
 	if err != nil {
 		return fmt.Errorf("fetch training set variant from metadata: %w", err)
 	}
 	status := ts.Status()
 	if status == metadata.READY {
-		return fmt.Errorf("training Set already set to %s", status.String())
+		return permanent(fmt.Errorf("training Set already set to %s", status.String()))
+	}
+	if err := c.verifyPins(context.Background(), ts); err != nil {
+		return err
 	}
-	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.PENDING, ""); err != nil {
+	if err := c.store().SetStatus(context.Background(), resID, metadata.PENDING, ""); err != nil {
 		return fmt.Errorf("set training set variant status to pending: %w", err)
 	}
-	providerEntry, err := ts.FetchProvider(c.Metadata, context.Background())
+	providerEntry, err := c.store().GetProvider(context.Background(), ts.Provider())
 	if err != nil {
 		return fmt.Errorf("fetch training set variant offline provider: %w", err)
 	}
-	p, err := provider.Get(provider.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	p, err := c.providers().Get(provider.Type(providerEntry.Type()), providerEntry.SerializedConfig())
 	if err != nil {
 		return fmt.Errorf("fetch offline store interface of training set provider: %w", err)
 	}
@@ -621,86 +1154,128 @@ func (c *Coordinator) runTrainingSetJob(resID metadata.ResourceID, schedule stri
 	}
 	providerResID := provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.TrainingSet}
 	if _, err := store.GetTrainingSet(providerResID); err == nil {
-		return fmt.Errorf("training set already exists: %w", err)
+		return permanent(metadata.WithErrorCode(metadata.ErrorAlreadyExists, fmt.Errorf("training set already exists: %w", err)))
 	}
 	features := ts.Features()
 	featureList := make([]provider.ResourceID, len(features))
 	for i, feature := range features {
 		featureList[i] = provider.ResourceID{Name: feature.Name, Variant: feature.Variant, Type: provider.Feature}
-		featureResource, err := c.Metadata.GetFeatureVariant(context.Background(), feature)
+		featureResource, err := c.store().GetFeatureVariant(context.Background(), feature)
 		if err != nil {
 			return fmt.Errorf("failed to get fetch dependent feature: %w", err)
 		}
 		sourceNameVariant := featureResource.Source()
 		_, err = c.AwaitPendingSource(sourceNameVariant)
 		if err != nil {
-			return fmt.Errorf("source of feature could not complete job: %v", err)
+			return fmt.Errorf("source of feature could not complete job: %w", err)
 		}
 	}
-	label, err := ts.FetchLabel(c.Metadata, context.Background())
+	label, err := c.store().GetLabelVariant(context.Background(), ts.Label())
 	if err != nil {
 		return fmt.Errorf("fetch training set label: %w", err)
 	}
 	labelSourceNameVariant := label.Source()
 	_, err = c.AwaitPendingSource(labelSourceNameVariant)
 	if err != nil {
-		return fmt.Errorf("source of label could not complete job: %v", err)
+		return fmt.Errorf("source of label could not complete job: %w", err)
 	}
 	trainingSetDef := provider.TrainingSetDef{
 		ID:       providerResID,
 		Label:    provider.ResourceID{Name: label.Name(), Variant: label.Variant(), Type: provider.Label},
 		Features: featureList,
 	}
+	if export := ts.Export(); export != nil {
+		trainingSetDef.Export = &provider.TrainingSetExport{
+			URI:         export.URI,
+			Format:      provider.ExportFormat(export.Format),
+			RowsPerFile: export.RowsPerFile,
+		}
+	}
+	offlineConfig, err := c.runnerProviderConfig(providerEntry)
+	if err != nil {
+		return err
+	}
 	tsRunnerConfig := runner.TrainingSetRunnerConfig{
 		OfflineType:   provider.Type(providerEntry.Type()),
-		OfflineConfig: providerEntry.SerializedConfig(),
+		OfflineConfig: offlineConfig,
 		Def:           trainingSetDef,
 		IsUpdate:      false,
 	}
 	serialized, _ := tsRunnerConfig.Serialize()
-	jobRunner, err := c.Spawner.GetJobRunner(runner.CREATE_TRAINING_SET, serialized, c.EtcdClient.Endpoints(), resID)
+	jobRunner, err := c.Spawner.GetJobRunner(runner.CREATE_TRAINING_SET, serialized, c.etcdEndpoints(), resID)
 	if err != nil {
 		return fmt.Errorf("create training set job runner: %w", err)
 	}
-	completionWatcher, err := jobRunner.Run()
+	completionWatcher, err := runner.RunWithContext(c.jobContext(resID), jobRunner)
 	if err != nil {
 		return fmt.Errorf("start training set job runner: %w", err)
 	}
-	if err := completionWatcher.Wait(); err != nil {
+	if err := c.waitReportingProgress(c.jobContext(resID), resID, completionWatcher); err != nil {
 		return fmt.Errorf("wait for training set job runner completion: %w", err)
 	}
-	if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+	if trainingSetDef.Export != nil {
+		if err := c.runExportTrainingSetJob(resID, providerEntry, offlineConfig, trainingSetDef); err != nil {
+			return err
+		}
+	}
+	if err := c.store().SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 		return fmt.Errorf("set training set job runner status: %w", err)
 	}
+	c.recordTrainingSetFreshness(resID)
 	if schedule != "" {
 		scheduleTrainingSetRunnerConfig := runner.TrainingSetRunnerConfig{
 			OfflineType:   provider.Type(providerEntry.Type()),
-			OfflineConfig: providerEntry.SerializedConfig(),
+			OfflineConfig: offlineConfig,
 			Def:           trainingSetDef,
 			IsUpdate:      true,
+			Schedule:      schedule,
 		}
 		serializedUpdate, err := scheduleTrainingSetRunnerConfig.Serialize()
 		if err != nil {
 			return fmt.Errorf("serialize training set schedule runner config: %w", err)
 		}
-		jobRunnerUpdate, err := c.Spawner.GetJobRunner(runner.CREATE_TRAINING_SET, serializedUpdate, c.EtcdClient.Endpoints(), resID)
+		jobRunnerUpdate, err := c.Spawner.GetJobRunner(runner.CREATE_TRAINING_SET, serializedUpdate, c.etcdEndpoints(), resID)
 		if err != nil {
 			return fmt.Errorf("spawn training set job runner: %w", err)
 		}
-		cronRunner, isCronRunner := jobRunnerUpdate.(runner.CronRunner)
-		if !isCronRunner {
-			return fmt.Errorf("kubernetes runner does not implement schedule")
-		}
+		cronRunner := c.cronRunner(jobRunnerUpdate, runner.CREATE_TRAINING_SET, serializedUpdate, resID)
 		if err := cronRunner.ScheduleJob(runner.CronSchedule(schedule)); err != nil {
-			return fmt.Errorf("schedule training set job in kubernetes: %w", err)
+			return fmt.Errorf("schedule training set job: %w", err)
 		}
-		if err := c.Metadata.SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+		if err := c.store().SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
 			return fmt.Errorf("update training set scheduler job status: %w", err)
 		}
 	}
 	return nil
 }
 
+// runExportTrainingSetJob writes a training set that's just been created to
+// the files of its Def's Export. Scheduled updates rewrite them themselves.
+func (c *Coordinator) runExportTrainingSetJob(resID metadata.ResourceID, providerEntry *metadata.Provider, offlineConfig provider.SerializedConfig, def provider.TrainingSetDef) error {
+	exportConfig := runner.ExportTrainingSetRunnerConfig{
+		OfflineType:   provider.Type(providerEntry.Type()),
+		OfflineConfig: offlineConfig,
+		Def:           def,
+	}
+	serialized, err := exportConfig.Serialize()
+	if err != nil {
+		return fmt.Errorf("serialize export training set runner config: %w", err)
+	}
+	c.Logger.Infow("Exporting training set", "resource", resID, "uri", def.Export.URI, "format", def.Export.Format)
+	jobRunner, err := c.Spawner.GetJobRunner(runner.EXPORT_TRAINING_SET, serialized, c.etcdEndpoints(), resID)
+	if err != nil {
+		return fmt.Errorf("create export training set job runner: %w", err)
+	}
+	completionWatcher, err := runner.RunWithContext(c.jobContext(resID), jobRunner)
+	if err != nil {
+		return fmt.Errorf("start export training set job runner: %w", err)
+	}
+	if err := c.waitReportingProgress(c.jobContext(resID), resID, completionWatcher); err != nil {
+		return fmt.Errorf("wait for export training set job runner completion: %w", err)
+	}
+	return nil
+}
+
 func (c *Coordinator) getJob(mtx *concurrency.Mutex, key string) (*metadata.CoordinatorJob, error) {
 	c.Logger.Debugf("Checking existence of job with key %s\n", key)
 	txn := (*c.KVClient).Txn(context.Background())
@@ -775,32 +1350,60 @@ func (c *Coordinator) hasJob(id metadata.ResourceID) (bool, error) {
 	return false, nil
 }
 
-func (c *Coordinator) createJobLock(jobKey string, s *concurrency.Session) (*concurrency.Mutex, error) {
+func (c *Coordinator) createJobLock(ctx context.Context, jobKey string, s *concurrency.Session) (*concurrency.Mutex, error) {
 	mtx := concurrency.NewMutex(s, GetLockKey(jobKey))
-	if err := mtx.Lock(context.Background()); err != nil {
+	if err := mtx.Lock(ctx); err != nil {
 		return nil, fmt.Errorf("create job lock in etcd with key %s: %w", GetLockKey(jobKey), err)
 	}
 	return mtx, nil
 }
 
 func (c *Coordinator) markJobFailed(job *metadata.CoordinatorJob) error {
-	if err := c.Metadata.SetStatus(context.Background(), job.Resource, metadata.FAILED, ""); err != nil {
+	if err := c.store().SetStatus(context.Background(), job.Resource, metadata.FAILED, ""); err != nil {
 		return fmt.Errorf("could not set job status to failed: %v", err)
 	}
 	return nil
 }
 
 func (c *Coordinator) ExecuteJob(jobKey string) error {
+	if !c.ownsJob(jobKey) {
+		c.Logger.Debugw("Job is owned by another coordinator", "job", jobKey)
+		return nil
+	}
+	// A job that's written again while it's claimed here, like when its
+	// attempts are counted, is left to the claim that's already running it.
+	if _, claiming := c.claiming.LoadOrStore(jobKey, true); claiming {
+		c.Logger.Debugw("Job is already claimed by this coordinator", "job", jobKey)
+		return nil
+	}
+	defer c.claiming.Delete(jobKey)
+	if err := c.waitToClaim(c.shutdown.claims); err != nil {
+		c.Logger.Infow("Shutting down, leaving job for another coordinator", "job", jobKey)
+		return nil
+	}
+	if !c.shutdown.start() {
+		c.Logger.Infow("Shutting down, leaving job for another coordinator", "job", jobKey)
+		return nil
+	}
+	defer c.shutdown.finish()
 	c.Logger.Info("Executing new job with key ", jobKey)
-	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(1))
+	// If this coordinator stops renewing the session, the lock is released
+	// after its TTL so that another coordinator can take over the job.
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(c.lockTTL()))
 	if err != nil {
 		return fmt.Errorf("new session: %w", err)
 	}
 	defer s.Close()
-	mtx, err := c.createJobLock(jobKey, s)
+	waitStart := time.Now()
+	mtx, err := c.createJobLock(c.shutdown.claims, jobKey, s)
+	if c.shutdown.claims.Err() != nil {
+		c.Logger.Infow("Shutting down, leaving job for another coordinator", "job", jobKey)
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("job lock: %w", err)
 	}
+	c.observeLockWait(time.Since(waitStart))
 	defer func() {
 		if err := mtx.Unlock(context.Background()); err != nil {
 			c.Logger.Debugw("Error unlocking mutex:", "error", err)
@@ -810,29 +1413,91 @@ func (c *Coordinator) ExecuteJob(jobKey string) error {
 	if err != nil {
 		return fmt.Errorf("get job: %w", err)
 	}
+	lock, err := c.MaintenanceLock(job.Resource)
+	if err != nil {
+		return err
+	}
+	if lock != nil {
+		// The job is left in place so that UnlockResource can run it.
+		c.Logger.Infow("Resource is locked for maintenance, job will run once it's unlocked", "job", jobKey, "reason", lock.Reason, "locked", lock.Locked)
+		return nil
+	}
 	c.Logger.Debugf("Job %s is on attempt %d", jobKey, job.Attempts)
 	if job.Attempts > MAX_ATTEMPTS {
 		return c.markJobFailed(job)
 	}
+	if job.Lineage == "" {
+		// It's written with the attempt count, so that a coordinator that
+		// takes the job over continues the same lineage.
+		job.Lineage = uuid.New().String()
+	}
 	if err := c.incrementJobAttempts(mtx, job, jobKey); err != nil {
 		return fmt.Errorf("increment attempt: %w", err)
 	}
-	type jobFunction func(metadata.ResourceID, string) error
-	fns := map[metadata.ResourceType]jobFunction{
-		metadata.TRAINING_SET_VARIANT: c.runTrainingSetJob,
-		metadata.FEATURE_VARIANT:      c.runFeatureMaterializeJob,
-		metadata.LABEL_VARIANT:        c.runLabelRegisterJob,
-		metadata.SOURCE_VARIANT:       c.runRegisterSourceJob,
+	c.jobLineages.Store(job.Resource, job.Lineage)
+	defer c.jobLineages.Delete(job.Resource)
+	jobType, err := jobTypeOf(job)
+	if err != nil {
+		return err
 	}
-	jobFunc, has := fns[job.Resource.Type]
-	if !has {
-		return fmt.Errorf("not a valid resource type for running jobs")
+	lease, interrupt := c.leaseContext(s)
+	defer interrupt()
+	ctx, cancel := context.WithCancel(lease)
+	defer cancel()
+	go c.watchCancellation(ctx, job.Resource, cancel)
+	c.jobContexts.Store(job.Resource, ctx)
+	defer c.jobContexts.Delete(job.Resource)
+	history, err := c.runWithRetries(ctx, job.Resource, job.Trigger, func() error {
+		// Jobs are scheduled on the cron expression a schedule runs on, which
+		// is what Kubernetes and the runners understand.
+		schedule, err := metadata.ParseSchedule(job.Schedule)
+		if err != nil {
+			return permanent(err)
+		}
+		if err := jobType.Schema.Validate(job.Config); err != nil {
+			return permanent(fmt.Errorf("invalid %s job config: %w", jobType.Name, err))
+		}
+		if err := c.awaitDependencies(ctx, job.Resource); err != nil {
+			return err
+		}
+		// Slots are taken after dependencies are ready, so queued jobs don't
+		// hold slots their dependencies need.
+		release, err := c.acquireJobSlot(ctx, job.Resource)
+		if err != nil {
+			return err
+		}
+		defer release()
+		run := Job{Resource: job.Resource, Schedule: schedule.Cron(), Config: job.Config}
+		return c.runWithRollback(ctx, jobType, run, func() error {
+			return c.runWithTimeout(ctx, job.Resource, func() error {
+				return jobType.Handler(ctx, c, run)
+			})
+		})
+	})
+	if errors.Is(err, errJobInterrupted) {
+		// The job and any cancellation request are left for the coordinator
+		// that takes it over.
+		c.Logger.Infow("Job interrupted, leaving it for another coordinator", "job", jobKey)
+		return nil
+	}
+	c.clearCancellation(job.Resource)
+	if errors.Is(err, errJobCancelled) {
+		c.Logger.Infow("Job cancelled", "job", jobKey)
+		// A cancelled job isn't run again until it's resubmitted.
+		if err := c.deleteJob(mtx, jobKey); err != nil {
+			return fmt.Errorf("job delete: %w", err)
+		}
+		return nil
 	}
-	if err := jobFunc(job.Resource, job.Schedule); err != nil {
-		statusErr := c.Metadata.SetStatus(context.Background(), job.Resource, metadata.FAILED, err.Error())
-		return fmt.Errorf("%s job failed: %v: %v", job.Resource.Type, err, statusErr)
+	if err != nil {
+		if dlErr := c.deadLetterJob(mtx, jobKey, job, history, err); dlErr != nil {
+			c.Logger.Errorw("Could not dead letter job", "job", jobKey, "error", dlErr)
+		}
+		c.notify(metadata.NotifyJobFailed, job.Resource, err.Error())
+		return fmt.Errorf("%s job failed: %w", job.Resource.Type, err)
 	}
 	c.Logger.Info("Succesfully executed job with key: ", jobKey)
+	c.notify(metadata.NotifyJobSucceeded, job.Resource, "job succeeded")
 	if err := c.deleteJob(mtx, jobKey); err != nil {
 		c.Logger.Debugw("Error deleting job", "error", err)
 		return fmt.Errorf("job delete: %w", err)
@@ -868,7 +1533,7 @@ func (c *Coordinator) signalResourceUpdate(key string, value string) error {
 		return fmt.Errorf("create new concurrency session for resource update job: %w", err)
 	}
 	defer s.Close()
-	mtx, err := c.createJobLock(key, s)
+	mtx, err := c.createJobLock(context.Background(), key, s)
 	if err != nil {
 		return fmt.Errorf("create lock on resource update job with key %s: %w", key, err)
 	}
@@ -881,9 +1546,13 @@ func (c *Coordinator) signalResourceUpdate(key string, value string) error {
 	if err := resUpdatedEvent.Deserialize(Config(value)); err != nil {
 		return fmt.Errorf("deserialize resource update event: %w", err)
 	}
-	if err := c.Metadata.SetStatus(context.Background(), resUpdatedEvent.ResourceID, metadata.READY, ""); err != nil {
+	if err := c.store().SetStatus(context.Background(), resUpdatedEvent.ResourceID, metadata.READY, ""); err != nil {
 		return fmt.Errorf("set resource update status: %w", err)
 	}
+	c.recordStats(resUpdatedEvent.ResourceID)
+	if err := c.refreshDownstream(context.Background(), resUpdatedEvent.ResourceID); err != nil {
+		c.Logger.Errorw("Could not refresh downstream resources", "resource", resUpdatedEvent.ResourceID, "error", err)
+	}
 	c.Logger.Info("Succesfully set update status for update job with key: ", key)
 	if err := c.deleteJob(mtx, key); err != nil {
 		return fmt.Errorf("delete resource update job: %w", err)
@@ -892,13 +1561,13 @@ func (c *Coordinator) signalResourceUpdate(key string, value string) error {
 }
 
 func (c *Coordinator) changeJobSchedule(key string, value string) error {
-	c.Logger.Info("Updating schedule of currently made cronjob in kubernetes: ", key)
+	c.Logger.Info("Updating schedule of currently made job: ", key)
 	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(1))
 	if err != nil {
 		return fmt.Errorf("create new concurrency session for resource update job: %w", err)
 	}
 	defer s.Close()
-	mtx, err := c.createJobLock(key, s)
+	mtx, err := c.createJobLock(context.Background(), key, s)
 	if err != nil {
 		return fmt.Errorf("create lock on resource update job with key %s: %w", key, err)
 	}
@@ -911,6 +1580,34 @@ func (c *Coordinator) changeJobSchedule(key string, value string) error {
 	if err := coordinatorScheduleJob.Deserialize(Config(value)); err != nil {
 		return fmt.Errorf("deserialize coordiantor schedule job: %w", err)
 	}
+	// An empty schedule removes it, along with any cron job that would
+	// otherwise keep running against the resource.
+	if strings.TrimSpace(coordinatorScheduleJob.Schedule) == "" {
+		if err := c.removeSchedule(context.Background(), coordinatorScheduleJob.Resource); err != nil {
+			return fmt.Errorf("remove schedule: %w", err)
+		}
+	} else {
+		rescheduled, err := c.rescheduleJob(context.Background(), coordinatorScheduleJob)
+		if err != nil {
+			return fmt.Errorf("update scheduled job: %w", err)
+		}
+		if !rescheduled {
+			if err := c.updateCronJob(coordinatorScheduleJob); err != nil {
+				return err
+			}
+		}
+	}
+	if err := c.store().SetStatus(context.Background(), coordinatorScheduleJob.Resource, metadata.READY, ""); err != nil {
+		return fmt.Errorf("set schedule job update status in metadata: %w", err)
+	}
+	c.Logger.Info("Succesfully updated schedule for job with key: ", key)
+	if err := c.deleteJob(mtx, key); err != nil {
+		return fmt.Errorf("delete update schedule job in etcd: %w", err)
+	}
+	return nil
+}
+
+func (c *Coordinator) updateCronJob(coordinatorScheduleJob *metadata.CoordinatorScheduleJob) error {
 	jobClient, err := runner.NewKubernetesJobClient(runner.GetCronJobName(coordinatorScheduleJob.Resource), runner.Namespace)
 	if err != nil {
 		return fmt.Errorf("create new kubernetes job client: %w", err)
@@ -919,16 +1616,17 @@ func (c *Coordinator) changeJobSchedule(key string, value string) error {
 	if err != nil {
 		return fmt.Errorf("fetch cron job from kuberentes with name %s: %w", runner.GetCronJobName(coordinatorScheduleJob.Resource), err)
 	}
-	cronJob.Spec.Schedule = coordinatorScheduleJob.Schedule
+	schedule, err := metadata.ParseSchedule(coordinatorScheduleJob.Schedule)
+	if err != nil {
+		return fmt.Errorf("parse new schedule: %w", err)
+	}
+	cronJob.Spec.Schedule = schedule.Cron()
+	// A paused schedule is suspended rather than deleted, so resuming it
+	// keeps the cron job's history.
+	suspend := coordinatorScheduleJob.Paused
+	cronJob.Spec.Suspend = &suspend
 	if _, err := jobClient.UpdateCronJob(cronJob); err != nil {
 		return fmt.Errorf("update kubernetes cron job: %w", err)
 	}
-	if err := c.Metadata.SetStatus(context.Background(), coordinatorScheduleJob.Resource, metadata.READY, ""); err != nil {
-		return fmt.Errorf("set schedule job update status in metadata: %w", err)
-	}
-	c.Logger.Info("Succesfully updated schedule for job in kubernetes with key: ", key)
-	if err := c.deleteJob(mtx, key); err != nil {
-		return fmt.Errorf("delete update schedule job in etcd: %w", err)
-	}
 	return nil
 }
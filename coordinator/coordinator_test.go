@@ -1,22 +1,35 @@
 package coordinator
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
 	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	re "github.com/avast/retry-go/v4"
+	"github.com/featureform/coordinator/mocks"
 	"github.com/featureform/metadata"
+	pb "github.com/featureform/metadata/proto"
 	"github.com/featureform/provider"
 	"github.com/featureform/runner"
 	"github.com/jackc/pgx/v4/pgxpool"
+	mvccpb "go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func createSafeUUID() string {
@@ -839,11 +852,14 @@ func TestRegisterSourceJobErrors(t *testing.T) {
 	}
 }
 
+// postgresSyntax quotes identifiers with double quotes, like sanitize.
+var postgresSyntax = provider.TypeQuerySyntax(provider.PostgresOffline)
+
 func TestTemplateReplace(t *testing.T) {
 	templateString := "Some example text {{name1.variant1}} and more {{name2.variant2}}"
 	replacements := map[string]string{"name1.variant1": "replacement1", "name2.variant2": "replacement2"}
 	correctString := "Some example text \"replacement1\" and more \"replacement2\""
-	result, err := templateReplace(templateString, replacements)
+	result, err := templateReplace(templateString, replacements, nil, postgresSyntax)
 	if err != nil {
 		t.Fatalf("template replace did not run correctly: %v", err)
 	}
@@ -856,13 +872,193 @@ func TestTemplateReplace(t *testing.T) {
 func TestTemplateReplaceError(t *testing.T) {
 	templateString := "Some example text {{name1.variant1}} and more {{name2.variant2}}"
 	wrongReplacements := map[string]string{"name1.variant1": "replacement1", "name3.variant3": "replacement2"}
-	_, err := templateReplace(templateString, wrongReplacements)
+	_, err := templateReplace(templateString, wrongReplacements, nil, postgresSyntax)
 	if err == nil {
 		t.Fatalf("template replace did not catch error: %v", err)
 	}
 
 }
 
+func TestTemplateReplaceSQL(t *testing.T) {
+	replacements := map[string]string{"a.v": "table_a", "b.v": "table_b"}
+	bigQuery := provider.QuerySyntax{IdentQuote: '`', BackslashEscapes: true}
+	cases := []struct {
+		name, template, expected string
+		syntax                   provider.QuerySyntax
+	}{
+		{"line comment", "SELECT * FROM {{a.v}} -- not {{ b.v }}\nWHERE x = 1", "SELECT * FROM \"table_a\" -- not {{ b.v }}\nWHERE x = 1", postgresSyntax},
+		{"block comment", "/* {{ {weird} */ SELECT * FROM {{ a.v }}", "/* {{ {weird} */ SELECT * FROM \"table_a\"", postgresSyntax},
+		{"string literal", "SELECT '{{a.v}}', 'it''s {' FROM {{b.v}}", "SELECT '{{a.v}}', 'it''s {' FROM \"table_b\"", postgresSyntax},
+		{"cte", "WITH x AS (SELECT * FROM {{a.v}}), y AS (SELECT * FROM {{b.v}}) SELECT * FROM x JOIN y USING (id)", "WITH x AS (SELECT * FROM \"table_a\"), y AS (SELECT * FROM \"table_b\") SELECT * FROM x JOIN y USING (id)", postgresSyntax},
+		{"statements", "SELECT 1 FROM {{a.v}}; SELECT ';' FROM {{b.v}};\n", "SELECT 1 FROM \"table_a\"; SELECT ';' FROM \"table_b\";\n", postgresSyntax},
+		{"dollar quotes", "SELECT $f$ {{a.v}} $f$, $$ ' $$ FROM {{b.v}} WHERE x = $1", "SELECT $f$ {{a.v}} $f$, $$ ' $$ FROM \"table_b\" WHERE x = $1", postgresSyntax},
+		{"escape string", "SELECT E'it\\'s {{a.v}}' FROM {{b.v}}", "SELECT E'it\\'s {{a.v}}' FROM \"table_b\"", postgresSyntax},
+		{"standard string", "SELECT 'C:\\' FROM {{b.v}}", "SELECT 'C:\\' FROM \"table_b\"", postgresSyntax},
+		{"backslash escapes", "SELECT 'it\\'s {{a.v}}', \"\\\"{{a.v}}\" FROM {{b.v}}", "SELECT 'it\\'s {{a.v}}', \"\\\"{{a.v}}\" FROM `table_b`", bigQuery},
+		{"backticks", "SELECT * FROM {{a.v}}", "SELECT * FROM `table_a`", bigQuery},
+	}
+	for _, c := range cases {
+		result, err := templateReplace(c.template, replacements, nil, c.syntax)
+		if err != nil {
+			t.Fatalf("%s: template replace failed: %v", c.name, err)
+		}
+		if result != c.expected {
+			t.Fatalf("%s: expected %q, got %q", c.name, c.expected, result)
+		}
+	}
+	for _, template := range []string{
+		"SELECT * FROM {{ {{a.v}} }}",
+		"SELECT * FROM {{a.v",
+		"SELECT * FROM {{a.v}} /* unclosed",
+		"SELECT 'unclosed FROM {{a.v}}",
+		"SELECT $f$ unclosed FROM {{a.v}}",
+	} {
+		if _, err := templateReplace(template, replacements, nil, postgresSyntax); err == nil {
+			t.Fatalf("template replace did not catch error in %q", template)
+		}
+	}
+	if quoted := provider.QuoteIdentifier(provider.PostgresOffline, "table_a"); quoted != `"table_a"` {
+		t.Fatalf("postgres quoted table as %s", quoted)
+	}
+}
+
+func TestTemplateReplaceFunctions(t *testing.T) {
+	replacements := map[string]string{"a.v": "table_a"}
+	params := map[string]string{"threshold": "0.5", "region": "o'hare"}
+	template := `SELECT * FROM {{ a.v }} WHERE score > {{ param "threshold" }} AND region = {{param "region"}} AND ts >= {{ schedule_start }}`
+	expected := `SELECT * FROM "table_a" WHERE score > 0.5 AND region = 'o''hare' AND ts >= {{ schedule_start }}`
+	result, err := templateReplace(template, replacements, params, postgresSyntax)
+	if err != nil {
+		t.Fatalf("template replace failed: %v", err)
+	}
+	if result != expected {
+		t.Fatalf("expected %q, got %q", expected, result)
+	}
+	numbers := map[string]string{"1": "1", "-2.5": "-2.5", ".5": ".5", "1e3": "1e3", "NaN": "'NaN'", "Inf": "'Inf'", "-Infinity": "'-Infinity'", "0x1p-2": "'0x1p-2'", "1_000": "'1_000'"}
+	for value, literal := range numbers {
+		result, err := templateReplace(`{{ param "n" }}`, replacements, map[string]string{"n": value}, postgresSyntax)
+		if err != nil {
+			t.Fatalf("template replace failed: %v", err)
+		}
+		if result != literal {
+			t.Fatalf("expected %s to be written as %s, got %s", value, literal, result)
+		}
+	}
+	for _, template := range []string{`{{ param "missing" }}`, `{{ param missing }}`} {
+		if _, err := templateReplace(template, replacements, params, postgresSyntax); err == nil {
+			t.Fatalf("template replace did not catch error in %q", template)
+		}
+	}
+}
+
+func TestSchemaMismatches(t *testing.T) {
+	schema := provider.TableSchema{Columns: []provider.TableColumn{
+		{Name: "user_id", ValueType: provider.String},
+		{Name: "balance", ValueType: provider.Int64},
+		{Name: "name", ValueType: provider.String},
+		{Name: "ts", ValueType: provider.Timestamp},
+	}}
+	feature := metadata.ResourceID{Name: "balance", Variant: "default", Type: metadata.FEATURE_VARIANT}
+	label := metadata.ResourceID{Name: "fraud", Variant: "default", Type: metadata.LABEL_VARIANT}
+	users := []columnUser{
+		{feature, string(provider.Float64), metadata.ResourceVariantColumns{Entity: "user_id", Value: "balance", TS: "ts"}},
+		{feature, string(provider.Int), metadata.ResourceVariantColumns{Entity: "USER_ID", Value: "name", TS: "name"}},
+		{label, string(provider.Bool), metadata.ResourceVariantColumns{Entity: "user_id", Value: "is_fraud"}},
+	}
+	mismatches := schemaMismatches(schema, users)
+	expected := []SchemaMismatch{
+		{Resource: feature, Column: "name", ExpectedType: string(provider.Int), ActualType: string(provider.String)},
+		{Resource: feature, Column: "name", ExpectedType: string(provider.Timestamp), ActualType: string(provider.String)},
+		{Resource: label, Column: "is_fraud", ExpectedType: string(provider.Bool)},
+	}
+	if !reflect.DeepEqual(mismatches, expected) {
+		t.Fatalf("Unexpected mismatches\nExpected: %v\nGot: %v", expected, mismatches)
+	}
+	err := &SchemaMismatchError{metadata.NameVariant{Name: "transactions", Variant: "default"}, mismatches}
+	if !strings.Contains(err.Error(), "column is_fraud does not exist") {
+		t.Fatalf("Error does not list missing column: %s", err)
+	}
+}
+
+type schemaPrimaryTable struct {
+	provider.PrimaryTable
+	schema provider.TableSchema
+}
+
+func (table schemaPrimaryTable) Schema() (provider.TableSchema, error) {
+	return table.schema, nil
+}
+
+type schemaOfflineStore struct {
+	provider.OfflineStore
+	table schemaPrimaryTable
+}
+
+func (store schemaOfflineStore) GetPrimaryTable(id provider.ResourceID) (provider.PrimaryTable, error) {
+	return store.table, nil
+}
+
+func TestValidateResourceSchema(t *testing.T) {
+	c := &Coordinator{Logger: zap.NewNop().Sugar()}
+	store := schemaOfflineStore{table: schemaPrimaryTable{schema: provider.TableSchema{Columns: []provider.TableColumn{
+		{Name: "user_id", ValueType: provider.String},
+		{Name: "balance", ValueType: provider.Int64},
+	}}}}
+	source := metadata.WrapSourceVariant(&pb.SourceVariant{Name: "transactions", Variant: "default"})
+	feature := metadata.ResourceID{Name: "balance", Variant: "default", Type: metadata.FEATURE_VARIANT}
+	matching := columnUser{feature, string(provider.Float64), metadata.ResourceVariantColumns{Entity: "user_id", Value: "balance"}}
+	if err := c.validateResourceSchema(store, source, matching); err != nil {
+		t.Fatalf("Matching feature failed validation: %s", err)
+	}
+	missing := columnUser{feature, string(provider.Float64), metadata.ResourceVariantColumns{Entity: "user_id", Value: "amount"}}
+	err := c.validateResourceSchema(store, source, missing)
+	if err == nil || re.IsRecoverable(err) || !strings.Contains(err.Error(), "column amount does not exist") {
+		t.Fatalf("Expected a permanent schema mismatch, got: %v", err)
+	}
+	// Sources without a readable table aren't checked.
+	if err := c.validateResourceSchema(provider.NewMemoryOfflineStore(), source, missing); err != nil {
+		t.Fatalf("Source without a table failed validation: %s", err)
+	}
+}
+
+func TestProbeStores(t *testing.T) {
+	offline := provider.NewMemoryOfflineStore()
+	online := provider.NewLocalOnlineStore()
+	for i := int64(1); i <= 2; i++ {
+		if err := probeOfflineStore(offline, i); err != nil {
+			t.Fatalf("Offline probe %d failed: %s", i, err)
+		}
+		if err := probeOnlineStore(online, i); err != nil {
+			t.Fatalf("Online probe %d failed: %s", i, err)
+		}
+	}
+	// A probe that failed after materializing leaves the materialization.
+	if _, err := offline.CreateMaterialization(provider.ResourceID{Name: probeResourceName, Variant: probeResourceVariant, Type: provider.Feature}); err != nil {
+		t.Fatalf("Failed to leave a stale materialization: %s", err)
+	}
+	if err := probeOfflineStore(offline, 3); err != nil {
+		t.Fatalf("Probe after a stale materialization failed: %s", err)
+	}
+}
+
+func TestProviderProbesWithMocks(t *testing.T) {
+	c, _, _, _ := newMockCoordinator()
+	c.Providers.(*mocks.Providers).Add(provider.PostgresOffline, provider.NewMemoryOfflineStore())
+	c.Providers.(*mocks.Providers).Add(provider.RedisOnline, provider.NewLocalOnlineStore())
+	probes, err := c.ProviderProbes()
+	if err != nil {
+		t.Fatalf("Failed to list probes: %s", err)
+	}
+	if len(probes) != 2 {
+		t.Fatalf("Expected a probe for each provider, got %d", len(probes))
+	}
+	for _, probe := range probes {
+		if err := probe.Run(context.Background()); err != nil {
+			t.Fatalf("Probe of %s failed: %s", probe.Target, err)
+		}
+	}
+}
+
 func TestCoordinatorCalls(t *testing.T) {
 	if testing.Short() {
 		return
@@ -1649,3 +1845,2108 @@ func testRegisterTransformationFromSource(addr string) error {
 
 	return nil
 }
+
+func TestMaintenanceLock(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	serv, addr := startServ(t)
+	defer serv.Stop()
+	coord, err := createNewCoordinator(addr)
+	if err != nil {
+		t.Fatalf("could not create new basic coordinator")
+	}
+	defer coord.Metadata.Close()
+	resID := metadata.ResourceID{Name: createSafeUUID(), Variant: "", Type: metadata.FEATURE_VARIANT}
+	if err := coord.LockResource(resID, "investigating bad data"); err != nil {
+		t.Fatalf("could not lock resource: %v", err)
+	}
+	lock, err := coord.MaintenanceLock(resID)
+	if err != nil || lock == nil || lock.Reason != "investigating bad data" {
+		t.Fatalf("could not get maintenance lock: %v, %v", lock, err)
+	}
+	job := &metadata.CoordinatorJob{Resource: resID}
+	serialized, err := job.Serialize()
+	if err != nil {
+		t.Fatalf("could not serialize job: %v", err)
+	}
+	jobKey := metadata.GetJobKey(resID)
+	if _, err := (*coord.KVClient).Put(context.Background(), jobKey, string(serialized)); err != nil {
+		t.Fatalf("could not set job: %v", err)
+	}
+	defer (*coord.KVClient).Delete(context.Background(), jobKey)
+	if err := coord.ExecuteJob(jobKey); err != nil {
+		t.Fatalf("locked job was not left waiting: %v", err)
+	}
+	if hasJob, err := coord.hasJob(resID); err != nil || !hasJob {
+		t.Fatalf("locked job was removed: %v", err)
+	}
+	runKey := metadata.GetManualRunKey(resID)
+	if _, err := (*coord.KVClient).Put(context.Background(), runKey, string(serialized)); err != nil {
+		t.Fatalf("could not set manual run: %v", err)
+	}
+	defer (*coord.KVClient).Delete(context.Background(), runKey)
+	before, err := (*coord.KVClient).Get(context.Background(), runKey)
+	if err != nil {
+		t.Fatalf("could not get manual run: %v", err)
+	}
+	if err := coord.UnlockResource(resID); err != nil {
+		t.Fatalf("could not unlock resource: %v", err)
+	}
+	after, err := (*coord.KVClient).Get(context.Background(), runKey)
+	if err != nil || len(after.Kvs) == 0 || after.Kvs[0].ModRevision <= before.Kvs[0].ModRevision {
+		t.Fatalf("held manual run was not resubmitted: %v", err)
+	}
+	if lock, err := coord.MaintenanceLock(resID); err != nil || lock != nil {
+		t.Fatalf("resource still locked: %v, %v", lock, err)
+	}
+}
+
+func TestMigrateOnlineStoreResumes(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{fmt.Sprintf("%s:%s", etcdHost, etcdPort)}})
+	if err != nil {
+		t.Fatalf("could not connect to etcd: %v", err)
+	}
+	defer cli.Close()
+	c, meta, _, spawner := newMockCoordinator()
+	kv := clientv3.NewKV(cli)
+	c.KVClient, c.EtcdClient = &kv, cli
+	meta.AddProvider(&pb.Provider{Name: "online2", Type: string(provider.RedisOnline), SerializedConfig: []byte("{}")})
+	features := []metadata.NameVariant{{Name: createSafeUUID(), Variant: "v1"}, {Name: createSafeUUID(), Variant: "v1"}}
+	ids := make([]metadata.ResourceID, len(features))
+	for i, feature := range features {
+		meta.AddFeatureVariant(&pb.FeatureVariant{Name: feature.Name, Variant: feature.Variant, Type: "float32", Provider: "online"})
+		ids[i] = metadata.ResourceID{Name: feature.Name, Variant: feature.Variant, Type: metadata.FEATURE_VARIANT}
+		defer cli.Delete(context.Background(), metadata.GetMaintenanceLockKey(ids[i]))
+	}
+	ctx := context.Background()
+	if err := c.LockResource(ids[1], "investigating bad data"); err != nil {
+		t.Fatalf("could not lock feature: %v", err)
+	}
+	if err := c.MigrateOnlineStore(ctx, features, "online2", "test"); err == nil || re.IsRecoverable(err) {
+		t.Fatalf("Expected migrating a feature locked for something else to fail permanently, got %v", err)
+	}
+	if lock, err := c.MaintenanceLock(ids[0]); err != nil || lock != nil {
+		t.Fatalf("Rejected migration locked a feature: %v, %v", lock, err)
+	}
+	if err := c.UnlockResource(ids[1]); err != nil {
+		t.Fatalf("could not unlock feature: %v", err)
+	}
+	spawner.Errors = map[string]error{runner.MIGRATE_ONLINE: fmt.Errorf("copy failed")}
+	if err := c.MigrateOnlineStore(ctx, features, "online2", "test"); err == nil {
+		t.Fatalf("Expected failed copy to fail the migration")
+	}
+	for i, id := range ids {
+		if lock, err := c.MaintenanceLock(id); err != nil || lock != nil {
+			t.Fatalf("Feature still locked after a migration that switched nothing over: %v, %v", lock, err)
+		}
+		if variant, _ := meta.GetFeatureVariant(ctx, features[i]); variant.Provider() != "online" {
+			t.Fatalf("Failed migration switched %s over", id.Name)
+		}
+	}
+	// A run that switched the first feature over before failing leaves both
+	// locked, and running it again switches over the second.
+	reason := "online store migration to online2"
+	for _, id := range ids {
+		if err := c.LockResource(id, reason); err != nil {
+			t.Fatalf("could not lock feature: %v", err)
+		}
+	}
+	if err := meta.UpdateFeatureVariantProvider(ctx, features[0], "online2", "test"); err != nil {
+		t.Fatalf("could not switch feature over: %v", err)
+	}
+	spawner.Errors = nil
+	if err := c.MigrateOnlineStore(ctx, features, "online2", "test"); err != nil {
+		t.Fatalf("Resumed migration failed: %v", err)
+	}
+	jobs := spawner.Jobs()
+	var config runner.OnlineMigrationRunnerConfig
+	if err := config.Deserialize(jobs[len(jobs)-1].Config); err != nil {
+		t.Fatalf("Could not deserialize migration config: %v", err)
+	}
+	if len(config.Features) != 1 || config.Features[0].ID.Name != features[1].Name {
+		t.Fatalf("Expected only the feature that wasn't switched over to be copied, got %+v", config.Features)
+	}
+	for i, id := range ids {
+		if lock, err := c.MaintenanceLock(id); err != nil || lock != nil {
+			t.Fatalf("Feature still locked after migration: %v, %v", lock, err)
+		}
+		if variant, _ := meta.GetFeatureVariant(ctx, features[i]); variant.Provider() != "online2" {
+			t.Fatalf("%s was not switched over", id.Name)
+		}
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for n, want := range expected {
+		if got := policy.backoff(uint(n)); got != want {
+			t.Fatalf("Backoff after attempt %d is %s, expected %s", n, got, want)
+		}
+	}
+	if attempts := (RetryPolicy{}).attempts(); attempts != 1 {
+		t.Fatalf("Zero policy makes %d attempts, expected 1", attempts)
+	}
+}
+
+func TestAwaitDependencies(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	serv, addr := startServ(t)
+	defer serv.Stop()
+	coord, err := createNewCoordinator(addr)
+	if err != nil {
+		t.Fatalf("could not create new basic coordinator")
+	}
+	defer coord.Metadata.Close()
+	parentName := createSafeUUID()
+	childName := createSafeUUID()
+	providerName := createSafeUUID()
+	userName := createSafeUUID()
+	defs := []metadata.ResourceDef{
+		metadata.UserDef{
+			Name: userName,
+		},
+		metadata.ProviderDef{
+			Name:             providerName,
+			Type:             "POSTGRES_OFFLINE",
+			SerializedConfig: []byte{},
+		},
+		metadata.SourceDef{
+			Name:     parentName,
+			Owner:    userName,
+			Provider: providerName,
+			Definition: metadata.PrimaryDataSource{
+				Location: metadata.SQLTable{
+					Name: createSafeUUID(),
+				},
+			},
+		},
+		metadata.SourceDef{
+			Name:     childName,
+			Owner:    userName,
+			Provider: providerName,
+			Definition: metadata.TransformationSource{
+				TransformationType: metadata.SQLTransformationType{
+					Query:   fmt.Sprintf("SELECT * FROM {{%s.}}", parentName),
+					Sources: []metadata.NameVariant{{Name: parentName}},
+				},
+			},
+		},
+	}
+	if err := coord.Metadata.CreateAll(context.Background(), defs); err != nil {
+		t.Fatalf("could not create test metadata entries: %v", err)
+	}
+	parentID := metadata.ResourceID{Name: parentName, Type: metadata.SOURCE_VARIANT}
+	childID := metadata.ResourceID{Name: childName, Type: metadata.SOURCE_VARIANT}
+	deps, err := coord.jobDependencies(context.Background(), childID)
+	if err != nil || len(deps) != 1 || deps[0] != parentID {
+		t.Fatalf("expected %v as the only dependency, got %v: %v", parentID, deps, err)
+	}
+	if err := coord.Metadata.SetStatus(context.Background(), parentID, metadata.FAILED, ""); err != nil {
+		t.Fatalf("could not set parent status: %v", err)
+	}
+	if err := coord.awaitDependencies(context.Background(), childID); err == nil {
+		t.Fatalf("did not fail on a failed dependency")
+	}
+	if err := coord.Metadata.SetStatus(context.Background(), parentID, metadata.READY, ""); err != nil {
+		t.Fatalf("could not set parent status: %v", err)
+	}
+	if err := coord.awaitDependencies(context.Background(), childID); err != nil {
+		t.Fatalf("ready dependency blocked job: %v", err)
+	}
+}
+
+func TestJobLimiter(t *testing.T) {
+	limiter := NewJobLimiter(3, 1, map[string]int{"warehouse": 2})
+	ctx := context.Background()
+	releaseA, err := limiter.Acquire(ctx, []string{"warehouse"})
+	if err != nil {
+		t.Fatalf("Could not acquire first warehouse slot: %v", err)
+	}
+	if _, err := limiter.Acquire(ctx, []string{"warehouse", "warehouse"}); err != nil {
+		t.Fatalf("Could not acquire second warehouse slot: %v", err)
+	}
+	blocked, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.Acquire(blocked, []string{"warehouse"}); err == nil {
+		t.Fatalf("Acquired more warehouse slots than its limit")
+	}
+	if _, err := limiter.Acquire(ctx, []string{"store"}); err != nil {
+		t.Fatalf("Could not acquire store slot: %v", err)
+	}
+	globalBlocked, cancelGlobal := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelGlobal()
+	if _, err := limiter.Acquire(globalBlocked, []string{"other"}); err == nil {
+		t.Fatalf("Acquired more slots than the global limit")
+	}
+	releaseA()
+	if _, err := limiter.Acquire(ctx, []string{"warehouse"}); err != nil {
+		t.Fatalf("Could not acquire released warehouse slot: %v", err)
+	}
+}
+
+func TestJobLimiterPriority(t *testing.T) {
+	limiter := NewJobLimiter(1, 0, nil)
+	ctx := context.Background()
+	release, err := limiter.Acquire(ctx, []string{"warehouse"})
+	if err != nil {
+		t.Fatalf("Could not acquire slot: %v", err)
+	}
+	granted := make(chan int32, 2)
+	acquire := func(priority int32) {
+		release, err := limiter.AcquireWithPriority(ctx, []string{"warehouse"}, priority)
+		if err != nil {
+			t.Errorf("Could not acquire slot with priority %d: %v", priority, err)
+			return
+		}
+		granted <- priority
+		release()
+	}
+	go acquire(int32(metadata.BackfillPriority))
+	time.Sleep(50 * time.Millisecond)
+	go acquire(int32(metadata.HighPriority))
+	time.Sleep(50 * time.Millisecond)
+	release()
+	if first := <-granted; first != int32(metadata.HighPriority) {
+		t.Fatalf("Slot granted to priority %d ahead of %d", first, metadata.HighPriority)
+	}
+	if second := <-granted; second != int32(metadata.BackfillPriority) {
+		t.Fatalf("Expected backfill job to run second, got priority %d", second)
+	}
+}
+
+func TestDeadLetterReplay(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	serv, addr := startServ(t)
+	defer serv.Stop()
+	coord, err := createNewCoordinator(addr)
+	if err != nil {
+		t.Fatalf("could not create new basic coordinator")
+	}
+	defer coord.Metadata.Close()
+	coord.Retry = RetryPolicy{MaxAttempts: 1}
+	sourceName := createSafeUUID()
+	providerName := createSafeUUID()
+	userName := createSafeUUID()
+	defs := []metadata.ResourceDef{
+		metadata.UserDef{
+			Name: userName,
+		},
+		metadata.ProviderDef{
+			Name:             providerName,
+			Type:             "POSTGRES_OFFLINE",
+			SerializedConfig: []byte{},
+		},
+		metadata.SourceDef{
+			Name:     sourceName,
+			Owner:    userName,
+			Provider: providerName,
+			Definition: metadata.PrimaryDataSource{
+				Location: metadata.SQLTable{
+					Name: createSafeUUID(),
+				},
+			},
+		},
+	}
+	if err := coord.Metadata.CreateAll(context.Background(), defs); err != nil {
+		t.Fatalf("could not create test metadata entries: %v", err)
+	}
+	sourceID := metadata.ResourceID{Name: sourceName, Type: metadata.SOURCE_VARIANT}
+	jobKey := metadata.GetJobKey(sourceID)
+	defer (*coord.KVClient).Delete(context.Background(), jobKey)
+	defer (*coord.KVClient).Delete(context.Background(), metadata.GetDeadLetterKey(sourceID))
+	if err := coord.ExecuteJob(jobKey); err == nil {
+		t.Fatalf("job with a broken provider succeeded")
+	}
+	if hasJob, err := coord.hasJob(sourceID); err != nil || hasJob {
+		t.Fatalf("failed job was not removed: %v", err)
+	}
+	deadLetters, err := coord.Metadata.ListDeadLetters(context.Background())
+	if err != nil {
+		t.Fatalf("could not list dead letters: %v", err)
+	}
+	found := false
+	for _, deadLetter := range deadLetters {
+		if deadLetter.Resource == sourceID {
+			found = true
+			if len(deadLetter.Attempts) != 1 || deadLetter.Error == "" {
+				t.Fatalf("dead letter is missing its failure: %v", deadLetter)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("failed job was not dead lettered: %v", deadLetters)
+	}
+	if err := coord.Metadata.ReplayDeadLetter(context.Background(), sourceID, "test"); err != nil {
+		t.Fatalf("could not replay dead letter: %v", err)
+	}
+	if hasJob, err := coord.hasJob(sourceID); err != nil || !hasJob {
+		t.Fatalf("replayed job was not resubmitted: %v", err)
+	}
+	if err := coord.Metadata.ReplayDeadLetter(context.Background(), sourceID, "test"); err == nil {
+		t.Fatalf("replayed the same dead letter twice")
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	coord := &Coordinator{Logger: zap.NewExample().Sugar(), shutdown: newShutdown()}
+	if !coord.shutdown.start() {
+		t.Fatalf("Could not start a job before shutdown")
+	}
+	finished := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(finished)
+		coord.shutdown.finish()
+	}()
+	if err := coord.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	select {
+	case <-finished:
+	default:
+		t.Fatalf("Shutdown returned before the running job finished")
+	}
+	if coord.shutdown.start() {
+		t.Fatalf("Started a job after shutdown")
+	}
+
+	coord = &Coordinator{Logger: zap.NewExample().Sugar(), shutdown: newShutdown()}
+	coord.shutdown.start()
+	go func() {
+		<-coord.shutdown.interrupt.Done()
+		coord.shutdown.finish()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := coord.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("Expected running job to be interrupted, got %v", err)
+	}
+}
+
+func TestPartitionOwnership(t *testing.T) {
+	members := []string{"coordinator-a", "coordinator-b", "coordinator-c"}
+	partitions := make([]*Partitioner, len(members))
+	for i, id := range members {
+		partitions[i] = NewPartitioner(id)
+		if !partitions[i].Owns("JOB__FEATURE_VARIANT__a__b") {
+			t.Fatalf("Partitioner without members doesn't own every job")
+		}
+		partitions[i].setMembers(append([]string{}, members...))
+	}
+	owned := make(map[string]int)
+	moved := 0
+	for i := 0; i < 300; i++ {
+		key := fmt.Sprintf("JOB__FEATURE_VARIANT__feature%d__default", i)
+		owners := 0
+		for _, p := range partitions {
+			if p.Owns(key) {
+				owners++
+				owned[p.ID]++
+			}
+		}
+		if owners != 1 {
+			t.Fatalf("%s has %d owners", key, owners)
+		}
+		// Removing a member only moves the jobs it owned.
+		before := jobOwner(members, key)
+		if after := jobOwner(members[:2], key); after != before {
+			if before != "coordinator-c" {
+				t.Fatalf("%s moved from %s to %s", key, before, after)
+			}
+			moved++
+		}
+	}
+	for _, id := range members {
+		if owned[id] < 50 {
+			t.Fatalf("Jobs aren't spread between members: %v", owned)
+		}
+	}
+	if moved != owned["coordinator-c"] {
+		t.Fatalf("Moved %d jobs, expected %d", moved, owned["coordinator-c"])
+	}
+}
+
+func TestPartitionRegistersAgain(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{fmt.Sprintf("%s:%s", etcdHost, etcdPort)}})
+	if err != nil {
+		t.Fatalf("Could not connect to etcd: %v", err)
+	}
+	defer cli.Close()
+	kv := clientv3.NewKV(cli)
+	c := &Coordinator{
+		Logger:     zap.NewExample().Sugar(),
+		EtcdClient: cli,
+		KVClient:   &kv,
+		LockTTL:    time.Second,
+		Partition:  NewPartitioner(createSafeUUID()),
+		// A replica that isn't leading doesn't claim the jobs in etcd.
+		Leadership: NewLeadership("standby"),
+	}
+	partitionRetryInterval = 10 * time.Millisecond
+	if err := c.JoinPartition(context.Background()); err != nil {
+		t.Fatalf("Could not join partition: %v", err)
+	}
+	memberKey := MemberPrefix + c.Partition.ID
+	registered := func() bool {
+		resp, err := kv.Get(context.Background(), memberKey)
+		return err == nil && len(resp.Kvs) == 1
+	}
+	waitFor := func(condition func() bool) bool {
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if condition() {
+				return true
+			}
+		}
+		return false
+	}
+	// Revoking the lease deletes the member key, the same as it expiring.
+	if _, err := cli.Revoke(context.Background(), c.Partition.session.Lease()); err != nil {
+		t.Fatalf("Could not revoke member lease: %v", err)
+	}
+	if !waitFor(registered) {
+		t.Fatalf("Member didn't register again after its lease expired")
+	}
+	isMember := func() bool {
+		for _, member := range c.Partition.Members() {
+			if member == c.Partition.ID {
+				return true
+			}
+		}
+		return false
+	}
+	if !waitFor(isMember) {
+		t.Fatalf("Member list doesn't have the member that registered again: %v", c.Partition.Members())
+	}
+	c.leavePartition()
+	time.Sleep(100 * time.Millisecond)
+	if registered() {
+		t.Fatalf("Member registered again after leaving")
+	}
+}
+
+func TestJobMaxRuntime(t *testing.T) {
+	coord := &Coordinator{
+		Logger:     zap.NewExample().Sugar(),
+		MaxRuntime: map[metadata.ResourceType]time.Duration{metadata.FEATURE_VARIANT: 50 * time.Millisecond},
+	}
+	ctx := context.Background()
+	id := metadata.ResourceID{Name: createSafeUUID(), Variant: "v", Type: metadata.FEATURE_VARIANT}
+	coord.jobContexts.Store(id, ctx)
+	runUntilCancelled := func() error {
+		<-coord.jobContext(id).Done()
+		return runner.ErrJobCancelled
+	}
+	err := coord.runWithTimeout(ctx, id, runUntilCancelled)
+	if err == nil || !strings.Contains(err.Error(), errJobTimedOut.Error()) {
+		t.Fatalf("Expected job to time out, got %v", err)
+	}
+	if re.IsRecoverable(err) {
+		t.Fatalf("Timed out job is retried without RetryTimeouts")
+	}
+	if coord.jobContext(id) != ctx {
+		t.Fatalf("Job context wasn't restored after the attempt")
+	}
+	coord.RetryTimeouts = true
+	if err := coord.runWithTimeout(ctx, id, runUntilCancelled); !errors.Is(err, errJobTimedOut) || !re.IsRecoverable(err) {
+		t.Fatalf("Expected a retryable timeout, got %v", err)
+	}
+	labelID := metadata.ResourceID{Name: id.Name, Variant: id.Variant, Type: metadata.LABEL_VARIANT}
+	if err := coord.runWithTimeout(ctx, labelID, func() error { return nil }); err != nil {
+		t.Fatalf("Job without a max runtime failed: %v", err)
+	}
+}
+
+func TestBackfillWindows(t *testing.T) {
+	start := time.Date(2022, 3, 1, 0, 30, 0, 0, time.UTC)
+	end := time.Date(2022, 3, 1, 3, 0, 0, 0, time.UTC)
+	windows, err := backfillWindows("0 * * * *", start, end)
+	if err != nil {
+		t.Fatalf("Could not split backfill: %v", err)
+	}
+	hour := func(h int) time.Time {
+		return time.Date(2022, 3, 1, h, 0, 0, 0, time.UTC)
+	}
+	expected := [][2]time.Time{{start, hour(1)}, {hour(1), hour(2)}, {hour(2), end}}
+	if !reflect.DeepEqual(windows, expected) {
+		t.Fatalf("Backfill windows are %v, expected %v", windows, expected)
+	}
+	if _, err := backfillWindows("not a schedule", start, end); err == nil {
+		t.Fatalf("Split backfill with an invalid schedule")
+	}
+	id := metadata.ResourceID{Name: "f", Variant: "v", Type: metadata.FEATURE_VARIANT}
+	if backfillKey(id, hour(1)) >= backfillKey(id, hour(2)) {
+		t.Fatalf("Backfill keys don't sort in time order")
+	}
+	if !strings.HasPrefix(backfillKey(id, hour(1)), backfillResourceKey(id)) {
+		t.Fatalf("Backfill key isn't under its resource's prefix")
+	}
+}
+
+func TestBackfillWaitsForLockAndSlot(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{fmt.Sprintf("%s:%s", etcdHost, etcdPort)}})
+	if err != nil {
+		t.Fatalf("could not connect to etcd: %v", err)
+	}
+	defer cli.Close()
+	c, meta, _, spawner := newMockCoordinator()
+	kv := clientv3.NewKV(cli)
+	c.KVClient, c.EtcdClient, c.shutdown = &kv, cli, newShutdown()
+	name := createSafeUUID()
+	meta.AddFeatureVariant(&pb.FeatureVariant{
+		Name:     name,
+		Variant:  "v1",
+		Source:   &pb.NameVariant{Name: "transactions", Variant: "default"},
+		Type:     "float32",
+		Entity:   "user",
+		Provider: "online",
+		Schedule: "0 * * * *",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_READY},
+		Location: &pb.FeatureVariant_Columns{Columns: &pb.Columns{Entity: "user_id", Value: "amount", Ts: "ts"}},
+	})
+	id := metadata.ResourceID{Name: name, Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	ctx := context.Background()
+	defer cli.Delete(ctx, backfillResourceKey(id), clientv3.WithPrefix())
+	defer cli.Delete(ctx, metadata.GetMaintenanceLockKey(id))
+	start := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := c.Backfill(id, start, start.Add(time.Hour)); err != nil {
+		t.Fatalf("could not queue backfill: %v", err)
+	}
+	if err := c.LockResource(id, "investigating bad data"); err != nil {
+		t.Fatalf("could not lock feature: %v", err)
+	}
+	if err := c.runBackfills(id); err != nil {
+		t.Fatalf("locked backfill failed: %v", err)
+	}
+	if jobs := spawner.Jobs(); len(jobs) != 0 {
+		t.Fatalf("backfill of a locked feature ran: %v", jobs)
+	}
+	if resp, err := kv.Get(ctx, backfillResourceKey(id), clientv3.WithPrefix()); err != nil || len(resp.Kvs) != 1 {
+		t.Fatalf("locked backfill wasn't left queued: %v", err)
+	}
+	if err := c.UnlockResource(id); err != nil {
+		t.Fatalf("could not unlock feature: %v", err)
+	}
+	// With every slot taken, the backfill waits for one.
+	c.Limiter = NewJobLimiter(1, 0, nil)
+	release, err := c.Limiter.Acquire(ctx, []string{"online"})
+	if err != nil {
+		t.Fatalf("could not take the only slot: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- c.runBackfills(id) }()
+	time.Sleep(200 * time.Millisecond)
+	if jobs := spawner.Jobs(); len(jobs) != 0 {
+		t.Fatalf("backfill ran without a slot: %v", jobs)
+	}
+	release()
+	if err := <-done; err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+	if jobs := spawner.Jobs(); len(jobs) != 1 || jobs[0].Resource != id {
+		t.Fatalf("expected one backfilled run, got %v", jobs)
+	}
+}
+
+func newMockCoordinator() (*Coordinator, *mocks.Metadata, *mocks.OfflineStore, *mocks.Spawner) {
+	meta := mocks.NewMetadata()
+	meta.AddProvider(&pb.Provider{Name: "offline", Type: string(provider.PostgresOffline), SerializedConfig: []byte("{}")})
+	meta.AddProvider(&pb.Provider{Name: "online", Type: string(provider.RedisOnline), SerializedConfig: []byte("{}")})
+	meta.AddSourceVariant(&pb.SourceVariant{
+		Name:     "transactions",
+		Variant:  "default",
+		Provider: "offline",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_READY},
+	})
+	offline := mocks.NewOfflineStore()
+	providers := mocks.NewProviders()
+	providers.Add(provider.PostgresOffline, offline)
+	spawner := &mocks.Spawner{}
+	c := &Coordinator{
+		Logger:    zap.NewNop().Sugar(),
+		Resources: meta,
+		Providers: providers,
+		Spawner:   spawner,
+		Timeout:   1,
+	}
+	return c, meta, offline, spawner
+}
+
+func TestFeatureMaterializeJobWithMocks(t *testing.T) {
+	c, meta, offline, spawner := newMockCoordinator()
+	meta.AddFeatureVariant(&pb.FeatureVariant{
+		Name:     "avg_amount",
+		Variant:  "v1",
+		Source:   &pb.NameVariant{Name: "transactions", Variant: "default"},
+		Type:     "float32",
+		Entity:   "user",
+		Provider: "online",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+		Location: &pb.FeatureVariant_Columns{Columns: &pb.Columns{Entity: "user_id", Value: "amount", Ts: "ts"}},
+	})
+	resID := metadata.ResourceID{Name: "avg_amount", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	if err := c.runFeatureMaterializeJob(resID, "0 * * * *"); err != nil {
+		t.Fatalf("Materialize job failed: %v", err)
+	}
+	if status, msg := meta.Status(resID); status != metadata.READY {
+		t.Fatalf("Expected feature to be READY, got %s: %s", status, msg)
+	}
+	schema, has := offline.ResourceSchema(provider.ResourceID{Name: "avg_amount", Variant: "v1", Type: provider.Feature})
+	if !has {
+		t.Fatalf("Feature resource table was not registered")
+	}
+	srcName, err := provider.GetTransformationName(provider.ResourceID{Name: "transactions", Variant: "default"})
+	if err != nil {
+		t.Fatalf("Could not get source table name: %v", err)
+	}
+	if schema.Entity != "user_id" || schema.Value != "amount" || schema.TS != "ts" || schema.SourceTable != srcName {
+		t.Fatalf("Feature registered with wrong schema: %#v", schema)
+	}
+	jobs := spawner.Jobs()
+	if len(jobs) != 2 {
+		t.Fatalf("Expected a materialization to be run and scheduled, got %d jobs", len(jobs))
+	}
+	if jobs[0].Name != runner.MATERIALIZE || jobs[0].Schedule != "" {
+		t.Fatalf("Expected a materialization to be run first, got %#v", jobs[0])
+	}
+	if jobs[1].Schedule != "0 * * * *" {
+		t.Fatalf("Expected the update to be scheduled, got %#v", jobs[1])
+	}
+	var config runner.MaterializedRunnerConfig
+	if err := config.Deserialize(jobs[1].Config); err != nil {
+		t.Fatalf("Could not deserialize materialize config: %v", err)
+	}
+	if !config.IsUpdate || config.OnlineType != provider.RedisOnline || config.OfflineType != provider.PostgresOffline {
+		t.Fatalf("Unexpected scheduled materialize config: %#v", config)
+	}
+}
+
+func TestTrainingSetJobRunnerFailureWithMocks(t *testing.T) {
+	c, meta, _, spawner := newMockCoordinator()
+	spawner.Errors = map[string]error{runner.CREATE_TRAINING_SET: errors.New("warehouse unavailable")}
+	source := &pb.NameVariant{Name: "transactions", Variant: "default"}
+	meta.AddFeatureVariant(&pb.FeatureVariant{Name: "avg_amount", Variant: "v1", Source: source, Provider: "online"})
+	meta.AddLabelVariant(&pb.LabelVariant{Name: "fraud", Variant: "v1", Source: source, Provider: "offline"})
+	meta.AddTrainingSetVariant(&pb.TrainingSetVariant{
+		Name:     "fraud_training",
+		Variant:  "v1",
+		Provider: "offline",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+		Features: []*pb.NameVariant{{Name: "avg_amount", Variant: "v1"}},
+		Label:    &pb.NameVariant{Name: "fraud", Variant: "v1"},
+	})
+	resID := metadata.ResourceID{Name: "fraud_training", Variant: "v1", Type: metadata.TRAINING_SET_VARIANT}
+	if err := c.runTrainingSetJob(resID, ""); err == nil {
+		t.Fatalf("Expected training set job to fail with its runner")
+	}
+	if status, _ := meta.Status(resID); status != metadata.PENDING {
+		t.Fatalf("Expected failed training set to be left PENDING for a retry, got %s", status)
+	}
+	jobs := spawner.Jobs()
+	if len(jobs) != 1 || jobs[0].Name != runner.CREATE_TRAINING_SET {
+		t.Fatalf("Expected one training set job, got %#v", jobs)
+	}
+	var config runner.TrainingSetRunnerConfig
+	if err := config.Deserialize(jobs[0].Config); err != nil {
+		t.Fatalf("Could not deserialize training set config: %v", err)
+	}
+	if len(config.Def.Features) != 1 || config.Def.Label.Name != "fraud" {
+		t.Fatalf("Unexpected training set def: %#v", config.Def)
+	}
+}
+
+func TestTrainingSetExportJobWithMocks(t *testing.T) {
+	c, meta, _, spawner := newMockCoordinator()
+	source := &pb.NameVariant{Name: "transactions", Variant: "default"}
+	meta.AddFeatureVariant(&pb.FeatureVariant{Name: "avg_amount", Variant: "v1", Source: source, Provider: "online"})
+	meta.AddLabelVariant(&pb.LabelVariant{Name: "fraud", Variant: "v1", Source: source, Provider: "offline"})
+	meta.AddTrainingSetVariant(&pb.TrainingSetVariant{
+		Name:     "fraud_training",
+		Variant:  "v1",
+		Provider: "offline",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+		Features: []*pb.NameVariant{{Name: "avg_amount", Variant: "v1"}},
+		Label:    &pb.NameVariant{Name: "fraud", Variant: "v1"},
+		Export:   &pb.TrainingSetExport{Uri: "s3://bucket/exports", Format: "PARQUET", RowsPerFile: 100},
+	})
+	resID := metadata.ResourceID{Name: "fraud_training", Variant: "v1", Type: metadata.TRAINING_SET_VARIANT}
+	if err := c.runTrainingSetJob(resID, ""); err != nil {
+		t.Fatalf("Training set job failed: %v", err)
+	}
+	if status, msg := meta.Status(resID); status != metadata.READY {
+		t.Fatalf("Expected exported training set to be ready, got %s: %s", status, msg)
+	}
+	jobs := spawner.Jobs()
+	if len(jobs) != 2 || jobs[0].Name != runner.CREATE_TRAINING_SET || jobs[1].Name != runner.EXPORT_TRAINING_SET {
+		t.Fatalf("Expected training set job followed by an export job, got %#v", jobs)
+	}
+	var config runner.ExportTrainingSetRunnerConfig
+	if err := config.Deserialize(jobs[1].Config); err != nil {
+		t.Fatalf("Could not deserialize export config: %v", err)
+	}
+	expected := provider.TrainingSetExport{URI: "s3://bucket/exports", Format: provider.ParquetExport, RowsPerFile: 100}
+	if config.Def.Export == nil || *config.Def.Export != expected || config.Def.ID.Name != "fraud_training" {
+		t.Fatalf("Unexpected export def: %#v", config.Def)
+	}
+}
+
+func TestTrainingSetFreshnessWithMocks(t *testing.T) {
+	c, meta, offline, _ := newMockCoordinator()
+	source := &pb.NameVariant{Name: "transactions", Variant: "default"}
+	meta.AddFeatureVariant(&pb.FeatureVariant{Name: "avg_amount", Variant: "v1", Source: source, Provider: "online"})
+	meta.AddLabelVariant(&pb.LabelVariant{Name: "fraud", Variant: "v1", Source: source, Provider: "offline"})
+	meta.AddTrainingSetVariant(&pb.TrainingSetVariant{
+		Name:     "fraud_training",
+		Variant:  "v1",
+		Provider: "offline",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+		Features: []*pb.NameVariant{{Name: "avg_amount", Variant: "v1"}},
+		Label:    &pb.NameVariant{Name: "fraud", Variant: "v1"},
+	})
+	start := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	tables := map[provider.ResourceID][]provider.ResourceRecord{
+		{Name: "avg_amount", Variant: "v1", Type: provider.Feature}: {
+			{Entity: "a", Value: 1, TS: start},
+			{Entity: "a", Value: 2, TS: start.Add(48 * time.Hour)},
+		},
+		{Name: "fraud", Variant: "v1", Type: provider.Label}: {
+			{Entity: "a", Value: true, TS: start.Add(time.Hour)},
+		},
+	}
+	for id, records := range tables {
+		table, err := offline.CreateResourceTable(id, provider.TableSchema{})
+		if err != nil {
+			t.Fatalf("Could not create resource table: %v", err)
+		}
+		for _, rec := range records {
+			if err := table.Write(rec); err != nil {
+				t.Fatalf("Could not write record: %v", err)
+			}
+		}
+	}
+	resID := metadata.ResourceID{Name: "fraud_training", Variant: "v1", Type: metadata.TRAINING_SET_VARIANT}
+	if err := c.runTrainingSetJob(resID, ""); err != nil {
+		t.Fatalf("Training set job failed: %v", err)
+	}
+	ts, err := meta.GetTrainingSetVariant(context.Background(), metadata.NameVariant{Name: "fraud_training", Variant: "v1"})
+	if err != nil {
+		t.Fatalf("Could not get training set: %v", err)
+	}
+	freshness := ts.Freshness()
+	if freshness == nil || len(freshness.Inputs) != 2 {
+		t.Fatalf("Expected freshness of the label and feature, got %#v", freshness)
+	}
+	// The feature's later value comes after every label, so it isn't used.
+	for _, input := range freshness.Inputs {
+		if !input.Latest.Equal(start) && !input.Latest.Equal(start.Add(time.Hour)) {
+			t.Fatalf("Unexpected freshness of %v: %v", input.Resource, input.Latest)
+		}
+	}
+	if late := freshness.After(start.Add(time.Hour)); len(late) != 0 {
+		t.Fatalf("Expected training set to only use data up to its latest label, got %v", late)
+	}
+}
+
+func TestConfigSchemaValidate(t *testing.T) {
+	schema := ConfigSchema{
+		"table":   {Type: StringConfig, Required: true},
+		"limit":   {Type: NumberConfig},
+		"columns": {Type: ListConfig},
+	}
+	valid := []string{
+		`{"table": "transactions"}`,
+		`{"table": "transactions", "limit": 10, "columns": ["a", "b"]}`,
+		`{"table": "transactions", "limit": null}`,
+	}
+	for _, config := range valid {
+		if err := schema.Validate([]byte(config)); err != nil {
+			t.Fatalf("Failed to validate %s: %v", config, err)
+		}
+	}
+	invalid := []string{
+		``,
+		`[]`,
+		`{"limit": 10}`,
+		`{"table": 10}`,
+		`{"table": "transactions", "other": true}`,
+	}
+	for _, config := range invalid {
+		if err := schema.Validate([]byte(config)); err == nil {
+			t.Fatalf("Succeeded in validating %s", config)
+		}
+	}
+	if err := ConfigSchema(nil).Validate([]byte("anything")); err != nil {
+		t.Fatalf("Nil schema rejected config: %v", err)
+	}
+}
+
+func TestRegisterJobType(t *testing.T) {
+	c, _, _, _ := newMockCoordinator()
+	var ran []Job
+	jobType := JobType{
+		Name: "VALIDATE",
+		Handler: func(ctx context.Context, c *Coordinator, job Job) error {
+			ran = append(ran, job)
+			return nil
+		},
+		Schema: ConfigSchema{"threshold": {Type: NumberConfig, Required: true}},
+	}
+	if err := RegisterJobType(jobType); err != nil {
+		t.Fatalf("Failed to register job type: %v", err)
+	}
+	defer UnregisterJobType(jobType.Name)
+	if err := RegisterJobType(jobType); err == nil {
+		t.Fatalf("Succeeded in registering a job type twice")
+	}
+	resID := metadata.ResourceID{Name: "transactions", Variant: "default", Type: metadata.SOURCE_VARIANT}
+	if err := c.QueueJob(context.Background(), "MISSING", resID, nil); err == nil {
+		t.Fatalf("Succeeded in queueing a job of an unregistered type")
+	}
+	if err := c.QueueJob(context.Background(), jobType.Name, resID, []byte(`{"threshold": "high"}`)); err == nil {
+		t.Fatalf("Succeeded in queueing a job with an invalid config")
+	}
+	found, err := jobTypeOf(&metadata.CoordinatorJob{Resource: resID, Kind: jobType.Name})
+	if err != nil {
+		t.Fatalf("Failed to find job type: %v", err)
+	}
+	config := []byte(`{"threshold": 0.5}`)
+	if err := found.Handler(context.Background(), c, Job{Resource: resID, Config: config}); err != nil {
+		t.Fatalf("Handler failed: %v", err)
+	}
+	if len(ran) != 1 || ran[0].Resource != resID || string(ran[0].Config) != string(config) {
+		t.Fatalf("Handler got wrong jobs: %v", ran)
+	}
+	builtin, err := jobTypeOf(&metadata.CoordinatorJob{Resource: resID})
+	if err != nil {
+		t.Fatalf("Failed to find built-in job type: %v", err)
+	}
+	if builtin.Name != metadata.SOURCE_VARIANT.String() {
+		t.Fatalf("Wrong built-in job type: %s", builtin.Name)
+	}
+}
+
+type unschedulableRunner struct {
+	runner.Runner
+}
+
+func TestCronRunnerFallsBackToScheduler(t *testing.T) {
+	c, _, _, _ := newMockCoordinator()
+	resID := metadata.ResourceID{Name: "avg_amount", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	cronRunner := &mocks.Runner{}
+	if c.cronRunner(cronRunner, runner.MATERIALIZE, nil, resID) != cronRunner {
+		t.Fatalf("Cron runner wasn't scheduled itself")
+	}
+	scheduled, isScheduled := c.cronRunner(unschedulableRunner{}, runner.MATERIALIZE, []byte("{}"), resID).(*schedulerRunner)
+	if !isScheduled {
+		t.Fatalf("Runner without a schedule wasn't run by the scheduler")
+	}
+	if scheduled.job.Name != runner.MATERIALIZE || scheduled.job.Resource != resID {
+		t.Fatalf("Wrong scheduled job: %#v", scheduled.job)
+	}
+}
+
+func TestInternalScheduler(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{fmt.Sprintf("%s:%s", etcdHost, etcdPort)}})
+	if err != nil {
+		t.Fatalf("Failed to connect to etcd: %v", err)
+	}
+	defer cli.Close()
+	kv := clientv3.NewKV(cli)
+	spawner := &mocks.Spawner{}
+	c := &Coordinator{
+		Logger:     zap.NewNop().Sugar(),
+		EtcdClient: cli,
+		KVClient:   &kv,
+		Spawner:    spawner,
+		shutdown:   newShutdown(),
+	}
+	ctx := context.Background()
+	resID := metadata.ResourceID{Name: createSafeUUID(), Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	defer kv.Delete(ctx, scheduleKey(resID))
+	job := ScheduledJob{Name: runner.MATERIALIZE, Config: []byte("{}"), Resource: resID, Schedule: "0 * * * *"}
+	if err := c.addScheduledJob(ctx, job); err != nil {
+		t.Fatalf("Failed to schedule job: %v", err)
+	}
+	if err := c.runDueJobs(ctx, time.Now()); err != nil {
+		t.Fatalf("Failed to run due jobs: %v", err)
+	}
+	now := time.Now().Add(time.Hour)
+	if err := c.runDueJobs(ctx, now); err != nil {
+		t.Fatalf("Failed to run due jobs: %v", err)
+	}
+	// The run is queued as a job, and isn't queued again until it's done.
+	key := jobTypeKey(ScheduledRunJobType, resID)
+	defer kv.Delete(ctx, key)
+	if err := c.runDueJobs(ctx, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to run due jobs: %v", err)
+	}
+	queued, err := kv.Get(ctx, key)
+	if err != nil || len(queued.Kvs) != 1 {
+		t.Fatalf("Scheduled run not queued: %v", err)
+	}
+	coordJob := &metadata.CoordinatorJob{}
+	if err := coordJob.Deserialize(queued.Kvs[0].Value); err != nil {
+		t.Fatalf("Failed to deserialize queued run: %v", err)
+	}
+	if coordJob.Kind != ScheduledRunJobType || coordJob.Trigger != metadata.TriggerSchedule {
+		t.Fatalf("Wrong job queued: %+v", coordJob)
+	}
+	if err := runScheduledJob(ctx, c, Job{Resource: resID, Config: coordJob.Config}); err != nil {
+		t.Fatalf("Scheduled run failed: %v", err)
+	}
+	jobs := spawner.Jobs()
+	if len(jobs) != 1 || jobs[0].Name != runner.MATERIALIZE || jobs[0].Resource != resID {
+		t.Fatalf("Expected one scheduled run, got: %v", jobs)
+	}
+	resp, err := kv.Get(ctx, scheduleKey(resID))
+	if err != nil || len(resp.Kvs) != 1 {
+		t.Fatalf("Failed to get scheduled job: %v", err)
+	}
+	saved := &ScheduledJob{}
+	if err := saved.Deserialize(resp.Kvs[0].Value); err != nil {
+		t.Fatalf("Failed to deserialize scheduled job: %v", err)
+	}
+	if !saved.LastRun.Equal(now) || !saved.Next.After(now) {
+		t.Fatalf("Wrong runs recorded: last %s, next %s", saved.LastRun, saved.Next)
+	}
+}
+
+func TestTriggeredRunWhileSchedulePaused(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{fmt.Sprintf("%s:%s", etcdHost, etcdPort)}})
+	if err != nil {
+		t.Fatalf("Failed to connect to etcd: %v", err)
+	}
+	defer cli.Close()
+	c, meta, _, spawner := newMockCoordinator()
+	kv := clientv3.NewKV(cli)
+	c.KVClient, c.EtcdClient, c.shutdown = &kv, cli, newShutdown()
+	name := createSafeUUID()
+	meta.AddFeatureVariant(&pb.FeatureVariant{
+		Name:     name,
+		Variant:  "v1",
+		Source:   &pb.NameVariant{Name: "transactions", Variant: "default"},
+		Type:     "float32",
+		Entity:   "user",
+		Provider: "online",
+		Schedule: "0 * * * *",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_READY},
+		Location: &pb.FeatureVariant_Columns{Columns: &pb.Columns{Entity: "user_id", Value: "amount", Ts: "ts"}},
+	})
+	id := metadata.ResourceID{Name: name, Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	ctx := context.Background()
+	pause, err := (&metadata.SchedulePause{Resource: id, Reason: "maintenance", Paused: time.Now().UTC()}).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize pause: %v", err)
+	}
+	if _, err := kv.Put(ctx, metadata.GetSchedulePauseKey(id), string(pause)); err != nil {
+		t.Fatalf("Failed to pause schedule: %v", err)
+	}
+	defer kv.Delete(ctx, metadata.GetSchedulePauseKey(id))
+	defer kv.Delete(ctx, fmt.Sprintf("UPDATE_EVENT_%s__%s__%s__", id.Name, id.Variant, id.Type.String()), clientv3.WithPrefix())
+	config, err := json.Marshal(scheduledRun{Name: runner.MATERIALIZE, Config: []byte("{}")})
+	if err != nil {
+		t.Fatalf("Failed to serialize scheduled run: %v", err)
+	}
+	if err := runScheduledJob(ctx, c, Job{Resource: id, Config: config}); err != nil {
+		t.Fatalf("Scheduled run failed: %v", err)
+	}
+	if jobs := spawner.Jobs(); len(jobs) != 0 {
+		t.Fatalf("Scheduled run of a paused feature ran: %v", jobs)
+	}
+	// Only the schedule is paused, so a triggered run still runs.
+	jobType, err := jobTypeOf(&metadata.CoordinatorJob{Resource: id, Kind: metadata.ManualRunJobKind, Trigger: metadata.TriggerManual})
+	if err != nil {
+		t.Fatalf("Manual runs have no job type: %v", err)
+	}
+	if err := jobType.Handler(ctx, c, Job{Resource: id}); err != nil {
+		t.Fatalf("Triggered run failed: %v", err)
+	}
+	if jobs := spawner.Jobs(); len(jobs) != 1 || jobs[0].Resource != id {
+		t.Fatalf("Expected the triggered run to run, got: %v", jobs)
+	}
+}
+
+func TestLabelOnlineMaterializationWithMocks(t *testing.T) {
+	c, meta, _, spawner := newMockCoordinator()
+	meta.AddLabelVariant(&pb.LabelVariant{
+		Name:           "is_fraud",
+		Variant:        "v1",
+		Source:         &pb.NameVariant{Name: "transactions", Variant: "default"},
+		Type:           "bool",
+		Entity:         "user",
+		Provider:       "offline",
+		OnlineProvider: "online",
+		Status:         &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+		Location:       &pb.LabelVariant_Columns{Columns: &pb.Columns{Entity: "user_id", Value: "fraud", Ts: "ts"}},
+	})
+	resID := metadata.ResourceID{Name: "is_fraud", Variant: "v1", Type: metadata.LABEL_VARIANT}
+	if err := c.runLabelRegisterJob(resID, ""); err != nil {
+		t.Fatalf("Label job failed: %v", err)
+	}
+	if status, msg := meta.Status(resID); status != metadata.READY {
+		t.Fatalf("Expected label to be READY, got %s: %s", status, msg)
+	}
+	jobs := spawner.Jobs()
+	if len(jobs) != 1 || jobs[0].Name != runner.MATERIALIZE {
+		t.Fatalf("Expected the label to be materialized, got %#v", jobs)
+	}
+	var config runner.MaterializedRunnerConfig
+	if err := config.Deserialize(jobs[0].Config); err != nil {
+		t.Fatalf("Could not deserialize materialize config: %v", err)
+	}
+	if config.ResourceID.Type != provider.Label || config.OnlineType != provider.RedisOnline || config.OfflineType != provider.PostgresOffline {
+		t.Fatalf("Unexpected label materialize config: %#v", config)
+	}
+}
+
+type maintainableOfflineStore struct {
+	*mocks.OfflineStore
+}
+
+func (store maintainableOfflineStore) MaintenanceTasks() []provider.MaintenanceTask {
+	return []provider.MaintenanceTask{provider.VacuumAnalyze}
+}
+
+func (store maintainableOfflineStore) RunMaintenance(ctx context.Context, task provider.MaintenanceTask) error {
+	return nil
+}
+
+func TestProviderMaintenanceJobWithMocks(t *testing.T) {
+	c, meta, offline, spawner := newMockCoordinator()
+	c.Providers.(*mocks.Providers).Add(provider.PostgresOffline, maintainableOfflineStore{offline})
+	meta.AddProvider(&pb.Provider{
+		Name:             "maintained",
+		Type:             string(provider.PostgresOffline),
+		SerializedConfig: []byte("{}"),
+		Maintenance: []*pb.MaintenanceTask{
+			{Kind: string(provider.VacuumAnalyze), Schedule: "@daily"},
+			{Kind: string(provider.VacuumAnalyze)},
+		},
+	})
+	resID := metadata.ResourceID{Name: "maintained", Type: metadata.PROVIDER}
+	if err := c.runProviderJob(resID, ""); err != nil {
+		t.Fatalf("Provider job failed: %v", err)
+	}
+	jobs := spawner.Jobs()
+	if len(jobs) != 2 || jobs[0].Name != runner.MAINTAIN_PROVIDER || jobs[1].Name != runner.MAINTAIN_PROVIDER {
+		t.Fatalf("Expected two maintenance jobs, got %#v", jobs)
+	}
+	if jobs[0].Schedule != "0 0 * * *" || jobs[1].Schedule != "" {
+		t.Fatalf("Expected the first task to be scheduled and the second run, got %#v", jobs)
+	}
+	var config runner.MaintenanceRunnerConfig
+	if err := config.Deserialize(jobs[0].Config); err != nil {
+		t.Fatalf("Could not deserialize maintenance config: %v", err)
+	}
+	if config.Task != provider.VacuumAnalyze || config.ProviderType != provider.PostgresOffline {
+		t.Fatalf("Unexpected maintenance config: %#v", config)
+	}
+	if err := c.RunProviderMaintenance("maintained", provider.Defragment, ""); err == nil || re.IsRecoverable(err) {
+		t.Fatalf("Expected unsupported maintenance to fail permanently, got %v", err)
+	}
+	if len(spawner.Jobs()) != 2 {
+		t.Fatalf("Unsupported maintenance was spawned")
+	}
+}
+
+func TestArchiveHistory(t *testing.T) {
+	c, _, _, _ := newMockCoordinator()
+	now := time.Date(2022, 3, 2, 12, 0, 0, 0, time.UTC)
+	events := []metadata.AuditEvent{
+		{Action: "Cancelled job", Requester: "ops", Time: now.Add(-48 * time.Hour)},
+		{Action: "Paused schedule", Requester: "ops", Time: now.Add(-30 * time.Hour)},
+		{Action: "Resumed schedule", Requester: "ops", Time: now.Add(-time.Hour)},
+	}
+	var kvs []*mvccpb.KeyValue
+	for i := range events {
+		serialized, err := events[i].Serialize()
+		if err != nil {
+			t.Fatalf("Could not serialize audit event: %v", err)
+		}
+		kvs = append(kvs, &mvccpb.KeyValue{Key: []byte(metadata.GetAuditKey(events[i])), Value: serialized, ModRevision: int64(i + 1)})
+	}
+	kvs = append(kvs, &mvccpb.KeyValue{Key: []byte(metadata.AuditPrefix + "broken"), Value: []byte("{")})
+	records := c.expiredHistory(auditHistory, kvs, now.Add(-24*time.Hour))
+	if len(records) != 2 || records[0].modRevision != 1 || records[1].modRevision != 2 {
+		t.Fatalf("Expected the two events from before the cutoff, got %+v", records)
+	}
+	dir := t.TempDir()
+	archiver, err := provider.NewArchiver("file://" + dir)
+	if err != nil {
+		t.Fatalf("Could not create archiver: %v", err)
+	}
+	if err := archiveHistory(context.Background(), archiver, "audit", records, "run"); err != nil {
+		t.Fatalf("Could not archive history: %v", err)
+	}
+	for day, action := range map[string]string{"2022-02-28": "Cancelled job", "2022-03-01": "Paused schedule"} {
+		file, err := os.Open(filepath.Join(dir, "audit", "date="+day, "run.jsonl.gz"))
+		if err != nil {
+			t.Fatalf("History for %s wasn't archived: %v", day, err)
+		}
+		defer file.Close()
+		zr, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("Archived history isn't gzipped: %v", err)
+		}
+		var archived metadata.AuditEvent
+		if err := json.NewDecoder(zr).Decode(&archived); err != nil || archived.Action != action {
+			t.Fatalf("Expected %s to be archived for %s, got %+v: %v", action, day, archived, err)
+		}
+	}
+	if _, err := c.ArchiveHistory(context.Background(), HistoryRetention{AuditEvents: time.Hour}); err == nil {
+		t.Fatalf("Pruned history without an archive")
+	}
+}
+
+func TestCatchUpPolicy(t *testing.T) {
+	schedule, err := metadata.ParseSchedule("0 * * * *")
+	if err != nil {
+		t.Fatalf("Could not parse schedule: %v", err)
+	}
+	next := time.Date(2022, 3, 1, 10, 0, 0, 0, time.UTC)
+	if due := missedRuns(schedule, next, next.Add(time.Minute)); len(due) != 1 {
+		t.Fatalf("Job that's on time has %d due runs", len(due))
+	}
+	due := missedRuns(schedule, next, next.Add(5*time.Hour+time.Minute))
+	if len(due) != 6 || !due[5].Equal(next.Add(5*time.Hour)) {
+		t.Fatalf("Expected six due runs, got %v", due)
+	}
+	policies := map[string]catchUpPlan{
+		"":           {run: true},
+		"once":       {run: true},
+		"skip":       {},
+		"backfill:2": {backfillFrom: due[4]},
+		"backfill:9": {backfillFrom: due[0]},
+	}
+	for value, expected := range policies {
+		policy, err := ParseCatchUpPolicy(value)
+		if err != nil {
+			t.Fatalf("Could not parse policy %q: %v", value, err)
+		}
+		if plan := policy.plan(due); plan != expected {
+			t.Fatalf("Policy %q planned %+v, expected %+v", value, plan, expected)
+		}
+		if plan := policy.plan(due[:1]); !plan.run {
+			t.Fatalf("Policy %q didn't run a job that's on time", value)
+		}
+	}
+	for _, invalid := range []string{"backfill", "backfill:0", "skip:2", "replay"} {
+		if _, err := ParseCatchUpPolicy(invalid); err == nil {
+			t.Fatalf("Parsed invalid policy %q", invalid)
+		}
+	}
+	skip := CatchUpPolicy{Mode: CatchUpSkip}
+	catchUp := CatchUpPolicies{Resources: map[metadata.NameVariant]CatchUpPolicy{{Name: "avg_txn", Variant: "v1"}: skip}}
+	if catchUp.For(metadata.ResourceID{Name: "avg_txn", Variant: "v1", Type: metadata.FEATURE_VARIANT}) != skip {
+		t.Fatalf("Resource didn't get its own policy")
+	}
+	if catchUp.For(metadata.ResourceID{Name: "avg_txn", Variant: "v2", Type: metadata.FEATURE_VARIANT}) != (CatchUpPolicy{}) {
+		t.Fatalf("Resource without a policy didn't get the default")
+	}
+}
+
+func TestKubernetesJobSpawnerImage(t *testing.T) {
+	os.Setenv("WORKER_IMAGE", "worker")
+	defer os.Unsetenv("WORKER_IMAGE")
+	config, err := (&runner.MaterializedRunnerConfig{OnlineType: "REDIS_ONLINE", OfflineType: "SPARK_OFFLINE"}).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %v", err)
+	}
+	spawner := &KubernetesJobSpawner{}
+	if image := spawner.image(config); image != "worker" {
+		t.Fatalf("Default spawner uses image %s, expected WORKER_IMAGE", image)
+	}
+	spawner.ProviderImages = map[provider.Type]string{"REDIS_ONLINE": "redis-worker", "SPARK_OFFLINE": "spark-worker"}
+	if image := spawner.image(config); image != "spark-worker" {
+		t.Fatalf("Spawner uses image %s, expected the offline provider's image", image)
+	}
+	spawner = &KubernetesJobSpawner{Image: "custom", ProviderImages: map[provider.Type]string{"SNOWFLAKE_OFFLINE": "snowflake-worker"}}
+	if image := spawner.image(config); image != "custom" {
+		t.Fatalf("Spawner uses image %s, expected custom", image)
+	}
+}
+
+func TestJobRunStatus(t *testing.T) {
+	ctx := context.Background()
+	if status := jobRunStatus(ctx, nil); status != metadata.JobRunSucceeded {
+		t.Fatalf("Successful run has status %s", status)
+	}
+	if status := jobRunStatus(ctx, errors.New("failed")); status != metadata.JobRunFailed {
+		t.Fatalf("Failed run has status %s", status)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if status := jobRunStatus(cancelled, context.Canceled); status != metadata.JobRunCancelled {
+		t.Fatalf("Cancelled run has status %s", status)
+	}
+	lease, interrupt := context.WithCancel(ctx)
+	interrupt()
+	if status := jobRunStatus(context.WithValue(lease, leaseKey{}, lease), context.Canceled); status != metadata.JobRunInterrupted {
+		t.Fatalf("Interrupted run has status %s", status)
+	}
+}
+
+func TestSendNotification(t *testing.T) {
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		if r.URL.Path == "/broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	job := JobNotification{
+		Event:    metadata.NotifyJobFailed,
+		Resource: metadata.ResourceID{Name: "avg_amount", Variant: "v1", Type: metadata.FEATURE_VARIANT},
+		Message:  "warehouse unavailable",
+		Time:     time.Now().UTC(),
+	}
+	notifications := []metadata.Notification{
+		{Name: "hook", Kind: metadata.WebhookNotification, URL: server.URL + "/hook"},
+		{Name: "slack", Kind: metadata.SlackNotification, URL: server.URL + "/slack"},
+		{Name: "pager", Kind: metadata.PagerDutyNotification, URL: server.URL + "/pager", RoutingKey: "key"},
+	}
+	for _, n := range notifications {
+		if err := sendNotification(context.Background(), server.Client(), n, job); err != nil {
+			t.Fatalf("Could not send %s notification: %v", n.Kind, err)
+		}
+	}
+	if bodies[0]["event"] != string(metadata.NotifyJobFailed) || bodies[0]["resource"].(map[string]interface{})["name"] != "avg_amount" {
+		t.Fatalf("Unexpected webhook body: %v", bodies[0])
+	}
+	if text, _ := bodies[1]["text"].(string); !strings.Contains(text, "warehouse unavailable") {
+		t.Fatalf("Unexpected slack body: %v", bodies[1])
+	}
+	if bodies[2]["routing_key"] != "key" || bodies[2]["event_action"] != "trigger" {
+		t.Fatalf("Unexpected pagerduty body: %v", bodies[2])
+	}
+	job.Event = metadata.NotifyJobSucceeded
+	if event := pagerDutyEvent("key", job); event["event_action"] != "resolve" || event["dedup_key"] != bodies[2]["dedup_key"] {
+		t.Fatalf("Succeeded job doesn't resolve its incident: %v", event)
+	}
+	broken := metadata.Notification{Name: "broken", Kind: metadata.WebhookNotification, URL: server.URL + "/broken"}
+	if err := sendNotification(context.Background(), server.Client(), broken, job); err == nil {
+		t.Fatalf("Expected failing webhook to return an error")
+	}
+}
+
+func TestDeleteResourceDataDeletesJobsWithMocks(t *testing.T) {
+	c, meta, _, spawner := newMockCoordinator()
+	meta.AddTrainingSetVariant(&pb.TrainingSetVariant{Name: "fraud", Variant: "v1", Provider: "offline"})
+	id := metadata.ResourceID{Name: "fraud", Variant: "v1", Type: metadata.TRAINING_SET_VARIANT}
+	if err := c.DeleteResourceData(id); err != nil {
+		t.Fatalf("Failed to delete resource data: %s", err)
+	}
+	if deleted := spawner.Deleted(); len(deleted) != 1 || deleted[0] != id {
+		t.Fatalf("Expected jobs of %v to be deleted, got %v", id, deleted)
+	}
+	c.Spawner = &MemoryJobSpawner{}
+	if err := c.removeSchedule(context.Background(), id); err != nil {
+		t.Fatalf("Expected spawner without jobs to clean up to be skipped: %s", err)
+	}
+}
+
+func TestDeleteResourceDataKeepsDataInUseWithMocks(t *testing.T) {
+	c, meta, _, _ := newMockCoordinator()
+	meta.AddFeatureVariant(&pb.FeatureVariant{
+		Name:         "avg_amount",
+		Variant:      "v1",
+		Source:       &pb.NameVariant{Name: "transactions", Variant: "default"},
+		Provider:     "online",
+		Trainingsets: []*pb.NameVariant{{Name: "fraud", Variant: "v1"}},
+	})
+	meta.AddTrainingSetVariant(&pb.TrainingSetVariant{
+		Name:     "fraud",
+		Variant:  "v1",
+		Provider: "offline",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_READY},
+	})
+	id := metadata.ResourceID{Name: "avg_amount", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	err := c.DeleteResourceData(id)
+	inUse, ok := err.(*ResourceDataInUse)
+	if !ok {
+		t.Fatalf("Expected data a READY training set uses to be kept, got %v", err)
+	}
+	if len(inUse.Dependents) != 1 || inUse.Dependents[0].Name != "fraud" {
+		t.Fatalf("Unexpected dependents: %v", inUse.Dependents)
+	}
+	ts := metadata.ResourceID{Name: "fraud", Variant: "v1", Type: metadata.TRAINING_SET_VARIANT}
+	if err := meta.SetStatus(context.Background(), ts, metadata.FAILED, "failed"); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+	if err := c.checkDataUnused(context.Background(), id); err != nil {
+		t.Fatalf("Expected data only a failed training set uses to be deletable: %s", err)
+	}
+}
+
+func TestDeleteResourceDataDeletesLabelOnlineTableWithMocks(t *testing.T) {
+	c, meta, _, _ := newMockCoordinator()
+	online := provider.NewLocalOnlineStore()
+	c.Providers.(*mocks.Providers).Add(provider.RedisOnline, online)
+	meta.AddLabelVariant(&pb.LabelVariant{
+		Name:           "fraud",
+		Variant:        "v1",
+		Source:         &pb.NameVariant{Name: "transactions", Variant: "default"},
+		OnlineProvider: "online",
+	})
+	resID := provider.ResourceID{Name: "fraud", Variant: "v1", Type: provider.Label}
+	if _, err := online.CreateTable(provider.MaterializedName(resID), resID.Variant, provider.Bool); err != nil {
+		t.Fatalf("Failed to create label table: %s", err)
+	}
+	id := metadata.ResourceID{Name: "fraud", Variant: "v1", Type: metadata.LABEL_VARIANT}
+	if err := c.DeleteResourceData(id); err != nil {
+		t.Fatalf("Failed to delete resource data: %s", err)
+	}
+	if _, err := online.GetTable(provider.MaterializedName(resID), resID.Variant); err == nil {
+		t.Fatalf("Expected the label's online table to be deleted")
+	}
+}
+
+func TestPlanJobWithMocks(t *testing.T) {
+	c, meta, _, spawner := newMockCoordinator()
+	meta.AddSourceVariant(&pb.SourceVariant{
+		Name:     "users",
+		Variant:  "default",
+		Provider: "offline",
+		Definition: &pb.SourceVariant_PrimaryData{PrimaryData: &pb.PrimaryData{
+			Location: &pb.PrimaryData_Table{Table: &pb.PrimarySQLTable{Name: "users"}},
+		}},
+	})
+	meta.AddSourceVariant(&pb.SourceVariant{
+		Name:     "active_users",
+		Variant:  "v1",
+		Provider: "offline",
+		Schedule: "@daily",
+		Definition: &pb.SourceVariant_Transformation{Transformation: &pb.Transformation{
+			Type: &pb.Transformation_SQLTransformation{SQLTransformation: &pb.SQLTransformation{
+				Query:  "SELECT * FROM {{users.default}} WHERE active",
+				Source: []*pb.NameVariant{{Name: "users", Variant: "default"}},
+			}},
+		}},
+	})
+	id := metadata.ResourceID{Name: "active_users", Variant: "v1", Type: metadata.SOURCE_VARIANT}
+	plans, err := c.PlanJobs(context.Background(), []metadata.ResourceID{id})
+	if err != nil {
+		t.Fatalf("Failed to plan job: %s", err)
+	}
+	plan := plans[0]
+	if len(plan.Problems) != 0 {
+		t.Fatalf("Expected no problems, got %v", plan.Problems)
+	}
+	if plan.Runner != runner.CREATE_TRANSFORMATION || plan.Schedule != "@daily" {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+	table, _ := provider.GetPrimaryTableName(provider.ResourceID{Name: "users", Variant: "default"})
+	if !strings.Contains(plan.Query, sanitize(table)) || strings.Contains(plan.Query, "{{") {
+		t.Fatalf("Expected query to read from %s, got %s", table, plan.Query)
+	}
+	usersID := metadata.ResourceID{Name: "users", Variant: "default", Type: metadata.SOURCE_VARIANT}
+	if len(plan.Dependencies) != 1 || plan.Dependencies[0] != usersID {
+		t.Fatalf("Expected dependency on users, got %v", plan.Dependencies)
+	}
+	if len(spawner.Jobs()) != 0 {
+		t.Fatalf("Expected planning not to run jobs, got %v", spawner.Jobs())
+	}
+	planned, err := NewJobPlanner(c.Providers)(context.Background(), meta, []metadata.ResourceID{id})
+	if err != nil || len(planned) != 1 || planned[0].Query != plan.Query {
+		t.Fatalf("Expected the metadata server's planner to plan the same job, got %+v: %v", planned, err)
+	}
+	meta.AddFeatureVariant(&pb.FeatureVariant{
+		Name:     "age",
+		Variant:  "v1",
+		Source:   &pb.NameVariant{Name: "missing", Variant: "v1"},
+		Provider: "unregistered",
+	})
+	if _, err := c.PlanJob(context.Background(), metadata.ResourceID{Name: "age", Variant: "v1", Type: metadata.FEATURE_VARIANT}); err == nil {
+		t.Fatalf("Expected feature with missing source to fail planning")
+	}
+}
+
+func TestRollbackFailedJobWithMocks(t *testing.T) {
+	c, meta, offline, spawner := newMockCoordinator()
+	meta.AddLabelVariant(&pb.LabelVariant{
+		Name:     "is_fraud",
+		Variant:  "v1",
+		Source:   &pb.NameVariant{Name: "transactions", Variant: "default"},
+		Provider: "offline",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_PENDING},
+	})
+	id := metadata.ResourceID{Name: "is_fraud", Variant: "v1", Type: metadata.LABEL_VARIANT}
+	tableID := provider.ResourceID{Name: "is_fraud", Variant: "v1", Type: provider.Label}
+	jobType, _ := jobTypeOf(&metadata.CoordinatorJob{Resource: id})
+	jobErr := fmt.Errorf("online copy failed")
+	err := c.runWithRollback(context.Background(), jobType, Job{Resource: id}, func() error {
+		if _, err := offline.CreateResourceTable(tableID, provider.TableSchema{}); err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		meta.SetStatus(context.Background(), id, metadata.READY, "")
+		return jobErr
+	})
+	if err != jobErr {
+		t.Fatalf("Expected job's error, got %v", err)
+	}
+	if _, err := offline.GetResourceTable(tableID); err == nil {
+		t.Fatalf("Expected partially created table to be deleted")
+	}
+	if status, msg := meta.Status(id); status != metadata.PENDING || !strings.Contains(msg, "online copy failed") {
+		t.Fatalf("Expected status to be reset to pending, got %s: %s", status, msg)
+	}
+	if len(spawner.Deleted()) != 1 {
+		t.Fatalf("Expected label's jobs to be deleted, got %v", spawner.Deleted())
+	}
+	// A job for a resource that was already ready keeps its data.
+	if _, err := offline.CreateResourceTable(tableID, provider.TableSchema{}); err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	meta.SetStatus(context.Background(), id, metadata.READY, "")
+	c.runWithRollback(context.Background(), jobType, Job{Resource: id}, func() error { return jobErr })
+	if _, err := offline.GetResourceTable(tableID); err != nil {
+		t.Fatalf("Expected table of ready label to be kept: %s", err)
+	}
+}
+
+func TestSourceCycleWithMocks(t *testing.T) {
+	c, meta, _, _ := newMockCoordinator()
+	transformation := func(name string, inputs ...string) *pb.SourceVariant {
+		sources := make([]*pb.NameVariant, len(inputs))
+		for i, input := range inputs {
+			sources[i] = &pb.NameVariant{Name: input, Variant: "v"}
+		}
+		return &pb.SourceVariant{
+			Name:     name,
+			Variant:  "v",
+			Provider: "offline",
+			Definition: &pb.SourceVariant_Transformation{Transformation: &pb.Transformation{
+				Type: &pb.Transformation_SQLTransformation{SQLTransformation: &pb.SQLTransformation{Query: "SELECT 1", Source: sources}},
+			}},
+		}
+	}
+	meta.AddSourceVariant(&pb.SourceVariant{
+		Name:     "base",
+		Variant:  "v",
+		Provider: "offline",
+		Definition: &pb.SourceVariant_PrimaryData{PrimaryData: &pb.PrimaryData{
+			Location: &pb.PrimaryData_Table{Table: &pb.PrimarySQLTable{Name: "base"}},
+		}},
+	})
+	// left and right both read from base, which isn't a cycle.
+	meta.AddSourceVariant(transformation("left", "base"))
+	meta.AddSourceVariant(transformation("right", "base"))
+	meta.AddSourceVariant(transformation("diamond", "left", "right"))
+	meta.AddSourceVariant(transformation("a", "diamond", "b"))
+	meta.AddSourceVariant(transformation("b", "c"))
+	meta.AddSourceVariant(transformation("c", "a"))
+	diamond := metadata.ResourceID{Name: "diamond", Variant: "v", Type: metadata.SOURCE_VARIANT}
+	if err := c.checkSourceCycle(context.Background(), diamond); err != nil {
+		t.Fatalf("Expected no cycle upstream of diamond, got %s", err)
+	}
+	a := metadata.ResourceID{Name: "a", Variant: "v", Type: metadata.SOURCE_VARIANT}
+	cycle, err := c.sourceCycle(context.Background(), metadata.NameVariant{Name: "a", Variant: "v"})
+	if err != nil {
+		t.Fatalf("Failed to walk sources: %s", err)
+	}
+	if msg := (&DependencyCycleError{Cycle: cycle}).Error(); !strings.Contains(msg, "a (v) -> b (v) -> c (v) -> a (v)") {
+		t.Fatalf("Expected cycle through a, b and c, got %s", msg)
+	}
+	err = c.awaitDependencies(context.Background(), a)
+	if err == nil || re.IsRecoverable(err) || !strings.Contains(err.Error(), "circular source dependency") {
+		t.Fatalf("Expected waiting on a cycle to fail permanently, got %v", err)
+	}
+	plan, err := c.PlanJob(context.Background(), metadata.ResourceID{Name: "b", Variant: "v", Type: metadata.SOURCE_VARIANT})
+	if err != nil {
+		t.Fatalf("Failed to plan job: %s", err)
+	}
+	found := false
+	for _, problem := range plan.Problems {
+		found = found || strings.Contains(problem, "circular source dependency")
+	}
+	if !found {
+		t.Fatalf("Expected plan to report the cycle, got %v", plan.Problems)
+	}
+}
+
+func TestRefreshOnUpstreamChangeWithMocks(t *testing.T) {
+	c, meta, _, _ := newMockCoordinator()
+	ready := &pb.ResourceStatus{Status: pb.ResourceStatus_READY}
+	meta.AddSourceVariant(&pb.SourceVariant{
+		Name:     "transactions",
+		Variant:  "default",
+		Provider: "offline",
+		Status:   ready,
+		Features: []*pb.NameVariant{{Name: "avg_amount", Variant: "v1"}, {Name: "max_amount", Variant: "v1"}, {Name: "min_amount", Variant: "v1"}},
+		Labels:   []*pb.NameVariant{{Name: "fraud", Variant: "v1"}},
+	})
+	source := &pb.NameVariant{Name: "transactions", Variant: "default"}
+	training := []*pb.NameVariant{{Name: "fraud_training", Variant: "v1"}}
+	meta.AddFeatureVariant(&pb.FeatureVariant{Name: "avg_amount", Variant: "v1", Source: source, Provider: "online", Status: ready, Trainingsets: training, RefreshOnUpstreamChange: true})
+	meta.AddFeatureVariant(&pb.FeatureVariant{Name: "max_amount", Variant: "v1", Source: source, Provider: "online", Status: ready})
+	meta.AddFeatureVariant(&pb.FeatureVariant{Name: "min_amount", Variant: "v1", Source: source, Provider: "online", Status: &pb.ResourceStatus{Status: pb.ResourceStatus_PENDING}, RefreshOnUpstreamChange: true})
+	meta.AddLabelVariant(&pb.LabelVariant{Name: "fraud", Variant: "v1", Source: source, Provider: "offline", Trainingsets: training})
+	meta.AddTrainingSetVariant(&pb.TrainingSetVariant{
+		Name:                    "fraud_training",
+		Variant:                 "v1",
+		Provider:                "offline",
+		Status:                  ready,
+		Features:                []*pb.NameVariant{{Name: "avg_amount", Variant: "v1"}},
+		Label:                   &pb.NameVariant{Name: "fraud", Variant: "v1"},
+		RefreshOnUpstreamChange: true,
+	})
+	sourceID := metadata.ResourceID{Name: "transactions", Variant: "default", Type: metadata.SOURCE_VARIANT}
+	featureID := metadata.ResourceID{Name: "avg_amount", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	tsID := metadata.ResourceID{Name: "fraud_training", Variant: "v1", Type: metadata.TRAINING_SET_VARIANT}
+	targets, err := c.refreshTargets(context.Background(), sourceID)
+	if err != nil {
+		t.Fatalf("Could not get refresh targets of source: %v", err)
+	}
+	if len(targets) != 2 || targets[0] != featureID || targets[1] != tsID {
+		t.Fatalf("Expected the flagged ready feature and the label's training set to be refreshed, got %v", targets)
+	}
+	targets, err = c.refreshTargets(context.Background(), featureID)
+	if err != nil {
+		t.Fatalf("Could not get refresh targets of feature: %v", err)
+	}
+	if len(targets) != 1 || targets[0] != tsID {
+		t.Fatalf("Expected the feature's training set to be refreshed, got %v", targets)
+	}
+	if targets, err := c.refreshTargets(context.Background(), tsID); err != nil || len(targets) != 0 {
+		t.Fatalf("Expected nothing downstream of a training set, got %v: %v", targets, err)
+	}
+	serialized, err := c.trainingSetUpdateConfig(context.Background(), tsID)
+	if err != nil {
+		t.Fatalf("Could not build training set update config: %v", err)
+	}
+	var config runner.TrainingSetRunnerConfig
+	if err := config.Deserialize(serialized); err != nil {
+		t.Fatalf("Could not deserialize training set config: %v", err)
+	}
+	if !config.IsUpdate || len(config.Def.Features) != 1 || config.Def.Label.Name != "fraud" {
+		t.Fatalf("Unexpected training set update config: %#v", config)
+	}
+	if err := runUpdateJob(context.Background(), c, Job{Resource: sourceID}); err == nil || re.IsRecoverable(err) {
+		t.Fatalf("Expected refreshing a primary source to fail permanently, got %v", err)
+	}
+}
+
+func TestCompactUpdatedFeatureWithMocks(t *testing.T) {
+	c, meta, _, spawner := newMockCoordinator()
+	meta.AddFeatureVariant(&pb.FeatureVariant{
+		Name:     "avg_amount",
+		Variant:  "v1",
+		Source:   &pb.NameVariant{Name: "transactions", Variant: "default"},
+		Type:     "float32",
+		Entity:   "user",
+		Provider: "online",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_READY},
+		Location: &pb.FeatureVariant_Columns{Columns: &pb.Columns{Entity: "user_id", Value: "amount", Ts: "ts"}},
+	})
+	featureID := metadata.ResourceID{Name: "avg_amount", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	c.compactUpdatedFeature(context.Background(), featureID)
+	if jobs := spawner.Jobs(); len(jobs) != 0 {
+		t.Fatalf("Expected no compaction without a retention policy, got %#v", jobs)
+	}
+	c.MaterializationRetention = &runner.RetentionPolicy{KeepGenerations: 2}
+	c.GenerationArchiveURI = "s3://bucket/generations"
+	c.compactUpdatedFeature(context.Background(), metadata.ResourceID{Name: "transactions", Variant: "default", Type: metadata.SOURCE_VARIANT})
+	if jobs := spawner.Jobs(); len(jobs) != 0 {
+		t.Fatalf("Expected only features to be compacted, got %#v", jobs)
+	}
+	c.compactUpdatedFeature(context.Background(), featureID)
+	jobs := spawner.Jobs()
+	if len(jobs) != 1 || jobs[0].Name != runner.COMPACT_MATERIALIZATIONS || jobs[0].Schedule != "" {
+		t.Fatalf("Expected a compaction to be run after the update, got %#v", jobs)
+	}
+	var config runner.CompactionRunnerConfig
+	if err := config.Deserialize(jobs[0].Config); err != nil {
+		t.Fatalf("Could not deserialize compaction config: %v", err)
+	}
+	expected := []provider.ResourceID{{Name: "avg_amount", Variant: "v1", Type: provider.Feature}}
+	if !reflect.DeepEqual(config.Features, expected) || config.Policy.KeepGenerations != 2 || config.ArchiveURI != "s3://bucket/generations" || config.OfflineType != provider.PostgresOffline {
+		t.Fatalf("Unexpected compaction config: %#v", config)
+	}
+}
+
+func TestLeadershipOwnership(t *testing.T) {
+	c, _, _, _ := newMockCoordinator()
+	key := "JOB__FEATURE_VARIANT__avg_amount__v1"
+	if !c.ownsJob(key) {
+		t.Fatalf("Coordinator without a leadership or partition doesn't own every job")
+	}
+	c.Leadership = NewLeadership("coordinator-a")
+	if c.ownsJob(key) {
+		t.Fatalf("Standby coordinator owns a job")
+	}
+	c.Leadership.setLeading(true)
+	if !c.ownsJob(key) {
+		t.Fatalf("Leader doesn't own a job")
+	}
+	c.Partition = NewPartitioner("coordinator-a")
+	c.Partition.setMembers([]string{"coordinator-a", "coordinator-b"})
+	if c.ownsJob(key) != c.Partition.Owns(key) {
+		t.Fatalf("Leader with a partition claims jobs outside of it")
+	}
+	c.Leadership.setLeading(false)
+	if c.ownsJob(key) {
+		t.Fatalf("Coordinator that lost leadership owns a job")
+	}
+}
+
+func TestJobErrorCodesWithMocks(t *testing.T) {
+	c, meta, _, _ := newMockCoordinator()
+	if _, err := templateReplace("SELECT * FROM {{ missing.v1 }}", nil, nil, postgresSyntax); metadata.ErrorCodeOf(err) != metadata.ErrorTemplateInvalid {
+		t.Fatalf("Expected an invalid template error, got %v", err)
+	}
+	meta.AddSourceVariant(&pb.SourceVariant{
+		Name:     "broken",
+		Variant:  "v1",
+		Provider: "offline",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_FAILED},
+	})
+	if _, err := c.AwaitPendingSource(metadata.NameVariant{Name: "broken", Variant: "v1"}); metadata.ErrorCodeOf(err) != metadata.ErrorSourceNotReady {
+		t.Fatalf("Expected a source not ready error, got %v", err)
+	}
+	if _, err := c.providers().Get(provider.SnowflakeOffline, nil); metadata.ErrorCodeOf(err) != metadata.ErrorProviderConfigInvalid {
+		t.Fatalf("Expected an invalid provider config error, got %v", err)
+	}
+	c.Providers = ProviderFactoryFunc(func(provider.Type, provider.SerializedConfig) (provider.Provider, error) {
+		return nil, errors.New(`pq: password authentication failed for user "featureform"`)
+	})
+	if _, err := c.providers().Get(provider.PostgresOffline, nil); metadata.ErrorCodeOf(err) != metadata.ErrorProviderAuthFailed {
+		t.Fatalf("Expected a provider auth error, got %v", err)
+	}
+	cycle := fmt.Errorf("wait for dependencies: %w", &DependencyCycleError{})
+	if code := metadata.ErrorCodeOf(cycle); code != metadata.ErrorDependencyCycle {
+		t.Fatalf("Expected a dependency cycle error, got %s", code)
+	}
+	resID := metadata.ResourceID{Name: "broken", Variant: "v1", Type: metadata.SOURCE_VARIANT}
+	if err := meta.SetStatusWithCode(context.Background(), resID, metadata.FAILED, metadata.ErrorSQLSyntaxError, "syntax error"); err != nil {
+		t.Fatalf("Could not set status: %v", err)
+	}
+	source, err := meta.GetSourceVariant(context.Background(), metadata.NameVariant{Name: "broken", Variant: "v1"})
+	if err != nil {
+		t.Fatalf("Could not get source: %v", err)
+	}
+	if source.ErrorCode() != metadata.ErrorSQLSyntaxError || meta.ErrorCode(resID) != metadata.ErrorSQLSyntaxError {
+		t.Fatalf("Expected the status to keep its error code, got %s", source.ErrorCode())
+	}
+}
+
+func TestClaimLimiter(t *testing.T) {
+	limiter := NewClaimLimiter(10, 3)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		if wait := limiter.reserve(now); wait != 0 {
+			t.Fatalf("Claim %d of a burst waited %s", i, wait)
+		}
+	}
+	if wait := limiter.reserve(now); wait != 100*time.Millisecond {
+		t.Fatalf("Expected a claim over the burst to wait 100ms, got %s", wait)
+	}
+	if wait := limiter.reserve(now); wait != 200*time.Millisecond {
+		t.Fatalf("Expected the next claim to wait 200ms, got %s", wait)
+	}
+	// After a quiet period, a full burst is available again, but no more.
+	later := now.Add(time.Minute)
+	for i := 0; i < 3; i++ {
+		if wait := limiter.reserve(later); wait != 0 {
+			t.Fatalf("Claim %d of a later burst waited %s", i, wait)
+		}
+	}
+	if wait := limiter.reserve(later); wait == 0 {
+		t.Fatalf("Claim over a later burst didn't wait")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx); err == nil {
+		t.Fatalf("Waiting with a cancelled context succeeded")
+	}
+}
+
+func TestManualRunWithMocks(t *testing.T) {
+	c, meta, _, _ := newMockCoordinator()
+	ready := &pb.ResourceStatus{Status: pb.ResourceStatus_READY}
+	meta.AddSourceVariant(&pb.SourceVariant{
+		Name:     "users",
+		Variant:  "default",
+		Provider: "offline",
+		Status:   ready,
+		Definition: &pb.SourceVariant_PrimaryData{PrimaryData: &pb.PrimaryData{
+			Location: &pb.PrimaryData_Table{Table: &pb.PrimarySQLTable{Name: "users"}},
+		}},
+	})
+	meta.AddSourceVariant(&pb.SourceVariant{
+		Name:     "active_users",
+		Variant:  "v1",
+		Provider: "offline",
+		Status:   ready,
+		Definition: &pb.SourceVariant_Transformation{Transformation: &pb.Transformation{
+			Type: &pb.Transformation_SQLTransformation{SQLTransformation: &pb.SQLTransformation{
+				Query:  "SELECT * FROM {{users.default}} WHERE active",
+				Source: []*pb.NameVariant{{Name: "users", Variant: "default"}},
+			}},
+		}},
+	})
+	id := metadata.ResourceID{Name: "active_users", Variant: "v1", Type: metadata.SOURCE_VARIANT}
+	jobType, err := jobTypeOf(&metadata.CoordinatorJob{Resource: id, Kind: metadata.ManualRunJobKind, Trigger: metadata.TriggerManual})
+	if err != nil {
+		t.Fatalf("Manual runs have no job type: %v", err)
+	}
+	if jobType.Rollback != nil {
+		t.Fatalf("Expected a manual run not to roll back its resource")
+	}
+	serialized, err := c.transformationUpdateConfig(context.Background(), id, nil)
+	if err != nil {
+		t.Fatalf("Could not build transformation update config: %v", err)
+	}
+	var config runner.CreateTransformationConfig
+	if err := config.Deserialize(serialized); err != nil {
+		t.Fatalf("Could not deserialize transformation config: %v", err)
+	}
+	if !config.IsUpdate || config.Backfill != nil || strings.Contains(config.TransformationConfig.Query, "{{") {
+		t.Fatalf("Unexpected transformation update config: %#v", config)
+	}
+	primaryID := metadata.ResourceID{Name: "users", Variant: "default", Type: metadata.SOURCE_VARIANT}
+	if _, err := c.transformationUpdateConfig(context.Background(), primaryID, nil); err == nil || re.IsRecoverable(err) {
+		t.Fatalf("Expected updating a primary source to fail permanently, got %v", err)
+	}
+}
+
+// progressWatcher reports a chunk done every time its progress is read,
+// until all its chunks are, and then completes.
+type progressWatcher struct {
+	*runner.SyncWatcher
+	chunks int
+	done   int
+}
+
+func (w *progressWatcher) Progress() runner.Progress {
+	if w.done < w.chunks {
+		w.done++
+	}
+	if w.done == w.chunks && !w.Complete() {
+		w.EndWatch(nil)
+	}
+	return runner.Progress{ChunksDone: w.done, TotalChunks: w.chunks}
+}
+
+func TestWaitReportingProgress(t *testing.T) {
+	c, meta, _, _ := newMockCoordinator()
+	meta.AddFeatureVariant(&pb.FeatureVariant{Name: "avg_amount", Variant: "v1", Provider: "online"})
+	id := metadata.ResourceID{Name: "avg_amount", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	c.ProgressInterval = time.Millisecond
+	watcher := &progressWatcher{
+		SyncWatcher: &runner.SyncWatcher{ResultSync: &runner.ResultSync{}, DoneChannel: make(chan interface{})},
+		chunks:      3,
+	}
+	if err := c.waitReportingProgress(context.Background(), id, watcher); err != nil {
+		t.Fatalf("Failed to wait for job: %s", err)
+	}
+	reports := meta.Progress(id)
+	if len(reports) != 2 || reports[0].ChunksDone != 1 || reports[1].ChunksDone != 2 || reports[1].TotalChunks != 3 {
+		t.Fatalf("Expected progress to be reported until the job completed, got %v", reports)
+	}
+	if status, _ := meta.Status(id); status != metadata.PENDING {
+		t.Fatalf("Expected reporting progress to keep the feature pending, got %v", status)
+	}
+}
+
+type rejectingVerifier struct {
+	verified []string
+}
+
+func (v *rejectingVerifier) Verify(ctx context.Context, image string) (string, error) {
+	v.verified = append(v.verified, image)
+	return "", errors.New("image isn't signed")
+}
+
+// pinningVerifier verifies every image, pinning those without a digest to
+// digest.
+type pinningVerifier struct {
+	digest string
+}
+
+func (v pinningVerifier) Verify(ctx context.Context, image string) (string, error) {
+	return runner.PinImage(image, v.digest)
+}
+
+func TestKubernetesSpawnerVerifiesImage(t *testing.T) {
+	config, err := (&runner.MaterializedRunnerConfig{OnlineType: "REDIS_ONLINE", OfflineType: "SPARK_OFFLINE"}).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %v", err)
+	}
+	digest := "sha256:" + strings.Repeat("a", 64)
+	verifier := &rejectingVerifier{}
+	spawner := &KubernetesJobSpawner{Image: "worker:v1", ImageDigest: digest, Verifier: verifier}
+	if _, err := spawner.GetJobRunner(runner.MATERIALIZE, config, []string{"localhost:2379"}, metadata.ResourceID{Name: "f", Variant: "v", Type: metadata.FEATURE_VARIANT}); err == nil {
+		t.Fatalf("Expected a job with an unsigned image not to be spawned")
+	}
+	if len(verifier.verified) != 1 || verifier.verified[0] != "worker:v1@"+digest {
+		t.Fatalf("Expected the pinned image to be verified, got %v", verifier.verified)
+	}
+	spawner.ProviderImages = map[provider.Type]string{"SPARK_OFFLINE": "spark-worker:v1"}
+	if digest := spawner.imageDigest(config); digest != "" {
+		t.Fatalf("Expected a provider image not to be pinned to the default image's digest, got %s", digest)
+	}
+}
+
+func TestKubernetesSpawnerPinsChunkJobImage(t *testing.T) {
+	config, err := (&runner.MaterializedRunnerConfig{OnlineType: "REDIS_ONLINE", OfflineType: "POSTGRES_OFFLINE"}).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %v", err)
+	}
+	digest := "sha256:" + strings.Repeat("c", 64)
+	spawner := &KubernetesJobSpawner{Image: "worker:v1", Verifier: pinningVerifier{digest}}
+	_, chunkConfig, err := spawner.jobImage(runner.MATERIALIZE, config)
+	if err != nil {
+		t.Fatalf("Failed to set chunk job config: %v", err)
+	}
+	materialize := &runner.MaterializedRunnerConfig{}
+	if err := materialize.Deserialize(chunkConfig); err != nil {
+		t.Fatalf("Failed to deserialize config: %v", err)
+	}
+	// Chunk jobs run by the digest verified when the job was spawned,
+	// rather than by the tag.
+	if expected := "worker:v1@" + digest; materialize.Image != expected {
+		t.Fatalf("Expected chunk jobs to run %s, got %s", expected, materialize.Image)
+	}
+	if materialize.OnlineType != "REDIS_ONLINE" || materialize.OfflineType != "POSTGRES_OFFLINE" {
+		t.Fatalf("Expected the rest of the config to be kept, got %+v", materialize)
+	}
+	// A provider's image is used for its chunk jobs too.
+	spawner.ProviderImages = map[provider.Type]string{"POSTGRES_OFFLINE": "postgres-worker:v2"}
+	if _, chunkConfig, err = spawner.jobImage(runner.MATERIALIZE, config); err != nil {
+		t.Fatalf("Failed to set chunk job config: %v", err)
+	}
+	if err := materialize.Deserialize(chunkConfig); err != nil {
+		t.Fatalf("Failed to deserialize config: %v", err)
+	}
+	if expected := "postgres-worker:v2@" + digest; materialize.Image != expected {
+		t.Fatalf("Expected chunk jobs to run the provider's image %s, got %s", expected, materialize.Image)
+	}
+}
+
+func TestKubernetesSpawnerChunkJobPods(t *testing.T) {
+	config, err := (&runner.MaterializedRunnerConfig{OnlineType: "REDIS_ONLINE", OfflineType: "POSTGRES_OFFLINE"}).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %v", err)
+	}
+	ttl := int32(600)
+	copyResources := v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")}}
+	spawner := &KubernetesJobSpawner{
+		Image: "worker:v1",
+		Pod: runner.KubernetesPodConfig{
+			NodeSelector:            map[string]string{"pool": "workers"},
+			ServiceAccount:          "worker",
+			Labels:                  map[string]string{"team": "ml"},
+			TTLSecondsAfterFinished: &ttl,
+		},
+		JobResources: map[string]v1.ResourceRequirements{string(runner.COPY_TO_ONLINE): copyResources},
+	}
+	_, chunkConfig, err := spawner.jobImage(runner.MATERIALIZE, config)
+	if err != nil {
+		t.Fatalf("Failed to set chunk job config: %v", err)
+	}
+	materialize := &runner.MaterializedRunnerConfig{}
+	if err := materialize.Deserialize(chunkConfig); err != nil {
+		t.Fatalf("Failed to deserialize config: %v", err)
+	}
+	pod := materialize.Pod
+	if pod == nil || pod.ServiceAccount != "worker" || pod.NodeSelector["pool"] != "workers" || pod.Labels["team"] != "ml" {
+		t.Fatalf("Expected chunk jobs to get the spawner's pod config, got %+v", pod)
+	}
+	if pod.TTLSecondsAfterFinished == nil || *pod.TTLSecondsAfterFinished != ttl {
+		t.Fatalf("Expected chunk jobs to be kept for %d seconds, got %v", ttl, pod.TTLSecondsAfterFinished)
+	}
+	if memory := pod.Resources.Limits[v1.ResourceMemory]; memory.String() != "4Gi" {
+		t.Fatalf("Expected chunk jobs to get the copy job's resources, got %v", pod.Resources)
+	}
+}
+
+func TestSubprocessJobSpawner(t *testing.T) {
+	id := metadata.ResourceID{Name: "f", Variant: "v", Type: metadata.FEATURE_VARIANT}
+	spawner := &SubprocessJobSpawner{Binary: "false"}
+	jobRunner, err := spawner.GetJobRunner(runner.MATERIALIZE, []byte("{}"), []string{"localhost:2379"}, id)
+	if err != nil {
+		t.Fatalf("Failed to get job runner: %v", err)
+	}
+	if jobRunner.Resource() != id {
+		t.Fatalf("Job runner has resource %v, expected %v", jobRunner.Resource(), id)
+	}
+	watcher, err := jobRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run job: %v", err)
+	}
+	if err := watcher.Wait(); err == nil {
+		t.Fatalf("Expected a failed worker process to fail the job")
+	}
+}
+
+func TestRunContextLogsToRun(t *testing.T) {
+	id := metadata.ResourceID{Name: "f", Variant: "v", Type: metadata.FEATURE_VARIANT}
+	coord := &Coordinator{Logger: zap.NewNop().Sugar()}
+	logger, logs := runner.CaptureLogs(coord.Logger, jobRunLogBytes)
+	coord.runLoggers.Store(id, logger)
+	runner.Logger(coord.jobContext(id)).Infow("Copying rows", "rows", 10)
+	if captured := logs.String(); !strings.Contains(captured, "Copying rows") {
+		t.Fatalf("Runner log wasn't captured for the run: %q", captured)
+	}
+	other := metadata.ResourceID{Name: "g", Variant: "v", Type: metadata.FEATURE_VARIANT}
+	if runner.Logger(coord.runContext(context.Background(), other)) == logger {
+		t.Fatalf("Resource without a run in progress logged to another resource's run")
+	}
+}
+
+func TestCompactionRevisionKeepsMargin(t *testing.T) {
+	tests := map[string]struct {
+		previous int64
+		expected int64
+	}{
+		"NoPrevious":  {previous: 0, expected: 0},
+		"UnderMargin": {previous: CompactionRetainedRevisions, expected: 0},
+		"OverMargin":  {previous: CompactionRetainedRevisions + 25, expected: 25},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if revision := compactionRevision(test.previous); revision != test.expected {
+				t.Fatalf("Expected to compact to %d after %d, got %d", test.expected, test.previous, revision)
+			}
+		})
+	}
+}
+
+const (
+	testStreamType       provider.Type = "TEST_STREAM"
+	testStreamOnlineType provider.Type = "TEST_STREAM_ONLINE"
+)
+
+var (
+	registerStreamProviders sync.Once
+	testStream              = provider.NewMemoryStreamingSource()
+	testStreamOnline        = provider.NewLocalOnlineStore()
+)
+
+// streamTestProviders registers providers that return the same stream and
+// online store whatever their config, so that the runners a
+// MemoryJobSpawner creates share them with the test.
+func streamTestProviders(t *testing.T) {
+	registerStreamProviders.Do(func() {
+		if err := provider.RegisterFactory(testStreamType, func(provider.SerializedConfig) (provider.Provider, error) {
+			return testStream, nil
+		}); err != nil {
+			t.Fatalf("Failed to register stream factory: %v", err)
+		}
+		if err := provider.RegisterFactory(testStreamOnlineType, func(provider.SerializedConfig) (provider.Provider, error) {
+			return testStreamOnline, nil
+		}); err != nil {
+			t.Fatalf("Failed to register online factory: %v", err)
+		}
+	})
+}
+
+func TestStreamToOnlineJobWithMocks(t *testing.T) {
+	streamTestProviders(t)
+	if err := runner.RegisterFactory(string(runner.STREAM_TO_ONLINE), runner.StreamToOnlineRunnerFactory); err != nil {
+		t.Fatalf("Failed to register stream runner factory: %v", err)
+	}
+	defer runner.UnregisterFactory(string(runner.STREAM_TO_ONLINE))
+	c, meta, _, _ := newMockCoordinator()
+	c.Spawner = &MemoryJobSpawner{}
+	c.Providers.(*mocks.Providers).Add(testStreamType, testStream)
+	meta.AddProvider(&pb.Provider{Name: "stream", Type: string(testStreamType), SerializedConfig: []byte("{}")})
+	meta.AddProvider(&pb.Provider{Name: "stream-online", Type: string(testStreamOnlineType), SerializedConfig: []byte("{}")})
+	meta.AddSourceVariant(&pb.SourceVariant{
+		Name:     "clicks",
+		Variant:  "default",
+		Provider: "stream",
+		Definition: &pb.SourceVariant_PrimaryData{PrimaryData: &pb.PrimaryData{
+			Location: &pb.PrimaryData_Table{Table: &pb.PrimarySQLTable{Name: "clicks_topic"}},
+		}},
+	})
+	meta.AddFeatureVariant(&pb.FeatureVariant{
+		Name:     "last_page",
+		Variant:  "v1",
+		Source:   &pb.NameVariant{Name: "clicks", Variant: "default"},
+		Type:     "string",
+		Entity:   "user",
+		Provider: "stream-online",
+		Status:   &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+		Location: &pb.FeatureVariant_Columns{Columns: &pb.Columns{Entity: "user", Value: "page"}},
+	})
+	sourceID := metadata.ResourceID{Name: "clicks", Variant: "default", Type: metadata.SOURCE_VARIANT}
+	if err := c.runRegisterSourceJob(sourceID, ""); err != nil {
+		t.Fatalf("Register stream source job failed: %v", err)
+	}
+	if status, msg := meta.Status(sourceID); status != metadata.READY {
+		t.Fatalf("Expected stream source to be ready, got %s: %s", status, msg)
+	}
+	featureID := metadata.ResourceID{Name: "last_page", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	expectValue := func(entity, expected string) {
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			var value interface{}
+			table, err := testStreamOnline.GetTable("last_page", "v1")
+			if err == nil {
+				value, err = table.Get(entity)
+			}
+			if err == nil && value == expected {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Expected %s to be streamed as %s, got %v (%v)", entity, expected, value, err)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	// The job runs until it's cancelled, like a claimed job's is.
+	stream := func() (context.CancelFunc, chan error) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c.jobContexts.Store(featureID, ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- c.runFeatureMaterializeJob(featureID, "")
+		}()
+		return cancel, done
+	}
+	testStream.Publish("clicks_topic", provider.ResourceRecord{Entity: "a", Value: "home"}, provider.ResourceRecord{Entity: "b", Value: "cart"})
+	cancel, done := stream()
+	expectValue("a", "home")
+	expectValue("b", "cart")
+	if status, msg := meta.Status(featureID); status != metadata.READY {
+		t.Fatalf("Expected streamed feature to be ready, got %s: %s", status, msg)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected stopped stream job to end as cancelled, got %v", err)
+	}
+	// A stream job that's run again, like after its coordinator goes away,
+	// carries on with the ready feature.
+	testStream.Publish("clicks_topic", provider.ResourceRecord{Entity: "a", Value: "checkout"})
+	cancel, done = stream()
+	defer cancel()
+	expectValue("a", "checkout")
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected stopped stream job to end as cancelled, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package coordinator
+
+import (
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/runner"
+)
+
+// CredentialStore is implemented by job spawners that give their workers
+// provider credentials some other way than in the job config.
+type CredentialStore interface {
+	StoreProviderConfig(name string, config provider.SerializedConfig) error
+}
+
+// StoreProviderConfig keeps the config in the secret projected into worker
+// pods, so jobs can be given a reference to it instead.
+func (k *KubernetesJobSpawner) StoreProviderConfig(name string, config provider.SerializedConfig) error {
+	return runner.StoreProviderCredentials(name, config)
+}
+
+// StoreProviderConfig keeps the config in the secret projected into workflow
+// pods, the same one Kubernetes jobs use.
+func (a *ArgoJobSpawner) StoreProviderConfig(name string, config provider.SerializedConfig) error {
+	return runner.StoreProviderCredentials(name, config)
+}
+
+// runnerProviderConfig returns what a runner config should hold for p: a
+// reference to its stored credentials if the spawner supports it, and the
+// serialized config otherwise.
+func (c *Coordinator) runnerProviderConfig(p *metadata.Provider) (provider.SerializedConfig, error) {
+	store, ok := c.Spawner.(CredentialStore)
+	if !ok {
+		return p.SerializedConfig(), nil
+	}
+	if err := store.StoreProviderConfig(p.Name(), p.SerializedConfig()); err != nil {
+		return nil, fmt.Errorf("store %s credentials: %w", p.Name(), err)
+	}
+	return runner.ProviderConfigRef(p.Name()), nil
+}
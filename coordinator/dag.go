@@ -0,0 +1,260 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// DependencyPollInterval is how often a job that's waiting on its
+// dependencies checks whether they're ready.
+var DependencyPollInterval = time.Second
+
+// jobDependencies returns the resources that must be READY before the job for
+// id can run. They're the resource's direct parents in the resource graph, so
+// waiting on them also waits on everything further upstream.
+func (c *Coordinator) jobDependencies(ctx context.Context, id metadata.ResourceID) ([]metadata.ResourceID, error) {
+	nv := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	switch id.Type {
+	case metadata.SOURCE_VARIANT:
+		source, err := c.store().GetSourceVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get source variant: %w", err)
+		}
+		if !source.IsSQLTransformation() {
+			return nil, nil
+		}
+		return resourceIDs(source.SQLTransformationSources(), metadata.SOURCE_VARIANT), nil
+	case metadata.FEATURE_VARIANT:
+		feature, err := c.store().GetFeatureVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get feature variant: %w", err)
+		}
+		return resourceIDs([]metadata.NameVariant{feature.Source()}, metadata.SOURCE_VARIANT), nil
+	case metadata.LABEL_VARIANT:
+		label, err := c.store().GetLabelVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get label variant: %w", err)
+		}
+		return resourceIDs([]metadata.NameVariant{label.Source()}, metadata.SOURCE_VARIANT), nil
+	case metadata.TRAINING_SET_VARIANT:
+		ts, err := c.store().GetTrainingSetVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get training set variant: %w", err)
+		}
+		deps := resourceIDs(ts.Features(), metadata.FEATURE_VARIANT)
+		return append(deps, metadata.ResourceID{Name: ts.Label().Name, Variant: ts.Label().Variant, Type: metadata.LABEL_VARIANT}), nil
+	default:
+		return nil, nil
+	}
+}
+
+// DependencyCycleError is returned for a transformation that's upstream of
+// itself. Its sources could never become READY, so rather than wait on them
+// forever its job fails.
+type DependencyCycleError struct {
+	// Cycle is the path from a source through the ones it's transformed
+	// from back to itself.
+	Cycle []metadata.NameVariant
+}
+
+func (err *DependencyCycleError) Error() string {
+	path := make([]string, len(err.Cycle))
+	for i, nv := range err.Cycle {
+		path[i] = fmt.Sprintf("%s (%s)", nv.Name, nv.Variant)
+	}
+	return fmt.Sprintf("circular source dependency: %s", strings.Join(path, " -> "))
+}
+
+func (err *DependencyCycleError) ErrorCode() metadata.ErrorCode {
+	return metadata.ErrorDependencyCycle
+}
+
+// checkSourceCycle fails a source's job permanently if the sources upstream
+// of it depend on each other in a cycle. Jobs of other resources wait on
+// sources whose own jobs check their cycles, so those aren't walked again.
+func (c *Coordinator) checkSourceCycle(ctx context.Context, id metadata.ResourceID) error {
+	if id.Type != metadata.SOURCE_VARIANT {
+		return nil
+	}
+	cycle, err := c.sourceCycle(ctx, metadata.NameVariant{Name: id.Name, Variant: id.Variant})
+	if err != nil {
+		return err
+	}
+	if cycle != nil {
+		return permanent(&DependencyCycleError{Cycle: cycle})
+	}
+	return nil
+}
+
+// sourceCycle walks the graph of transformations upstream of a source, depth
+// first, and returns the first cycle it finds, or nil if there's none.
+func (c *Coordinator) sourceCycle(ctx context.Context, start metadata.NameVariant) ([]metadata.NameVariant, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[metadata.NameVariant]int)
+	var path []metadata.NameVariant
+	var visit func(nv metadata.NameVariant) ([]metadata.NameVariant, error)
+	visit = func(nv metadata.NameVariant) ([]metadata.NameVariant, error) {
+		switch state[nv] {
+		case visiting:
+			for i := range path {
+				if path[i] == nv {
+					cycle := append([]metadata.NameVariant{}, path[i:]...)
+					return append(cycle, nv), nil
+				}
+			}
+		case visited:
+			return nil, nil
+		}
+		source, err := c.store().GetSourceVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get source variant %s (%s): %w", nv.Name, nv.Variant, err)
+		}
+		state[nv] = visiting
+		path = append(path, nv)
+		if source.IsSQLTransformation() {
+			for _, input := range source.SQLTransformationSources() {
+				if cycle, err := visit(input); cycle != nil || err != nil {
+					return cycle, err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[nv] = visited
+		return nil, nil
+	}
+	return visit(start)
+}
+
+// jobDependents returns the resources whose data is built from id's, which
+// are its direct children in the resource graph.
+func (c *Coordinator) jobDependents(ctx context.Context, id metadata.ResourceID) ([]metadata.ResourceID, error) {
+	nv := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	switch id.Type {
+	case metadata.SOURCE_VARIANT:
+		source, err := c.store().GetSourceVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get source variant: %w", err)
+		}
+		deps := resourceIDs(source.Features(), metadata.FEATURE_VARIANT)
+		deps = append(deps, resourceIDs(source.Labels(), metadata.LABEL_VARIANT)...)
+		return append(deps, resourceIDs(source.TrainingSets(), metadata.TRAINING_SET_VARIANT)...), nil
+	case metadata.FEATURE_VARIANT:
+		feature, err := c.store().GetFeatureVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get feature variant: %w", err)
+		}
+		return resourceIDs(feature.TrainingSets(), metadata.TRAINING_SET_VARIANT), nil
+	case metadata.LABEL_VARIANT:
+		label, err := c.store().GetLabelVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get label variant: %w", err)
+		}
+		return resourceIDs(label.TrainingSets(), metadata.TRAINING_SET_VARIANT), nil
+	default:
+		return nil, nil
+	}
+}
+
+func resourceIDs(nvs []metadata.NameVariant, t metadata.ResourceType) []metadata.ResourceID {
+	ids := make([]metadata.ResourceID, len(nvs))
+	for i, nv := range nvs {
+		ids[i] = metadata.ResourceID{Name: nv.Name, Variant: nv.Variant, Type: t}
+	}
+	return ids
+}
+
+func (c *Coordinator) resourceStatus(ctx context.Context, id metadata.ResourceID) (metadata.ResourceStatus, error) {
+	nv := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	switch id.Type {
+	case metadata.SOURCE_VARIANT:
+		source, err := c.store().GetSourceVariant(ctx, nv)
+		if err != nil {
+			return metadata.NO_STATUS, err
+		}
+		return source.Status(), nil
+	case metadata.FEATURE_VARIANT:
+		feature, err := c.store().GetFeatureVariant(ctx, nv)
+		if err != nil {
+			return metadata.NO_STATUS, err
+		}
+		return feature.Status(), nil
+	case metadata.LABEL_VARIANT:
+		label, err := c.store().GetLabelVariant(ctx, nv)
+		if err != nil {
+			return metadata.NO_STATUS, err
+		}
+		return label.Status(), nil
+	case metadata.TRAINING_SET_VARIANT:
+		ts, err := c.store().GetTrainingSetVariant(ctx, nv)
+		if err != nil {
+			return metadata.NO_STATUS, err
+		}
+		return ts.Status(), nil
+	default:
+		return metadata.NO_STATUS, fmt.Errorf("%s resources don't have jobs", id.Type)
+	}
+}
+
+// awaitDependencies blocks until every dependency of id is READY, so that a
+// job queues behind its parents instead of failing because they haven't run
+// yet. Jobs on independent branches of the graph don't wait on each other.
+// A failed dependency fails the job permanently, since retrying can't help
+// until the dependency is fixed and rerun.
+func (c *Coordinator) awaitDependencies(ctx context.Context, id metadata.ResourceID) error {
+	if err := c.checkSourceCycle(ctx, id); err != nil {
+		return err
+	}
+	deps, err := c.jobDependencies(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, dep := range deps {
+		if err := c.awaitDependency(ctx, id, dep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Coordinator) awaitDependency(ctx context.Context, id, dep metadata.ResourceID) error {
+	logged := false
+	for {
+		status, err := c.resourceStatus(ctx, dep)
+		if err != nil {
+			return fmt.Errorf("get status of dependency %s %s (%s): %w", dep.Type, dep.Name, dep.Variant, err)
+		}
+		switch status {
+		case metadata.READY:
+			return nil
+		case metadata.FAILED:
+			return permanent(fmt.Errorf("dependency %s %s (%s) failed", dep.Type, dep.Name, dep.Variant))
+		}
+		hasJob, err := c.hasJob(dep)
+		if err != nil {
+			return err
+		}
+		if !hasJob {
+			// The dependency's job may have finished since its status was read.
+			if status, err := c.resourceStatus(ctx, dep); err == nil && status == metadata.READY {
+				return nil
+			}
+			return fmt.Errorf("dependency %s %s (%s) is %s and has no job to run", dep.Type, dep.Name, dep.Variant, status)
+		}
+		if !logged {
+			c.Logger.Infow("Waiting for dependency", "resource", id, "dependency", dep, "status", status)
+			logged = true
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(DependencyPollInterval):
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// deadLetterJob moves a job that has failed for good from its job key to its
+// dead-letter key, with the error and attempts that led there, so that it
+// isn't picked up again until it's replayed.
+func (c *Coordinator) deadLetterJob(mtx *concurrency.Mutex, jobKey string, job *metadata.CoordinatorJob, attempts []metadata.JobAttempt, jobErr error) error {
+	serializedJob, err := job.Serialize()
+	if err != nil {
+		return fmt.Errorf("serialize job: %w", err)
+	}
+	deadLetter := &metadata.DeadLetter{
+		Job:          serializedJob,
+		Resource:     job.Resource,
+		Error:        jobErr.Error(),
+		Attempts:     attempts,
+		DeadLettered: time.Now().UTC(),
+	}
+	serialized, err := deadLetter.Serialize()
+	if err != nil {
+		return fmt.Errorf("serialize dead letter: %w", err)
+	}
+	txn := (*c.KVClient).Txn(context.Background())
+	response, err := txn.If(mtx.IsOwner()).Then(
+		clientv3.OpPut(metadata.GetDeadLetterKey(job.Resource), string(serialized)),
+		clientv3.OpDelete(jobKey),
+	).Commit()
+	if err != nil {
+		return fmt.Errorf("dead letter transaction failed: %w", err)
+	}
+	if !response.Succeeded {
+		return fmt.Errorf("was not owner of lock")
+	}
+	c.Logger.Warnw("Moved failed job to dead letters", "job", jobKey, "attempts", len(attempts), "error", jobErr)
+	return nil
+}
@@ -0,0 +1,70 @@
+package coordinator
+
+import (
+	"context"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// MetadataStore is the part of the metadata client that jobs read resources
+// from and report their status to. It's implemented by *metadata.Client, and
+// by the in-memory store in the mocks package for tests.
+type MetadataStore interface {
+	metadata.ResourceReader
+	SetStatus(ctx context.Context, id metadata.ResourceID, status metadata.ResourceStatus, errorMessage string) error
+	SetStatusWithCode(ctx context.Context, id metadata.ResourceID, status metadata.ResourceStatus, code metadata.ErrorCode, errorMessage string) error
+	SetProgress(ctx context.Context, id metadata.ResourceID, progress metadata.JobProgress) error
+	SetStats(ctx context.Context, id metadata.ResourceID, stats metadata.TableStats) error
+	SetTrainingSetFreshness(ctx context.Context, id metadata.NameVariant, freshness metadata.TrainingSetFreshness) error
+	UpdateFeatureVariantProvider(ctx context.Context, id metadata.NameVariant, provider, requester string) error
+	ListProviders(ctx context.Context) ([]*metadata.Provider, error)
+}
+
+// ProviderFactory opens the providers that jobs read from and write to.
+type ProviderFactory interface {
+	Get(t provider.Type, config provider.SerializedConfig) (provider.Provider, error)
+}
+
+// ProviderFactoryFunc is a ProviderFactory that calls the function.
+type ProviderFactoryFunc func(t provider.Type, config provider.SerializedConfig) (provider.Provider, error)
+
+func (fn ProviderFactoryFunc) Get(t provider.Type, config provider.SerializedConfig) (provider.Provider, error) {
+	return fn(t, config)
+}
+
+// store returns where jobs look up resources, which is the metadata client
+// unless another store was injected.
+func (c *Coordinator) store() MetadataStore {
+	if c.Resources != nil {
+		return c.Resources
+	}
+	return c.Metadata
+}
+
+// providers returns how jobs open providers, which is by their registered
+// factories unless another ProviderFactory was injected. Providers that can't
+// be opened fail with ErrorProviderConfigInvalid, unless their error says
+// why, like credentials that were rejected.
+func (c *Coordinator) providers() ProviderFactory {
+	factory := c.Providers
+	if factory == nil {
+		factory = ProviderFactoryFunc(provider.Get)
+	}
+	return ProviderFactoryFunc(func(t provider.Type, config provider.SerializedConfig) (provider.Provider, error) {
+		p, err := factory.Get(t, config)
+		if err != nil && metadata.ErrorCodeOf(err) == metadata.ErrorUnknown {
+			return nil, metadata.WithErrorCode(metadata.ErrorProviderConfigInvalid, err)
+		}
+		return p, err
+	})
+}
+
+// etcdEndpoints returns the endpoints spawned jobs reach etcd at, or none if
+// the coordinator isn't connected to etcd, as in tests.
+func (c *Coordinator) etcdEndpoints() []string {
+	if c.EtcdClient == nil {
+		return nil
+	}
+	return c.EtcdClient.Endpoints()
+}
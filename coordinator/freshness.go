@@ -0,0 +1,72 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// recordTrainingSetFreshness stores how recent the feature and label values
+// a training set was just built from are, so the training set can be
+// verified against a cutoff later. Like stats, a failure is logged instead of
+// failing the build, and stores that can't tell are skipped.
+func (c *Coordinator) recordTrainingSetFreshness(resID metadata.ResourceID) {
+	if err := c.refreshTrainingSetFreshness(resID); err != nil {
+		c.Logger.Warnw("Could not record training set freshness", "resource", resID, "error", err)
+	}
+}
+
+func (c *Coordinator) refreshTrainingSetFreshness(resID metadata.ResourceID) error {
+	ctx := context.Background()
+	nv := metadata.NameVariant{Name: resID.Name, Variant: resID.Variant}
+	ts, err := c.store().GetTrainingSetVariant(ctx, nv)
+	if err != nil {
+		return fmt.Errorf("get training set variant from metadata: %w", err)
+	}
+	providerEntry, err := c.store().GetProvider(ctx, ts.Provider())
+	if err != nil {
+		return fmt.Errorf("get training set provider from metadata: %w", err)
+	}
+	p, err := c.providers().Get(provider.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	if err != nil {
+		return fmt.Errorf("get training set provider: %w", err)
+	}
+	store, err := p.AsOfflineStore()
+	if err != nil {
+		return fmt.Errorf("convert training set provider to offline store: %w", err)
+	}
+	freshnessStore, ok := store.(provider.TrainingSetFreshnessStore)
+	if !ok {
+		return nil
+	}
+	label := ts.Label()
+	def := provider.TrainingSetDef{
+		ID:       provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.TrainingSet},
+		Label:    provider.ResourceID{Name: label.Name, Variant: label.Variant, Type: provider.Label},
+		Features: make([]provider.ResourceID, len(ts.Features())),
+	}
+	for i, feature := range ts.Features() {
+		def.Features[i] = provider.ResourceID{Name: feature.Name, Variant: feature.Variant, Type: provider.Feature}
+	}
+	latest, err := freshnessStore.TrainingSetFreshness(def)
+	if err != nil {
+		return fmt.Errorf("compute training set freshness: %w", err)
+	}
+	freshness := metadata.TrainingSetFreshness{Built: time.Now().UTC()}
+	inputs := append([]provider.ResourceID{def.Label}, def.Features...)
+	for _, input := range inputs {
+		inputLatest, has := latest[input]
+		if !has {
+			continue
+		}
+		id := metadata.ResourceID{Name: input.Name, Variant: input.Variant, Type: metadata.FEATURE_VARIANT}
+		if input.Type == provider.Label {
+			id.Type = metadata.LABEL_VARIANT
+		}
+		freshness.Inputs = append(freshness.Inputs, metadata.InputFreshness{Resource: id, Latest: inputLatest})
+	}
+	return c.store().SetTrainingSetFreshness(ctx, nv, freshness)
+}
@@ -0,0 +1,219 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"google.golang.org/grpc/codes"
+	grpcstatus "google.golang.org/grpc/status"
+)
+
+// DeleteResourceData removes the data a resource variant left behind in its
+// providers: tables, materializations and the online tables of features and
+// labels, as well as the jobs spawned for it. It should be called before the
+// resource is removed from metadata, since the providers are found through
+// it. Data that's already gone is skipped. Data that READY resources are still built from isn't
+// deleted, and ResourceDataInUse is returned instead.
+func (c *Coordinator) DeleteResourceData(id metadata.ResourceID) error {
+	ctx := context.Background()
+	if err := c.checkDataUnused(ctx, id); err != nil {
+		return err
+	}
+	if err := c.removeSchedule(ctx, id); err != nil {
+		return err
+	}
+	nameVariant := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	switch id.Type {
+	case metadata.FEATURE_VARIANT:
+		feature, err := c.store().GetFeatureVariant(ctx, nameVariant)
+		if err != nil {
+			return fmt.Errorf("get feature variant from metadata: %w", err)
+		}
+		resID := provider.ResourceID{Name: id.Name, Variant: id.Variant, Type: provider.Feature}
+		if err := c.deleteOnlineTable(feature.Provider(), resID); err != nil {
+			return err
+		}
+		source, err := c.store().GetSourceVariant(ctx, feature.Source())
+		if err != nil {
+			return fmt.Errorf("get feature source from metadata: %w", err)
+		}
+		store, err := c.fetchOfflineStore(source)
+		if err != nil {
+			return err
+		}
+		if err := deleteMaterializations(store, resID); err != nil {
+			return err
+		}
+		return deleteOfflineTable(store, resID)
+	case metadata.LABEL_VARIANT:
+		label, err := c.store().GetLabelVariant(ctx, nameVariant)
+		if err != nil {
+			return fmt.Errorf("get label variant from metadata: %w", err)
+		}
+		source, err := c.store().GetSourceVariant(ctx, label.Source())
+		if err != nil {
+			return fmt.Errorf("get label source from metadata: %w", err)
+		}
+		store, err := c.fetchOfflineStore(source)
+		if err != nil {
+			return err
+		}
+		resID := provider.ResourceID{Name: id.Name, Variant: id.Variant, Type: provider.Label}
+		if label.ServedOnline() {
+			if err := c.deleteOnlineTable(label.OnlineProvider(), resID); err != nil {
+				return err
+			}
+			if err := deleteMaterializations(store, resID); err != nil {
+				return err
+			}
+		}
+		return deleteOfflineTable(store, resID)
+	case metadata.SOURCE_VARIANT:
+		source, err := c.store().GetSourceVariant(ctx, nameVariant)
+		if err != nil {
+			return fmt.Errorf("get source variant from metadata: %w", err)
+		}
+		store, err := c.fetchOfflineStore(source)
+		if err != nil {
+			return err
+		}
+		resType := provider.Primary
+		if source.IsTransformation() {
+			resType = provider.Transformation
+		}
+		return deleteOfflineTable(store, provider.ResourceID{Name: id.Name, Variant: id.Variant, Type: resType})
+	case metadata.TRAINING_SET_VARIANT:
+		ts, err := c.store().GetTrainingSetVariant(ctx, nameVariant)
+		if err != nil {
+			return fmt.Errorf("get training set variant from metadata: %w", err)
+		}
+		store, err := c.fetchOfflineStore(ts)
+		if err != nil {
+			return err
+		}
+		return deleteOfflineTable(store, provider.ResourceID{Name: id.Name, Variant: id.Variant, Type: provider.TrainingSet})
+	default:
+		return fmt.Errorf("%v has no provider data to delete", id.Type)
+	}
+}
+
+// ResourceDataInUse is returned when deleting a resource's data would break
+// resources that are built from it.
+type ResourceDataInUse struct {
+	ID         metadata.ResourceID
+	Dependents []metadata.ResourceID
+}
+
+func (err *ResourceDataInUse) Error() string {
+	names := make([]string, len(err.Dependents))
+	for i, dep := range err.Dependents {
+		names[i] = fmt.Sprintf("%s %s (%s)", dep.Type, dep.Name, dep.Variant)
+	}
+	return fmt.Sprintf("%s %s (%s) is still used by %s", err.ID.Type, err.ID.Name, err.ID.Variant, strings.Join(names, ", "))
+}
+
+// checkDataUnused fails if any READY resource is built from id's data.
+// Dependents that haven't been built yet are rebuilt along with id, so they
+// don't keep it from being deleted.
+func (c *Coordinator) checkDataUnused(ctx context.Context, id metadata.ResourceID) error {
+	deps, err := c.jobDependents(ctx, id)
+	if err != nil {
+		return fmt.Errorf("find dependents: %w", err)
+	}
+	var live []metadata.ResourceID
+	for _, dep := range deps {
+		status, err := c.resourceStatus(ctx, dep)
+		var notFound *metadata.ResourceNotFound
+		if errors.As(err, &notFound) || grpcstatus.Code(err) == codes.NotFound {
+			// Removed from metadata, so there's nothing left that uses id.
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("get status of dependent %s %s (%s): %w", dep.Type, dep.Name, dep.Variant, err)
+		}
+		if status == metadata.READY {
+			live = append(live, dep)
+		}
+	}
+	if len(live) > 0 {
+		return &ResourceDataInUse{ID: id, Dependents: live}
+	}
+	return nil
+}
+
+type providerFetcher interface {
+	Provider() string
+}
+
+func (c *Coordinator) fetchOfflineStore(resource providerFetcher) (provider.OfflineStore, error) {
+	entry, err := c.store().GetProvider(context.Background(), resource.Provider())
+	if err != nil {
+		return nil, fmt.Errorf("fetch offline provider: %w", err)
+	}
+	p, err := c.providers().Get(provider.Type(entry.Type()), entry.SerializedConfig())
+	if err != nil {
+		return nil, fmt.Errorf("configure offline provider: %w", err)
+	}
+	return p.AsOfflineStore()
+}
+
+// deleteOnlineTable drops the online table a feature or label variant was
+// materialized to in the named provider.
+func (c *Coordinator) deleteOnlineTable(providerName string, id provider.ResourceID) error {
+	entry, err := c.store().GetProvider(context.Background(), providerName)
+	if err != nil {
+		return fmt.Errorf("fetch online provider: %w", err)
+	}
+	p, err := c.providers().Get(provider.Type(entry.Type()), entry.SerializedConfig())
+	if err != nil {
+		return fmt.Errorf("configure online provider: %w", err)
+	}
+	store, err := p.AsOnlineStore()
+	if err != nil {
+		return err
+	}
+	err = store.DeleteTable(provider.MaterializedName(id), id.Variant)
+	var notFound *provider.TableNotFound
+	if err != nil && !errors.As(err, &notFound) {
+		return fmt.Errorf("delete online table: %w", err)
+	}
+	return nil
+}
+
+// deleteMaterializations drops a feature or label variant's materialization
+// along with any generations that updates have replaced. The materialization
+// ID follows the convention the SQL stores use.
+func deleteMaterializations(store provider.OfflineStore, id provider.ResourceID) error {
+	matIDs := []provider.MaterializationID{provider.ResourceMaterializationID(id)}
+	if genStore, ok := store.(provider.GenerationStore); ok {
+		gens, err := genStore.MaterializationGenerations(id)
+		if err != nil {
+			return fmt.Errorf("list materialization generations: %w", err)
+		}
+		for _, gen := range gens {
+			matIDs = append(matIDs, gen.ID)
+		}
+	}
+	for _, matID := range matIDs {
+		err := store.DeleteMaterialization(matID)
+		var notFound *provider.MaterializationNotFound
+		if err != nil && !errors.As(err, &notFound) {
+			return fmt.Errorf("delete materialization %s: %w", matID, err)
+		}
+	}
+	return nil
+}
+
+func deleteOfflineTable(store provider.OfflineStore, id provider.ResourceID) error {
+	err := store.DeleteTable(id)
+	var tableNotFound *provider.TableNotFound
+	var trainingSetNotFound *provider.TrainingSetNotFound
+	if err != nil && !errors.As(err, &tableNotFound) && !errors.As(err, &trainingSetNotFound) {
+		return fmt.Errorf("delete %v table: %w", id.Type, err)
+	}
+	return nil
+}
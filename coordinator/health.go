@@ -0,0 +1,69 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// HealthCheckTimeout bounds how long a single provider health check can take
+// so that one unreachable provider doesn't hold up the others.
+var HealthCheckTimeout = 30 * time.Second
+
+// WatchProviderHealth checks the health of every provider each interval
+// until ctx is done.
+func (c *Coordinator) WatchProviderHealth(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.CheckProviderHealth(ctx); err != nil {
+			c.Logger.Errorw("Could not check provider health", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckProviderHealth runs the health check of each registered provider and
+// stores the result as the provider's status, so that broken credentials show
+// up in metadata before the jobs that use them fail.
+func (c *Coordinator) CheckProviderHealth(ctx context.Context) error {
+	providers, err := c.Metadata.ListProviders(ctx)
+	if err != nil {
+		return fmt.Errorf("list providers: %w", err)
+	}
+	for _, p := range providers {
+		status, code, errorMessage := metadata.READY, metadata.ErrorCode(""), ""
+		if err := c.checkProviderHealth(ctx, p); err != nil {
+			c.Logger.Warnw("Provider is unhealthy", "provider", p.Name(), "error", err)
+			status, code, errorMessage = metadata.FAILED, metadata.ErrorCodeOf(err), err.Error()
+		}
+		if p.Status() == status && p.Error() == errorMessage && p.ErrorCode() == code {
+			continue
+		}
+		id := metadata.ResourceID{Name: p.Name(), Type: metadata.PROVIDER}
+		if err := c.store().SetStatusWithCode(ctx, id, status, code, errorMessage); err != nil {
+			return fmt.Errorf("set %s provider status: %w", p.Name(), err)
+		}
+	}
+	return nil
+}
+
+func (c *Coordinator) checkProviderHealth(ctx context.Context, p *metadata.Provider) error {
+	store, err := c.providers().Get(provider.Type(p.Type()), provider.SerializedConfig(p.SerializedConfig()))
+	if err != nil {
+		return fmt.Errorf("get provider: %w", err)
+	}
+	if c, ok := store.(interface{ Close() error }); ok {
+		defer c.Close()
+	}
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+	return store.CheckHealth(ctx)
+}
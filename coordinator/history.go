@@ -0,0 +1,256 @@
+package coordinator
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	mvccpb "go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// HistoryArchiveLock is held by the coordinator that's archiving history, so
+// that replicas don't archive the same records twice.
+const HistoryArchiveLock = "HISTORY_ARCHIVE_LOCK"
+
+// historyBatchSize is how many keys are read from etcd at a time while
+// looking for expired history.
+var historyBatchSize int64 = 1000
+
+// HistoryRetention is how long each kind of history is kept in etcd before
+// it's archived and pruned. History with a zero retention is kept in etcd.
+type HistoryRetention struct {
+	AuditEvents time.Duration
+	DeadLetters time.Duration
+	// JobRuns is also how long what workers logged is kept.
+	JobRuns time.Duration
+	// ArchiveURI is where history is written before it's pruned, such as
+	// s3://bucket/history. Nothing is pruned without one.
+	ArchiveURI string
+}
+
+// historyKind is a kind of record kept under an etcd prefix.
+type historyKind struct {
+	name   string
+	prefix string
+	// recorded returns when a record happened.
+	recorded func(value []byte) (time.Time, error)
+}
+
+var auditHistory = historyKind{
+	name:   "audit",
+	prefix: metadata.AuditPrefix,
+	recorded: func(value []byte) (time.Time, error) {
+		event := &metadata.AuditEvent{}
+		err := event.Deserialize(value)
+		return event.Time, err
+	},
+}
+
+var deadLetterHistory = historyKind{
+	name:   "deadletters",
+	prefix: metadata.DeadLetterPrefix,
+	recorded: func(value []byte) (time.Time, error) {
+		deadLetter := &metadata.DeadLetter{}
+		err := deadLetter.Deserialize(value)
+		return deadLetter.DeadLettered, err
+	},
+}
+
+var jobRunHistory = historyKind{
+	name:   "jobruns",
+	prefix: metadata.JobRunPrefix,
+	recorded: func(value []byte) (time.Time, error) {
+		run := &metadata.JobRun{}
+		err := run.Deserialize(value)
+		return run.Ended, err
+	},
+}
+
+var workerLogHistory = historyKind{
+	name:   "workerlogs",
+	prefix: metadata.WorkerLogPrefix,
+	recorded: func(value []byte) (time.Time, error) {
+		workerLog := &metadata.WorkerLog{}
+		err := workerLog.Deserialize(value)
+		return workerLog.Written, err
+	},
+}
+
+type historyRecord struct {
+	key         string
+	modRevision int64
+	recorded    time.Time
+	value       []byte
+}
+
+// ArchiveHistory writes the history that's older than its retention to the
+// archive and then prunes it from etcd, returning how many records were
+// pruned. Records are archived as gzipped JSON lines, partitioned by the day
+// they were recorded on, so they can be queried where they're stored.
+func (c *Coordinator) ArchiveHistory(ctx context.Context, retention HistoryRetention) (int, error) {
+	if retention.ArchiveURI == "" {
+		return 0, fmt.Errorf("history can't be pruned without an archive uri")
+	}
+	archiver, err := provider.NewArchiver(retention.ArchiveURI)
+	if err != nil {
+		return 0, fmt.Errorf("create history archiver: %w", err)
+	}
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(c.lockTTL()))
+	if err != nil {
+		return 0, fmt.Errorf("new session: %w", err)
+	}
+	defer s.Close()
+	mtx := concurrency.NewMutex(s, HistoryArchiveLock)
+	if err := mtx.TryLock(ctx); errors.Is(err, concurrency.ErrLocked) {
+		c.Logger.Debugw("History is being archived by another coordinator")
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("lock history archive: %w", err)
+	}
+	defer mtx.Unlock(context.Background())
+	now := time.Now().UTC()
+	kinds := []struct {
+		historyKind
+		retention time.Duration
+	}{
+		{auditHistory, retention.AuditEvents},
+		{deadLetterHistory, retention.DeadLetters},
+		{jobRunHistory, retention.JobRuns},
+		{workerLogHistory, retention.JobRuns},
+	}
+	pruned := 0
+	for _, kind := range kinds {
+		if kind.retention <= 0 {
+			continue
+		}
+		n, err := c.archiveHistoryKind(ctx, archiver, kind.historyKind, now.Add(-kind.retention), now)
+		pruned += n
+		if err != nil {
+			return pruned, fmt.Errorf("archive %s history: %w", kind.name, err)
+		}
+	}
+	return pruned, nil
+}
+
+// archiveHistoryKind archives and prunes the records of kind from before
+// cutoff, a batch at a time. A batch is only pruned once it's archived.
+func (c *Coordinator) archiveHistoryKind(ctx context.Context, archiver provider.Archiver, kind historyKind, cutoff, now time.Time) (int, error) {
+	pruned := 0
+	start, end := kind.prefix, clientv3.GetPrefixRangeEnd(kind.prefix)
+	for batch := 0; ; batch++ {
+		resp, err := (*c.KVClient).Get(ctx, start, clientv3.WithRange(end), clientv3.WithLimit(historyBatchSize))
+		if err != nil {
+			return pruned, fmt.Errorf("get history: %w", err)
+		}
+		records := c.expiredHistory(kind, resp.Kvs, cutoff)
+		if len(records) > 0 {
+			if err := archiveHistory(ctx, archiver, kind.name, records, fmt.Sprintf("%d-%d", now.UnixNano(), batch)); err != nil {
+				return pruned, err
+			}
+			n, err := c.pruneHistory(ctx, records)
+			pruned += n
+			if err != nil {
+				return pruned, err
+			}
+			c.Logger.Infow("Archived history", "kind", kind.name, "records", len(records), "pruned", n)
+		}
+		if !resp.More || len(resp.Kvs) == 0 {
+			return pruned, nil
+		}
+		start = string(resp.Kvs[len(resp.Kvs)-1].Key) + "\x00"
+	}
+}
+
+// expiredHistory returns the records in kvs from before cutoff. Records that
+// can't be read are logged and kept.
+func (c *Coordinator) expiredHistory(kind historyKind, kvs []*mvccpb.KeyValue, cutoff time.Time) []historyRecord {
+	records := make([]historyRecord, 0)
+	for _, kv := range kvs {
+		recorded, err := kind.recorded(kv.Value)
+		if err != nil {
+			c.Logger.Errorw("Could not read history record", "key", string(kv.Key), "error", err)
+			continue
+		}
+		if recorded.Before(cutoff) {
+			records = append(records, historyRecord{key: string(kv.Key), modRevision: kv.ModRevision, recorded: recorded, value: kv.Value})
+		}
+	}
+	return records
+}
+
+// archiveHistory writes records to one object per day they were recorded
+// on, under <kind>/date=<day>/<name>.jsonl.gz.
+func archiveHistory(ctx context.Context, archiver provider.Archiver, kind string, records []historyRecord, name string) error {
+	days := make(map[string][]historyRecord)
+	for _, record := range records {
+		day := record.recorded.UTC().Format("2006-01-02")
+		days[day] = append(days[day], record)
+	}
+	for day, dayRecords := range days {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		for _, record := range dayRecords {
+			var line bytes.Buffer
+			if err := json.Compact(&line, record.value); err != nil {
+				return fmt.Errorf("compact %s: %w", record.key, err)
+			}
+			line.WriteByte('\n')
+			if _, err := zw.Write(line.Bytes()); err != nil {
+				return err
+			}
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		key := fmt.Sprintf("%s/date=%s/%s.jsonl.gz", kind, day, name)
+		if err := archiver.Archive(ctx, key, buf.Bytes()); err != nil {
+			return fmt.Errorf("archive %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// pruneHistory deletes the archived records from etcd, unless they've
+// changed since they were read, like a dead letter that was replayed and
+// failed again. Those are left to be archived again once they expire.
+func (c *Coordinator) pruneHistory(ctx context.Context, records []historyRecord) (int, error) {
+	pruned := 0
+	for _, record := range records {
+		resp, err := (*c.KVClient).Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(record.key), "=", record.modRevision)).
+			Then(clientv3.OpDelete(record.key)).
+			Commit()
+		if err != nil {
+			return pruned, fmt.Errorf("prune %s: %w", record.key, err)
+		}
+		if resp.Succeeded {
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// ArchiveHistoryEvery archives and prunes expired history each interval
+// until ctx is done.
+func (c *Coordinator) ArchiveHistoryEvery(ctx context.Context, interval time.Duration, retention HistoryRetention) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.ArchiveHistory(ctx, retention); err != nil {
+			c.Logger.Errorw("Could not archive history", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
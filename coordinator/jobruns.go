@@ -0,0 +1,114 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+)
+
+// recordJobRun keeps a run of a resource's job, so that it can be read with
+// GetJobRuns. A run that can't be recorded is logged, rather than failing
+// the job it's a run of.
+func (c *Coordinator) recordJobRun(run metadata.JobRun) {
+	serialized, err := run.Serialize()
+	if err != nil {
+		c.Logger.Errorw("Could not serialize job run", "resource", run.Resource, "error", err)
+		return
+	}
+	if _, err := (*c.KVClient).Put(context.Background(), metadata.GetJobRunKey(run), string(serialized)); err != nil {
+		c.Logger.Errorw("Could not record job run", "resource", run.Resource, "attempt", run.Attempt, "error", err)
+	}
+}
+
+// jobRunLogBytes is how much of what a job run logs is recorded with it.
+const jobRunLogBytes = 16 << 10
+
+// recordedRun makes one attempt at a resource's job and records how it went,
+// along with what its runners logged. Runners only log to the run if they're
+// started with the job's context. Those that run in workers record what they
+// logged themselves, and the run points to what the workers it started
+// recorded.
+func (c *Coordinator) recordedRun(ctx context.Context, id metadata.ResourceID, trigger metadata.JobTrigger, attempt uint, job func() error) error {
+	finished := c.observeJobRun(ctx, id)
+	logger, logs := runner.CaptureLogs(c.Logger, jobRunLogBytes)
+	c.runLoggers.Store(id, logger.With("resource", id, "attempt", attempt))
+	defer c.runLoggers.Delete(id)
+	revision := c.etcdRevision(ctx)
+	started := time.Now().UTC()
+	err := job()
+	ended := time.Now().UTC()
+	run := metadata.JobRun{
+		Resource:      id,
+		Attempt:       attempt,
+		Trigger:       trigger,
+		Started:       started,
+		Ended:         ended,
+		Duration:      ended.Sub(started),
+		Status:        jobRunStatus(ctx, err),
+		Logs:          logs.String(),
+		WorkerLogKeys: c.workerLogKeys(id, revision),
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	finished(run.Status, run.Duration)
+	c.recordJobRun(run)
+	return err
+}
+
+// etcdRevision returns etcd's current revision, or 0 if it can't be read.
+func (c *Coordinator) etcdRevision(ctx context.Context) int64 {
+	resp, err := (*c.KVClient).Get(ctx, metadata.WorkerLogPrefix, clientv3.WithCountOnly())
+	if err != nil {
+		c.Logger.Errorw("Could not get etcd revision", "error", err)
+		return 0
+	}
+	return resp.Header.Revision
+}
+
+// workerLogKeys returns the keys of what the workers of id's jobs recorded
+// after revision, which are the workers that a run started at revision
+// started. They're left out if the revision couldn't be read.
+func (c *Coordinator) workerLogKeys(id metadata.ResourceID, revision int64) []string {
+	if revision == 0 {
+		return nil
+	}
+	resp, err := (*c.KVClient).Get(context.Background(), metadata.GetWorkerLogPrefix(id), clientv3.WithPrefix(), clientv3.WithKeysOnly(), clientv3.WithMinModRev(revision+1))
+	if err != nil {
+		c.Logger.Errorw("Could not list worker logs", "resource", id, "error", err)
+		return nil
+	}
+	keys := make([]string, len(resp.Kvs))
+	for i, kv := range resp.Kvs {
+		keys[i] = string(kv.Key)
+	}
+	return keys
+}
+
+// runContext returns ctx with the logger of the run of id's job that's in
+// progress, if there is one, so that what its runners log is recorded with
+// the run.
+func (c *Coordinator) runContext(ctx context.Context, id metadata.ResourceID) context.Context {
+	if logger, ok := c.runLoggers.Load(id); ok {
+		return runner.WithLogger(ctx, logger.(*zap.SugaredLogger))
+	}
+	return ctx
+}
+
+func jobRunStatus(ctx context.Context, err error) metadata.JobRunStatus {
+	switch {
+	case err == nil:
+		return metadata.JobRunSucceeded
+	case interrupted(ctx) || errors.Is(err, errJobInterrupted):
+		return metadata.JobRunInterrupted
+	case ctx.Err() != nil || errors.Is(err, errJobCancelled):
+		return metadata.JobRunCancelled
+	default:
+		return metadata.JobRunFailed
+	}
+}
@@ -0,0 +1,265 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/featureform/metadata"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Job is a queued job, as it's passed to the handler of its job type.
+type Job struct {
+	Resource metadata.ResourceID
+	// Schedule is the cron expression the resource is scheduled on, if it
+	// is.
+	Schedule string
+	// Config is the job's config, which has been validated against its
+	// job type's schema.
+	Config []byte
+}
+
+// JobHandler runs a job. Like the jobs the coordinator runs for resources,
+// it's retried if it returns an error, unless the error is permanent.
+type JobHandler func(ctx context.Context, c *Coordinator, job Job) error
+
+// JobType is a kind of job the coordinator can run. Each resource type has a
+// built-in job type, named after the resource type, that creates the
+// resource. Other kinds of job, such as validation or export jobs, are added
+// with RegisterJobType and queued with QueueJob.
+type JobType struct {
+	Name    string
+	Handler JobHandler
+	// Schema describes the config of the job type's jobs. Jobs whose config
+	// doesn't match it aren't queued or run.
+	Schema ConfigSchema
+	// Rollback, if it's set, is run after each failed attempt at a job.
+	Rollback JobRollback
+}
+
+var jobTypesMtx sync.RWMutex
+
+var jobTypes = map[string]JobType{
+	metadata.TRAINING_SET_VARIANT.String(): creationJobType(metadata.TRAINING_SET_VARIANT, (*Coordinator).runTrainingSetJob),
+	metadata.FEATURE_VARIANT.String():      creationJobType(metadata.FEATURE_VARIANT, (*Coordinator).runFeatureMaterializeJob),
+	metadata.LABEL_VARIANT.String():        creationJobType(metadata.LABEL_VARIANT, (*Coordinator).runLabelRegisterJob),
+	metadata.SOURCE_VARIANT.String():       creationJobType(metadata.SOURCE_VARIANT, (*Coordinator).runRegisterSourceJob),
+	metadata.PROVIDER.String():             resourceJobType(metadata.PROVIDER, (*Coordinator).runProviderJob),
+	RefreshJobType:                         {Name: RefreshJobType, Handler: runUpdateJob, Schema: ConfigSchema{}},
+	metadata.ManualRunJobKind:              {Name: metadata.ManualRunJobKind, Handler: runUpdateJob, Schema: ConfigSchema{}},
+	metadata.DeleteOrphansJobKind:          {Name: metadata.DeleteOrphansJobKind, Handler: runOrphanDeletionJob, Schema: orphanDeletionSchema},
+	ScheduledRunJobType:                    {Name: ScheduledRunJobType, Handler: runScheduledJob, Schema: scheduledRunSchema},
+}
+
+// creationJobType is the job type of a resource that's created in its
+// providers, which is rolled back if it fails part way through.
+func creationJobType(t metadata.ResourceType, run func(*Coordinator, metadata.ResourceID, string) error) JobType {
+	jobType := resourceJobType(t, run)
+	jobType.Rollback = rollbackResource
+	return jobType
+}
+
+func resourceJobType(t metadata.ResourceType, run func(*Coordinator, metadata.ResourceID, string) error) JobType {
+	return JobType{
+		Name: t.String(),
+		Handler: func(ctx context.Context, c *Coordinator, job Job) error {
+			return run(c, job.Resource, job.Schedule)
+		},
+	}
+}
+
+func RegisterJobType(jobType JobType) error {
+	if jobType.Name == "" || jobType.Handler == nil {
+		return fmt.Errorf("job type needs a name and a handler")
+	}
+	jobTypesMtx.Lock()
+	defer jobTypesMtx.Unlock()
+	if _, exists := jobTypes[jobType.Name]; exists {
+		return fmt.Errorf("job type already registered: %s", jobType.Name)
+	}
+	jobTypes[jobType.Name] = jobType
+	return nil
+}
+
+func UnregisterJobType(name string) error {
+	jobTypesMtx.Lock()
+	defer jobTypesMtx.Unlock()
+	if _, exists := jobTypes[name]; !exists {
+		return fmt.Errorf("job type %s not registered", name)
+	}
+	delete(jobTypes, name)
+	return nil
+}
+
+// jobTypeOf returns the job type that runs a job.
+func jobTypeOf(job *metadata.CoordinatorJob) (JobType, error) {
+	name := job.Kind
+	if name == "" {
+		name = job.Resource.Type.String()
+	}
+	jobTypesMtx.RLock()
+	defer jobTypesMtx.RUnlock()
+	jobType, exists := jobTypes[name]
+	if !exists {
+		return JobType{}, fmt.Errorf("job type not registered: %s", name)
+	}
+	return jobType, nil
+}
+
+// jobTypeKey is where a job of a registered type is queued. Jobs of different
+// types for the same resource are queued separately.
+func jobTypeKey(kind string, id metadata.ResourceID) string {
+	return fmt.Sprintf("JOB__%s__%s__%s__%s", kind, id.Type, id.Name, id.Variant)
+}
+
+// QueueJob queues a job of a registered type for a resource, once its config
+// has been checked against the type's schema. The job is run by whichever
+// coordinator claims it, like the jobs queued for new resources.
+func (c *Coordinator) QueueJob(ctx context.Context, kind string, id metadata.ResourceID, config []byte) error {
+	return c.queueJob(ctx, kind, id, config, metadata.TriggerQueued)
+}
+
+func (c *Coordinator) queueJob(ctx context.Context, kind string, id metadata.ResourceID, config []byte, trigger metadata.JobTrigger) error {
+	key, serialized, err := c.newQueuedJob(kind, id, config, trigger)
+	if err != nil {
+		return err
+	}
+	if _, err := (*c.KVClient).Put(ctx, key, serialized); err != nil {
+		return fmt.Errorf("queue %s job: %w", kind, err)
+	}
+	if err := c.publishJob(ctx, key); err != nil {
+		return err
+	}
+	c.Logger.Infow("Queued job", "kind", kind, "resource", id)
+	return nil
+}
+
+// queueJobOnce queues a job like queueJob, unless a job of the same kind is
+// already queued or running for the resource. It returns whether the job was
+// queued.
+func (c *Coordinator) queueJobOnce(ctx context.Context, kind string, id metadata.ResourceID, config []byte, trigger metadata.JobTrigger) (bool, error) {
+	key, serialized, err := c.newQueuedJob(kind, id, config, trigger)
+	if err != nil {
+		return false, err
+	}
+	txn, err := (*c.KVClient).Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, serialized)).
+		Commit()
+	if err != nil {
+		return false, fmt.Errorf("queue %s job: %w", kind, err)
+	}
+	if !txn.Succeeded {
+		return false, nil
+	}
+	if err := c.publishJob(ctx, key); err != nil {
+		return true, err
+	}
+	c.Logger.Infow("Queued job", "kind", kind, "resource", id)
+	return true, nil
+}
+
+// newQueuedJob checks a job's config against its type's schema and returns
+// the key it's queued at and its serialized job.
+func (c *Coordinator) newQueuedJob(kind string, id metadata.ResourceID, config []byte, trigger metadata.JobTrigger) (string, string, error) {
+	job := &metadata.CoordinatorJob{Resource: id, Kind: kind, Config: config, Trigger: trigger}
+	jobType, err := jobTypeOf(job)
+	if err != nil {
+		return "", "", err
+	}
+	if err := jobType.Schema.Validate(config); err != nil {
+		return "", "", fmt.Errorf("invalid %s job config: %w", kind, err)
+	}
+	serialized, err := job.Serialize()
+	if err != nil {
+		return "", "", fmt.Errorf("serialize job: %w", err)
+	}
+	return jobTypeKey(kind, id), string(serialized), nil
+}
+
+// ConfigType is the JSON type of a config field.
+type ConfigType string
+
+const (
+	StringConfig ConfigType = "string"
+	NumberConfig ConfigType = "number"
+	BoolConfig   ConfigType = "bool"
+	ListConfig   ConfigType = "list"
+	ObjectConfig ConfigType = "object"
+)
+
+func (t ConfigType) matches(value interface{}) bool {
+	switch value.(type) {
+	case string:
+		return t == StringConfig
+	case float64:
+		return t == NumberConfig
+	case bool:
+		return t == BoolConfig
+	case []interface{}:
+		return t == ListConfig
+	case map[string]interface{}:
+		return t == ObjectConfig
+	}
+	return false
+}
+
+type ConfigField struct {
+	Type     ConfigType
+	Required bool
+}
+
+// ConfigSchema describes a job config, which is a JSON object, by its
+// fields. Fields it doesn't describe aren't allowed. A nil schema accepts
+// any config.
+type ConfigSchema map[string]ConfigField
+
+func (schema ConfigSchema) Validate(config []byte) error {
+	if schema == nil {
+		return nil
+	}
+	fields := make(map[string]interface{})
+	if len(config) > 0 {
+		if err := json.Unmarshal(config, &fields); err != nil {
+			return fmt.Errorf("config is not a JSON object: %w", err)
+		}
+	}
+	// Fields are checked in order so that the same config always fails with
+	// the same error.
+	for _, name := range sortedKeys(fields) {
+		if _, has := schema[name]; !has {
+			return fmt.Errorf("unknown field %q", name)
+		}
+	}
+	names := make([]string, 0, len(schema))
+	for name := range schema {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		field := schema[name]
+		value, has := fields[name]
+		if !has || value == nil {
+			if field.Required {
+				return fmt.Errorf("missing required field %q", name)
+			}
+			continue
+		}
+		if !field.Type.matches(value) {
+			return fmt.Errorf("field %q must be a %s", name, field.Type)
+		}
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,90 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// LeaderElection is the etcd prefix that coordinator replicas with a
+// Leadership campaign under.
+const LeaderElection = "COORDINATOR_LEADER"
+
+// Leadership elects one of a set of coordinator replicas to claim jobs, so
+// that a second replica can be run for availability rather than throughput.
+// The other replicas stand by until the leader's lease expires or it shuts
+// down, and then one of them is elected and claims the jobs that are queued.
+// Jobs the old leader was running are taken over once their locks are
+// released, the same way an orphaned job is.
+type Leadership struct {
+	ID      string
+	mtx     sync.RWMutex
+	leading bool
+}
+
+func NewLeadership(id string) *Leadership {
+	return &Leadership{ID: id}
+}
+
+// IsLeader reports whether this replica is the leader.
+func (l *Leadership) IsLeader() bool {
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	return l.leading
+}
+
+func (l *Leadership) setLeading(leading bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.leading = leading
+}
+
+// RunForLeader campaigns for leadership of the coordinator's replicas and
+// leads them while it's elected, until the coordinator shuts down. A replica
+// that loses its lease stops claiming jobs and campaigns again.
+func (c *Coordinator) RunForLeader() error {
+	claims := c.shutdown.claims
+	for claims.Err() == nil {
+		if err := c.lead(claims); err != nil && claims.Err() == nil {
+			c.Logger.Errorw("Stopped leading coordinators", "error", err)
+			time.Sleep(time.Second)
+		}
+	}
+	return nil
+}
+
+func (c *Coordinator) lead(ctx context.Context) error {
+	// The leader holds the election through its session's lease, like the
+	// scheduler's leader, so it has the same TTL as the job locks.
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(c.lockTTL()))
+	if err != nil {
+		return fmt.Errorf("new session: %w", err)
+	}
+	defer s.Close()
+	election := concurrency.NewElection(s, LeaderElection)
+	if err := election.Campaign(ctx, c.Leadership.ID); err != nil {
+		return fmt.Errorf("campaign for leader: %w", err)
+	}
+	defer func() {
+		if err := election.Resign(context.Background()); err != nil {
+			c.Logger.Debugw("Error resigning leadership", "error", err)
+		}
+	}()
+	// Jobs stop being claimed before leadership is resigned, so that the next
+	// leader doesn't claim them at the same time.
+	c.Leadership.setLeading(true)
+	defer c.Leadership.setLeading(false)
+	c.Logger.Infow("Leading coordinators", "leader", c.Leadership.ID)
+	// Jobs that were queued while another replica led, or while there was no
+	// leader, weren't claimed by this one when they were published.
+	c.claimOwnedJobs(ctx)
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-s.Done():
+		return fmt.Errorf("leader session expired")
+	}
+}
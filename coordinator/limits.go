@@ -0,0 +1,207 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/featureform/metadata"
+)
+
+// JobLimiter caps how many jobs run at once, in total and against each
+// provider, so that registering hundreds of resources at once doesn't start
+// hundreds of transformations against the same warehouse. Jobs over the limit
+// wait for a running job to finish, and waiting jobs are given slots in order
+// of priority, then in the order they asked. A limit of zero means no limit.
+type JobLimiter struct {
+	global int
+	// defaultPerProvider applies to providers that aren't in perProvider.
+	defaultPerProvider int
+	perProvider        map[string]int
+	mtx                sync.Mutex
+	running            map[string]int
+	waiting            []*slotRequest
+	requests           uint64
+}
+
+type slotRequest struct {
+	slots    []string
+	priority int32
+	order    uint64
+	granted  chan struct{}
+}
+
+// globalSlots is the key of the global limit among the provider names.
+const globalSlots = ""
+
+func NewJobLimiter(global, defaultPerProvider int, perProvider map[string]int) *JobLimiter {
+	return &JobLimiter{
+		global:             global,
+		defaultPerProvider: defaultPerProvider,
+		perProvider:        perProvider,
+		running:            make(map[string]int),
+	}
+}
+
+func (l *JobLimiter) limit(slots string) int {
+	if slots == globalSlots {
+		return l.global
+	}
+	if limit, has := l.perProvider[slots]; has {
+		return limit
+	}
+	return l.defaultPerProvider
+}
+
+// Acquire waits for a slot for a job that uses providers and returns a
+// function that frees it.
+func (l *JobLimiter) Acquire(ctx context.Context, providers []string) (func(), error) {
+	return l.AcquireWithPriority(ctx, providers, 0)
+}
+
+// AcquireWithPriority is Acquire for a job with a priority. While jobs are
+// waiting for slots, a job isn't given a slot that a higher priority job is
+// waiting for. All of a job's slots are taken at once, so that two jobs can't
+// each hold a slot the other is waiting on.
+func (l *JobLimiter) AcquireWithPriority(ctx context.Context, providers []string, priority int32) (func(), error) {
+	slots := []string{globalSlots}
+	seen := map[string]bool{globalSlots: true}
+	for _, provider := range providers {
+		if !seen[provider] {
+			seen[provider] = true
+			slots = append(slots, provider)
+		}
+	}
+	l.mtx.Lock()
+	l.requests++
+	req := &slotRequest{slots: slots, priority: priority, order: l.requests, granted: make(chan struct{})}
+	l.waiting = append(l.waiting, req)
+	l.grant()
+	l.mtx.Unlock()
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			l.mtx.Lock()
+			defer l.mtx.Unlock()
+			for _, s := range slots {
+				l.running[s]--
+			}
+			l.grant()
+		})
+	}
+	select {
+	case <-req.granted:
+		return release, nil
+	case <-ctx.Done():
+	}
+	l.mtx.Lock()
+	select {
+	case <-req.granted:
+		// The slots were granted as ctx was done.
+		l.mtx.Unlock()
+		release()
+	default:
+		for i, waiting := range l.waiting {
+			if waiting == req {
+				l.waiting = append(l.waiting[:i], l.waiting[i+1:]...)
+				break
+			}
+		}
+		l.grant()
+		l.mtx.Unlock()
+	}
+	return nil, ctx.Err()
+}
+
+// grant gives slots to the waiting requests that fit, in priority order. The
+// slots of a request that doesn't fit are held back from the requests after
+// it, so that lower priority jobs can't keep taking them.
+func (l *JobLimiter) grant() {
+	sort.SliceStable(l.waiting, func(i, j int) bool {
+		if l.waiting[i].priority != l.waiting[j].priority {
+			return l.waiting[i].priority > l.waiting[j].priority
+		}
+		return l.waiting[i].order < l.waiting[j].order
+	})
+	held := make(map[string]bool)
+	remaining := l.waiting[:0]
+	for _, req := range l.waiting {
+		fits := true
+		for _, s := range req.slots {
+			if limit := l.limit(s); held[s] || (limit > 0 && l.running[s] >= limit) {
+				fits = false
+			}
+		}
+		if !fits {
+			for _, s := range req.slots {
+				if l.limit(s) > 0 {
+					held[s] = true
+				}
+			}
+			remaining = append(remaining, req)
+			continue
+		}
+		for _, s := range req.slots {
+			l.running[s]++
+		}
+		close(req.granted)
+	}
+	l.waiting = remaining
+}
+
+// jobProviders returns the providers a resource's job runs against, and the
+// priority it waits for slots with.
+func (c *Coordinator) jobProviders(ctx context.Context, id metadata.ResourceID) ([]string, metadata.Priority, error) {
+	nv := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	switch id.Type {
+	case metadata.SOURCE_VARIANT:
+		source, err := c.store().GetSourceVariant(ctx, nv)
+		if err != nil {
+			return nil, 0, fmt.Errorf("get source variant: %w", err)
+		}
+		return []string{source.Provider()}, source.Priority(), nil
+	case metadata.FEATURE_VARIANT:
+		feature, err := c.store().GetFeatureVariant(ctx, nv)
+		if err != nil {
+			return nil, 0, fmt.Errorf("get feature variant: %w", err)
+		}
+		// Materializing reads from the source's provider and writes to the
+		// feature's.
+		source, err := c.store().GetSourceVariant(ctx, feature.Source())
+		if err != nil {
+			return nil, 0, fmt.Errorf("get feature source variant: %w", err)
+		}
+		return []string{feature.Provider(), source.Provider()}, feature.Priority(), nil
+	case metadata.LABEL_VARIANT:
+		label, err := c.store().GetLabelVariant(ctx, nv)
+		if err != nil {
+			return nil, 0, fmt.Errorf("get label variant: %w", err)
+		}
+		return []string{label.Provider()}, label.Priority(), nil
+	case metadata.TRAINING_SET_VARIANT:
+		ts, err := c.store().GetTrainingSetVariant(ctx, nv)
+		if err != nil {
+			return nil, 0, fmt.Errorf("get training set variant: %w", err)
+		}
+		return []string{ts.Provider()}, ts.Priority(), nil
+	case metadata.PROVIDER:
+		// Maintenance can wait for the jobs that keep features fresh.
+		return []string{id.Name}, metadata.BackfillPriority, nil
+	default:
+		return nil, metadata.DefaultPriority, nil
+	}
+}
+
+// acquireJobSlot waits until the job for id is within the coordinator's
+// concurrency limits. The returned function must be called once it's done.
+func (c *Coordinator) acquireJobSlot(ctx context.Context, id metadata.ResourceID) (func(), error) {
+	if c.Limiter == nil {
+		return func() {}, nil
+	}
+	providers, priority, err := c.jobProviders(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return c.Limiter.AcquireWithPriority(ctx, providers, int32(priority))
+}
@@ -1,13 +1,25 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"github.com/featureform/coordinator"
+	"github.com/featureform/coordinator/queue"
 	"github.com/featureform/metadata"
+	pb "github.com/featureform/metadata/proto"
+	"github.com/featureform/metrics"
+	"github.com/featureform/provider"
 	"github.com/featureform/runner"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"math"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
 	"time"
 )
 
@@ -38,6 +50,30 @@ func main() {
 	if err := runner.RegisterFactory(string(runner.CREATE_TRAINING_SET), runner.TrainingSetRunnerFactory); err != nil {
 		panic(fmt.Errorf("failed to register training set runner factory: %w", err))
 	}
+	if err := runner.RegisterFactory(string(runner.MIGRATE_ONLINE), runner.OnlineMigrationRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register online migration runner factory: %w", err))
+	}
+	if err := runner.RegisterFactory(string(runner.COMPACT_MATERIALIZATIONS), runner.CompactionRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register compaction runner factory: %w", err))
+	}
+	if err := runner.RegisterFactory(string(runner.MAINTAIN_PROVIDER), runner.MaintenanceRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register maintenance runner factory: %w", err))
+	}
+	if err := runner.RegisterFactory(string(runner.DELETE_RESOURCE), runner.DeleteResourceRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register delete resource runner factory: %w", err))
+	}
+	if err := runner.RegisterFactory(string(runner.COPY_OFFLINE), runner.OfflineCopyRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register offline copy runner factory: %w", err))
+	}
+	if err := runner.RegisterFactory(string(runner.VALIDATE), runner.ValidationRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register validation runner factory: %w", err))
+	}
+	if err := runner.RegisterFactory(string(runner.EXPORT_TRAINING_SET), runner.ExportTrainingSetRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register export training set runner factory: %w", err))
+	}
+	if err := runner.RegisterFactory(string(runner.STREAM_TO_ONLINE), runner.StreamToOnlineRunnerFactory); err != nil {
+		panic(fmt.Errorf("failed to register stream to online runner factory: %w", err))
+	}
 	if err != nil {
 		panic(err)
 	}
@@ -51,15 +87,727 @@ func main() {
 		panic(err)
 	}
 	logger.Debug("Connected to Metadata")
-	coord, err := coordinator.NewCoordinator(client, logger, cli, &coordinator.MemoryJobSpawner{})
+	spawner, err := jobSpawner(os.Getenv("JOB_SPAWNER"))
+	if err != nil {
+		logger.Errorw("Invalid job spawner: %v", err)
+		panic(err)
+	}
+	coord, err := coordinator.NewCoordinator(client, logger, cli, spawner)
 	if err != nil {
 		logger.Errorw("Failed to set up coordinator: %v", err)
 		panic(err)
 	}
+	if attempts := os.Getenv("JOB_MAX_ATTEMPTS"); attempts != "" {
+		maxAttempts, err := strconv.ParseUint(attempts, 10, 32)
+		if err != nil {
+			logger.Errorw("Invalid job max attempts: %v", err)
+			panic(err)
+		}
+		coord.Retry.MaxAttempts = uint(maxAttempts)
+	}
+	if backoff := os.Getenv("JOB_RETRY_BACKOFF"); backoff != "" {
+		initialBackoff, err := time.ParseDuration(backoff)
+		if err != nil {
+			logger.Errorw("Invalid job retry backoff: %v", err)
+			panic(err)
+		}
+		coord.Retry.InitialBackoff = initialBackoff
+	}
+	if backoff := os.Getenv("JOB_RETRY_MAX_BACKOFF"); backoff != "" {
+		maxBackoff, err := time.ParseDuration(backoff)
+		if err != nil {
+			logger.Errorw("Invalid job retry max backoff: %v", err)
+			panic(err)
+		}
+		coord.Retry.MaxBackoff = maxBackoff
+	}
+	globalLimit, err := envInt("MAX_CONCURRENT_JOBS")
+	if err != nil {
+		logger.Errorw("Invalid max concurrent jobs: %v", err)
+		panic(err)
+	}
+	providerLimit, err := envInt("MAX_CONCURRENT_JOBS_PER_PROVIDER")
+	if err != nil {
+		logger.Errorw("Invalid max concurrent jobs per provider: %v", err)
+		panic(err)
+	}
+	providerLimits, err := parseProviderLimits(os.Getenv("PROVIDER_JOB_LIMITS"))
+	if err != nil {
+		logger.Errorw("Invalid provider job limits: %v", err)
+		panic(err)
+	}
+	if globalLimit > 0 || providerLimit > 0 || len(providerLimits) > 0 {
+		coord.Limiter = coordinator.NewJobLimiter(globalLimit, providerLimit, providerLimits)
+	}
+	maxRuntime, err := parseMaxRuntimes(os.Getenv("JOB_MAX_RUNTIME"))
+	if err != nil {
+		logger.Errorw("Invalid job max runtime: %v", err)
+		panic(err)
+	}
+	coord.MaxRuntime = maxRuntime
+	coord.RetryTimeouts = os.Getenv("JOB_RETRY_TIMEOUTS") == "true"
+	if interval := os.Getenv("PROBE_INTERVAL"); interval != "" {
+		probeInterval, err := time.ParseDuration(interval)
+		if err != nil {
+			logger.Errorw("Invalid probe interval: %v", err)
+			panic(err)
+		}
+		probeMetrics := metrics.NewProbeMetrics("coordinator", logger)
+		go probeMetrics.RunEvery(context.Background(), probeInterval, coord.ProviderProbes)
+	}
+	if port := os.Getenv("METRICS_PORT"); port != "" {
+		queueDepthInterval := time.Minute
+		if interval := os.Getenv("QUEUE_DEPTH_INTERVAL"); interval != "" {
+			if queueDepthInterval, err = time.ParseDuration(interval); err != nil {
+				logger.Errorw("Invalid queue depth interval: %v", err)
+				panic(err)
+			}
+		}
+		coord.Metrics = metrics.NewJobMetrics("coordinator", logger)
+		go coord.Metrics.RunEvery(context.Background(), queueDepthInterval, coord.QueueDepth)
+		go coord.Metrics.ExposePort(port)
+	}
+	if interval := os.Getenv("HEALTH_CHECK_INTERVAL"); interval != "" {
+		healthCheckInterval, err := time.ParseDuration(interval)
+		if err != nil {
+			logger.Errorw("Invalid health check interval: %v", err)
+			panic(err)
+		}
+		go coord.WatchProviderHealth(context.Background(), healthCheckInterval)
+	}
+	if interval := os.Getenv("ORPHAN_AUDIT_INTERVAL"); interval != "" {
+		auditInterval, err := time.ParseDuration(interval)
+		if err != nil {
+			logger.Errorw("Invalid orphan audit interval: %v", err)
+			panic(err)
+		}
+		go coord.AuditOrphanedTablesEvery(context.Background(), auditInterval)
+	}
+	if uri := os.Getenv("HISTORY_ARCHIVE_URI"); uri != "" {
+		retention, interval, err := historyRetention(uri)
+		if err != nil {
+			logger.Errorw("Invalid history retention: %v", err)
+			panic(err)
+		}
+		go coord.ArchiveHistoryEvery(context.Background(), interval, retention)
+	}
+	if retention, err := materializationRetention(); err != nil {
+		logger.Errorw("Invalid materialization retention: %v", err)
+		panic(err)
+	} else if retention != nil {
+		coord.MaterializationRetention = retention
+		coord.GenerationArchiveURI = os.Getenv("GENERATION_ARCHIVE_URI")
+	}
+	catchUp, err := catchUpPolicies(os.Getenv("CATCHUP_POLICY"), os.Getenv("CATCHUP_RESOURCE_POLICIES"))
+	if err != nil {
+		logger.Errorw("Invalid catch-up policy: %v", err)
+		panic(err)
+	}
+	coord.CatchUp = catchUp
+	if deadline := os.Getenv("CRON_STARTING_DEADLINE"); deadline != "" {
+		if runner.CronStartingDeadline, err = time.ParseDuration(deadline); err != nil {
+			logger.Errorw("Invalid cron starting deadline: %v", err)
+			panic(err)
+		}
+	}
+	if ttl := os.Getenv("JOB_LOCK_TTL"); ttl != "" {
+		lockTTL, err := time.ParseDuration(ttl)
+		if err != nil {
+			logger.Errorw("Invalid job lock TTL: %v", err)
+			panic(err)
+		}
+		coord.LockTTL = lockTTL
+	}
+	if rate := os.Getenv("JOB_CLAIM_RATE"); rate != "" {
+		claimRate, err := strconv.ParseFloat(rate, 64)
+		if err == nil && claimRate <= 0 {
+			err = fmt.Errorf("claim rate must be positive: %s", rate)
+		}
+		if err != nil {
+			logger.Errorw("Invalid job claim rate: %v", err)
+			panic(err)
+		}
+		claimBurst, err := envInt("JOB_CLAIM_BURST")
+		if err != nil {
+			logger.Errorw("Invalid job claim burst: %v", err)
+			panic(err)
+		}
+		coord.ClaimLimiter = coordinator.NewClaimLimiter(claimRate, claimBurst)
+	}
+	if coord.ClaimBatchSize, err = envInt("JOB_CLAIM_BATCH_SIZE"); err != nil {
+		logger.Errorw("Invalid job claim batch size: %v", err)
+		panic(err)
+	}
+	if interval := os.Getenv("ETCD_COMPACTION_INTERVAL"); interval != "" {
+		compactionInterval, err := time.ParseDuration(interval)
+		if err != nil {
+			logger.Errorw("Invalid etcd compaction interval: %v", err)
+			panic(err)
+		}
+		go coord.CompactEtcdEvery(context.Background(), compactionInterval)
+	}
+	if coord.ChunkSizing, err = chunkSizing(); err != nil {
+		logger.Errorw("Invalid materialization chunk sizing: %v", err)
+		panic(err)
+	}
+	if interval := os.Getenv("PROGRESS_INTERVAL"); interval != "" {
+		if coord.ProgressInterval, err = time.ParseDuration(interval); err != nil {
+			logger.Errorw("Invalid progress interval: %v", err)
+			panic(err)
+		}
+	}
+	if coord.MaterializeParallelism, err = envInt("MATERIALIZE_PARALLELISM"); err != nil {
+		logger.Errorw("Invalid materialization parallelism: %v", err)
+		panic(err)
+	}
+	if uri := os.Getenv("MATERIALIZE_BULK_LOAD_URI"); uri != "" {
+		if _, err := provider.NewArchiver(uri); err != nil {
+			logger.Errorw("Invalid materialization bulk load URI: %v", err)
+			panic(err)
+		}
+		coord.BulkLoadURI = uri
+	}
+	jobQueue, err := queue.New(queue.ConfigFromEnv(), cli)
+	if err != nil {
+		logger.Errorw("Invalid job queue: %v", err)
+		panic(err)
+	}
+	coord.Queue = jobQueue
+	defer jobQueue.Close()
+	if interval := os.Getenv("SCHEDULER_INTERVAL"); interval != "" {
+		schedulerInterval, err := time.ParseDuration(interval)
+		if err != nil {
+			logger.Errorw("Invalid scheduler interval: %v", err)
+			panic(err)
+		}
+		coord.SchedulerInterval = schedulerInterval
+	}
+	if os.Getenv("PARTITION_JOBS") == "true" {
+		id, err := os.Hostname()
+		if err != nil {
+			logger.Errorw("Could not get coordinator ID: %v", err)
+			panic(err)
+		}
+		coord.Partition = coordinator.NewPartitioner(id)
+		if err := coord.JoinPartition(context.Background()); err != nil {
+			logger.Errorw("Could not join coordinator partition: %v", err)
+			panic(err)
+		}
+	}
+	if os.Getenv("LEADER_ELECTION") == "true" {
+		id, err := os.Hostname()
+		if err != nil {
+			logger.Errorw("Could not get coordinator ID: %v", err)
+			panic(err)
+		}
+		coord.Leadership = coordinator.NewLeadership(id)
+		go func() {
+			if err := coord.RunForLeader(); err != nil {
+				logger.Errorw("Stopped running for leader", "error", err)
+			}
+		}()
+	}
+	gracePeriod := 25 * time.Second
+	if period := os.Getenv("SHUTDOWN_GRACE_PERIOD"); period != "" {
+		gracePeriod, err = time.ParseDuration(period)
+		if err != nil {
+			logger.Errorw("Invalid shutdown grace period: %v", err)
+			panic(err)
+		}
+	}
+	shutdownDone := make(chan struct{})
+	go func() {
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGTERM, os.Interrupt)
+		<-sigs
+		ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+		defer cancel()
+		if err := coord.Shutdown(ctx); err != nil {
+			logger.Warnw("Interrupted jobs on shutdown", "error", err)
+		}
+		close(shutdownDone)
+	}()
+	go func() {
+		if err := coord.RunScheduler(); err != nil {
+			logger.Errorw("Stopped running the scheduler", "error", err)
+		}
+	}()
+	go func() {
+		if err := coord.WatchForBackfills(); err != nil {
+			logger.Errorw("Stopped running backfills", "error", err)
+		}
+	}()
 	logger.Debug("Begin Job Watch")
 	if err := coord.WatchForNewJobs(); err != nil {
 		logger.Errorw(err.Error())
 		panic(err)
 		return
 	}
+	<-shutdownDone
+}
+
+// jobSpawner returns the spawner named by JOB_SPAWNER. Jobs run in the
+// coordinator unless another one is set.
+func jobSpawner(name string) (coordinator.JobSpawner, error) {
+	switch name {
+	case "", "memory":
+		return &coordinator.MemoryJobSpawner{}, nil
+	case "subprocess":
+		return &coordinator.SubprocessJobSpawner{Binary: os.Getenv("WORKER_BINARY")}, nil
+	case "kubernetes":
+		return kubernetesJobSpawner()
+	case "argo":
+		retries, err := envInt("ARGO_RETRIES")
+		if err != nil {
+			return nil, fmt.Errorf("argo retries: %w", err)
+		}
+		return &coordinator.ArgoJobSpawner{
+			Retries:        int32(retries),
+			ServiceAccount: os.Getenv("ARGO_SERVICE_ACCOUNT"),
+			ArchiveLogs:    os.Getenv("ARGO_ARCHIVE_LOGS") == "true",
+		}, nil
+	case "batch":
+		retries, err := envInt("BATCH_RETRIES")
+		if err != nil {
+			return nil, fmt.Errorf("batch retries: %w", err)
+		}
+		client := runner.BatchJobClient{
+			Region:        os.Getenv("AWS_REGION"),
+			JobQueue:      os.Getenv("BATCH_JOB_QUEUE"),
+			JobDefinition: os.Getenv("BATCH_JOB_DEFINITION"),
+			Retries:       int32(retries),
+		}
+		if client.Region == "" || client.JobQueue == "" || client.JobDefinition == "" {
+			return nil, fmt.Errorf("batch job spawner needs AWS_REGION, BATCH_JOB_QUEUE and BATCH_JOB_DEFINITION")
+		}
+		return &coordinator.AWSJobSpawner{Client: client}, nil
+	case "ecs":
+		client := runner.ECSJobClient{
+			Region:         os.Getenv("AWS_REGION"),
+			Cluster:        os.Getenv("ECS_CLUSTER"),
+			TaskDefinition: os.Getenv("ECS_TASK_DEFINITION"),
+			Container:      os.Getenv("ECS_CONTAINER"),
+			Subnets:        envList("ECS_SUBNETS"),
+			SecurityGroups: envList("ECS_SECURITY_GROUPS"),
+			AssignPublicIP: os.Getenv("ECS_ASSIGN_PUBLIC_IP") == "true",
+		}
+		if client.Region == "" || client.Cluster == "" || client.TaskDefinition == "" || client.Container == "" || len(client.Subnets) == 0 {
+			return nil, fmt.Errorf("ecs job spawner needs AWS_REGION, ECS_CLUSTER, ECS_TASK_DEFINITION, ECS_CONTAINER and ECS_SUBNETS")
+		}
+		return &coordinator.AWSJobSpawner{Client: client}, nil
+	case "cloudrun":
+		client := runner.CloudRunClient{Project: os.Getenv("GCP_PROJECT"), Region: os.Getenv("CLOUD_RUN_REGION")}
+		if client.Project == "" || client.Region == "" {
+			return nil, fmt.Errorf("cloud run job spawner needs GCP_PROJECT and CLOUD_RUN_REGION")
+		}
+		retries, err := envInt("CLOUD_RUN_RETRIES")
+		if err != nil {
+			return nil, fmt.Errorf("cloud run retries: %w", err)
+		}
+		jobResources, err := parseCloudRunResources(os.Getenv("CLOUD_RUN_JOB_RESOURCES"))
+		if err != nil {
+			return nil, fmt.Errorf("cloud run job resources: %w", err)
+		}
+		spawner := &coordinator.CloudRunJobSpawner{
+			Client:         client,
+			Resources:      runner.CloudRunResources{CPU: os.Getenv("CLOUD_RUN_CPU"), Memory: os.Getenv("CLOUD_RUN_MEMORY")},
+			JobResources:   jobResources,
+			Retries:        int32(retries),
+			ServiceAccount: os.Getenv("CLOUD_RUN_SERVICE_ACCOUNT"),
+		}
+		if timeout := os.Getenv("CLOUD_RUN_TASK_TIMEOUT"); timeout != "" {
+			if spawner.Timeout, err = time.ParseDuration(timeout); err != nil {
+				return nil, fmt.Errorf("cloud run task timeout: %w", err)
+			}
+		}
+		return spawner, nil
+	case "nomad":
+		client := runner.NomadClient{
+			Address:     os.Getenv("NOMAD_ADDR"),
+			Token:       os.Getenv("NOMAD_TOKEN"),
+			Namespace:   os.Getenv("NOMAD_NAMESPACE"),
+			Region:      os.Getenv("NOMAD_REGION"),
+			Datacenters: envList("NOMAD_DATACENTERS"),
+		}
+		if client.Address == "" {
+			client.Address = "http://127.0.0.1:4646"
+		}
+		cpu, err := envInt("NOMAD_CPU")
+		if err != nil {
+			return nil, fmt.Errorf("nomad cpu: %w", err)
+		}
+		memory, err := envInt("NOMAD_MEMORY_MB")
+		if err != nil {
+			return nil, fmt.Errorf("nomad memory: %w", err)
+		}
+		retries, err := envInt("NOMAD_RETRIES")
+		if err != nil {
+			return nil, fmt.Errorf("nomad retries: %w", err)
+		}
+		return &coordinator.NomadJobSpawner{
+			Client:    client,
+			Resources: runner.NomadResources{CPU: cpu, MemoryMB: memory},
+			Retries:   int32(retries),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown job spawner %q", name)
+	}
+}
+
+// historyRetention reads how long audit events and dead letters are kept in
+// etcd, from AUDIT_RETENTION and DEADLETTER_RETENTION, and how often expired
+// history is archived to uri, from HISTORY_ARCHIVE_INTERVAL.
+func historyRetention(uri string) (coordinator.HistoryRetention, time.Duration, error) {
+	retention := coordinator.HistoryRetention{ArchiveURI: uri}
+	durations := []struct {
+		env string
+		dst *time.Duration
+	}{
+		{"AUDIT_RETENTION", &retention.AuditEvents},
+		{"DEADLETTER_RETENTION", &retention.DeadLetters},
+		{"JOBRUN_RETENTION", &retention.JobRuns},
+	}
+	for _, duration := range durations {
+		if value := os.Getenv(duration.env); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil {
+				return retention, 0, fmt.Errorf("%s: %w", duration.env, err)
+			}
+			*duration.dst = parsed
+		}
+	}
+	interval := time.Hour
+	if value := os.Getenv("HISTORY_ARCHIVE_INTERVAL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return retention, 0, fmt.Errorf("HISTORY_ARCHIVE_INTERVAL: %w", err)
+		}
+		interval = parsed
+	}
+	return retention, interval, nil
+}
+
+// materializationRetention reads how many generations of a feature's
+// materialization are kept, from MATERIALIZATION_KEEP_GENERATIONS, and for
+// how long, from MATERIALIZATION_MAX_AGE. It's nil if neither is set, in
+// which case generations are kept until they're compacted by hand.
+func materializationRetention() (*runner.RetentionPolicy, error) {
+	keep, maxAge := os.Getenv("MATERIALIZATION_KEEP_GENERATIONS"), os.Getenv("MATERIALIZATION_MAX_AGE")
+	if keep == "" && maxAge == "" {
+		return nil, nil
+	}
+	policy := &runner.RetentionPolicy{}
+	var err error
+	if keep != "" {
+		if policy.KeepGenerations, err = strconv.Atoi(keep); err != nil || policy.KeepGenerations < 0 {
+			return nil, fmt.Errorf("MATERIALIZATION_KEEP_GENERATIONS: invalid count %q", keep)
+		}
+	} else {
+		// Only the age of generations bounds how many are kept.
+		policy.KeepGenerations = math.MaxInt32
+	}
+	if maxAge != "" {
+		if policy.MaxAge, err = time.ParseDuration(maxAge); err != nil {
+			return nil, fmt.Errorf("MATERIALIZATION_MAX_AGE: %w", err)
+		}
+	}
+	return policy, nil
+}
+
+// catchUpPolicies reads the default catch-up policy and the policies of
+// resources, written as "name.variant=policy,name.variant=policy".
+func catchUpPolicies(defaultPolicy, resourcePolicies string) (coordinator.CatchUpPolicies, error) {
+	policies := coordinator.CatchUpPolicies{Resources: make(map[metadata.NameVariant]coordinator.CatchUpPolicy)}
+	var err error
+	if policies.Default, err = coordinator.ParseCatchUpPolicy(defaultPolicy); err != nil {
+		return policies, err
+	}
+	for _, entry := range strings.Split(resourcePolicies, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		nameVariant := strings.SplitN(parts[0], ".", 2)
+		if len(parts) != 2 || len(nameVariant) != 2 {
+			return policies, fmt.Errorf("invalid resource catch-up policy %q", entry)
+		}
+		policy, err := coordinator.ParseCatchUpPolicy(parts[1])
+		if err != nil {
+			return policies, fmt.Errorf("%s: %w", parts[0], err)
+		}
+		policies.Resources[metadata.NameVariant{Name: nameVariant[0], Variant: nameVariant[1]}] = policy
+	}
+	return policies, nil
+}
+
+// kubernetesJobSpawner returns a spawner whose pods are configured by the
+// WORKER_* env vars. Pods get Kubernetes' defaults for what isn't set.
+func kubernetesJobSpawner() (*coordinator.KubernetesJobSpawner, error) {
+	requests, err := resourceList(os.Getenv("WORKER_CPU_REQUEST"), os.Getenv("WORKER_MEMORY_REQUEST"))
+	if err != nil {
+		return nil, fmt.Errorf("worker requests: %w", err)
+	}
+	limits, err := resourceList(os.Getenv("WORKER_CPU_LIMIT"), os.Getenv("WORKER_MEMORY_LIMIT"))
+	if err != nil {
+		return nil, fmt.Errorf("worker limits: %w", err)
+	}
+	nodeSelector, err := parsePairs(os.Getenv("WORKER_NODE_SELECTOR"))
+	if err != nil {
+		return nil, fmt.Errorf("worker node selector: %w", err)
+	}
+	tolerations, err := parseTolerations(os.Getenv("WORKER_TOLERATIONS"))
+	if err != nil {
+		return nil, fmt.Errorf("worker tolerations: %w", err)
+	}
+	labels, err := parsePairs(os.Getenv("WORKER_LABELS"))
+	if err != nil {
+		return nil, fmt.Errorf("worker labels: %w", err)
+	}
+	annotations, err := parsePairs(os.Getenv("WORKER_ANNOTATIONS"))
+	if err != nil {
+		return nil, fmt.Errorf("worker annotations: %w", err)
+	}
+	var ttl *int32
+	if value := os.Getenv("WORKER_TTL_SECONDS_AFTER_FINISHED"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || seconds < 0 {
+			return nil, fmt.Errorf("invalid worker TTL after finished %q", value)
+		}
+		ttl = new(int32)
+		*ttl = int32(seconds)
+	}
+	images, err := parsePairs(os.Getenv("WORKER_PROVIDER_IMAGES"))
+	if err != nil {
+		return nil, fmt.Errorf("worker provider images: %w", err)
+	}
+	providerImages := make(map[provider.Type]string)
+	for providerType, image := range images {
+		providerImages[provider.Type(providerType)] = image
+	}
+	jobResources, err := parseCloudRunResources(os.Getenv("WORKER_JOB_RESOURCES"))
+	if err != nil {
+		return nil, fmt.Errorf("worker job resources: %w", err)
+	}
+	kubernetesResources := make(map[string]v1.ResourceRequirements)
+	for job, limits := range jobResources {
+		list, err := resourceList(limits.CPU, limits.Memory)
+		if err != nil {
+			return nil, fmt.Errorf("resources for %s: %w", job, err)
+		}
+		kubernetesResources[job] = v1.ResourceRequirements{Requests: list, Limits: list}
+	}
+	var verifier runner.ImageVerifier
+	if key := os.Getenv("WORKER_IMAGE_COSIGN_KEY"); key != "" {
+		verifier = &runner.CosignVerifier{Key: key, Path: os.Getenv("COSIGN_PATH")}
+	}
+	return &coordinator.KubernetesJobSpawner{
+		Image:          os.Getenv("WORKER_IMAGE"),
+		ImageDigest:    os.Getenv("WORKER_IMAGE_DIGEST"),
+		ProviderImages: providerImages,
+		Verifier:       verifier,
+		Pod: runner.KubernetesPodConfig{
+			Resources:               v1.ResourceRequirements{Requests: requests, Limits: limits},
+			NodeSelector:            nodeSelector,
+			Tolerations:             tolerations,
+			ServiceAccount:          os.Getenv("WORKER_SERVICE_ACCOUNT"),
+			Labels:                  labels,
+			Annotations:             annotations,
+			TTLSecondsAfterFinished: ttl,
+		},
+		JobResources: kubernetesResources,
+	}, nil
+}
+
+// resourceList returns the cpu and memory quantities that are set, or nil if
+// neither is.
+func resourceList(cpu, memory string) (v1.ResourceList, error) {
+	var list v1.ResourceList
+	for name, value := range map[v1.ResourceName]string{v1.ResourceCPU: cpu, v1.ResourceMemory: memory} {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if list == nil {
+			list = v1.ResourceList{}
+		}
+		list[name] = quantity
+	}
+	return list, nil
+}
+
+// parsePairs reads a map written as "key=value,key=value".
+func parsePairs(value string) (map[string]string, error) {
+	var pairs map[string]string
+	if value == "" {
+		return pairs, nil
+	}
+	pairs = make(map[string]string)
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected key=value, got %q", entry)
+		}
+		pairs[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return pairs, nil
+}
+
+// parseTolerations reads tolerations written as "key=value:Effect,key:Effect".
+// A toleration without a value tolerates any value of its key.
+func parseTolerations(value string) ([]v1.Toleration, error) {
+	var tolerations []v1.Toleration
+	if value == "" {
+		return tolerations, nil
+	}
+	for _, entry := range strings.Split(value, ",") {
+		i := strings.LastIndex(entry, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("expected key=value:Effect, got %q", entry)
+		}
+		toleration := v1.Toleration{Effect: v1.TaintEffect(strings.TrimSpace(entry[i+1:]))}
+		switch toleration.Effect {
+		case "", v1.TaintEffectNoSchedule, v1.TaintEffectPreferNoSchedule, v1.TaintEffectNoExecute:
+		default:
+			return nil, fmt.Errorf("unknown taint effect %s", toleration.Effect)
+		}
+		key := strings.TrimSpace(entry[:i])
+		if parts := strings.SplitN(key, "=", 2); len(parts) == 2 {
+			toleration.Key, toleration.Value = parts[0], parts[1]
+			toleration.Operator = v1.TolerationOpEqual
+		} else {
+			toleration.Key = key
+			toleration.Operator = v1.TolerationOpExists
+		}
+		tolerations = append(tolerations, toleration)
+	}
+	return tolerations, nil
+}
+
+func envInt(name string) (int, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
+
+// chunkSizing reads how materializations are split into chunks from
+// MATERIALIZE_CHUNK_ROWS, which fixes the size, or the target bytes, target
+// duration and bounds that adaptive sizes are chosen within. It returns nil
+// if none of them are set.
+func chunkSizing() (*runner.ChunkSizing, error) {
+	sizing := &runner.ChunkSizing{}
+	set := false
+	for name, value := range map[string]*int64{
+		"MATERIALIZE_CHUNK_ROWS":         &sizing.Rows,
+		"MATERIALIZE_CHUNK_TARGET_BYTES": &sizing.TargetBytes,
+		"MATERIALIZE_CHUNK_MIN_ROWS":     &sizing.MinRows,
+		"MATERIALIZE_CHUNK_MAX_ROWS":     &sizing.MaxRows,
+	} {
+		env := os.Getenv(name)
+		if env == "" {
+			continue
+		}
+		parsed, err := strconv.ParseInt(env, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		*value, set = parsed, true
+	}
+	if duration := os.Getenv("MATERIALIZE_CHUNK_TARGET_DURATION"); duration != "" {
+		parsed, err := time.ParseDuration(duration)
+		if err != nil {
+			return nil, fmt.Errorf("MATERIALIZE_CHUNK_TARGET_DURATION: %w", err)
+		}
+		sizing.TargetDuration, set = parsed, true
+	}
+	if !set {
+		return nil, nil
+	}
+	return sizing, nil
+}
+
+// envList splits a comma separated env var, like a list of subnets.
+func envList(name string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(name), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// parseProviderLimits reads limits written as "provider=limit,provider=limit".
+func parseProviderLimits(value string) (map[string]int, error) {
+	limits := make(map[string]int)
+	if value == "" {
+		return limits, nil
+	}
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected provider=limit, got %q", entry)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("limit for %s: %w", parts[0], err)
+		}
+		limits[strings.TrimSpace(parts[0])] = limit
+	}
+	return limits, nil
+}
+
+// parseCloudRunResources reads resources written as
+// "job=cpu:memory,job=cpu:memory", where job is a job name such as
+// Materialize. Either limit can be left out, as in "Materialize=:16Gi".
+func parseCloudRunResources(value string) (map[string]runner.CloudRunResources, error) {
+	resources := make(map[string]runner.CloudRunResources)
+	if value == "" {
+		return resources, nil
+	}
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected job=cpu:memory, got %q", entry)
+		}
+		limits := strings.SplitN(parts[1], ":", 2)
+		if len(limits) != 2 {
+			return nil, fmt.Errorf("expected cpu:memory for %s, got %q", parts[0], parts[1])
+		}
+		resources[strings.TrimSpace(parts[0])] = runner.CloudRunResources{
+			CPU:    strings.TrimSpace(limits[0]),
+			Memory: strings.TrimSpace(limits[1]),
+		}
+	}
+	return resources, nil
+}
+
+// parseMaxRuntimes reads max runtimes written as "type=duration,type=duration",
+// where type is a resource type such as FEATURE_VARIANT.
+func parseMaxRuntimes(value string) (map[metadata.ResourceType]time.Duration, error) {
+	runtimes := make(map[metadata.ResourceType]time.Duration)
+	if value == "" {
+		return runtimes, nil
+	}
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("expected type=duration, got %q", entry)
+		}
+		name := strings.TrimSpace(parts[0])
+		resourceType, ok := pb.ResourceType_value[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown resource type %s", name)
+		}
+		runtime, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("max runtime for %s: %w", name, err)
+		}
+		runtimes[metadata.ResourceType(resourceType)] = runtime
+	}
+	return runtimes, nil
 }
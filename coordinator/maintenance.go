@@ -0,0 +1,47 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/featureform/metadata"
+)
+
+// LockResource stops all jobs against id from running until UnlockResource
+// is called. Jobs that are triggered while it's locked wait for the lock to
+// be released, and scheduled runs are skipped.
+func (c *Coordinator) LockResource(id metadata.ResourceID, reason string) error {
+	if err := metadata.SetMaintenanceLock(context.Background(), *c.KVClient, id, reason); err != nil {
+		return fmt.Errorf("set maintenance lock: %w", err)
+	}
+	c.Logger.Infow("Locked resource for maintenance", "resource", id, "reason", reason)
+	return nil
+}
+
+// UnlockResource releases the maintenance lock on id and runs the jobs that
+// were waiting on it, both its own job and a manual run of it, and its
+// backfill.
+func (c *Coordinator) UnlockResource(id metadata.ResourceID) error {
+	ctx := context.Background()
+	keys, err := metadata.ReleaseMaintenanceLock(ctx, *c.KVClient, id)
+	if err != nil {
+		return fmt.Errorf("release maintenance lock: %w", err)
+	}
+	c.Logger.Infow("Unlocked resource", "resource", id, "jobs", keys)
+	for _, key := range keys {
+		if err := c.publishJob(ctx, key); err != nil {
+			return err
+		}
+	}
+	return c.resumeBackfill(ctx, id)
+}
+
+// MaintenanceLock returns the maintenance lock on id, or nil if it isn't
+// locked.
+func (c *Coordinator) MaintenanceLock(id metadata.ResourceID) (*metadata.MaintenanceLock, error) {
+	lock, err := metadata.GetMaintenanceLock(context.Background(), *c.KVClient, id)
+	if err != nil {
+		return nil, fmt.Errorf("get maintenance lock: %w", err)
+	}
+	return lock, nil
+}
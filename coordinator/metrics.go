@@ -0,0 +1,55 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/featureform/coordinator/queue"
+	"github.com/featureform/metadata"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// jobMetricLabels returns the resource type and provider that the runs of
+// id's job are labeled with. A provider that can't be looked up is left
+// blank, rather than failing the job.
+func (c *Coordinator) jobMetricLabels(ctx context.Context, id metadata.ResourceID) (string, string) {
+	providers, _, err := c.jobProviders(ctx, id)
+	if err != nil {
+		c.Logger.Debugw("Could not get job provider for metrics", "resource", id, "error", err)
+	}
+	provider := ""
+	if len(providers) > 0 {
+		provider = providers[0]
+	}
+	return id.Type.String(), provider
+}
+
+// observeJobRun counts a run of id's job as it starts, and returns a function
+// that records how it ended.
+func (c *Coordinator) observeJobRun(ctx context.Context, id metadata.ResourceID) func(status metadata.JobRunStatus, duration time.Duration) {
+	if c.Metrics == nil {
+		return func(metadata.JobRunStatus, time.Duration) {}
+	}
+	resourceType, provider := c.jobMetricLabels(ctx, id)
+	c.Metrics.JobStarted(resourceType, provider)
+	return func(status metadata.JobRunStatus, duration time.Duration) {
+		c.Metrics.JobFinished(resourceType, provider, strings.ToLower(string(status)), duration)
+	}
+}
+
+func (c *Coordinator) observeLockWait(wait time.Duration) {
+	if c.Metrics != nil {
+		c.Metrics.ObserveLockWait(wait)
+	}
+}
+
+// QueueDepth returns how many jobs are waiting to be run or are running.
+func (c *Coordinator) QueueDepth(ctx context.Context) (int, error) {
+	resp, err := (*c.KVClient).Get(ctx, queue.JobPrefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+	if err != nil {
+		return 0, fmt.Errorf("count jobs: %w", err)
+	}
+	return int(resp.Count), nil
+}
@@ -0,0 +1,229 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/runner"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+var onlineMigrationSchema = ConfigSchema{
+	"Features":    {Type: ListConfig, Required: true},
+	"Destination": {Type: StringConfig, Required: true},
+	"Requester":   {Type: StringConfig},
+}
+
+// The migration job type is added here rather than with the other built-in
+// job types, since migrations look through the job types for the jobs they
+// wait on.
+func init() {
+	jobTypes[metadata.MigrateOnlineJobKind] = JobType{Name: metadata.MigrateOnlineJobKind, Handler: runOnlineMigrationJob, Schema: onlineMigrationSchema}
+}
+
+// runOnlineMigrationJob runs a migration queued by the metadata server's
+// MigrateOnlineStore.
+func runOnlineMigrationJob(ctx context.Context, c *Coordinator, job Job) error {
+	migration := &metadata.OnlineMigration{}
+	if err := migration.Deserialize(job.Config); err != nil {
+		return permanent(fmt.Errorf("deserialize migration: %w", err))
+	}
+	return c.MigrateOnlineStore(ctx, migration.Features, migration.Destination, migration.Requester)
+}
+
+// MigrateOnlineStore copies the online values of features to the destination
+// provider, verifies them, and then switches the features over so they're
+// served from the destination. The features must share an online provider.
+//
+// The features are locked for maintenance from before the copy until every
+// one of them is switched over, so that no job writes values the copy would
+// miss. If a migration fails part way through switching over, the features
+// stay locked, and running it again, such as on the job's next attempt,
+// picks up with the features that haven't been switched over yet.
+func (c *Coordinator) MigrateOnlineStore(ctx context.Context, features []metadata.NameVariant, destination, requester string) error {
+	if len(features) == 0 {
+		return permanent(fmt.Errorf("no features to migrate"))
+	}
+	variants, err := c.store().GetFeatureVariants(ctx, features)
+	if err != nil {
+		return fmt.Errorf("get feature variants: %w", err)
+	}
+	ids := make([]metadata.ResourceID, len(variants))
+	pending := make([]*metadata.FeatureVariant, 0, len(variants))
+	sourceName := ""
+	for i, variant := range variants {
+		ids[i] = metadata.ResourceID{Name: variant.Name(), Variant: variant.Variant(), Type: metadata.FEATURE_VARIANT}
+		if variant.Provider() == destination {
+			continue
+		}
+		if sourceName == "" {
+			sourceName = variant.Provider()
+		} else if variant.Provider() != sourceName {
+			return permanent(fmt.Errorf("features are served from different providers: %s and %s", sourceName, variant.Provider()))
+		}
+		pending = append(pending, variant)
+	}
+	reason := fmt.Sprintf("online store migration to %s", destination)
+	if len(pending) == 0 {
+		// Every feature was switched over by an earlier run, which left them
+		// locked.
+		return c.unlockMigration(ids, reason)
+	}
+	if err := c.lockForMigration(ctx, ids, reason); err != nil {
+		return err
+	}
+	c.Logger.Infow("Starting online store migration", "features", features, "from", sourceName, "to", destination)
+	if err := c.copyOnlineFeatures(ctx, pending, sourceName, destination); err != nil {
+		if len(pending) == len(variants) {
+			// Nothing has been switched over, so the features can go on being
+			// served and updated from their provider.
+			if unlockErr := c.unlockMigration(ids, reason); unlockErr != nil {
+				c.Logger.Errorw("Could not unlock features after failed migration", "features", features, "error", unlockErr)
+			}
+		}
+		return err
+	}
+	for _, variant := range pending {
+		nv := metadata.NameVariant{Name: variant.Name(), Variant: variant.Variant()}
+		if err := c.store().UpdateFeatureVariantProvider(ctx, nv, destination, requester); err != nil {
+			return fmt.Errorf("cut over %s (%s): %w", nv.Name, nv.Variant, err)
+		}
+	}
+	if err := c.unlockMigration(ids, reason); err != nil {
+		return err
+	}
+	c.Logger.Infow("Online store migration complete", "features", features, "from", sourceName, "to", destination)
+	return nil
+}
+
+// lockForMigration locks the features for the migration, unless they're
+// locked for something else, and waits for the jobs already running against
+// them to finish.
+func (c *Coordinator) lockForMigration(ctx context.Context, ids []metadata.ResourceID, reason string) error {
+	unlocked := make([]metadata.ResourceID, 0, len(ids))
+	for _, id := range ids {
+		lock, err := c.MaintenanceLock(id)
+		if err != nil {
+			return err
+		}
+		if lock == nil {
+			unlocked = append(unlocked, id)
+		} else if lock.Reason != reason {
+			return permanent(fmt.Errorf("%s (%s) is locked for maintenance: %s", id.Name, id.Variant, lock.Reason))
+		}
+	}
+	for _, id := range unlocked {
+		if err := c.LockResource(id, reason); err != nil {
+			return err
+		}
+	}
+	for _, id := range ids {
+		if err := c.awaitRunningJobs(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unlockMigration releases the locks the migration holds on the features.
+func (c *Coordinator) unlockMigration(ids []metadata.ResourceID, reason string) error {
+	for _, id := range ids {
+		lock, err := c.MaintenanceLock(id)
+		if err != nil {
+			return err
+		}
+		if lock == nil || lock.Reason != reason {
+			continue
+		}
+		if err := c.UnlockResource(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// awaitRunningJobs waits for every job that's running against id to finish,
+// by taking and releasing its job lock. Jobs that haven't started by then
+// don't while id is locked for maintenance.
+func (c *Coordinator) awaitRunningJobs(ctx context.Context, id metadata.ResourceID) error {
+	keys := []string{metadata.GetJobKey(id)}
+	jobTypesMtx.RLock()
+	for kind := range jobTypes {
+		keys = append(keys, jobTypeKey(kind, id))
+	}
+	jobTypesMtx.RUnlock()
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(c.lockTTL()))
+	if err != nil {
+		return fmt.Errorf("new session: %w", err)
+	}
+	defer s.Close()
+	for _, key := range keys {
+		resp, err := (*c.KVClient).Get(ctx, key, clientv3.WithCountOnly())
+		if err != nil {
+			return fmt.Errorf("get job %s: %w", key, err)
+		}
+		if resp.Count == 0 {
+			continue
+		}
+		mtx, err := c.createJobLock(ctx, key, s)
+		if err != nil {
+			return err
+		}
+		if err := mtx.Unlock(ctx); err != nil {
+			return fmt.Errorf("release job lock %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func (c *Coordinator) copyOnlineFeatures(ctx context.Context, variants []*metadata.FeatureVariant, sourceName, destination string) error {
+	migrationFeatures := make([]runner.MigrationFeature, len(variants))
+	for i, variant := range variants {
+		migrationFeatures[i] = runner.MigrationFeature{
+			ID:    provider.ResourceID{Name: variant.Name(), Variant: variant.Variant(), Type: provider.Feature},
+			VType: provider.ValueType(variant.Type()),
+		}
+	}
+	source, err := c.store().GetProvider(ctx, sourceName)
+	if err != nil {
+		return fmt.Errorf("get source provider: %w", err)
+	}
+	dest, err := c.store().GetProvider(ctx, destination)
+	if err != nil {
+		return fmt.Errorf("get destination provider: %w", err)
+	}
+	sourceConfig, err := c.runnerProviderConfig(source)
+	if err != nil {
+		return err
+	}
+	destConfig, err := c.runnerProviderConfig(dest)
+	if err != nil {
+		return err
+	}
+	migrationConfig := &runner.OnlineMigrationRunnerConfig{
+		SourceType:        provider.Type(source.Type()),
+		SourceConfig:      sourceConfig,
+		DestinationType:   provider.Type(dest.Type()),
+		DestinationConfig: destConfig,
+		Features:          migrationFeatures,
+	}
+	serialized, err := migrationConfig.Serialize()
+	if err != nil {
+		return fmt.Errorf("serialize migration config: %w", err)
+	}
+	jobRunner, err := c.Spawner.GetJobRunner(runner.MIGRATE_ONLINE, serialized, c.etcdEndpoints(), metadata.ResourceID{})
+	if err != nil {
+		return fmt.Errorf("create migration runner: %w", err)
+	}
+	watcher, err := jobRunner.Run()
+	if err != nil {
+		return fmt.Errorf("run migration: %w", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+// Package mocks has in-memory stand-ins for the metadata server, providers
+// and job spawner that coordinator jobs depend on, so that jobs can be tested
+// without etcd, Postgres or Redis. They're injected through the
+// coordinator's Resources, Providers and Spawner fields.
+package mocks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/featureform/metadata"
+	pb "github.com/featureform/metadata/proto"
+)
+
+// Metadata is an in-memory coordinator.MetadataStore. Resources are added in
+// their serialized form, and status changes made by jobs are written back to
+// them.
+type Metadata struct {
+	mtx          sync.Mutex
+	providers    map[string]*pb.Provider
+	sources      map[metadata.NameVariant]*pb.SourceVariant
+	features     map[metadata.NameVariant]*pb.FeatureVariant
+	labels       map[metadata.NameVariant]*pb.LabelVariant
+	trainingSets map[metadata.NameVariant]*pb.TrainingSetVariant
+	stats        map[metadata.ResourceID]metadata.TableStats
+	progress     map[metadata.ResourceID][]metadata.JobProgress
+}
+
+func NewMetadata() *Metadata {
+	return &Metadata{
+		providers:    make(map[string]*pb.Provider),
+		sources:      make(map[metadata.NameVariant]*pb.SourceVariant),
+		features:     make(map[metadata.NameVariant]*pb.FeatureVariant),
+		labels:       make(map[metadata.NameVariant]*pb.LabelVariant),
+		trainingSets: make(map[metadata.NameVariant]*pb.TrainingSetVariant),
+		stats:        make(map[metadata.ResourceID]metadata.TableStats),
+		progress:     make(map[metadata.ResourceID][]metadata.JobProgress),
+	}
+}
+
+func (m *Metadata) AddProvider(p *pb.Provider) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.providers[p.Name] = p
+}
+
+func (m *Metadata) AddSourceVariant(v *pb.SourceVariant) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.sources[metadata.NameVariant{Name: v.Name, Variant: v.Variant}] = v
+}
+
+func (m *Metadata) AddFeatureVariant(v *pb.FeatureVariant) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.features[metadata.NameVariant{Name: v.Name, Variant: v.Variant}] = v
+}
+
+func (m *Metadata) AddLabelVariant(v *pb.LabelVariant) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.labels[metadata.NameVariant{Name: v.Name, Variant: v.Variant}] = v
+}
+
+func (m *Metadata) AddTrainingSetVariant(v *pb.TrainingSetVariant) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.trainingSets[metadata.NameVariant{Name: v.Name, Variant: v.Variant}] = v
+}
+
+func (m *Metadata) GetProvider(ctx context.Context, name string) (*metadata.Provider, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	p, has := m.providers[name]
+	if !has {
+		return nil, fmt.Errorf("provider %s not found", name)
+	}
+	return metadata.WrapProvider(p), nil
+}
+
+// ListProviders returns the providers sorted by name.
+func (m *Metadata) ListProviders(ctx context.Context) ([]*metadata.Provider, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	providers := make([]*metadata.Provider, 0, len(m.providers))
+	for _, p := range m.providers {
+		providers = append(providers, metadata.WrapProvider(p))
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Name() < providers[j].Name() })
+	return providers, nil
+}
+
+func (m *Metadata) GetSourceVariant(ctx context.Context, id metadata.NameVariant) (*metadata.SourceVariant, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	v, has := m.sources[id]
+	if !has {
+		return nil, fmt.Errorf("source variant %s (%s) not found", id.Name, id.Variant)
+	}
+	return metadata.WrapSourceVariant(v), nil
+}
+
+func (m *Metadata) GetSourceVariants(ctx context.Context, ids []metadata.NameVariant) ([]*metadata.SourceVariant, error) {
+	variants := make([]*metadata.SourceVariant, len(ids))
+	for i, id := range ids {
+		v, err := m.GetSourceVariant(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		variants[i] = v
+	}
+	return variants, nil
+}
+
+func (m *Metadata) GetFeatureVariant(ctx context.Context, id metadata.NameVariant) (*metadata.FeatureVariant, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	v, has := m.features[id]
+	if !has {
+		return nil, fmt.Errorf("feature variant %s (%s) not found", id.Name, id.Variant)
+	}
+	return metadata.WrapFeatureVariant(v), nil
+}
+
+func (m *Metadata) GetFeatureVariants(ctx context.Context, ids []metadata.NameVariant) ([]*metadata.FeatureVariant, error) {
+	variants := make([]*metadata.FeatureVariant, len(ids))
+	for i, id := range ids {
+		v, err := m.GetFeatureVariant(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		variants[i] = v
+	}
+	return variants, nil
+}
+
+func (m *Metadata) GetLabelVariant(ctx context.Context, id metadata.NameVariant) (*metadata.LabelVariant, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	v, has := m.labels[id]
+	if !has {
+		return nil, fmt.Errorf("label variant %s (%s) not found", id.Name, id.Variant)
+	}
+	return metadata.WrapLabelVariant(v), nil
+}
+
+func (m *Metadata) GetLabelVariants(ctx context.Context, ids []metadata.NameVariant) ([]*metadata.LabelVariant, error) {
+	variants := make([]*metadata.LabelVariant, len(ids))
+	for i, id := range ids {
+		v, err := m.GetLabelVariant(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		variants[i] = v
+	}
+	return variants, nil
+}
+
+func (m *Metadata) GetTrainingSetVariant(ctx context.Context, id metadata.NameVariant) (*metadata.TrainingSetVariant, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	v, has := m.trainingSets[id]
+	if !has {
+		return nil, fmt.Errorf("training set variant %s (%s) not found", id.Name, id.Variant)
+	}
+	return metadata.WrapTrainingSetVariant(v), nil
+}
+
+func (m *Metadata) SetStatus(ctx context.Context, id metadata.ResourceID, status metadata.ResourceStatus, errorMessage string) error {
+	return m.SetStatusWithCode(ctx, id, status, "", errorMessage)
+}
+
+func (m *Metadata) SetStatusWithCode(ctx context.Context, id metadata.ResourceID, status metadata.ResourceStatus, code metadata.ErrorCode, errorMessage string) error {
+	return m.setStatus(id, &pb.ResourceStatus{Status: pb.ResourceStatus_Status(status), ErrorMessage: errorMessage, ErrorCode: string(code)})
+}
+
+func (m *Metadata) SetProgress(ctx context.Context, id metadata.ResourceID, progress metadata.JobProgress) error {
+	if err := m.setStatus(id, &pb.ResourceStatus{Status: pb.ResourceStatus_PENDING, Progress: progress.Serialize()}); err != nil {
+		return err
+	}
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.progress[id] = append(m.progress[id], progress)
+	return nil
+}
+
+func (m *Metadata) setStatus(id metadata.ResourceID, serialized *pb.ResourceStatus) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	nv := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	var has bool
+	switch id.Type {
+	case metadata.SOURCE_VARIANT:
+		var v *pb.SourceVariant
+		if v, has = m.sources[nv]; has {
+			v.Status = serialized
+		}
+	case metadata.FEATURE_VARIANT:
+		var v *pb.FeatureVariant
+		if v, has = m.features[nv]; has {
+			v.Status = serialized
+		}
+	case metadata.LABEL_VARIANT:
+		var v *pb.LabelVariant
+		if v, has = m.labels[nv]; has {
+			v.Status = serialized
+		}
+	case metadata.TRAINING_SET_VARIANT:
+		var v *pb.TrainingSetVariant
+		if v, has = m.trainingSets[nv]; has {
+			v.Status = serialized
+		}
+	}
+	if !has {
+		return fmt.Errorf("%s %s (%s) not found", id.Type, id.Name, id.Variant)
+	}
+	return nil
+}
+
+func (m *Metadata) SetStats(ctx context.Context, id metadata.ResourceID, stats metadata.TableStats) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.stats[id] = stats
+	return nil
+}
+
+func (m *Metadata) SetTrainingSetFreshness(ctx context.Context, id metadata.NameVariant, freshness metadata.TrainingSetFreshness) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	ts, has := m.trainingSets[id]
+	if !has {
+		return fmt.Errorf("training set %s (%s) not found", id.Name, id.Variant)
+	}
+	ts.Freshness = freshness.Serialize()
+	return nil
+}
+
+// UpdateFeatureVariantProvider switches the online provider a feature
+// variant is served from.
+func (m *Metadata) UpdateFeatureVariantProvider(ctx context.Context, id metadata.NameVariant, provider, requester string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	v, has := m.features[id]
+	if !has {
+		return fmt.Errorf("feature variant %s (%s) not found", id.Name, id.Variant)
+	}
+	v.Provider = provider
+	return nil
+}
+
+// Status returns the status a resource variant was last set to, and its
+// error message.
+func (m *Metadata) Status(id metadata.ResourceID) (metadata.ResourceStatus, string) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	status := m.status(id)
+	return metadata.ResourceStatus(status.GetStatus()), status.GetErrorMessage()
+}
+
+// Progress returns every progress report made for a resource variant's
+// jobs, in order.
+func (m *Metadata) Progress(id metadata.ResourceID) []metadata.JobProgress {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return append([]metadata.JobProgress(nil), m.progress[id]...)
+}
+
+// ErrorCode returns the code of the error a resource variant's status was
+// last set with.
+func (m *Metadata) ErrorCode(id metadata.ResourceID) metadata.ErrorCode {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	return metadata.ErrorCode(m.status(id).GetErrorCode())
+}
+
+func (m *Metadata) status(id metadata.ResourceID) *pb.ResourceStatus {
+	nv := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	switch id.Type {
+	case metadata.SOURCE_VARIANT:
+		if v, has := m.sources[nv]; has {
+			return v.Status
+		}
+	case metadata.FEATURE_VARIANT:
+		if v, has := m.features[nv]; has {
+			return v.Status
+		}
+	case metadata.LABEL_VARIANT:
+		if v, has := m.labels[nv]; has {
+			return v.Status
+		}
+	case metadata.TRAINING_SET_VARIANT:
+		if v, has := m.trainingSets[nv]; has {
+			return v.Status
+		}
+	}
+	return nil
+}
+
+// Stats returns the table stats recorded for a resource variant.
+func (m *Metadata) Stats(id metadata.ResourceID) (metadata.TableStats, bool) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	stats, has := m.stats[id]
+	return stats, has
+}
@@ -0,0 +1,105 @@
+package mocks
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/featureform/provider"
+)
+
+// Providers is a coordinator.ProviderFactory that returns the provider added
+// for each type, whatever its config.
+type Providers struct {
+	mtx       sync.Mutex
+	providers map[provider.Type]provider.Provider
+}
+
+func NewProviders() *Providers {
+	return &Providers{providers: make(map[provider.Type]provider.Provider)}
+}
+
+func (p *Providers) Add(t provider.Type, impl provider.Provider) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.providers[t] = impl
+}
+
+func (p *Providers) Get(t provider.Type, config provider.SerializedConfig) (provider.Provider, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	impl, has := p.providers[t]
+	if !has {
+		return nil, fmt.Errorf("no mock provider of type %s", t)
+	}
+	return impl, nil
+}
+
+// OfflineStore records the tables that jobs register in it, and leaves the
+// rest to an in-memory offline store. The data itself is written by job
+// runners, which Spawner stands in for.
+type OfflineStore struct {
+	provider.OfflineStore
+	mtx       sync.Mutex
+	resources map[provider.ResourceID]provider.ResourceSchema
+	primaries map[provider.ResourceID]string
+}
+
+func NewOfflineStore() *OfflineStore {
+	return &OfflineStore{
+		OfflineStore: provider.NewMemoryOfflineStore(),
+		resources:    make(map[provider.ResourceID]provider.ResourceSchema),
+		primaries:    make(map[provider.ResourceID]string),
+	}
+}
+
+func (store *OfflineStore) AsOfflineStore() (provider.OfflineStore, error) {
+	return store, nil
+}
+
+// TrainingSetFreshness is read from the tables written to the in-memory
+// store.
+func (store *OfflineStore) TrainingSetFreshness(def provider.TrainingSetDef) (provider.TrainingSetFreshness, error) {
+	return store.OfflineStore.(provider.TrainingSetFreshnessStore).TrainingSetFreshness(def)
+}
+
+// RegisterResourceFromSourceTable records the schema. It returns a nil
+// table, since jobs only read resource tables in their runners.
+func (store *OfflineStore) RegisterResourceFromSourceTable(id provider.ResourceID, schema provider.ResourceSchema) (provider.OfflineTable, error) {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+	if _, has := store.resources[id]; has {
+		return nil, &provider.TableAlreadyExists{Feature: id.Name, Variant: id.Variant}
+	}
+	store.resources[id] = schema
+	return nil, nil
+}
+
+// RegisterPrimaryFromSourceTable records the source table. It returns a nil
+// table, which has no schema to validate.
+func (store *OfflineStore) RegisterPrimaryFromSourceTable(id provider.ResourceID, sourceName string) (provider.PrimaryTable, error) {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+	if _, has := store.primaries[id]; has {
+		return nil, &provider.TableAlreadyExists{Feature: id.Name, Variant: id.Variant}
+	}
+	store.primaries[id] = sourceName
+	return nil, nil
+}
+
+// ResourceSchema returns the schema a feature or label's table was
+// registered with.
+func (store *OfflineStore) ResourceSchema(id provider.ResourceID) (provider.ResourceSchema, bool) {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+	schema, has := store.resources[id]
+	return schema, has
+}
+
+// PrimarySource returns the source table a primary table was registered
+// from.
+func (store *OfflineStore) PrimarySource(id provider.ResourceID) (string, bool) {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+	source, has := store.primaries[id]
+	return source, has
+}
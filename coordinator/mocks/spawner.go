@@ -0,0 +1,114 @@
+package mocks
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+)
+
+// SpawnedJob is a job runner that a coordinator asked the Spawner for.
+type SpawnedJob struct {
+	Name     string
+	Config   runner.Config
+	Resource metadata.ResourceID
+	// Schedule is set if the job was scheduled instead of run.
+	Schedule runner.CronSchedule
+}
+
+// Spawner is a coordinator.JobSpawner whose runners complete immediately.
+// It records each job it's asked for, so tests can check the configs jobs
+// pass to their runners.
+type Spawner struct {
+	// Errors holds the error that runners of each job name fail with.
+	Errors  map[string]error
+	mtx     sync.Mutex
+	jobs    []SpawnedJob
+	deleted []metadata.ResourceID
+}
+
+func (s *Spawner) GetJobRunner(jobName string, config runner.Config, etcdEndpoints []string, id metadata.ResourceID) (runner.Runner, error) {
+	return &Runner{spawner: s, job: SpawnedJob{Name: jobName, Config: config, Resource: id}}, nil
+}
+
+// Jobs returns the jobs that were run or scheduled, in order.
+func (s *Spawner) Jobs() []SpawnedJob {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]SpawnedJob(nil), s.jobs...)
+}
+
+// DeleteJobs records that a resource's jobs were deleted.
+func (s *Spawner) DeleteJobs(id metadata.ResourceID) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.deleted = append(s.deleted, id)
+	return nil
+}
+
+// Deleted returns the resources whose jobs were deleted, in order.
+func (s *Spawner) Deleted() []metadata.ResourceID {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return append([]metadata.ResourceID(nil), s.deleted...)
+}
+
+func (s *Spawner) record(job SpawnedJob) error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.jobs = append(s.jobs, job)
+	return s.Errors[job.Name]
+}
+
+// Runner is a runner.CronRunner returned by Spawner.
+type Runner struct {
+	spawner *Spawner
+	job     SpawnedJob
+}
+
+func (r *Runner) Run() (runner.CompletionWatcher, error) {
+	return &Watcher{err: r.spawner.record(r.job)}, nil
+}
+
+func (r *Runner) ScheduleJob(schedule runner.CronSchedule) error {
+	job := r.job
+	job.Schedule = schedule
+	return r.spawner.record(job)
+}
+
+func (r *Runner) Resource() metadata.ResourceID {
+	return r.job.Resource
+}
+
+func (r *Runner) IsUpdateJob() bool {
+	return false
+}
+
+// Watcher is a completed runner.CompletionWatcher.
+type Watcher struct {
+	err error
+}
+
+func (w *Watcher) Complete() bool {
+	return true
+}
+
+func (w *Watcher) String() string {
+	if w.err != nil {
+		return fmt.Sprintf("Job failed: %v", w.err)
+	}
+	return "Job completed"
+}
+
+func (w *Watcher) Wait() error {
+	return w.err
+}
+
+func (w *Watcher) Err() error {
+	return w.err
+}
+
+func (w *Watcher) Progress() runner.Progress {
+	return runner.Progress{}
+}
@@ -0,0 +1,127 @@
+package coordinator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// PagerDutyEventsURL is where PagerDuty notifications are sent if they don't
+// have a url of their own.
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// NotificationTimeout bounds how long each notification is sent for.
+var NotificationTimeout = 10 * time.Second
+
+// JobNotification is an event about a resource's job.
+type JobNotification struct {
+	Event    metadata.NotificationEvent
+	Resource metadata.ResourceID
+	Message  string
+	Time     time.Time
+}
+
+func (n JobNotification) summary() string {
+	return fmt.Sprintf("%s %s (%s): %s", n.Resource.Type, n.Resource.Name, n.Resource.Variant, n.Message)
+}
+
+// notify sends an event to every registered notification that wants it, in
+// the background. Notifications that can't be sent are logged, so that a
+// broken webhook doesn't hold up or fail jobs.
+func (c *Coordinator) notify(event metadata.NotificationEvent, id metadata.ResourceID, message string) {
+	if c.KVClient == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), NotificationTimeout)
+		defer cancel()
+		notifications, err := metadata.ListNotifications(ctx, *c.KVClient)
+		if err != nil {
+			c.Logger.Errorw("Could not list notifications", "event", event, "resource", id, "error", err)
+			return
+		}
+		job := JobNotification{Event: event, Resource: id, Message: message, Time: time.Now().UTC()}
+		for _, n := range notifications {
+			if !n.Wants(event) {
+				continue
+			}
+			if err := sendNotification(ctx, http.DefaultClient, n, job); err != nil {
+				c.Logger.Errorw("Could not send notification", "notification", n.Name, "event", event, "resource", id, "error", err)
+			}
+		}
+	}()
+}
+
+// sendNotification posts job to n in the format of its kind.
+func sendNotification(ctx context.Context, client *http.Client, n metadata.Notification, job JobNotification) error {
+	url, body := n.URL, webhookEvent(job)
+	switch n.Kind {
+	case metadata.SlackNotification:
+		body = map[string]string{"text": job.summary()}
+	case metadata.PagerDutyNotification:
+		if url == "" {
+			url = PagerDutyEventsURL
+		}
+		body = pagerDutyEvent(n.RoutingKey, job)
+	}
+	serialized, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(serialized))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s notification %s returned %s", n.Kind, n.Name, resp.Status)
+	}
+	return nil
+}
+
+func webhookEvent(job JobNotification) interface{} {
+	return map[string]interface{}{
+		"event": job.Event,
+		"resource": map[string]string{
+			"name":    job.Resource.Name,
+			"variant": job.Resource.Variant,
+			"type":    job.Resource.Type.String(),
+		},
+		"message": job.Message,
+		"time":    job.Time.Format(time.RFC3339),
+	}
+}
+
+// pagerDutyEvent triggers an incident for a failed job or missed schedule,
+// and resolves the resource's incident once its job succeeds. Incidents are
+// deduplicated by resource.
+func pagerDutyEvent(routingKey string, job JobNotification) map[string]interface{} {
+	action, severity := "trigger", "error"
+	switch job.Event {
+	case metadata.NotifyJobSucceeded:
+		action, severity = "resolve", "info"
+	case metadata.NotifyScheduleMissed:
+		severity = "warning"
+	}
+	return map[string]interface{}{
+		"routing_key":  routingKey,
+		"event_action": action,
+		"dedup_key":    fmt.Sprintf("featureform/%s/%s/%s", job.Resource.Type, job.Resource.Name, job.Resource.Variant),
+		"payload": map[string]interface{}{
+			"summary":   job.summary(),
+			"source":    "featureform-coordinator",
+			"severity":  severity,
+			"timestamp": job.Time.Format(time.RFC3339),
+		},
+	}
+}
@@ -0,0 +1,233 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// OrphanedTable is a table in a provider that's named after a resource
+// variant that isn't in metadata. They're left behind by jobs that crashed
+// part way through and by resources that were removed without their data.
+type OrphanedTable struct {
+	Provider string
+	// Online is set for online store tables, which hold features and labels.
+	Online   bool
+	Resource provider.ResourceID
+}
+
+var offlineToMetadataType = map[provider.OfflineResourceType]metadata.ResourceType{
+	provider.Feature:        metadata.FEATURE_VARIANT,
+	provider.Label:          metadata.LABEL_VARIANT,
+	provider.TrainingSet:    metadata.TRAINING_SET_VARIANT,
+	provider.Primary:        metadata.SOURCE_VARIANT,
+	provider.Transformation: metadata.SOURCE_VARIANT,
+}
+
+type variantLister interface {
+	Name() string
+	Variants() []string
+}
+
+// registeredVariants returns every resource variant in metadata that can
+// have tables in a provider.
+func (c *Coordinator) registeredVariants(ctx context.Context) (map[metadata.ResourceID]struct{}, error) {
+	registered := make(map[metadata.ResourceID]struct{})
+	add := func(t metadata.ResourceType, resource variantLister) {
+		for _, variant := range resource.Variants() {
+			registered[metadata.ResourceID{Name: resource.Name(), Variant: variant, Type: t}] = struct{}{}
+		}
+	}
+	features, err := c.Metadata.ListFeatures(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list features: %w", err)
+	}
+	for _, feature := range features {
+		add(metadata.FEATURE_VARIANT, feature)
+	}
+	labels, err := c.Metadata.ListLabels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list labels: %w", err)
+	}
+	for _, label := range labels {
+		add(metadata.LABEL_VARIANT, label)
+	}
+	sources, err := c.Metadata.ListSources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list sources: %w", err)
+	}
+	for _, source := range sources {
+		add(metadata.SOURCE_VARIANT, source)
+	}
+	trainingSets, err := c.Metadata.ListTrainingSets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list training sets: %w", err)
+	}
+	for _, ts := range trainingSets {
+		add(metadata.TRAINING_SET_VARIANT, ts)
+	}
+	return registered, nil
+}
+
+func isOrphaned(registered map[metadata.ResourceID]struct{}, id provider.ResourceID) bool {
+	t, has := offlineToMetadataType[id.Type]
+	if !has {
+		return false
+	}
+	_, isRegistered := registered[metadata.ResourceID{Name: id.Name, Variant: id.Variant, Type: t}]
+	return !isRegistered
+}
+
+// FindOrphanedTables lists the orphaned tables in every provider that can
+// list its tables. Nothing is deleted; the report is meant to be reviewed
+// before passing it to the metadata server's DeleteOrphanedTables, which
+// queues a job that deletes the tables.
+func (c *Coordinator) FindOrphanedTables(ctx context.Context) ([]OrphanedTable, error) {
+	registered, err := c.registeredVariants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	providers, err := c.Metadata.ListProviders(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list providers: %w", err)
+	}
+	orphans := make([]OrphanedTable, 0)
+	for _, p := range providers {
+		found, err := c.findOrphanedTables(p, registered)
+		if err != nil {
+			return nil, fmt.Errorf("list %s tables: %w", p.Name(), err)
+		}
+		orphans = append(orphans, found...)
+	}
+	return orphans, nil
+}
+
+func (c *Coordinator) findOrphanedTables(p *metadata.Provider, registered map[metadata.ResourceID]struct{}) ([]OrphanedTable, error) {
+	store, err := c.providers().Get(provider.Type(p.Type()), provider.SerializedConfig(p.SerializedConfig()))
+	if err != nil {
+		return nil, fmt.Errorf("get provider: %w", err)
+	}
+	if c, ok := store.(interface{ Close() error }); ok {
+		defer c.Close()
+	}
+	var orphans []OrphanedTable
+	if lister, ok := store.(provider.ResourceLister); ok {
+		ids, err := lister.ListResources()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if isOrphaned(registered, id) {
+				orphans = append(orphans, OrphanedTable{Provider: p.Name(), Resource: id})
+			}
+		}
+	}
+	if lister, ok := store.(provider.OnlineTableLister); ok {
+		ids, err := lister.ListTables()
+		if err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			if isOrphaned(registered, id) {
+				orphans = append(orphans, OrphanedTable{Provider: p.Name(), Online: true, Resource: id})
+			}
+		}
+	}
+	return orphans, nil
+}
+
+var orphanDeletionSchema = ConfigSchema{
+	"Provider":  {Type: StringConfig, Required: true},
+	"Tables":    {Type: ListConfig, Required: true},
+	"Requester": {Type: StringConfig},
+}
+
+// runOrphanDeletionJob drops the reviewed tables queued by the metadata
+// server's DeleteOrphanedTables.
+func runOrphanDeletionJob(ctx context.Context, c *Coordinator, job Job) error {
+	deletion := &metadata.OrphanDeletion{}
+	if err := deletion.Deserialize(job.Config); err != nil {
+		return permanent(fmt.Errorf("deserialize orphan deletion: %w", err))
+	}
+	tables := make([]OrphanedTable, len(deletion.Tables))
+	for i, table := range deletion.Tables {
+		tables[i] = OrphanedTable{
+			Provider: deletion.Provider,
+			Online:   table.Online,
+			Resource: provider.ResourceID{Name: table.Name, Variant: table.Variant, Type: provider.OfflineResourceType(table.TableType)},
+		}
+	}
+	deleted, err := c.DeleteOrphanedTables(ctx, tables)
+	c.Logger.Infow("Deleted orphaned tables", "provider", deletion.Provider, "requester", deletion.Requester, "requested", len(tables), "deleted", len(deleted))
+	return err
+}
+
+// DeleteOrphanedTables drops the given tables and returns the ones it
+// dropped. Tables whose resource has been registered since they were found
+// are skipped.
+func (c *Coordinator) DeleteOrphanedTables(ctx context.Context, tables []OrphanedTable) ([]OrphanedTable, error) {
+	registered, err := c.registeredVariants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deleted := make([]OrphanedTable, 0, len(tables))
+	for _, table := range tables {
+		if !isOrphaned(registered, table.Resource) {
+			c.Logger.Infow("Skipping table that's no longer orphaned", "provider", table.Provider, "resource", table.Resource)
+			continue
+		}
+		if err := c.deleteOrphanedTable(ctx, table); err != nil {
+			return deleted, fmt.Errorf("delete %s table %v: %w", table.Provider, table.Resource, err)
+		}
+		c.Logger.Infow("Deleted orphaned table", "provider", table.Provider, "online", table.Online, "resource", table.Resource)
+		deleted = append(deleted, table)
+	}
+	return deleted, nil
+}
+
+func (c *Coordinator) deleteOrphanedTable(ctx context.Context, table OrphanedTable) error {
+	entry, err := c.store().GetProvider(ctx, table.Provider)
+	if err != nil {
+		return fmt.Errorf("get provider: %w", err)
+	}
+	p, err := c.providers().Get(provider.Type(entry.Type()), entry.SerializedConfig())
+	if err != nil {
+		return fmt.Errorf("configure provider: %w", err)
+	}
+	if table.Online {
+		store, err := p.AsOnlineStore()
+		if err != nil {
+			return err
+		}
+		return store.DeleteTable(provider.MaterializedName(table.Resource), table.Resource.Variant)
+	}
+	store, err := p.AsOfflineStore()
+	if err != nil {
+		return err
+	}
+	return deleteOfflineTable(store, table.Resource)
+}
+
+// AuditOrphanedTablesEvery logs the orphaned tables in every provider each
+// interval until ctx is done.
+func (c *Coordinator) AuditOrphanedTablesEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		orphans, err := c.FindOrphanedTables(ctx)
+		if err != nil {
+			c.Logger.Errorw("Could not audit provider tables", "error", err)
+		}
+		for _, orphan := range orphans {
+			c.Logger.Warnw("Found orphaned table", "provider", orphan.Provider, "online", orphan.Online, "resource", orphan.Resource)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mvccpb "go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// MemberPrefix is the etcd prefix that coordinator replicas register under.
+const MemberPrefix = "COORDINATOR_MEMBER__"
+
+// Partitioner splits the JOB_ keyspace between coordinator replicas, so that
+// each job is only claimed by one of them and throughput grows with the
+// number of replicas. Each job is owned by the member that ranks highest for
+// it by rendezvous hashing, so when a replica joins or leaves only the jobs
+// it gains or loses move. Job locks still guard against two replicas running
+// a job while membership is changing.
+type Partitioner struct {
+	ID      string
+	mtx     sync.RWMutex
+	members []string
+	session *concurrency.Session
+	// leave stops the coordinator from keeping its membership.
+	leave context.CancelFunc
+}
+
+func NewPartitioner(id string) *Partitioner {
+	return &Partitioner{ID: id}
+}
+
+// Owns reports whether this member should claim the job with jobKey. A
+// partitioner that doesn't know of any members yet owns every job.
+func (p *Partitioner) Owns(jobKey string) bool {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	if len(p.members) == 0 {
+		return true
+	}
+	return jobOwner(p.members, jobKey) == p.ID
+}
+
+// Members returns the IDs of the replicas the keyspace is split between.
+func (p *Partitioner) Members() []string {
+	p.mtx.RLock()
+	defer p.mtx.RUnlock()
+	return append([]string{}, p.members...)
+}
+
+func (p *Partitioner) setMembers(members []string) {
+	sort.Strings(members)
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+	p.members = members
+}
+
+func jobOwner(members []string, jobKey string) string {
+	var owner string
+	var best uint64
+	for _, member := range members {
+		h := fnv.New64a()
+		h.Write([]byte(member))
+		h.Write([]byte{0})
+		h.Write([]byte(jobKey))
+		if weight := h.Sum64(); owner == "" || weight > best {
+			owner, best = member, weight
+		}
+	}
+	return owner
+}
+
+func (c *Coordinator) ownsJob(jobKey string) bool {
+	if c.Leadership != nil && !c.Leadership.IsLeader() {
+		return false
+	}
+	return c.Partition == nil || c.Partition.Owns(jobKey)
+}
+
+// partitionRetryInterval is how long a coordinator waits between attempts to
+// register in its partition, or to list its members, when etcd fails.
+var partitionRetryInterval = time.Second
+
+// JoinPartition registers the coordinator as a member of its partition and
+// keeps the member list up to date until ctx is done. The registration is
+// held by a lease with the coordinator's LockTTL, so a replica that dies
+// stops owning jobs once it expires, and jobs that change owner are claimed
+// by their new one. A replica whose lease expires while it's still running,
+// such as after losing etcd for longer than the TTL, registers again.
+func (c *Coordinator) JoinPartition(ctx context.Context) error {
+	p := c.Partition
+	ctx, cancel := context.WithCancel(ctx)
+	s, err := c.registerMember(ctx)
+	if err != nil {
+		cancel()
+		return err
+	}
+	members, revision, err := c.getMembers(ctx)
+	if err != nil {
+		cancel()
+		s.Close()
+		return err
+	}
+	p.mtx.Lock()
+	p.leave = cancel
+	p.mtx.Unlock()
+	c.updateMembers(members)
+	c.Logger.Infow("Joined coordinator partition", "member", p.ID, "members", p.Members())
+	go c.keepRegistered(ctx, s)
+	go c.watchMembers(ctx, members, revision+1)
+	return nil
+}
+
+// registerMember puts the coordinator's member key under the lease of a new
+// session.
+func (c *Coordinator) registerMember(ctx context.Context) (*concurrency.Session, error) {
+	p := c.Partition
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(c.lockTTL()))
+	if err != nil {
+		return nil, fmt.Errorf("new member session: %w", err)
+	}
+	if _, err := (*c.KVClient).Put(ctx, MemberPrefix+p.ID, p.ID, clientv3.WithLease(s.Lease())); err != nil {
+		s.Close()
+		return nil, fmt.Errorf("register member %s: %w", p.ID, err)
+	}
+	p.mtx.Lock()
+	p.session = s
+	p.mtx.Unlock()
+	return s, nil
+}
+
+// getMembers lists the registered members, along with the revision they
+// were listed at.
+func (c *Coordinator) getMembers(ctx context.Context) (map[string]bool, int64, error) {
+	resp, err := (*c.KVClient).Get(ctx, MemberPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, 0, fmt.Errorf("get members: %w", err)
+	}
+	members := make(map[string]bool)
+	for _, kv := range resp.Kvs {
+		members[strings.TrimPrefix(string(kv.Key), MemberPrefix)] = true
+	}
+	return members, resp.Header.Revision, nil
+}
+
+// keepRegistered registers the coordinator again each time its session ends
+// while ctx isn't done. Its member key is deleted along with the session's
+// lease, so the other members stop counting it until then.
+func (c *Coordinator) keepRegistered(ctx context.Context, s *concurrency.Session) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.Done():
+		}
+		c.Logger.Warnw("Coordinator partition membership expired, registering again", "member", c.Partition.ID)
+		for {
+			var err error
+			if s, err = c.registerMember(ctx); err == nil {
+				break
+			}
+			c.Logger.Errorw("Could not register in coordinator partition", "member", c.Partition.ID, "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(partitionRetryInterval):
+			}
+		}
+		c.Logger.Infow("Registered in coordinator partition again", "member", c.Partition.ID)
+	}
+}
+
+// watchMembers keeps the member list up to date from revision on until ctx
+// is done. When the watch fails, such as when etcd has compacted the
+// revision it was at, the members are listed again and watched from there.
+func (c *Coordinator) watchMembers(ctx context.Context, members map[string]bool, revision int64) {
+	for {
+		err := c.watchMemberChanges(ctx, members, revision)
+		if ctx.Err() != nil {
+			return
+		}
+		c.Logger.Warnw("Coordinator partition watch stopped, listing members again", "error", err)
+		for {
+			var listErr error
+			if members, revision, listErr = c.getMembers(ctx); listErr == nil {
+				break
+			}
+			c.Logger.Errorw("Could not list coordinator partition members", "error", listErr)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(partitionRetryInterval):
+			}
+		}
+		revision++
+		c.updateMembers(members)
+		c.Logger.Infow("Coordinator partition changed", "members", c.Partition.Members())
+		c.claimOwnedJobs(ctx)
+	}
+}
+
+// watchMemberChanges applies the changes to the members from revision on,
+// until the watch ends. It returns why the watch failed, if it did.
+func (c *Coordinator) watchMemberChanges(ctx context.Context, members map[string]bool, revision int64) error {
+	watch := c.EtcdClient.Watch(ctx, MemberPrefix, clientv3.WithPrefix(), clientv3.WithRev(revision))
+	for resp := range watch {
+		if err := resp.Err(); err != nil {
+			return err
+		}
+		for _, ev := range resp.Events {
+			id := strings.TrimPrefix(string(ev.Kv.Key), MemberPrefix)
+			if ev.Type == mvccpb.PUT {
+				members[id] = true
+			} else {
+				delete(members, id)
+			}
+		}
+		c.updateMembers(members)
+		c.Logger.Infow("Coordinator partition changed", "members", c.Partition.Members())
+		c.claimOwnedJobs(ctx)
+	}
+	return fmt.Errorf("member watch closed")
+}
+
+func (c *Coordinator) updateMembers(members map[string]bool) {
+	ids := make([]string, 0, len(members))
+	for id := range members {
+		ids = append(ids, id)
+	}
+	c.Partition.setMembers(ids)
+}
+
+// claimOwnedJobs runs the existing jobs that this coordinator owns, which
+// after a membership change or an election may include jobs another replica
+// owned before. Jobs that are already running here, or still running on their
+// previous owner, wait on their lock.
+func (c *Coordinator) claimOwnedJobs(ctx context.Context) {
+	_, err := c.listJobKeys(ctx, func(key string) {
+		if !c.ownsJob(key) {
+			return
+		}
+		if _, claiming := c.claiming.Load(key); claiming {
+			return
+		}
+		go func() {
+			if err := c.ExecuteJob(key); err != nil {
+				c.Logger.Errorw("Error executing job: Partition change", "error", err)
+			}
+		}()
+	})
+	if err != nil {
+		c.Logger.Errorw("Could not list jobs to claim", "error", err)
+	}
+}
+
+// leavePartition removes the coordinator from its partition so that the
+// other replicas take over its jobs without waiting for its lease to expire.
+func (c *Coordinator) leavePartition() {
+	if c.Partition == nil {
+		return
+	}
+	p := c.Partition
+	p.mtx.Lock()
+	leave, session := p.leave, p.session
+	p.mtx.Unlock()
+	if leave != nil {
+		// Stops the coordinator from registering again once its session ends.
+		leave()
+	}
+	if session == nil {
+		return
+	}
+	if err := session.Close(); err != nil {
+		c.Logger.Errorw("Could not leave coordinator partition", "error", err)
+	}
+}
@@ -0,0 +1,51 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// verifyPins checks that every upstream variant a training set was pinned to
+// is still the version it was pinned to. A pinned variant that's been deleted,
+// or deleted and registered again, fails the build permanently, since building
+// from other data would break reproducibility.
+func (c *Coordinator) verifyPins(ctx context.Context, ts *metadata.TrainingSetVariant) error {
+	if !ts.PinsDependencies() {
+		return nil
+	}
+	for _, pin := range ts.Pins() {
+		id := pin.Resource
+		if err := c.verifyPin(ctx, pin); err != nil {
+			return permanent(fmt.Errorf("pinned %s %s (%s): %w", id.Type, id.Name, id.Variant, err))
+		}
+	}
+	return nil
+}
+
+func (c *Coordinator) verifyPin(ctx context.Context, pin metadata.VariantPin) error {
+	nv := metadata.NameVariant{Name: pin.Resource.Name, Variant: pin.Resource.Variant}
+	var created interface{ Created() time.Time }
+	switch pin.Resource.Type {
+	case metadata.FEATURE_VARIANT:
+		feature, err := c.store().GetFeatureVariant(ctx, nv)
+		if err != nil {
+			return fmt.Errorf("was deleted: %w", err)
+		}
+		created = feature
+	case metadata.LABEL_VARIANT:
+		label, err := c.store().GetLabelVariant(ctx, nv)
+		if err != nil {
+			return fmt.Errorf("was deleted: %w", err)
+		}
+		created = label
+	default:
+		return fmt.Errorf("can't pin %s resources", pin.Resource.Type)
+	}
+	if !created.Created().Equal(pin.Created) {
+		return fmt.Errorf("was registered again at %s after being pinned at %s", created.Created(), pin.Created)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/runner"
+	"go.uber.org/zap"
+)
+
+// PlanJobs plans the jobs of registered resources. Resources that haven't
+// been registered yet are planned by the metadata server's PlanJobs, with
+// the planner NewJobPlanner returns.
+func (c *Coordinator) PlanJobs(ctx context.Context, ids []metadata.ResourceID) ([]metadata.JobPlan, error) {
+	plans := make([]metadata.JobPlan, len(ids))
+	for i, id := range ids {
+		plan, err := c.PlanJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		plans[i] = *plan
+	}
+	return plans, nil
+}
+
+// NewJobPlanner returns the planner the metadata server plans jobs with. It
+// plans them the same way PlanJob does, reading resources from what the
+// server gives it and opening providers with providers.
+func NewJobPlanner(providers ProviderFactory) metadata.JobPlanner {
+	return func(ctx context.Context, resources metadata.ResourceReader, ids []metadata.ResourceID) ([]metadata.JobPlan, error) {
+		c := &Coordinator{Logger: zap.NewNop().Sugar(), Resources: planStore{resources}, Providers: providers}
+		return c.PlanJobs(ctx, ids)
+	}
+}
+
+// planStore is the MetadataStore of a planner, which only reads resources.
+type planStore struct {
+	metadata.ResourceReader
+}
+
+var errPlanWrite = errors.New("planning doesn't change resources")
+
+func (planStore) SetStatus(context.Context, metadata.ResourceID, metadata.ResourceStatus, string) error {
+	return errPlanWrite
+}
+
+func (planStore) SetStatusWithCode(context.Context, metadata.ResourceID, metadata.ResourceStatus, metadata.ErrorCode, string) error {
+	return errPlanWrite
+}
+
+func (planStore) SetProgress(context.Context, metadata.ResourceID, metadata.JobProgress) error {
+	return errPlanWrite
+}
+
+func (planStore) SetStats(context.Context, metadata.ResourceID, metadata.TableStats) error {
+	return errPlanWrite
+}
+
+func (planStore) SetTrainingSetFreshness(context.Context, metadata.NameVariant, metadata.TrainingSetFreshness) error {
+	return errPlanWrite
+}
+
+func (planStore) UpdateFeatureVariantProvider(context.Context, metadata.NameVariant, string, string) error {
+	return errPlanWrite
+}
+
+func (planStore) ListProviders(context.Context) ([]*metadata.Provider, error) {
+	return nil, errors.New("planning doesn't list providers")
+}
+
+// PlanJob resolves a resource's dependencies, checks that its providers can
+// be configured and renders its transformation's query, the same way its
+// job would. It fails if the resource or what it's created from can't be
+// found, and records anything else that would fail the job as a problem of
+// the plan.
+func (c *Coordinator) PlanJob(ctx context.Context, id metadata.ResourceID) (*metadata.JobPlan, error) {
+	plan := &metadata.JobPlan{Resource: id}
+	nv := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	switch id.Type {
+	case metadata.SOURCE_VARIANT:
+		source, err := c.store().GetSourceVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get source variant: %w", err)
+		}
+		plan.Schedule = source.Schedule()
+		if source.IsSQLTransformation() {
+			plan.Runner = runner.CREATE_TRANSFORMATION
+			c.planQuery(ctx, plan, source)
+			if err := c.checkSourceCycle(ctx, id); err != nil {
+				plan.AddProblem("%s", err)
+			}
+		} else if !source.IsPrimaryDataSQLTable() {
+			plan.AddProblem("source type not implemented")
+		}
+	case metadata.FEATURE_VARIANT:
+		feature, err := c.store().GetFeatureVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get feature variant: %w", err)
+		}
+		plan.Runner = runner.MATERIALIZE
+		plan.Schedule = feature.Schedule()
+	case metadata.LABEL_VARIANT:
+		label, err := c.store().GetLabelVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get label variant: %w", err)
+		}
+		if label.ServedOnline() {
+			plan.Runner = runner.MATERIALIZE
+			plan.Providers = append(plan.Providers, label.OnlineProvider())
+		}
+	case metadata.TRAINING_SET_VARIANT:
+		ts, err := c.store().GetTrainingSetVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get training set variant: %w", err)
+		}
+		plan.Runner = runner.CREATE_TRAINING_SET
+		plan.Schedule = ts.Schedule()
+	default:
+		return nil, fmt.Errorf("%s resources don't have jobs", id.Type)
+	}
+	providers, _, err := c.jobProviders(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	plan.Providers = append(providers, plan.Providers...)
+	for _, name := range plan.Providers {
+		c.planProvider(ctx, plan, name)
+	}
+	if plan.Dependencies, err = c.jobDependencies(ctx, id); err != nil {
+		return nil, err
+	}
+	for _, dep := range plan.Dependencies {
+		status, err := c.resourceStatus(ctx, dep)
+		if err != nil {
+			plan.AddProblem("dependency %s %s (%s) can't be found: %s", dep.Type, dep.Name, dep.Variant, err)
+		} else if status == metadata.FAILED {
+			plan.AddProblem("dependency %s %s (%s) failed", dep.Type, dep.Name, dep.Variant)
+		}
+	}
+	if _, err := metadata.ParseSchedule(plan.Schedule); err != nil {
+		plan.AddProblem("%s", err)
+	}
+	return plan, nil
+}
+
+// planProvider checks that a provider is registered and that its config can
+// be opened, without running anything against it.
+func (c *Coordinator) planProvider(ctx context.Context, plan *metadata.JobPlan, name string) {
+	if name == "" {
+		return
+	}
+	entry, err := c.store().GetProvider(ctx, name)
+	if err != nil {
+		plan.AddProblem("provider %s can't be found: %s", name, err)
+		return
+	}
+	if _, err := c.providers().Get(provider.Type(entry.Type()), entry.SerializedConfig()); err != nil {
+		plan.AddProblem("provider %s can't be configured: %s", name, err)
+	}
+}
+
+// planQuery renders a transformation's query the way its job does. Unlike
+// the job, it doesn't wait for the sources to be ready, since their tables
+// are named after them either way. Run-time templates are left in the query,
+// since they differ between runs.
+func (c *Coordinator) planQuery(ctx context.Context, plan *metadata.JobPlan, transformSource *metadata.SourceVariant) {
+	sourceMap := make(map[string]string)
+	for _, nv := range transformSource.SQLTransformationSources() {
+		source, err := c.store().GetSourceVariant(ctx, nv)
+		if err != nil {
+			plan.AddProblem("source %s (%s) of query can't be found: %s", nv.Name, nv.Variant, err)
+			continue
+		}
+		tableName, err := sourceTableName(source)
+		if err != nil {
+			plan.AddProblem("source %s (%s) of query has no table: %s", nv.Name, nv.Variant, err)
+			continue
+		}
+		sourceMap[nv.ClientString()] = tableName
+	}
+	sourceProvider, err := c.store().GetProvider(ctx, transformSource.Provider())
+	if err != nil {
+		plan.AddProblem("provider of query can't be found: %s", err)
+		return
+	}
+	query, err := templateReplace(transformSource.SQLTransformationQuery(), sourceMap, transformSource.SQLTransformationParameters(), providerSyntax(sourceProvider))
+	if err != nil {
+		plan.AddProblem("render query: %s", err)
+		return
+	}
+	plan.Query = query
+}
@@ -0,0 +1,134 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metrics"
+	"github.com/featureform/provider"
+)
+
+// The probe reuses one resource per provider so that repeated runs don't
+// leave tables behind.
+const (
+	probeResourceName    = "featureform_probe"
+	probeResourceVariant = "probe"
+	probeEntity          = "probe"
+)
+
+// ProviderProbes returns a probe for each registered provider that runs a
+// tiny end to end materialization against it: a write and materialization on
+// offline stores and a write and read on online stores.
+func (c *Coordinator) ProviderProbes() ([]metrics.Probe, error) {
+	providers, err := c.store().ListProviders(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("list providers: %w", err)
+	}
+	probes := make([]metrics.Probe, len(providers))
+	for i, p := range providers {
+		t, config := provider.Type(p.Type()), provider.SerializedConfig(p.SerializedConfig())
+		probes[i] = metrics.Probe{
+			Name:   "provider_materialization",
+			Target: p.Name(),
+			Run: func(ctx context.Context) error {
+				return c.probeProvider(t, config)
+			},
+		}
+	}
+	return probes, nil
+}
+
+func (c *Coordinator) probeProvider(t provider.Type, config provider.SerializedConfig) error {
+	p, err := c.providers().Get(t, config)
+	if err != nil {
+		return fmt.Errorf("get provider: %w", err)
+	}
+	if c, ok := p.(interface{ Close() error }); ok {
+		defer c.Close()
+	}
+	value := time.Now().UTC().Unix()
+	if store, err := p.AsOfflineStore(); err == nil {
+		return probeOfflineStore(store, value)
+	}
+	if store, err := p.AsOnlineStore(); err == nil {
+		return probeOnlineStore(store, value)
+	}
+	return fmt.Errorf("%s provider is neither an offline nor an online store", t)
+}
+
+func probeOfflineStore(store provider.OfflineStore, value int64) (err error) {
+	id := provider.ResourceID{Name: probeResourceName, Variant: probeResourceVariant, Type: provider.Feature}
+	table, err := store.GetResourceTable(id)
+	if err != nil {
+		schema := provider.TableSchema{
+			Columns: []provider.TableColumn{
+				{Name: "entity", ValueType: provider.String},
+				{Name: "value", ValueType: provider.Int64},
+				{Name: "ts", ValueType: provider.Timestamp},
+			},
+		}
+		if table, err = store.CreateResourceTable(id, schema); err != nil {
+			return fmt.Errorf("create probe table: %w", err)
+		}
+	}
+	if err := table.Write(provider.ResourceRecord{Entity: probeEntity, Value: value, TS: time.Unix(value, 0).UTC()}); err != nil {
+		return fmt.Errorf("write probe record: %w", err)
+	}
+	// A probe that didn't finish leaves its materialization behind, which
+	// would stop this one from being created.
+	if err := deleteMaterializations(store, id); err != nil {
+		return fmt.Errorf("delete stale probe materialization: %w", err)
+	}
+	mat, err := store.CreateMaterialization(id)
+	var exists *provider.MaterializationExists
+	if errors.As(err, &exists) {
+		if err := store.DeleteMaterialization(exists.ID); err != nil {
+			return fmt.Errorf("delete stale probe materialization: %w", err)
+		}
+		mat, err = store.CreateMaterialization(id)
+	}
+	if err != nil {
+		return fmt.Errorf("create probe materialization: %w", err)
+	}
+	defer func() {
+		if deleteErr := store.DeleteMaterialization(mat.ID()); deleteErr != nil && err == nil {
+			err = fmt.Errorf("delete probe materialization: %w", deleteErr)
+		}
+	}()
+	iter, err := mat.IterateSegment(0, 1)
+	if err != nil {
+		return fmt.Errorf("iterate probe materialization: %w", err)
+	}
+	if !iter.Next() {
+		if err := iter.Err(); err != nil {
+			return fmt.Errorf("read probe materialization: %w", err)
+		}
+		return errors.New("probe materialization is empty")
+	}
+	if got := fmt.Sprint(iter.Value().Value); got != fmt.Sprint(value) {
+		return fmt.Errorf("probe materialization has value %s, expected %d", got, value)
+	}
+	return nil
+}
+
+func probeOnlineStore(store provider.OnlineStore, value int64) error {
+	table, err := store.GetTable(probeResourceName, probeResourceVariant)
+	if err != nil {
+		if table, err = store.CreateTable(probeResourceName, probeResourceVariant, provider.Int64); err != nil {
+			return fmt.Errorf("create probe table: %w", err)
+		}
+	}
+	if err := table.Set(probeEntity, value); err != nil {
+		return fmt.Errorf("set probe value: %w", err)
+	}
+	got, err := table.Get(probeEntity)
+	if err != nil {
+		return fmt.Errorf("get probe value: %w", err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(value) {
+		return fmt.Errorf("probe table has value %v, expected %d", got, value)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package coordinator
+
+import (
+	"context"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+)
+
+// DefaultProgressInterval is how often the progress of a job is written to
+// its resource's status, if ProgressInterval isn't set.
+const DefaultProgressInterval = 30 * time.Second
+
+func (c *Coordinator) progressInterval() time.Duration {
+	if c.ProgressInterval > 0 {
+		return c.ProgressInterval
+	}
+	return DefaultProgressInterval
+}
+
+// waitReportingProgress waits for a job that creates a resource, and writes
+// the job's progress to the resource's status until it completes. Jobs that
+// don't report their progress leave the status alone. Reporting stops before
+// it returns, so that a late report can't overwrite the status the job's
+// result is recorded with.
+func (c *Coordinator) waitReportingProgress(ctx context.Context, id metadata.ResourceID, watcher runner.CompletionWatcher) error {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(c.progressInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			progress := watcher.Progress()
+			if progress.IsZero() || watcher.Complete() {
+				continue
+			}
+			if err := c.store().SetProgress(context.Background(), id, jobProgress(progress)); err != nil {
+				c.Logger.Errorw("Failed to record job progress", "resource", id, "error", err)
+			}
+		}
+	}()
+	err := runner.WaitWithContext(ctx, watcher)
+	close(done)
+	<-stopped
+	return err
+}
+
+func jobProgress(progress runner.Progress) metadata.JobProgress {
+	return metadata.JobProgress{
+		RowsCopied:  progress.RowsCopied,
+		TotalRows:   progress.TotalRows,
+		ChunksDone:  progress.ChunksDone,
+		TotalChunks: progress.TotalChunks,
+		ETA:         progress.ETA,
+	}
+}
@@ -0,0 +1,96 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// JobPrefix is the etcd prefix that jobs are stored under.
+const JobPrefix = "JOB_"
+
+// EtcdQueue delivers jobs by watching for them to be written to etcd, so
+// publishing a job is a no-op.
+type EtcdQueue struct {
+	client *clientv3.Client
+}
+
+func NewEtcdQueue(client *clientv3.Client) *EtcdQueue {
+	return &EtcdQueue{client: client}
+}
+
+func (q *EtcdQueue) Publish(ctx context.Context, key string) error {
+	return nil
+}
+
+func (q *EtcdQueue) Subscribe(ctx context.Context, subscribed func(), handle func(key string)) error {
+	if q.client == nil {
+		return fmt.Errorf("etcd job queue has no etcd client")
+	}
+	// A watch channel is closed if its connection is lost, so it's reopened
+	// from where it left off until ctx is done. If that revision has been
+	// compacted, the job keys are listed again instead.
+	var revision int64
+	for ctx.Err() == nil {
+		opts := []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithCreatedNotify()}
+		if revision != 0 {
+			opts = append(opts, clientv3.WithRev(revision))
+		}
+		watchCtx, cancel := context.WithCancel(ctx)
+		for wresp := range q.client.Watch(watchCtx, JobPrefix, opts...) {
+			if wresp.CompactRevision != 0 {
+				// The watch is started again from the compacted revision
+				// if the keys couldn't be listed, so that they're listed
+				// again.
+				if next, err := q.relist(ctx, handle); err == nil {
+					revision = next
+				} else {
+					time.Sleep(time.Second)
+				}
+				break
+			}
+			if wresp.Err() != nil {
+				break
+			}
+			if wresp.Created {
+				// Later watches carry on from the first one, so only
+				// it is reported.
+				if revision == 0 {
+					revision = wresp.Header.Revision + 1
+				}
+				if subscribed != nil {
+					subscribed()
+					subscribed = nil
+				}
+				continue
+			}
+			for _, ev := range wresp.Events {
+				if ev.Type == clientv3.EventTypePut {
+					handle(string(ev.Kv.Key))
+				}
+				revision = ev.Kv.ModRevision + 1
+			}
+		}
+		cancel()
+	}
+	return nil
+}
+
+// relist handles every job key that's in etcd, and returns the revision to
+// watch from after them.
+func (q *EtcdQueue) relist(ctx context.Context, handle func(key string)) (int64, error) {
+	resp, err := q.client.Get(ctx, JobPrefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return 0, fmt.Errorf("list jobs after compaction: %w", err)
+	}
+	for _, kv := range resp.Kvs {
+		handle(string(kv.Key))
+	}
+	return resp.Header.Revision + 1, nil
+}
+
+func (q *EtcdQueue) Close() error {
+	return nil
+}
@@ -0,0 +1,166 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+const kafkaDialTimeout = 10 * time.Second
+
+// KafkaQueue publishes jobs to a Kafka topic on the brokers at
+// Config.Address, separated by commas. Subscribers read every partition of
+// the topic from the offsets it ended at when they subscribed, without a
+// consumer group, so that every coordinator gets every job and nothing is
+// left on the brokers once they stop.
+type KafkaQueue struct {
+	brokers []string
+	topic   string
+	dialer  *kafka.Dialer
+	writer  *kafka.Writer
+}
+
+func NewKafkaQueue(config Config) *KafkaQueue {
+	brokers := strings.Split(config.Address, ",")
+	dialer := &kafka.Dialer{Timeout: kafkaDialTimeout, DualStack: true}
+	transport := &kafka.Transport{DialTimeout: kafkaDialTimeout}
+	if config.Username != "" {
+		mechanism := plain.Mechanism{Username: config.Username, Password: config.Password}
+		dialer.SASLMechanism = mechanism
+		transport.SASL = mechanism
+	}
+	return &KafkaQueue{
+		brokers: brokers,
+		topic:   config.topic(),
+		dialer:  dialer,
+		writer: &kafka.Writer{
+			Addr:         kafka.TCP(brokers...),
+			Topic:        config.topic(),
+			Balancer:     &kafka.LeastBytes{},
+			RequiredAcks: kafka.RequireAll,
+			Transport:    transport,
+		},
+	}
+}
+
+func (q *KafkaQueue) Publish(ctx context.Context, key string) error {
+	if err := q.writer.WriteMessages(ctx, kafka.Message{Value: []byte(key)}); err != nil {
+		return fmt.Errorf("publish job to kafka: %w", err)
+	}
+	return nil
+}
+
+// endOffsets returns the offset that the next record published to each of
+// the topic's partitions will have.
+func (q *KafkaQueue) endOffsets(ctx context.Context) (map[int]int64, error) {
+	var lastErr error
+	for _, broker := range q.brokers {
+		conn, err := q.dialer.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		partitions, err := conn.ReadPartitions(q.topic)
+		conn.Close()
+		if err != nil {
+			return nil, fmt.Errorf("list partitions of %s: %w", q.topic, err)
+		}
+		offsets := make(map[int]int64, len(partitions))
+		for _, partition := range partitions {
+			leader, err := q.dialer.DialLeader(ctx, "tcp", broker, q.topic, partition.ID)
+			if err != nil {
+				return nil, fmt.Errorf("connect to leader of partition %d of %s: %w", partition.ID, q.topic, err)
+			}
+			offset, err := leader.ReadLastOffset()
+			leader.Close()
+			if err != nil {
+				return nil, fmt.Errorf("read end of partition %d of %s: %w", partition.ID, q.topic, err)
+			}
+			offsets[partition.ID] = offset
+		}
+		return offsets, nil
+	}
+	return nil, fmt.Errorf("could not connect to any kafka broker: %w", lastErr)
+}
+
+// Subscribe reads where each partition ends before calling subscribed, and
+// then reads every partition from there, so that no job published once
+// subscribed is called is missed.
+func (q *KafkaQueue) Subscribe(ctx context.Context, subscribed func(), handle func(key string)) error {
+	offsets, err := q.endOffsets(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	readers := make([]*kafka.Reader, 0, len(offsets))
+	defer func() {
+		for _, reader := range readers {
+			reader.Close()
+		}
+	}()
+	for partition, offset := range offsets {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:   q.brokers,
+			Topic:     q.topic,
+			Partition: partition,
+			Dialer:    q.dialer,
+		})
+		readers = append(readers, reader)
+		if err := reader.SetOffset(offset); err != nil {
+			return fmt.Errorf("seek partition %d of %s: %w", partition, q.topic, err)
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	keys := make(chan string)
+	errs := make(chan error, len(readers))
+	var wg sync.WaitGroup
+	// The readers are stopped before they're closed.
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+	for _, reader := range readers {
+		wg.Add(1)
+		go func(reader *kafka.Reader) {
+			defer wg.Done()
+			for {
+				msg, err := reader.ReadMessage(ctx)
+				if err != nil {
+					if ctx.Err() == nil {
+						errs <- fmt.Errorf("read kafka topic %s: %w", q.topic, err)
+					}
+					return
+				}
+				select {
+				case keys <- string(msg.Value):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(reader)
+	}
+	if subscribed != nil {
+		subscribed()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case key := <-keys:
+			handle(key)
+		}
+	}
+}
+
+func (q *KafkaQueue) Close() error {
+	return q.writer.Close()
+}
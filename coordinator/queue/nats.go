@@ -0,0 +1,106 @@
+package queue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const natsDialTimeout = 10 * time.Second
+
+// NATSQueue publishes jobs to a NATS subject. It uses core NATS rather than
+// JetStream, so every subscriber gets the jobs published while it's
+// connected.
+type NATSQueue struct {
+	address string
+	subject string
+	options []nats.Option
+	mtx     sync.Mutex
+	// pub is the connection jobs are published on, which is opened by the
+	// first Publish and reconnects on its own.
+	pub *nats.Conn
+}
+
+func NewNATSQueue(config Config) *NATSQueue {
+	options := []nats.Option{nats.Name("featureform-coordinator"), nats.Timeout(natsDialTimeout)}
+	if config.Username != "" {
+		options = append(options, nats.UserInfo(config.Username, config.Password))
+	}
+	return &NATSQueue{
+		address: config.Address,
+		subject: config.topic(),
+		options: options,
+	}
+}
+
+func (q *NATSQueue) connect(options ...nats.Option) (*nats.Conn, error) {
+	nc, err := nats.Connect(q.address, append(append([]nats.Option{}, q.options...), options...)...)
+	if err != nil {
+		return nil, fmt.Errorf("connect to nats: %w", err)
+	}
+	return nc, nil
+}
+
+func (q *NATSQueue) Publish(ctx context.Context, key string) error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if q.pub == nil {
+		nc, err := q.connect()
+		if err != nil {
+			return err
+		}
+		q.pub = nc
+	}
+	if err := q.pub.Publish(q.subject, []byte(key)); err != nil {
+		return fmt.Errorf("publish job to nats: %w", err)
+	}
+	// Publishes are buffered, so the job is only published once the server
+	// has answered a flush.
+	if err := q.pub.FlushTimeout(natsDialTimeout); err != nil {
+		return fmt.Errorf("publish job to nats: %w", err)
+	}
+	return nil
+}
+
+func (q *NATSQueue) Subscribe(ctx context.Context, subscribed func(), handle func(key string)) error {
+	// The subscriber doesn't reconnect, since the jobs published while it's
+	// disconnected would be lost. Returning instead has the coordinator
+	// subscribe again and list the jobs in etcd.
+	closed := make(chan struct{})
+	nc, err := q.connect(nats.NoReconnect(), nats.ClosedHandler(func(*nats.Conn) { close(closed) }))
+	if err != nil {
+		return err
+	}
+	defer nc.Close()
+	if _, err := nc.Subscribe(q.subject, func(msg *nats.Msg) { handle(string(msg.Data)) }); err != nil {
+		return fmt.Errorf("subscribe to nats subject %s: %w", q.subject, err)
+	}
+	// The subscription is only in place once the server has answered a
+	// flush.
+	if err := nc.FlushTimeout(natsDialTimeout); err != nil {
+		return fmt.Errorf("subscribe to nats subject %s: %w", q.subject, err)
+	}
+	if subscribed != nil {
+		subscribed()
+	}
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-closed:
+		return fmt.Errorf("lost nats connection: %v", nc.LastError())
+	}
+}
+
+func (q *NATSQueue) Close() error {
+	q.mtx.Lock()
+	defer q.mtx.Unlock()
+	if q.pub == nil {
+		return nil
+	}
+	q.pub.Close()
+	q.pub = nil
+	return nil
+}
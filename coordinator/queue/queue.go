@@ -0,0 +1,97 @@
+// Package queue has the backends that deliver new coordinator jobs to
+// coordinators. Jobs are stored in etcd, which stays the source of truth for
+// their state and locks, and a queue only carries the keys of jobs that are
+// ready to run. By default coordinators watch etcd for new jobs; large
+// deployments can move that traffic to Kafka, NATS or Redis Streams.
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Queue delivers the keys of new jobs. Every subscriber gets every key, as it
+// does with an etcd watch, and coordinators decide which of them runs a job
+// with its lock and the coordinator partition.
+type Queue interface {
+	// Publish announces that the job stored under key is ready to run. It's
+	// called once the job has been written to etcd.
+	Publish(ctx context.Context, key string) error
+	// Subscribe calls handle with the key of each job published once the
+	// subscription is in place, until ctx is done or the connection to the
+	// queue is lost. It calls subscribed, if it's set, as soon as the
+	// subscription is in place, so that jobs published while no
+	// coordinator was subscribed can then be found by a scan of etcd
+	// without missing any published during it.
+	Subscribe(ctx context.Context, subscribed func(), handle func(key string)) error
+	Close() error
+}
+
+type Backend string
+
+const (
+	Etcd  Backend = "etcd"
+	Kafka Backend = "kafka"
+	NATS  Backend = "nats"
+	Redis Backend = "redis"
+)
+
+// DefaultTopic is the topic, subject or stream jobs are published to, if
+// Config doesn't set one.
+const DefaultTopic = "featureform-jobs"
+
+type Config struct {
+	Backend Backend
+	// Address is the host and port of the Kafka brokers, separated by
+	// commas, or of the NATS or Redis server.
+	Address  string
+	Topic    string
+	Username string
+	Password string
+}
+
+// ConfigFromEnv reads the queue config that the coordinator and the metadata
+// server share from JOB_QUEUE, JOB_QUEUE_ADDRESS, JOB_QUEUE_TOPIC,
+// JOB_QUEUE_USERNAME and JOB_QUEUE_PASSWORD.
+func ConfigFromEnv() Config {
+	return Config{
+		Backend:  Backend(os.Getenv("JOB_QUEUE")),
+		Address:  os.Getenv("JOB_QUEUE_ADDRESS"),
+		Topic:    os.Getenv("JOB_QUEUE_TOPIC"),
+		Username: os.Getenv("JOB_QUEUE_USERNAME"),
+		Password: os.Getenv("JOB_QUEUE_PASSWORD"),
+	}
+}
+
+func (config Config) topic() string {
+	if config.Topic == "" {
+		return DefaultTopic
+	}
+	return config.Topic
+}
+
+// New returns the queue config describes. The etcd queue watches the etcd
+// cluster of client, which is only needed by subscribers.
+func New(config Config, client *clientv3.Client) (Queue, error) {
+	switch config.Backend {
+	case "", Etcd:
+		return NewEtcdQueue(client), nil
+	case Kafka, NATS, Redis:
+		if config.Address == "" {
+			return nil, fmt.Errorf("%s job queue needs an address", config.Backend)
+		}
+	default:
+		return nil, fmt.Errorf("unknown job queue backend: %s", config.Backend)
+	}
+	switch config.Backend {
+	case Kafka:
+		return NewKafkaQueue(config), nil
+	case NATS:
+		return NewNATSQueue(config), nil
+	default:
+		return NewRedisQueue(config), nil
+	}
+}
@@ -0,0 +1,240 @@
+package queue
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestNew(t *testing.T) {
+	type testCase struct {
+		Config  Config
+		Queue   Queue
+		IsError bool
+	}
+	tests := map[string]testCase{
+		"Default":   {Config: Config{}, Queue: &EtcdQueue{}},
+		"Etcd":      {Config: Config{Backend: Etcd}, Queue: &EtcdQueue{}},
+		"Kafka":     {Config: Config{Backend: Kafka, Address: "localhost:9092"}, Queue: &KafkaQueue{}},
+		"NATS":      {Config: Config{Backend: NATS, Address: "localhost:4222"}, Queue: &NATSQueue{}},
+		"Redis":     {Config: Config{Backend: Redis, Address: "localhost:6379"}, Queue: &RedisQueue{}},
+		"NoAddress": {Config: Config{Backend: Kafka}, IsError: true},
+		"Unknown":   {Config: Config{Backend: "sqs", Address: "localhost"}, IsError: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			q, err := New(test.Config, nil)
+			if test.IsError {
+				if err == nil {
+					t.Fatalf("Expected an error for %#v", test.Config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to create queue: %v", err)
+			}
+			if fmt.Sprintf("%T", q) != fmt.Sprintf("%T", test.Queue) {
+				t.Fatalf("Expected a %T, got a %T", test.Queue, q)
+			}
+			q.Close()
+		})
+	}
+}
+
+// testQueue checks that a key published once a subscriber says it's
+// subscribed is delivered to it.
+func testQueue(t *testing.T, q Queue) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	keys := make(chan string, 1)
+	subscribed := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- q.Subscribe(ctx, func() { close(subscribed) }, func(key string) { keys <- key })
+	}()
+	select {
+	case <-subscribed:
+	case err := <-done:
+		t.Fatalf("Subscriber didn't subscribe: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Subscriber didn't subscribe")
+	}
+	if err := q.Publish(ctx, "JOB__FEATURE_VARIANT__avg__v1"); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	select {
+	case key := <-keys:
+		if key != "JOB__FEATURE_VARIANT__avg__v1" {
+			t.Fatalf("Got wrong key: %s", key)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Published key wasn't delivered")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Failed to close queue: %v", err)
+	}
+}
+
+func TestKafkaQueue(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	port := os.Getenv("KAFKA_PORT")
+	if port == "" {
+		return
+	}
+	address := fmt.Sprintf("localhost:%s", port)
+	topic := fmt.Sprintf("test-jobs-%d", time.Now().UnixNano())
+	conn, err := kafka.Dial("tcp", address)
+	if err != nil {
+		t.Fatalf("Failed to connect to kafka: %v", err)
+	}
+	err = conn.CreateTopics(kafka.TopicConfig{Topic: topic, NumPartitions: 2, ReplicationFactor: 1})
+	conn.Close()
+	if err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+	q := NewKafkaQueue(Config{Backend: Kafka, Address: address, Topic: topic})
+	// A job published before the subscriber subscribes isn't delivered.
+	if err := q.Publish(context.Background(), "JOB__FEATURE_VARIANT__old__v1"); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+	testQueue(t, q)
+}
+
+func TestKafkaQueuePublishError(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	address := lis.Addr().String()
+	lis.Close()
+	q := NewKafkaQueue(Config{Backend: Kafka, Address: address})
+	defer q.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := q.Publish(ctx, "JOB__key"); err == nil {
+		t.Fatalf("Expected publishing without a broker to fail")
+	}
+}
+
+// natsServer is a stand-in for a NATS server that supports PUB and SUB.
+type natsServer struct {
+	mtx      sync.Mutex
+	listener net.Listener
+	subs     map[net.Conn]string
+}
+
+func newNATSServer(t *testing.T) *natsServer {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	s := &natsServer{listener: lis, subs: make(map[net.Conn]string)}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *natsServer) serve(conn net.Conn) {
+	defer conn.Close()
+	defer func() {
+		s.mtx.Lock()
+		delete(s.subs, conn)
+		s.mtx.Unlock()
+	}()
+	fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+	r := bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "PING":
+			fmt.Fprint(conn, "PONG\r\n")
+		case "SUB":
+			s.mtx.Lock()
+			s.subs[conn] = fields[2]
+			s.mtx.Unlock()
+		case "PUB":
+			size, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, size+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			s.mtx.Lock()
+			for sub, sid := range s.subs {
+				fmt.Fprintf(sub, "MSG %s %s %d\r\n%s", fields[1], sid, size, payload)
+			}
+			s.mtx.Unlock()
+		}
+	}
+}
+
+func TestNATSQueue(t *testing.T) {
+	server := newNATSServer(t)
+	defer server.listener.Close()
+	q := NewNATSQueue(Config{Backend: NATS, Address: server.listener.Addr().String()})
+	testQueue(t, q)
+}
+
+func TestNATSQueueRefused(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer lis.Close()
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		fmt.Fprint(conn, "INFO {}\r\n")
+		bufio.NewReader(conn).ReadString('\n')
+		fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+	}()
+	q := NewNATSQueue(Config{Backend: NATS, Address: lis.Addr().String(), Username: "user", Password: "wrong"})
+	if err := q.Publish(context.Background(), "JOB__key"); err == nil {
+		t.Fatalf("Expected a refused connection to fail")
+	}
+}
+
+func TestRedisQueue(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+	port := os.Getenv("REDIS_PORT")
+	if port == "" {
+		return
+	}
+	q := NewRedisQueue(Config{Backend: Redis, Address: fmt.Sprintf("localhost:%s", port), Topic: fmt.Sprintf("test-jobs-%d", time.Now().UnixNano())})
+	q.Block = 100 * time.Millisecond
+	testQueue(t, q)
+}
@@ -0,0 +1,95 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisStreamLength bounds the length of the job stream. Old entries are
+// only needed by subscribers that have fallen that far behind.
+const redisStreamLength = 10000
+
+const redisJobField = "key"
+
+// RedisQueue publishes jobs to a Redis stream. Subscribers read the stream
+// from where they subscribed rather than in a consumer group, so that every
+// coordinator gets every job.
+type RedisQueue struct {
+	client *redis.Client
+	stream string
+	// Block is how long each read waits for new jobs.
+	Block time.Duration
+}
+
+func NewRedisQueue(config Config) *RedisQueue {
+	return &RedisQueue{
+		client: redis.NewClient(&redis.Options{
+			Addr:     config.Address,
+			Username: config.Username,
+			Password: config.Password,
+		}),
+		stream: config.topic(),
+		Block:  5 * time.Second,
+	}
+}
+
+func (q *RedisQueue) Publish(ctx context.Context, key string) error {
+	err := q.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: q.stream,
+		MaxLen: redisStreamLength,
+		Approx: true,
+		Values: map[string]interface{}{redisJobField: key},
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("publish job to redis: %w", err)
+	}
+	return nil
+}
+
+func (q *RedisQueue) Subscribe(ctx context.Context, subscribed func(), handle func(key string)) error {
+	// Reading starts after the stream's last entry, rather than from $, so
+	// that jobs published between reads aren't missed.
+	last := "0-0"
+	entries, err := q.client.XRevRangeN(ctx, q.stream, "+", "-", 1).Result()
+	if err != nil {
+		return fmt.Errorf("read end of redis stream %s: %w", q.stream, err)
+	}
+	if len(entries) > 0 {
+		last = entries[0].ID
+	}
+	if subscribed != nil {
+		subscribed()
+	}
+	for ctx.Err() == nil {
+		streams, err := q.client.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{q.stream, last},
+			Block:   q.Block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("read redis stream %s: %w", q.stream, err)
+		}
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				last = msg.ID
+				if key, ok := msg.Values[redisJobField].(string); ok {
+					handle(key)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (q *RedisQueue) Close() error {
+	return q.client.Close()
+}
@@ -0,0 +1,172 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/runner"
+)
+
+// RefreshJobType is the job type that updates a feature or rebuilds a
+// training set because what it's made from was updated. Refresh jobs are
+// queued for resources that refresh on upstream changes each time one of
+// their upstream resources finishes an update run.
+const RefreshJobType = "REFRESH"
+
+// refreshDownstream queues refresh jobs for the resources made from one that
+// was just updated, if they refresh on upstream changes. Refreshes are queued
+// under the same key each time, so a resource whose refresh hasn't run yet
+// isn't refreshed twice.
+func (c *Coordinator) refreshDownstream(ctx context.Context, id metadata.ResourceID) error {
+	targets, err := c.refreshTargets(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, target := range targets {
+		if err := c.queueJob(ctx, RefreshJobType, target, nil, metadata.TriggerUpstream); err != nil {
+			return fmt.Errorf("queue refresh of %s %s (%s): %w", target.Type, target.Name, target.Variant, err)
+		}
+		c.Logger.Infow("Queued refresh on upstream change", "resource", target, "upstream", id)
+	}
+	return nil
+}
+
+// refreshTargets returns the resources to refresh after one was updated. A
+// source's features and the training sets of its labels are refreshed, as
+// are the training sets of a feature. Since a refreshed feature records an
+// update of its own, its training sets are refreshed after it. Resources that
+// aren't ready yet are skipped, since the jobs creating them will read the new
+// data anyway.
+func (c *Coordinator) refreshTargets(ctx context.Context, id metadata.ResourceID) ([]metadata.ResourceID, error) {
+	var features, trainingSets metadata.NameVariants
+	nv := metadata.NameVariant{Name: id.Name, Variant: id.Variant}
+	switch id.Type {
+	case metadata.SOURCE_VARIANT:
+		source, err := c.store().GetSourceVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get source variant: %w", err)
+		}
+		features = source.Features()
+		for _, labelID := range source.Labels() {
+			label, err := c.store().GetLabelVariant(ctx, labelID)
+			if err != nil {
+				return nil, fmt.Errorf("get label of source: %w", err)
+			}
+			trainingSets = append(trainingSets, label.TrainingSets()...)
+		}
+	case metadata.FEATURE_VARIANT:
+		feature, err := c.store().GetFeatureVariant(ctx, nv)
+		if err != nil {
+			return nil, fmt.Errorf("get feature variant: %w", err)
+		}
+		trainingSets = feature.TrainingSets()
+	default:
+		return nil, nil
+	}
+	var targets []metadata.ResourceID
+	for _, featureID := range features {
+		feature, err := c.store().GetFeatureVariant(ctx, featureID)
+		if err != nil {
+			return nil, fmt.Errorf("get feature of source: %w", err)
+		}
+		if feature.RefreshesOnUpstreamChange() && feature.Status() == metadata.READY {
+			targets = append(targets, metadata.ResourceID{Name: featureID.Name, Variant: featureID.Variant, Type: metadata.FEATURE_VARIANT})
+		}
+	}
+	// A training set can share a source's labels, so it's refreshed once.
+	seen := make(map[metadata.NameVariant]bool)
+	for _, tsID := range trainingSets {
+		if seen[tsID] {
+			continue
+		}
+		seen[tsID] = true
+		ts, err := c.store().GetTrainingSetVariant(ctx, tsID)
+		if err != nil {
+			return nil, fmt.Errorf("get training set variant: %w", err)
+		}
+		if ts.RefreshesOnUpstreamChange() && ts.Status() == metadata.READY {
+			targets = append(targets, metadata.ResourceID{Name: tsID.Name, Variant: tsID.Variant, Type: metadata.TRAINING_SET_VARIANT})
+		}
+	}
+	return targets, nil
+}
+
+// runUpdateJob runs a resource's update runner once, the way a scheduled
+// update would, and records the update so that what's downstream of it is
+// refreshed in turn. It runs both refreshes and the manual runs that
+// TriggerRun queues.
+func runUpdateJob(ctx context.Context, c *Coordinator, job Job) error {
+	id := job.Resource
+	var jobName string
+	var serialized runner.Config
+	var err error
+	switch id.Type {
+	case metadata.FEATURE_VARIANT:
+		jobName = runner.MATERIALIZE
+		serialized, err = c.featureUpdateConfig(ctx, id, nil)
+	case metadata.TRAINING_SET_VARIANT:
+		jobName = runner.CREATE_TRAINING_SET
+		serialized, err = c.trainingSetUpdateConfig(ctx, id)
+	case metadata.SOURCE_VARIANT:
+		jobName = runner.CREATE_TRANSFORMATION
+		serialized, err = c.transformationUpdateConfig(ctx, id, nil)
+	default:
+		return permanent(fmt.Errorf("%s resources can't be updated", id.Type))
+	}
+	if err != nil {
+		return err
+	}
+	jobRunner, err := c.Spawner.GetJobRunner(jobName, serialized, c.etcdEndpoints(), id)
+	if err != nil {
+		return fmt.Errorf("create update runner: %w", err)
+	}
+	watcher, err := runner.RunWithContext(c.runContext(ctx, id), jobRunner)
+	if err != nil {
+		return fmt.Errorf("run update: %w", err)
+	}
+	if err := runner.WaitWithContext(ctx, watcher); err != nil {
+		return fmt.Errorf("wait for update: %w", err)
+	}
+	if id.Type == metadata.TRAINING_SET_VARIANT {
+		c.recordTrainingSetFreshness(id)
+	}
+	c.compactUpdatedFeature(ctx, id)
+	return c.logUpdateEvent(ctx, id)
+}
+
+// trainingSetUpdateConfig is the config of a run that rebuilds a training set
+// from its features' and label's current data.
+func (c *Coordinator) trainingSetUpdateConfig(ctx context.Context, id metadata.ResourceID) (runner.Config, error) {
+	ts, err := c.store().GetTrainingSetVariant(ctx, metadata.NameVariant{Name: id.Name, Variant: id.Variant})
+	if err != nil {
+		return nil, fmt.Errorf("get training set variant: %w", err)
+	}
+	providerEntry, err := c.store().GetProvider(ctx, ts.Provider())
+	if err != nil {
+		return nil, fmt.Errorf("fetch training set variant offline provider: %w", err)
+	}
+	offlineConfig, err := c.runnerProviderConfig(providerEntry)
+	if err != nil {
+		return nil, err
+	}
+	features := ts.Features()
+	featureList := make([]provider.ResourceID, len(features))
+	for i, feature := range features {
+		featureList[i] = provider.ResourceID{Name: feature.Name, Variant: feature.Variant, Type: provider.Feature}
+	}
+	label := ts.Label()
+	config := runner.TrainingSetRunnerConfig{
+		OfflineType:   provider.Type(providerEntry.Type()),
+		OfflineConfig: offlineConfig,
+		Def: provider.TrainingSetDef{
+			ID:       provider.ResourceID{Name: id.Name, Variant: id.Variant, Type: provider.TrainingSet},
+			Label:    provider.ResourceID{Name: label.Name, Variant: label.Variant, Type: provider.Label},
+			Features: featureList,
+		},
+		IsUpdate: true,
+		Schedule: ts.Schedule(),
+	}
+	return config.Serialize()
+}
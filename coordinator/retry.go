@@ -0,0 +1,114 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	re "github.com/avast/retry-go/v4"
+	"github.com/featureform/metadata"
+)
+
+// RetryPolicy controls how many times a failing job is run, and how long the
+// coordinator waits between runs, before its resource is marked FAILED.
+type RetryPolicy struct {
+	MaxAttempts uint
+	// The wait doubles after each failed attempt, from InitialBackoff up to
+	// MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 5 * time.Second,
+	MaxBackoff:     5 * time.Minute,
+}
+
+// backoff returns the wait after attempt n, counting from zero.
+func (p RetryPolicy) backoff(n uint) time.Duration {
+	wait := p.InitialBackoff
+	for i := uint(0); i < n && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+	return wait
+}
+
+func (p RetryPolicy) attempts() uint {
+	// retry-go treats zero attempts as retrying forever.
+	if p.MaxAttempts == 0 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// permanent marks a job error that retrying won't fix, such as a resource
+// that's already been created.
+func permanent(err error) error {
+	return re.Unrecoverable(err)
+}
+
+// runWithRetries runs a job until it succeeds, returns a permanent error, or
+// runs out of attempts. The resource stays PENDING between attempts, with the
+// last error and attempt count as its status message, and is marked FAILED
+// once it gives up. If ctx is cancelled the job isn't retried, and the
+// resource is marked CANCELLED instead, unless the job was interrupted by its
+// coordinator, in which case its status is left for the coordinator that
+// takes it over. The failed attempts are returned with the error.
+func (c *Coordinator) runWithRetries(ctx context.Context, id metadata.ResourceID, trigger metadata.JobTrigger, job func() error) ([]metadata.JobAttempt, error) {
+	policy := c.Retry
+	attempts := policy.attempts()
+	var made uint
+	var history []metadata.JobAttempt
+	err := re.Do(
+		func() error {
+			made++
+			err := c.recordedRun(ctx, id, trigger, made, job)
+			if err != nil {
+				history = append(history, metadata.JobAttempt{Attempt: made, Error: err.Error(), Failed: time.Now().UTC()})
+			}
+			return err
+		},
+		re.Context(ctx),
+		re.RetryIf(func(err error) bool {
+			return ctx.Err() == nil && re.IsRecoverable(err)
+		}),
+		re.Attempts(attempts),
+		re.LastErrorOnly(true),
+		re.DelayType(func(n uint, _ error, _ *re.Config) time.Duration {
+			return policy.backoff(n)
+		}),
+		re.OnRetry(func(n uint, err error) {
+			if n+1 >= attempts {
+				return
+			}
+			wait := policy.backoff(n)
+			c.Logger.Warnw("Job failed, retrying", "resource", id, "attempt", n+1, "max_attempts", attempts, "backoff", wait, "error", err)
+			msg := fmt.Sprintf("attempt %d of %d failed, retrying in %s: %v", n+1, attempts, wait, err)
+			if statusErr := c.store().SetStatusWithCode(context.Background(), id, metadata.PENDING, metadata.ErrorCodeOf(err), msg); statusErr != nil {
+				c.Logger.Errorw("Could not record failed attempt", "resource", id, "error", statusErr)
+			}
+		}),
+	)
+	if err == nil {
+		return history, nil
+	}
+	if interrupted(ctx) {
+		return history, errJobInterrupted
+	}
+	if ctx.Err() != nil {
+		msg := fmt.Sprintf("cancelled on attempt %d of %d", made, attempts)
+		if statusErr := c.store().SetStatus(context.Background(), id, metadata.CANCELLED, msg); statusErr != nil {
+			return history, fmt.Errorf("%s: %v", msg, statusErr)
+		}
+		return history, errJobCancelled
+	}
+	msg := fmt.Sprintf("failed after %d of %d attempts: %v", made, attempts, err)
+	if statusErr := c.store().SetStatusWithCode(context.Background(), id, metadata.FAILED, metadata.ErrorCodeOf(err), msg); statusErr != nil {
+		return history, fmt.Errorf("%s: %v", msg, statusErr)
+	}
+	return history, fmt.Errorf("failed after %d attempts: %w", made, err)
+}
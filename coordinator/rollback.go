@@ -0,0 +1,49 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/featureform/metadata"
+)
+
+// JobRollback undoes what a failed job left behind, so that the job can be
+// run again from a clean state. jobErr is why the job failed.
+type JobRollback func(ctx context.Context, c *Coordinator, job Job, jobErr error) error
+
+// rollbackResource deletes the tables, materializations and jobs that a
+// failed attempt at creating a resource left in its providers, like an
+// offline table whose online copy failed, and resets the resource's status
+// so that its next attempt or a replay doesn't find it half made.
+func rollbackResource(ctx context.Context, c *Coordinator, job Job, jobErr error) error {
+	if err := c.DeleteResourceData(job.Resource); err != nil {
+		return fmt.Errorf("delete partially created data: %w", err)
+	}
+	msg := fmt.Sprintf("rolled back after failure: %v", jobErr)
+	if err := c.store().SetStatus(ctx, job.Resource, metadata.PENDING, msg); err != nil {
+		return fmt.Errorf("reset status: %w", err)
+	}
+	return nil
+}
+
+// runWithRollback runs an attempt at a job and rolls it back if it fails.
+// Jobs of resources that were already READY aren't rolled back, since the
+// data is from an earlier run, and neither are jobs that were cancelled or
+// interrupted, since their runners may still be using it. A rollback that
+// fails is logged, and the job's error is returned either way.
+func (c *Coordinator) runWithRollback(ctx context.Context, jobType JobType, job Job, run func() error) error {
+	if jobType.Rollback == nil {
+		return run()
+	}
+	before, statusErr := c.resourceStatus(ctx, job.Resource)
+	err := run()
+	if err == nil || statusErr != nil || before == metadata.READY || ctx.Err() != nil {
+		return err
+	}
+	if rbErr := jobType.Rollback(context.Background(), c, job, err); rbErr != nil {
+		c.Logger.Errorw("Could not roll back failed job", "resource", job.Resource, "error", rbErr)
+		return err
+	}
+	c.Logger.Infow("Rolled back failed job", "resource", job.Resource, "error", err)
+	return err
+}
@@ -0,0 +1,351 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/runner"
+	"github.com/google/uuid"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// SchedulePrefix is the etcd prefix that jobs run by the coordinator's own
+// scheduler are stored under.
+const SchedulePrefix = "INTERNAL_SCHEDULE__"
+
+// SchedulerElection is the etcd prefix that coordinators campaign under to
+// run the scheduler. Only the leader runs scheduled jobs, and another
+// coordinator takes over once the leader's lease expires.
+const SchedulerElection = "SCHEDULER_LEADER"
+
+// ScheduledRunJobType is the kind of the jobs that the scheduler queues for
+// the runs of scheduled jobs.
+const ScheduledRunJobType = "SCHEDULED_RUN"
+
+var scheduledRunSchema = ConfigSchema{
+	"Name":   {Type: StringConfig, Required: true},
+	"Config": {Type: StringConfig, Required: true},
+}
+
+// DefaultSchedulerInterval is how often the scheduler checks for due jobs,
+// if SchedulerInterval isn't set.
+const DefaultSchedulerInterval = 15 * time.Second
+
+// ScheduledJob is a job that the coordinator's scheduler runs on a schedule,
+// for job runners that can't schedule themselves the way Kubernetes cron jobs
+// do, such as the ones MemoryJobSpawner creates. Like a cron job, each run
+// gets a new runner made from the job's name and config.
+type ScheduledJob struct {
+	Name     string
+	Config   runner.Config
+	Resource metadata.ResourceID
+	Schedule string
+	Paused   bool
+	// Next is when the job is next due to run.
+	Next    time.Time
+	LastRun time.Time
+}
+
+func (job *ScheduledJob) Serialize() ([]byte, error) {
+	return json.Marshal(job)
+}
+
+func (job *ScheduledJob) Deserialize(serialized []byte) error {
+	if err := json.Unmarshal(serialized, job); err != nil {
+		return fmt.Errorf("deserialize scheduled job: %w", err)
+	}
+	return nil
+}
+
+func scheduleKey(id metadata.ResourceID) string {
+	return fmt.Sprintf("%s%s__%s__%s", SchedulePrefix, id.Type, id.Name, id.Variant)
+}
+
+// schedulerRunner schedules a runner that isn't a runner.CronRunner with the
+// coordinator's scheduler.
+type schedulerRunner struct {
+	runner.Runner
+	c   *Coordinator
+	job ScheduledJob
+}
+
+func (r *schedulerRunner) ScheduleJob(schedule runner.CronSchedule) error {
+	job := r.job
+	job.Schedule = string(schedule)
+	return r.c.addScheduledJob(context.Background(), job)
+}
+
+// cronRunner returns the runner.CronRunner that schedules a job runner's
+// update runs.
+func (c *Coordinator) cronRunner(jobRunner runner.Runner, jobName string, config runner.Config, id metadata.ResourceID) runner.CronRunner {
+	if cronRunner, isCronRunner := jobRunner.(runner.CronRunner); isCronRunner {
+		return cronRunner
+	}
+	return &schedulerRunner{
+		Runner: jobRunner,
+		c:      c,
+		job:    ScheduledJob{Name: jobName, Config: config, Resource: id},
+	}
+}
+
+func (c *Coordinator) addScheduledJob(ctx context.Context, job ScheduledJob) error {
+	schedule, err := metadata.ParseSchedule(job.Schedule)
+	if err != nil {
+		return err
+	}
+	if schedule.IsZero() {
+		return fmt.Errorf("no schedule for %s %s (%s)", job.Resource.Type, job.Resource.Name, job.Resource.Variant)
+	}
+	pause, err := metadata.GetSchedulePause(ctx, *c.KVClient, job.Resource)
+	if err != nil {
+		return fmt.Errorf("check schedule pause: %w", err)
+	}
+	job.Paused = pause != nil
+	job.Next = schedule.Next(time.Now())
+	serialized, err := job.Serialize()
+	if err != nil {
+		return err
+	}
+	if _, err := (*c.KVClient).Put(ctx, scheduleKey(job.Resource), string(serialized)); err != nil {
+		return fmt.Errorf("save scheduled job: %w", err)
+	}
+	c.Logger.Infow("Scheduled job", "resource", job.Resource, "schedule", job.Schedule, "next", job.Next)
+	return nil
+}
+
+// rescheduleJob applies a schedule change to a job run by the scheduler. It
+// returns false if the resource's updates aren't run by the scheduler.
+func (c *Coordinator) rescheduleJob(ctx context.Context, change *metadata.CoordinatorScheduleJob) (bool, error) {
+	resp, err := (*c.KVClient).Get(ctx, scheduleKey(change.Resource))
+	if err != nil {
+		return false, fmt.Errorf("get scheduled job: %w", err)
+	}
+	if len(resp.Kvs) == 0 {
+		return false, nil
+	}
+	job := &ScheduledJob{}
+	if err := job.Deserialize(resp.Kvs[0].Value); err != nil {
+		return false, err
+	}
+	schedule, err := metadata.ParseSchedule(change.Schedule)
+	if err != nil {
+		return false, err
+	}
+	job.Schedule = schedule.Cron()
+	job.Paused = change.Paused
+	job.Next = schedule.Next(time.Now())
+	serialized, err := job.Serialize()
+	if err != nil {
+		return false, err
+	}
+	if _, err := (*c.KVClient).Put(ctx, scheduleKey(change.Resource), string(serialized)); err != nil {
+		return false, fmt.Errorf("save scheduled job: %w", err)
+	}
+	return true, nil
+}
+
+func (c *Coordinator) schedulerInterval() time.Duration {
+	if c.SchedulerInterval > 0 {
+		return c.SchedulerInterval
+	}
+	return DefaultSchedulerInterval
+}
+
+// RunScheduler runs scheduled jobs while this coordinator is the scheduler's
+// leader, until the coordinator shuts down. Coordinators that aren't the
+// leader wait to take over.
+func (c *Coordinator) RunScheduler() error {
+	claims := c.shutdown.claims
+	for claims.Err() == nil {
+		if err := c.leadScheduler(claims); err != nil && claims.Err() == nil {
+			c.Logger.Errorw("Stopped leading the scheduler", "error", err)
+			time.Sleep(time.Second)
+		}
+	}
+	return nil
+}
+
+func (c *Coordinator) leadScheduler(ctx context.Context) error {
+	// The leader holds the election through its session's lease, so if it
+	// stops renewing it another coordinator is elected after the TTL.
+	s, err := concurrency.NewSession(c.EtcdClient, concurrency.WithTTL(c.lockTTL()))
+	if err != nil {
+		return fmt.Errorf("new session: %w", err)
+	}
+	defer s.Close()
+	election := concurrency.NewElection(s, SchedulerElection)
+	candidate, err := os.Hostname()
+	if err != nil {
+		candidate = uuid.New().String()
+	}
+	if err := election.Campaign(ctx, candidate); err != nil {
+		return fmt.Errorf("campaign for scheduler: %w", err)
+	}
+	defer func() {
+		if err := election.Resign(context.Background()); err != nil {
+			c.Logger.Debugw("Error resigning scheduler", "error", err)
+		}
+	}()
+	c.Logger.Infow("Leading the scheduler", "candidate", candidate)
+	ticker := time.NewTicker(c.schedulerInterval())
+	defer ticker.Stop()
+	for {
+		if err := c.runDueJobs(ctx, time.Now()); err != nil {
+			c.Logger.Errorw("Error running scheduled jobs", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.Done():
+			return fmt.Errorf("scheduler session expired")
+		case <-ticker.C:
+		}
+	}
+}
+
+// runDueJobs queues a run of each scheduled job that's due at now. The runs
+// are coordinator jobs, so that they're claimed, locked, retried and timed
+// out like any other.
+func (c *Coordinator) runDueJobs(ctx context.Context, now time.Time) error {
+	resp, err := (*c.KVClient).Get(ctx, SchedulePrefix, clientv3.WithPrefix())
+	if err != nil {
+		return fmt.Errorf("get scheduled jobs: %w", err)
+	}
+	for _, kv := range resp.Kvs {
+		job := &ScheduledJob{}
+		if err := job.Deserialize(kv.Value); err != nil {
+			c.Logger.Errorw("Could not deserialize scheduled job", "key", string(kv.Key), "error", err)
+			continue
+		}
+		if job.Paused || job.Next.After(now) {
+			continue
+		}
+		// A job that's still queued or running from its last run isn't
+		// queued again, and its next run isn't moved on until it has finished.
+		running, err := (*c.KVClient).Get(ctx, jobTypeKey(ScheduledRunJobType, job.Resource), clientv3.WithCountOnly())
+		if err != nil {
+			return fmt.Errorf("get scheduled run: %w", err)
+		}
+		if running.Count > 0 {
+			continue
+		}
+		schedule, err := metadata.ParseSchedule(job.Schedule)
+		if err != nil {
+			c.Logger.Errorw("Invalid schedule", "resource", job.Resource, "error", err)
+			continue
+		}
+		// If runs were missed, such as while no coordinator led the scheduler,
+		// the resource's catch-up policy decides what to do about them.
+		due := missedRuns(schedule, job.Next, now)
+		policy := c.CatchUp.For(job.Resource)
+		plan := policy.plan(due)
+		if len(due) > 1 {
+			c.Logger.Infow("Scheduled job missed runs", "resource", job.Resource, "missed", len(due)-1, "catch_up", policy.Mode)
+			c.notify(metadata.NotifyScheduleMissed, job.Resource, fmt.Sprintf("missed %d scheduled runs", len(due)-1))
+		}
+		if plan.run {
+			job.LastRun = now
+		}
+		job.Next = schedule.Next(now)
+		serialized, err := job.Serialize()
+		if err != nil {
+			return err
+		}
+		// The job's next run is saved before it's started, unless its schedule
+		// was changed since it was read.
+		txn, err := (*c.KVClient).Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(string(kv.Key)), "=", kv.ModRevision)).
+			Then(clientv3.OpPut(string(kv.Key), string(serialized))).
+			Commit()
+		if err != nil {
+			return fmt.Errorf("save next run: %w", err)
+		}
+		if !txn.Succeeded {
+			continue
+		}
+		if !plan.backfillFrom.IsZero() {
+			// The backfill's last window ends now, so it stands in for this
+			// run too.
+			_, err := c.Backfill(job.Resource, plan.backfillFrom, now)
+			if err == nil {
+				continue
+			}
+			c.Logger.Warnw("Could not backfill missed runs, running once instead", "resource", job.Resource, "error", err)
+		} else if !plan.run {
+			continue
+		}
+		config, err := json.Marshal(scheduledRun{Name: job.Name, Config: job.Config})
+		if err != nil {
+			return fmt.Errorf("serialize scheduled run: %w", err)
+		}
+		queued, err := c.queueJobOnce(ctx, ScheduledRunJobType, job.Resource, config, metadata.TriggerSchedule)
+		if err != nil {
+			c.Logger.Errorw("Could not queue scheduled run", "resource", job.Resource, "error", err)
+		} else if !queued {
+			c.Logger.Infow("Skipping scheduled run, the last one hasn't finished", "resource", job.Resource)
+		}
+	}
+	return nil
+}
+
+// scheduledRun is the config of a scheduled job's run: the runner it's made
+// from.
+type scheduledRun struct {
+	Name   string
+	Config runner.Config
+}
+
+// runScheduledJob makes one run of a scheduled job. Like the worker does
+// for a cron job's runs, it skips resources that are paused, and logs an
+// update event once the run completes.
+func runScheduledJob(ctx context.Context, c *Coordinator, job Job) error {
+	run := &scheduledRun{}
+	if err := json.Unmarshal(job.Config, run); err != nil {
+		return permanent(fmt.Errorf("deserialize scheduled run: %w", err))
+	}
+	pause, err := metadata.GetSchedulePause(ctx, *c.KVClient, job.Resource)
+	if err != nil {
+		return fmt.Errorf("check schedule pause: %w", err)
+	}
+	if pause != nil {
+		c.Logger.Infow("Skipping scheduled run of paused resource", "resource", job.Resource, "reason", pause.Reason)
+		return nil
+	}
+	jobRunner, err := c.Spawner.GetJobRunner(run.Name, run.Config, c.etcdEndpoints(), job.Resource)
+	if err != nil {
+		return fmt.Errorf("create %s runner: %w", run.Name, err)
+	}
+	watcher, err := runner.RunWithContext(c.runContext(ctx, job.Resource), jobRunner)
+	if err != nil {
+		return fmt.Errorf("run %s runner: %w", run.Name, err)
+	}
+	if err := runner.WaitWithContext(ctx, watcher); err != nil {
+		return fmt.Errorf("wait for %s runner: %w", run.Name, err)
+	}
+	if job.Resource.Type == metadata.TRAINING_SET_VARIANT {
+		c.recordTrainingSetFreshness(job.Resource)
+	}
+	c.compactUpdatedFeature(ctx, job.Resource)
+	return c.logUpdateEvent(ctx, job.Resource)
+}
+
+// logUpdateEvent records that a resource's update run finished, the way the
+// runners of cron jobs do, so that its status is set and what's downstream of
+// it is refreshed.
+func (c *Coordinator) logUpdateEvent(ctx context.Context, id metadata.ResourceID) error {
+	event := &ResourceUpdatedEvent{ResourceID: id, Completed: time.Now()}
+	serialized, err := event.Serialize()
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("UPDATE_EVENT_%s__%s__%s__%s", id.Name, id.Variant, id.Type.String(), uuid.New().String())
+	if _, err := (*c.KVClient).Put(ctx, key, string(serialized)); err != nil {
+		return fmt.Errorf("log update event: %w", err)
+	}
+	return nil
+}
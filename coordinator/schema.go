@@ -0,0 +1,128 @@
+package coordinator
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// SchemaMismatch describes a column that a feature or label expects but the
+// source's table doesn't have, or has with an incompatible type.
+type SchemaMismatch struct {
+	Resource     metadata.ResourceID
+	Column       string
+	ExpectedType string
+	// ActualType is empty if the column is missing.
+	ActualType string
+}
+
+func (m SchemaMismatch) String() string {
+	if m.ActualType == "" {
+		return fmt.Sprintf("%s %s (%s): column %s does not exist", m.Resource.Type, m.Resource.Name, m.Resource.Variant, m.Column)
+	}
+	return fmt.Sprintf("%s %s (%s): column %s has type %s, expected %s", m.Resource.Type, m.Resource.Name, m.Resource.Variant, m.Column, m.ActualType, m.ExpectedType)
+}
+
+type SchemaMismatchError struct {
+	Source     metadata.NameVariant
+	Mismatches []SchemaMismatch
+}
+
+func (err *SchemaMismatchError) Error() string {
+	mismatches := make([]string, len(err.Mismatches))
+	for i, m := range err.Mismatches {
+		mismatches[i] = m.String()
+	}
+	return fmt.Sprintf("source %s (%s) does not match the schema of its features and labels: %s", err.Source.Name, err.Source.Variant, strings.Join(mismatches, "; "))
+}
+
+type columnUser struct {
+	id        metadata.ResourceID
+	valueType string
+	columns   metadata.ResourceVariantColumns
+}
+
+// validateResourceSchema checks the columns a feature or label reads from its
+// source against the schema of the table registered for the source. It's run
+// before the resource's own table is registered, so a resource that doesn't
+// match its source fails without leaving a table behind. Sources whose tables
+// can't be read or can't report their schema aren't checked.
+func (c *Coordinator) validateResourceSchema(store provider.OfflineStore, source *metadata.SourceVariant, user columnUser) error {
+	srcID := provider.ResourceID{Name: source.Name(), Variant: source.Variant(), Type: provider.Primary}
+	var table provider.PrimaryTable
+	var err error
+	if source.IsTransformation() {
+		srcID.Type = provider.Transformation
+		table, err = store.GetTransformationTable(srcID)
+	} else {
+		table, err = store.GetPrimaryTable(srcID)
+	}
+	if err != nil {
+		c.Logger.Warnw("Could not get source table to check its schema", "source", srcID, "resource", user.id, "error", err)
+		return nil
+	}
+	schemaTable, ok := table.(provider.SchemaTable)
+	if !ok {
+		return nil
+	}
+	schema, err := schemaTable.Schema()
+	if err != nil {
+		return fmt.Errorf("get source table schema: %w", err)
+	}
+	mismatches := schemaMismatches(schema, []columnUser{user})
+	if len(mismatches) > 0 {
+		return permanent(&SchemaMismatchError{
+			Source:     metadata.NameVariant{Name: source.Name(), Variant: source.Variant()},
+			Mismatches: mismatches,
+		})
+	}
+	return nil
+}
+
+func schemaMismatches(schema provider.TableSchema, users []columnUser) []SchemaMismatch {
+	types := make(map[string]provider.ValueType, len(schema.Columns))
+	for _, column := range schema.Columns {
+		types[strings.ToLower(column.Name)] = column.ValueType
+	}
+	mismatches := make([]SchemaMismatch, 0)
+	check := func(id metadata.ResourceID, column string, expected provider.ValueType) {
+		actual, has := types[strings.ToLower(column)]
+		if !has {
+			mismatches = append(mismatches, SchemaMismatch{Resource: id, Column: column, ExpectedType: string(expected)})
+		} else if !compatibleTypes(expected, actual) {
+			mismatches = append(mismatches, SchemaMismatch{Resource: id, Column: column, ExpectedType: string(expected), ActualType: string(actual)})
+		}
+	}
+	for _, user := range users {
+		check(user.id, user.columns.Entity, provider.NilType)
+		check(user.id, user.columns.Value, provider.ValueType(user.valueType))
+		if user.columns.TS != "" {
+			check(user.id, user.columns.TS, provider.Timestamp)
+		}
+	}
+	return mismatches
+}
+
+// compatibleTypes reports whether a column of type actual can be read as
+// expected. Unknown types on either side are assumed to be compatible, and
+// integer columns can be read as floats or decimals.
+func compatibleTypes(expected, actual provider.ValueType) bool {
+	if expected == provider.NilType || actual == provider.NilType {
+		return true
+	}
+	switch expected {
+	case provider.Int, provider.Int32, provider.Int64:
+		return isIntType(actual)
+	case provider.Float32, provider.Float64:
+		return isIntType(actual) || actual == provider.Float32 || actual == provider.Float64
+	case provider.Decimal:
+		return isIntType(actual) || actual == provider.Decimal
+	}
+	return expected == actual
+}
+
+func isIntType(t provider.ValueType) bool {
+	return t == provider.Int || t == provider.Int32 || t == provider.Int64
+}
@@ -0,0 +1,135 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/client/v3/concurrency"
+)
+
+// DefaultLockTTL is how long a job's lock outlives a coordinator that stops
+// renewing it, for example because it crashed or lost its connection to etcd.
+// Another coordinator takes over the job within about this long. A
+// coordinator that shuts down cleanly releases its locks straight away.
+var DefaultLockTTL = 10 * time.Second
+
+// errJobInterrupted is returned by a job that was stopped part way because
+// its coordinator is shutting down or lost its lock. The job is left in etcd
+// for another coordinator to run again.
+var errJobInterrupted = errors.New("job interrupted")
+
+// shutdown tracks the jobs a coordinator is running so that it can stop
+// claiming new ones and wait for the rest.
+type shutdown struct {
+	// claims is cancelled once the coordinator stops claiming jobs.
+	claims     context.Context
+	stopClaims context.CancelFunc
+	// interrupt is cancelled once running jobs are out of time to finish.
+	interrupt     context.Context
+	interruptJobs context.CancelFunc
+	mtx           sync.Mutex
+	running       int
+	idle          chan struct{}
+	idleClosed    bool
+}
+
+func newShutdown() *shutdown {
+	s := &shutdown{idle: make(chan struct{})}
+	s.claims, s.stopClaims = context.WithCancel(context.Background())
+	s.interrupt, s.interruptJobs = context.WithCancel(context.Background())
+	return s
+}
+
+// start records that a job is starting, unless the coordinator has stopped
+// claiming jobs.
+func (s *shutdown) start() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.claims.Err() != nil {
+		return false
+	}
+	s.running++
+	return true
+}
+
+func (s *shutdown) finish() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.running--
+	s.closeIdleIfDone()
+}
+
+func (s *shutdown) stop() {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.stopClaims()
+	s.closeIdleIfDone()
+}
+
+func (s *shutdown) closeIdleIfDone() {
+	if s.running == 0 && s.claims.Err() != nil && !s.idleClosed {
+		close(s.idle)
+		s.idleClosed = true
+	}
+}
+
+// Shutdown stops the coordinator from claiming new jobs and waits for the
+// jobs it's running to finish. It leaves its partition, if it's in one, so
+// that the other replicas start claiming its share of new jobs. Jobs that are
+// still running when ctx is done are interrupted and left in etcd, and their
+// locks are released so that another coordinator takes them over. Shutdown
+// returns once all of the coordinator's jobs have stopped, with ctx's error if
+// any were interrupted.
+func (c *Coordinator) Shutdown(ctx context.Context) error {
+	c.Logger.Info("Shutting down, no longer claiming jobs")
+	c.shutdown.stop()
+	c.leavePartition()
+	select {
+	case <-c.shutdown.idle:
+		c.Logger.Info("All jobs finished")
+		return nil
+	case <-ctx.Done():
+	}
+	c.Logger.Warn("Interrupting jobs that didn't finish in time")
+	c.shutdown.interruptJobs()
+	<-c.shutdown.idle
+	return ctx.Err()
+}
+
+// lockTTL is the TTL of job lock sessions, which etcd takes in seconds.
+func (c *Coordinator) lockTTL() int {
+	ttl := int(c.LockTTL / time.Second)
+	if ttl < 1 {
+		return 1
+	}
+	return ttl
+}
+
+type leaseKey struct{}
+
+// leaseContext returns a context that's done once the coordinator interrupts
+// its jobs or loses the session holding a job's lock. A job that has lost its
+// lock must stop, since another coordinator may already be running it.
+func (c *Coordinator) leaseContext(s *concurrency.Session) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-s.Done():
+			c.Logger.Warn("Lost job lock session")
+		case <-c.shutdown.interrupt.Done():
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+	ctx = context.WithValue(ctx, leaseKey{}, ctx)
+	return ctx, cancel
+}
+
+// interrupted reports whether a job context is done because its lease is,
+// rather than because the job was cancelled.
+func interrupted(ctx context.Context) bool {
+	lease, ok := ctx.Value(leaseKey{}).(context.Context)
+	return ok && lease.Err() != nil
+}
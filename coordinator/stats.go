@@ -0,0 +1,77 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+type statsTable interface {
+	Stats() (provider.TableStats, error)
+}
+
+// recordStats stores the stats of the table behind a source or feature
+// variant in metadata. Stats are only used for display and monitoring, so a
+// failure is logged instead of failing the job that called it.
+func (c *Coordinator) recordStats(resID metadata.ResourceID) {
+	if resID.Type != metadata.SOURCE_VARIANT && resID.Type != metadata.FEATURE_VARIANT {
+		return
+	}
+	if err := c.refreshStats(resID); err != nil {
+		c.Logger.Warnw("Could not record table stats", "resource", resID, "error", err)
+	}
+}
+
+func (c *Coordinator) refreshStats(resID metadata.ResourceID) error {
+	table, err := c.getStatsTable(resID)
+	if err != nil {
+		return err
+	}
+	stats, err := table.Stats()
+	if err != nil {
+		return fmt.Errorf("compute table stats: %w", err)
+	}
+	return c.store().SetStats(context.Background(), resID, metadata.TableStats{
+		NumRows:    stats.NumRows,
+		SizeBytes:  stats.SizeBytes,
+		MinTS:      stats.MinTS,
+		MaxTS:      stats.MaxTS,
+		NullCounts: stats.NullCounts,
+		Computed:   time.Now().UTC(),
+	})
+}
+
+func (c *Coordinator) getStatsTable(resID metadata.ResourceID) (statsTable, error) {
+	ctx := context.Background()
+	nameVariant := metadata.NameVariant{Name: resID.Name, Variant: resID.Variant}
+	if resID.Type == metadata.FEATURE_VARIANT {
+		feature, err := c.store().GetFeatureVariant(ctx, nameVariant)
+		if err != nil {
+			return nil, fmt.Errorf("get feature variant from metadata: %w", err)
+		}
+		source, err := c.store().GetSourceVariant(ctx, feature.Source())
+		if err != nil {
+			return nil, fmt.Errorf("get feature source from metadata: %w", err)
+		}
+		store, err := c.fetchOfflineStore(source)
+		if err != nil {
+			return nil, err
+		}
+		return store.GetMaterialization(provider.ResourceMaterializationID(provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature}))
+	}
+	source, err := c.store().GetSourceVariant(ctx, nameVariant)
+	if err != nil {
+		return nil, fmt.Errorf("get source variant from metadata: %w", err)
+	}
+	store, err := c.fetchOfflineStore(source)
+	if err != nil {
+		return nil, err
+	}
+	if source.IsTransformation() {
+		return store.GetTransformationTable(provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Transformation})
+	}
+	return store.GetPrimaryTable(provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Primary})
+}
@@ -0,0 +1,105 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/runner"
+)
+
+// isStreamed reports whether source's provider is a streaming source, whose
+// primary table is a topic that features are streamed from.
+func (c *Coordinator) isStreamed(source metadata.NameVariant) (bool, error) {
+	sourceVariant, err := c.store().GetSourceVariant(context.Background(), source)
+	if err != nil {
+		return false, fmt.Errorf("get source variant from metadata: %w", err)
+	}
+	sourceProvider, err := c.store().GetProvider(context.Background(), sourceVariant.Provider())
+	if err != nil {
+		return false, fmt.Errorf("fetch source's dependent provider in metadata: %w", err)
+	}
+	p, err := c.providers().Get(provider.Type(sourceProvider.Type()), sourceProvider.SerializedConfig())
+	if err != nil {
+		return false, err
+	}
+	_, isStream := p.(provider.StreamingSource)
+	return isStream, nil
+}
+
+// runStreamSourceJob registers a source whose primary table is a topic.
+// There's nothing to copy, since features are streamed from it as they're
+// written.
+func (c *Coordinator) runStreamSourceJob(source *metadata.SourceVariant, resID metadata.ResourceID) error {
+	if !source.IsPrimaryDataSQLTable() {
+		return permanent(fmt.Errorf("streaming sources can only be primary tables"))
+	}
+	if source.PrimaryDataSQLTableName() == "" {
+		return permanent(fmt.Errorf("no topic set"))
+	}
+	if err := c.store().SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+		return fmt.Errorf("set done status for registering stream: %w", err)
+	}
+	return nil
+}
+
+// runStreamToOnlineJob streams a feature's values from its source's topic
+// into its online table. The feature is ready once the stream has started,
+// and the job runs for as long as the stream does, so that if its
+// coordinator goes away another one takes the job over and carries on from
+// the stream's last checkpoint. The job only ends once it's cancelled.
+func (c *Coordinator) runStreamToOnlineJob(feature *metadata.FeatureVariant, source *metadata.SourceVariant, sourceProvider *metadata.Provider, resID metadata.ResourceID) error {
+	featureProvider, err := c.store().GetProvider(context.Background(), feature.Provider())
+	if err != nil {
+		return fmt.Errorf("could not fetch online provider: %w", err)
+	}
+	onlineConfig, err := c.runnerProviderConfig(featureProvider)
+	if err != nil {
+		return err
+	}
+	sourceConfig, err := c.runnerProviderConfig(sourceProvider)
+	if err != nil {
+		return err
+	}
+	streamConfig := runner.StreamToOnlineRunnerConfig{
+		SourceType:   provider.Type(sourceProvider.Type()),
+		SourceConfig: sourceConfig,
+		Topic:        source.PrimaryDataSQLTableName(),
+		OnlineType:   provider.Type(featureProvider.Type()),
+		OnlineConfig: onlineConfig,
+		ResourceID:   provider.ResourceID{Name: resID.Name, Variant: resID.Variant, Type: provider.Feature},
+		ValueType:    provider.ValueType(feature.Type()),
+	}
+	serialized, err := streamConfig.Serialize()
+	if err != nil {
+		return fmt.Errorf("serialize stream to online runner config: %w", err)
+	}
+	c.Logger.Infow("Starting stream", "resource", resID, "topic", streamConfig.Topic)
+	jobRunner, err := c.Spawner.GetJobRunner(runner.STREAM_TO_ONLINE, serialized, c.etcdEndpoints(), resID)
+	if err != nil {
+		return fmt.Errorf("could not create stream runner: %w", err)
+	}
+	ctx := c.jobContext(resID)
+	completionWatcher, err := runner.RunWithContext(ctx, jobRunner)
+	if err != nil {
+		return fmt.Errorf("creating watcher for stream runner: %w", err)
+	}
+	if err := c.store().SetStatus(context.Background(), resID, metadata.READY, ""); err != nil {
+		if cancellable, ok := completionWatcher.(runner.CancellableWatcher); ok {
+			cancellable.Cancel()
+		}
+		return fmt.Errorf("stream set ready: %w", err)
+	}
+	err = c.waitReportingProgress(ctx, resID, completionWatcher)
+	if ctx.Err() != nil {
+		// The job is cancelled or interrupted, so it's left to those to
+		// record.
+		return ctx.Err()
+	}
+	if err == nil {
+		err = errors.New("stream ended")
+	}
+	return fmt.Errorf("stream running: %w", err)
+}
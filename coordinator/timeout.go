@@ -0,0 +1,56 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// errJobTimedOut is returned by an attempt at a job that ran past its
+// resource type's max runtime.
+var errJobTimedOut = metadata.WithErrorCode(metadata.ErrorJobTimedOut, errors.New("job timed out"))
+
+// maxRuntime is how long an attempt at a job for id may run, or zero if its
+// resource type isn't limited.
+func (c *Coordinator) maxRuntime(id metadata.ResourceID) time.Duration {
+	return c.MaxRuntime[id.Type]
+}
+
+// runWithTimeout runs an attempt at the job for id with its runner's context
+// limited by the job's max runtime. When the runner is stopped for running
+// too long, the attempt fails with a timeout error, which is only retried if
+// the coordinator retries timeouts.
+func (c *Coordinator) runWithTimeout(ctx context.Context, id metadata.ResourceID, run func() error) error {
+	return c.limitRuntime(ctx, id, func(runCtx context.Context) error {
+		if runCtx != ctx {
+			c.jobContexts.Store(id, runCtx)
+			defer c.jobContexts.Store(id, ctx)
+		}
+		return run()
+	})
+}
+
+// limitRuntime runs an attempt at a job for id with a context that's limited
+// by the job's max runtime, for runs like backfills that pass their context
+// to their runners themselves rather than through the job's context.
+func (c *Coordinator) limitRuntime(ctx context.Context, id metadata.ResourceID, run func(context.Context) error) error {
+	max := c.maxRuntime(id)
+	if max <= 0 {
+		return run(ctx)
+	}
+	runCtx, cancel := context.WithTimeout(ctx, max)
+	defer cancel()
+	err := run(runCtx)
+	if ctx.Err() != nil || !errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	c.Logger.Warnw("Job exceeded its max runtime", "resource", id, "max_runtime", max, "error", err)
+	timeoutErr := fmt.Errorf("%w: ran for longer than %s", errJobTimedOut, max)
+	if !c.RetryTimeouts {
+		return permanent(timeoutErr)
+	}
+	return timeoutErr
+}
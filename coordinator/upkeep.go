@@ -0,0 +1,101 @@
+package coordinator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"github.com/featureform/runner"
+)
+
+// runProviderJob sets up the maintenance tasks a provider was registered
+// with. Tasks with a schedule become cron jobs, the rest run once.
+func (c *Coordinator) runProviderJob(resID metadata.ResourceID, schedule string) error {
+	c.Logger.Info("Running provider maintenance job on resource: ", resID)
+	p, err := c.store().GetProvider(context.Background(), resID.Name)
+	if err != nil {
+		return fmt.Errorf("get provider from metadata: %w", err)
+	}
+	for _, task := range p.Maintenance() {
+		if err := c.RunProviderMaintenance(resID.Name, provider.MaintenanceTask(task.Kind), task.Schedule); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunProviderMaintenance runs a maintenance task on a provider, such as
+// vacuuming its materialization tables. With a schedule the task is set up as
+// a cron job, otherwise it runs once and this waits for it.
+func (c *Coordinator) RunProviderMaintenance(providerName string, task provider.MaintenanceTask, schedule string) error {
+	parsed, err := metadata.ParseSchedule(schedule)
+	if err != nil {
+		return permanent(err)
+	}
+	p, err := c.store().GetProvider(context.Background(), providerName)
+	if err != nil {
+		return fmt.Errorf("get provider: %w", err)
+	}
+	if err := c.checkMaintenance(p, task); err != nil {
+		return err
+	}
+	providerConfig, err := c.runnerProviderConfig(p)
+	if err != nil {
+		return err
+	}
+	maintenanceConfig := &runner.MaintenanceRunnerConfig{
+		ProviderType:   provider.Type(p.Type()),
+		ProviderConfig: providerConfig,
+		Task:           task,
+	}
+	serialized, err := maintenanceConfig.Serialize()
+	if err != nil {
+		return fmt.Errorf("serialize maintenance config: %w", err)
+	}
+	// Each task gets its own job, so that a provider's tasks can be on
+	// different schedules.
+	jobID := metadata.ResourceID{Name: providerName, Variant: "maintenance-" + strings.ToLower(string(task)), Type: metadata.PROVIDER}
+	jobRunner, err := c.Spawner.GetJobRunner(runner.MAINTAIN_PROVIDER, serialized, c.etcdEndpoints(), jobID)
+	if err != nil {
+		return fmt.Errorf("create maintenance runner: %w", err)
+	}
+	if !parsed.IsZero() {
+		cronRunner := c.cronRunner(jobRunner, runner.MAINTAIN_PROVIDER, serialized, jobID)
+		if err := cronRunner.ScheduleJob(runner.CronSchedule(parsed.Cron())); err != nil {
+			return fmt.Errorf("schedule maintenance job: %w", err)
+		}
+		return nil
+	}
+	watcher, err := jobRunner.Run()
+	if err != nil {
+		return fmt.Errorf("run maintenance: %w", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		return fmt.Errorf("%s maintenance failed: %w", task, err)
+	}
+	return nil
+}
+
+// checkMaintenance fails permanently if the provider can't run task, rather
+// than every scheduled run failing in a worker.
+func (c *Coordinator) checkMaintenance(p *metadata.Provider, task provider.MaintenanceTask) error {
+	store, err := c.providers().Get(provider.Type(p.Type()), provider.SerializedConfig(p.SerializedConfig()))
+	if err != nil {
+		return fmt.Errorf("get provider: %w", err)
+	}
+	if closer, ok := store.(interface{ Close() error }); ok {
+		defer closer.Close()
+	}
+	maintainable, ok := store.(provider.MaintainableStore)
+	if !ok {
+		return permanent(&provider.UnsupportedMaintenance{Provider: provider.Type(p.Type()), Task: task})
+	}
+	for _, supported := range maintainable.MaintenanceTasks() {
+		if supported == task {
+			return nil
+		}
+	}
+	return permanent(&provider.UnsupportedMaintenance{Provider: provider.Type(p.Type()), Task: task})
+}
@@ -0,0 +1,283 @@
+// Package demo loads a synthetic transactions dataset into an offline store
+// and registers an example pipeline on top of it: a primary source, a
+// scheduled SQL transformation, a feature, a label and a training set. It's
+// meant for trying out a deployment without writing definitions by hand.
+package demo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+const (
+	DefaultVariant  = "demo"
+	DefaultRows     = 10000
+	DefaultSchedule = "@hourly"
+	Owner           = "featureform-demo"
+	Entity          = "customer"
+
+	TransactionsTable = "demo_transactions_raw"
+	Transactions      = "demo_transactions"
+	CustomerActivity  = "demo_customer_activity"
+	TransactionAmount = "demo_transaction_amount"
+	IsFraud           = "demo_is_fraud"
+	FraudTrainingSet  = "demo_fraud_training"
+)
+
+// Config describes a demo to load. Providers have to be registered in
+// metadata already; everything else has a default.
+type Config struct {
+	OfflineProvider string
+	OnlineProvider  string
+	// Variant is used for every resource, so that a demo can be loaded again
+	// next to an earlier one.
+	Variant string
+	Rows    int
+	// Seed makes the dataset reproducible.
+	Seed int64
+	// Schedule is what the transformation, feature and training set are
+	// updated on. "none" leaves them unscheduled.
+	Schedule string
+	// Start is the time of the first transaction. Transactions are spread
+	// over the 30 days after it.
+	Start time.Time
+}
+
+func (config Config) withDefaults() Config {
+	if config.Variant == "" {
+		config.Variant = DefaultVariant
+	}
+	if config.Rows <= 0 {
+		config.Rows = DefaultRows
+	}
+	if config.Seed == 0 {
+		config.Seed = 1
+	}
+	switch config.Schedule {
+	case "":
+		config.Schedule = DefaultSchedule
+	case "none":
+		config.Schedule = ""
+	}
+	if config.Start.IsZero() {
+		config.Start = time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -30)
+	}
+	return config
+}
+
+// TransactionsSchema is the schema of the demo dataset.
+var TransactionsSchema = provider.TableSchema{
+	Columns: []provider.TableColumn{
+		{Name: "transaction_id", ValueType: provider.String},
+		{Name: "customer_id", ValueType: provider.String},
+		{Name: "amount", ValueType: provider.Float32},
+		{Name: "ts", ValueType: provider.Timestamp},
+		{Name: "is_fraud", ValueType: provider.Bool},
+	},
+}
+
+// GenerateTransactions returns rows of synthetic transactions that match
+// TransactionsSchema. The same seed always generates the same rows. Fraud is
+// rare, and more likely for large transactions, so the label is learnable
+// from the amount.
+func GenerateTransactions(rows int, seed int64, start time.Time) []provider.GenericRecord {
+	r := rand.New(rand.NewSource(seed))
+	customers := rows/20 + 1
+	window := int64(30 * 24 * time.Hour)
+	records := make([]provider.GenericRecord, rows)
+	for i := range records {
+		amount := float32(math.Round(math.Exp(r.NormFloat64()+3)*100) / 100)
+		fraudChance := 0.01
+		if amount > 100 {
+			fraudChance = 0.2
+		}
+		records[i] = provider.GenericRecord{
+			fmt.Sprintf("t%07d", i),
+			fmt.Sprintf("c%05d", r.Intn(customers)),
+			amount,
+			start.Add(time.Duration(r.Int63n(window))),
+			r.Float64() < fraudChance,
+		}
+	}
+	return records
+}
+
+// LoadDataset writes the demo dataset to store and returns the name of its
+// table.
+func LoadDataset(store provider.OfflineStore, config Config) (string, error) {
+	config = config.withDefaults()
+	id := provider.ResourceID{Name: TransactionsTable, Variant: config.Variant, Type: provider.Primary}
+	table, err := store.CreatePrimaryTable(id, TransactionsSchema)
+	if err != nil {
+		return "", fmt.Errorf("create demo table, a demo with variant %s may already be loaded: %w", config.Variant, err)
+	}
+	for _, record := range GenerateTransactions(config.Rows, config.Seed, config.Start) {
+		if err := table.Write(record); err != nil {
+			return "", fmt.Errorf("write demo transaction: %w", err)
+		}
+	}
+	return table.GetName(), nil
+}
+
+// Pipeline returns the definitions of the example pipeline, built on the
+// demo dataset in table.
+func Pipeline(config Config, table string) []metadata.ResourceDef {
+	config = config.withDefaults()
+	variant := config.Variant
+	transactions := metadata.NameVariant{Name: Transactions, Variant: variant}
+	activity := metadata.NameVariant{Name: CustomerActivity, Variant: variant}
+	columns := func(value string) metadata.ResourceVariantColumns {
+		return metadata.ResourceVariantColumns{Entity: "customer_id", Value: value, TS: "ts"}
+	}
+	return []metadata.ResourceDef{
+		metadata.UserDef{
+			Name: Owner,
+		},
+		metadata.EntityDef{
+			Name:        Entity,
+			Description: "A customer making transactions",
+		},
+		metadata.SourceDef{
+			Name:        Transactions,
+			Variant:     variant,
+			Description: "Synthetic card transactions",
+			Owner:       Owner,
+			Provider:    config.OfflineProvider,
+			Definition: metadata.PrimaryDataSource{
+				Location: metadata.SQLTable{
+					Name: table,
+				},
+			},
+		},
+		metadata.SourceDef{
+			Name:        CustomerActivity,
+			Variant:     variant,
+			Description: "Transactions with a positive amount",
+			Owner:       Owner,
+			Provider:    config.OfflineProvider,
+			Schedule:    config.Schedule,
+			Definition: metadata.TransformationSource{
+				TransformationType: metadata.SQLTransformationType{
+					Query:   fmt.Sprintf("SELECT customer_id, amount, ts, is_fraud FROM {{%s.%s}} WHERE amount > 0", Transactions, variant),
+					Sources: []metadata.NameVariant{transactions},
+				},
+			},
+		},
+		metadata.FeatureDef{
+			Name:        TransactionAmount,
+			Variant:     variant,
+			Description: "The amount of a customer's latest transaction",
+			Type:        string(provider.Float32),
+			Entity:      Entity,
+			Owner:       Owner,
+			Provider:    config.OnlineProvider,
+			Schedule:    config.Schedule,
+			Source:      activity,
+			Location:    columns("amount"),
+		},
+		metadata.LabelDef{
+			Name:        IsFraud,
+			Variant:     variant,
+			Description: "Whether a customer's transaction was fraudulent",
+			Type:        string(provider.Bool),
+			Entity:      Entity,
+			Owner:       Owner,
+			Provider:    config.OfflineProvider,
+			Source:      activity,
+			Location:    columns("is_fraud"),
+		},
+		metadata.TrainingSetDef{
+			Name:        FraudTrainingSet,
+			Variant:     variant,
+			Description: "Transaction amounts labeled with fraud",
+			Owner:       Owner,
+			Provider:    config.OfflineProvider,
+			Schedule:    config.Schedule,
+			Label:       metadata.NameVariant{Name: IsFraud, Variant: variant},
+			Features:    metadata.NameVariants{{Name: TransactionAmount, Variant: variant}},
+		},
+	}
+}
+
+// Result is a loaded demo.
+type Result struct {
+	// Table is the offline store table the dataset was written to.
+	Table     string
+	Resources []metadata.ResourceID
+}
+
+// Load writes the demo dataset to the offline provider and registers the
+// example pipeline in metadata, where the coordinator picks it up.
+func Load(ctx context.Context, client *metadata.Client, config Config) (*Result, error) {
+	config = config.withDefaults()
+	if config.OfflineProvider == "" || config.OnlineProvider == "" {
+		return nil, fmt.Errorf("demo needs an offline and an online provider")
+	}
+	if _, err := client.GetProvider(ctx, config.OnlineProvider); err != nil {
+		return nil, fmt.Errorf("get online provider: %w", err)
+	}
+	offline, err := client.GetProvider(ctx, config.OfflineProvider)
+	if err != nil {
+		return nil, fmt.Errorf("get offline provider: %w", err)
+	}
+	p, err := provider.Get(provider.Type(offline.Type()), offline.SerializedConfig())
+	if err != nil {
+		return nil, fmt.Errorf("get offline provider: %w", err)
+	}
+	store, err := p.AsOfflineStore()
+	if err != nil {
+		return nil, fmt.Errorf("use %s as an offline store: %w", config.OfflineProvider, err)
+	}
+	table, err := LoadDataset(store, config)
+	if err != nil {
+		return nil, err
+	}
+	defs := Pipeline(config, table)
+	// The demo user and entity are shared by every variant of the demo, so
+	// they're only created by the first one.
+	var create []metadata.ResourceDef
+	for _, def := range defs {
+		switch casted := def.(type) {
+		case metadata.UserDef:
+			if _, err := client.GetUser(ctx, casted.Name); err == nil {
+				continue
+			}
+		case metadata.EntityDef:
+			if _, err := client.GetEntity(ctx, casted.Name); err == nil {
+				continue
+			}
+		}
+		create = append(create, def)
+	}
+	if err := client.CreateAll(ctx, create); err != nil {
+		return nil, fmt.Errorf("register demo pipeline: %w", err)
+	}
+	return &Result{Table: table, Resources: resourceIDs(defs)}, nil
+}
+
+func resourceIDs(defs []metadata.ResourceDef) []metadata.ResourceID {
+	var ids []metadata.ResourceID
+	for _, def := range defs {
+		var id metadata.ResourceID
+		switch casted := def.(type) {
+		case metadata.SourceDef:
+			id = metadata.ResourceID{Name: casted.Name, Variant: casted.Variant, Type: metadata.SOURCE_VARIANT}
+		case metadata.FeatureDef:
+			id = metadata.ResourceID{Name: casted.Name, Variant: casted.Variant, Type: metadata.FEATURE_VARIANT}
+		case metadata.LabelDef:
+			id = metadata.ResourceID{Name: casted.Name, Variant: casted.Variant, Type: metadata.LABEL_VARIANT}
+		case metadata.TrainingSetDef:
+			id = metadata.ResourceID{Name: casted.Name, Variant: casted.Variant, Type: metadata.TRAINING_SET_VARIANT}
+		default:
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,192 @@
+package demo
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"go.uber.org/zap/zaptest"
+)
+
+// fakeStore is an offline store that only supports primary tables.
+type fakeStore struct {
+	provider.OfflineStore
+	mtx    sync.Mutex
+	tables map[provider.ResourceID]*fakeTable
+}
+
+func (store *fakeStore) AsOfflineStore() (provider.OfflineStore, error) {
+	return store, nil
+}
+
+func (store *fakeStore) CreatePrimaryTable(id provider.ResourceID, schema provider.TableSchema) (provider.PrimaryTable, error) {
+	store.mtx.Lock()
+	defer store.mtx.Unlock()
+	if _, has := store.tables[id]; has {
+		return nil, &provider.TableAlreadyExists{Feature: id.Name, Variant: id.Variant}
+	}
+	table := &fakeTable{name: "featureform_primary__" + id.Name + "__" + id.Variant}
+	store.tables[id] = table
+	return table, nil
+}
+
+type fakeTable struct {
+	provider.PrimaryTable
+	name    string
+	records []provider.GenericRecord
+}
+
+func (table *fakeTable) Write(record provider.GenericRecord) error {
+	table.records = append(table.records, record)
+	return nil
+}
+
+func (table *fakeTable) GetName() string {
+	return table.name
+}
+
+func TestGenerateTransactions(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	records := GenerateTransactions(500, 7, start)
+	if len(records) != 500 {
+		t.Fatalf("Generated %d transactions, expected 500", len(records))
+	}
+	if !reflect.DeepEqual(records, GenerateTransactions(500, 7, start)) {
+		t.Fatalf("Transactions generated from the same seed differ")
+	}
+	frauds := 0
+	for _, record := range records {
+		if len(record) != len(TransactionsSchema.Columns) {
+			t.Fatalf("Transaction %v doesn't match schema", record)
+		}
+		ts := record[3].(time.Time)
+		if ts.Before(start) || !ts.Before(start.AddDate(0, 0, 30)) {
+			t.Fatalf("Transaction time %s outside of demo window", ts)
+		}
+		if record[2].(float32) <= 0 {
+			t.Fatalf("Transaction amount %v isn't positive", record[2])
+		}
+		if record[4].(bool) {
+			frauds++
+		}
+	}
+	if frauds == 0 || frauds > len(records)/4 {
+		t.Fatalf("Unrealistic number of fraudulent transactions: %d", frauds)
+	}
+}
+
+func TestLoadDataset(t *testing.T) {
+	store := &fakeStore{tables: make(map[provider.ResourceID]*fakeTable)}
+	config := Config{Rows: 100}
+	name, err := LoadDataset(store, config)
+	if err != nil {
+		t.Fatalf("Failed to load dataset: %s", err)
+	}
+	table := store.tables[provider.ResourceID{Name: TransactionsTable, Variant: DefaultVariant, Type: provider.Primary}]
+	if table == nil || table.name != name {
+		t.Fatalf("Dataset not loaded into %s", name)
+	}
+	if len(table.records) != 100 {
+		t.Fatalf("Loaded %d transactions, expected 100", len(table.records))
+	}
+	if _, err := LoadDataset(store, config); err == nil {
+		t.Fatalf("Succeeded in loading the same variant twice")
+	}
+}
+
+func TestPipelineSchedule(t *testing.T) {
+	type testCase struct {
+		Schedule string
+		Expected string
+	}
+	tests := map[string]testCase{
+		"Default": {"", DefaultSchedule},
+		"Custom":  {"0 0 * * *", "0 0 * * *"},
+		"None":    {"none", ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, def := range Pipeline(Config{Schedule: test.Schedule}, "table") {
+				var schedule string
+				switch casted := def.(type) {
+				case metadata.FeatureDef:
+					schedule = casted.Schedule
+				case metadata.TrainingSetDef:
+					schedule = casted.Schedule
+				case metadata.SourceDef:
+					if _, isPrimary := casted.Definition.(metadata.PrimaryDataSource); isPrimary {
+						continue
+					}
+					schedule = casted.Schedule
+				default:
+					continue
+				}
+				if schedule != test.Expected {
+					t.Fatalf("%T scheduled on %q, expected %q", def, schedule, test.Expected)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	logger := zaptest.NewLogger(t).Sugar()
+	serv, err := metadata.NewMetadataServer(&metadata.Config{
+		Logger:          logger,
+		StorageProvider: metadata.LocalStorageProvider{},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create metadata server: %s", err)
+	}
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go serv.ServeOnListener(lis)
+	defer serv.Stop()
+	client, err := metadata.NewClient(lis.Addr().String(), logger)
+	if err != nil {
+		t.Fatalf("Failed to create client: %s", err)
+	}
+	store := &fakeStore{tables: make(map[provider.ResourceID]*fakeTable)}
+	offlineType := provider.Type("DEMO_TEST_OFFLINE")
+	if err := provider.RegisterFactory(offlineType, func(provider.SerializedConfig) (provider.Provider, error) { return store, nil }); err != nil {
+		t.Fatalf("Failed to register factory: %s", err)
+	}
+	providers := []metadata.ResourceDef{
+		metadata.ProviderDef{Name: "offline", Type: string(offlineType), SerializedConfig: []byte("{}")},
+		metadata.ProviderDef{Name: "online", Type: string(provider.LocalOnline), SerializedConfig: []byte("{}")},
+	}
+	ctx := context.Background()
+	if err := client.CreateAll(ctx, providers); err != nil {
+		t.Fatalf("Failed to create providers: %s", err)
+	}
+	config := Config{OfflineProvider: "offline", OnlineProvider: "online", Rows: 50}
+	result, err := Load(ctx, client, config)
+	if err != nil {
+		t.Fatalf("Failed to load demo: %s", err)
+	}
+	if len(result.Resources) != 5 {
+		t.Fatalf("Registered %d resources, expected 5: %v", len(result.Resources), result.Resources)
+	}
+	ts, err := client.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: FraudTrainingSet, Variant: DefaultVariant})
+	if err != nil {
+		t.Fatalf("Training set not registered: %s", err)
+	}
+	if ts.Schedule() != DefaultSchedule {
+		t.Fatalf("Training set scheduled on %q, expected %q", ts.Schedule(), DefaultSchedule)
+	}
+	// A second variant reuses the demo user and entity.
+	config.Variant = "second"
+	if _, err := Load(ctx, client, config); err != nil {
+		t.Fatalf("Failed to load second demo: %s", err)
+	}
+	if _, err := Load(ctx, client, config); err == nil {
+		t.Fatalf("Succeeded in loading the same demo twice")
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/featureform/helpers/demo"
+	"github.com/featureform/metadata"
+	"go.uber.org/zap"
+)
+
+func main() {
+	metadataAddr := flag.String("metadata", "localhost:8080", "address of the metadata server")
+	offline := flag.String("offline", "", "registered offline provider to load the dataset into")
+	online := flag.String("online", "", "registered online provider to serve the demo feature from")
+	variant := flag.String("variant", demo.DefaultVariant, "variant of every demo resource")
+	rows := flag.Int("rows", demo.DefaultRows, "number of transactions to generate")
+	seed := flag.Int64("seed", 1, "seed of the generated transactions")
+	schedule := flag.String("schedule", demo.DefaultSchedule, `schedule to update the pipeline on, or "none"`)
+	flag.Parse()
+	if *offline == "" || *online == "" {
+		fmt.Fprintln(os.Stderr, "-offline and -online are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	logger := zap.NewExample().Sugar()
+	client, err := metadata.NewClient(*metadataAddr, logger)
+	if err != nil {
+		logger.Panicw("Failed to connect", "Err", err)
+	}
+	result, err := demo.Load(context.Background(), client, demo.Config{
+		OfflineProvider: *offline,
+		OnlineProvider:  *online,
+		Variant:         *variant,
+		Rows:            *rows,
+		Seed:            *seed,
+		Schedule:        *schedule,
+	})
+	if err != nil {
+		logger.Panicw("Failed to load demo", "Err", err)
+	}
+	fmt.Printf("Loaded %d transactions into %s\n", *rows, result.Table)
+	for _, id := range result.Resources {
+		fmt.Printf("Registered %s %s (%s)\n", id.Type, id.Name, id.Variant)
+	}
+}
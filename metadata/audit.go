@@ -0,0 +1,59 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditPrefix is the etcd prefix that audit events are recorded under.
+const AuditPrefix = "AUDIT__"
+
+// AuditEvent records a change made through the metadata server. Events are
+// kept in etcd until the coordinator archives them.
+type AuditEvent struct {
+	Action    string
+	Requester string
+	Time      time.Time
+	// Details are the rest of the event, such as the resource it changed.
+	Details map[string]string
+}
+
+func (e *AuditEvent) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
+func (e *AuditEvent) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, e)
+}
+
+// GetAuditKey returns a key that's unique to the event, and that sorts
+// events by when they happened.
+func GetAuditKey(event AuditEvent) string {
+	return fmt.Sprintf("%s%020d__%s", AuditPrefix, event.Time.UnixNano(), uuid.NewString())
+}
+
+// audit logs an event to the audit logger and records it in the lookup.
+// keysAndValues are logged as they would be by Infow. A failure to record
+// the event is logged, rather than failing a change that's already been made.
+func (serv *MetadataServer) audit(action, requester string, keysAndValues ...interface{}) {
+	now := time.Now().UTC()
+	serv.Logger.Named("audit").Infow(action, append(keysAndValues, "requester", requester, "time", now.Format(TIME_FORMAT))...)
+	event := AuditEvent{Action: action, Requester: requester, Time: now, Details: make(map[string]string)}
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		event.Details[fmt.Sprint(keysAndValues[i])] = fmt.Sprint(keysAndValues[i+1])
+	}
+	if err := serv.lookup.RecordAudit(event); err != nil {
+		serv.Logger.Errorw("Could not record audit event", "action", action, "error", err)
+	}
+}
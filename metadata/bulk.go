@@ -0,0 +1,193 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+// jobResourceTypes are the resource types the coordinator runs jobs for.
+var jobResourceTypes = []ResourceType{
+	SOURCE_VARIANT,
+	FEATURE_VARIANT,
+	LABEL_VARIANT,
+	TRAINING_SET_VARIANT,
+}
+
+type statusGetter interface {
+	GetStatus() *pb.ResourceStatus
+}
+
+func resourceStatus(res Resource) ResourceStatus {
+	if getter, ok := res.Proto().(statusGetter); ok {
+		return ResourceStatus(getter.GetStatus().GetStatus())
+	}
+	return NO_STATUS
+}
+
+func resourceProvider(res Resource) string {
+	if getter, ok := res.Proto().(providerGetter); ok {
+		return getter.GetProvider()
+	}
+	return ""
+}
+
+func matchesBulkFilter(filter *pb.BulkFilter, res Resource) bool {
+	if filter.GetProvider() != "" && resourceProvider(res) != filter.GetProvider() {
+		return false
+	}
+	return strings.HasPrefix(res.ID().Name, filter.GetNamePrefix())
+}
+
+// jobResources lists the resources of the given types that pass keep. Types
+// that the coordinator doesn't run jobs for are ignored, and no types means
+// all of them.
+func (serv *MetadataServer) jobResources(types []pb.ResourceType, keep func(Resource) bool) ([]Resource, error) {
+	selected := jobResourceTypes
+	if len(types) > 0 {
+		selected = nil
+		for _, t := range jobResourceTypes {
+			for _, requested := range types {
+				if t.Serialized() == requested {
+					selected = append(selected, t)
+				}
+			}
+		}
+	}
+	var matched []Resource
+	for _, t := range selected {
+		resources, err := serv.lookup.ListForType(t)
+		if err != nil {
+			return nil, err
+		}
+		for _, res := range resources {
+			if keep(res) {
+				matched = append(matched, res)
+			}
+		}
+	}
+	return matched, nil
+}
+
+func bulkResult(ids []ResourceID, dryRun bool) *pb.BulkOperationResult {
+	result := &pb.BulkOperationResult{
+		Resources: make([]*pb.ResourceID, len(ids)),
+		DryRun:    dryRun,
+	}
+	for i, id := range ids {
+		result.Resources[i] = &pb.ResourceID{
+			Resource:     &pb.NameVariant{Name: id.Name, Variant: id.Variant},
+			ResourceType: id.Type.Serialized(),
+		}
+	}
+	return result
+}
+
+func (serv *MetadataServer) auditBulk(operation string, ids []ResourceID, dryRun bool, requester string) {
+	serv.audit("Bulk operation", requester, "operation", operation, "resources", len(ids), "dry_run", dryRun)
+}
+
+// BulkRerunFailed queues a new job for every FAILED resource that matches the
+// filter. Each job starts again from its first attempt.
+func (serv *MetadataServer) BulkRerunFailed(ctx context.Context, req *pb.BulkRerunRequest) (*pb.BulkOperationResult, error) {
+	resources, err := serv.jobResources(req.GetFilter().GetTypes(), func(res Resource) bool {
+		return resourceStatus(res) == FAILED && matchesBulkFilter(req.GetFilter(), res)
+	})
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]ResourceID, len(resources))
+	for i, res := range resources {
+		ids[i] = res.ID()
+		if req.DryRun {
+			continue
+		}
+		if err := serv.lookup.SetStatus(ids[i], pb.ResourceStatus{Status: pb.ResourceStatus_PENDING}); err != nil {
+			return nil, err
+		}
+		if err := serv.lookup.ResetJob(ids[i], res.Schedule()); err != nil {
+			return nil, err
+		}
+	}
+	serv.auditBulk("rerun_failed", ids, req.DryRun, req.Requester)
+	return bulkResult(ids, req.DryRun), nil
+}
+
+// scheduledResources lists the resources of a provider that have a schedule.
+func (serv *MetadataServer) scheduledResources(provider string) ([]Resource, error) {
+	if _, err := serv.lookup.Lookup(ResourceID{Name: provider, Type: PROVIDER}); err != nil {
+		return nil, err
+	}
+	return serv.jobResources(nil, func(res Resource) bool {
+		return res.Schedule() != "" && resourceProvider(res) == provider
+	})
+}
+
+// BulkPauseSchedules pauses the schedule of every scheduled resource of a
+// provider. Like PauseSchedule, only the scheduled runs stop, so runs can
+// still be triggered while the provider is paused.
+func (serv *MetadataServer) BulkPauseSchedules(ctx context.Context, req *pb.BulkPauseSchedulesRequest) (*pb.BulkOperationResult, error) {
+	resources, err := serv.scheduledResources(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]ResourceID, len(resources))
+	for i, res := range resources {
+		ids[i] = res.ID()
+		if req.DryRun {
+			continue
+		}
+		if err := serv.lookup.PauseSchedule(ids[i], res.Schedule(), req.Reason, req.Requester); err != nil {
+			return nil, err
+		}
+	}
+	serv.auditBulk("pause_schedules", ids, req.DryRun, req.Requester)
+	return bulkResult(ids, req.DryRun), nil
+}
+
+// BulkResumeSchedules resumes the schedule of every scheduled resource of a
+// provider, undoing BulkPauseSchedules.
+func (serv *MetadataServer) BulkResumeSchedules(ctx context.Context, req *pb.BulkResumeSchedulesRequest) (*pb.BulkOperationResult, error) {
+	resources, err := serv.scheduledResources(req.Provider)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]ResourceID, len(resources))
+	for i, res := range resources {
+		ids[i] = res.ID()
+		if req.DryRun {
+			continue
+		}
+		if err := serv.lookup.ResumeSchedule(ids[i], res.Schedule()); err != nil {
+			return nil, err
+		}
+	}
+	serv.auditBulk("resume_schedules", ids, req.DryRun, req.Requester)
+	return bulkResult(ids, req.DryRun), nil
+}
+
+// BulkSetStatus sets the same status on every listed resource. All of them
+// must exist, so a typo doesn't leave the list half updated.
+func (serv *MetadataServer) BulkSetStatus(ctx context.Context, req *pb.BulkSetStatusRequest) (*pb.BulkOperationResult, error) {
+	ids := make([]ResourceID, len(req.Resources))
+	for i, res := range req.Resources {
+		ids[i] = ResourceID{Name: res.Resource.GetName(), Variant: res.Resource.GetVariant(), Type: ResourceType(res.ResourceType)}
+		if _, err := serv.lookup.Lookup(ids[i]); err != nil {
+			return nil, err
+		}
+	}
+	if !req.DryRun {
+		for _, id := range ids {
+			if err := serv.lookup.SetStatus(id, pb.ResourceStatus{Status: req.Status.GetStatus(), ErrorMessage: req.Status.GetErrorMessage(), ErrorCode: req.Status.GetErrorCode()}); err != nil {
+				return nil, err
+			}
+		}
+	}
+	serv.auditBulk("set_status", ids, req.DryRun, req.Requester)
+	return bulkResult(ids, req.DryRun), nil
+}
@@ -0,0 +1,215 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package catalog exports Featureform's resources to external data catalogs,
+// so that a company-wide catalog lists features, labels, sources and training
+// sets along with their owners, descriptions and lineage.
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+	"go.uber.org/zap"
+)
+
+// DatasetID identifies a resource variant in a catalog.
+type DatasetID struct {
+	Type    metadata.ResourceType
+	Name    string
+	Variant string
+}
+
+func (id DatasetID) String() string {
+	return fmt.Sprintf("%s.%s.%s", id.Type, id.Name, id.Variant)
+}
+
+// Dataset describes a resource variant as a catalog lists it.
+type Dataset struct {
+	DatasetID
+	Description string
+	Owner       string
+	// Upstream holds the datasets this one is computed from.
+	Upstream   []DatasetID
+	Properties map[string]string
+}
+
+// Catalog is an external data catalog that datasets are exported to.
+type Catalog interface {
+	// Sync creates or updates each dataset in the catalog.
+	Sync(ctx context.Context, datasets []Dataset) error
+}
+
+// Exporter syncs every resource variant in metadata to a catalog.
+type Exporter struct {
+	Metadata *metadata.Client
+	Catalog  Catalog
+	Logger   *zap.SugaredLogger
+}
+
+// Export syncs the current resource variants to the catalog.
+func (e *Exporter) Export(ctx context.Context) error {
+	datasets, err := e.datasets(ctx)
+	if err != nil {
+		return err
+	}
+	if err := e.Catalog.Sync(ctx, datasets); err != nil {
+		return fmt.Errorf("sync catalog: %w", err)
+	}
+	e.Logger.Infow("Exported resources to catalog", "datasets", len(datasets))
+	return nil
+}
+
+// ExportEvery exports to the catalog each interval until ctx is done. A
+// failed export is logged and tried again at the next interval.
+func (e *Exporter) ExportEvery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := e.Export(ctx); err != nil {
+			e.Logger.Errorw("Catalog export failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Exporter) datasets(ctx context.Context) ([]Dataset, error) {
+	var datasets []Dataset
+	sources, err := e.Metadata.ListSources(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list sources: %w", err)
+	}
+	for _, source := range sources {
+		variants, err := e.Metadata.GetSourceVariants(ctx, source.NameVariants())
+		if err != nil {
+			return nil, fmt.Errorf("get source variants of %s: %w", source.Name(), err)
+		}
+		for _, variant := range variants {
+			datasets = append(datasets, sourceDataset(variant))
+		}
+	}
+	features, err := e.Metadata.ListFeatures(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list features: %w", err)
+	}
+	for _, feature := range features {
+		variants, err := e.Metadata.GetFeatureVariants(ctx, feature.NameVariants())
+		if err != nil {
+			return nil, fmt.Errorf("get feature variants of %s: %w", feature.Name(), err)
+		}
+		for _, variant := range variants {
+			datasets = append(datasets, featureDataset(variant))
+		}
+	}
+	labels, err := e.Metadata.ListLabels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list labels: %w", err)
+	}
+	for _, label := range labels {
+		variants, err := e.Metadata.GetLabelVariants(ctx, label.NameVariants())
+		if err != nil {
+			return nil, fmt.Errorf("get label variants of %s: %w", label.Name(), err)
+		}
+		for _, variant := range variants {
+			datasets = append(datasets, labelDataset(variant))
+		}
+	}
+	trainingSets, err := e.Metadata.ListTrainingSets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list training sets: %w", err)
+	}
+	for _, ts := range trainingSets {
+		variants, err := e.Metadata.GetTrainingSetVariants(ctx, ts.NameVariants())
+		if err != nil {
+			return nil, fmt.Errorf("get training set variants of %s: %w", ts.Name(), err)
+		}
+		for _, variant := range variants {
+			datasets = append(datasets, trainingSetDataset(variant))
+		}
+	}
+	return datasets, nil
+}
+
+func sourceDataset(variant *metadata.SourceVariant) Dataset {
+	var upstream []DatasetID
+	if variant.IsSQLTransformation() {
+		for _, source := range variant.SQLTransformationSources() {
+			upstream = append(upstream, datasetID(metadata.SOURCE_VARIANT, source))
+		}
+	}
+	properties := map[string]string{
+		"provider": variant.Provider(),
+		"status":   variant.Status().String(),
+	}
+	if variant.IsPrimaryDataSQLTable() {
+		properties["table"] = variant.PrimaryDataSQLTableName()
+	}
+	if variant.IsSQLTransformation() {
+		properties["query"] = variant.SQLTransformationQuery()
+	}
+	return Dataset{
+		DatasetID:   DatasetID{Type: metadata.SOURCE_VARIANT, Name: variant.Name(), Variant: variant.Variant()},
+		Description: variant.Description(),
+		Owner:       variant.Owner(),
+		Upstream:    upstream,
+		Properties:  properties,
+	}
+}
+
+func featureDataset(variant *metadata.FeatureVariant) Dataset {
+	return Dataset{
+		DatasetID:   DatasetID{Type: metadata.FEATURE_VARIANT, Name: variant.Name(), Variant: variant.Variant()},
+		Description: variant.Description(),
+		Owner:       variant.Owner(),
+		Upstream:    []DatasetID{datasetID(metadata.SOURCE_VARIANT, variant.Source())},
+		Properties: map[string]string{
+			"entity":   variant.Entity(),
+			"type":     variant.Type(),
+			"provider": variant.Provider(),
+			"status":   variant.Status().String(),
+		},
+	}
+}
+
+func labelDataset(variant *metadata.LabelVariant) Dataset {
+	return Dataset{
+		DatasetID:   DatasetID{Type: metadata.LABEL_VARIANT, Name: variant.Name(), Variant: variant.Variant()},
+		Description: variant.Description(),
+		Owner:       variant.Owner(),
+		Upstream:    []DatasetID{datasetID(metadata.SOURCE_VARIANT, variant.Source())},
+		Properties: map[string]string{
+			"entity":   variant.Entity(),
+			"type":     variant.Type(),
+			"provider": variant.Provider(),
+			"status":   variant.Status().String(),
+		},
+	}
+}
+
+func trainingSetDataset(variant *metadata.TrainingSetVariant) Dataset {
+	upstream := []DatasetID{datasetID(metadata.LABEL_VARIANT, variant.Label())}
+	for _, feature := range variant.Features() {
+		upstream = append(upstream, datasetID(metadata.FEATURE_VARIANT, feature))
+	}
+	return Dataset{
+		DatasetID:   DatasetID{Type: metadata.TRAINING_SET_VARIANT, Name: variant.Name(), Variant: variant.Variant()},
+		Description: variant.Description(),
+		Owner:       variant.Owner(),
+		Upstream:    upstream,
+		Properties: map[string]string{
+			"provider": variant.Provider(),
+			"status":   variant.Status().String(),
+		},
+	}
+}
+
+func datasetID(t metadata.ResourceType, nv metadata.NameVariant) DatasetID {
+	return DatasetID{Type: t, Name: nv.Name, Variant: nv.Variant}
+}
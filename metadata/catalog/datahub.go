@@ -0,0 +1,132 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package catalog
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DataHub ingests datasets through the entity ingestion API of a DataHub
+// metadata service (GMS). Each resource variant becomes a dataset on the
+// featureform platform, with its owner, description and upstream lineage.
+type DataHub struct {
+	// URL is the address of the metadata service, such as
+	// http://datahub-gms:8080.
+	URL string
+	// Token is a personal access token, if the service requires one.
+	Token string
+	// Env is the fabric datasets are registered in, PROD by default.
+	Env    string
+	Client *http.Client
+}
+
+func NewDataHub(url, token string) *DataHub {
+	return &DataHub{
+		URL:    strings.TrimSuffix(url, "/"),
+		Token:  token,
+		Env:    "PROD",
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+const dataHubActor = "urn:li:corpuser:featureform"
+
+func (d *DataHub) urn(id DatasetID) string {
+	return fmt.Sprintf("urn:li:dataset:(urn:li:dataPlatform:featureform,%s,%s)", id, d.Env)
+}
+
+func (d *DataHub) Sync(ctx context.Context, datasets []Dataset) error {
+	for _, dataset := range datasets {
+		if err := d.ingest(ctx, dataset); err != nil {
+			return fmt.Errorf("ingest %s: %w", dataset.DatasetID, err)
+		}
+	}
+	return nil
+}
+
+// snapshot is a DatasetSnapshot with its aspects, in the JSON form of the
+// ingestion API.
+func (d *DataHub) snapshot(dataset Dataset) map[string]interface{} {
+	stamp := map[string]interface{}{"time": time.Now().UnixMilli(), "actor": dataHubActor}
+	properties := map[string]string{}
+	for key, value := range dataset.Properties {
+		properties[key] = value
+	}
+	aspects := []interface{}{
+		map[string]interface{}{
+			"com.linkedin.dataset.DatasetProperties": map[string]interface{}{
+				"name":             dataset.DatasetID.String(),
+				"description":      dataset.Description,
+				"customProperties": properties,
+			},
+		},
+	}
+	if dataset.Owner != "" {
+		aspects = append(aspects, map[string]interface{}{
+			"com.linkedin.common.Ownership": map[string]interface{}{
+				"owners": []interface{}{
+					map[string]interface{}{"owner": "urn:li:corpuser:" + dataset.Owner, "type": "DATAOWNER"},
+				},
+				"lastModified": stamp,
+			},
+		})
+	}
+	if len(dataset.Upstream) > 0 {
+		upstreams := make([]interface{}, len(dataset.Upstream))
+		for i, id := range dataset.Upstream {
+			upstreams[i] = map[string]interface{}{
+				"auditStamp": stamp,
+				"dataset":    d.urn(id),
+				"type":       "TRANSFORMED",
+			}
+		}
+		aspects = append(aspects, map[string]interface{}{
+			"com.linkedin.dataset.UpstreamLineage": map[string]interface{}{"upstreams": upstreams},
+		})
+	}
+	return map[string]interface{}{
+		"entity": map[string]interface{}{
+			"value": map[string]interface{}{
+				"com.linkedin.metadata.snapshot.DatasetSnapshot": map[string]interface{}{
+					"urn":     d.urn(dataset.DatasetID),
+					"aspects": aspects,
+				},
+			},
+		},
+	}
+}
+
+func (d *DataHub) ingest(ctx context.Context, dataset Dataset) error {
+	body, err := json.Marshal(d.snapshot(dataset))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL+"/entities?action=ingest", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-RestLi-Protocol-Version", "2.0.0")
+	if d.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+d.Token)
+	}
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("datahub returned %s: %s", resp.Status, msg)
+	}
+	return nil
+}
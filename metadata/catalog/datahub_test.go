@@ -0,0 +1,74 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/featureform/metadata"
+)
+
+func TestDataHubSync(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/entities" || r.URL.Query().Get("action") != "ingest" {
+			t.Errorf("Unexpected request to %s", r.URL)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization header is %q", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if !json.Valid(body) {
+			t.Errorf("Ingested invalid JSON: %s", body)
+		}
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+	source := DatasetID{Type: metadata.SOURCE_VARIANT, Name: "transactions", Variant: "default"}
+	datasets := []Dataset{
+		{DatasetID: source, Owner: "alice"},
+		{
+			DatasetID:   DatasetID{Type: metadata.FEATURE_VARIANT, Name: "avg_amount", Variant: "v1"},
+			Description: "Average transaction amount",
+			Owner:       "bob",
+			Upstream:    []DatasetID{source},
+			Properties:  map[string]string{"entity": "user"},
+		},
+	}
+	if err := NewDataHub(server.URL+"/", "token").Sync(context.Background(), datasets); err != nil {
+		t.Fatalf("Could not sync datasets: %v", err)
+	}
+	if len(bodies) != 2 {
+		t.Fatalf("Expected 2 datasets to be ingested, got %d", len(bodies))
+	}
+	feature := bodies[1]
+	for _, want := range []string{
+		"urn:li:dataset:(urn:li:dataPlatform:featureform,FEATURE_VARIANT.avg_amount.v1,PROD)",
+		"urn:li:corpuser:bob",
+		"Average transaction amount",
+		"com.linkedin.dataset.UpstreamLineage",
+		"urn:li:dataset:(urn:li:dataPlatform:featureform,SOURCE_VARIANT.transactions.default,PROD)",
+	} {
+		if !strings.Contains(feature, want) {
+			t.Fatalf("Ingested feature doesn't contain %s: %s", want, feature)
+		}
+	}
+	if strings.Contains(bodies[0], "UpstreamLineage") {
+		t.Fatalf("Source without upstreams was given lineage: %s", bodies[0])
+	}
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+	if err := NewDataHub(failing.URL, "").Sync(context.Background(), datasets); err == nil {
+		t.Fatalf("Sync succeeded despite DataHub rejecting it")
+	}
+}
@@ -0,0 +1,48 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/metadata/catalog"
+	"go.uber.org/zap"
+)
+
+func main() {
+	logger := zap.NewExample().Sugar()
+	metadataUrl := fmt.Sprintf("%s:%s", os.Getenv("METADATA_HOST"), os.Getenv("METADATA_PORT"))
+	client, err := metadata.NewClient(metadataUrl, logger)
+	if err != nil {
+		logger.Errorw("Could not connect to metadata: %v", err)
+		panic(err)
+	}
+	datahubUrl := os.Getenv("DATAHUB_URL")
+	if datahubUrl == "" {
+		panic(fmt.Errorf("DATAHUB_URL not set"))
+	}
+	datahub := catalog.NewDataHub(datahubUrl, os.Getenv("DATAHUB_TOKEN"))
+	if env := os.Getenv("DATAHUB_ENV"); env != "" {
+		datahub.Env = env
+	}
+	interval := time.Hour
+	if value := os.Getenv("CATALOG_EXPORT_INTERVAL"); value != "" {
+		interval, err = time.ParseDuration(value)
+		if err != nil {
+			logger.Errorw("Invalid catalog export interval: %v", err)
+			panic(err)
+		}
+	}
+	exporter := &catalog.Exporter{
+		Metadata: client,
+		Catalog:  datahub,
+		Logger:   logger,
+	}
+	exporter.ExportEvery(context.Background(), interval)
+}
@@ -0,0 +1,197 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package chatops answers chat commands about Featureform resources, such as
+// "/featureform status my_feature" in Slack, so that the state of a feature
+// can be checked from the channels on-call already works in.
+package chatops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+	"go.uber.org/zap"
+)
+
+// Status is the current state of a resource variant.
+type Status struct {
+	Type    metadata.ResourceType
+	Name    string
+	Variant string
+	Status  metadata.ResourceStatus
+	Error   string
+	// LastWritten is when a feature's online values were last written. It's
+	// zero for other resources, or if the feature hasn't been materialized.
+	LastWritten time.Time
+}
+
+// StatusSource looks up the status of resources by name.
+type StatusSource interface {
+	// Status returns the status of the named resource's variant, or of its
+	// default variant if variant is empty.
+	Status(ctx context.Context, name, variant string) (Status, error)
+}
+
+// MetadataStatus looks resources up in metadata. A name is checked against
+// features, then labels, training sets and sources.
+type MetadataStatus struct {
+	Metadata  *metadata.Client
+	Logger    *zap.SugaredLogger
+	providers *provider.Cache
+}
+
+func NewMetadataStatus(client *metadata.Client, logger *zap.SugaredLogger) *MetadataStatus {
+	return &MetadataStatus{
+		Metadata:  client,
+		Logger:    logger,
+		providers: provider.NewCache(),
+	}
+}
+
+func (s *MetadataStatus) Status(ctx context.Context, name, variant string) (Status, error) {
+	if feature, err := s.Metadata.GetFeature(ctx, name); err == nil {
+		return s.featureStatus(ctx, name, variantOr(variant, feature.DefaultVariant()))
+	}
+	if label, err := s.Metadata.GetLabel(ctx, name); err == nil {
+		v, err := s.Metadata.GetLabelVariant(ctx, metadata.NameVariant{Name: name, Variant: variantOr(variant, label.DefaultVariant())})
+		if err != nil {
+			return Status{}, err
+		}
+		return Status{Type: metadata.LABEL_VARIANT, Name: v.Name(), Variant: v.Variant(), Status: v.Status(), Error: v.Error()}, nil
+	}
+	if ts, err := s.Metadata.GetTrainingSet(ctx, name); err == nil {
+		v, err := s.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variantOr(variant, ts.DefaultVariant())})
+		if err != nil {
+			return Status{}, err
+		}
+		return Status{Type: metadata.TRAINING_SET_VARIANT, Name: v.Name(), Variant: v.Variant(), Status: v.Status(), Error: v.Error()}, nil
+	}
+	if source, err := s.Metadata.GetSource(ctx, name); err == nil {
+		v, err := s.Metadata.GetSourceVariant(ctx, metadata.NameVariant{Name: name, Variant: variantOr(variant, source.DefaultVariant())})
+		if err != nil {
+			return Status{}, err
+		}
+		return Status{Type: metadata.SOURCE_VARIANT, Name: v.Name(), Variant: v.Variant(), Status: v.Status(), Error: v.Error()}, nil
+	}
+	return Status{}, fmt.Errorf("no resource named %s", name)
+}
+
+func (s *MetadataStatus) featureStatus(ctx context.Context, name, variant string) (Status, error) {
+	v, err := s.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		return Status{}, err
+	}
+	status := Status{Type: metadata.FEATURE_VARIANT, Name: v.Name(), Variant: v.Variant(), Status: v.Status(), Error: v.Error()}
+	if v.Status() != metadata.READY {
+		return status, nil
+	}
+	written, err := s.lastWritten(ctx, v)
+	if err != nil {
+		// The status is still worth answering with.
+		s.Logger.Errorw("Failed to get last written time", "Name", name, "Variant", variant, "Error", err)
+		return status, nil
+	}
+	status.LastWritten = written
+	return status, nil
+}
+
+func (s *MetadataStatus) lastWritten(ctx context.Context, variant *metadata.FeatureVariant) (time.Time, error) {
+	providerEntry, err := variant.FetchProvider(s.Metadata, ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fetch provider: %w", err)
+	}
+	p, err := s.providers.Get(providerEntry.Name(), provider.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get provider: %w", err)
+	}
+	store, err := p.AsOnlineStore()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return provider.LastWritten(store, variant.Name(), variant.Variant())
+}
+
+func variantOr(variant, defaultVariant string) string {
+	if variant != "" {
+		return variant
+	}
+	return defaultVariant
+}
+
+const usage = "Usage: status <name> [variant]"
+
+// Responder answers the text of chat commands.
+type Responder struct {
+	Statuses StatusSource
+	Logger   *zap.SugaredLogger
+	// Now returns the current time, for reporting how stale values are.
+	Now func() time.Time
+}
+
+// Respond returns the reply to a command's text, such as "status my_feature"
+// or "status my_feature v2".
+func (r *Responder) Respond(ctx context.Context, text string) string {
+	args := strings.Fields(text)
+	if len(args) == 0 || args[0] == "help" {
+		return usage
+	}
+	switch args[0] {
+	case "status":
+		if len(args) < 2 || len(args) > 3 {
+			return usage
+		}
+		var variant string
+		if len(args) == 3 {
+			variant = args[2]
+		}
+		status, err := r.Statuses.Status(ctx, args[1], variant)
+		if err != nil {
+			r.Logger.Errorw("Failed to get status", "Name", args[1], "Variant", variant, "Error", err)
+			return fmt.Sprintf("Could not get the status of %s: %v", args[1], err)
+		}
+		return r.format(status)
+	default:
+		return fmt.Sprintf("Unknown command %q. %s", args[0], usage)
+	}
+}
+
+func (r *Responder) format(status Status) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s (%s): %s", typeName(status.Type), status.Name, status.Variant, status.Status)
+	if status.Type == metadata.FEATURE_VARIANT {
+		if status.LastWritten.IsZero() {
+			b.WriteString("\nFreshness: unknown")
+		} else {
+			now := time.Now
+			if r.Now != nil {
+				now = r.Now
+			}
+			age := now().Sub(status.LastWritten).Round(time.Second)
+			fmt.Fprintf(&b, "\nFreshness: last written %s ago (%s)", age, status.LastWritten.UTC().Format(time.RFC3339))
+		}
+	}
+	if status.Error != "" {
+		fmt.Fprintf(&b, "\nLast error: %s", status.Error)
+	}
+	return b.String()
+}
+
+func typeName(t metadata.ResourceType) string {
+	switch t {
+	case metadata.FEATURE_VARIANT:
+		return "Feature"
+	case metadata.LABEL_VARIANT:
+		return "Label"
+	case metadata.TRAINING_SET_VARIANT:
+		return "Training set"
+	case metadata.SOURCE_VARIANT:
+		return "Source"
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,136 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package chatops
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/featureform/metadata"
+	"go.uber.org/zap"
+)
+
+type fakeStatuses map[string]Status
+
+func (f fakeStatuses) Status(ctx context.Context, name, variant string) (Status, error) {
+	status, has := f[name]
+	if !has {
+		return Status{}, fmt.Errorf("no resource named %s", name)
+	}
+	return status, nil
+}
+
+func testResponder() *Responder {
+	written := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	return &Responder{
+		Statuses: fakeStatuses{
+			"my_feature": {
+				Type:        metadata.FEATURE_VARIANT,
+				Name:        "my_feature",
+				Variant:     "v1",
+				Status:      metadata.READY,
+				LastWritten: written,
+			},
+			"broken": {
+				Type:    metadata.SOURCE_VARIANT,
+				Name:    "broken",
+				Variant: "default",
+				Status:  metadata.FAILED,
+				Error:   "table not found",
+			},
+		},
+		Logger: zap.NewNop().Sugar(),
+		Now:    func() time.Time { return written.Add(90 * time.Minute) },
+	}
+}
+
+func TestRespond(t *testing.T) {
+	r := testResponder()
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"status my_feature", []string{"Feature my_feature (v1): READY", "last written 1h30m0s ago"}},
+		{"status broken", []string{"Source broken (default): FAILED", "Last error: table not found"}},
+		{"status missing", []string{"Could not get the status of missing"}},
+		{"", []string{usage}},
+		{"restart my_feature", []string{"Unknown command"}},
+	}
+	for _, test := range tests {
+		reply := r.Respond(context.Background(), test.text)
+		for _, want := range test.want {
+			if !strings.Contains(reply, want) {
+				t.Fatalf("Reply to %q is %q, expected it to contain %q", test.text, reply, want)
+			}
+		}
+	}
+}
+
+func TestSlackHandler(t *testing.T) {
+	h := &SlackHandler{Responder: testResponder(), SigningSecret: "secret"}
+	body := url.Values{"command": {"/featureform"}, "text": {"status my_feature"}}.Encode()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+	signature := "v0=" + hex.EncodeToString(mac.Sum(nil))
+
+	for _, sig := range []string{signature, "v0=bad"} {
+		req := httptest.NewRequest(http.MethodPost, "/slack", strings.NewReader(body))
+		req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+		req.Header.Set("X-Slack-Signature", sig)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if sig != signature {
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("Expected a bad signature to be rejected, got %d", rec.Code)
+			}
+			continue
+		}
+		if rec.Code != http.StatusOK {
+			t.Fatalf("Expected OK, got %d: %s", rec.Code, rec.Body)
+		}
+		var resp map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("Could not parse response: %v", err)
+		}
+		if !strings.Contains(resp["text"], "READY") {
+			t.Fatalf("Unexpected response %v", resp)
+		}
+	}
+}
+
+func TestTeamsHandler(t *testing.T) {
+	key := []byte("teams-secret")
+	h := &TeamsHandler{Responder: testResponder(), Secret: base64.StdEncoding.EncodeToString(key)}
+	body := `{"type":"message","text":"<at>featureform</at> status broken"}`
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(body))
+	req := httptest.NewRequest(http.MethodPost, "/teams", strings.NewReader(body))
+	req.Header.Set("Authorization", "HMAC "+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected OK, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Could not parse response: %v", err)
+	}
+	if !strings.Contains(resp["text"], "FAILED<br>Last error: table not found") {
+		t.Fatalf("Unexpected response %v", resp)
+	}
+}
@@ -0,0 +1,50 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/metadata/chatops"
+	"go.uber.org/zap"
+)
+
+func main() {
+	logger := zap.NewExample().Sugar()
+	metadataUrl := fmt.Sprintf("%s:%s", os.Getenv("METADATA_HOST"), os.Getenv("METADATA_PORT"))
+	client, err := metadata.NewClient(metadataUrl, logger)
+	if err != nil {
+		logger.Errorw("Could not connect to metadata: %v", err)
+		panic(err)
+	}
+	responder := &chatops.Responder{
+		Statuses: chatops.NewMetadataStatus(client, logger),
+		Logger:   logger,
+	}
+	mux := http.NewServeMux()
+	slackSecret := os.Getenv("SLACK_SIGNING_SECRET")
+	if slackSecret != "" {
+		mux.Handle("/slack", &chatops.SlackHandler{Responder: responder, SigningSecret: slackSecret})
+	}
+	teamsSecret := os.Getenv("TEAMS_WEBHOOK_SECRET")
+	if teamsSecret != "" {
+		mux.Handle("/teams", &chatops.TeamsHandler{Responder: responder, Secret: teamsSecret})
+	}
+	if slackSecret == "" && teamsSecret == "" {
+		panic(fmt.Errorf("neither SLACK_SIGNING_SECRET nor TEAMS_WEBHOOK_SECRET is set"))
+	}
+	port := os.Getenv("CHATOPS_PORT")
+	if port == "" {
+		port = "8080"
+	}
+	logger.Infow("Serving chat commands", "Port", port)
+	if err := http.ListenAndServe(":"+port, mux); err != nil {
+		logger.Errorw("Chat command server stopped: %v", err)
+		panic(err)
+	}
+}
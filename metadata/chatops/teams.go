@@ -0,0 +1,77 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package chatops
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// mention matches the <at>name</at> tags Teams puts around mentions of the
+// webhook in a message.
+var mention = regexp.MustCompile(`<at>[^<]*</at>`)
+
+// TeamsHandler answers messages sent to a Teams outgoing webhook, such as
+// "@featureform status my_feature". Requests are verified with the
+// webhook's security token, which Teams shows base64 encoded.
+type TeamsHandler struct {
+	Responder *Responder
+	Secret    string
+}
+
+func (h *TeamsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, "could not read request", http.StatusBadRequest)
+		return
+	}
+	if err := h.verify(r.Header.Get("Authorization"), body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var activity struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(body, &activity); err != nil {
+		http.Error(w, "invalid message", http.StatusBadRequest)
+		return
+	}
+	text := mention.ReplaceAllString(activity.Text, "")
+	reply := h.Responder.Respond(r.Context(), text)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"type": "message",
+		"text": strings.ReplaceAll(reply, "\n", "<br>"),
+	})
+}
+
+func (h *TeamsHandler) verify(auth string, body []byte) error {
+	key, err := base64.StdEncoding.DecodeString(h.Secret)
+	if err != nil {
+		return fmt.Errorf("invalid webhook secret")
+	}
+	signature := strings.TrimPrefix(auth, "HMAC ")
+	if signature == auth {
+		return fmt.Errorf("missing HMAC authorization")
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	expected := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
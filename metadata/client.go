@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"sort"
 	"time"
 
 	pb "github.com/featureform/metadata/proto"
@@ -17,6 +18,7 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	durpb "google.golang.org/protobuf/types/known/durationpb"
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -89,15 +91,247 @@ func (client *Client) RequestScheduleChange(ctx context.Context, resID ResourceI
 }
 
 func (client *Client) SetStatus(ctx context.Context, resID ResourceID, status ResourceStatus, errorMessage string) error {
+	return client.SetStatusWithCode(ctx, resID, status, "", errorMessage)
+}
+
+// SetStatusWithCode sets a resource's status along with the code of the error
+// in its message, if it has one.
+func (client *Client) SetStatusWithCode(ctx context.Context, resID ResourceID, status ResourceStatus, code ErrorCode, errorMessage string) error {
+	nameVariant := pb.NameVariant{Name: resID.Name, Variant: resID.Variant}
+	resourceID := pb.ResourceID{Resource: &nameVariant, ResourceType: resID.Type.Serialized()}
+	resourceStatus := pb.ResourceStatus{Status: pb.ResourceStatus_Status(status), ErrorMessage: errorMessage, ErrorCode: string(code)}
+	statusRequest := pb.SetStatusRequest{ResourceId: &resourceID, Status: &resourceStatus}
+	_, err := client.grpcConn.SetResourceStatus(ctx, &statusRequest)
+	return err
+}
+
+// JobProgress is how far along the job of a PENDING resource is. Fields
+// that the job can't tell are zero.
+type JobProgress struct {
+	RowsCopied  int64
+	TotalRows   int64
+	ChunksDone  int
+	TotalChunks int
+	ETA         time.Duration
+}
+
+func (progress JobProgress) Serialize() *pb.JobProgress {
+	serialized := &pb.JobProgress{
+		RowsCopied:  progress.RowsCopied,
+		TotalRows:   progress.TotalRows,
+		ChunksDone:  int32(progress.ChunksDone),
+		TotalChunks: int32(progress.TotalChunks),
+	}
+	if progress.ETA > 0 {
+		serialized.Eta = durpb.New(progress.ETA)
+	}
+	return serialized
+}
+
+// SetProgress records how far along a resource's job is. The resource stays
+// PENDING, and the progress is cleared once its status is next set.
+func (client *Client) SetProgress(ctx context.Context, resID ResourceID, progress JobProgress) error {
 	nameVariant := pb.NameVariant{Name: resID.Name, Variant: resID.Variant}
 	resourceID := pb.ResourceID{Resource: &nameVariant, ResourceType: resID.Type.Serialized()}
-	resourceStatus := pb.ResourceStatus{Status: pb.ResourceStatus_Status(status), ErrorMessage: errorMessage}
+	resourceStatus := pb.ResourceStatus{Status: pb.ResourceStatus_PENDING, Progress: progress.Serialize()}
 	statusRequest := pb.SetStatusRequest{ResourceId: &resourceID, Status: &resourceStatus}
 	_, err := client.grpcConn.SetResourceStatus(ctx, &statusRequest)
 	return err
 }
 
+// BulkFilter selects resources for a bulk operation. Empty fields match
+// everything.
+type BulkFilter struct {
+	Types      []ResourceType
+	Provider   string
+	NamePrefix string
+}
+
+func (filter BulkFilter) serialize() *pb.BulkFilter {
+	types := make([]pb.ResourceType, len(filter.Types))
+	for i, t := range filter.Types {
+		types[i] = t.Serialized()
+	}
+	return &pb.BulkFilter{Types: types, Provider: filter.Provider, NamePrefix: filter.NamePrefix}
+}
+
+func parseBulkResult(result *pb.BulkOperationResult) []ResourceID {
+	ids := make([]ResourceID, len(result.Resources))
+	for i, res := range result.Resources {
+		ids[i] = ResourceID{Name: res.Resource.GetName(), Variant: res.Resource.GetVariant(), Type: ResourceType(res.ResourceType)}
+	}
+	return ids
+}
+
+// BulkRerunFailed reruns every FAILED resource that matches filter and returns
+// them. If dryRun is set, the resources are returned without being rerun.
+func (client *Client) BulkRerunFailed(ctx context.Context, filter BulkFilter, dryRun bool, requester string) ([]ResourceID, error) {
+	req := pb.BulkRerunRequest{Filter: filter.serialize(), DryRun: dryRun, Requester: requester}
+	result, err := client.grpcConn.BulkRerunFailed(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return parseBulkResult(result), nil
+}
+
+// BulkPauseSchedules pauses the scheduled runs of every resource on a
+// provider and returns them. If dryRun is set, nothing is paused.
+func (client *Client) BulkPauseSchedules(ctx context.Context, provider, reason string, dryRun bool, requester string) ([]ResourceID, error) {
+	req := pb.BulkPauseSchedulesRequest{Provider: provider, Reason: reason, DryRun: dryRun, Requester: requester}
+	result, err := client.grpcConn.BulkPauseSchedules(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return parseBulkResult(result), nil
+}
+
+// BulkResumeSchedules resumes the scheduled runs of every resource on a
+// provider and returns them. If dryRun is set, nothing is resumed.
+func (client *Client) BulkResumeSchedules(ctx context.Context, provider string, dryRun bool, requester string) ([]ResourceID, error) {
+	req := pb.BulkResumeSchedulesRequest{Provider: provider, DryRun: dryRun, Requester: requester}
+	result, err := client.grpcConn.BulkResumeSchedules(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return parseBulkResult(result), nil
+}
+
+// BulkSetStatus sets status on each of ids. If dryRun is set, the ids are
+// only checked to exist.
+func (client *Client) BulkSetStatus(ctx context.Context, ids []ResourceID, status ResourceStatus, errorMessage string, dryRun bool, requester string) ([]ResourceID, error) {
+	resources := make([]*pb.ResourceID, len(ids))
+	for i, id := range ids {
+		resources[i] = &pb.ResourceID{Resource: &pb.NameVariant{Name: id.Name, Variant: id.Variant}, ResourceType: id.Type.Serialized()}
+	}
+	req := pb.BulkSetStatusRequest{
+		Resources: resources,
+		Status:    &pb.ResourceStatus{Status: status.Serialized(), ErrorMessage: errorMessage},
+		DryRun:    dryRun,
+		Requester: requester,
+	}
+	result, err := client.grpcConn.BulkSetStatus(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	return parseBulkResult(result), nil
+}
+
+// CancelJob stops the running job of a resource and marks it CANCELLED.
+func (client *Client) CancelJob(ctx context.Context, id ResourceID, requester string) error {
+	req := pb.CancelJobRequest{
+		Resource:  &pb.ResourceID{Resource: &pb.NameVariant{Name: id.Name, Variant: id.Variant}, ResourceType: id.Type.Serialized()},
+		Requester: requester,
+	}
+	_, err := client.grpcConn.CancelJob(ctx, &req)
+	return err
+}
+
+// TriggerRun runs the job of a ready feature, transformation or training set
+// now, outside its schedule. The run is recorded as a manual run in the
+// resource's job history.
+func (client *Client) TriggerRun(ctx context.Context, id ResourceID, requester string) error {
+	req := pb.TriggerRunRequest{
+		Resource:  &pb.ResourceID{Resource: &pb.NameVariant{Name: id.Name, Variant: id.Variant}, ResourceType: id.Type.Serialized()},
+		Requester: requester,
+	}
+	_, err := client.grpcConn.TriggerRun(ctx, &req)
+	return err
+}
+
+// PauseSchedule suspends the scheduled runs of a resource until
+// ResumeSchedule is called. Its schedule is kept.
+func (client *Client) PauseSchedule(ctx context.Context, id ResourceID, reason, requester string) error {
+	req := pb.PauseScheduleRequest{
+		Resource:  &pb.ResourceID{Resource: &pb.NameVariant{Name: id.Name, Variant: id.Variant}, ResourceType: id.Type.Serialized()},
+		Reason:    reason,
+		Requester: requester,
+	}
+	_, err := client.grpcConn.PauseSchedule(ctx, &req)
+	return err
+}
+
+// GetJobRuns returns the runs of a resource's jobs, oldest first.
+func (client *Client) GetJobRuns(ctx context.Context, id ResourceID) ([]JobRun, error) {
+	req := pb.ResourceID{Resource: &pb.NameVariant{Name: id.Name, Variant: id.Variant}, ResourceType: id.Type.Serialized()}
+	list, err := client.grpcConn.GetJobRuns(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]JobRun, len(list.GetRuns()))
+	for i, run := range list.GetRuns() {
+		runs[i] = parseJobRun(run)
+	}
+	return runs, nil
+}
+
+// ResumeSchedule resumes the scheduled runs of a paused resource.
+func (client *Client) ResumeSchedule(ctx context.Context, id ResourceID, requester string) error {
+	req := pb.ResumeScheduleRequest{
+		Resource:  &pb.ResourceID{Resource: &pb.NameVariant{Name: id.Name, Variant: id.Variant}, ResourceType: id.Type.Serialized()},
+		Requester: requester,
+	}
+	_, err := client.grpcConn.ResumeSchedule(ctx, &req)
+	return err
+}
+
+// UpdateProviderConfig replaces the serialized config of an existing provider,
+// typically to rotate its credentials. The server validates the new config
+// before storing it.
+func (client *Client) UpdateProviderConfig(ctx context.Context, name string, config []byte, requester string) error {
+	req := pb.ProviderConfigUpdate{Name: name, SerializedConfig: config, Requester: requester}
+	_, err := client.grpcConn.UpdateProviderConfig(ctx, &req)
+	return err
+}
+
+// UpdateFeatureVariantProvider switches the online provider a feature variant
+// is served from.
+func (client *Client) UpdateFeatureVariantProvider(ctx context.Context, id NameVariant, provider, requester string) error {
+	req := pb.FeatureProviderUpdate{Feature: id.Serialize(), Provider: provider, Requester: requester}
+	_, err := client.grpcConn.UpdateFeatureVariantProvider(ctx, &req)
+	return err
+}
+
+// TableStats describe the offline table behind a source or feature variant.
+// SizeBytes is negative if the provider can't tell.
+type TableStats struct {
+	NumRows    int64
+	SizeBytes  int64
+	MinTS      time.Time
+	MaxTS      time.Time
+	NullCounts map[string]int64
+	Computed   time.Time
+}
+
+func (stats TableStats) Serialize() *pb.TableStats {
+	columns := make([]*pb.ColumnStats, 0, len(stats.NullCounts))
+	for name, nulls := range stats.NullCounts {
+		columns = append(columns, &pb.ColumnStats{Name: name, NullCount: nulls})
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].Name < columns[j].Name })
+	return &pb.TableStats{
+		NumRows:   stats.NumRows,
+		SizeBytes: stats.SizeBytes,
+		MinTs:     tspb.New(stats.MinTS),
+		MaxTs:     tspb.New(stats.MaxTS),
+		Columns:   columns,
+		Computed:  tspb.New(stats.Computed),
+	}
+}
+
+// SetStats records the latest stats of a source or feature variant's table.
+func (client *Client) SetStats(ctx context.Context, resID ResourceID, stats TableStats) error {
+	nameVariant := pb.NameVariant{Name: resID.Name, Variant: resID.Variant}
+	resourceID := pb.ResourceID{Resource: &nameVariant, ResourceType: resID.Type.Serialized()}
+	_, err := client.grpcConn.SetResourceStats(ctx, &pb.SetStatsRequest{ResourceId: &resourceID, Stats: stats.Serialize()})
+	return err
+}
+
 func (client *Client) CreateAll(ctx context.Context, defs []ResourceDef) error {
+	// Schedules are checked up front so that a bad one doesn't leave the
+	// resources before it half-applied.
+	if err := validateSchedules(defs); err != nil {
+		return err
+	}
 	for _, def := range defs {
 		if err := client.Create(ctx, def); err != nil {
 			return err
@@ -203,6 +437,16 @@ type FeatureDef struct {
 	Provider    string
 	Schedule    string
 	Location    interface{}
+	// MockValue is served instead of the stored value when serving runs in
+	// sandbox mode. It's parsed according to Type.
+	MockValue string
+	Priority  Priority
+	// Scopes are what a caller needs to serve the feature online, for
+	// features that not everyone should read, like credit scores.
+	Scopes []string
+	// RefreshOnUpstreamChange updates the feature each time its source is,
+	// rather than only on its schedule.
+	RefreshOnUpstreamChange bool
 }
 
 type ResourceVariantColumns struct {
@@ -237,6 +481,15 @@ func (def FeatureDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateFeatureVariant(ctx context.Context, def FeatureDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateFeatureVariant(ctx, serialized)
+	return err
+}
+
+func (def FeatureDef) serialize() (*pb.FeatureVariant, error) {
 	serialized := &pb.FeatureVariant{
 		Name:        def.Name,
 		Variant:     def.Variant,
@@ -248,17 +501,21 @@ func (client *Client) CreateFeatureVariant(ctx context.Context, def FeatureDef)
 		Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
 		Provider:    def.Provider,
 		Schedule:    def.Schedule,
+		MockValue:   def.MockValue,
+		Priority:    int32(def.Priority),
+		Scopes:      def.Scopes,
+
+		RefreshOnUpstreamChange: def.RefreshOnUpstreamChange,
 	}
 	switch x := def.Location.(type) {
 	case ResourceVariantColumns:
 		serialized.Location = def.Location.(ResourceVariantColumns).SerializeFeatureColumns()
 	case nil:
-		return fmt.Errorf("FeatureDef Columns not set")
+		return nil, fmt.Errorf("FeatureDef Columns not set")
 	default:
-		return fmt.Errorf("FeatureDef Columns has unexpected type %T", x)
+		return nil, fmt.Errorf("FeatureDef Columns has unexpected type %T", x)
 	}
-	_, err := client.grpcConn.CreateFeatureVariant(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 type featureStream interface {
@@ -340,6 +597,13 @@ type LabelDef struct {
 	Owner       string
 	Provider    string
 	Location    interface{}
+	Priority    Priority
+	// OnlineProvider, if set, is the online store the label is materialized
+	// to so that it can be served.
+	OnlineProvider string
+	// Scopes are what a caller needs to serve the label, online or in a
+	// training set.
+	Scopes []string
 }
 
 func (def LabelDef) ResourceType() ResourceType {
@@ -347,27 +611,38 @@ func (def LabelDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateLabelVariant(ctx context.Context, def LabelDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateLabelVariant(ctx, serialized)
+	return err
+}
+
+func (def LabelDef) serialize() (*pb.LabelVariant, error) {
 	serialized := &pb.LabelVariant{
-		Name:        def.Name,
-		Variant:     def.Variant,
-		Description: def.Description,
-		Type:        def.Type,
-		Source:      def.Source.Serialize(),
-		Entity:      def.Entity,
-		Owner:       def.Owner,
-		Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS},
-		Provider:    def.Provider,
+		Name:           def.Name,
+		Variant:        def.Variant,
+		Description:    def.Description,
+		Type:           def.Type,
+		Source:         def.Source.Serialize(),
+		Entity:         def.Entity,
+		Owner:          def.Owner,
+		Status:         &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS},
+		Provider:       def.Provider,
+		Priority:       int32(def.Priority),
+		OnlineProvider: def.OnlineProvider,
+		Scopes:         def.Scopes,
 	}
 	switch x := def.Location.(type) {
 	case ResourceVariantColumns:
 		serialized.Location = def.Location.(ResourceVariantColumns).SerializeLabelColumns()
 	case nil:
-		return fmt.Errorf("LabelDef Primary not set")
+		return nil, fmt.Errorf("LabelDef Primary not set")
 	default:
-		return fmt.Errorf("LabelDef Primary has unexpected type %T", x)
+		return nil, fmt.Errorf("LabelDef Primary has unexpected type %T", x)
 	}
-	_, err := client.grpcConn.CreateLabelVariant(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 func (client *Client) GetLabelVariants(ctx context.Context, ids []NameVariant) ([]*LabelVariant, error) {
@@ -473,6 +748,36 @@ type TrainingSetDef struct {
 	Schedule    string
 	Label       NameVariant
 	Features    NameVariants
+	// PinDependencies pins the features and label to the versions registered
+	// now. The training set won't build if any of them is replaced later.
+	PinDependencies bool
+	Priority        Priority
+	// RefreshOnUpstreamChange rebuilds the training set each time one of its
+	// features or its label's source is updated.
+	RefreshOnUpstreamChange bool
+	// Export writes the training set's rows as files each time it's built.
+	Export *TrainingSetExport
+}
+
+// TrainingSetExport is where and in which format a training set's rows are
+// written as files.
+type TrainingSetExport struct {
+	// URI is an archive URI, such as s3://bucket/prefix.
+	URI string
+	// Format is PARQUET, CSV or TFRECORD.
+	Format      string
+	RowsPerFile int
+}
+
+func (export *TrainingSetExport) serialize() *pb.TrainingSetExport {
+	if export == nil {
+		return nil
+	}
+	return &pb.TrainingSetExport{
+		Uri:         export.URI,
+		Format:      export.Format,
+		RowsPerFile: int32(export.RowsPerFile),
+	}
 }
 
 func (def TrainingSetDef) ResourceType() ResourceType {
@@ -480,21 +785,29 @@ func (def TrainingSetDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateTrainingSetVariant(ctx context.Context, def TrainingSetDef) error {
-	serialized := &pb.TrainingSetVariant{
-		Name:        def.Name,
-		Variant:     def.Variant,
-		Description: def.Description,
-		Owner:       def.Owner,
-		Provider:    def.Provider,
-		Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
-		Label:       def.Label.Serialize(),
-		Features:    def.Features.Serialize(),
-		Schedule:    def.Schedule,
-	}
-	_, err := client.grpcConn.CreateTrainingSetVariant(ctx, serialized)
+	_, err := client.grpcConn.CreateTrainingSetVariant(ctx, def.serialize())
 	return err
 }
 
+func (def TrainingSetDef) serialize() *pb.TrainingSetVariant {
+	return &pb.TrainingSetVariant{
+		Name:            def.Name,
+		Variant:         def.Variant,
+		Description:     def.Description,
+		Owner:           def.Owner,
+		Provider:        def.Provider,
+		Status:          &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
+		Label:           def.Label.Serialize(),
+		Features:        def.Features.Serialize(),
+		Schedule:        def.Schedule,
+		PinDependencies: def.PinDependencies,
+		Priority:        int32(def.Priority),
+
+		RefreshOnUpstreamChange: def.RefreshOnUpstreamChange,
+		Export:                  def.Export.serialize(),
+	}
+}
+
 func (client *Client) GetTrainingSetVariant(ctx context.Context, id NameVariant) (*TrainingSetVariant, error) {
 	variants, err := client.GetTrainingSetVariants(ctx, []NameVariant{id})
 	if err != nil {
@@ -597,6 +910,18 @@ type SourceDef struct {
 	Provider    string
 	Schedule    string
 	Definition  SourceType
+	Priority    Priority
+	// SlowlyChanging is set if the source is a slowly changing dimension
+	// table. Its features and labels take their timestamps from its valid
+	// from column.
+	SlowlyChanging *SlowlyChangingDimension
+}
+
+// SlowlyChangingDimension names the columns of an SCD2 table that bound when
+// each of its rows was valid.
+type SlowlyChangingDimension struct {
+	ValidFrom string
+	ValidTo   string
 }
 
 type SourceType interface {
@@ -619,6 +944,28 @@ func (t SQLTable) isPrimaryData() bool {
 
 type TransformationSource struct {
 	TransformationType TransformationType
+	// Storage is how the result is stored. It's copied into a table unless
+	// it's set to a view.
+	Storage TransformationStorage
+}
+
+// TransformationStorage is how a transformation's result is stored in its
+// provider.
+type TransformationStorage int32
+
+const (
+	// TransformationTable copies the result into a table of its own.
+	TransformationTable TransformationStorage = TransformationStorage(pb.Transformation_TABLE)
+	// TransformationView registers the query as a view, which is run each
+	// time the transformation is read.
+	TransformationView = TransformationStorage(pb.Transformation_VIEW)
+	// TransformationMaterializedView registers the query as a materialized
+	// view, where the provider supports them, which is refreshed by each run.
+	TransformationMaterializedView = TransformationStorage(pb.Transformation_MATERIALIZED_VIEW)
+)
+
+func (s TransformationStorage) String() string {
+	return pb.Transformation_Storage_name[int32(s)]
 }
 
 type TransformationType interface {
@@ -628,6 +975,8 @@ type TransformationType interface {
 type SQLTransformationType struct {
 	Query   string
 	Sources NameVariants
+	// Parameters are the values of the query's {{ param "name" }} templates.
+	Parameters map[string]string
 }
 
 type PrimaryDataSource struct {
@@ -653,8 +1002,9 @@ func (s TransformationSource) Serialize() (*pb.SourceVariant_Transformation, err
 		transformation = &pb.Transformation{
 			Type: &pb.Transformation_SQLTransformation{
 				SQLTransformation: &pb.SQLTransformation{
-					Query:  s.TransformationType.(SQLTransformationType).Query,
-					Source: s.TransformationType.(SQLTransformationType).Sources.Serialize(),
+					Query:      s.TransformationType.(SQLTransformationType).Query,
+					Source:     s.TransformationType.(SQLTransformationType).Sources.Serialize(),
+					Parameters: s.TransformationType.(SQLTransformationType).Parameters,
 				},
 			},
 		}
@@ -663,6 +1013,7 @@ func (s TransformationSource) Serialize() (*pb.SourceVariant_Transformation, err
 	default:
 		return nil, fmt.Errorf("TransformationSource Type has unexpected type %T", x)
 	}
+	transformation.Storage = pb.Transformation_Storage(s.Storage)
 	return &pb.SourceVariant_Transformation{
 		Transformation: transformation,
 	}, nil
@@ -694,6 +1045,15 @@ func (def SourceDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) error {
+	serialized, err := def.serialize()
+	if err != nil {
+		return err
+	}
+	_, err = client.grpcConn.CreateSourceVariant(ctx, serialized)
+	return err
+}
+
+func (def SourceDef) serialize() (*pb.SourceVariant, error) {
 	serialized := &pb.SourceVariant{
 		Name:        def.Name,
 		Variant:     def.Variant,
@@ -702,6 +1062,13 @@ func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) er
 		Status:      &pb.ResourceStatus{Status: pb.ResourceStatus_CREATED},
 		Provider:    def.Provider,
 		Schedule:    def.Schedule,
+		Priority:    int32(def.Priority),
+	}
+	if scd := def.SlowlyChanging; scd != nil {
+		if scd.ValidFrom == "" || scd.ValidTo == "" {
+			return nil, fmt.Errorf("slowly changing dimension needs valid from and valid to columns")
+		}
+		serialized.SlowlyChanging = &pb.SlowlyChangingDimension{ValidFrom: scd.ValidFrom, ValidTo: scd.ValidTo}
 	}
 	var err error
 	switch x := def.Definition.(type) {
@@ -710,15 +1077,14 @@ func (client *Client) CreateSourceVariant(ctx context.Context, def SourceDef) er
 	case PrimaryDataSource:
 		serialized.Definition, err = def.Definition.(PrimaryDataSource).Serialize()
 	case nil:
-		return fmt.Errorf("SourceDef Definition not set")
+		return nil, fmt.Errorf("SourceDef Definition not set")
 	default:
-		return fmt.Errorf("SourceDef Definition has unexpected type %T", x)
+		return nil, fmt.Errorf("SourceDef Definition has unexpected type %T", x)
 	}
 	if err != nil {
-		return err
+		return nil, err
 	}
-	_, err = client.grpcConn.CreateSourceVariant(ctx, serialized)
-	return err
+	return serialized, nil
 }
 
 func (client *Client) GetSourceVariants(ctx context.Context, ids []NameVariant) ([]*SourceVariant, error) {
@@ -899,6 +1265,16 @@ type ProviderDef struct {
 	Software         string
 	Team             string
 	SerializedConfig []byte
+	// Maintenance is run by the coordinator on each task's schedule.
+	Maintenance []MaintenanceTask
+}
+
+// MaintenanceTask is upkeep run on a provider's tables, such as
+// VACUUM_ANALYZE. A task without a schedule runs once, when the provider is
+// created.
+type MaintenanceTask struct {
+	Kind     string
+	Schedule string
 }
 
 func (def ProviderDef) ResourceType() ResourceType {
@@ -906,6 +1282,11 @@ func (def ProviderDef) ResourceType() ResourceType {
 }
 
 func (client *Client) CreateProvider(ctx context.Context, def ProviderDef) error {
+	_, err := client.grpcConn.CreateProvider(ctx, def.serialize())
+	return err
+}
+
+func (def ProviderDef) serialize() *pb.Provider {
 	serialized := &pb.Provider{
 		Name:             def.Name,
 		Description:      def.Description,
@@ -915,8 +1296,10 @@ func (client *Client) CreateProvider(ctx context.Context, def ProviderDef) error
 		Status:           &pb.ResourceStatus{Status: pb.ResourceStatus_NO_STATUS},
 		SerializedConfig: def.SerializedConfig,
 	}
-	_, err := client.grpcConn.CreateProvider(ctx, serialized)
-	return err
+	for _, task := range def.Maintenance {
+		serialized.Maintenance = append(serialized.Maintenance, &pb.MaintenanceTask{Kind: task.Kind, Schedule: task.Schedule})
+	}
+	return serialized
 }
 
 type providerStream interface {
@@ -1115,6 +1498,99 @@ func (fn lastUpdatedFn) LastUpdated() time.Time {
 	return t
 }
 
+type nextRunGetter interface {
+	GetNextRun() *tspb.Timestamp
+}
+
+type nextRunFn struct {
+	getter nextRunGetter
+}
+
+// NextRun is when a scheduled resource will next be updated. It's the zero
+// time for resources that aren't scheduled.
+func (fn nextRunFn) NextRun() time.Time {
+	next := fn.getter.GetNextRun()
+	if next == nil {
+		return time.Time{}
+	}
+	return next.AsTime()
+}
+
+// Priority orders a resource's job among the jobs waiting for a concurrency
+// slot. Jobs with a higher priority run first.
+type Priority int32
+
+const (
+	BackfillPriority Priority = -10
+	DefaultPriority  Priority = 0
+	HighPriority     Priority = 10
+)
+
+type priorityGetter interface {
+	GetPriority() int32
+}
+
+type priorityFn struct {
+	getter priorityGetter
+}
+
+func (fn priorityFn) Priority() Priority {
+	return Priority(fn.getter.GetPriority())
+}
+
+type progressGetter interface {
+	GetStatus() *pb.ResourceStatus
+}
+
+type progressFn struct {
+	getter progressGetter
+}
+
+// Progress returns how far along the variant's job is, or nil if it isn't
+// running or doesn't report its progress.
+func (fn progressFn) Progress() *JobProgress {
+	serialized := fn.getter.GetStatus().GetProgress()
+	if serialized == nil {
+		return nil
+	}
+	return &JobProgress{
+		RowsCopied:  serialized.RowsCopied,
+		TotalRows:   serialized.TotalRows,
+		ChunksDone:  int(serialized.ChunksDone),
+		TotalChunks: int(serialized.TotalChunks),
+		ETA:         serialized.Eta.AsDuration(),
+	}
+}
+
+type statsGetter interface {
+	GetStats() *pb.TableStats
+}
+
+type statsFn struct {
+	getter statsGetter
+}
+
+// Stats returns the last stats recorded for the variant's table, or nil if
+// none have been.
+func (fn statsFn) Stats() *TableStats {
+	serialized := fn.getter.GetStats()
+	if serialized == nil {
+		return nil
+	}
+	nulls := make(map[string]int64, len(serialized.Columns))
+	for _, col := range serialized.Columns {
+		nulls[col.Name] = col.NullCount
+	}
+	return &TableStats{
+		NumRows:    serialized.NumRows,
+		SizeBytes:  serialized.SizeBytes,
+		MinTS:      serialized.MinTs.AsTime(),
+		MaxTS:      serialized.MaxTs.AsTime(),
+		NullCounts: nulls,
+		Computed:   serialized.Computed.AsTime(),
+	}
+}
+
 type variantsDescriber interface {
 	GetName() string
 	GetDefaultVariant() string
@@ -1271,6 +1747,10 @@ type FeatureVariant struct {
 	fetchSourceFns
 	createdFn
 	lastUpdatedFn
+	nextRunFn
+	statsFn
+	priorityFn
+	progressFn
 	protoStringer
 }
 
@@ -1282,6 +1762,10 @@ func wrapProtoFeatureVariant(serialized *pb.FeatureVariant) *FeatureVariant {
 		fetchSourceFns:       fetchSourceFns{serialized},
 		createdFn:            createdFn{serialized},
 		lastUpdatedFn:        lastUpdatedFn{serialized},
+		nextRunFn:            nextRunFn{serialized},
+		statsFn:              statsFn{serialized},
+		priorityFn:           priorityFn{serialized},
+		progressFn:           progressFn{serialized},
 		protoStringer:        protoStringer{serialized},
 	}
 }
@@ -1324,6 +1808,35 @@ func (variant *FeatureVariant) Error() string {
 	return ""
 }
 
+// ErrorCode classifies the error the feature's job failed with, if it did.
+func (variant *FeatureVariant) ErrorCode() ErrorCode {
+	return ErrorCode(variant.serialized.GetStatus().GetErrorCode())
+}
+
+func (variant *FeatureVariant) MockValue() string {
+	return variant.serialized.GetMockValue()
+}
+
+func (variant *FeatureVariant) HasMockValue() bool {
+	return variant.serialized.GetMockValue() != ""
+}
+
+// Scopes are what a caller needs all of to serve the feature online.
+func (variant *FeatureVariant) Scopes() []string {
+	return variant.serialized.GetScopes()
+}
+
+// RefreshesOnUpstreamChange is whether the feature is updated each time its
+// source is.
+func (variant *FeatureVariant) RefreshesOnUpstreamChange() bool {
+	return variant.serialized.GetRefreshOnUpstreamChange()
+}
+
+// Schedule is the cron schedule the feature is updated on, if it has one.
+func (variant *FeatureVariant) Schedule() string {
+	return variant.serialized.GetSchedule()
+}
+
 func (variant *FeatureVariant) Location() interface{} {
 	return variant.serialized.GetLocation()
 }
@@ -1425,6 +1938,14 @@ func (provider *Provider) SerializedConfig() []byte {
 	return provider.serialized.GetSerializedConfig()
 }
 
+func (provider *Provider) Maintenance() []MaintenanceTask {
+	tasks := make([]MaintenanceTask, len(provider.serialized.GetMaintenance()))
+	for i, task := range provider.serialized.GetMaintenance() {
+		tasks[i] = MaintenanceTask{Kind: task.GetKind(), Schedule: task.GetSchedule()}
+	}
+	return tasks
+}
+
 func (provider *Provider) Status() ResourceStatus {
 	if provider.serialized.GetStatus() != nil {
 		return ResourceStatus(provider.serialized.GetStatus().Status)
@@ -1439,6 +1960,11 @@ func (provider *Provider) Error() string {
 	return ""
 }
 
+// ErrorCode classifies the error the provider's job failed with, if it did.
+func (provider *Provider) ErrorCode() ErrorCode {
+	return ErrorCode(provider.serialized.GetStatus().GetErrorCode())
+}
+
 type Model struct {
 	serialized *pb.Model
 	fetchTrainingSetsFns
@@ -1503,6 +2029,8 @@ type LabelVariant struct {
 	fetchProviderFns
 	fetchSourceFns
 	createdFn
+	priorityFn
+	progressFn
 	protoStringer
 }
 
@@ -1513,6 +2041,8 @@ func wrapProtoLabelVariant(serialized *pb.LabelVariant) *LabelVariant {
 		fetchProviderFns:     fetchProviderFns{serialized},
 		fetchSourceFns:       fetchSourceFns{serialized},
 		createdFn:            createdFn{serialized},
+		priorityFn:           priorityFn{serialized},
+		progressFn:           progressFn{serialized},
 		protoStringer:        protoStringer{serialized},
 	}
 }
@@ -1555,6 +2085,27 @@ func (variant *LabelVariant) Error() string {
 	return ""
 }
 
+// ErrorCode classifies the error the label's job failed with, if it did.
+func (variant *LabelVariant) ErrorCode() ErrorCode {
+	return ErrorCode(variant.serialized.GetStatus().GetErrorCode())
+}
+
+// OnlineProvider is the online store the label is served from, or "" if it
+// isn't served online.
+func (variant *LabelVariant) OnlineProvider() string {
+	return variant.serialized.GetOnlineProvider()
+}
+
+// ServedOnline reports whether the label is materialized to an online store.
+func (variant *LabelVariant) ServedOnline() bool {
+	return variant.serialized.GetOnlineProvider() != ""
+}
+
+// Scopes are what a caller needs all of to serve the label.
+func (variant *LabelVariant) Scopes() []string {
+	return variant.serialized.GetScopes()
+}
+
 func (variant *LabelVariant) Location() interface{} {
 	return variant.serialized.GetLocation()
 }
@@ -1597,6 +2148,9 @@ type TrainingSetVariant struct {
 	fetchProviderFns
 	createdFn
 	lastUpdatedFn
+	nextRunFn
+	priorityFn
+	progressFn
 	protoStringer
 }
 
@@ -1607,6 +2161,9 @@ func wrapProtoTrainingSetVariant(serialized *pb.TrainingSetVariant) *TrainingSet
 		fetchProviderFns: fetchProviderFns{serialized},
 		createdFn:        createdFn{serialized},
 		lastUpdatedFn:    lastUpdatedFn{serialized},
+		nextRunFn:        nextRunFn{serialized},
+		priorityFn:       priorityFn{serialized},
+		progressFn:       progressFn{serialized},
 		protoStringer:    protoStringer{serialized},
 	}
 }
@@ -1641,6 +2198,11 @@ func (variant *TrainingSetVariant) Error() string {
 	return variant.serialized.GetStatus().ErrorMessage
 }
 
+// ErrorCode classifies the error the training set's job failed with, if it did.
+func (variant *TrainingSetVariant) ErrorCode() ErrorCode {
+	return ErrorCode(variant.serialized.GetStatus().GetErrorCode())
+}
+
 func (variant *TrainingSetVariant) Label() NameVariant {
 	return parseNameVariant(variant.serialized.GetLabel())
 }
@@ -1653,6 +2215,59 @@ func (variant *TrainingSetVariant) FetchLabel(client *Client, ctx context.Contex
 	return labelList[0], nil
 }
 
+// VariantPin is the version of a resource variant a training set was pinned
+// to.
+type VariantPin struct {
+	Resource ResourceID
+	Created  time.Time
+}
+
+// Schedule is the cron schedule the training set is updated on, if it has
+// one.
+func (variant *TrainingSetVariant) Schedule() string {
+	return variant.serialized.GetSchedule()
+}
+
+func (variant *TrainingSetVariant) PinsDependencies() bool {
+	return variant.serialized.GetPinDependencies()
+}
+
+// RefreshesOnUpstreamChange is whether the training set is rebuilt each time
+// one of its features or its label's source is updated.
+func (variant *TrainingSetVariant) RefreshesOnUpstreamChange() bool {
+	return variant.serialized.GetRefreshOnUpstreamChange()
+}
+
+// Export is where the training set's rows are written as files each time
+// it's built, or nil if they aren't.
+func (variant *TrainingSetVariant) Export() *TrainingSetExport {
+	export := variant.serialized.GetExport()
+	if export == nil {
+		return nil
+	}
+	return &TrainingSetExport{
+		URI:         export.GetUri(),
+		Format:      export.GetFormat(),
+		RowsPerFile: int(export.GetRowsPerFile()),
+	}
+}
+
+func (variant *TrainingSetVariant) Pins() []VariantPin {
+	pins := make([]VariantPin, len(variant.serialized.GetPins()))
+	for i, pin := range variant.serialized.GetPins() {
+		res := pin.GetResource()
+		pins[i] = VariantPin{
+			Resource: ResourceID{
+				Name:    res.GetResource().GetName(),
+				Variant: res.GetResource().GetVariant(),
+				Type:    ResourceType(res.GetResourceType()),
+			},
+			Created: pin.GetCreated().AsTime(),
+		}
+	}
+	return pins
+}
+
 type Source struct {
 	serialized *pb.Source
 	variantsFns
@@ -1679,6 +2294,10 @@ type SourceVariant struct {
 	fetchProviderFns
 	createdFn
 	lastUpdatedFn
+	nextRunFn
+	statsFn
+	priorityFn
+	progressFn
 	protoStringer
 }
 
@@ -1691,6 +2310,10 @@ func wrapProtoSourceVariant(serialized *pb.SourceVariant) *SourceVariant {
 		fetchProviderFns:     fetchProviderFns{serialized},
 		createdFn:            createdFn{serialized},
 		lastUpdatedFn:        lastUpdatedFn{serialized},
+		nextRunFn:            nextRunFn{serialized},
+		statsFn:              statsFn{serialized},
+		priorityFn:           priorityFn{serialized},
+		progressFn:           progressFn{serialized},
 		protoStringer:        protoStringer{serialized},
 	}
 }
@@ -1730,6 +2353,26 @@ func (variant *SourceVariant) Error() string {
 
 }
 
+// ErrorCode classifies the error the source's job failed with, if it did.
+func (variant *SourceVariant) ErrorCode() ErrorCode {
+	return ErrorCode(variant.serialized.GetStatus().GetErrorCode())
+}
+
+// Schedule is the cron schedule the source is updated on, if it has one.
+func (variant *SourceVariant) Schedule() string {
+	return variant.serialized.GetSchedule()
+}
+
+// SlowlyChanging returns the validity columns of a slowly changing dimension
+// source, or nil if the source isn't one.
+func (variant *SourceVariant) SlowlyChanging() *SlowlyChangingDimension {
+	scd := variant.serialized.GetSlowlyChanging()
+	if scd == nil {
+		return nil
+	}
+	return &SlowlyChangingDimension{ValidFrom: scd.GetValidFrom(), ValidTo: scd.GetValidTo()}
+}
+
 func (variant *SourceVariant) IsTransformation() bool {
 	return reflect.TypeOf(variant.serialized.GetDefinition()) == reflect.TypeOf(&pb.SourceVariant_Transformation{})
 }
@@ -1760,6 +2403,20 @@ func (variant *SourceVariant) SQLTransformationSources() []NameVariant {
 	return variants
 }
 
+// TransformationStorage is how the transformation's result is stored.
+func (variant *SourceVariant) TransformationStorage() TransformationStorage {
+	return TransformationStorage(variant.serialized.GetTransformation().GetStorage())
+}
+
+// SQLTransformationParameters are the values of the transformation query's
+// {{ param "name" }} templates.
+func (variant *SourceVariant) SQLTransformationParameters() map[string]string {
+	if !variant.IsSQLTransformation() {
+		return nil
+	}
+	return variant.serialized.GetTransformation().GetSQLTransformation().GetParameters()
+}
+
 func (variant *SourceVariant) isPrimaryData() bool {
 	return reflect.TypeOf(variant.serialized.GetDefinition()) == reflect.TypeOf(&pb.SourceVariant_PrimaryData{})
 }
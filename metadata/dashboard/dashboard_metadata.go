@@ -46,6 +46,7 @@ type FeatureVariantResource struct {
 	Variant      string                                  `json:"variant"`
 	Status       string                                  `json:"status"`
 	Error        string                                  `json:"error"`
+	ErrorCode    string                                  `json:"error-code"`
 	Location     map[string]string                       `json:"location"`
 	Source       metadata.NameVariant                    `json:"source"`
 	TrainingSets map[string][]TrainingSetVariantResource `json:"training-sets"`
@@ -70,6 +71,7 @@ type TrainingSetVariantResource struct {
 	Features    map[string][]FeatureVariantResource `json:"features"`
 	Status      string                              `json:"status"`
 	Error       string                              `json:"error"`
+	ErrorCode   string                              `json:"error-code"`
 }
 
 type TrainingSetResource struct {
@@ -93,6 +95,7 @@ type SourceVariantResource struct {
 	TrainingSets map[string][]TrainingSetVariantResource `json:"training-sets"`
 	Status       string                                  `json:"status"`
 	Error        string                                  `json:"error"`
+	ErrorCode    string                                  `json:"error-code"`
 	Definition   string                                  `json:"definition"`
 }
 
@@ -118,6 +121,7 @@ type LabelVariantResource struct {
 	TrainingSets map[string][]TrainingSetVariantResource `json:"training-sets"`
 	Status       string                                  `json:"status"`
 	Error        string                                  `json:"error"`
+	ErrorCode    string                                  `json:"error-code"`
 }
 
 type LabelResource struct {
@@ -171,6 +175,7 @@ type ProviderResource struct {
 	TrainingSets map[string][]TrainingSetVariantResource `json:"training-sets"`
 	Status       string                                  `json:"status"`
 	Error        string                                  `json:"error"`
+	ErrorCode    string                                  `json:"error-code"`
 }
 
 type FetchError struct {
@@ -205,6 +210,7 @@ func featureShallowMap(variant *metadata.FeatureVariant) FeatureVariantResource
 		Location:    columnsToMap(variant.LocationColumns().(metadata.ResourceVariantColumns)),
 		Status:      variant.Status().String(),
 		Error:       variant.Error(),
+		ErrorCode:   string(variant.ErrorCode()),
 	}
 }
 
@@ -222,6 +228,7 @@ func labelShallowMap(variant *metadata.LabelVariant) LabelVariantResource {
 		Location:    columnsToMap(variant.LocationColumns().(metadata.ResourceVariantColumns)),
 		Status:      variant.Status().String(),
 		Error:       variant.Error(),
+		ErrorCode:   string(variant.ErrorCode()),
 	}
 }
 
@@ -236,6 +243,7 @@ func trainingSetShallowMap(variant *metadata.TrainingSetVariant) TrainingSetVari
 		Label:       variant.Label(),
 		Status:      variant.Status().String(),
 		Error:       variant.Error(),
+		ErrorCode:   string(variant.ErrorCode()),
 	}
 }
 
@@ -259,6 +267,7 @@ func sourceShallowMap(variant *metadata.SourceVariant) SourceVariantResource {
 		Provider:    variant.Provider(),
 		Status:      variant.Status().String(),
 		Error:       variant.Error(),
+		ErrorCode:   string(variant.ErrorCode()),
 		Definition:  sourceString,
 	}
 }
@@ -691,6 +700,7 @@ func (m *MetadataServer) GetMetadata(c *gin.Context) {
 			Team:         provider.Team(),
 			Status:       provider.Status().String(),
 			Error:        provider.Error(),
+			ErrorCode:    string(provider.ErrorCode()),
 		}
 		fetchGroup := new(errgroup.Group)
 		fetchGroup.Go(func() error {
@@ -0,0 +1,93 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+
+	pb "github.com/featureform/metadata/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (d DeadLetter) proto() *pb.DeadLetter {
+	attempts := make([]*pb.JobAttempt, len(d.Attempts))
+	for i, attempt := range d.Attempts {
+		attempts[i] = &pb.JobAttempt{Attempt: uint32(attempt.Attempt), Error: attempt.Error, Failed: tspb.New(attempt.Failed)}
+	}
+	return &pb.DeadLetter{
+		Resource:     &pb.ResourceID{Resource: &pb.NameVariant{Name: d.Resource.Name, Variant: d.Resource.Variant}, ResourceType: d.Resource.Type.Serialized()},
+		Error:        d.Error,
+		Attempts:     attempts,
+		DeadLettered: tspb.New(d.DeadLettered),
+	}
+}
+
+func parseDeadLetter(serialized *pb.DeadLetter) DeadLetter {
+	res := serialized.GetResource()
+	d := DeadLetter{
+		Resource:     ResourceID{Name: res.GetResource().GetName(), Variant: res.GetResource().GetVariant(), Type: ResourceType(res.GetResourceType())},
+		Error:        serialized.GetError(),
+		DeadLettered: serialized.GetDeadLettered().AsTime(),
+	}
+	for _, attempt := range serialized.GetAttempts() {
+		d.Attempts = append(d.Attempts, JobAttempt{Attempt: uint(attempt.GetAttempt()), Error: attempt.GetError(), Failed: attempt.GetFailed().AsTime()})
+	}
+	return d
+}
+
+// ListDeadLetters returns the jobs that failed for good and haven't been
+// replayed.
+func (client *Client) ListDeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	list, err := client.grpcConn.ListDeadLetters(ctx, &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	deadLetters := make([]DeadLetter, len(list.GetDeadLetters()))
+	for i, deadLetter := range list.GetDeadLetters() {
+		deadLetters[i] = parseDeadLetter(deadLetter)
+	}
+	return deadLetters, nil
+}
+
+// ReplayDeadLetter resubmits the dead-lettered job of a resource, starting
+// again from its first attempt.
+func (client *Client) ReplayDeadLetter(ctx context.Context, id ResourceID, requester string) error {
+	req := pb.ReplayDeadLetterRequest{
+		Resource:  &pb.ResourceID{Resource: &pb.NameVariant{Name: id.Name, Variant: id.Variant}, ResourceType: id.Type.Serialized()},
+		Requester: requester,
+	}
+	_, err := client.grpcConn.ReplayDeadLetter(ctx, &req)
+	return err
+}
+
+// ListDeadLetters returns the jobs that failed for good, with the error and
+// attempts that led there. The jobs themselves aren't sent.
+func (serv *MetadataServer) ListDeadLetters(ctx context.Context, _ *pb.Empty) (*pb.DeadLetterList, error) {
+	deadLetters, err := serv.lookup.ListDeadLetters()
+	if err != nil {
+		return nil, err
+	}
+	list := &pb.DeadLetterList{DeadLetters: make([]*pb.DeadLetter, len(deadLetters))}
+	for i, deadLetter := range deadLetters {
+		list.DeadLetters[i] = deadLetter.proto()
+	}
+	return list, nil
+}
+
+// ReplayDeadLetter resubmits a resource's dead-lettered job from its first
+// attempt. It's meant to be called once the cause of the failure has been
+// fixed. The resource is set back to PENDING until the job runs.
+func (serv *MetadataServer) ReplayDeadLetter(ctx context.Context, req *pb.ReplayDeadLetterRequest) (*pb.Empty, error) {
+	res := req.GetResource()
+	id := ResourceID{Name: res.GetResource().GetName(), Variant: res.GetResource().GetVariant(), Type: ResourceType(res.GetResourceType())}
+	if _, err := serv.lookup.Lookup(id); err != nil {
+		return nil, err
+	}
+	if err := serv.lookup.ReplayDeadLetter(id); err != nil {
+		return nil, err
+	}
+	serv.audit("Replayed dead letter", req.Requester, "resource", id)
+	return &pb.Empty{}, nil
+}
@@ -0,0 +1,202 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/protobuf/proto"
+)
+
+// Encrypted configs are prefixed so that plaintext configs written before
+// encryption was enabled can still be read.
+var envelopePrefix = []byte("ffenc1:")
+
+// KeyWrapper encrypts and decrypts the per-config data keys used for envelope
+// encryption. A KMS backed implementation only needs to call its Encrypt and
+// Decrypt APIs; LocalKeyWrapper uses a key held by the metadata server.
+type KeyWrapper interface {
+	WrapKey(dataKey []byte) ([]byte, error)
+	UnwrapKey(wrapped []byte) ([]byte, error)
+}
+
+type LocalKeyWrapper struct {
+	aead cipher.AEAD
+}
+
+// NewLocalKeyWrapper creates a KeyWrapper from a 16, 24 or 32 byte AES key.
+func NewLocalKeyWrapper(key []byte) (*LocalKeyWrapper, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid local encryption key: %w", err)
+	}
+	return &LocalKeyWrapper{aead}, nil
+}
+
+func (wrapper *LocalKeyWrapper) WrapKey(dataKey []byte) ([]byte, error) {
+	return seal(wrapper.aead, dataKey)
+}
+
+func (wrapper *LocalKeyWrapper) UnwrapKey(wrapped []byte) ([]byte, error) {
+	return open(wrapper.aead, wrapped)
+}
+
+type envelope struct {
+	WrappedKey []byte
+	Ciphertext []byte
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, nil)
+}
+
+func encryptConfig(wrapper KeyWrapper, config []byte) ([]byte, error) {
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := seal(aead, config)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := wrapper.WrapKey(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not wrap data key: %w", err)
+	}
+	serialized, err := json.Marshal(envelope{WrappedKey: wrapped, Ciphertext: ciphertext})
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, envelopePrefix...), serialized...), nil
+}
+
+func decryptConfig(wrapper KeyWrapper, config []byte) ([]byte, error) {
+	if !bytes.HasPrefix(config, envelopePrefix) {
+		return config, nil
+	}
+	var env envelope
+	if err := json.Unmarshal(config[len(envelopePrefix):], &env); err != nil {
+		return nil, fmt.Errorf("invalid config envelope: %w", err)
+	}
+	dataKey, err := wrapper.UnwrapKey(env.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not unwrap data key: %w", err)
+	}
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(aead, env.Ciphertext)
+}
+
+// encryptedResourceLookup encrypts provider configs before they reach the
+// wrapped lookup and decrypts them on the way out, so the rest of the server
+// only ever sees plaintext configs.
+type encryptedResourceLookup struct {
+	wrapper KeyWrapper
+	ResourceLookup
+}
+
+func (lookup encryptedResourceLookup) decrypt(res Resource) (Resource, error) {
+	provider, ok := res.(*providerResource)
+	if !ok {
+		return res, nil
+	}
+	config, err := decryptConfig(lookup.wrapper, provider.serialized.SerializedConfig)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt config for provider %s: %w", provider.serialized.Name, err)
+	}
+	decrypted := proto.Clone(provider.serialized).(*pb.Provider)
+	decrypted.SerializedConfig = config
+	return &providerResource{decrypted}, nil
+}
+
+func (lookup encryptedResourceLookup) decryptAll(resources []Resource) ([]Resource, error) {
+	for i, res := range resources {
+		decrypted, err := lookup.decrypt(res)
+		if err != nil {
+			return nil, err
+		}
+		resources[i] = decrypted
+	}
+	return resources, nil
+}
+
+func (lookup encryptedResourceLookup) Set(id ResourceID, res Resource) error {
+	provider, ok := res.(*providerResource)
+	if !ok {
+		return lookup.ResourceLookup.Set(id, res)
+	}
+	encrypted := proto.Clone(provider.serialized).(*pb.Provider)
+	config, err := encryptConfig(lookup.wrapper, provider.serialized.SerializedConfig)
+	if err != nil {
+		return fmt.Errorf("could not encrypt config for provider %s: %w", provider.serialized.Name, err)
+	}
+	encrypted.SerializedConfig = config
+	return lookup.ResourceLookup.Set(id, &providerResource{encrypted})
+}
+
+func (lookup encryptedResourceLookup) Lookup(id ResourceID) (Resource, error) {
+	res, err := lookup.ResourceLookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	return lookup.decrypt(res)
+}
+
+func (lookup encryptedResourceLookup) ListForType(t ResourceType) ([]Resource, error) {
+	resources, err := lookup.ResourceLookup.ListForType(t)
+	if err != nil {
+		return nil, err
+	}
+	return lookup.decryptAll(resources)
+}
+
+func (lookup encryptedResourceLookup) List() ([]Resource, error) {
+	resources, err := lookup.ResourceLookup.List()
+	if err != nil {
+		return nil, err
+	}
+	return lookup.decryptAll(resources)
+}
+
+func (lookup encryptedResourceLookup) Submap(ids []ResourceID) (ResourceLookup, error) {
+	submap, err := lookup.ResourceLookup.Submap(ids)
+	if err != nil {
+		return nil, err
+	}
+	return encryptedResourceLookup{lookup.wrapper, submap}, nil
+}
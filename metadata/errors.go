@@ -0,0 +1,127 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorCode classifies why a resource's job failed. It's stored in the
+// resource's status along with the error's message, so that UIs and clients
+// can react to a kind of failure, like asking for new credentials, without
+// parsing the message.
+type ErrorCode string
+
+const (
+	// ErrorUnknown is the code of errors that don't match any other.
+	ErrorUnknown ErrorCode = "UNKNOWN"
+	// ErrorProviderAuthFailed is a provider that rejected its credentials.
+	ErrorProviderAuthFailed ErrorCode = "PROVIDER_AUTH_FAILED"
+	// ErrorProviderUnavailable is a provider that couldn't be reached.
+	ErrorProviderUnavailable ErrorCode = "PROVIDER_UNAVAILABLE"
+	// ErrorProviderConfigInvalid is a provider whose config couldn't be used
+	// to open it.
+	ErrorProviderConfigInvalid ErrorCode = "PROVIDER_CONFIG_INVALID"
+	// ErrorSourceNotReady is a source that was still pending or had failed
+	// when a job needed its data.
+	ErrorSourceNotReady ErrorCode = "SOURCE_NOT_READY"
+	// ErrorDependencyCycle is a transformation whose sources depend on it.
+	ErrorDependencyCycle ErrorCode = "DEPENDENCY_CYCLE"
+	// ErrorSQLSyntaxError is a query that a provider couldn't parse.
+	ErrorSQLSyntaxError ErrorCode = "SQL_SYNTAX_ERROR"
+	// ErrorTemplateInvalid is a transformation query whose templates couldn't
+	// be filled in.
+	ErrorTemplateInvalid ErrorCode = "TEMPLATE_INVALID"
+	// ErrorResourceNotFound is a resource that a job needed and that isn't
+	// registered.
+	ErrorResourceNotFound ErrorCode = "RESOURCE_NOT_FOUND"
+	// ErrorAlreadyExists is a resource whose data already exists in its
+	// provider.
+	ErrorAlreadyExists ErrorCode = "ALREADY_EXISTS"
+	// ErrorJobTimedOut is a job that ran for longer than its max runtime.
+	ErrorJobTimedOut ErrorCode = "JOB_TIMED_OUT"
+)
+
+// CodedError is an error that knows its ErrorCode.
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (err *CodedError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *CodedError) Unwrap() error {
+	return err.Err
+}
+
+func (err *CodedError) ErrorCode() ErrorCode {
+	return err.Code
+}
+
+// WithErrorCode attaches a code to err. It returns nil if err is.
+func WithErrorCode(code ErrorCode, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &CodedError{Code: code, Err: err}
+}
+
+// errorMessageCodes classify errors that lost their type on the way, like the
+// ones that runners in other processes report, by what the databases and
+// clients that providers use say in them. The first that matches is used.
+var errorMessageCodes = []struct {
+	substring string
+	code      ErrorCode
+}{
+	{"password authentication failed", ErrorProviderAuthFailed},
+	{"authentication failed", ErrorProviderAuthFailed},
+	{"invalid credentials", ErrorProviderAuthFailed},
+	{"incorrect username or password", ErrorProviderAuthFailed},
+	{"access denied", ErrorProviderAuthFailed},
+	{"noauth", ErrorProviderAuthFailed},
+	{"syntax error", ErrorSQLSyntaxError},
+	{"connection refused", ErrorProviderUnavailable},
+	{"no such host", ErrorProviderUnavailable},
+	{"i/o timeout", ErrorProviderUnavailable},
+}
+
+// ErrorCodeOf returns the code of an error. Errors are classified by the
+// first error in their chain with an ErrorCode method, like a CodedError,
+// then by their gRPC status, then by their message. Those that match none
+// are ErrorUnknown, and a nil error has no code.
+func ErrorCodeOf(err error) ErrorCode {
+	if err == nil {
+		return ""
+	}
+	var coded interface{ ErrorCode() ErrorCode }
+	if errors.As(err, &coded) {
+		return coded.ErrorCode()
+	}
+	var notFound *ResourceNotFound
+	if errors.As(err, &notFound) {
+		return ErrorResourceNotFound
+	}
+	if st, ok := status.FromError(err); ok {
+		switch st.Code() {
+		case codes.NotFound:
+			return ErrorResourceNotFound
+		case codes.AlreadyExists:
+			return ErrorAlreadyExists
+		}
+	}
+	msg := strings.ToLower(err.Error())
+	for _, match := range errorMessageCodes {
+		if strings.Contains(msg, match.substring) {
+			return match.code
+		}
+	}
+	return ErrorUnknown
+}
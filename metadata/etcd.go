@@ -35,12 +35,24 @@ type CoordinatorJob struct {
 	Attempts int
 	Resource ResourceID
 	Schedule string
+	// Kind is the registered job type that runs the job, with its Config.
+	// Jobs without one are run by the job type of their resource's type.
+	Kind   string
+	Config []byte
+	// Trigger is what started the job. It's recorded with each of its runs.
+	Trigger JobTrigger
+	// Lineage identifies the job across its attempts and the coordinators
+	// that take it over. It's set when the job first runs.
+	Lineage string `json:",omitempty"`
 }
 
 type CoordinatorScheduleJob struct {
 	Attempts int
 	Resource ResourceID
 	Schedule string
+	// Paused suspends the resource's scheduled runs without removing its
+	// schedule.
+	Paused bool
 }
 
 func (c *CoordinatorScheduleJob) Serialize() ([]byte, error) {
@@ -65,6 +77,9 @@ type TempJob struct {
 	Variant  string
 	Type     string
 	Schedule string
+	Kind     string     `json:",omitempty"`
+	Config   []byte     `json:",omitempty"`
+	Trigger  JobTrigger `json:",omitempty"`
 }
 
 func (c *CoordinatorJob) Serialize() ([]byte, error) {
@@ -74,6 +89,9 @@ func (c *CoordinatorJob) Serialize() ([]byte, error) {
 		Variant:  c.Resource.Variant,
 		Type:     c.Resource.Type.String(),
 		Schedule: c.Schedule,
+		Kind:     c.Kind,
+		Config:   c.Config,
+		Trigger:  c.Trigger,
 	}
 	serialized, err := json.Marshal(job)
 	if err != nil {
@@ -94,6 +112,9 @@ func (c *CoordinatorJob) Deserialize(serialized []byte) error {
 	c.Resource.Variant = job.Variant
 	c.Resource.Type = ResourceType(pb.ResourceType_value[job.Type])
 	c.Schedule = job.Schedule
+	c.Kind = job.Kind
+	c.Config = job.Config
+	c.Trigger = job.Trigger
 	return nil
 }
 
@@ -356,10 +377,147 @@ func GetJobKey(id ResourceID) string {
 	return fmt.Sprintf("JOB__%s__%s__%s", id.Type, id.Name, id.Variant)
 }
 
+// ManualRunJobKind is the kind of the coordinator jobs that TriggerRun queues.
+// The coordinator runs them by updating the resource once, like a scheduled
+// run does.
+const ManualRunJobKind = "RUN"
+
+// GetManualRunKey is where a manual run of a resource is queued. It's apart
+// from the resource's own job, so that a run doesn't replace it.
+func GetManualRunKey(id ResourceID) string {
+	return fmt.Sprintf("JOB__%s__%s__%s__%s", ManualRunJobKind, id.Type, id.Name, id.Variant)
+}
+
 func GetScheduleJobKey(id ResourceID) string {
 	return fmt.Sprintf("SCHEDULEJOB__%s__%s__%s", id.Type, id.Name, id.Variant)
 }
 
+func GetMaintenanceLockKey(id ResourceID) string {
+	return fmt.Sprintf("MAINTENANCE__%s__%s__%s", id.Type, id.Name, id.Variant)
+}
+
+func GetSchedulePauseKey(id ResourceID) string {
+	return fmt.Sprintf("SCHEDULEPAUSE__%s__%s__%s", id.Type, id.Name, id.Variant)
+}
+
+// MaintenanceLock stops every job against Resource from running, whether it
+// was scheduled or triggered, until the lock is released.
+type MaintenanceLock struct {
+	Resource ResourceID
+	Reason   string
+	Locked   time.Time
+}
+
+func (l *MaintenanceLock) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
+func (l *MaintenanceLock) Deserialize(serialized []byte) error {
+	err := json.Unmarshal(serialized, l)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// GetMaintenanceLock returns the lock on id, or nil if it isn't locked.
+func GetMaintenanceLock(ctx context.Context, kv clientv3.KV, id ResourceID) (*MaintenanceLock, error) {
+	resp, err := kv.Get(ctx, GetMaintenanceLockKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	lock := &MaintenanceLock{}
+	if err := lock.Deserialize(resp.Kvs[0].Value); err != nil {
+		return nil, fmt.Errorf("could not deserialize maintenance lock: %w", err)
+	}
+	return lock, nil
+}
+
+// SetMaintenanceLock locks id for maintenance, replacing any lock it has.
+func SetMaintenanceLock(ctx context.Context, kv clientv3.KV, id ResourceID, reason string) error {
+	lock := &MaintenanceLock{
+		Resource: id,
+		Reason:   reason,
+		Locked:   time.Now().UTC(),
+	}
+	serialized, err := lock.Serialize()
+	if err != nil {
+		return err
+	}
+	_, err = kv.Put(ctx, GetMaintenanceLockKey(id), string(serialized))
+	return err
+}
+
+// ReleaseMaintenanceLock removes the lock on id and writes the jobs that were
+// held back by it again, so that the coordinators watching for jobs run
+// them. It returns the keys of the jobs it wrote.
+func ReleaseMaintenanceLock(ctx context.Context, kv clientv3.KV, id ResourceID) ([]string, error) {
+	if _, err := kv.Delete(ctx, GetMaintenanceLockKey(id)); err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0)
+	// Both a resource's own job and a manual run of it wait for its lock.
+	for _, key := range []string{GetJobKey(id), GetManualRunKey(id)} {
+		resp, err := kv.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Kvs) == 0 {
+			continue
+		}
+		if _, err := kv.Put(ctx, key, string(resp.Kvs[0].Value)); err != nil {
+			return nil, fmt.Errorf("resubmit %s: %w", key, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// SchedulePause records that the scheduled runs of Resource are suspended,
+// until its schedule is resumed.
+type SchedulePause struct {
+	Resource  ResourceID
+	Reason    string
+	Requester string
+	Paused    time.Time
+}
+
+func (p *SchedulePause) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
+func (p *SchedulePause) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, p)
+}
+
+// GetSchedulePause returns the pause on id's schedule, or nil if it isn't
+// paused.
+func GetSchedulePause(ctx context.Context, kv clientv3.KV, id ResourceID) (*SchedulePause, error) {
+	resp, err := kv.Get(ctx, GetSchedulePauseKey(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	pause := &SchedulePause{}
+	if err := pause.Deserialize(resp.Kvs[0].Value); err != nil {
+		return nil, fmt.Errorf("could not deserialize schedule pause: %w", err)
+	}
+	return pause, nil
+}
+
 func (lookup etcdResourceLookup) HasJob(id ResourceID) (bool, error) {
 	job_key := GetJobKey(id)
 	count, err := lookup.connection.GetCountWithPrefix(job_key)
@@ -380,6 +538,7 @@ func (lookup etcdResourceLookup) SetJob(id ResourceID, schedule string) error {
 		Attempts: 0,
 		Resource: id,
 		Schedule: schedule,
+		Trigger:  TriggerRegistration,
 	}
 	serialized, err := coordinatorJob.Serialize()
 	if err != nil {
@@ -392,11 +551,61 @@ func (lookup etcdResourceLookup) SetJob(id ResourceID, schedule string) error {
 	return nil
 }
 
+func (lookup etcdResourceLookup) ResetJob(id ResourceID, schedule string) error {
+	coordinatorJob := CoordinatorJob{
+		Attempts: 0,
+		Resource: id,
+		Schedule: schedule,
+		Trigger:  TriggerRerun,
+	}
+	serialized, err := coordinatorJob.Serialize()
+	if err != nil {
+		return err
+	}
+	if err := lookup.connection.Put(GetJobKey(id), string(serialized)); err != nil {
+		return err
+	}
+	// The job being rerun replaces its dead letter.
+	return lookup.connection.Delete(GetDeadLetterKey(id))
+}
+
+// LockResource sets a maintenance lock on a resource, which stops its jobs
+// until it's unlocked.
+func (lookup etcdResourceLookup) LockResource(id ResourceID, reason string) error {
+	return SetMaintenanceLock(context.Background(), lookup.connection.Client, id, reason)
+}
+
+// UnlockResource removes the maintenance lock on a resource and resubmits
+// the jobs it held back.
+func (lookup etcdResourceLookup) UnlockResource(id ResourceID) ([]string, error) {
+	return ReleaseMaintenanceLock(context.Background(), lookup.connection.Client, id)
+}
+
 func (lookup etcdResourceLookup) SetSchedule(id ResourceID, schedule string) error {
+	res, err := lookup.Lookup(id)
+	if err != nil {
+		return fmt.Errorf("etcd: could not lookup: %w", err)
+	}
+	if err := res.UpdateSchedule(schedule); err != nil {
+		return fmt.Errorf("etcd: could not update schedule: %w", err)
+	}
+	if err := lookup.Set(id, res); err != nil {
+		return fmt.Errorf("etcd: could not set: %w", err)
+	}
+	// A new schedule for a paused resource stays paused.
+	pause, err := lookup.connection.Get(GetSchedulePauseKey(id))
+	if err != nil {
+		return err
+	}
+	return lookup.putScheduleJob(id, schedule, len(pause) > 0)
+}
+
+func (lookup etcdResourceLookup) putScheduleJob(id ResourceID, schedule string, paused bool) error {
 	coordinatorScheduleJob := CoordinatorScheduleJob{
 		Attempts: 0,
 		Resource: id,
 		Schedule: schedule,
+		Paused:   paused,
 	}
 	serialized, err := coordinatorScheduleJob.Serialize()
 	if err != nil {
@@ -409,6 +618,116 @@ func (lookup etcdResourceLookup) SetSchedule(id ResourceID, schedule string) err
 	return nil
 }
 
+// PauseSchedule records a pause on a resource's schedule and asks the
+// coordinator to suspend its scheduled runs.
+func (lookup etcdResourceLookup) PauseSchedule(id ResourceID, schedule, reason, requester string) error {
+	pause := &SchedulePause{
+		Resource:  id,
+		Reason:    reason,
+		Requester: requester,
+		Paused:    time.Now().UTC(),
+	}
+	serialized, err := pause.Serialize()
+	if err != nil {
+		return err
+	}
+	if err := lookup.connection.Put(GetSchedulePauseKey(id), string(serialized)); err != nil {
+		return err
+	}
+	return lookup.putScheduleJob(id, schedule, true)
+}
+
+// TriggerRun queues a run of a resource's job outside its schedule. A run
+// that's already queued isn't queued twice.
+func (lookup etcdResourceLookup) TriggerRun(id ResourceID, schedule string) error {
+	coordinatorJob := CoordinatorJob{
+		Resource: id,
+		Schedule: schedule,
+		Kind:     ManualRunJobKind,
+		Trigger:  TriggerManual,
+	}
+	serialized, err := coordinatorJob.Serialize()
+	if err != nil {
+		return err
+	}
+	return lookup.connection.Put(GetManualRunKey(id), string(serialized))
+}
+
+// MigrateOnlineStore queues an online store migration for the coordinator,
+// unless one to the same destination is already queued.
+func (lookup etcdResourceLookup) MigrateOnlineStore(migration OnlineMigration) error {
+	config, err := migration.Serialize()
+	if err != nil {
+		return err
+	}
+	coordinatorJob := CoordinatorJob{
+		Resource: ResourceID{Name: migration.Destination, Type: PROVIDER},
+		Kind:     MigrateOnlineJobKind,
+		Config:   config,
+		Trigger:  TriggerQueued,
+	}
+	serialized, err := coordinatorJob.Serialize()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	key := GetOnlineMigrationKey(migration.Destination)
+	txn, err := lookup.connection.Client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(serialized))).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !txn.Succeeded {
+		return fmt.Errorf("a migration to %s is already queued", migration.Destination)
+	}
+	return nil
+}
+
+// DeleteOrphanedTables queues a deletion of a provider's orphaned tables for
+// the coordinator, unless one is already queued for the provider.
+func (lookup etcdResourceLookup) DeleteOrphanedTables(deletion OrphanDeletion) error {
+	config, err := deletion.Serialize()
+	if err != nil {
+		return err
+	}
+	coordinatorJob := CoordinatorJob{
+		Resource: ResourceID{Name: deletion.Provider, Type: PROVIDER},
+		Kind:     DeleteOrphansJobKind,
+		Config:   config,
+		Trigger:  TriggerQueued,
+	}
+	serialized, err := coordinatorJob.Serialize()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	key := GetOrphanDeletionKey(deletion.Provider)
+	txn, err := lookup.connection.Client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(serialized))).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if !txn.Succeeded {
+		return fmt.Errorf("a deletion of %s's orphaned tables is already queued", deletion.Provider)
+	}
+	return nil
+}
+
+// ResumeSchedule removes the pause on a resource's schedule and asks the
+// coordinator to resume its scheduled runs.
+func (lookup etcdResourceLookup) ResumeSchedule(id ResourceID, schedule string) error {
+	if err := lookup.connection.Delete(GetSchedulePauseKey(id)); err != nil {
+		return err
+	}
+	return lookup.putScheduleJob(id, schedule, false)
+}
+
 func (lookup etcdResourceLookup) Set(id ResourceID, res Resource) error {
 
 	serRes, err := lookup.serializeResource(res)
@@ -508,3 +827,215 @@ func (lookup etcdResourceLookup) SetStatus(id ResourceID, status pb.ResourceStat
 	}
 	return nil
 }
+
+func GetCancelKey(id ResourceID) string {
+	return fmt.Sprintf("CANCEL__%s__%s__%s", id.Type, id.Name, id.Variant)
+}
+
+// JobCancellation asks the coordinator to stop the job running for Resource.
+type JobCancellation struct {
+	Resource  ResourceID
+	Requester string
+	Requested time.Time
+}
+
+func (c *JobCancellation) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
+func (c *JobCancellation) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, c)
+}
+
+// CancelJob writes a cancellation for the job of a resource, which the
+// coordinator picks up and uses to interrupt the job.
+func (lookup etcdResourceLookup) CancelJob(id ResourceID, requester string) error {
+	hasJob, err := lookup.HasJob(id)
+	if err != nil {
+		return err
+	}
+	if !hasJob {
+		return fmt.Errorf("%s %s (%s) has no job to cancel", id.Type, id.Name, id.Variant)
+	}
+	cancellation := &JobCancellation{
+		Resource:  id,
+		Requester: requester,
+		Requested: time.Now().UTC(),
+	}
+	serialized, err := cancellation.Serialize()
+	if err != nil {
+		return err
+	}
+	return lookup.connection.Put(GetCancelKey(id), string(serialized))
+}
+
+//Deletes a key from ETCD
+func (s EtcdStorage) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	_, err := s.Client.Delete(ctx, key)
+	return err
+}
+
+func (lookup etcdResourceLookup) RecordAudit(event AuditEvent) error {
+	serialized, err := event.Serialize()
+	if err != nil {
+		return err
+	}
+	return lookup.connection.Put(GetAuditKey(event), string(serialized))
+}
+
+func (lookup etcdResourceLookup) GetJobRuns(id ResourceID) ([]JobRun, error) {
+	values, err := lookup.connection.GetWithPrefix(GetJobRunPrefix(id))
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]JobRun, len(values))
+	for i, value := range values {
+		if err := runs[i].Deserialize(value); err != nil {
+			return nil, fmt.Errorf("deserialize job run: %w", err)
+		}
+		for _, key := range runs[i].WorkerLogKeys {
+			value, err := lookup.connection.Get(key)
+			if err != nil {
+				return nil, fmt.Errorf("get worker log: %w", err)
+			}
+			if len(value) == 0 {
+				continue
+			}
+			workerLog := WorkerLog{}
+			if err := workerLog.Deserialize(value); err != nil {
+				return nil, fmt.Errorf("deserialize worker log: %w", err)
+			}
+			runs[i].WorkerLogs = append(runs[i].WorkerLogs, workerLog.Logs)
+		}
+	}
+	return runs, nil
+}
+
+func (lookup etcdResourceLookup) SetNotification(n Notification) error {
+	serialized, err := n.Serialize()
+	if err != nil {
+		return err
+	}
+	return lookup.connection.Put(GetNotificationKey(n.Name), string(serialized))
+}
+
+func (lookup etcdResourceLookup) DeleteNotification(name string) error {
+	return lookup.connection.Delete(GetNotificationKey(name))
+}
+
+func (lookup etcdResourceLookup) ListDeadLetters() ([]DeadLetter, error) {
+	values, err := lookup.connection.GetWithPrefix(DeadLetterPrefix)
+	if err != nil {
+		return nil, err
+	}
+	deadLetters := make([]DeadLetter, len(values))
+	for i, value := range values {
+		if err := deadLetters[i].Deserialize(value); err != nil {
+			return nil, fmt.Errorf("deserialize dead letter: %w", err)
+		}
+	}
+	return deadLetters, nil
+}
+
+// ReplayDeadLetter puts a dead-lettered job back under its job key, starting
+// again from its first attempt, and sets its resource back to pending.
+func (lookup etcdResourceLookup) ReplayDeadLetter(id ResourceID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
+	defer cancel()
+	deadLetterKey := GetDeadLetterKey(id)
+	resp, err := lookup.connection.Client.Get(ctx, deadLetterKey)
+	if err != nil {
+		return err
+	}
+	if len(resp.Kvs) == 0 {
+		return fmt.Errorf("%s %s (%s) has no dead letter", id.Type, id.Name, id.Variant)
+	}
+	deadLetter := &DeadLetter{}
+	if err := deadLetter.Deserialize(resp.Kvs[0].Value); err != nil {
+		return fmt.Errorf("deserialize dead letter: %w", err)
+	}
+	job := &CoordinatorJob{}
+	if err := job.Deserialize(deadLetter.Job); err != nil {
+		return fmt.Errorf("deserialize dead-lettered job: %w", err)
+	}
+	job.Attempts = 0
+	job.Trigger = TriggerReplay
+	serialized, err := job.Serialize()
+	if err != nil {
+		return err
+	}
+	// Only replay if the dead letter hasn't changed, so that two operators
+	// replaying at once don't submit the job twice.
+	txn, err := lookup.connection.Client.Txn(ctx).If(
+		clientv3.Compare(clientv3.ModRevision(deadLetterKey), "=", resp.Kvs[0].ModRevision),
+	).Then(
+		clientv3.OpPut(GetJobKey(id), string(serialized)),
+		clientv3.OpDelete(deadLetterKey),
+	).Commit()
+	if err != nil {
+		return err
+	}
+	if !txn.Succeeded {
+		return fmt.Errorf("dead letter for %s %s (%s) changed while replaying it", id.Type, id.Name, id.Variant)
+	}
+	return lookup.SetStatus(id, pb.ResourceStatus{Status: pb.ResourceStatus_PENDING})
+}
+
+func (lookup etcdResourceLookup) ListNotifications() ([]Notification, error) {
+	values, err := lookup.connection.GetWithPrefix(NotificationPrefix)
+	if err != nil {
+		return nil, err
+	}
+	notifications := make([]Notification, len(values))
+	for i, value := range values {
+		if err := notifications[i].Deserialize(value); err != nil {
+			return nil, fmt.Errorf("deserialize notification: %w", err)
+		}
+	}
+	return notifications, nil
+}
+
+// DeadLetterPrefix is the etcd prefix that the coordinator keeps dead
+// letters under.
+const DeadLetterPrefix = "DEADLETTER__"
+
+func GetDeadLetterKey(id ResourceID) string {
+	return fmt.Sprintf("%s%s__%s__%s", DeadLetterPrefix, id.Type, id.Name, id.Variant)
+}
+
+// JobAttempt records one failed run of a coordinator job.
+type JobAttempt struct {
+	Attempt uint
+	Error   string
+	Failed  time.Time
+}
+
+// DeadLetter is a coordinator job that failed permanently or ran out of
+// attempts. It's kept, with what went wrong, until it's replayed.
+type DeadLetter struct {
+	// Job is the serialized CoordinatorJob, as it would be stored under its
+	// job key.
+	Job          []byte
+	Resource     ResourceID
+	Error        string
+	Attempts     []JobAttempt
+	DeadLettered time.Time
+}
+
+func (d *DeadLetter) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
+func (d *DeadLetter) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, d)
+}
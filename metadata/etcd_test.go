@@ -14,6 +14,7 @@ import (
 	tspb "google.golang.org/protobuf/types/known/timestamppb"
 	"log"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -891,3 +892,152 @@ func TestGetJobKeys(t *testing.T) {
 		t.Fatalf("Could not generate correct schedule job key")
 	}
 }
+
+func TestMaintenanceLockSerialize(t *testing.T) {
+	lock := &MaintenanceLock{
+		Resource: ResourceID{Name: "test", Variant: "foo", Type: FEATURE_VARIANT},
+		Reason:   "investigating null values",
+		Locked:   time.Now().UTC().Truncate(time.Second),
+	}
+	serialized, err := lock.Serialize()
+	if err != nil {
+		t.Fatalf("Could not serialize maintenance lock: %s", err)
+	}
+	copyLock := &MaintenanceLock{}
+	if err := copyLock.Deserialize(serialized); err != nil {
+		t.Fatalf("Could not deserialize maintenance lock: %s", err)
+	}
+	if !reflect.DeepEqual(copyLock, lock) {
+		t.Fatalf("Maintenance lock changed on serialization: %v != %v", copyLock, lock)
+	}
+	if key := GetMaintenanceLockKey(lock.Resource); key != "MAINTENANCE__FEATURE_VARIANT__test__foo" {
+		t.Fatalf("Could not generate correct maintenance lock key: %s", key)
+	}
+}
+
+func TestAuditEventSerialize(t *testing.T) {
+	event := &AuditEvent{
+		Action:    "Cancelled job",
+		Requester: "ops",
+		Time:      time.Now().UTC().Truncate(time.Second),
+		Details:   map[string]string{"resource": "{test foo FEATURE_VARIANT}"},
+	}
+	serialized, err := event.Serialize()
+	if err != nil {
+		t.Fatalf("Could not serialize audit event: %s", err)
+	}
+	copyEvent := &AuditEvent{}
+	if err := copyEvent.Deserialize(serialized); err != nil {
+		t.Fatalf("Could not deserialize audit event: %s", err)
+	}
+	if !reflect.DeepEqual(copyEvent, event) {
+		t.Fatalf("Audit event changed on serialization: %v != %v", copyEvent, event)
+	}
+	later := *event
+	later.Time = event.Time.Add(time.Nanosecond)
+	first, second := GetAuditKey(*event), GetAuditKey(later)
+	if !strings.HasPrefix(first, AuditPrefix) || first >= second {
+		t.Fatalf("Audit keys don't sort by time: %s, %s", first, second)
+	}
+	if GetAuditKey(*event) == first {
+		t.Fatalf("Audit keys of events at the same time clash")
+	}
+}
+
+func TestJobRunSerialize(t *testing.T) {
+	id := ResourceID{Name: "test", Variant: "foo", Type: FEATURE_VARIANT}
+	started := time.Now().UTC().Truncate(time.Second)
+	run := &JobRun{
+		Resource: id,
+		Attempt:  2,
+		Trigger:  TriggerSchedule,
+		Started:  started,
+		Ended:    started.Add(time.Minute),
+		Duration: time.Minute,
+		Status:   JobRunFailed,
+		Error:    "provider unavailable",
+		Logs:     "info\tStarting materialization",
+	}
+	serialized, err := run.Serialize()
+	if err != nil {
+		t.Fatalf("Could not serialize job run: %s", err)
+	}
+	copyRun := &JobRun{}
+	if err := copyRun.Deserialize(serialized); err != nil {
+		t.Fatalf("Could not deserialize job run: %s", err)
+	}
+	if !reflect.DeepEqual(copyRun, run) {
+		t.Fatalf("Job run changed on serialization: %v != %v", copyRun, run)
+	}
+	if parsed := parseJobRun(run.proto()); !reflect.DeepEqual(&parsed, run) {
+		t.Fatalf("Job run changed on conversion to proto: %v != %v", parsed, run)
+	}
+	later := *run
+	later.Started = started.Add(time.Second)
+	first, second := GetJobRunKey(*run), GetJobRunKey(later)
+	if !strings.HasPrefix(first, GetJobRunPrefix(id)) || first >= second {
+		t.Fatalf("Job run keys don't sort by start: %s, %s", first, second)
+	}
+	job := &CoordinatorJob{Resource: id, Trigger: TriggerReplay}
+	serializedJob, err := job.Serialize()
+	if err != nil {
+		t.Fatalf("Could not serialize job: %s", err)
+	}
+	copyJob := &CoordinatorJob{}
+	if err := copyJob.Deserialize(serializedJob); err != nil || copyJob.Trigger != TriggerReplay {
+		t.Fatalf("Job trigger wasn't kept: %v: %v", copyJob, err)
+	}
+}
+
+func TestTrainingSetFreshnessAfter(t *testing.T) {
+	cutoff := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	freshness := TrainingSetFreshness{
+		Inputs: []InputFreshness{
+			{Resource: ResourceID{Name: "fraud", Variant: "v1", Type: LABEL_VARIANT}, Latest: cutoff},
+			{Resource: ResourceID{Name: "avg_amount", Variant: "v1", Type: FEATURE_VARIANT}, Latest: cutoff.Add(time.Hour)},
+		},
+		Built: cutoff.Add(24 * time.Hour),
+	}
+	if parsed := parseTrainingSetFreshness(freshness.Serialize()); !reflect.DeepEqual(parsed, freshness) {
+		t.Fatalf("Freshness changed on conversion to proto: %v != %v", parsed, freshness)
+	}
+	late := freshness.After(cutoff)
+	if len(late) != 1 || late[0].Resource.Name != "avg_amount" {
+		t.Fatalf("Expected only the feature to be after the cutoff, got %v", late)
+	}
+	if late := freshness.After(cutoff.Add(time.Hour)); len(late) != 0 {
+		t.Fatalf("Expected no inputs after the latest value, got %v", late)
+	}
+}
+
+func TestNotificationValidate(t *testing.T) {
+	valid := []Notification{
+		{Name: "hook", Kind: WebhookNotification, URL: "https://example.com/hook"},
+		{Name: "slack", Kind: SlackNotification, URL: "https://hooks.slack.com/services/T/B/X", Events: []NotificationEvent{NotifyJobFailed}},
+		{Name: "pager", Kind: PagerDutyNotification, RoutingKey: "key"},
+	}
+	for _, n := range valid {
+		if err := n.validate(); err != nil {
+			t.Fatalf("Valid notification %s failed validation: %s", n.Name, err)
+		}
+		if parsed := parseNotification(n.proto()); !reflect.DeepEqual(parsed, n) {
+			t.Fatalf("Notification changed on conversion to proto: %v != %v", parsed, n)
+		}
+	}
+	invalid := []Notification{
+		{Kind: WebhookNotification, URL: "https://example.com/hook"},
+		{Name: "hook", Kind: WebhookNotification},
+		{Name: "hook", Kind: WebhookNotification, URL: "ftp://example.com"},
+		{Name: "pager", Kind: PagerDutyNotification},
+		{Name: "email", Kind: "email", URL: "https://example.com"},
+		{Name: "hook", Kind: WebhookNotification, URL: "https://example.com", Events: []NotificationEvent{"job_started"}},
+	}
+	for _, n := range invalid {
+		if err := n.validate(); err == nil {
+			t.Fatalf("Invalid notification passed validation: %v", n)
+		}
+	}
+	if valid[1].Wants(NotifyJobSucceeded) || !valid[1].Wants(NotifyJobFailed) || !valid[0].Wants(NotifyScheduleMissed) {
+		t.Fatalf("Notifications don't filter their events")
+	}
+}
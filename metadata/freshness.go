@@ -0,0 +1,147 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/protobuf/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// InputFreshness is the latest timestamp of the values of a training set's
+// feature or label that the training set was built from.
+type InputFreshness struct {
+	Resource ResourceID
+	Latest   time.Time
+}
+
+// TrainingSetFreshness is recorded by the coordinator each time it builds a
+// training set, so that it can be checked to only use data from before a
+// cutoff, like when backtesting a model trained as of that date.
+type TrainingSetFreshness struct {
+	Inputs []InputFreshness
+	Built  time.Time
+}
+
+// After returns the inputs with values from after cutoff.
+func (f TrainingSetFreshness) After(cutoff time.Time) []InputFreshness {
+	late := make([]InputFreshness, 0)
+	for _, input := range f.Inputs {
+		if input.Latest.After(cutoff) {
+			late = append(late, input)
+		}
+	}
+	return late
+}
+
+func (input InputFreshness) proto() *pb.InputFreshness {
+	return &pb.InputFreshness{
+		Resource: &pb.ResourceID{Resource: input.Resource.Proto(), ResourceType: input.Resource.Type.Serialized()},
+		Latest:   tspb.New(input.Latest),
+	}
+}
+
+func parseInputFreshness(input *pb.InputFreshness) InputFreshness {
+	res := input.GetResource()
+	return InputFreshness{
+		Resource: ResourceID{Name: res.GetResource().GetName(), Variant: res.GetResource().GetVariant(), Type: ResourceType(res.GetResourceType())},
+		Latest:   input.GetLatest().AsTime(),
+	}
+}
+
+func (f TrainingSetFreshness) Serialize() *pb.TrainingSetFreshness {
+	inputs := make([]*pb.InputFreshness, len(f.Inputs))
+	for i, input := range f.Inputs {
+		inputs[i] = input.proto()
+	}
+	return &pb.TrainingSetFreshness{Inputs: inputs, Built: tspb.New(f.Built)}
+}
+
+func parseTrainingSetFreshness(serialized *pb.TrainingSetFreshness) TrainingSetFreshness {
+	inputs := make([]InputFreshness, len(serialized.GetInputs()))
+	for i, input := range serialized.GetInputs() {
+		inputs[i] = parseInputFreshness(input)
+	}
+	return TrainingSetFreshness{Inputs: inputs, Built: serialized.GetBuilt().AsTime()}
+}
+
+// Freshness returns the freshness recorded when the training set was last
+// built, or nil if none has been.
+func (variant *TrainingSetVariant) Freshness() *TrainingSetFreshness {
+	serialized := variant.serialized.GetFreshness()
+	if serialized == nil {
+		return nil
+	}
+	freshness := parseTrainingSetFreshness(serialized)
+	return &freshness
+}
+
+// SetTrainingSetFreshness replaces the freshness of a training set variant.
+func (client *Client) SetTrainingSetFreshness(ctx context.Context, id NameVariant, freshness TrainingSetFreshness) error {
+	req := pb.SetTrainingSetFreshnessRequest{TrainingSet: id.Serialize(), Freshness: freshness.Serialize()}
+	_, err := client.grpcConn.SetTrainingSetFreshness(ctx, &req)
+	return err
+}
+
+// VerifyTrainingSetCutoff returns the inputs of a training set with values
+// from after cutoff. A training set with none only used data up to cutoff.
+func (client *Client) VerifyTrainingSetCutoff(ctx context.Context, id NameVariant, cutoff time.Time) ([]InputFreshness, error) {
+	req := pb.TrainingSetCutoffRequest{TrainingSet: id.Serialize(), Cutoff: tspb.New(cutoff)}
+	result, err := client.grpcConn.VerifyTrainingSetCutoff(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	late := make([]InputFreshness, len(result.GetLateInputs()))
+	for i, input := range result.GetLateInputs() {
+		late[i] = parseInputFreshness(input)
+	}
+	return late, nil
+}
+
+func (serv *MetadataServer) SetTrainingSetFreshness(ctx context.Context, req *pb.SetTrainingSetFreshnessRequest) (*pb.Empty, error) {
+	id := ResourceID{Name: req.GetTrainingSet().GetName(), Variant: req.GetTrainingSet().GetVariant(), Type: TRAINING_SET_VARIANT}
+	res, err := serv.lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	resource, ok := res.(*trainingSetVariantResource)
+	if !ok {
+		return nil, fmt.Errorf("resource %s (%s) isn't a training set variant: %T", id.Name, id.Variant, res)
+	}
+	serialized := proto.Clone(resource.serialized).(*pb.TrainingSetVariant)
+	serialized.Freshness = req.GetFreshness()
+	if err := serv.lookup.Set(id, &trainingSetVariantResource{serialized}); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+// VerifyTrainingSetCutoff checks the freshness recorded for a training set
+// against a cutoff. It fails if none has been recorded, since there's nothing
+// to verify the training set with until it's built.
+func (serv *MetadataServer) VerifyTrainingSetCutoff(ctx context.Context, req *pb.TrainingSetCutoffRequest) (*pb.TrainingSetCutoffResult, error) {
+	id := ResourceID{Name: req.GetTrainingSet().GetName(), Variant: req.GetTrainingSet().GetVariant(), Type: TRAINING_SET_VARIANT}
+	res, err := serv.lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	resource, ok := res.(*trainingSetVariantResource)
+	if !ok {
+		return nil, fmt.Errorf("resource %s (%s) isn't a training set variant: %T", id.Name, id.Variant, res)
+	}
+	if resource.serialized.GetFreshness() == nil {
+		return nil, fmt.Errorf("training set %s (%s) has no recorded freshness", id.Name, id.Variant)
+	}
+	late := parseTrainingSetFreshness(resource.serialized.GetFreshness()).After(req.GetCutoff().AsTime())
+	result := &pb.TrainingSetCutoffResult{Verified: len(late) == 0, LateInputs: make([]*pb.InputFreshness, len(late))}
+	for i, input := range late {
+		result.LateInputs[i] = input.proto()
+	}
+	return result, nil
+}
@@ -0,0 +1,186 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	durpb "google.golang.org/protobuf/types/known/durationpb"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// JobRunPrefix is the etcd prefix that the coordinator records job runs
+// under.
+const JobRunPrefix = "JOBRUN__"
+
+// WorkerLogPrefix is the etcd prefix that workers record what they logged
+// under.
+const WorkerLogPrefix = "WORKERLOG__"
+
+// JobTrigger is what started a coordinator job.
+type JobTrigger string
+
+const (
+	// TriggerRegistration is a job to create a resource when it's registered.
+	TriggerRegistration JobTrigger = "registration"
+	// TriggerRerun is a failed job that was rerun, such as by BulkRerunFailed.
+	TriggerRerun JobTrigger = "rerun"
+	// TriggerReplay is a dead-lettered job that was replayed.
+	TriggerReplay JobTrigger = "replay"
+	// TriggerQueued is a job of a registered job type that was queued.
+	TriggerQueued   JobTrigger = "queued"
+	TriggerSchedule JobTrigger = "schedule"
+	TriggerBackfill JobTrigger = "backfill"
+	// TriggerUpstream is a refresh of a resource that was queued because
+	// what it's made from was updated.
+	TriggerUpstream JobTrigger = "upstream"
+	// TriggerManual is a run that was requested outside a resource's
+	// schedule, such as by TriggerRun.
+	TriggerManual JobTrigger = "manual"
+)
+
+// JobRunStatus is how a job run ended.
+type JobRunStatus string
+
+const (
+	JobRunSucceeded JobRunStatus = "SUCCEEDED"
+	JobRunFailed    JobRunStatus = "FAILED"
+	JobRunCancelled JobRunStatus = "CANCELLED"
+	// JobRunInterrupted is a run that was stopped so that another coordinator
+	// could take over its job, like when its coordinator shut down.
+	JobRunInterrupted JobRunStatus = "INTERRUPTED"
+)
+
+// JobRun is one attempt at running a resource's job.
+type JobRun struct {
+	Resource ResourceID
+	Attempt  uint
+	Trigger  JobTrigger
+	Started  time.Time
+	Ended    time.Time
+	Duration time.Duration
+	Status   JobRunStatus
+	Error    string `json:",omitempty"`
+	// Logs is the end of what the run's runners logged.
+	Logs string `json:",omitempty"`
+	// WorkerLogKeys are where the workers the run started recorded what
+	// they logged.
+	WorkerLogKeys []string `json:",omitempty"`
+	// WorkerLogs are what the run's workers logged, read from
+	// WorkerLogKeys. Logs that have been archived are left out.
+	WorkerLogs []string `json:"-"`
+}
+
+func (r *JobRun) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
+func (r *JobRun) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, r)
+}
+
+// WorkerLog is what a worker logged while running a job. Workers run
+// outside the coordinator, so they record it themselves when they exit, and
+// the coordinator points the job's run at it.
+type WorkerLog struct {
+	Resource ResourceID
+	// Job is the name of the runner the worker ran.
+	Job     string
+	Written time.Time
+	// Logs is the end of what the worker logged.
+	Logs string
+}
+
+func (l *WorkerLog) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
+func (l *WorkerLog) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, l)
+}
+
+// GetWorkerLogPrefix returns the prefix of what the workers of a resource's
+// jobs logged.
+func GetWorkerLogPrefix(id ResourceID) string {
+	return fmt.Sprintf("%s%s__%s__%s__", WorkerLogPrefix, id.Type, id.Name, id.Variant)
+}
+
+// GetWorkerLogKey returns where a worker records what it logged. worker
+// tells apart the workers of a job, like the pods of a chunked job.
+func GetWorkerLogKey(l WorkerLog, worker string) string {
+	return fmt.Sprintf("%s%020d__%s", GetWorkerLogPrefix(l.Resource), l.Written.UnixNano(), worker)
+}
+
+// GetJobRunPrefix returns the prefix of the runs of a resource's jobs.
+func GetJobRunPrefix(id ResourceID) string {
+	return fmt.Sprintf("%s%s__%s__%s__", JobRunPrefix, id.Type, id.Name, id.Variant)
+}
+
+// GetJobRunKey returns a key that sorts the runs of a resource by when they
+// started.
+func GetJobRunKey(run JobRun) string {
+	return fmt.Sprintf("%s%020d__%d", GetJobRunPrefix(run.Resource), run.Started.UnixNano(), run.Attempt)
+}
+
+func (r JobRun) proto() *pb.JobRun {
+	return &pb.JobRun{
+		Resource:   &pb.ResourceID{Resource: &pb.NameVariant{Name: r.Resource.Name, Variant: r.Resource.Variant}, ResourceType: r.Resource.Type.Serialized()},
+		Attempt:    uint32(r.Attempt),
+		Trigger:    string(r.Trigger),
+		Started:    tspb.New(r.Started),
+		Ended:      tspb.New(r.Ended),
+		Duration:   durpb.New(r.Duration),
+		Status:     string(r.Status),
+		Error:      r.Error,
+		Logs:       r.Logs,
+		WorkerLogs: r.WorkerLogs,
+	}
+}
+
+func parseJobRun(run *pb.JobRun) JobRun {
+	res := run.GetResource()
+	return JobRun{
+		Resource:   ResourceID{Name: res.GetResource().GetName(), Variant: res.GetResource().GetVariant(), Type: ResourceType(res.GetResourceType())},
+		Attempt:    uint(run.GetAttempt()),
+		Trigger:    JobTrigger(run.GetTrigger()),
+		Started:    run.GetStarted().AsTime(),
+		Ended:      run.GetEnded().AsTime(),
+		Duration:   run.GetDuration().AsDuration(),
+		Status:     JobRunStatus(run.GetStatus()),
+		Error:      run.GetError(),
+		Logs:       run.GetLogs(),
+		WorkerLogs: run.GetWorkerLogs(),
+	}
+}
+
+// GetJobRuns returns the runs of a resource's jobs, oldest first. Runs are
+// kept until the coordinator archives them.
+func (serv *MetadataServer) GetJobRuns(ctx context.Context, res *pb.ResourceID) (*pb.JobRunList, error) {
+	id := ResourceID{Name: res.GetResource().GetName(), Variant: res.GetResource().GetVariant(), Type: ResourceType(res.GetResourceType())}
+	if _, err := serv.lookup.Lookup(id); err != nil {
+		return nil, err
+	}
+	runs, err := serv.lookup.GetJobRuns(id)
+	if err != nil {
+		return nil, err
+	}
+	list := &pb.JobRunList{Runs: make([]*pb.JobRun, len(runs))}
+	for i, run := range runs {
+		list.Runs[i] = run.proto()
+	}
+	return list, nil
+}
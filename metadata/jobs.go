@@ -0,0 +1,183 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+// CancelJob stops the running job of a resource. The coordinator cancels the
+// job and sets the resource's status to CANCELLED.
+func (serv *MetadataServer) CancelJob(ctx context.Context, req *pb.CancelJobRequest) (*pb.Empty, error) {
+	res := req.GetResource()
+	id := ResourceID{Name: res.GetResource().GetName(), Variant: res.GetResource().GetVariant(), Type: ResourceType(res.GetResourceType())}
+	if _, err := serv.lookup.Lookup(id); err != nil {
+		return nil, err
+	}
+	if err := serv.lookup.CancelJob(id, req.Requester); err != nil {
+		return nil, err
+	}
+	serv.audit("Cancelled job", req.Requester, "resource", id)
+	return &pb.Empty{}, nil
+}
+
+// PauseSchedule suspends the scheduled runs of a resource. Its schedule is
+// kept, and the coordinator suspends its runs until it's resumed.
+func (serv *MetadataServer) PauseSchedule(ctx context.Context, req *pb.PauseScheduleRequest) (*pb.Empty, error) {
+	id, schedule, err := serv.scheduledResource(req.GetResource())
+	if err != nil {
+		return nil, err
+	}
+	if err := serv.lookup.PauseSchedule(id, schedule, req.Reason, req.Requester); err != nil {
+		return nil, err
+	}
+	serv.audit("Paused schedule", req.Requester, "resource", id, "reason", req.Reason)
+	return &pb.Empty{}, nil
+}
+
+// ResumeSchedule resumes the scheduled runs of a paused resource.
+func (serv *MetadataServer) ResumeSchedule(ctx context.Context, req *pb.ResumeScheduleRequest) (*pb.Empty, error) {
+	id, schedule, err := serv.scheduledResource(req.GetResource())
+	if err != nil {
+		return nil, err
+	}
+	if err := serv.lookup.ResumeSchedule(id, schedule); err != nil {
+		return nil, err
+	}
+	serv.audit("Resumed schedule", req.Requester, "resource", id)
+	return &pb.Empty{}, nil
+}
+
+// TriggerRun queues an immediate run of a feature's materialization, a
+// transformation or a training set, outside its schedule. The coordinator
+// updates the resource once, the way a scheduled run does, and records the
+// run in its job history as a manual run.
+func (serv *MetadataServer) TriggerRun(ctx context.Context, req *pb.TriggerRunRequest) (*pb.Empty, error) {
+	res := req.GetResource()
+	id := ResourceID{Name: res.GetResource().GetName(), Variant: res.GetResource().GetVariant(), Type: ResourceType(res.GetResourceType())}
+	resource, err := serv.lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	if !canTriggerRun(resource) {
+		return nil, fmt.Errorf("%s %s (%s) can't be run outside its job", id.Type, id.Name, id.Variant)
+	}
+	if status := resourceStatus(resource); status != READY {
+		return nil, fmt.Errorf("%s %s (%s) is %s, and only ready resources can be run", id.Type, id.Name, id.Variant, status)
+	}
+	if err := serv.lookup.TriggerRun(id, resource.Schedule()); err != nil {
+		return nil, err
+	}
+	serv.audit("Triggered run", req.Requester, "resource", id)
+	return &pb.Empty{}, nil
+}
+
+// canTriggerRun reports whether a resource has a run that updates it, which
+// features, SQL transformations and training sets do.
+func canTriggerRun(res Resource) bool {
+	switch variant := res.Proto().(type) {
+	case *pb.FeatureVariant, *pb.TrainingSetVariant:
+		return true
+	case *pb.SourceVariant:
+		return variant.GetTransformation().GetSQLTransformation() != nil
+	default:
+		return false
+	}
+}
+
+func (serv *MetadataServer) scheduledResource(res *pb.ResourceID) (ResourceID, string, error) {
+	id := ResourceID{Name: res.GetResource().GetName(), Variant: res.GetResource().GetVariant(), Type: ResourceType(res.GetResourceType())}
+	resource, err := serv.lookup.Lookup(id)
+	if err != nil {
+		return ResourceID{}, "", err
+	}
+	schedule := resource.Schedule()
+	if schedule == "" {
+		return ResourceID{}, "", fmt.Errorf("%s %s (%s) has no schedule", id.Type, id.Name, id.Variant)
+	}
+	return id, schedule, nil
+}
+
+// JobPublisher announces that a coordinator job was written under key, for
+// coordinators that get their jobs from a queue.
+type JobPublisher interface {
+	Publish(ctx context.Context, key string) error
+}
+
+// publishingResourceLookup publishes each job once the wrapped lookup has
+// stored it.
+type publishingResourceLookup struct {
+	publisher JobPublisher
+	ResourceLookup
+}
+
+func (lookup publishingResourceLookup) SetJob(id ResourceID, schedule string) error {
+	if err := lookup.ResourceLookup.SetJob(id, schedule); err != nil {
+		return err
+	}
+	return lookup.publish(id)
+}
+
+func (lookup publishingResourceLookup) ResetJob(id ResourceID, schedule string) error {
+	if err := lookup.ResourceLookup.ResetJob(id, schedule); err != nil {
+		return err
+	}
+	return lookup.publish(id)
+}
+
+func (lookup publishingResourceLookup) TriggerRun(id ResourceID, schedule string) error {
+	if err := lookup.ResourceLookup.TriggerRun(id, schedule); err != nil {
+		return err
+	}
+	return lookup.publishKey(id, GetManualRunKey(id))
+}
+
+func (lookup publishingResourceLookup) ReplayDeadLetter(id ResourceID) error {
+	if err := lookup.ResourceLookup.ReplayDeadLetter(id); err != nil {
+		return err
+	}
+	return lookup.publish(id)
+}
+
+func (lookup publishingResourceLookup) MigrateOnlineStore(migration OnlineMigration) error {
+	if err := lookup.ResourceLookup.MigrateOnlineStore(migration); err != nil {
+		return err
+	}
+	return lookup.publishKey(ResourceID{Name: migration.Destination, Type: PROVIDER}, GetOnlineMigrationKey(migration.Destination))
+}
+
+func (lookup publishingResourceLookup) DeleteOrphanedTables(deletion OrphanDeletion) error {
+	if err := lookup.ResourceLookup.DeleteOrphanedTables(deletion); err != nil {
+		return err
+	}
+	return lookup.publishKey(ResourceID{Name: deletion.Provider, Type: PROVIDER}, GetOrphanDeletionKey(deletion.Provider))
+}
+
+func (lookup publishingResourceLookup) UnlockResource(id ResourceID) ([]string, error) {
+	keys, err := lookup.ResourceLookup.UnlockResource(id)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if err := lookup.publishKey(id, key); err != nil {
+			return nil, err
+		}
+	}
+	return keys, nil
+}
+
+func (lookup publishingResourceLookup) publish(id ResourceID) error {
+	return lookup.publishKey(id, GetJobKey(id))
+}
+
+func (lookup publishingResourceLookup) publishKey(id ResourceID, key string) error {
+	if err := lookup.publisher.Publish(context.Background(), key); err != nil {
+		return fmt.Errorf("publish job for %s %s (%s): %w", id.Type, id.Name, id.Variant, err)
+	}
+	return nil
+}
@@ -63,6 +63,7 @@ const (
 	PENDING                  = ResourceStatus(pb.ResourceStatus_PENDING)
 	READY                    = ResourceStatus(pb.ResourceStatus_READY)
 	FAILED                   = ResourceStatus(pb.ResourceStatus_FAILED)
+	CANCELLED                = ResourceStatus(pb.ResourceStatus_CANCELLED)
 )
 
 func (r ResourceStatus) String() string {
@@ -87,6 +88,10 @@ func (serv *MetadataServer) needsJob(res Resource) bool {
 		res.ID().Type == LABEL_VARIANT {
 		return true
 	}
+	// A provider's job schedules its maintenance.
+	if provider, ok := res.(*providerResource); ok {
+		return len(provider.serialized.GetMaintenance()) > 0
+	}
 	return false
 }
 
@@ -213,6 +218,42 @@ type ResourceLookup interface {
 	SetJob(ResourceID, string) error
 	SetStatus(ResourceID, pb.ResourceStatus) error
 	SetSchedule(ResourceID, string) error
+	// ResetJob replaces any job for a resource with a new one, so that a
+	// failed job runs again from its first attempt.
+	ResetJob(ResourceID, string) error
+	LockResource(ResourceID, string) error
+	// UnlockResource releases a resource's maintenance lock and resubmits the
+	// jobs it held back, returning the keys of those jobs.
+	UnlockResource(ResourceID) ([]string, error)
+	// CancelJob signals the coordinator to stop the running job for a
+	// resource.
+	CancelJob(ResourceID, string) error
+	// PauseSchedule suspends the scheduled runs of a resource, keeping its
+	// schedule, until ResumeSchedule is called.
+	PauseSchedule(id ResourceID, schedule, reason, requester string) error
+	ResumeSchedule(id ResourceID, schedule string) error
+	// TriggerRun queues a run of a resource's job outside its schedule.
+	TriggerRun(id ResourceID, schedule string) error
+	// RecordAudit keeps an audit event, so it outlives the server's logs.
+	RecordAudit(AuditEvent) error
+	// GetJobRuns returns the recorded runs of a resource's jobs, oldest first.
+	GetJobRuns(ResourceID) ([]JobRun, error)
+	// SetNotification registers a notification, replacing any with its name.
+	SetNotification(Notification) error
+	DeleteNotification(name string) error
+	ListNotifications() ([]Notification, error)
+	// ListDeadLetters returns the jobs that failed for good and haven't been
+	// replayed.
+	ListDeadLetters() ([]DeadLetter, error)
+	// ReplayDeadLetter resubmits a resource's dead-lettered job from its
+	// first attempt.
+	ReplayDeadLetter(ResourceID) error
+	// MigrateOnlineStore queues a job that moves features to another online
+	// provider.
+	MigrateOnlineStore(OnlineMigration) error
+	// DeleteOrphanedTables queues a job that drops orphaned tables from a
+	// provider.
+	DeleteOrphanedTables(OrphanDeletion) error
 }
 
 type TypeSenseWrapper struct {
@@ -314,6 +355,70 @@ func (lookup localResourceLookup) HasJob(id ResourceID) (bool, error) {
 	return false, nil
 }
 
+func (lookup localResourceLookup) ResetJob(id ResourceID, schedule string) error {
+	return nil
+}
+
+func (lookup localResourceLookup) LockResource(id ResourceID, reason string) error {
+	return nil
+}
+
+func (lookup localResourceLookup) UnlockResource(id ResourceID) ([]string, error) {
+	return nil, nil
+}
+
+func (lookup localResourceLookup) CancelJob(id ResourceID, requester string) error {
+	return nil
+}
+
+func (lookup localResourceLookup) PauseSchedule(id ResourceID, schedule, reason, requester string) error {
+	return nil
+}
+
+func (lookup localResourceLookup) ResumeSchedule(id ResourceID, schedule string) error {
+	return nil
+}
+
+func (lookup localResourceLookup) TriggerRun(id ResourceID, schedule string) error {
+	return nil
+}
+
+func (lookup localResourceLookup) RecordAudit(event AuditEvent) error {
+	return nil
+}
+
+func (lookup localResourceLookup) GetJobRuns(id ResourceID) ([]JobRun, error) {
+	return []JobRun{}, nil
+}
+
+func (lookup localResourceLookup) SetNotification(n Notification) error {
+	return nil
+}
+
+func (lookup localResourceLookup) DeleteNotification(name string) error {
+	return nil
+}
+
+func (lookup localResourceLookup) ListNotifications() ([]Notification, error) {
+	return []Notification{}, nil
+}
+
+func (lookup localResourceLookup) ListDeadLetters() ([]DeadLetter, error) {
+	return []DeadLetter{}, nil
+}
+
+func (lookup localResourceLookup) ReplayDeadLetter(id ResourceID) error {
+	return nil
+}
+
+func (lookup localResourceLookup) MigrateOnlineStore(migration OnlineMigration) error {
+	return nil
+}
+
+func (lookup localResourceLookup) DeleteOrphanedTables(deletion OrphanDeletion) error {
+	return nil
+}
+
 type sourceResource struct {
 	serialized *pb.Source
 }
@@ -416,13 +521,19 @@ func (this *sourceVariantResource) Notify(lookup ResourceLookup, op operation, t
 }
 
 func (resource *sourceVariantResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.LastUpdated = tspb.Now()
+	now := time.Now()
+	resource.serialized.LastUpdated = tspb.New(now)
+	resource.serialized.NextRun = nextRun(resource.serialized.Schedule, now)
 	resource.serialized.Status = &status
 	return nil
 }
 
 func (resource *sourceVariantResource) UpdateSchedule(schedule string) error {
+	if _, err := ParseSchedule(schedule); err != nil {
+		return err
+	}
 	resource.serialized.Schedule = schedule
+	resource.serialized.NextRun = nextRun(schedule, time.Now())
 	return nil
 }
 
@@ -532,13 +643,19 @@ func (this *featureVariantResource) Notify(lookup ResourceLookup, op operation,
 }
 
 func (resource *featureVariantResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.LastUpdated = tspb.Now()
+	now := time.Now()
+	resource.serialized.LastUpdated = tspb.New(now)
+	resource.serialized.NextRun = nextRun(resource.serialized.Schedule, now)
 	resource.serialized.Status = &status
 	return nil
 }
 
 func (resource *featureVariantResource) UpdateSchedule(schedule string) error {
+	if _, err := ParseSchedule(schedule); err != nil {
+		return err
+	}
 	resource.serialized.Schedule = schedule
+	resource.serialized.NextRun = nextRun(schedule, time.Now())
 	return nil
 }
 
@@ -625,6 +742,9 @@ func (resource *labelVariantResource) Dependencies(lookup ResourceLookup) (Resou
 			Type: LABEL,
 		},
 	}
+	if online := serialized.OnlineProvider; online != "" && online != serialized.Provider {
+		depIds = append(depIds, ResourceID{Name: online, Type: PROVIDER})
+	}
 	deps, err := lookup.Submap(depIds)
 	if err != nil {
 		return nil, err
@@ -758,13 +878,19 @@ func (this *trainingSetVariantResource) Notify(lookup ResourceLookup, op operati
 }
 
 func (resource *trainingSetVariantResource) UpdateStatus(status pb.ResourceStatus) error {
-	resource.serialized.LastUpdated = tspb.Now()
+	now := time.Now()
+	resource.serialized.LastUpdated = tspb.New(now)
+	resource.serialized.NextRun = nextRun(resource.serialized.Schedule, now)
 	resource.serialized.Status = &status
 	return nil
 }
 
 func (resource *trainingSetVariantResource) UpdateSchedule(schedule string) error {
+	if _, err := ParseSchedule(schedule); err != nil {
+		return err
+	}
 	resource.serialized.Schedule = schedule
+	resource.serialized.NextRun = nextRun(schedule, time.Now())
 	return nil
 }
 
@@ -990,11 +1116,13 @@ func (resource *entityResource) UpdateSchedule(schedule string) error {
 }
 
 type MetadataServer struct {
-	Logger     *zap.SugaredLogger
-	lookup     ResourceLookup
-	address    string
-	grpcServer *grpc.Server
-	listener   net.Listener
+	Logger           *zap.SugaredLogger
+	lookup           ResourceLookup
+	validateProvider ProviderValidator
+	planJobs         JobPlanner
+	address          string
+	grpcServer       *grpc.Server
+	listener         net.Listener
 	pb.UnimplementedMetadataServer
 }
 
@@ -1005,7 +1133,15 @@ func NewMetadataServer(config *Config) (*MetadataServer, error) {
 	if err != nil {
 		return nil, err
 	}
-	if config.TypeSenseParams != nil {
+	if config.KeyWrapper != nil {
+		lookup = encryptedResourceLookup{config.KeyWrapper, lookup}
+	}
+	if config.JobPublisher != nil {
+		lookup = publishingResourceLookup{config.JobPublisher, lookup}
+	}
+	if config.ReadOnly {
+		lookup = newReadOnlyResourceLookup(lookup, config.CacheTTL)
+	} else if config.TypeSenseParams != nil {
 		searcher, errInitializeSearch := search.NewTypesenseSearch(config.TypeSenseParams)
 		if errInitializeSearch != nil {
 			return nil, errInitializeSearch
@@ -1016,9 +1152,11 @@ func NewMetadataServer(config *Config) (*MetadataServer, error) {
 		}
 	}
 	return &MetadataServer{
-		lookup:  lookup,
-		address: config.Address,
-		Logger:  config.Logger,
+		lookup:           lookup,
+		validateProvider: config.ProviderValidator,
+		planJobs:         config.JobPlanner,
+		address:          config.Address,
+		Logger:           config.Logger,
 	}, nil
 }
 
@@ -1097,14 +1235,144 @@ type Config struct {
 	TypeSenseParams *search.TypeSenseParams
 	StorageProvider StorageProvider
 	Address         string
-}
+	// ReadOnly runs the server as a mirror that rejects all writes and serves
+	// reads from a cache that is refreshed every CacheTTL.
+	ReadOnly bool
+	CacheTTL time.Duration
+	// ProviderValidator checks new provider configs before they are stored.
+	ProviderValidator ProviderValidator
+	// KeyWrapper enables envelope encryption of provider configs at rest.
+	KeyWrapper KeyWrapper
+	// JobPublisher announces the jobs the server creates to coordinators that
+	// don't watch etcd for them.
+	JobPublisher JobPublisher
+	// JobPlanner plans jobs for PlanJobs, which isn't served without one.
+	JobPlanner JobPlanner
+}
+
+// ProviderValidator is called with a provider's type and serialized config
+// and returns an error if the provider cannot be connected to.
+type ProviderValidator func(providerType string, config []byte) error
 
 func (serv *MetadataServer) RequestScheduleChange(ctx context.Context, req *pb.ScheduleChangeRequest) (*pb.Empty, error) {
 	resID := ResourceID{Name: req.ResourceId.Resource.Name, Variant: req.ResourceId.Resource.Variant, Type: ResourceType(req.ResourceId.ResourceType)}
+	if _, err := ParseSchedule(req.Schedule); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s %s (%s): %v", resID.Type, resID.Name, resID.Variant, err)
+	}
 	err := serv.lookup.SetSchedule(resID, req.Schedule)
 	return &pb.Empty{}, err
 }
 
+func (serv *MetadataServer) UpdateProviderConfig(ctx context.Context, req *pb.ProviderConfigUpdate) (*pb.Empty, error) {
+	id := ResourceID{Name: req.Name, Type: PROVIDER}
+	res, err := serv.lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := res.(*providerResource)
+	if !ok {
+		return nil, fmt.Errorf("resource %s is not a provider: %T", req.Name, res)
+	}
+	if serv.validateProvider != nil {
+		if err := serv.validateProvider(provider.serialized.Type, req.SerializedConfig); err != nil {
+			serv.Logger.Errorw("Rejected provider config update", "provider", req.Name, "error", err)
+			return nil, status.Errorf(codes.InvalidArgument, "invalid config for provider %s: %v", req.Name, err)
+		}
+	}
+	updated := proto.Clone(provider.serialized).(*pb.Provider)
+	updated.SerializedConfig = req.SerializedConfig
+	if err := serv.lookup.Set(id, &providerResource{updated}); err != nil {
+		return nil, err
+	}
+	serv.audit("Provider config rotated", req.Requester, "provider", req.Name, "type", updated.Type)
+	return &pb.Empty{}, nil
+}
+
+// UpdateFeatureVariantProvider points a feature variant at a different online
+// provider. It's the cutover step of an online store migration, so it expects
+// the values to already have been copied to the new provider.
+func (serv *MetadataServer) UpdateFeatureVariantProvider(ctx context.Context, req *pb.FeatureProviderUpdate) (*pb.Empty, error) {
+	id := ResourceID{Name: req.Feature.GetName(), Variant: req.Feature.GetVariant(), Type: FEATURE_VARIANT}
+	res, err := serv.lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	feature, ok := res.(*featureVariantResource)
+	if !ok {
+		return nil, fmt.Errorf("resource %s (%s) is not a feature variant: %T", id.Name, id.Variant, res)
+	}
+	oldID := ResourceID{Name: feature.serialized.Provider, Type: PROVIDER}
+	newID := ResourceID{Name: req.Provider, Type: PROVIDER}
+	if oldID == newID {
+		return &pb.Empty{}, nil
+	}
+	oldRes, err := serv.lookup.Lookup(oldID)
+	if err != nil {
+		return nil, err
+	}
+	newRes, err := serv.lookup.Lookup(newID)
+	if err != nil {
+		return nil, err
+	}
+	oldProvider, ok := oldRes.(*providerResource)
+	if !ok {
+		return nil, fmt.Errorf("resource %s is not a provider: %T", oldID.Name, oldRes)
+	}
+	newProvider, ok := newRes.(*providerResource)
+	if !ok {
+		return nil, fmt.Errorf("resource %s is not a provider: %T", newID.Name, newRes)
+	}
+	updatedFeature := proto.Clone(feature.serialized).(*pb.FeatureVariant)
+	updatedFeature.Provider = req.Provider
+	updatedOld := proto.Clone(oldProvider.serialized).(*pb.Provider)
+	features := make([]*pb.NameVariant, 0, len(updatedOld.Features))
+	for _, nv := range updatedOld.Features {
+		if nv.Name != id.Name || nv.Variant != id.Variant {
+			features = append(features, nv)
+		}
+	}
+	updatedOld.Features = features
+	updatedNew := proto.Clone(newProvider.serialized).(*pb.Provider)
+	updatedNew.Features = append(updatedNew.Features, id.Proto())
+	if err := serv.lookup.Set(newID, &providerResource{updatedNew}); err != nil {
+		return nil, err
+	}
+	if err := serv.lookup.Set(id, &featureVariantResource{updatedFeature}); err != nil {
+		return nil, err
+	}
+	if err := serv.lookup.Set(oldID, &providerResource{updatedOld}); err != nil {
+		return nil, err
+	}
+	serv.audit("Feature variant provider changed", req.Requester, "name", id.Name, "variant", id.Variant, "from", oldID.Name, "to", newID.Name)
+	return &pb.Empty{}, nil
+}
+
+// SetResourceStats replaces the table stats of a source or feature variant.
+func (serv *MetadataServer) SetResourceStats(ctx context.Context, req *pb.SetStatsRequest) (*pb.Empty, error) {
+	id := ResourceID{Name: req.ResourceId.Resource.Name, Variant: req.ResourceId.Resource.Variant, Type: ResourceType(req.ResourceId.ResourceType)}
+	res, err := serv.lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	var updated Resource
+	switch resource := res.(type) {
+	case *featureVariantResource:
+		serialized := proto.Clone(resource.serialized).(*pb.FeatureVariant)
+		serialized.Stats = req.Stats
+		updated = &featureVariantResource{serialized}
+	case *sourceVariantResource:
+		serialized := proto.Clone(resource.serialized).(*pb.SourceVariant)
+		serialized.Stats = req.Stats
+		updated = &sourceVariantResource{serialized}
+	default:
+		return nil, fmt.Errorf("resource %s (%s) has no table stats: %T", id.Name, id.Variant, res)
+	}
+	if err := serv.lookup.Set(id, updated); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
 func (serv *MetadataServer) SetResourceStatus(ctx context.Context, req *pb.SetStatusRequest) (*pb.Empty, error) {
 	serv.Logger.Infow("Setting resource status", "request", req.String())
 	resID := ResourceID{Name: req.ResourceId.Resource.Name, Variant: req.ResourceId.Resource.Variant, Type: ResourceType(req.ResourceId.ResourceType)}
@@ -1188,6 +1456,13 @@ func (serv *MetadataServer) ListTrainingSets(_ *pb.Empty, stream pb.Metadata_Lis
 
 func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, variant *pb.TrainingSetVariant) (*pb.Empty, error) {
 	variant.Created = tspb.New(time.Now())
+	if variant.PinDependencies {
+		pins, err := serv.pinDependencies(variant)
+		if err != nil {
+			return nil, err
+		}
+		variant.Pins = pins
+	}
 	return serv.genericCreate(ctx, &trainingSetVariantResource{variant}, func(name, variant string) Resource {
 		return &trainingSetResource{
 			&pb.TrainingSet{
@@ -1200,6 +1475,34 @@ func (serv *MetadataServer) CreateTrainingSetVariant(ctx context.Context, varian
 	})
 }
 
+// pinDependencies records the created time of a training set's features and
+// label, so the coordinator can tell if one of them is replaced later.
+func (serv *MetadataServer) pinDependencies(variant *pb.TrainingSetVariant) ([]*pb.VariantPin, error) {
+	ids := []ResourceID{{Name: variant.Label.GetName(), Variant: variant.Label.GetVariant(), Type: LABEL_VARIANT}}
+	for _, feature := range variant.Features {
+		ids = append(ids, ResourceID{Name: feature.Name, Variant: feature.Variant, Type: FEATURE_VARIANT})
+	}
+	pins := make([]*pb.VariantPin, len(ids))
+	for i, id := range ids {
+		res, err := serv.lookup.Lookup(id)
+		if err != nil {
+			return nil, err
+		}
+		getter, ok := res.Proto().(createdGetter)
+		if !ok {
+			return nil, fmt.Errorf("%s %s (%s) has no created time to pin", id.Type, id.Name, id.Variant)
+		}
+		pins[i] = &pb.VariantPin{
+			Resource: &pb.ResourceID{
+				Resource:     &pb.NameVariant{Name: id.Name, Variant: id.Variant},
+				ResourceType: id.Type.Serialized(),
+			},
+			Created: getter.GetCreated(),
+		}
+	}
+	return pins, nil
+}
+
 func (serv *MetadataServer) GetTrainingSets(stream pb.Metadata_GetTrainingSetsServer) error {
 	return serv.genericGet(stream, TRAINING_SET, func(msg proto.Message) error {
 		return stream.Send(msg.(*pb.TrainingSet))
@@ -1268,6 +1571,14 @@ func (serv *MetadataServer) ListProviders(_ *pb.Empty, stream pb.Metadata_ListPr
 }
 
 func (serv *MetadataServer) CreateProvider(ctx context.Context, provider *pb.Provider) (*pb.Empty, error) {
+	for _, task := range provider.GetMaintenance() {
+		if task.GetKind() == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "provider %s: maintenance task has no kind", provider.GetName())
+		}
+		if _, err := ParseSchedule(task.GetSchedule()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "provider %s: %s maintenance: %v", provider.GetName(), task.GetKind(), err)
+		}
+	}
 	return serv.genericCreate(ctx, &providerResource{provider}, nil)
 }
 
@@ -1332,6 +1643,12 @@ func (serv *MetadataServer) genericCreate(ctx context.Context, res Resource, ini
 	} else if has {
 		return nil, &ResourceExists{id}
 	}
+	// Setting the schedule again validates it and records the next run.
+	if schedule := res.Schedule(); schedule != "" {
+		if err := res.UpdateSchedule(schedule); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "%s %s (%s): %v", id.Type, id.Name, id.Variant, err)
+		}
+	}
 	if err := serv.lookup.Set(id, res); err != nil {
 		return nil, err
 	}
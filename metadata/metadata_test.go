@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"net"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	pb "github.com/featureform/metadata/proto"
 	"github.com/google/uuid"
 	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 func TestResourceTypes(t *testing.T) {
@@ -1557,3 +1561,824 @@ func TestBannedStrings(t *testing.T) {
 		t.Fatalf("valid resource triggered an error")
 	}
 }
+
+func TestReadOnlyResourceLookup(t *testing.T) {
+	local := make(localResourceLookup)
+	id := ResourceID{Name: "Featureform", Type: USER}
+	if err := local.Set(id, &userResource{&pb.User{Name: "Featureform"}}); err != nil {
+		t.Fatalf("Failed to set resource: %s", err)
+	}
+	lookup := newReadOnlyResourceLookup(local, time.Hour)
+	if _, err := lookup.Lookup(id); err != nil {
+		t.Fatalf("Failed to lookup resource: %s", err)
+	}
+	if users, err := lookup.ListForType(USER); err != nil || len(users) != 1 {
+		t.Fatalf("Failed to list resources: %v %s", users, err)
+	}
+	// Cached reads must survive the resource disappearing from the store.
+	delete(local, id)
+	if _, err := lookup.Lookup(id); err != nil {
+		t.Fatalf("Lookup not served from cache: %s", err)
+	}
+	if users, err := lookup.ListForType(USER); err != nil || len(users) != 1 {
+		t.Fatalf("List not served from cache: %v %s", users, err)
+	}
+	other := ResourceID{Name: "other", Type: USER}
+	if _, err := lookup.Lookup(other); err == nil {
+		t.Fatalf("Succeeded in looking up missing resource")
+	}
+	writes := map[string]func() error{
+		"Set":              func() error { return lookup.Set(other, &userResource{&pb.User{Name: "other"}}) },
+		"SetJob":           func() error { return lookup.SetJob(id, "") },
+		"SetStatus":        func() error { return lookup.SetStatus(id, pb.ResourceStatus{}) },
+		"SetSchedule":      func() error { return lookup.SetSchedule(id, "* * * * *") },
+		"PauseSchedule":    func() error { return lookup.PauseSchedule(id, "* * * * *", "maintenance", "test") },
+		"ResumeSchedule":   func() error { return lookup.ResumeSchedule(id, "* * * * *") },
+		"ReplayDeadLetter": func() error { return lookup.ReplayDeadLetter(id) },
+		"MigrateOnlineStore": func() error {
+			return lookup.MigrateOnlineStore(OnlineMigration{Features: []NameVariant{{Name: "f"}}, Destination: "redis"})
+		},
+		"DeleteOrphanedTables": func() error {
+			return lookup.DeleteOrphanedTables(OrphanDeletion{Provider: "postgres", Tables: []OrphanedTable{{Name: "f"}}})
+		},
+	}
+	for name, write := range writes {
+		err := write()
+		if _, ok := err.(*ReadOnlyError); !ok {
+			t.Fatalf("%s not rejected by read-only lookup: %v", name, err)
+		}
+	}
+	if has, _ := local.Has(other); has {
+		t.Fatalf("Read-only lookup wrote to store")
+	}
+}
+
+func TestPlanJobs(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	config := &Config{
+		Logger:          logger.Sugar(),
+		StorageProvider: LocalStorageProvider{},
+		// The planner depends each feature on its source, which it has to be
+		// able to read whether it's registered or only being planned.
+		JobPlanner: func(ctx context.Context, resources ResourceReader, ids []ResourceID) ([]JobPlan, error) {
+			plans := make([]JobPlan, 0)
+			for _, id := range ids {
+				plan := JobPlan{Resource: id}
+				if id.Type == FEATURE_VARIANT {
+					feature, err := resources.GetFeatureVariant(ctx, NameVariant{Name: id.Name, Variant: id.Variant})
+					if err != nil {
+						return nil, err
+					}
+					source, err := resources.GetSourceVariant(ctx, feature.Source())
+					if err != nil {
+						return nil, err
+					}
+					if _, err := resources.GetProvider(ctx, source.Provider()); err != nil {
+						plan.AddProblem("provider %s can't be found", source.Provider())
+					}
+					plan.Dependencies = []ResourceID{{Name: source.Name(), Variant: source.Variant(), Type: SOURCE_VARIANT}}
+				}
+				plans = append(plans, plan)
+			}
+			return plans, nil
+		},
+	}
+	serv, err := NewMetadataServer(config)
+	if err != nil {
+		t.Fatalf("Failed to create metadata server: %s", err)
+	}
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go func() {
+		if err := serv.ServeOnListener(lis); err != nil {
+			t.Logf("Server error: %s", err)
+		}
+	}()
+	defer serv.Stop()
+	client := client(t, lis.Addr().String())
+	ctx := context.Background()
+	registered := []ResourceDef{
+		UserDef{Name: "Featureform"},
+		ProviderDef{Name: "mockOffline", Type: "POSTGRES_OFFLINE", SerializedConfig: []byte("{}")},
+		SourceDef{
+			Name:       "users",
+			Variant:    "default",
+			Owner:      "Featureform",
+			Provider:   "mockOffline",
+			Definition: PrimaryDataSource{Location: SQLTable{Name: "users"}},
+		},
+	}
+	if err := client.CreateAll(ctx, registered); err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	columns := ResourceVariantColumns{Entity: "user", Value: "value", TS: "ts"}
+	defs := []ResourceDef{
+		EntityDef{Name: "user"},
+		SourceDef{
+			Name:       "active_users",
+			Variant:    "v1",
+			Owner:      "Featureform",
+			Provider:   "newOffline",
+			Definition: PrimaryDataSource{Location: SQLTable{Name: "active_users"}},
+		},
+		ProviderDef{Name: "newOffline", Type: "POSTGRES_OFFLINE", SerializedConfig: []byte("{}")},
+		FeatureDef{Name: "age", Variant: "v1", Source: NameVariant{"users", "default"}, Type: "int", Entity: "user", Owner: "Featureform", Location: columns},
+		FeatureDef{Name: "active", Variant: "v1", Source: NameVariant{"active_users", "v1"}, Type: "bool", Entity: "user", Owner: "Featureform", Location: columns},
+	}
+	plans, err := client.PlanJobs(ctx, defs)
+	if err != nil {
+		t.Fatalf("Failed to plan jobs: %s", err)
+	}
+	if len(plans) != 3 {
+		t.Fatalf("Expected plans for the source and both features, got %+v", plans)
+	}
+	deps := map[string]string{}
+	for _, plan := range plans {
+		if len(plan.Problems) != 0 {
+			t.Fatalf("Expected no problems, got %+v", plan)
+		}
+		if plan.Resource.Type == FEATURE_VARIANT {
+			deps[plan.Resource.Name] = plan.Dependencies[0].Name
+		}
+	}
+	if deps["age"] != "users" || deps["active"] != "active_users" {
+		t.Fatalf("Expected features to depend on their sources, got %v", deps)
+	}
+	if _, err := client.GetFeatureVariant(ctx, NameVariant{"age", "v1"}); err == nil {
+		t.Fatalf("Planning registered a feature")
+	}
+	if _, err := client.GetProvider(ctx, "newOffline"); err == nil {
+		t.Fatalf("Planning registered a provider")
+	}
+}
+
+func TestUpdateProviderConfig(t *testing.T) {
+	logger := zaptest.NewLogger(t)
+	config := &Config{
+		Logger:          logger.Sugar(),
+		StorageProvider: LocalStorageProvider{},
+		ProviderValidator: func(providerType string, config []byte) error {
+			if string(config) == "bad" {
+				return fmt.Errorf("bad credentials")
+			}
+			return nil
+		},
+	}
+	serv, err := NewMetadataServer(config)
+	if err != nil {
+		t.Fatalf("Failed to create metadata server: %s", err)
+	}
+	lis, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	go func() {
+		if err := serv.ServeOnListener(lis); err != nil {
+			t.Logf("Server error: %s", err)
+		}
+	}()
+	defer serv.Stop()
+	client := client(t, lis.Addr().String())
+	ctx := context.Background()
+	def := ProviderDef{
+		Name:             "mockOnline",
+		Type:             "REDIS_ONLINE",
+		SerializedConfig: []byte("old"),
+	}
+	if err := client.CreateProvider(ctx, def); err != nil {
+		t.Fatalf("Failed to create provider: %s", err)
+	}
+	if err := client.UpdateProviderConfig(ctx, def.Name, []byte("bad"), "test"); err == nil {
+		t.Fatalf("Succeeded in updating provider with invalid config")
+	}
+	if err := client.UpdateProviderConfig(ctx, def.Name, []byte("new"), "test"); err != nil {
+		t.Fatalf("Failed to update provider config: %s", err)
+	}
+	provider, err := client.GetProvider(ctx, def.Name)
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	assertEqual(t, string(provider.SerializedConfig()), "new")
+	if err := client.UpdateProviderConfig(ctx, "missing", []byte("new"), "test"); err == nil {
+		t.Fatalf("Succeeded in updating missing provider")
+	}
+}
+
+func TestUpdateFeatureVariantProvider(t *testing.T) {
+	defs := append(filledResourceDefs(), ProviderDef{
+		Name:             "mockOnline2",
+		Description:      "Another mock online provider",
+		Type:             "DYNAMO-ONLINE",
+		Software:         "dynamodb",
+		Team:             "fraud",
+		SerializedConfig: []byte("ONLINE CONFIG"),
+	})
+	ctx := testContext{Defs: defs}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	id := NameVariant{Name: "feature", Variant: "variant"}
+	if err := client.UpdateFeatureVariantProvider(context.Background(), id, "mockOnline2", "test"); err != nil {
+		t.Fatalf("Failed to update feature provider: %s", err)
+	}
+	feature, err := client.GetFeatureVariant(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	assertEqual(t, feature.Provider(), "mockOnline2")
+	oldProvider, err := client.GetProvider(context.Background(), "mockOnline")
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	for _, nv := range oldProvider.Features() {
+		if nv == id {
+			t.Fatalf("Old provider still lists feature: %v", oldProvider.Features())
+		}
+	}
+	newProvider, err := client.GetProvider(context.Background(), "mockOnline2")
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	assertEquivalentNameVariants(t, newProvider.Features(), []NameVariant{id})
+	if err := client.UpdateFeatureVariantProvider(context.Background(), id, "missing", "test"); err == nil {
+		t.Fatalf("Succeeded in moving feature to missing provider")
+	}
+}
+
+func TestBulkOperations(t *testing.T) {
+	ctx := testContext{Defs: filledResourceDefs()}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	failed := []ResourceID{
+		{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT},
+		{Name: "feature2", Variant: "variant", Type: FEATURE_VARIANT},
+		{Name: "training-set", Variant: "variant", Type: TRAINING_SET_VARIANT},
+	}
+	if _, err := client.BulkSetStatus(bg, append(failed, ResourceID{Name: "missing", Type: FEATURE_VARIANT}), FAILED, "", false, "test"); err == nil {
+		t.Fatalf("Succeeded in setting status on a missing resource")
+	}
+	if _, err := client.BulkSetStatus(bg, failed, FAILED, "broken", false, "test"); err != nil {
+		t.Fatalf("Failed to set statuses: %s", err)
+	}
+	rerun, err := client.BulkRerunFailed(bg, BulkFilter{Provider: "mockOnline"}, true, "test")
+	if err != nil {
+		t.Fatalf("Failed to dry run rerun: %s", err)
+	}
+	if len(rerun) != 2 {
+		t.Fatalf("Expected 2 failed online features, got %v", rerun)
+	}
+	feature, err := client.GetFeatureVariant(bg, NameVariant{Name: "feature", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	assertEqual(t, feature.Status(), FAILED)
+	rerun, err = client.BulkRerunFailed(bg, BulkFilter{Types: []ResourceType{FEATURE_VARIANT}, NamePrefix: "feature2"}, false, "test")
+	if err != nil {
+		t.Fatalf("Failed to rerun: %s", err)
+	}
+	if len(rerun) != 1 || rerun[0] != failed[1] {
+		t.Fatalf("Expected only %v to rerun, got %v", failed[1], rerun)
+	}
+	feature, err = client.GetFeatureVariant(bg, NameVariant{Name: "feature2", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	assertEqual(t, feature.Status(), PENDING)
+	rerun, err = client.BulkRerunFailed(bg, BulkFilter{}, false, "test")
+	if err != nil {
+		t.Fatalf("Failed to rerun: %s", err)
+	}
+	if len(rerun) != 2 {
+		t.Fatalf("Expected the remaining 2 failed resources to rerun, got %v", rerun)
+	}
+	if paused, err := client.BulkPauseSchedules(bg, "mockOffline", "maintenance", true, "test"); err != nil {
+		t.Fatalf("Failed to dry run pause: %s", err)
+	} else if len(paused) != 0 {
+		t.Fatalf("Paused unscheduled resources: %v", paused)
+	}
+	if _, err := client.BulkPauseSchedules(bg, "missing", "maintenance", true, "test"); err == nil {
+		t.Fatalf("Succeeded in pausing a missing provider")
+	}
+}
+
+// scheduleRecordingLookup records the pauses, locks and triggered runs that
+// are set through it.
+type scheduleRecordingLookup struct {
+	ResourceLookup
+	paused    map[ResourceID]string
+	locked    []ResourceID
+	triggered []ResourceID
+}
+
+func (lookup *scheduleRecordingLookup) PauseSchedule(id ResourceID, schedule, reason, requester string) error {
+	lookup.paused[id] = reason
+	return nil
+}
+
+func (lookup *scheduleRecordingLookup) ResumeSchedule(id ResourceID, schedule string) error {
+	delete(lookup.paused, id)
+	return nil
+}
+
+func (lookup *scheduleRecordingLookup) LockResource(id ResourceID, reason string) error {
+	lookup.locked = append(lookup.locked, id)
+	return nil
+}
+
+func (lookup *scheduleRecordingLookup) TriggerRun(id ResourceID, schedule string) error {
+	lookup.triggered = append(lookup.triggered, id)
+	return nil
+}
+
+func TestBulkPauseSchedules(t *testing.T) {
+	ctx := testContext{Defs: filledResourceDefs()}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	if err := client.RequestScheduleChange(bg, id, "0 * * * *"); err != nil {
+		t.Fatalf("Failed to set schedule: %s", err)
+	}
+	if err := client.SetStatus(bg, id, READY, ""); err != nil {
+		t.Fatalf("Failed to set status: %s", err)
+	}
+	lookup := &scheduleRecordingLookup{ResourceLookup: ctx.serv.lookup, paused: make(map[ResourceID]string)}
+	serv := &MetadataServer{Logger: ctx.serv.Logger, lookup: lookup}
+	result, err := serv.BulkPauseSchedules(bg, &pb.BulkPauseSchedulesRequest{Provider: "mockOnline", Reason: "maintenance", Requester: "test"})
+	if err != nil {
+		t.Fatalf("Failed to pause schedules: %s", err)
+	}
+	if paused := parseBulkResult(result); !reflect.DeepEqual(paused, []ResourceID{id}) {
+		t.Fatalf("Expected only %v to be paused, got %v", id, paused)
+	}
+	if lookup.paused[id] != "maintenance" || len(lookup.locked) != 0 {
+		t.Fatalf("Expected %v's schedule to be paused without a lock, got pauses %v and locks %v", id, lookup.paused, lookup.locked)
+	}
+	// Pausing a schedule leaves triggered runs to run.
+	trigger := &pb.TriggerRunRequest{Resource: &pb.ResourceID{Resource: &pb.NameVariant{Name: id.Name, Variant: id.Variant}, ResourceType: id.Type.Serialized()}, Requester: "test"}
+	if _, err := serv.TriggerRun(bg, trigger); err != nil {
+		t.Fatalf("Failed to trigger a run while paused: %s", err)
+	}
+	if !reflect.DeepEqual(lookup.triggered, []ResourceID{id}) {
+		t.Fatalf("Expected a run of %v to be triggered, got %v", id, lookup.triggered)
+	}
+	if resumed, err := serv.BulkResumeSchedules(bg, &pb.BulkResumeSchedulesRequest{Provider: "mockOnline", DryRun: true, Requester: "test"}); err != nil {
+		t.Fatalf("Failed to dry run resume: %s", err)
+	} else if len(resumed.Resources) != 1 || len(lookup.paused) != 1 {
+		t.Fatalf("Dry run resumed schedules: %v", resumed)
+	}
+	if _, err := serv.BulkResumeSchedules(bg, &pb.BulkResumeSchedulesRequest{Provider: "mockOnline", Requester: "test"}); err != nil {
+		t.Fatalf("Failed to resume schedules: %s", err)
+	}
+	if len(lookup.paused) != 0 {
+		t.Fatalf("Schedules still paused: %v", lookup.paused)
+	}
+	if _, err := client.BulkResumeSchedules(bg, "missing", true, "test"); err == nil {
+		t.Fatalf("Succeeded in resuming a missing provider")
+	}
+}
+
+func TestSetStats(t *testing.T) {
+	ctx := testContext{Defs: filledResourceDefs()}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	stats := TableStats{
+		NumRows:    10,
+		SizeBytes:  -1,
+		MinTS:      time.UnixMilli(0).UTC(),
+		MaxTS:      time.UnixMilli(1000).UTC(),
+		NullCounts: map[string]int64{"entity": 0, "value": 2},
+		Computed:   time.UnixMilli(2000).UTC(),
+	}
+	featureID := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	if err := client.SetStats(context.Background(), featureID, stats); err != nil {
+		t.Fatalf("Failed to set feature stats: %s", err)
+	}
+	feature, err := client.GetFeatureVariant(context.Background(), NameVariant{Name: "feature", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if got := feature.Stats(); got == nil || !reflect.DeepEqual(*got, stats) {
+		t.Fatalf("Feature stats %+v, expected %+v", got, stats)
+	}
+	source, err := client.GetSourceVariant(context.Background(), NameVariant{Name: "mockSource", Variant: "var"})
+	if err != nil {
+		t.Fatalf("Failed to get source: %s", err)
+	}
+	if source.Stats() != nil {
+		t.Fatalf("Source has stats before any were set: %+v", source.Stats())
+	}
+	sourceID := ResourceID{Name: "mockSource", Variant: "var", Type: SOURCE_VARIANT}
+	if err := client.SetStats(context.Background(), sourceID, stats); err != nil {
+		t.Fatalf("Failed to set source stats: %s", err)
+	}
+	labelID := ResourceID{Name: "label", Variant: "variant", Type: LABEL_VARIANT}
+	if err := client.SetStats(context.Background(), labelID, stats); err == nil {
+		t.Fatalf("Succeeded in setting stats on a label")
+	}
+}
+
+func TestSetProgress(t *testing.T) {
+	ctx := testContext{Defs: filledResourceDefs()}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	featureID := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	progress := JobProgress{RowsCopied: 512, TotalRows: 2048, ChunksDone: 1, TotalChunks: 4, ETA: 90 * time.Second}
+	if err := client.SetProgress(context.Background(), featureID, progress); err != nil {
+		t.Fatalf("Failed to set feature progress: %s", err)
+	}
+	feature, err := client.GetFeatureVariant(context.Background(), NameVariant{Name: "feature", Variant: "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if got := feature.Progress(); got == nil || *got != progress {
+		t.Fatalf("Feature progress %+v, expected %+v", got, progress)
+	}
+	if feature.Status() != PENDING {
+		t.Fatalf("Expected a feature with progress to be pending, got %v", feature.Status())
+	}
+	if err := client.SetStatus(context.Background(), featureID, READY, ""); err != nil {
+		t.Fatalf("Failed to set feature status: %s", err)
+	}
+	if feature, err = client.GetFeatureVariant(context.Background(), NameVariant{Name: "feature", Variant: "variant"}); err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if got := feature.Progress(); got != nil {
+		t.Fatalf("Expected setting the status to clear progress, got %+v", got)
+	}
+}
+
+func TestEncryptedResourceLookup(t *testing.T) {
+	wrapper, err := NewLocalKeyWrapper([]byte("0123456789abcdef0123456789abcdef"))
+	if err != nil {
+		t.Fatalf("Failed to create key wrapper: %s", err)
+	}
+	local := make(localResourceLookup)
+	lookup := encryptedResourceLookup{wrapper, local}
+	id := ResourceID{Name: "mockOnline", Type: PROVIDER}
+	config := []byte(`{"Password": "hunter2"}`)
+	res := &providerResource{&pb.Provider{Name: "mockOnline", SerializedConfig: config}}
+	if err := lookup.Set(id, res); err != nil {
+		t.Fatalf("Failed to set provider: %s", err)
+	}
+	assertEqual(t, string(res.serialized.SerializedConfig), string(config))
+	stored := local[id].(*providerResource).serialized.SerializedConfig
+	if strings.Contains(string(stored), "hunter2") {
+		t.Fatalf("Provider config stored in plaintext: %s", stored)
+	}
+	found, err := lookup.Lookup(id)
+	if err != nil {
+		t.Fatalf("Failed to lookup provider: %s", err)
+	}
+	assertEqual(t, string(found.(*providerResource).serialized.SerializedConfig), string(config))
+	providers, err := lookup.ListForType(PROVIDER)
+	if err != nil || len(providers) != 1 {
+		t.Fatalf("Failed to list providers: %v %s", providers, err)
+	}
+	assertEqual(t, string(providers[0].(*providerResource).serialized.SerializedConfig), string(config))
+	// Configs written before encryption was enabled are read as is.
+	plainID := ResourceID{Name: "plain", Type: PROVIDER}
+	local[plainID] = &providerResource{&pb.Provider{Name: "plain", SerializedConfig: config}}
+	plain, err := lookup.Lookup(plainID)
+	if err != nil {
+		t.Fatalf("Failed to lookup plaintext provider: %s", err)
+	}
+	assertEqual(t, string(plain.(*providerResource).serialized.SerializedConfig), string(config))
+	otherWrapper, err := NewLocalKeyWrapper([]byte("fedcba9876543210fedcba9876543210"))
+	if err != nil {
+		t.Fatalf("Failed to create key wrapper: %s", err)
+	}
+	if _, err := (encryptedResourceLookup{otherWrapper, local}).Lookup(id); err == nil {
+		t.Fatalf("Succeeded in decrypting with the wrong key")
+	}
+}
+
+func TestTrainingSetPins(t *testing.T) {
+	ctx := testContext{Defs: filledResourceDefs()}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	def := TrainingSetDef{
+		Name:            "training-set",
+		Variant:         "pinned",
+		Provider:        "mockOffline",
+		Label:           NameVariant{"label", "variant"},
+		Features:        NameVariants{{"feature", "variant"}},
+		Owner:           "Other",
+		PinDependencies: true,
+	}
+	if err := client.CreateTrainingSetVariant(bg, def); err != nil {
+		t.Fatalf("Failed to create pinned training set: %s", err)
+	}
+	ts, err := client.GetTrainingSetVariant(bg, NameVariant{"training-set", "pinned"})
+	if err != nil {
+		t.Fatalf("Failed to get training set: %s", err)
+	}
+	if !ts.PinsDependencies() {
+		t.Fatalf("Training set isn't pinned")
+	}
+	pins := ts.Pins()
+	if len(pins) != 2 {
+		t.Fatalf("Expected a pin for the label and feature, got %v", pins)
+	}
+	label, err := client.GetLabelVariant(bg, NameVariant{"label", "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get label: %s", err)
+	}
+	feature, err := client.GetFeatureVariant(bg, NameVariant{"feature", "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	assertEqual(t, pins[0], VariantPin{Resource: ResourceID{"label", "variant", LABEL_VARIANT}, Created: label.Created()})
+	assertEqual(t, pins[1], VariantPin{Resource: ResourceID{"feature", "variant", FEATURE_VARIANT}, Created: feature.Created()})
+	unpinned, err := client.GetTrainingSetVariant(bg, NameVariant{"training-set", "variant"})
+	if err != nil {
+		t.Fatalf("Failed to get training set: %s", err)
+	}
+	if unpinned.PinsDependencies() || len(unpinned.Pins()) != 0 {
+		t.Fatalf("Training set was pinned without asking")
+	}
+}
+
+func TestCancelJob(t *testing.T) {
+	ctx := testContext{Defs: filledResourceDefs()}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	if err := client.CancelJob(bg, ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}, "test"); err != nil {
+		t.Fatalf("Failed to cancel job: %s", err)
+	}
+	if err := client.CancelJob(bg, ResourceID{Name: "missing", Variant: "variant", Type: FEATURE_VARIANT}, "test"); err == nil {
+		t.Fatalf("Succeeded in cancelling the job of a missing resource")
+	}
+	assertEqual(t, CANCELLED.String(), "CANCELLED")
+}
+
+func TestPauseSchedule(t *testing.T) {
+	ctx := testContext{Defs: filledResourceDefs()}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	id := ResourceID{Name: "feature", Variant: "variant", Type: FEATURE_VARIANT}
+	if err := client.PauseSchedule(bg, id, "maintenance", "test"); err == nil {
+		t.Fatalf("Succeeded in pausing a resource with no schedule")
+	}
+	if err := client.RequestScheduleChange(bg, id, "0 * * * *"); err != nil {
+		t.Fatalf("Failed to set schedule: %s", err)
+	}
+	if err := client.PauseSchedule(bg, id, "maintenance", "test"); err != nil {
+		t.Fatalf("Failed to pause schedule: %s", err)
+	}
+	if err := client.ResumeSchedule(bg, id, "test"); err != nil {
+		t.Fatalf("Failed to resume schedule: %s", err)
+	}
+	missing := ResourceID{Name: "missing", Variant: "variant", Type: FEATURE_VARIANT}
+	if err := client.PauseSchedule(bg, missing, "maintenance", "test"); err == nil {
+		t.Fatalf("Succeeded in pausing a missing resource")
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	valid := map[string]string{
+		"":              "",
+		"0 * * * *":     "0 * * * *",
+		"@daily":        "0 0 * * *",
+		"@every 1m":     "* * * * *",
+		"@every 15m":    "*/15 * * * *",
+		"@every 1h":     "0 * * * *",
+		"@every 6h":     "0 */6 * * *",
+		"@every 24h":    "0 0 * * *",
+		" */5 * * * * ": "*/5 * * * *",
+	}
+	for spec, cron := range valid {
+		schedule, err := ParseSchedule(spec)
+		if err != nil {
+			t.Fatalf("Failed to parse %q: %s", spec, err)
+		}
+		if schedule.Cron() != cron {
+			t.Fatalf("Wrong cron for %q: %q != %q", spec, schedule.Cron(), cron)
+		}
+	}
+	invalid := []string{
+		"* * * *",
+		"0 0 * * * *",
+		"61 * * * *",
+		"@sometimes",
+		"@every 30s",
+		"@every 7m",
+		"@every 5h",
+		"@every soon",
+	}
+	for _, spec := range invalid {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Fatalf("Succeeded in parsing invalid schedule %q", spec)
+		}
+	}
+	schedule, _ := ParseSchedule("@every 15m")
+	now := time.Date(2022, 6, 1, 10, 7, 0, 0, time.UTC)
+	if next := schedule.Next(now); !next.Equal(time.Date(2022, 6, 1, 10, 15, 0, 0, time.UTC)) {
+		t.Fatalf("Wrong next run: %s", next)
+	}
+}
+
+func TestScheduleNextRun(t *testing.T) {
+	ctx := testContext{Defs: filledResourceDefs()}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	nv := NameVariant{Name: "feature", Variant: "variant"}
+	id := ResourceID{Name: nv.Name, Variant: nv.Variant, Type: FEATURE_VARIANT}
+	if err := client.RequestScheduleChange(bg, id, "* * *"); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected invalid argument for a bad schedule, got: %v", err)
+	}
+	before := time.Now()
+	if err := client.RequestScheduleChange(bg, id, "@every 15m"); err != nil {
+		t.Fatalf("Failed to set schedule: %s", err)
+	}
+	feature, err := client.GetFeatureVariant(bg, nv)
+	if err != nil {
+		t.Fatalf("Failed to get feature: %s", err)
+	}
+	if next := feature.NextRun(); next.Before(before) || next.After(before.Add(15*time.Minute)) {
+		t.Fatalf("Wrong next run: %s", next)
+	}
+	defs := []ResourceDef{
+		FeatureDef{
+			Name:     "scheduled",
+			Variant:  "variant",
+			Schedule: "@every 7m",
+		},
+	}
+	if err := client.CreateAll(bg, defs); err == nil {
+		t.Fatalf("Succeeded in creating a feature with an invalid schedule")
+	}
+}
+
+type recordingPublisher struct {
+	keys []string
+	err  error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, key string) error {
+	p.keys = append(p.keys, key)
+	return p.err
+}
+
+func TestPublishingResourceLookup(t *testing.T) {
+	publisher := &recordingPublisher{}
+	lookup := publishingResourceLookup{publisher, make(localResourceLookup)}
+	id := ResourceID{Name: "avg", Variant: "v1", Type: FEATURE_VARIANT}
+	if err := lookup.SetJob(id, ""); err != nil {
+		t.Fatalf("Failed to set job: %s", err)
+	}
+	if err := lookup.ResetJob(id, ""); err != nil {
+		t.Fatalf("Failed to reset job: %s", err)
+	}
+	if len(publisher.keys) != 2 || publisher.keys[0] != GetJobKey(id) || publisher.keys[1] != GetJobKey(id) {
+		t.Fatalf("Jobs not published: %v", publisher.keys)
+	}
+	if err := lookup.TriggerRun(id, ""); err != nil {
+		t.Fatalf("Failed to trigger run: %s", err)
+	}
+	if len(publisher.keys) != 3 || publisher.keys[2] != GetManualRunKey(id) {
+		t.Fatalf("Manual run not published: %v", publisher.keys)
+	}
+	publisher.err = fmt.Errorf("queue unavailable")
+	if err := lookup.ResetJob(id, ""); err == nil {
+		t.Fatalf("Succeeded in resetting job that couldn't be published")
+	}
+}
+
+// heldJobsLookup is a lookup whose resources have jobs held back by a
+// maintenance lock.
+type heldJobsLookup struct {
+	localResourceLookup
+	held []string
+}
+
+func (lookup heldJobsLookup) UnlockResource(id ResourceID) ([]string, error) {
+	return lookup.held, nil
+}
+
+func TestPublishingResourceLookupUnlock(t *testing.T) {
+	publisher := &recordingPublisher{}
+	id := ResourceID{Name: "avg", Variant: "v1", Type: FEATURE_VARIANT}
+	held := []string{GetJobKey(id), GetManualRunKey(id)}
+	lookup := publishingResourceLookup{publisher, heldJobsLookup{make(localResourceLookup), held}}
+	keys, err := lookup.UnlockResource(id)
+	if err != nil {
+		t.Fatalf("Failed to unlock resource: %s", err)
+	}
+	if !reflect.DeepEqual(keys, held) || !reflect.DeepEqual(publisher.keys, held) {
+		t.Fatalf("Held jobs not published: %v, %v", keys, publisher.keys)
+	}
+}
+
+func TestCanTriggerRun(t *testing.T) {
+	sql := &pb.SourceVariant{Definition: &pb.SourceVariant_Transformation{Transformation: &pb.Transformation{
+		Type: &pb.Transformation_SQLTransformation{SQLTransformation: &pb.SQLTransformation{Query: "SELECT 1"}},
+	}}}
+	primary := &pb.SourceVariant{Definition: &pb.SourceVariant_PrimaryData{PrimaryData: &pb.PrimaryData{}}}
+	cases := []struct {
+		res      Resource
+		runnable bool
+	}{
+		{&featureVariantResource{&pb.FeatureVariant{}}, true},
+		{&trainingSetVariantResource{&pb.TrainingSetVariant{}}, true},
+		{&sourceVariantResource{sql}, true},
+		{&sourceVariantResource{primary}, false},
+		{&labelVariantResource{&pb.LabelVariant{}}, false},
+	}
+	for _, c := range cases {
+		if canTriggerRun(c.res) != c.runnable {
+			t.Fatalf("Expected %s to be runnable: %v", c.res.ID().Type, c.runnable)
+		}
+	}
+}
+
+func TestProviderMaintenance(t *testing.T) {
+	maintenance := []MaintenanceTask{{Kind: "VACUUM_ANALYZE", Schedule: "@daily"}, {Kind: "OPTIMIZE"}}
+	ctx := testContext{Defs: []ResourceDef{ProviderDef{
+		Name:             "maintained",
+		Type:             "POSTGRES_OFFLINE",
+		SerializedConfig: []byte("{}"),
+		Maintenance:      maintenance,
+	}}}
+	client, err := ctx.Create(t)
+	if err != nil {
+		t.Fatalf("Failed to create resources: %s", err)
+	}
+	defer ctx.Destroy()
+	bg := context.Background()
+	provider, err := client.GetProvider(bg, "maintained")
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	if !reflect.DeepEqual(provider.Maintenance(), maintenance) {
+		t.Fatalf("Provider maintenance is %v, expected %v", provider.Maintenance(), maintenance)
+	}
+	invalid := map[string]MaintenanceTask{
+		"NoKind":      {Schedule: "@daily"},
+		"BadSchedule": {Kind: "VACUUM_ANALYZE", Schedule: "* * *"},
+	}
+	for name, task := range invalid {
+		def := ProviderDef{Name: "invalid" + name, Type: "POSTGRES_OFFLINE", SerializedConfig: []byte("{}"), Maintenance: []MaintenanceTask{task}}
+		if err := client.CreateProvider(bg, def); status.Code(err) != codes.InvalidArgument {
+			t.Fatalf("Expected invalid argument for %s maintenance, got: %v", name, err)
+		}
+	}
+}
+
+func TestErrorCodeOf(t *testing.T) {
+	cases := []struct {
+		err  error
+		code ErrorCode
+	}{
+		{nil, ""},
+		{fmt.Errorf("wrapped: %w", WithErrorCode(ErrorSourceNotReady, fmt.Errorf("not ready"))), ErrorSourceNotReady},
+		{&ResourceNotFound{ID: ResourceID{Name: "a", Type: FEATURE_VARIANT}}, ErrorResourceNotFound},
+		{status.Error(codes.NotFound, "missing"), ErrorResourceNotFound},
+		{status.Error(codes.AlreadyExists, "exists"), ErrorAlreadyExists},
+		{fmt.Errorf(`pq: password authentication failed for user "ff"`), ErrorProviderAuthFailed},
+		{fmt.Errorf(`pq: syntax error at or near "SELEC"`), ErrorSQLSyntaxError},
+		{fmt.Errorf("dial tcp 10.0.0.1:5432: connect: connection refused"), ErrorProviderUnavailable},
+		{fmt.Errorf("something else"), ErrorUnknown},
+	}
+	for _, c := range cases {
+		if code := ErrorCodeOf(c.err); code != c.code {
+			t.Fatalf("Expected %v to have code %s, got %s", c.err, c.code, code)
+		}
+	}
+	if WithErrorCode(ErrorUnknown, nil) != nil {
+		t.Fatalf("Coding a nil error didn't return nil")
+	}
+}
@@ -0,0 +1,83 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+// MigrateOnlineJobKind is the kind of the coordinator jobs that
+// MigrateOnlineStore queues.
+const MigrateOnlineJobKind = "MIGRATE_ONLINE"
+
+// OnlineMigration is the config of a job that moves features to another
+// online provider.
+type OnlineMigration struct {
+	Features    []NameVariant
+	Destination string
+	Requester   string `json:",omitempty"`
+}
+
+func (m *OnlineMigration) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
+func (m *OnlineMigration) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, m)
+}
+
+// GetOnlineMigrationKey is where a migration to the destination provider is
+// queued. There's one at a time for each destination.
+func GetOnlineMigrationKey(destination string) string {
+	return fmt.Sprintf("JOB__%s__%s__%s__", MigrateOnlineJobKind, PROVIDER, destination)
+}
+
+// MigrateOnlineStore moves features to another online provider.
+func (client *Client) MigrateOnlineStore(ctx context.Context, features []NameVariant, destination, requester string) error {
+	req := pb.OnlineMigrationRequest{
+		Features:    make([]*pb.NameVariant, len(features)),
+		Destination: destination,
+		Requester:   requester,
+	}
+	for i, feature := range features {
+		req.Features[i] = feature.Serialize()
+	}
+	_, err := client.grpcConn.MigrateOnlineStore(ctx, &req)
+	return err
+}
+
+// MigrateOnlineStore queues a job that copies the online values of features
+// to the destination provider and then serves them from it. The features
+// are locked for maintenance while they're copied, so that their values
+// don't change until they've been switched over.
+func (serv *MetadataServer) MigrateOnlineStore(ctx context.Context, req *pb.OnlineMigrationRequest) (*pb.Empty, error) {
+	if len(req.GetFeatures()) == 0 {
+		return nil, fmt.Errorf("no features to migrate")
+	}
+	if _, err := serv.lookup.Lookup(ResourceID{Name: req.Destination, Type: PROVIDER}); err != nil {
+		return nil, err
+	}
+	migration := OnlineMigration{Destination: req.Destination, Requester: req.Requester}
+	for _, feature := range req.GetFeatures() {
+		id := ResourceID{Name: feature.GetName(), Variant: feature.GetVariant(), Type: FEATURE_VARIANT}
+		if _, err := serv.lookup.Lookup(id); err != nil {
+			return nil, err
+		}
+		migration.Features = append(migration.Features, NameVariant{Name: id.Name, Variant: id.Variant})
+	}
+	if err := serv.lookup.MigrateOnlineStore(migration); err != nil {
+		return nil, err
+	}
+	serv.audit("Queued online store migration", req.Requester, "destination", req.Destination, "features", len(migration.Features))
+	return &pb.Empty{}, nil
+}
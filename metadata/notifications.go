@@ -0,0 +1,213 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	pb "github.com/featureform/metadata/proto"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// NotificationPrefix is the etcd prefix that notifications are registered
+// under.
+const NotificationPrefix = "NOTIFICATION__"
+
+// NotificationKind is how a notification is delivered.
+type NotificationKind string
+
+const (
+	// WebhookNotification posts each event as JSON.
+	WebhookNotification NotificationKind = "webhook"
+	// SlackNotification posts a message to a Slack incoming webhook.
+	SlackNotification NotificationKind = "slack"
+	// PagerDutyNotification triggers an incident when a job fails and
+	// resolves it once the job succeeds.
+	PagerDutyNotification NotificationKind = "pagerduty"
+)
+
+// NotificationEvent is a job state change that the coordinator notifies of.
+type NotificationEvent string
+
+const (
+	NotifyJobSucceeded   NotificationEvent = "job_succeeded"
+	NotifyJobFailed      NotificationEvent = "job_failed"
+	NotifyScheduleMissed NotificationEvent = "schedule_missed"
+)
+
+// Notification is a registered destination for job events.
+type Notification struct {
+	Name       string
+	Kind       NotificationKind
+	URL        string `json:",omitempty"`
+	RoutingKey string `json:",omitempty"`
+	// Events are the events that are sent. All of them are if it's empty.
+	Events []NotificationEvent `json:",omitempty"`
+}
+
+func (n *Notification) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
+func (n *Notification) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, n)
+}
+
+// Wants returns whether event is sent to the notification.
+func (n Notification) Wants(event NotificationEvent) bool {
+	if len(n.Events) == 0 {
+		return true
+	}
+	for _, wanted := range n.Events {
+		if wanted == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (n Notification) validate() error {
+	if n.Name == "" {
+		return fmt.Errorf("notification needs a name")
+	}
+	switch n.Kind {
+	case WebhookNotification, SlackNotification:
+		if n.URL == "" {
+			return fmt.Errorf("%s notification %s needs a url", n.Kind, n.Name)
+		}
+	case PagerDutyNotification:
+		if n.RoutingKey == "" {
+			return fmt.Errorf("pagerduty notification %s needs a routing key", n.Name)
+		}
+	default:
+		return fmt.Errorf("unknown notification kind %q", n.Kind)
+	}
+	if n.URL != "" {
+		if u, err := url.Parse(n.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("notification %s has an invalid url", n.Name)
+		}
+	}
+	for _, event := range n.Events {
+		switch event {
+		case NotifyJobSucceeded, NotifyJobFailed, NotifyScheduleMissed:
+		default:
+			return fmt.Errorf("unknown notification event %q", event)
+		}
+	}
+	return nil
+}
+
+func GetNotificationKey(name string) string {
+	return NotificationPrefix + name
+}
+
+// ListNotifications returns the registered notifications, which the
+// coordinator reads from etcd directly.
+func ListNotifications(ctx context.Context, kv clientv3.KV) ([]Notification, error) {
+	resp, err := kv.Get(ctx, NotificationPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	notifications := make([]Notification, len(resp.Kvs))
+	for i, kv := range resp.Kvs {
+		if err := notifications[i].Deserialize(kv.Value); err != nil {
+			return nil, fmt.Errorf("could not deserialize notification %s: %w", kv.Key, err)
+		}
+	}
+	return notifications, nil
+}
+
+func (n Notification) proto() *pb.Notification {
+	events := make([]string, len(n.Events))
+	for i, event := range n.Events {
+		events[i] = string(event)
+	}
+	return &pb.Notification{Name: n.Name, Kind: string(n.Kind), Url: n.URL, RoutingKey: n.RoutingKey, Events: events}
+}
+
+func parseNotification(serialized *pb.Notification) Notification {
+	n := Notification{
+		Name:       serialized.GetName(),
+		Kind:       NotificationKind(serialized.GetKind()),
+		URL:        serialized.GetUrl(),
+		RoutingKey: serialized.GetRoutingKey(),
+	}
+	for _, event := range serialized.GetEvents() {
+		n.Events = append(n.Events, NotificationEvent(event))
+	}
+	return n
+}
+
+// RegisterNotification adds a notification, replacing any with its name.
+func (client *Client) RegisterNotification(ctx context.Context, n Notification, requester string) error {
+	req := pb.RegisterNotificationRequest{Notification: n.proto(), Requester: requester}
+	_, err := client.grpcConn.RegisterNotification(ctx, &req)
+	return err
+}
+
+func (client *Client) DeleteNotification(ctx context.Context, name, requester string) error {
+	req := pb.DeleteNotificationRequest{Name: name, Requester: requester}
+	_, err := client.grpcConn.DeleteNotification(ctx, &req)
+	return err
+}
+
+func (client *Client) ListNotifications(ctx context.Context) ([]Notification, error) {
+	list, err := client.grpcConn.ListNotifications(ctx, &pb.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	notifications := make([]Notification, len(list.GetNotifications()))
+	for i, n := range list.GetNotifications() {
+		notifications[i] = parseNotification(n)
+	}
+	return notifications, nil
+}
+
+func (serv *MetadataServer) RegisterNotification(ctx context.Context, req *pb.RegisterNotificationRequest) (*pb.Empty, error) {
+	n := parseNotification(req.GetNotification())
+	if err := n.validate(); err != nil {
+		return nil, err
+	}
+	if err := serv.lookup.SetNotification(n); err != nil {
+		return nil, err
+	}
+	// The url and routing key are left out, since they're credentials.
+	serv.audit("Registered notification", req.Requester, "name", n.Name, "kind", n.Kind)
+	return &pb.Empty{}, nil
+}
+
+func (serv *MetadataServer) DeleteNotification(ctx context.Context, req *pb.DeleteNotificationRequest) (*pb.Empty, error) {
+	if err := serv.lookup.DeleteNotification(req.Name); err != nil {
+		return nil, err
+	}
+	serv.audit("Deleted notification", req.Requester, "name", req.Name)
+	return &pb.Empty{}, nil
+}
+
+// ListNotifications returns the registered notifications without their
+// credentials: the routing key, and the path of the url, which is the secret
+// part of a Slack webhook.
+func (serv *MetadataServer) ListNotifications(ctx context.Context, _ *pb.Empty) (*pb.NotificationList, error) {
+	notifications, err := serv.lookup.ListNotifications()
+	if err != nil {
+		return nil, err
+	}
+	list := &pb.NotificationList{Notifications: make([]*pb.Notification, len(notifications))}
+	for i, n := range notifications {
+		n.RoutingKey = ""
+		if u, err := url.Parse(n.URL); err == nil && n.URL != "" {
+			n.URL = fmt.Sprintf("%s://%s", u.Scheme, u.Host)
+		}
+		list.Notifications[i] = n.proto()
+	}
+	return list, nil
+}
@@ -0,0 +1,98 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	pb "github.com/featureform/metadata/proto"
+)
+
+// DeleteOrphansJobKind is the kind of the coordinator jobs that
+// DeleteOrphanedTables queues.
+const DeleteOrphansJobKind = "DELETE_ORPHANS"
+
+// OrphanedTable is a table in a provider that's named after a resource
+// variant that isn't registered.
+type OrphanedTable struct {
+	Online  bool `json:",omitempty"`
+	Name    string
+	Variant string
+	// TableType is the provider's type of the table, like a feature or a
+	// primary table.
+	TableType int
+}
+
+// OrphanDeletion is the config of a job that drops reviewed orphaned tables
+// from a provider.
+type OrphanDeletion struct {
+	Provider  string
+	Tables    []OrphanedTable
+	Requester string `json:",omitempty"`
+}
+
+func (d *OrphanDeletion) Serialize() ([]byte, error) {
+	serialized, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	return serialized, nil
+}
+
+func (d *OrphanDeletion) Deserialize(serialized []byte) error {
+	return json.Unmarshal(serialized, d)
+}
+
+// GetOrphanDeletionKey is where a deletion of a provider's orphaned tables
+// is queued. There's one at a time for each provider.
+func GetOrphanDeletionKey(provider string) string {
+	return fmt.Sprintf("JOB__%s__%s__%s__", DeleteOrphansJobKind, PROVIDER, provider)
+}
+
+// DeleteOrphanedTables drops orphaned tables from a provider. The tables are
+// meant to come from a reviewed orphan audit.
+func (client *Client) DeleteOrphanedTables(ctx context.Context, provider string, tables []OrphanedTable, requester string) error {
+	req := pb.OrphanDeletionRequest{
+		Provider:  provider,
+		Tables:    make([]*pb.OrphanedTable, len(tables)),
+		Requester: requester,
+	}
+	for i, table := range tables {
+		req.Tables[i] = &pb.OrphanedTable{Online: table.Online, Name: table.Name, Variant: table.Variant, TableType: int32(table.TableType)}
+	}
+	_, err := client.grpcConn.DeleteOrphanedTables(ctx, &req)
+	return err
+}
+
+// DeleteOrphanedTables queues a job that drops the given orphaned tables
+// from a provider. Only the tables in the request are dropped, and the job
+// skips any whose resource has been registered since they were reported.
+func (serv *MetadataServer) DeleteOrphanedTables(ctx context.Context, req *pb.OrphanDeletionRequest) (*pb.Empty, error) {
+	if len(req.GetTables()) == 0 {
+		return nil, fmt.Errorf("no tables to delete")
+	}
+	if _, err := serv.lookup.Lookup(ResourceID{Name: req.Provider, Type: PROVIDER}); err != nil {
+		return nil, err
+	}
+	deletion := OrphanDeletion{Provider: req.Provider, Requester: req.Requester}
+	for _, table := range req.GetTables() {
+		if table.GetName() == "" {
+			return nil, fmt.Errorf("orphaned table has no resource name")
+		}
+		deletion.Tables = append(deletion.Tables, OrphanedTable{
+			Online:    table.GetOnline(),
+			Name:      table.GetName(),
+			Variant:   table.GetVariant(),
+			TableType: int(table.GetTableType()),
+		})
+	}
+	if err := serv.lookup.DeleteOrphanedTables(deletion); err != nil {
+		return nil, err
+	}
+	serv.audit("Queued orphaned table deletion", req.Requester, "provider", req.Provider, "tables", len(deletion.Tables))
+	return &pb.Empty{}, nil
+}
@@ -0,0 +1,297 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// JobPlan is what the job that creates a resource would do, worked out
+// without running anything.
+type JobPlan struct {
+	Resource ResourceID
+	// Runner is the runner the job spawns. It's empty if the coordinator
+	// creates the resource itself, like a primary table.
+	Runner string
+	// Providers are the providers the job runs against.
+	Providers []string
+	// Dependencies are the resources the job waits on.
+	Dependencies []ResourceID
+	// Query is the SQL that a transformation runs, with its sources'
+	// tables filled in.
+	Query string
+	// Schedule is the cron expression the resource is updated on, if it is.
+	Schedule string
+	// Problems are why the job would fail, like a provider that can't be
+	// configured. The job is expected to succeed if there are none.
+	Problems []string
+}
+
+func (plan *JobPlan) AddProblem(format string, args ...interface{}) {
+	plan.Problems = append(plan.Problems, fmt.Sprintf(format, args...))
+}
+
+func (plan JobPlan) proto() *pb.JobPlan {
+	serialized := &pb.JobPlan{
+		Resource:  &pb.ResourceID{Resource: &pb.NameVariant{Name: plan.Resource.Name, Variant: plan.Resource.Variant}, ResourceType: plan.Resource.Type.Serialized()},
+		Runner:    plan.Runner,
+		Providers: plan.Providers,
+		Query:     plan.Query,
+		Schedule:  plan.Schedule,
+		Problems:  plan.Problems,
+	}
+	for _, dep := range plan.Dependencies {
+		serialized.Dependencies = append(serialized.Dependencies, &pb.ResourceID{Resource: &pb.NameVariant{Name: dep.Name, Variant: dep.Variant}, ResourceType: dep.Type.Serialized()})
+	}
+	return serialized
+}
+
+func parseJobPlan(serialized *pb.JobPlan) JobPlan {
+	res := serialized.GetResource()
+	plan := JobPlan{
+		Resource:  ResourceID{Name: res.GetResource().GetName(), Variant: res.GetResource().GetVariant(), Type: ResourceType(res.GetResourceType())},
+		Runner:    serialized.GetRunner(),
+		Providers: serialized.GetProviders(),
+		Query:     serialized.GetQuery(),
+		Schedule:  serialized.GetSchedule(),
+		Problems:  serialized.GetProblems(),
+	}
+	for _, dep := range serialized.GetDependencies() {
+		plan.Dependencies = append(plan.Dependencies, ResourceID{Name: dep.GetResource().GetName(), Variant: dep.GetResource().GetVariant(), Type: ResourceType(dep.GetResourceType())})
+	}
+	return plan
+}
+
+// ResourceReader is what jobs read resources through. It's implemented by
+// *Client.
+type ResourceReader interface {
+	GetProvider(ctx context.Context, name string) (*Provider, error)
+	GetSourceVariant(ctx context.Context, id NameVariant) (*SourceVariant, error)
+	GetSourceVariants(ctx context.Context, ids []NameVariant) ([]*SourceVariant, error)
+	GetFeatureVariant(ctx context.Context, id NameVariant) (*FeatureVariant, error)
+	GetFeatureVariants(ctx context.Context, ids []NameVariant) ([]*FeatureVariant, error)
+	GetLabelVariant(ctx context.Context, id NameVariant) (*LabelVariant, error)
+	GetLabelVariants(ctx context.Context, ids []NameVariant) ([]*LabelVariant, error)
+	GetTrainingSetVariant(ctx context.Context, id NameVariant) (*TrainingSetVariant, error)
+}
+
+// JobPlanner plans the jobs of resources the way the coordinator would run
+// them, reading the resources and what they depend on from resources.
+type JobPlanner func(ctx context.Context, resources ResourceReader, ids []ResourceID) ([]JobPlan, error)
+
+// PlanJobs plans the jobs that creating defs would run, without creating
+// anything, so that they can be previewed before they're applied. Resources
+// that don't have jobs, like users and entities, aren't planned.
+func (client *Client) PlanJobs(ctx context.Context, defs []ResourceDef) ([]JobPlan, error) {
+	req := &pb.PlanRequest{}
+	for _, def := range defs {
+		switch casted := def.(type) {
+		case ProviderDef:
+			req.Providers = append(req.Providers, casted.serialize())
+		case SourceDef:
+			serialized, err := casted.serialize()
+			if err != nil {
+				return nil, err
+			}
+			req.Sources = append(req.Sources, serialized)
+		case FeatureDef:
+			serialized, err := casted.serialize()
+			if err != nil {
+				return nil, err
+			}
+			req.Features = append(req.Features, serialized)
+		case LabelDef:
+			serialized, err := casted.serialize()
+			if err != nil {
+				return nil, err
+			}
+			req.Labels = append(req.Labels, serialized)
+		case TrainingSetDef:
+			req.TrainingSets = append(req.TrainingSets, casted.serialize())
+		case UserDef, EntityDef, ModelDef:
+		default:
+			return nil, fmt.Errorf("%T not implemented in PlanJobs", casted)
+		}
+	}
+	list, err := client.grpcConn.PlanJobs(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	plans := make([]JobPlan, len(list.GetPlans()))
+	for i, plan := range list.GetPlans() {
+		plans[i] = parseJobPlan(plan)
+	}
+	return plans, nil
+}
+
+// PlanJobs plans the jobs of resource definitions that haven't been
+// registered, as they'd run if the definitions were created. Nothing is
+// registered or run.
+func (serv *MetadataServer) PlanJobs(ctx context.Context, req *pb.PlanRequest) (*pb.JobPlanList, error) {
+	if serv.planJobs == nil {
+		return nil, status.Error(codes.Unimplemented, "metadata server has no job planner")
+	}
+	resources := newPlanResources(serv.lookup)
+	for _, p := range req.GetProviders() {
+		resources.providers[p.Name] = p
+	}
+	ids := make([]ResourceID, 0)
+	for _, source := range req.GetSources() {
+		nv := NameVariant{Name: source.Name, Variant: source.Variant}
+		resources.sources[nv] = source
+		ids = append(ids, ResourceID{Name: nv.Name, Variant: nv.Variant, Type: SOURCE_VARIANT})
+	}
+	for _, feature := range req.GetFeatures() {
+		nv := NameVariant{Name: feature.Name, Variant: feature.Variant}
+		resources.features[nv] = feature
+		ids = append(ids, ResourceID{Name: nv.Name, Variant: nv.Variant, Type: FEATURE_VARIANT})
+	}
+	for _, label := range req.GetLabels() {
+		nv := NameVariant{Name: label.Name, Variant: label.Variant}
+		resources.labels[nv] = label
+		ids = append(ids, ResourceID{Name: nv.Name, Variant: nv.Variant, Type: LABEL_VARIANT})
+	}
+	for _, ts := range req.GetTrainingSets() {
+		nv := NameVariant{Name: ts.Name, Variant: ts.Variant}
+		resources.trainingSets[nv] = ts
+		ids = append(ids, ResourceID{Name: nv.Name, Variant: nv.Variant, Type: TRAINING_SET_VARIANT})
+	}
+	plans, err := serv.planJobs(ctx, resources, ids)
+	if err != nil {
+		return nil, err
+	}
+	list := &pb.JobPlanList{Plans: make([]*pb.JobPlan, len(plans))}
+	for i, plan := range plans {
+		list.Plans[i] = plan.proto()
+	}
+	return list, nil
+}
+
+// planResources reads the definitions being planned as if they were
+// registered, and everything else from the server's lookup.
+type planResources struct {
+	lookup       ResourceLookup
+	providers    map[string]*pb.Provider
+	sources      map[NameVariant]*pb.SourceVariant
+	features     map[NameVariant]*pb.FeatureVariant
+	labels       map[NameVariant]*pb.LabelVariant
+	trainingSets map[NameVariant]*pb.TrainingSetVariant
+}
+
+func newPlanResources(lookup ResourceLookup) *planResources {
+	return &planResources{
+		lookup:       lookup,
+		providers:    make(map[string]*pb.Provider),
+		sources:      make(map[NameVariant]*pb.SourceVariant),
+		features:     make(map[NameVariant]*pb.FeatureVariant),
+		labels:       make(map[NameVariant]*pb.LabelVariant),
+		trainingSets: make(map[NameVariant]*pb.TrainingSetVariant),
+	}
+}
+
+// registered returns the proto of a registered resource.
+func (resources *planResources) registered(id ResourceID) (interface{}, error) {
+	res, err := resources.lookup.Lookup(id)
+	if err != nil {
+		return nil, err
+	}
+	return res.Proto(), nil
+}
+
+func (resources *planResources) GetProvider(ctx context.Context, name string) (*Provider, error) {
+	if p, has := resources.providers[name]; has {
+		return WrapProvider(p), nil
+	}
+	p, err := resources.registered(ResourceID{Name: name, Type: PROVIDER})
+	if err != nil {
+		return nil, err
+	}
+	return WrapProvider(p.(*pb.Provider)), nil
+}
+
+func (resources *planResources) GetSourceVariant(ctx context.Context, id NameVariant) (*SourceVariant, error) {
+	if v, has := resources.sources[id]; has {
+		return WrapSourceVariant(v), nil
+	}
+	v, err := resources.registered(ResourceID{Name: id.Name, Variant: id.Variant, Type: SOURCE_VARIANT})
+	if err != nil {
+		return nil, err
+	}
+	return WrapSourceVariant(v.(*pb.SourceVariant)), nil
+}
+
+func (resources *planResources) GetSourceVariants(ctx context.Context, ids []NameVariant) ([]*SourceVariant, error) {
+	variants := make([]*SourceVariant, len(ids))
+	for i, id := range ids {
+		v, err := resources.GetSourceVariant(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		variants[i] = v
+	}
+	return variants, nil
+}
+
+func (resources *planResources) GetFeatureVariant(ctx context.Context, id NameVariant) (*FeatureVariant, error) {
+	if v, has := resources.features[id]; has {
+		return WrapFeatureVariant(v), nil
+	}
+	v, err := resources.registered(ResourceID{Name: id.Name, Variant: id.Variant, Type: FEATURE_VARIANT})
+	if err != nil {
+		return nil, err
+	}
+	return WrapFeatureVariant(v.(*pb.FeatureVariant)), nil
+}
+
+func (resources *planResources) GetFeatureVariants(ctx context.Context, ids []NameVariant) ([]*FeatureVariant, error) {
+	variants := make([]*FeatureVariant, len(ids))
+	for i, id := range ids {
+		v, err := resources.GetFeatureVariant(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		variants[i] = v
+	}
+	return variants, nil
+}
+
+func (resources *planResources) GetLabelVariant(ctx context.Context, id NameVariant) (*LabelVariant, error) {
+	if v, has := resources.labels[id]; has {
+		return WrapLabelVariant(v), nil
+	}
+	v, err := resources.registered(ResourceID{Name: id.Name, Variant: id.Variant, Type: LABEL_VARIANT})
+	if err != nil {
+		return nil, err
+	}
+	return WrapLabelVariant(v.(*pb.LabelVariant)), nil
+}
+
+func (resources *planResources) GetLabelVariants(ctx context.Context, ids []NameVariant) ([]*LabelVariant, error) {
+	variants := make([]*LabelVariant, len(ids))
+	for i, id := range ids {
+		v, err := resources.GetLabelVariant(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		variants[i] = v
+	}
+	return variants, nil
+}
+
+func (resources *planResources) GetTrainingSetVariant(ctx context.Context, id NameVariant) (*TrainingSetVariant, error) {
+	if v, has := resources.trainingSets[id]; has {
+		return WrapTrainingSetVariant(v), nil
+	}
+	v, err := resources.registered(ResourceID{Name: id.Name, Variant: id.Variant, Type: TRAINING_SET_VARIANT})
+	if err != nil {
+		return nil, err
+	}
+	return WrapTrainingSetVariant(v.(*pb.TrainingSetVariant)), nil
+}
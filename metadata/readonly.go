@@ -0,0 +1,201 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	pb "github.com/featureform/metadata/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultMirrorCacheTTL is how long a read-only mirror caches lookups when
+// Config.CacheTTL is not set.
+const DefaultMirrorCacheTTL = time.Minute
+
+type ReadOnlyError struct {
+	Operation string
+}
+
+func (err *ReadOnlyError) Error() string {
+	return fmt.Sprintf("%s not allowed: metadata server is a read-only mirror", err.Operation)
+}
+
+func (err *ReadOnlyError) GRPCStatus() *status.Status {
+	return status.New(codes.FailedPrecondition, err.Error())
+}
+
+type cachedValue struct {
+	value   interface{}
+	expires time.Time
+}
+
+// readOnlyResourceLookup rejects all writes and caches reads from the wrapped
+// lookup for ttl. It lets serving clusters run a local metadata mirror that
+// keeps answering from cache while the primary store is unavailable.
+type readOnlyResourceLookup struct {
+	ResourceLookup
+	ttl   time.Duration
+	mtx   *sync.RWMutex
+	cache map[string]cachedValue
+}
+
+func newReadOnlyResourceLookup(lookup ResourceLookup, ttl time.Duration) *readOnlyResourceLookup {
+	if ttl <= 0 {
+		ttl = DefaultMirrorCacheTTL
+	}
+	return &readOnlyResourceLookup{
+		ResourceLookup: lookup,
+		ttl:            ttl,
+		mtx:            &sync.RWMutex{},
+		cache:          make(map[string]cachedValue),
+	}
+}
+
+// cached returns the cached value for key or calls fetch to fill it. If fetch
+// fails and a stale value exists, the stale value is served instead.
+func (lookup *readOnlyResourceLookup) cached(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	lookup.mtx.RLock()
+	entry, has := lookup.cache[key]
+	lookup.mtx.RUnlock()
+	if has && time.Now().Before(entry.expires) {
+		return entry.value, nil
+	}
+	value, err := fetch()
+	if err != nil {
+		if has {
+			return entry.value, nil
+		}
+		return nil, err
+	}
+	lookup.mtx.Lock()
+	lookup.cache[key] = cachedValue{value: value, expires: time.Now().Add(lookup.ttl)}
+	lookup.mtx.Unlock()
+	return value, nil
+}
+
+func (lookup *readOnlyResourceLookup) Lookup(id ResourceID) (Resource, error) {
+	key := fmt.Sprintf("lookup__%s__%s__%s", id.Type, id.Name, id.Variant)
+	res, err := lookup.cached(key, func() (interface{}, error) {
+		return lookup.ResourceLookup.Lookup(id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.(Resource), nil
+}
+
+func (lookup *readOnlyResourceLookup) Has(id ResourceID) (bool, error) {
+	key := fmt.Sprintf("has__%s__%s__%s", id.Type, id.Name, id.Variant)
+	has, err := lookup.cached(key, func() (interface{}, error) {
+		return lookup.ResourceLookup.Has(id)
+	})
+	if err != nil {
+		return false, err
+	}
+	return has.(bool), nil
+}
+
+func (lookup *readOnlyResourceLookup) ListForType(t ResourceType) ([]Resource, error) {
+	key := fmt.Sprintf("list__%s", t)
+	resources, err := lookup.cached(key, func() (interface{}, error) {
+		return lookup.ResourceLookup.ListForType(t)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resources.([]Resource), nil
+}
+
+func (lookup *readOnlyResourceLookup) List() ([]Resource, error) {
+	resources, err := lookup.cached("list", func() (interface{}, error) {
+		return lookup.ResourceLookup.List()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resources.([]Resource), nil
+}
+
+func (lookup *readOnlyResourceLookup) Set(ResourceID, Resource) error {
+	return &ReadOnlyError{"Set"}
+}
+
+func (lookup *readOnlyResourceLookup) SetJob(ResourceID, string) error {
+	return &ReadOnlyError{"SetJob"}
+}
+
+func (lookup *readOnlyResourceLookup) SetStatus(ResourceID, pb.ResourceStatus) error {
+	return &ReadOnlyError{"SetStatus"}
+}
+
+func (lookup *readOnlyResourceLookup) SetSchedule(ResourceID, string) error {
+	return &ReadOnlyError{"SetSchedule"}
+}
+
+func (lookup *readOnlyResourceLookup) ResetJob(ResourceID, string) error {
+	return &ReadOnlyError{"ResetJob"}
+}
+
+func (lookup *readOnlyResourceLookup) LockResource(ResourceID, string) error {
+	return &ReadOnlyError{"LockResource"}
+}
+
+func (lookup *readOnlyResourceLookup) UnlockResource(ResourceID) ([]string, error) {
+	return nil, &ReadOnlyError{"UnlockResource"}
+}
+
+func (lookup *readOnlyResourceLookup) CancelJob(ResourceID, string) error {
+	return &ReadOnlyError{"CancelJob"}
+}
+
+func (lookup *readOnlyResourceLookup) PauseSchedule(ResourceID, string, string, string) error {
+	return &ReadOnlyError{"PauseSchedule"}
+}
+
+func (lookup *readOnlyResourceLookup) ResumeSchedule(ResourceID, string) error {
+	return &ReadOnlyError{"ResumeSchedule"}
+}
+
+func (lookup *readOnlyResourceLookup) TriggerRun(ResourceID, string) error {
+	return &ReadOnlyError{"TriggerRun"}
+}
+
+func (lookup *readOnlyResourceLookup) RecordAudit(AuditEvent) error {
+	return &ReadOnlyError{"RecordAudit"}
+}
+
+func (lookup *readOnlyResourceLookup) SetNotification(Notification) error {
+	return &ReadOnlyError{"SetNotification"}
+}
+
+func (lookup *readOnlyResourceLookup) DeleteNotification(string) error {
+	return &ReadOnlyError{"DeleteNotification"}
+}
+
+func (lookup *readOnlyResourceLookup) ListDeadLetters() ([]DeadLetter, error) {
+	deadLetters, err := lookup.cached("deadletters", func() (interface{}, error) {
+		return lookup.ResourceLookup.ListDeadLetters()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deadLetters.([]DeadLetter), nil
+}
+
+func (lookup *readOnlyResourceLookup) ReplayDeadLetter(ResourceID) error {
+	return &ReadOnlyError{"ReplayDeadLetter"}
+}
+
+func (lookup *readOnlyResourceLookup) MigrateOnlineStore(OnlineMigration) error {
+	return &ReadOnlyError{"MigrateOnlineStore"}
+}
+
+func (lookup *readOnlyResourceLookup) DeleteOrphanedTables(OrphanDeletion) error {
+	return &ReadOnlyError{"DeleteOrphanedTables"}
+}
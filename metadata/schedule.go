@@ -0,0 +1,160 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gorhill/cronexpr"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const everyPrefix = "@every "
+
+var scheduleDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a validated resource schedule. It's written either as a five
+// field cron expression, one of the @hourly style descriptors, or as
+// "@every <duration>", such as "@every 5m". Every schedule runs on a cron
+// expression, so that scheduled runs line up with the clock wherever they
+// run.
+type Schedule struct {
+	spec string
+	cron string
+	expr *cronexpr.Expression
+}
+
+// ParseSchedule validates a schedule. An empty spec is the zero Schedule,
+// for resources that aren't scheduled.
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return Schedule{}, nil
+	}
+	cron, err := scheduleCron(spec)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	expr, err := cronexpr.Parse(cron)
+	if err != nil {
+		return Schedule{}, fmt.Errorf("invalid schedule %q: %w", spec, err)
+	}
+	return Schedule{spec: spec, cron: cron, expr: expr}, nil
+}
+
+func scheduleCron(spec string) (string, error) {
+	if strings.HasPrefix(spec, everyPrefix) {
+		return everyCron(strings.TrimSpace(strings.TrimPrefix(spec, everyPrefix)))
+	}
+	if strings.HasPrefix(spec, "@") {
+		cron, has := scheduleDescriptors[spec]
+		if !has {
+			return "", fmt.Errorf("unknown descriptor, expected one of @yearly, @monthly, @weekly, @daily, @hourly or @every <duration>")
+		}
+		return cron, nil
+	}
+	if fields := len(strings.Fields(spec)); fields != 5 {
+		return "", fmt.Errorf("expected 5 fields (minute hour day-of-month month day-of-week), got %d", fields)
+	}
+	return spec, nil
+}
+
+// everyCron returns the cron expression for an interval. Intervals have to
+// evenly divide an hour or a day so that runs fall at the same times of day.
+func everyCron(interval string) (string, error) {
+	d, err := time.ParseDuration(interval)
+	if err != nil {
+		return "", fmt.Errorf("parse interval: %w", err)
+	}
+	if d < time.Minute || d%time.Minute != 0 {
+		return "", fmt.Errorf("interval %s must be a whole number of minutes", d)
+	}
+	switch minutes := int(d / time.Minute); {
+	case minutes == 1:
+		return "* * * * *", nil
+	case minutes < 60 && 60%minutes == 0:
+		return fmt.Sprintf("*/%d * * * *", minutes), nil
+	}
+	if d%time.Hour == 0 {
+		switch hours := int(d / time.Hour); {
+		case hours == 1:
+			return "0 * * * *", nil
+		case hours == 24:
+			return "0 0 * * *", nil
+		case hours < 24 && 24%hours == 0:
+			return fmt.Sprintf("0 */%d * * *", hours), nil
+		}
+	}
+	return "", fmt.Errorf("interval %s must evenly divide an hour or a day", d)
+}
+
+// String returns the schedule as it was written.
+func (s Schedule) String() string {
+	return s.spec
+}
+
+// Cron returns the cron expression the schedule runs on.
+func (s Schedule) Cron() string {
+	return s.cron
+}
+
+func (s Schedule) IsZero() bool {
+	return s.expr == nil
+}
+
+// Next returns the first scheduled run after t, or the zero time if there
+// isn't one.
+func (s Schedule) Next(t time.Time) time.Time {
+	if s.IsZero() {
+		return time.Time{}
+	}
+	return s.expr.Next(t)
+}
+
+// validateSchedules checks the schedule of every scheduled resource in defs.
+func validateSchedules(defs []ResourceDef) error {
+	for _, def := range defs {
+		var id ResourceID
+		var schedule string
+		switch casted := def.(type) {
+		case FeatureDef:
+			id, schedule = ResourceID{casted.Name, casted.Variant, FEATURE_VARIANT}, casted.Schedule
+		case TrainingSetDef:
+			id, schedule = ResourceID{casted.Name, casted.Variant, TRAINING_SET_VARIANT}, casted.Schedule
+		case SourceDef:
+			id, schedule = ResourceID{casted.Name, casted.Variant, SOURCE_VARIANT}, casted.Schedule
+		default:
+			continue
+		}
+		if _, err := ParseSchedule(schedule); err != nil {
+			return fmt.Errorf("%s %s (%s): %w", id.Type, id.Name, id.Variant, err)
+		}
+	}
+	return nil
+}
+
+// nextRun returns when a resource with the given schedule will next update,
+// or nil if it isn't scheduled.
+func nextRun(schedule string, now time.Time) *tspb.Timestamp {
+	parsed, err := ParseSchedule(schedule)
+	if err != nil || parsed.IsZero() {
+		return nil
+	}
+	next := parsed.Next(now)
+	if next.IsZero() {
+		return nil
+	}
+	return tspb.New(next)
+}
@@ -5,11 +5,16 @@
 package main
 
 import (
+	"encoding/base64"
 	"fmt"
 	"github.com/featureform/metadata/search"
 	"os"
+	"time"
 
+	"github.com/featureform/coordinator"
+	"github.com/featureform/coordinator/queue"
 	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
 	"go.uber.org/zap"
 )
 
@@ -35,6 +40,33 @@ func main() {
 			ApiKey: os.Getenv("TYPESENSE_APIKEY"),
 		},
 		StorageProvider: storageProvider,
+		ProviderValidator: func(t string, config []byte) error {
+			return provider.Validate(provider.Type(t), config)
+		},
+		JobPlanner: coordinator.NewJobPlanner(coordinator.ProviderFactoryFunc(provider.Get)),
+	}
+	if key := os.Getenv("CONFIG_ENCRYPTION_KEY"); key != "" {
+		decoded, err := base64.StdEncoding.DecodeString(key)
+		if err != nil {
+			logger.Panicw("Invalid config encryption key", "Err", err)
+		}
+		wrapper, err := metadata.NewLocalKeyWrapper(decoded)
+		if err != nil {
+			logger.Panicw("Invalid config encryption key", "Err", err)
+		}
+		config.KeyWrapper = wrapper
+	}
+	jobQueue, err := queue.New(queue.ConfigFromEnv(), nil)
+	if err != nil {
+		logger.Panicw("Invalid job queue", "Err", err)
+	}
+	config.JobPublisher = jobQueue
+	if os.Getenv("READ_ONLY") == "true" {
+		config.ReadOnly = true
+		if ttl, err := time.ParseDuration(os.Getenv("CACHE_TTL")); err == nil {
+			config.CacheTTL = ttl
+		}
+		logger.Infow("Running as read-only mirror", "CacheTTL", config.CacheTTL)
 	}
 	server, err := metadata.NewMetadataServer(config)
 	if err != nil {
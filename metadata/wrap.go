@@ -0,0 +1,32 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metadata
+
+import (
+	pb "github.com/featureform/metadata/proto"
+)
+
+// The Wrap functions build resources from their serialized form, for stores
+// other than the metadata server, such as the in-memory ones used in tests.
+
+func WrapProvider(serialized *pb.Provider) *Provider {
+	return wrapProtoProvider(serialized)
+}
+
+func WrapSourceVariant(serialized *pb.SourceVariant) *SourceVariant {
+	return wrapProtoSourceVariant(serialized)
+}
+
+func WrapFeatureVariant(serialized *pb.FeatureVariant) *FeatureVariant {
+	return wrapProtoFeatureVariant(serialized)
+}
+
+func WrapLabelVariant(serialized *pb.LabelVariant) *LabelVariant {
+	return wrapProtoLabelVariant(serialized)
+}
+
+func WrapTrainingSetVariant(serialized *pb.TrainingSetVariant) *TrainingSetVariant {
+	return wrapProtoTrainingSetVariant(serialized)
+}
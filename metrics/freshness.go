@@ -0,0 +1,83 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+// ValueAge is when the online values of a feature variant were last written.
+type ValueAge struct {
+	Feature     string
+	Variant     string
+	LastWritten time.Time
+}
+
+// ValueAgeMetrics reports how old the values being served are, so that a
+// materialization that has silently stopped can be alerted on even while its
+// job status looks healthy.
+type ValueAgeMetrics struct {
+	Age    *prometheus.GaugeVec
+	Logger *zap.SugaredLogger
+}
+
+func NewValueAgeMetrics(name string, logger *zap.SugaredLogger) *ValueAgeMetrics {
+	age := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_feature_value_age_seconds", name),
+			Help: "Seconds since the online values of a feature were last written, labeled by feature and variant",
+		},
+		[]string{"feature", "variant"},
+	)
+	prometheus.MustRegister(age)
+	return &ValueAgeMetrics{
+		Age:    age,
+		Logger: logger,
+	}
+}
+
+// Record sets the age of each feature as of now. Features whose write time
+// was never recorded are skipped.
+func (m *ValueAgeMetrics) Record(ages []ValueAge, now time.Time) {
+	for _, age := range ages {
+		if age.LastWritten.IsZero() {
+			continue
+		}
+		m.Age.WithLabelValues(age.Feature, age.Variant).Set(now.Sub(age.LastWritten).Seconds())
+	}
+}
+
+// RunEvery records the ages returned by ages every interval until ctx is
+// done.
+func (m *ValueAgeMetrics) RunEvery(ctx context.Context, interval time.Duration, ages func(ctx context.Context) ([]ValueAge, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		list, err := ages(ctx)
+		if err != nil {
+			m.Logger.Errorw("Failed to get feature value ages", "Error", err)
+		}
+		m.Record(list, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *ValueAgeMetrics) GetObservedAge(feature, variant string) (float64, error) {
+	var metric = &dto.Metric{}
+	if err := m.Age.WithLabelValues(feature, variant).Write(metric); err != nil {
+		return 0, err
+	}
+	return metric.Gauge.GetValue(), nil
+}
@@ -0,0 +1,126 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+// JobMetrics follows the lifecycle of the coordinator's jobs. Runs are
+// labeled by the type of resource they create and the provider it's on, and
+// finished runs also by how they ended.
+type JobMetrics struct {
+	Started    *prometheus.CounterVec
+	Finished   *prometheus.CounterVec
+	Duration   *prometheus.HistogramVec
+	QueueDepth prometheus.Gauge
+	LockWait   prometheus.Histogram
+	Logger     *zap.SugaredLogger
+}
+
+func NewJobMetrics(name string, logger *zap.SugaredLogger) *JobMetrics {
+	started := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_jobs_started_total", name),
+			Help: "Counter for job runs that were started, labeled by resource type and provider",
+		},
+		[]string{"type", "provider"},
+	)
+	finished := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_jobs_finished_total", name),
+			Help: "Counter for job runs that finished, labeled by resource type, provider and status",
+		},
+		[]string{"type", "provider", "status"},
+	)
+	duration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    fmt.Sprintf("%s_job_duration_seconds", name),
+			Help:    "Duration of job runs, labeled by resource type, provider and status",
+			Buckets: prometheus.ExponentialBuckets(1, 2, 16),
+		},
+		[]string{"type", "provider", "status"},
+	)
+	queueDepth := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: fmt.Sprintf("%s_job_queue_depth", name),
+			Help: "Jobs that are waiting to be run or are running",
+		},
+	)
+	lockWait := prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    fmt.Sprintf("%s_job_lock_wait_seconds", name),
+			Help:    "Time spent waiting for a job's lock before running it",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 16),
+		},
+	)
+	prometheus.MustRegister(started)
+	prometheus.MustRegister(finished)
+	prometheus.MustRegister(duration)
+	prometheus.MustRegister(queueDepth)
+	prometheus.MustRegister(lockWait)
+	return &JobMetrics{
+		Started:    started,
+		Finished:   finished,
+		Duration:   duration,
+		QueueDepth: queueDepth,
+		LockWait:   lockWait,
+		Logger:     logger,
+	}
+}
+
+func (m *JobMetrics) JobStarted(resourceType, provider string) {
+	m.Started.WithLabelValues(resourceType, provider).Inc()
+}
+
+func (m *JobMetrics) JobFinished(resourceType, provider, status string, duration time.Duration) {
+	m.Finished.WithLabelValues(resourceType, provider, status).Inc()
+	m.Duration.WithLabelValues(resourceType, provider, status).Observe(duration.Seconds())
+}
+
+func (m *JobMetrics) ObserveLockWait(wait time.Duration) {
+	m.LockWait.Observe(wait.Seconds())
+}
+
+// RunEvery records the queue depth returned by depth every interval until
+// ctx is done.
+func (m *JobMetrics) RunEvery(ctx context.Context, interval time.Duration, depth func(ctx context.Context) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if n, err := depth(ctx); err != nil {
+			m.Logger.Errorw("Failed to get job queue depth", "Error", err)
+		} else {
+			m.QueueDepth.Set(float64(n))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *JobMetrics) ExposePort(port string) {
+	http.Handle("/metrics", promhttp.Handler())
+	log.Fatal(http.ListenAndServe(port, nil))
+}
+
+func (m *JobMetrics) GetFinishedCount(resourceType, provider, status string) (int, error) {
+	var metric = &dto.Metric{}
+	if err := m.Finished.WithLabelValues(resourceType, provider, status).Write(metric); err != nil {
+		return 0, err
+	}
+	return int(metric.Counter.GetValue()), nil
+}
@@ -5,12 +5,15 @@
 package metrics
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
 	prometheus "github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
 )
 
 func GetCounterValue(metric *prometheus.CounterVec, labelValues ...string) (float64, error) {
@@ -124,3 +127,94 @@ func TestMetrics(t *testing.T) {
 	assert.Equal(t, int(latencyTrainingCounterValue), latencyTrainingCount, "Training latency records 6 events")
 
 }
+
+func TestRunProbe(t *testing.T) {
+	probeMetrics := NewProbeMetrics("test_probes", zap.NewExample().Sugar())
+	failing := true
+	probe := Probe{
+		Name:   "canary",
+		Target: "feature.variant",
+		Run: func(ctx context.Context) error {
+			if failing {
+				return errors.New("probe failed")
+			}
+			return nil
+		},
+	}
+	if err := probeMetrics.RunProbe(context.Background(), probe, time.Second); err == nil {
+		t.Fatalf("Failing probe succeeded")
+	}
+	failing = false
+	for i := 0; i < 2; i++ {
+		if err := probeMetrics.RunProbe(context.Background(), probe, time.Second); err != nil {
+			t.Fatalf("Probe failed: %s", err)
+		}
+	}
+	successes, err := probeMetrics.GetObservedCount("canary", "feature.variant", string(SUCCESS))
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 2, successes, "2 successful probes should be recorded")
+	errorCount, err := probeMetrics.GetObservedCount("canary", "feature.variant", string(ERROR))
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 1, errorCount, "1 failed probe should be recorded")
+	latencyCount, err := GetHistogramValue(probeMetrics.Latency, "canary", "feature.variant", string(SUCCESS))
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 2, int(latencyCount), "Probe latency records 2 successes")
+}
+
+func TestRecordValueAge(t *testing.T) {
+	ageMetrics := NewValueAgeMetrics("test_freshness", zap.NewExample().Sugar())
+	now := time.Now()
+	ageMetrics.Record([]ValueAge{
+		{Feature: "feature", Variant: "variant", LastWritten: now.Add(-time.Minute)},
+		{Feature: "unwritten", Variant: "variant"},
+	}, now)
+	age, err := ageMetrics.GetObservedAge("feature", "variant")
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 60.0, age, "Age should be a minute")
+	unwritten, err := ageMetrics.GetObservedAge("unwritten", "variant")
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 0.0, unwritten, "Unwritten features shouldn't have an age")
+}
+
+func TestJobMetrics(t *testing.T) {
+	jobMetrics := NewJobMetrics("test_jobs", zap.NewExample().Sugar())
+	jobMetrics.JobStarted("FEATURE_VARIANT", "redis")
+	jobMetrics.JobFinished("FEATURE_VARIANT", "redis", "failed", time.Second)
+	jobMetrics.JobStarted("FEATURE_VARIANT", "redis")
+	jobMetrics.JobFinished("FEATURE_VARIANT", "redis", "succeeded", 2*time.Second)
+	started, err := GetCounterValue(jobMetrics.Started, "FEATURE_VARIANT", "redis")
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 2.0, started, "2 started jobs should be recorded")
+	failed, err := jobMetrics.GetFinishedCount("FEATURE_VARIANT", "redis", "failed")
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 1, failed, "1 failed job should be recorded")
+	durations, err := GetHistogramValue(jobMetrics.Duration, "FEATURE_VARIANT", "redis", "succeeded")
+	if err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 1, int(durations), "Job duration records 1 success")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	jobMetrics.RunEvery(ctx, time.Minute, func(ctx context.Context) (int, error) {
+		return 3, nil
+	})
+	var m = &dto.Metric{}
+	if err := jobMetrics.QueueDepth.Write(m); err != nil {
+		t.Fatalf("Could not fetch value: %v", err)
+	}
+	assert.Equal(t, 3.0, m.Gauge.GetValue(), "Queue depth should be recorded")
+}
@@ -0,0 +1,109 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"go.uber.org/zap"
+)
+
+// Probe is a synthetic check that exercises a real code path, such as
+// serving a canary feature, so that breakage shows up in metrics before
+// users hit it.
+type Probe struct {
+	Name   string
+	Target string
+	Run    func(ctx context.Context) error
+}
+
+type ProbeMetrics struct {
+	Count   *prometheus.CounterVec
+	Latency *prometheus.HistogramVec
+	Logger  *zap.SugaredLogger
+}
+
+func NewProbeMetrics(name string, logger *zap.SugaredLogger) *ProbeMetrics {
+	count := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: fmt.Sprintf("%s_probe_counter", name),
+			Help: "Counter for synthetic probe runs, labeled by probe, target and status",
+		},
+		[]string{"probe", "target", "status"},
+	)
+	latency := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    fmt.Sprintf("%s_probe_duration_seconds", name),
+			Help:    "Latency for synthetic probe runs, labeled by probe, target and status",
+			Buckets: prometheus.ExponentialBuckets(0.005, 2, 12),
+		},
+		[]string{"probe", "target", "status"},
+	)
+	prometheus.MustRegister(count)
+	prometheus.MustRegister(latency)
+	return &ProbeMetrics{
+		Count:   count,
+		Latency: latency,
+		Logger:  logger,
+	}
+}
+
+// RunProbe runs probe once with timeout and records its outcome.
+func (m *ProbeMetrics) RunProbe(ctx context.Context, probe Probe, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	start := time.Now()
+	err := probe.Run(ctx)
+	status := string(SUCCESS)
+	if err != nil {
+		status = string(ERROR)
+		m.Logger.Errorw("Probe failed", "Probe", probe.Name, "Target", probe.Target, "Error", err)
+	}
+	m.Latency.WithLabelValues(probe.Name, probe.Target, status).Observe(time.Since(start).Seconds())
+	m.Count.WithLabelValues(probe.Name, probe.Target, status).Inc()
+	return err
+}
+
+// RunEvery runs the probes returned by probes every interval until ctx is
+// done. Probes are listed on each run so that targets added after startup are
+// picked up. Each run is given interval to complete.
+func (m *ProbeMetrics) RunEvery(ctx context.Context, interval time.Duration, probes func() ([]Probe, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		list, err := probes()
+		if err != nil {
+			m.Logger.Errorw("Failed to list probes", "Error", err)
+		}
+		for _, probe := range list {
+			m.RunProbe(ctx, probe, interval)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *ProbeMetrics) ExposePort(port string) {
+	http.Handle("/metrics", promhttp.Handler())
+	log.Fatal(http.ListenAndServe(port, nil))
+}
+
+func (m *ProbeMetrics) GetObservedCount(probe, target, status string) (int, error) {
+	var metric = &dto.Metric{}
+	if err := m.Count.WithLabelValues(probe, target, status).Write(metric); err != nil {
+		return 0, err
+	}
+	return int(metric.Counter.GetValue()), nil
+}
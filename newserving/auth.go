@@ -0,0 +1,367 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package newserving
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/featureform/metadata"
+	pb "github.com/featureform/proto"
+	jwt "github.com/form3tech-oss/jwt-go"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	grpcmeta "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	apiKeyHeader        = "x-api-key"
+	authorizationHeader = "authorization"
+	// MissingScopesReason is the reason of the error details that a
+	// PermissionDenied error has for each feature the caller can't serve.
+	MissingScopesReason = "MISSING_SCOPES"
+)
+
+// Principal is the caller of a serving request and the scopes it was granted.
+type Principal struct {
+	Subject string   `json:"subject"`
+	Scopes  []string `json:"scopes"`
+}
+
+// anonymous is the principal of requests without credentials. It has no
+// scopes, so it can only serve features that don't require any.
+var anonymous = &Principal{}
+
+func (p *Principal) hasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// missingScopes returns the required scopes the principal doesn't have.
+func (p *Principal) missingScopes(required []string) []string {
+	missing := make([]string, 0)
+	for _, scope := range required {
+		if !p.hasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	return missing
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext returns the principal a request was authenticated as.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// Authenticator finds the principal that made a request from its headers.
+// It returns nil if the request doesn't have the credentials it reads, and an
+// error if it has them but they're invalid.
+type Authenticator interface {
+	Authenticate(md grpcmeta.MD) (*Principal, error)
+}
+
+// Authenticators authenticates a request with the first of its
+// authenticators that finds credentials in it.
+type Authenticators []Authenticator
+
+func (auths Authenticators) Authenticate(md grpcmeta.MD) (*Principal, error) {
+	for _, auth := range auths {
+		p, err := auth.Authenticate(md)
+		if err != nil || p != nil {
+			return p, err
+		}
+	}
+	return nil, nil
+}
+
+// APIKeys authenticates requests by the key in their x-api-key header.
+type APIKeys map[string]Principal
+
+func (keys APIKeys) Authenticate(md grpcmeta.MD) (*Principal, error) {
+	values := md.Get(apiKeyHeader)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	p, has := keys[values[0]]
+	if !has {
+		return nil, errors.New("unknown api key")
+	}
+	return &p, nil
+}
+
+// JWTAuthenticator authenticates requests by the bearer token in their
+// authorization header. Tokens are signed with HMAC using a []byte Key, or
+// with RSA using an *rsa.PublicKey. Their scopes are read from a space
+// separated "scope" claim, as in OAuth 2, or a "scopes" list.
+type JWTAuthenticator struct {
+	Key interface{}
+	// Issuer and Audience are checked against the token's claims if set.
+	Issuer   string
+	Audience string
+}
+
+func (auth JWTAuthenticator) Authenticate(md grpcmeta.MD) (*Principal, error) {
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	raw := strings.TrimSpace(values[0])
+	if len(raw) < len("bearer ") || !strings.EqualFold(raw[:len("bearer ")], "bearer ") {
+		return nil, nil
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(strings.TrimSpace(raw[len("bearer "):]), claims, auth.key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if auth.Issuer != "" && !claims.VerifyIssuer(auth.Issuer, true) {
+		return nil, errors.New("invalid token: wrong issuer")
+	}
+	if auth.Audience != "" && !claims.VerifyAudience(auth.Audience, true) {
+		return nil, errors.New("invalid token: wrong audience")
+	}
+	p := &Principal{}
+	p.Subject, _ = claims["sub"].(string)
+	if scope, ok := claims["scope"].(string); ok {
+		p.Scopes = append(p.Scopes, strings.Fields(scope)...)
+	}
+	if scopes, ok := claims["scopes"].([]interface{}); ok {
+		for _, scope := range scopes {
+			if s, ok := scope.(string); ok {
+				p.Scopes = append(p.Scopes, s)
+			}
+		}
+	}
+	return p, nil
+}
+
+// key checks that the token is signed with the kind of key configured, so an
+// RSA public key can't be used as an HMAC secret.
+func (auth JWTAuthenticator) key(token *jwt.Token) (interface{}, error) {
+	switch auth.Key.(type) {
+	case []byte:
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			return auth.Key, nil
+		}
+	case *rsa.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); ok {
+			return auth.Key, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+}
+
+func (serv *FeatureServer) authenticate(ctx context.Context, auth Authenticator) (context.Context, error) {
+	p := anonymous
+	if md, ok := grpcmeta.FromIncomingContext(ctx); ok {
+		found, err := auth.Authenticate(md)
+		if err != nil {
+			serv.Logger.Infow("Authentication failed", "Err", err)
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if found != nil {
+			p = found
+		}
+	}
+	return context.WithValue(ctx, principalKey{}, p), nil
+}
+
+// UnaryAuthInterceptor authenticates requests with auth and checks that the
+// caller has the scopes of every feature and label whose values it asks for.
+// Requests without credentials can only serve those that don't declare
+// scopes.
+func (serv *FeatureServer) UnaryAuthInterceptor(auth Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := serv.authenticate(ctx, auth)
+		if err != nil {
+			return nil, err
+		}
+		if err := serv.authorize(ctx, req); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor authenticates streaming requests with auth, and
+// authorizes each request they receive like UnaryAuthInterceptor does.
+func (serv *FeatureServer) StreamAuthInterceptor(auth Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := serv.authenticate(stream.Context(), auth)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx, serv: serv})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	serv *FeatureServer
+}
+
+func (stream *authenticatedStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *authenticatedStream) RecvMsg(m interface{}) error {
+	if err := stream.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return stream.serv.authorize(stream.ctx, m)
+}
+
+// servedResource is a feature or label that a request serves values of.
+type servedResource struct {
+	Type metadata.ResourceType
+	ID   metadata.NameVariant
+}
+
+func (res servedResource) kind() string {
+	if res.Type == metadata.LABEL_VARIANT {
+		return "label"
+	}
+	return "feature"
+}
+
+func servedFeatures(features []*pb.FeatureID) []servedResource {
+	served := make([]servedResource, len(features))
+	for i, feature := range features {
+		served[i] = servedResource{metadata.FEATURE_VARIANT, metadata.NameVariant{Name: feature.GetName(), Variant: feature.GetVersion()}}
+	}
+	return served
+}
+
+// servedResources returns the features and labels whose values req serves.
+// A training data request serves its training set's label, and either the
+// features it selects or all of the training set's.
+func (serv *FeatureServer) servedResources(ctx context.Context, req interface{}) ([]servedResource, error) {
+	switch casted := req.(type) {
+	case *pb.FeatureServeRequest:
+		return servedFeatures(casted.GetFeatures()), nil
+	case *pb.MultiEntityFeatureServeRequest:
+		return servedFeatures(casted.GetFeatures()), nil
+	case *pb.ValueLineageRequest:
+		// The report holds the value, so it needs the same scopes.
+		return servedFeatures([]*pb.FeatureID{casted.GetFeature()}), nil
+	case *pb.LabelServeRequest:
+		served := make([]servedResource, len(casted.GetLabels()))
+		for i, label := range casted.GetLabels() {
+			served[i] = servedResource{metadata.LABEL_VARIANT, metadata.NameVariant{Name: label.GetName(), Variant: label.GetVersion()}}
+		}
+		return served, nil
+	case *pb.TrainingDataRequest:
+		id := metadata.NameVariant{Name: casted.GetId().GetName(), Variant: casted.GetId().GetVersion()}
+		ts, err := serv.Metadata.GetTrainingSetVariant(ctx, id)
+		if err != nil {
+			return nil, lookupError("training set", id, err)
+		}
+		served := servedFeatures(casted.GetFeatures())
+		if len(served) == 0 {
+			for _, feature := range ts.Features() {
+				served = append(served, servedResource{metadata.FEATURE_VARIANT, feature})
+			}
+		}
+		return append(served, servedResource{metadata.LABEL_VARIANT, ts.Label()}), nil
+	}
+	return nil, nil
+}
+
+// lookupError is returned when the scopes of what a request serves can't be
+// looked up, so that it's denied rather than served unchecked. Resources
+// that don't exist are denied the same as those the caller can't serve, so
+// callers can't tell which names exist.
+func lookupError(kind string, id metadata.NameVariant, err error) error {
+	if status.Code(err) == codes.NotFound {
+		return status.Errorf(codes.PermissionDenied, "not permitted to serve %s %s.%s", kind, id.Name, id.Variant)
+	}
+	return status.Errorf(codes.Unavailable, "could not check scopes of %s %s.%s: %v", kind, id.Name, id.Variant, err)
+}
+
+func (serv *FeatureServer) requiredScopes(ctx context.Context, res servedResource) ([]string, error) {
+	if res.Type == metadata.LABEL_VARIANT {
+		variant, err := serv.Metadata.GetLabelVariant(ctx, res.ID)
+		if err != nil {
+			return nil, err
+		}
+		return variant.Scopes(), nil
+	}
+	variant, err := serv.Metadata.GetFeatureVariant(ctx, res.ID)
+	if err != nil {
+		return nil, err
+	}
+	return variant.Scopes(), nil
+}
+
+// authorize returns a PermissionDenied error, with an ErrorInfo for each
+// feature or label the caller is missing scopes for, if it can't be served
+// everything req asks for.
+func (serv *FeatureServer) authorize(ctx context.Context, req interface{}) error {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok {
+		p = anonymous
+	}
+	served, err := serv.servedResources(ctx, req)
+	if err != nil {
+		serv.Logger.Infow("Denied serving request", "Subject", p.Subject, "Err", err)
+		return err
+	}
+	var denied []string
+	var details []*errdetails.ErrorInfo
+	checked := make(map[servedResource]bool)
+	for _, res := range served {
+		if checked[res] {
+			continue
+		}
+		checked[res] = true
+		scopes, err := serv.requiredScopes(ctx, res)
+		if err != nil {
+			err = lookupError(res.kind(), res.ID, err)
+			serv.Logger.Infow("Denied serving request", "Subject", p.Subject, "Err", err)
+			return err
+		}
+		missing := p.missingScopes(scopes)
+		if len(missing) == 0 {
+			continue
+		}
+		sort.Strings(missing)
+		denied = append(denied, fmt.Sprintf("%s %s.%s (missing %s)", res.kind(), res.ID.Name, res.ID.Variant, strings.Join(missing, ", ")))
+		details = append(details, &errdetails.ErrorInfo{
+			Reason: MissingScopesReason,
+			Domain: "featureform",
+			Metadata: map[string]string{
+				res.kind(): res.ID.Name,
+				"variant":  res.ID.Variant,
+				"scopes":   strings.Join(missing, " "),
+			},
+		})
+	}
+	if len(denied) == 0 {
+		return nil
+	}
+	serv.Logger.Infow("Denied serving request", "Subject", p.Subject, "Denied", denied)
+	st := status.New(codes.PermissionDenied, fmt.Sprintf("not permitted to serve %s", strings.Join(denied, "; ")))
+	for _, detail := range details {
+		if withDetail, err := st.WithDetails(detail); err == nil {
+			st = withDetail
+		}
+	}
+	return st.Err()
+}
@@ -0,0 +1,68 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package newserving
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/metrics"
+	"github.com/featureform/provider"
+)
+
+// ValueAges returns when the online values of each ready feature variant
+// were last written.
+func (serv *FeatureServer) ValueAges(ctx context.Context) ([]metrics.ValueAge, error) {
+	features, err := serv.Metadata.ListFeatures(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list features: %w", err)
+	}
+	var ids []metadata.NameVariant
+	for _, feature := range features {
+		ids = append(ids, feature.NameVariants()...)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	variants, err := serv.Metadata.GetFeatureVariants(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("get feature variants: %w", err)
+	}
+	ages := make([]metrics.ValueAge, 0, len(variants))
+	for _, variant := range variants {
+		if variant.Status() != metadata.READY {
+			continue
+		}
+		written, err := serv.lastWritten(ctx, variant)
+		if err != nil {
+			serv.Logger.Errorw("Failed to get last written time", "Name", variant.Name(), "Variant", variant.Variant(), "Error", err)
+			continue
+		}
+		ages = append(ages, metrics.ValueAge{
+			Feature:     variant.Name(),
+			Variant:     variant.Variant(),
+			LastWritten: written,
+		})
+	}
+	return ages, nil
+}
+
+func (serv *FeatureServer) lastWritten(ctx context.Context, variant *metadata.FeatureVariant) (time.Time, error) {
+	providerEntry, err := variant.FetchProvider(serv.Metadata, ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("fetch provider: %w", err)
+	}
+	p, err := serv.providers.Get(providerEntry.Name(), provider.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get provider: %w", err)
+	}
+	store, err := p.AsOnlineStore()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return provider.LastWritten(store, variant.Name(), variant.Variant())
+}
@@ -0,0 +1,104 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package newserving
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/featureform/metadata"
+	pb "github.com/featureform/proto"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxLineageDepth is how many transformations upstream of a feature the
+// lineage of its values is followed.
+const maxLineageDepth = 10
+
+// ValueLineage compiles a report of where a feature's value for an entity
+// came from: when its sources' data was last updated, the latest runs of the
+// jobs that transformed and materialized it, and when it was written to and
+// served from the online store. Parts of the report that can't be compiled,
+// such as a value that can't be served, are listed as warnings rather than
+// failing the report, since those are what it's meant to explain.
+func (serv *FeatureServer) ValueLineage(ctx context.Context, req *pb.ValueLineageRequest) (*pb.ValueLineage, error) {
+	name, variant := req.GetFeature().GetName(), req.GetFeature().GetVersion()
+	feature, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		return nil, err
+	}
+	report := &pb.ValueLineage{Feature: req.GetFeature(), Entity: req.GetEntity()}
+	warn := func(format string, args ...interface{}) {
+		report.Warnings = append(report.Warnings, fmt.Sprintf(format, args...))
+	}
+	if req.GetEntity().GetName() != feature.Entity() {
+		warn("feature is of entity %s, not %s", feature.Entity(), req.GetEntity().GetName())
+	}
+	entityMap := map[string]string{req.GetEntity().GetName(): req.GetEntity().GetValue()}
+	if report.Value, err = serv.getFeatureValue(ctx, name, variant, entityMap); err != nil {
+		warn("serve value: %s", err)
+	}
+	report.Served = tspb.New(time.Now())
+	if written, err := serv.lastWritten(ctx, feature); err != nil {
+		warn("get online write time: %s", err)
+	} else if !written.IsZero() {
+		report.OnlineWritten = tspb.New(written)
+	}
+	featureID := metadata.ResourceID{Name: name, Variant: variant, Type: metadata.FEATURE_VARIANT}
+	if report.Materialization, err = serv.latestJobRun(ctx, featureID); err != nil {
+		warn("get materialization runs: %s", err)
+	}
+	report.Source = serv.sourceLineage(ctx, feature.Source(), 0, warn)
+	return report, nil
+}
+
+func (serv *FeatureServer) sourceLineage(ctx context.Context, id metadata.NameVariant, depth int, warn func(string, ...interface{})) *pb.SourceLineage {
+	lineage := &pb.SourceLineage{Name: id.Name, Version: id.Variant}
+	source, err := serv.Metadata.GetSourceVariant(ctx, id)
+	if err != nil {
+		warn("get source %s (%s): %s", id.Name, id.Variant, err)
+		return lineage
+	}
+	if stats := source.Stats(); stats != nil {
+		lineage.LatestEvent = tspb.New(stats.MaxTS)
+		lineage.StatsComputed = tspb.New(stats.Computed)
+	}
+	sourceID := metadata.ResourceID{Name: id.Name, Variant: id.Variant, Type: metadata.SOURCE_VARIANT}
+	if lineage.Job, err = serv.latestJobRun(ctx, sourceID); err != nil {
+		warn("get runs of source %s (%s): %s", id.Name, id.Variant, err)
+	}
+	if !source.IsSQLTransformation() {
+		return lineage
+	}
+	if depth >= maxLineageDepth {
+		warn("lineage of source %s (%s) is more than %d transformations deep", id.Name, id.Variant, maxLineageDepth)
+		return lineage
+	}
+	for _, input := range source.SQLTransformationSources() {
+		lineage.Inputs = append(lineage.Inputs, serv.sourceLineage(ctx, input, depth+1, warn))
+	}
+	return lineage
+}
+
+// latestJobRun returns the latest run of a resource's job, or nil if it
+// hasn't been run.
+func (serv *FeatureServer) latestJobRun(ctx context.Context, id metadata.ResourceID) (*pb.JobRunSummary, error) {
+	runs, err := serv.Metadata.GetJobRuns(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	run := runs[len(runs)-1]
+	return &pb.JobRunSummary{
+		Status:  string(run.Status),
+		Trigger: string(run.Trigger),
+		Started: tspb.New(run.Started),
+		Ended:   tspb.New(run.Ended),
+		Error:   run.Error,
+	}, nil
+}
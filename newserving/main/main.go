@@ -5,15 +5,21 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/featureform/metadata"
 	"github.com/featureform/metrics"
 	"github.com/featureform/newserving"
 
 	pb "github.com/featureform/proto"
+	jwt "github.com/form3tech-oss/jwt-go"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 )
@@ -40,11 +46,51 @@ func main() {
 	}
 
 	serv, err := newserving.NewFeatureServer(meta, promMetrics, logger)
+	if err == nil && os.Getenv("SANDBOX") == "true" {
+		logger.Info("Serving mock values in sandbox mode")
+		serv.Sandbox = true
+	}
 
-	grpcServer := grpc.NewServer()
 	if err != nil {
 		logger.Panicw("Failed to create training server", "Err", err)
 	}
+	auth := authenticators(logger)
+	limits := requestLimits(logger)
+	// Requests are validated first, so that oversized ones are rejected
+	// before their features' scopes are looked up.
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(serv.UnaryValidationInterceptor(limits), serv.UnaryAuthInterceptor(auth)),
+		grpc.ChainStreamInterceptor(serv.StreamValidationInterceptor(limits), serv.StreamAuthInterceptor(auth)),
+	)
+	if uri := os.Getenv("SPOOL_URI"); uri != "" {
+		spooler, err := newserving.NewSpooler(uri)
+		if err != nil {
+			logger.Panicw("Failed to create training data spooler", "Err", err)
+		}
+		if rows := os.Getenv("SPOOL_ROWS_PER_FILE"); rows != "" {
+			if spooler.RowsPerFile, err = strconv.Atoi(rows); err != nil || spooler.RowsPerFile <= 0 {
+				logger.Panicw("Invalid spool rows per file", "Rows", rows)
+			}
+		}
+		if expiry := os.Getenv("SPOOL_URL_EXPIRY"); expiry != "" {
+			if spooler.URLExpiry, err = time.ParseDuration(expiry); err != nil {
+				logger.Panicw("Invalid spool url expiry", "Err", err)
+			}
+		}
+		logger.Infow("Spooling training data", "URI", uri)
+		serv.Spooler = spooler
+	}
+	if canary := os.Getenv("CANARY_FEATURE"); canary != "" {
+		startCanaryProbe(serv, canary, os.Getenv("CANARY_ENTITY"), logger)
+	}
+	if env := os.Getenv("VALUE_AGE_INTERVAL"); env != "" {
+		interval, err := time.ParseDuration(env)
+		if err != nil {
+			logger.Panicw("Invalid value age interval", "Err", err)
+		}
+		ageMetrics := metrics.NewValueAgeMetrics("serving", logger)
+		go ageMetrics.RunEvery(context.Background(), interval, serv.ValueAges)
+	}
 	pb.RegisterFeatureServer(grpcServer, serv)
 	logger.Infow("Serving metrics", "Port", metricsPort)
 	go promMetrics.ExposePort(metricsPort)
@@ -55,3 +101,94 @@ func main() {
 	}
 
 }
+
+// authenticators reads API keys from the JSON file at SERVING_API_KEYS_FILE,
+// mapping each key to its subject and scopes, and verifies JWTs signed with
+// SERVING_JWT_SECRET or the RSA key in SERVING_JWT_PUBLIC_KEY_FILE. Without
+// either, every request is anonymous and only features without scopes serve.
+func authenticators(logger *zap.SugaredLogger) newserving.Authenticators {
+	var auths newserving.Authenticators
+	if path := os.Getenv("SERVING_API_KEYS_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Panicw("Failed to read api keys", "Err", err)
+		}
+		keys := make(newserving.APIKeys)
+		if err := json.Unmarshal(data, &keys); err != nil {
+			logger.Panicw("Invalid api keys file", "Err", err)
+		}
+		logger.Infow("Authenticating api keys", "Keys", len(keys))
+		auths = append(auths, keys)
+	}
+	jwtAuth := newserving.JWTAuthenticator{
+		Issuer:   os.Getenv("SERVING_JWT_ISSUER"),
+		Audience: os.Getenv("SERVING_JWT_AUDIENCE"),
+	}
+	if secret := os.Getenv("SERVING_JWT_SECRET"); secret != "" {
+		jwtAuth.Key = []byte(secret)
+	} else if path := os.Getenv("SERVING_JWT_PUBLIC_KEY_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logger.Panicw("Failed to read jwt public key", "Err", err)
+		}
+		if jwtAuth.Key, err = jwt.ParseRSAPublicKeyFromPEM(data); err != nil {
+			logger.Panicw("Invalid jwt public key", "Err", err)
+		}
+	}
+	if jwtAuth.Key != nil {
+		logger.Infow("Authenticating jwts", "Issuer", jwtAuth.Issuer, "Audience", jwtAuth.Audience)
+		auths = append(auths, jwtAuth)
+	}
+	return auths
+}
+
+// requestLimits overrides the default request limits with
+// SERVING_MAX_FEATURES, SERVING_MAX_ENTITIES and
+// SERVING_MAX_ENTITY_VALUE_LENGTH. A limit of 0 isn't enforced.
+func requestLimits(logger *zap.SugaredLogger) newserving.RequestLimits {
+	limits := newserving.DefaultRequestLimits
+	for env, limit := range map[string]*int{
+		"SERVING_MAX_FEATURES":            &limits.MaxFeatures,
+		"SERVING_MAX_ENTITIES":            &limits.MaxEntities,
+		"SERVING_MAX_ENTITY_VALUE_LENGTH": &limits.MaxEntityValueLength,
+	} {
+		value := os.Getenv(env)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			logger.Panicw("Invalid request limit", "Env", env, "Value", value)
+		}
+		*limit = parsed
+	}
+	logger.Infow("Validating requests", "Limits", limits)
+	return limits
+}
+
+// startCanaryProbe periodically serves the CANARY_FEATURE (name.variant) for
+// CANARY_ENTITY (entity=value) every PROBE_INTERVAL.
+func startCanaryProbe(serv *newserving.FeatureServer, feature, entity string, logger *zap.SugaredLogger) {
+	interval := time.Minute
+	if env := os.Getenv("PROBE_INTERVAL"); env != "" {
+		parsed, err := time.ParseDuration(env)
+		if err != nil {
+			logger.Panicw("Invalid probe interval", "Err", err)
+		}
+		interval = parsed
+	}
+	nameVariant := strings.SplitN(feature, ".", 2)
+	if len(nameVariant) != 2 {
+		logger.Panicw("Canary feature must be name.variant", "Feature", feature)
+	}
+	entityValue := strings.SplitN(entity, "=", 2)
+	if len(entityValue) != 2 {
+		logger.Panicw("Canary entity must be entity=value", "Entity", entity)
+	}
+	probe := serv.CanaryProbe(nameVariant[0], nameVariant[1], entityValue[0], entityValue[1])
+	probeMetrics := metrics.NewProbeMetrics("serving", logger)
+	logger.Infow("Starting canary probe", "Feature", feature, "Entity", entity, "Interval", interval)
+	go probeMetrics.RunEvery(context.Background(), interval, func() ([]metrics.Probe, error) {
+		return []metrics.Probe{probe}, nil
+	})
+}
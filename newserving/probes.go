@@ -0,0 +1,40 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package newserving
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/featureform/metrics"
+	pb "github.com/featureform/proto"
+	"github.com/google/uuid"
+	grpcmeta "google.golang.org/grpc/metadata"
+)
+
+// CanaryProbe returns a probe that serves feature for the given entity through
+// the same path as user requests, so a broken online store or metadata
+// lookup is caught by the probe first.
+func (serv *FeatureServer) CanaryProbe(name, variant, entity, entityValue string) metrics.Probe {
+	req := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{{Name: name, Version: variant}},
+		Entities: []*pb.Entity{{Name: entity, Value: entityValue}},
+	}
+	return metrics.Probe{
+		Name:   "canary_serve",
+		Target: fmt.Sprintf("%s.%s", name, variant),
+		Run: func(ctx context.Context) error {
+			ctx = grpcmeta.NewIncomingContext(ctx, grpcmeta.Pairs(requestIDHeader, "canary-"+uuid.NewString()))
+			row, err := serv.FeatureServe(ctx, req)
+			if err != nil {
+				return err
+			}
+			if len(row.GetValues()) != 1 {
+				return fmt.Errorf("canary served %d values, expected 1", len(row.GetValues()))
+			}
+			return nil
+		},
+	}
+}
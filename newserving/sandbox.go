@@ -0,0 +1,41 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package newserving
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/featureform/provider"
+)
+
+// parseMockValue converts a feature's mock value from metadata into the Go
+// type its online store would return for valueType.
+func parseMockValue(valueType, raw string) (interface{}, error) {
+	switch provider.ValueType(valueType) {
+	case provider.NilType, provider.String:
+		return raw, nil
+	case provider.Int:
+		return strconv.Atoi(raw)
+	case provider.Int32:
+		val, err := strconv.ParseInt(raw, 10, 32)
+		return int32(val), err
+	case provider.Int64:
+		return strconv.ParseInt(raw, 10, 64)
+	case provider.Float32:
+		val, err := strconv.ParseFloat(raw, 32)
+		return float32(val), err
+	case provider.Float64:
+		return strconv.ParseFloat(raw, 64)
+	case provider.Bool:
+		return strconv.ParseBool(raw)
+	case provider.Bytes:
+		return []byte(raw), nil
+	case provider.Decimal:
+		return provider.DecimalValue(raw), nil
+	default:
+		return nil, fmt.Errorf("mock values not supported for type %s", valueType)
+	}
+}
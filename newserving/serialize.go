@@ -8,6 +8,7 @@ import (
 	"fmt"
 
 	pb "github.com/featureform/proto"
+	"github.com/featureform/provider"
 )
 
 type feature struct {
@@ -103,6 +104,10 @@ func wrapValue(value interface{}) (proto *pb.Value, err error) {
 		proto = wrapInt64(typed)
 	case bool:
 		proto = wrapBool(typed)
+	case []byte:
+		proto = wrapBytes(typed)
+	case provider.DecimalValue:
+		proto = wrapDecimal(typed)
 	case *pb.Value:
 		proto = typed
 	case nil:
@@ -155,6 +160,18 @@ func wrapBool(val bool) *pb.Value {
 	}
 }
 
+func wrapBytes(val []byte) *pb.Value {
+	return &pb.Value{
+		Value: &pb.Value_BytesValue{BytesValue: val},
+	}
+}
+
+func wrapDecimal(val provider.DecimalValue) *pb.Value {
+	return &pb.Value{
+		Value: &pb.Value_DecimalValue{DecimalValue: string(val)},
+	}
+}
+
 func wrapNil(val interface{}) *pb.Value {
 	return &pb.Value{
 		Value: &pb.Value_StrValue{""},
@@ -12,40 +12,100 @@ import (
 	"github.com/featureform/metrics"
 	pb "github.com/featureform/proto"
 	"github.com/featureform/provider"
+	"github.com/google/uuid"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	grpcmeta "google.golang.org/grpc/metadata"
 )
 
 type FeatureServer struct {
 	pb.UnimplementedFeatureServer
-	Metrics  metrics.MetricsHandler
-	Metadata *metadata.Client
-	Logger   *zap.SugaredLogger
+	Metrics   metrics.MetricsHandler
+	Metadata  *metadata.Client
+	Logger    *zap.SugaredLogger
+	providers *provider.Cache
+	// Sandbox serves the mock value defined in metadata for features that
+	// have one, instead of reading from their online store.
+	Sandbox bool
+	// Spooler is where SpoolTrainingData writes training data. Spooling is
+	// disabled if it's nil.
+	Spooler *Spooler
 }
 
 func NewFeatureServer(meta *metadata.Client, promMetrics metrics.MetricsHandler, logger *zap.SugaredLogger) (*FeatureServer, error) {
 	logger.Debug("Creating new training data server")
 	return &FeatureServer{
-		Metadata: meta,
-		Metrics:  promMetrics,
-		Logger:   logger,
+		Metadata:  meta,
+		Metrics:   promMetrics,
+		Logger:    logger,
+		providers: provider.NewCache(),
 	}, nil
 }
 
+// requestIDHeader is read from incoming requests and echoed back so clients
+// can correlate a request with serving logs and store operations.
+const requestIDHeader = "x-request-id"
+
+// requestID returns the caller supplied request ID, or a new one if there
+// isn't one, and sets it as a response header.
+func requestID(ctx context.Context) string {
+	var id string
+	if md, ok := grpcmeta.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDHeader); len(ids) > 0 {
+			id = ids[0]
+		}
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+	grpc.SetHeader(ctx, grpcmeta.Pairs(requestIDHeader, id))
+	return id
+}
+
 func (serv *FeatureServer) TrainingData(req *pb.TrainingDataRequest, stream pb.Feature_TrainingDataServer) error {
 	id := req.GetId()
 	name, variant := id.GetName(), id.GetVersion()
 	featureObserver := serv.Metrics.BeginObservingTrainingServe(name, variant)
 	defer featureObserver.Finish()
-	logger := serv.Logger.With("Name", name, "Variant", variant)
+	logger := serv.Logger.With("Name", name, "Variant", variant, "RequestID", requestID(stream.Context()))
 	logger.Info("Serving training data")
-	iter, err := serv.getTrainingSetIterator(name, variant)
+	selection, err := serv.selectFeatures(stream.Context(), name, variant, req.GetFeatures())
+	if err != nil {
+		logger.Errorw("Invalid feature selection", "Error", err)
+		featureObserver.SetError()
+		return err
+	}
+	// Without a schema there's nothing to check the rows against.
+	numFeatures := -1
+	if req.GetIncludeSchema() {
+		schema, err := serv.trainingDataSchema(stream.Context(), name, variant)
+		if err != nil {
+			logger.Errorw("Failed to get training set schema", "Error", err)
+			featureObserver.SetError()
+			return err
+		}
+		selection.filterSchema(schema)
+		if err := stream.Send(&pb.TrainingDataRow{Schema: schema}); err != nil {
+			logger.Errorw("Failed to write to stream", "Error", err)
+			featureObserver.SetError()
+			return err
+		}
+		numFeatures = len(schema.Features)
+	}
+	iter, err := serv.getTrainingSetIterator(name, variant, selection)
 	if err != nil {
 		logger.Errorw("Failed to get training set iterator", "Error", err)
 		featureObserver.SetError()
 		return err
 	}
 	for iter.Next() {
+		if features := iter.Features(); numFeatures >= 0 && len(features) != numFeatures {
+			err := fmt.Errorf("training set row has %d features, schema has %d", len(features), numFeatures)
+			logger.Errorw("Training set doesn't match its schema", "Error", err)
+			featureObserver.SetError()
+			return err
+		}
 		sRow, err := serializedRow(iter.Features(), iter.Label())
 		if err != nil {
 			return err
@@ -65,7 +125,114 @@ func (serv *FeatureServer) TrainingData(req *pb.TrainingDataRequest, stream pb.F
 	return nil
 }
 
-func (serv *FeatureServer) getTrainingSetIterator(name, variant string) (provider.TrainingSetIterator, error) {
+// trainingDataSchema describes a training set's columns. Features are listed
+// in the order the training set was defined with, which is the order they
+// have in every row.
+func (serv *FeatureServer) trainingDataSchema(ctx context.Context, name, variant string) (*pb.TrainingDataSchema, error) {
+	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		return nil, err
+	}
+	featureIDs := ts.Features()
+	features, err := serv.Metadata.GetFeatureVariants(ctx, featureIDs)
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[metadata.NameVariant]string, len(features))
+	for _, feature := range features {
+		types[metadata.NameVariant{Name: feature.Name(), Variant: feature.Variant()}] = feature.Type()
+	}
+	label, err := ts.FetchLabel(serv.Metadata, ctx)
+	if err != nil {
+		return nil, err
+	}
+	schema := &pb.TrainingDataSchema{
+		Features: make([]*pb.TrainingDataColumn, len(featureIDs)),
+		Label: &pb.TrainingDataColumn{
+			Name:    label.Name(),
+			Version: label.Variant(),
+			Type:    label.Type(),
+		},
+	}
+	for i, id := range featureIDs {
+		schema.Features[i] = &pb.TrainingDataColumn{
+			Name:    id.Name,
+			Version: id.Variant,
+			Type:    types[id],
+		}
+	}
+	return schema, nil
+}
+
+// featureSelection is the subset of a training set's features that a request
+// asked for, in the order it asked for them.
+type featureSelection struct {
+	features []provider.ResourceID
+	// The position of each feature in the training set's rows.
+	positions []int
+}
+
+// filterSchema leaves only the selected features in schema. It does nothing
+// if there's no selection.
+func (selection *featureSelection) filterSchema(schema *pb.TrainingDataSchema) {
+	if selection == nil {
+		return
+	}
+	selected := make([]*pb.TrainingDataColumn, len(selection.positions))
+	for i, pos := range selection.positions {
+		selected[i] = schema.Features[pos]
+	}
+	schema.Features = selected
+}
+
+// selectFeatures returns nil if the request didn't ask for specific features.
+func (serv *FeatureServer) selectFeatures(ctx context.Context, name, variant string, requested []*pb.FeatureID) (*featureSelection, error) {
+	if len(requested) == 0 {
+		return nil, nil
+	}
+	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[metadata.NameVariant]int)
+	for i, id := range ts.Features() {
+		positions[id] = i
+	}
+	selection := &featureSelection{
+		features:  make([]provider.ResourceID, len(requested)),
+		positions: make([]int, len(requested)),
+	}
+	for i, feature := range requested {
+		id := metadata.NameVariant{Name: feature.GetName(), Variant: feature.GetVersion()}
+		pos, has := positions[id]
+		if !has {
+			return nil, fmt.Errorf("feature %s (%s) is not in training set %s (%s)", id.Name, id.Variant, name, variant)
+		}
+		selection.features[i] = provider.ResourceID{Name: id.Name, Variant: id.Variant, Type: provider.Feature}
+		selection.positions[i] = pos
+	}
+	return selection, nil
+}
+
+// selectedTrainingRows drops the features that weren't selected from rows
+// read from a store that can't leave them out itself.
+type selectedTrainingRows struct {
+	provider.TrainingSetIterator
+	positions []int
+}
+
+func (it *selectedTrainingRows) Features() []interface{} {
+	all := it.TrainingSetIterator.Features()
+	features := make([]interface{}, len(it.positions))
+	for i, pos := range it.positions {
+		if pos < len(all) {
+			features[i] = all[pos]
+		}
+	}
+	return features
+}
+
+func (serv *FeatureServer) getTrainingSetIterator(name, variant string, selection *featureSelection) (provider.TrainingSetIterator, error) {
 	ctx := context.TODO()
 	serv.Logger.Infow("Getting Training Set Iterator", "name", name, "variant", variant)
 	ts, err := serv.Metadata.GetTrainingSetVariant(ctx, metadata.NameVariant{name, variant})
@@ -77,7 +244,7 @@ func (serv *FeatureServer) getTrainingSetIterator(name, variant string) (provide
 	if err != nil {
 		return nil, err
 	}
-	p, err := provider.Get(provider.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	p, err := serv.providers.Get(providerEntry.Name(), provider.Type(providerEntry.Type()), providerEntry.SerializedConfig())
 	if err != nil {
 		return nil, err
 	}
@@ -87,8 +254,20 @@ func (serv *FeatureServer) getTrainingSetIterator(name, variant string) (provide
 		// That shouldn't be possible.
 		return nil, err
 	}
-	serv.Logger.Debugw("Get Training Set From Store", "name", name, "variant", variant)
-	return store.GetTrainingSet(provider.ResourceID{Name: name, Variant: variant})
+	id := provider.ResourceID{Name: name, Variant: variant}
+	if selection == nil {
+		serv.Logger.Debugw("Get Training Set From Store", "name", name, "variant", variant)
+		return store.GetTrainingSet(id)
+	}
+	if selector, ok := store.(provider.FeatureSelectionStore); ok {
+		serv.Logger.Debugw("Get Training Set Features From Store", "name", name, "variant", variant, "features", selection.features)
+		return selector.GetTrainingSetFeatures(id, selection.features)
+	}
+	iter, err := store.GetTrainingSet(id)
+	if err != nil {
+		return nil, err
+	}
+	return &selectedTrainingRows{iter, selection.positions}, nil
 }
 
 func (serv *FeatureServer) FeatureServe(ctx context.Context, req *pb.FeatureServeRequest) (*pb.FeatureRow, error) {
@@ -98,10 +277,12 @@ func (serv *FeatureServer) FeatureServe(ctx context.Context, req *pb.FeatureServ
 	for _, entity := range entities {
 		entityMap[entity.GetName()] = entity.GetValue()
 	}
+	reqID := requestID(ctx)
+	ctx = provider.WithRequestID(ctx, reqID)
 	vals := make([]*pb.Value, len(features))
 	for i, feature := range req.GetFeatures() {
 		name, variant := feature.GetName(), feature.GetVersion()
-		serv.Logger.Infow("Serving feature", "Name", name, "Variant", variant)
+		serv.Logger.Infow("Serving feature", "Name", name, "Variant", variant, "RequestID", reqID)
 		val, err := serv.getFeatureValue(ctx, name, variant, entityMap)
 		if err != nil {
 			return nil, err
@@ -113,30 +294,57 @@ func (serv *FeatureServer) FeatureServe(ctx context.Context, req *pb.FeatureServ
 	}, nil
 }
 
-func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant string, entityMap map[string]string) (*pb.Value, error) {
+// LabelServe serves the latest value of labels that are materialized to an
+// online store.
+func (serv *FeatureServer) LabelServe(ctx context.Context, req *pb.LabelServeRequest) (*pb.LabelRow, error) {
+	entityMap := make(map[string]string)
+	for _, entity := range req.GetEntities() {
+		entityMap[entity.GetName()] = entity.GetValue()
+	}
+	reqID := requestID(ctx)
+	ctx = provider.WithRequestID(ctx, reqID)
+	vals := make([]*pb.Value, len(req.GetLabels()))
+	for i, label := range req.GetLabels() {
+		name, variant := label.GetName(), label.GetVersion()
+		serv.Logger.Infow("Serving label", "Name", name, "Variant", variant, "RequestID", reqID)
+		val, err := serv.getLabelValue(ctx, name, variant, entityMap)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+	return &pb.LabelRow{
+		Values: vals,
+	}, nil
+}
+
+func (serv *FeatureServer) getLabelValue(ctx context.Context, name, variant string, entityMap map[string]string) (*pb.Value, error) {
 	obs := serv.Metrics.BeginObservingOnlineServe(name, variant)
 	defer obs.Finish()
-	logger := serv.Logger.With("Name", name, "Variant", variant)
-	logger.Debug("Getting metadata")
-	meta, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{name, variant})
+	logger := serv.Logger.With("Label", name, "Variant", variant)
+	meta, err := serv.Metadata.GetLabelVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
 	if err != nil {
 		logger.Errorw("metadata lookup failed", "Err", err)
 		obs.SetError()
 		return nil, err
 	}
+	if !meta.ServedOnline() {
+		obs.SetError()
+		return nil, fmt.Errorf("label %s (%s) is not served online", name, variant)
+	}
 	entity, has := entityMap[meta.Entity()]
 	if !has {
 		logger.Errorw("Entity not found", "Entity", meta.Entity())
 		obs.SetError()
 		return nil, fmt.Errorf("No value for entity %s", meta.Entity())
 	}
-	providerEntry, err := meta.FetchProvider(serv.Metadata, ctx)
+	providerEntry, err := serv.Metadata.GetProvider(ctx, meta.OnlineProvider())
 	if err != nil {
 		logger.Errorw("fetching provider metadata failed", "Error", err)
 		obs.SetError()
 		return nil, err
 	}
-	p, err := provider.Get(provider.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	p, err := serv.providers.Get(providerEntry.Name(), provider.Type(providerEntry.Type()), providerEntry.SerializedConfig())
 	if err != nil {
 		logger.Errorw("failed to get provider", "Error", err)
 		obs.SetError()
@@ -144,19 +352,76 @@ func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant st
 	}
 	store, err := p.AsOnlineStore()
 	if err != nil {
-		logger.Errorw("failed to use provider as onlinestore for feature", "Error", err)
+		logger.Errorw("failed to use provider as onlinestore for label", "Error", err)
 		obs.SetError()
-		// This means that the provider of the feature isn't an online store.
-		// That shouldn't be possible.
 		return nil, err
 	}
-	table, err := store.GetTable(name, variant)
+	id := provider.ResourceID{Name: name, Variant: variant, Type: provider.Label}
+	table, err := store.GetTable(provider.MaterializedName(id), variant)
 	if err != nil {
-		logger.Errorw("feature not found", "Error", err)
+		logger.Errorw("label not found", "Error", err)
+		obs.SetError()
+		return nil, err
+	}
+	val, err := provider.GetWithContext(ctx, table, entity)
+	if err != nil {
+		logger.Errorw("entity not found", "Error", err)
+		obs.SetError()
+		return nil, err
+	}
+	f, err := newFeature(val)
+	if err != nil {
+		logger.Errorw("invalid label type", "Error", err)
 		obs.SetError()
 		return nil, err
 	}
-	val, err := table.Get(entity)
+	obs.ServeRow()
+	return f.Serialized(), nil
+}
+
+func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant string, entityMap map[string]string) (*pb.Value, error) {
+	obs := serv.Metrics.BeginObservingOnlineServe(name, variant)
+	defer obs.Finish()
+	logger := serv.Logger.With("Name", name, "Variant", variant)
+	if reqID, ok := provider.RequestID(ctx); ok {
+		logger = logger.With("RequestID", reqID)
+	}
+	logger.Debug("Getting metadata")
+	meta, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{name, variant})
+	if err != nil {
+		logger.Errorw("metadata lookup failed", "Err", err)
+		obs.SetError()
+		return nil, err
+	}
+	entity, has := entityMap[meta.Entity()]
+	if !has {
+		logger.Errorw("Entity not found", "Entity", meta.Entity())
+		obs.SetError()
+		return nil, fmt.Errorf("No value for entity %s", meta.Entity())
+	}
+	if serv.Sandbox && meta.HasMockValue() {
+		logger.Debugw("Serving mock value", "Entity", entity)
+		val, err := parseMockValue(meta.Type(), meta.MockValue())
+		if err != nil {
+			logger.Errorw("invalid mock value", "Error", err)
+			obs.SetError()
+			return nil, err
+		}
+		f, err := newFeature(val)
+		if err != nil {
+			logger.Errorw("invalid feature type", "Error", err)
+			obs.SetError()
+			return nil, err
+		}
+		obs.ServeRow()
+		return f.Serialized(), nil
+	}
+	table, err := serv.getFeatureTable(ctx, meta, logger)
+	if err != nil {
+		obs.SetError()
+		return nil, err
+	}
+	val, err := provider.GetWithContext(ctx, table, entity)
 	if err != nil {
 		logger.Errorw("entity not found", "Error", err)
 		obs.SetError()
@@ -171,3 +436,148 @@ func (serv *FeatureServer) getFeatureValue(ctx context.Context, name, variant st
 	obs.ServeRow()
 	return f.Serialized(), nil
 }
+
+func (serv *FeatureServer) getFeatureTable(ctx context.Context, meta *metadata.FeatureVariant, logger *zap.SugaredLogger) (provider.OnlineStoreTable, error) {
+	providerEntry, err := meta.FetchProvider(serv.Metadata, ctx)
+	if err != nil {
+		logger.Errorw("fetching provider metadata failed", "Error", err)
+		return nil, err
+	}
+	p, err := serv.providers.Get(providerEntry.Name(), provider.Type(providerEntry.Type()), providerEntry.SerializedConfig())
+	if err != nil {
+		logger.Errorw("failed to get provider", "Error", err)
+		return nil, err
+	}
+	store, err := p.AsOnlineStore()
+	if err != nil {
+		logger.Errorw("failed to use provider as onlinestore for feature", "Error", err)
+		// This means that the provider of the feature isn't an online store.
+		// That shouldn't be possible.
+		return nil, err
+	}
+	table, err := store.GetTable(meta.Name(), meta.Variant())
+	if err != nil {
+		logger.Errorw("feature not found", "Error", err)
+		return nil, err
+	}
+	return table, nil
+}
+
+// MultiEntityFeatureServe serves a feature vector for each row of entities.
+// A row that can't be served is marked with an error rather than failing the
+// request, so that a ranking request still scores the rest of its
+// candidates. A feature that can't be served at all does fail it.
+func (serv *FeatureServer) MultiEntityFeatureServe(ctx context.Context, req *pb.MultiEntityFeatureServeRequest) (*pb.MultiEntityFeatureRows, error) {
+	features := req.GetFeatures()
+	entityMaps := make([]map[string]string, len(req.GetRows()))
+	for i, row := range req.GetRows() {
+		entityMaps[i] = make(map[string]string)
+		for _, entity := range row.GetEntities() {
+			entityMaps[i][entity.GetName()] = entity.GetValue()
+		}
+	}
+	reqID := requestID(ctx)
+	ctx = provider.WithRequestID(ctx, reqID)
+	rows := make([]*pb.MultiEntityFeatureRow, len(entityMaps))
+	for i := range rows {
+		rows[i] = &pb.MultiEntityFeatureRow{Values: make([]*pb.Value, len(features))}
+	}
+	for j, feature := range features {
+		name, variant := feature.GetName(), feature.GetVersion()
+		serv.Logger.Infow("Serving feature for entities", "Name", name, "Variant", variant, "Rows", len(rows), "RequestID", reqID)
+		vals, errs, err := serv.getFeatureValues(ctx, name, variant, entityMaps)
+		if err != nil {
+			return nil, err
+		}
+		for i, row := range rows {
+			if errs[i] != nil && row.Error == "" {
+				row.Error = fmt.Sprintf("feature %s (%s): %s", name, variant, errs[i])
+			}
+			row.Values[j] = vals[i]
+		}
+	}
+	for _, row := range rows {
+		if row.Error != "" {
+			row.Values = nil
+		}
+	}
+	return &pb.MultiEntityFeatureRows{
+		Rows: rows,
+	}, nil
+}
+
+// getFeatureValues reads a feature's value for each of entityMaps in a
+// single batch. Errors are per row.
+func (serv *FeatureServer) getFeatureValues(ctx context.Context, name, variant string, entityMaps []map[string]string) ([]*pb.Value, []error, error) {
+	obs := serv.Metrics.BeginObservingOnlineServe(name, variant)
+	defer obs.Finish()
+	logger := serv.Logger.With("Name", name, "Variant", variant)
+	if reqID, ok := provider.RequestID(ctx); ok {
+		logger = logger.With("RequestID", reqID)
+	}
+	meta, err := serv.Metadata.GetFeatureVariant(ctx, metadata.NameVariant{Name: name, Variant: variant})
+	if err != nil {
+		logger.Errorw("metadata lookup failed", "Err", err)
+		obs.SetError()
+		return nil, nil, err
+	}
+	vals := make([]*pb.Value, len(entityMaps))
+	errs := make([]error, len(entityMaps))
+	// Only rows that have the feature's entity are read.
+	var entities []string
+	var positions []int
+	for i, entityMap := range entityMaps {
+		entity, has := entityMap[meta.Entity()]
+		if !has {
+			errs[i] = fmt.Errorf("No value for entity %s", meta.Entity())
+			continue
+		}
+		entities = append(entities, entity)
+		positions = append(positions, i)
+	}
+	if serv.Sandbox && meta.HasMockValue() {
+		logger.Debugw("Serving mock value", "Rows", len(positions))
+		val, err := parseMockValue(meta.Type(), meta.MockValue())
+		if err != nil {
+			logger.Errorw("invalid mock value", "Error", err)
+			obs.SetError()
+			return nil, nil, err
+		}
+		f, err := newFeature(val)
+		if err != nil {
+			logger.Errorw("invalid feature type", "Error", err)
+			obs.SetError()
+			return nil, nil, err
+		}
+		for _, i := range positions {
+			vals[i] = f.Serialized()
+			obs.ServeRow()
+		}
+		return vals, errs, nil
+	}
+	table, err := serv.getFeatureTable(ctx, meta, logger)
+	if err != nil {
+		obs.SetError()
+		return nil, nil, err
+	}
+	read, readErrs, err := provider.BatchGet(ctx, table, entities)
+	if err != nil {
+		logger.Errorw("batch read failed", "Error", err)
+		obs.SetError()
+		return nil, nil, err
+	}
+	for k, i := range positions {
+		if readErrs[k] != nil {
+			errs[i] = readErrs[k]
+			continue
+		}
+		f, err := newFeature(read[k])
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		vals[i] = f.Serialized()
+		obs.ServeRow()
+	}
+	return vals, errs, nil
+}
@@ -6,16 +6,28 @@ package newserving
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net"
+	"net/url"
+	"os"
 	"reflect"
+	"sort"
 	"testing"
+	"time"
 
+	jwt "github.com/form3tech-oss/jwt-go"
 	"github.com/google/uuid"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	grpcmeta "google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/featureform/metadata"
 	"github.com/featureform/metrics"
@@ -345,6 +357,17 @@ func simpleResourceDefsFn(providerType string) []metadata.ResourceDef {
 	}
 }
 
+func onlineLabelResourceDefsFn(providerType string) []metadata.ResourceDef {
+	defs := simpleResourceDefsFn(providerType)
+	for i, def := range defs {
+		if label, ok := def.(metadata.LabelDef); ok {
+			label.OnlineProvider = "mockOnline"
+			defs[i] = label
+		}
+	}
+	return defs
+}
+
 func simpleTrainingSetDefs() []provider.TrainingSetDef {
 	return []provider.TrainingSetDef{
 		{
@@ -416,10 +439,10 @@ func createMockOnlineStoreFactory(recsMap map[provider.ResourceID][]provider.Res
 	return func(cfg provider.SerializedConfig) (provider.Provider, error) {
 		store := provider.NewLocalOnlineStore()
 		for id, recs := range recsMap {
-			if id.Type != provider.Feature {
+			if id.Type != provider.Feature && id.Type != provider.Label {
 				continue
 			}
-			table, err := store.CreateTable(id.Name, id.Variant, provider.String)
+			table, err := store.CreateTable(provider.MaterializedName(id), id.Variant, provider.String)
 			if err != nil {
 				panic(err)
 			}
@@ -552,6 +575,130 @@ func TestFeatureServe(t *testing.T) {
 	}
 }
 
+func TestMultiEntityFeatureServe(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	row := func(entities ...*pb.Entity) *pb.EntityRow {
+		return &pb.EntityRow{Entities: entities}
+	}
+	req := &pb.MultiEntityFeatureServeRequest{
+		Features: []*pb.FeatureID{
+			{Name: "feature", Version: "variant"},
+		},
+		Rows: []*pb.EntityRow{
+			row(&pb.Entity{Name: "mockEntity", Value: "b"}),
+			row(&pb.Entity{Name: "mockEntity", Value: "NonExistantEntity"}),
+			row(&pb.Entity{Name: "otherEntity", Value: "a"}),
+			row(&pb.Entity{Name: "mockEntity", Value: "a"}),
+		},
+	}
+	resp, err := serv.MultiEntityFeatureServe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to serve features: %s", err)
+	}
+	if len(resp.Rows) != len(req.Rows) {
+		t.Fatalf("Wrong number of rows: %d\nExpected: %d", len(resp.Rows), len(req.Rows))
+	}
+	expected := map[int]interface{}{0: "def", 3: 12.5}
+	for i, val := range expected {
+		if resp.Rows[i].Error != "" {
+			t.Fatalf("Row %d failed: %s", i, resp.Rows[i].Error)
+		}
+		if len(resp.Rows[i].Values) != 1 || unwrapVal(resp.Rows[i].Values[0]) != val {
+			t.Fatalf("Wrong values for row %d: %v\nExpected: %v", i, resp.Rows[i].Values, val)
+		}
+	}
+	for _, i := range []int{1, 2} {
+		if resp.Rows[i].Error == "" || len(resp.Rows[i].Values) != 0 {
+			t.Fatalf("Row %d without a value not marked with an error: %v", i, resp.Rows[i])
+		}
+	}
+}
+
+func TestMultiEntityFeatureNotFound(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.MultiEntityFeatureServeRequest{
+		Features: []*pb.FeatureID{
+			{Name: "nonexistantFeature", Version: "variant"},
+		},
+		Rows: []*pb.EntityRow{
+			{Entities: []*pb.Entity{{Name: "mockEntity", Value: "a"}}},
+		},
+	}
+	if _, err := serv.MultiEntityFeatureServe(context.Background(), req); err == nil {
+		t.Fatalf("Succeeded in serving non-existant feature")
+	}
+}
+
+func TestLabelServe(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: onlineLabelResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.LabelServeRequest{
+		Labels: []*pb.LabelID{
+			&pb.LabelID{
+				Name:    "label",
+				Version: "variant",
+			},
+		},
+		Entities: []*pb.Entity{
+			&pb.Entity{
+				Name:  "mockEntity",
+				Value: "a",
+			},
+		},
+	}
+	resp, err := serv.LabelServe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to serve label: %s", err)
+	}
+	vals := resp.Values
+	if len(vals) != len(req.Labels) {
+		t.Fatalf("Wrong number of values: %d\nExpcted: %d", len(vals), len(req.Labels))
+	}
+	if val := unwrapVal(vals[0]); val != true {
+		t.Fatalf("Wrong label value: %v\nExpcted: %v", val, true)
+	}
+}
+
+func TestLabelNotServedOnline(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.LabelServeRequest{
+		Labels: []*pb.LabelID{
+			&pb.LabelID{
+				Name:    "label",
+				Version: "variant",
+			},
+		},
+		Entities: []*pb.Entity{
+			&pb.Entity{
+				Name:  "mockEntity",
+				Value: "a",
+			},
+		},
+	}
+	if _, err := serv.LabelServe(context.Background(), req); err == nil {
+		t.Fatalf("Served label that isn't materialized online")
+	}
+}
+
 func TestFeatureNotFound(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: simpleResourceDefsFn,
@@ -897,6 +1044,220 @@ func TestSimpleTrainingSetServe(t *testing.T) {
 	}
 }
 
+func TestTrainingSetServeSchema(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(simpleFeatureRecords(), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.TrainingDataRequest{
+		Id: &pb.TrainingDataID{
+			Name:    "training-set",
+			Version: "variant",
+		},
+		IncludeSchema: true,
+	}
+	stream := newMockTrainingStream()
+	errChan := make(chan error)
+	go func() {
+		if err := serv.TrainingData(req, stream); err != nil {
+			errChan <- err
+		}
+		close(errChan)
+	}()
+	var rows []*pb.TrainingDataRow
+	moreVals := true
+	for moreVals {
+		select {
+		case row := <-stream.RowChan:
+			rows = append(rows, row)
+		case err := <-errChan:
+			if err != nil {
+				t.Fatalf("Failed to get training data: %s", err)
+			}
+			moreVals = false
+		}
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected a schema and 2 rows, got %d messages", len(rows))
+	}
+	schema := rows[0].GetSchema()
+	if schema == nil || len(rows[0].Features) != 0 {
+		t.Fatalf("First message is not a schema: %v", rows[0])
+	}
+	if len(schema.Features) != 1 || schema.Features[0].Name != "feature" || schema.Features[0].Version != "variant" {
+		t.Fatalf("Wrong features in schema: %v", schema.Features)
+	}
+	if schema.Label.GetName() != "label" || schema.Label.GetVersion() != "variant" {
+		t.Fatalf("Wrong label in schema: %v", schema.Label)
+	}
+	for _, row := range rows[1:] {
+		if row.GetSchema() != nil || len(row.Features) != 1 {
+			t.Fatalf("Row doesn't match the schema: %v", row)
+		}
+	}
+}
+
+func TestTrainingSetServeSelectedFeatures(t *testing.T) {
+	wideResourceDefsFn := func(providerType string) []metadata.ResourceDef {
+		defs := simpleResourceDefsFn(providerType)
+		return append(defs,
+			metadata.FeatureDef{
+				Name:     "feature2",
+				Variant:  "variant",
+				Provider: "mockOnline",
+				Entity:   "mockEntity",
+				Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
+				Owner:    "Featureform",
+				Location: metadata.ResourceVariantColumns{
+					Entity: "col1",
+					Value:  "col4",
+					TS:     "col3",
+				},
+			},
+			metadata.TrainingSetDef{
+				Name:     "wide-training-set",
+				Variant:  "variant",
+				Provider: "mockOnline",
+				Label:    metadata.NameVariant{Name: "label", Variant: "variant"},
+				Features: metadata.NameVariants{{Name: "feature", Variant: "variant"}, {Name: "feature2", Variant: "variant"}},
+				Owner:    "Featureform",
+			},
+		)
+	}
+	recs := simpleFeatureRecords()
+	recs[provider.ResourceID{Name: "feature2", Variant: "variant", Type: provider.Feature}] = []provider.ResourceRecord{
+		{Entity: "a", Value: int64(1)},
+		{Entity: "b", Value: int64(2)},
+	}
+	tsDefs := []provider.TrainingSetDef{
+		{
+			ID:       provider.ResourceID{Name: "wide-training-set", Variant: "variant"},
+			Label:    provider.ResourceID{Name: "label", Variant: "variant"},
+			Features: []provider.ResourceID{{Name: "feature", Variant: "variant"}, {Name: "feature2", Variant: "variant"}},
+		},
+	}
+	ctx := onlineTestContext{
+		ResourceDefsFn: wideResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(recs, tsDefs),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	serve := func(features []*pb.FeatureID) ([]*pb.TrainingDataRow, error) {
+		req := &pb.TrainingDataRequest{
+			Id:            &pb.TrainingDataID{Name: "wide-training-set", Version: "variant"},
+			IncludeSchema: true,
+			Features:      features,
+		}
+		stream := newMockTrainingStream()
+		errChan := make(chan error)
+		go func() {
+			if err := serv.TrainingData(req, stream); err != nil {
+				errChan <- err
+			}
+			close(errChan)
+		}()
+		var rows []*pb.TrainingDataRow
+		for {
+			select {
+			case row := <-stream.RowChan:
+				rows = append(rows, row)
+			case err := <-errChan:
+				return rows, err
+			}
+		}
+	}
+	rows, err := serve([]*pb.FeatureID{{Name: "feature2", Version: "variant"}})
+	if err != nil {
+		t.Fatalf("Failed to get training data: %s", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected a schema and 2 rows, got %d messages", len(rows))
+	}
+	schema := rows[0].GetSchema()
+	if len(schema.GetFeatures()) != 1 || schema.Features[0].Name != "feature2" {
+		t.Fatalf("Schema doesn't only hold the selected feature: %v", schema)
+	}
+	expectedRows := map[interface{}]interface{}{int64(1): true, int64(2): false}
+	for _, row := range rows[1:] {
+		if len(row.Features) != 1 {
+			t.Fatalf("Row doesn't only hold the selected feature: %v", row)
+		}
+		feature, label := unwrapVal(row.Features[0]), unwrapVal(row.Label)
+		if expected, has := expectedRows[feature]; !has || expected != label {
+			t.Fatalf("Unexpected row %v, %v", feature, label)
+		}
+	}
+	if _, err := serve([]*pb.FeatureID{{Name: "missing", Version: "variant"}}); err == nil {
+		t.Fatalf("Succeeded in selecting a feature that isn't in the training set")
+	}
+}
+
+func TestSpoolTrainingData(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOfflineStoreFactory(simpleFeatureRecords(), simpleTrainingSetDefs()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.TrainingDataRequest{
+		Id: &pb.TrainingDataID{Name: "training-set", Version: "variant"},
+	}
+	if _, err := serv.SpoolTrainingData(context.Background(), req); err == nil {
+		t.Fatalf("Spooled training data without a spooler")
+	}
+	spooler, err := NewSpooler("file://" + t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create spooler: %s", err)
+	}
+	spooler.RowsPerFile = 1
+	serv.Spooler = spooler
+	manifest, err := serv.SpoolTrainingData(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to spool training data: %s", err)
+	}
+	if manifest.NumRows != 2 || len(manifest.Files) != 2 {
+		t.Fatalf("Expected 2 rows in 2 files, got %d rows in %d files", manifest.NumRows, len(manifest.Files))
+	}
+	if len(manifest.GetSchema().GetFeatures()) != 1 || manifest.Schema.Features[0].Name != "feature" {
+		t.Fatalf("Wrong schema in manifest: %v", manifest.Schema)
+	}
+	spooled := make([]string, 0, len(manifest.Files))
+	for _, file := range manifest.Files {
+		u, err := url.Parse(file.Url)
+		if err != nil {
+			t.Fatalf("Invalid file url %s: %s", file.Url, err)
+		}
+		data, err := os.ReadFile(u.Path)
+		if err != nil {
+			t.Fatalf("Failed to read spooled file: %s", err)
+		}
+		if int64(len(data)) != file.SizeBytes || file.NumRows != 1 {
+			t.Fatalf("File %s doesn't match the manifest: %d bytes, %d rows", file.Url, len(data), file.NumRows)
+		}
+		pr, err := reader.NewParquetReader(buffer.NewBufferFileFromBytes(data), nil, 1)
+		if err != nil {
+			t.Fatalf("Spooled file %s is not a Parquet file: %s", file.Url, err)
+		}
+		rows, err := pr.ReadByNumber(int(pr.GetNumRows()))
+		pr.ReadStop()
+		if err != nil || len(rows) != 1 {
+			t.Fatalf("Failed to read back spooled file %s: %d rows, %v", file.Url, len(rows), err)
+		}
+		serialized, err := json.Marshal(rows[0])
+		if err != nil {
+			t.Fatalf("Failed to serialize spooled row: %s", err)
+		}
+		spooled = append(spooled, string(serialized))
+	}
+	sort.Strings(spooled)
+	expected := []string{`{"Feature__variant":"12.5","Label":"true"}`, `{"Feature__variant":"def","Label":"false"}`}
+	if !reflect.DeepEqual(spooled, expected) {
+		t.Fatalf("Expected to read back %v, got %v", expected, spooled)
+	}
+}
+
 func TestTrainingSetNotFound(t *testing.T) {
 	ctx := onlineTestContext{
 		ResourceDefsFn: simpleResourceDefsFn,
@@ -1054,3 +1415,407 @@ func TestTrainingSetInvalidFeature(t *testing.T) {
 		t.Fatalf("Succeeded in serving invalid feature: %s", err)
 	}
 }
+
+func TestRequestID(t *testing.T) {
+	ctx := grpcmeta.NewIncomingContext(context.Background(), grpcmeta.Pairs(requestIDHeader, "abc-123"))
+	if id := requestID(ctx); id != "abc-123" {
+		t.Fatalf("Request ID not read from header: %s", id)
+	}
+	first, second := requestID(context.Background()), requestID(context.Background())
+	if first == "" || first == second {
+		t.Fatalf("Request IDs not generated: %s %s", first, second)
+	}
+}
+
+func mockValueResourceDefsFn(providerType string) []metadata.ResourceDef {
+	defs := simpleResourceDefsFn(providerType)
+	for i, def := range defs {
+		if feature, ok := def.(metadata.FeatureDef); ok {
+			feature.Type = "float64"
+			feature.MockValue = "42.5"
+			defs[i] = feature
+		}
+	}
+	return defs
+}
+
+func TestSandboxFeatureServe(t *testing.T) {
+	// No provider factory is registered, so the value can only come from the mock.
+	ctx := onlineTestContext{
+		ResourceDefsFn: mockValueResourceDefsFn,
+		FactoryFn:      nil,
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.FeatureServeRequest{
+		Features: []*pb.FeatureID{
+			&pb.FeatureID{
+				Name:    "feature",
+				Version: "variant",
+			},
+		},
+		Entities: []*pb.Entity{
+			&pb.Entity{
+				Name:  "mockEntity",
+				Value: "a",
+			},
+		},
+	}
+	if _, err := serv.FeatureServe(context.Background(), req); err == nil {
+		t.Fatalf("Served mock value outside of sandbox mode")
+	}
+	serv.Sandbox = true
+	resp, err := serv.FeatureServe(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to serve mock feature: %s", err)
+	}
+	if val := unwrapVal(resp.Values[0]); val != 42.5 {
+		t.Fatalf("Wrong mock value: %v\nExpected: %v", val, 42.5)
+	}
+}
+
+func TestParseMockValue(t *testing.T) {
+	valid := map[string]interface{}{
+		"int":     5,
+		"int32":   int32(5),
+		"int64":   int64(5),
+		"float32": float32(5),
+		"float64": 5.0,
+		"string":  "5",
+		"":        "5",
+	}
+	for valueType, expected := range valid {
+		val, err := parseMockValue(valueType, "5")
+		if err != nil {
+			t.Fatalf("Failed to parse %s mock value: %s", valueType, err)
+		}
+		if !reflect.DeepEqual(val, expected) {
+			t.Fatalf("Wrong %s mock value: %#v\nExpected: %#v", valueType, val, expected)
+		}
+	}
+	if val, err := parseMockValue("bool", "true"); err != nil || val != true {
+		t.Fatalf("Failed to parse bool mock value: %v %s", val, err)
+	}
+	if _, err := parseMockValue("int", "abc"); err == nil {
+		t.Fatalf("Succeeded in parsing invalid int mock value")
+	}
+	if _, err := parseMockValue("time.Time", "abc"); err == nil {
+		t.Fatalf("Succeeded in parsing unsupported mock value type")
+	}
+}
+
+func TestCanaryProbe(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: mockValueResourceDefsFn,
+		FactoryFn:      nil,
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	probe := serv.CanaryProbe("feature", "variant", "mockEntity", "a")
+	if probe.Target != "feature.variant" {
+		t.Fatalf("Wrong probe target: %s", probe.Target)
+	}
+	if err := probe.Run(context.Background()); err == nil {
+		t.Fatalf("Canary succeeded without an online store")
+	}
+	serv.Sandbox = true
+	if err := probe.Run(context.Background()); err != nil {
+		t.Fatalf("Canary failed: %s", err)
+	}
+}
+
+func scopedResourceDefsFn(providerType string) []metadata.ResourceDef {
+	return append(simpleResourceDefsFn(providerType), metadata.FeatureDef{
+		Name:     "credit_score",
+		Variant:  "variant",
+		Provider: "mockOnline",
+		Entity:   "mockEntity",
+		Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
+		Owner:    "Featureform",
+		Location: metadata.ResourceVariantColumns{
+			Entity: "col1",
+			Value:  "col2",
+			TS:     "col3",
+		},
+		Scopes: []string{"pii", "credit"},
+	}, metadata.LabelDef{
+		Name:     "defaulted",
+		Variant:  "variant",
+		Provider: "mockOnline",
+		Entity:   "mockEntity",
+		Source:   metadata.NameVariant{Name: "mockSource", Variant: "var"},
+		Owner:    "Featureform",
+		Location: metadata.ResourceVariantColumns{
+			Entity: "col1",
+			Value:  "col2",
+			TS:     "col3",
+		},
+		Scopes: []string{"credit"},
+	}, metadata.TrainingSetDef{
+		Name:     "defaults",
+		Variant:  "variant",
+		Provider: "mockOnline",
+		Label:    metadata.NameVariant{Name: "defaulted", Variant: "variant"},
+		Features: metadata.NameVariants{{Name: "feature", Variant: "variant"}},
+		Owner:    "Featureform",
+	})
+}
+
+func scopedFeatureRecords() map[provider.ResourceID][]provider.ResourceRecord {
+	recs := simpleFeatureRecords()
+	recs[provider.ResourceID{Name: "credit_score", Variant: "variant", Type: provider.Feature}] = []provider.ResourceRecord{
+		{Entity: "a", Value: 700.0},
+	}
+	return recs
+}
+
+func serveWithAuth(serv *FeatureServer, auth Authenticator, md grpcmeta.MD, features ...string) error {
+	req := &pb.FeatureServeRequest{
+		Entities: []*pb.Entity{{Name: "mockEntity", Value: "a"}},
+	}
+	for _, feature := range features {
+		req.Features = append(req.Features, &pb.FeatureID{Name: feature, Version: "variant"})
+	}
+	ctx := grpcmeta.NewIncomingContext(context.Background(), md)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return serv.FeatureServe(ctx, req.(*pb.FeatureServeRequest))
+	}
+	_, err := serv.UnaryAuthInterceptor(auth)(ctx, req, &grpc.UnaryServerInfo{}, handler)
+	return err
+}
+
+func TestFeatureScopes(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: scopedResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(scopedFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	auth := Authenticators{APIKeys{
+		"analyst": {Subject: "analyst", Scopes: []string{"pii"}},
+		"risk":    {Subject: "risk", Scopes: []string{"pii", "credit"}},
+	}}
+	if err := serveWithAuth(serv, auth, grpcmeta.MD{}, "feature"); err != nil {
+		t.Fatalf("Failed to serve open feature anonymously: %s", err)
+	}
+	if err := serveWithAuth(serv, auth, grpcmeta.Pairs("x-api-key", "risk"), "feature", "credit_score"); err != nil {
+		t.Fatalf("Failed to serve scoped feature with its scopes: %s", err)
+	}
+	err := serveWithAuth(serv, auth, grpcmeta.Pairs("x-api-key", "analyst"), "feature", "credit_score")
+	st, _ := status.FromError(err)
+	if st.Code() != codes.PermissionDenied {
+		t.Fatalf("Expected permission denied, got %v", err)
+	}
+	if len(st.Details()) != 1 {
+		t.Fatalf("Expected details for one feature, got %v", st.Details())
+	}
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	if !ok || info.Reason != MissingScopesReason || info.Metadata["feature"] != "credit_score" || info.Metadata["scopes"] != "credit" {
+		t.Fatalf("Unexpected denial details: %v", st.Details()[0])
+	}
+	err = serveWithAuth(serv, auth, grpcmeta.Pairs("x-api-key", "unknown"), "feature")
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Expected unknown key to be unauthenticated, got %v", err)
+	}
+}
+
+// requestStream is a server stream that receives a single request.
+type requestStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req *pb.TrainingDataRequest
+}
+
+func (stream *requestStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *requestStream) RecvMsg(m interface{}) error {
+	m.(*pb.TrainingDataRequest).Id = stream.req.GetId()
+	m.(*pb.TrainingDataRequest).Features = stream.req.GetFeatures()
+	return nil
+}
+
+func TestScopesOfEveryServingRequest(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: scopedResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(scopedFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	auth := Authenticators{APIKeys{
+		"analyst": {Subject: "analyst", Scopes: []string{"pii"}},
+		"risk":    {Subject: "risk", Scopes: []string{"pii", "credit"}},
+	}}
+	unary := func(key string, req interface{}) error {
+		ctx := grpcmeta.NewIncomingContext(context.Background(), grpcmeta.Pairs("x-api-key", key))
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		}
+		_, err := serv.UnaryAuthInterceptor(auth)(ctx, req, &grpc.UnaryServerInfo{}, handler)
+		return err
+	}
+	stream := func(key string, req *pb.TrainingDataRequest) error {
+		ctx := grpcmeta.NewIncomingContext(context.Background(), grpcmeta.Pairs("x-api-key", key))
+		handler := func(srv interface{}, stream grpc.ServerStream) error {
+			return stream.RecvMsg(&pb.TrainingDataRequest{})
+		}
+		return serv.StreamAuthInterceptor(auth)(nil, &requestStream{ctx: ctx, req: req}, &grpc.StreamServerInfo{}, handler)
+	}
+	labels := &pb.LabelServeRequest{Labels: []*pb.LabelID{{Name: "defaulted", Version: "variant"}}}
+	if err := unary("risk", labels); err != nil {
+		t.Fatalf("Failed to serve scoped label with its scopes: %s", err)
+	}
+	err := unary("analyst", labels)
+	st, _ := status.FromError(err)
+	if st.Code() != codes.PermissionDenied || len(st.Details()) != 1 {
+		t.Fatalf("Expected label to be denied with details, got %v", err)
+	}
+	if info, ok := st.Details()[0].(*errdetails.ErrorInfo); !ok || info.Metadata["label"] != "defaulted" || info.Metadata["scopes"] != "credit" {
+		t.Fatalf("Unexpected denial details: %v", st.Details()[0])
+	}
+	training := &pb.TrainingDataRequest{Id: &pb.TrainingDataID{Name: "defaults", Version: "variant"}}
+	if err := unary("analyst", training); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Expected spooling a training set with a scoped label to be denied, got %v", err)
+	}
+	if err := stream("analyst", training); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Expected streaming a training set with a scoped label to be denied, got %v", err)
+	}
+	if err := stream("risk", training); err != nil {
+		t.Fatalf("Failed to stream training set with its scopes: %s", err)
+	}
+	open := &pb.TrainingDataRequest{Id: &pb.TrainingDataID{Name: "training-set", Version: "variant"}}
+	if err := stream("analyst", open); err != nil {
+		t.Fatalf("Failed to stream training set without scopes: %s", err)
+	}
+	missing := &pb.FeatureServeRequest{Features: []*pb.FeatureID{{Name: "missing", Version: "variant"}}}
+	if err := unary("risk", missing); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Expected feature that can't be looked up to be denied, got %v", err)
+	}
+	if err := lookupError("feature", metadata.NameVariant{Name: "f", Variant: "v"}, status.Error(codes.Unavailable, "down")); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Expected failed lookup to be unavailable, got %v", err)
+	}
+}
+
+func TestJWTScopes(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: scopedResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(scopedFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	secret := []byte("secret")
+	auth := JWTAuthenticator{Key: secret, Issuer: "featureform"}
+	sign := func(claims jwt.MapClaims) grpcmeta.MD {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %s", err)
+		}
+		return grpcmeta.Pairs("authorization", "Bearer "+token)
+	}
+	valid := sign(jwt.MapClaims{"iss": "featureform", "sub": "risk", "scope": "pii credit"})
+	if err := serveWithAuth(serv, auth, valid, "credit_score"); err != nil {
+		t.Fatalf("Failed to serve with token scopes: %s", err)
+	}
+	listed := sign(jwt.MapClaims{"iss": "featureform", "scopes": []string{"pii"}})
+	if err := serveWithAuth(serv, auth, listed, "credit_score"); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Expected token without credit scope to be denied, got %v", err)
+	}
+	expired := sign(jwt.MapClaims{"iss": "featureform", "scope": "pii credit", "exp": time.Now().Add(-time.Minute).Unix()})
+	if err := serveWithAuth(serv, auth, expired, "credit_score"); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Expected expired token to be unauthenticated, got %v", err)
+	}
+	wrongIssuer := sign(jwt.MapClaims{"iss": "other", "scope": "pii credit"})
+	if err := serveWithAuth(serv, auth, wrongIssuer, "credit_score"); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("Expected token from another issuer to be unauthenticated, got %v", err)
+	}
+}
+
+func TestRequestValidation(t *testing.T) {
+	limits := RequestLimits{MaxFeatures: 2, MaxEntities: 2, MaxEntityValueLength: 4}
+	entity := func(value string) []*pb.Entity {
+		return []*pb.Entity{{Name: "user", Value: value}}
+	}
+	features := func(names ...string) []*pb.FeatureID {
+		ids := make([]*pb.FeatureID, len(names))
+		for i, name := range names {
+			ids[i] = &pb.FeatureID{Name: name, Version: "v"}
+		}
+		return ids
+	}
+	valid := []interface{}{
+		&pb.FeatureServeRequest{Features: features("a", "b"), Entities: entity("abcd")},
+		&pb.MultiEntityFeatureServeRequest{Features: features("a"), Rows: []*pb.EntityRow{{Entities: entity("a")}}},
+		&pb.TrainingDataRequest{Id: &pb.TrainingDataID{Name: "ts", Version: "v"}},
+		&pb.LabelServeRequest{},
+	}
+	for _, req := range valid {
+		if err := limits.Validate(req); err != nil {
+			t.Fatalf("Expected %T to be valid: %s", req, err)
+		}
+	}
+	invalid := map[string]interface{}{
+		"features":          &pb.FeatureServeRequest{Entities: entity("a")},
+		"entities":          &pb.FeatureServeRequest{Features: features("a")},
+		"entities[0].value": &pb.FeatureServeRequest{Features: features("a"), Entities: entity("abcde")},
+		"rows":              &pb.MultiEntityFeatureServeRequest{Features: features("a"), Rows: make([]*pb.EntityRow, 3)},
+		"id.name":           &pb.TrainingDataRequest{},
+	}
+	for field, req := range invalid {
+		st, _ := status.FromError(limits.Validate(req))
+		if st.Code() != codes.InvalidArgument {
+			t.Fatalf("Expected invalid argument for %s, got %v", field, st.Err())
+		}
+		if len(st.Details()) != 1 {
+			t.Fatalf("Expected bad request details for %s, got %v", field, st.Details())
+		}
+		badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+		if !ok || len(badRequest.FieldViolations) != 1 || badRequest.FieldViolations[0].Field != field {
+			t.Fatalf("Unexpected violations for %s: %v", field, st.Details()[0])
+		}
+	}
+	tooMany := &pb.FeatureServeRequest{Features: features("a", "b", "c"), Entities: entity("a")}
+	if err := limits.Validate(tooMany); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Expected too many features to be invalid, got %v", err)
+	}
+	if err := (RequestLimits{}).Validate(tooMany); err != nil {
+		t.Fatalf("Expected zero limits not to be enforced: %s", err)
+	}
+}
+
+func TestValueLineage(t *testing.T) {
+	ctx := onlineTestContext{
+		ResourceDefsFn: simpleResourceDefsFn,
+		FactoryFn:      createMockOnlineStoreFactory(simpleFeatureRecords()),
+	}
+	serv := ctx.Create(t)
+	defer ctx.Destroy()
+	req := &pb.ValueLineageRequest{
+		Feature: &pb.FeatureID{Name: "feature", Version: "variant"},
+		Entity:  &pb.Entity{Name: "mockEntity", Value: "a"},
+	}
+	report, err := serv.ValueLineage(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Failed to report value lineage: %s", err)
+	}
+	if len(report.Warnings) != 0 {
+		t.Fatalf("Unexpected warnings: %v", report.Warnings)
+	}
+	if val := unwrapVal(report.Value); val != 12.5 {
+		t.Fatalf("Wrong feature value: %v", val)
+	}
+	if report.Served == nil || report.Materialization != nil {
+		t.Fatalf("Expected served time and no materialization runs: %v", report)
+	}
+	if report.Source.GetName() != "mockSource" || report.Source.GetVersion() != "var" {
+		t.Fatalf("Wrong source lineage: %v", report.Source)
+	}
+	req.Entity.Value = "missing"
+	report, err = serv.ValueLineage(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Expected value that can't be served to be reported: %s", err)
+	}
+	if report.Value != nil || len(report.Warnings) != 1 {
+		t.Fatalf("Expected a warning about the value instead of one: %v", report)
+	}
+}
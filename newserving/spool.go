@@ -0,0 +1,235 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package newserving
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	pb "github.com/featureform/proto"
+	"github.com/featureform/provider"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	DefaultSpoolRowsPerFile = 1000000
+	DefaultSpoolURLExpiry   = time.Hour
+)
+
+// Spooler writes training data to object storage as Parquet files that
+// training jobs download directly.
+type Spooler struct {
+	// Archiver must also be a provider.URLSigner.
+	Archiver    provider.Archiver
+	RowsPerFile int
+	URLExpiry   time.Duration
+}
+
+// NewSpooler returns a spooler that writes to an archive URI such as
+// s3://bucket/prefix.
+func NewSpooler(uri string) (*Spooler, error) {
+	archiver, err := provider.NewArchiver(uri)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := archiver.(provider.URLSigner); !ok {
+		return nil, fmt.Errorf("cannot sign urls for objects in %s", uri)
+	}
+	return &Spooler{
+		Archiver:    archiver,
+		RowsPerFile: DefaultSpoolRowsPerFile,
+		URLExpiry:   DefaultSpoolURLExpiry,
+	}, nil
+}
+
+func (serv *FeatureServer) SpoolTrainingData(ctx context.Context, req *pb.TrainingDataRequest) (*pb.TrainingDataManifest, error) {
+	id := req.GetId()
+	name, variant := id.GetName(), id.GetVersion()
+	featureObserver := serv.Metrics.BeginObservingTrainingServe(name, variant)
+	defer featureObserver.Finish()
+	reqID := requestID(ctx)
+	logger := serv.Logger.With("Name", name, "Variant", variant, "RequestID", reqID)
+	if serv.Spooler == nil {
+		featureObserver.SetError()
+		return nil, status.Error(codes.Unimplemented, "training data spooling is not enabled")
+	}
+	logger.Info("Spooling training data")
+	selection, err := serv.selectFeatures(ctx, name, variant, req.GetFeatures())
+	if err != nil {
+		logger.Errorw("Invalid feature selection", "Error", err)
+		featureObserver.SetError()
+		return nil, err
+	}
+	schema, err := serv.trainingDataSchema(ctx, name, variant)
+	if err != nil {
+		logger.Errorw("Failed to get training set schema", "Error", err)
+		featureObserver.SetError()
+		return nil, err
+	}
+	selection.filterSchema(schema)
+	iter, err := serv.getTrainingSetIterator(name, variant, selection)
+	if err != nil {
+		logger.Errorw("Failed to get training set iterator", "Error", err)
+		featureObserver.SetError()
+		return nil, err
+	}
+	spool := &trainingDataSpool{
+		spooler: serv.Spooler,
+		prefix:  path.Join("training_data", name, variant, reqID),
+		columns: spoolColumns(schema),
+		manifest: &pb.TrainingDataManifest{
+			Schema:  schema,
+			Expires: timestamppb.New(time.Now().Add(serv.Spooler.URLExpiry)),
+		},
+	}
+	defer spool.abort()
+	for iter.Next() {
+		features := iter.Features()
+		if len(features) != len(schema.Features) {
+			err := fmt.Errorf("training set row has %d features, schema has %d", len(features), len(schema.Features))
+			logger.Errorw("Training set doesn't match its schema", "Error", err)
+			featureObserver.SetError()
+			return nil, err
+		}
+		row := make([]interface{}, 0, len(features)+1)
+		if err := spool.add(ctx, append(append(row, features...), iter.Label())); err != nil {
+			logger.Errorw("Failed to spool training data", "Error", err)
+			featureObserver.SetError()
+			return nil, err
+		}
+		featureObserver.ServeRow()
+	}
+	if err := iter.Err(); err != nil {
+		logger.Errorw("Dataset error", "Error", err)
+		featureObserver.SetError()
+		return nil, err
+	}
+	if err := spool.finish(ctx); err != nil {
+		logger.Errorw("Failed to spool training data", "Error", err)
+		featureObserver.SetError()
+		return nil, err
+	}
+	logger.Infow("Spooled training data", "Files", len(spool.manifest.Files), "Rows", spool.manifest.NumRows)
+	return spool.manifest, nil
+}
+
+// spoolColumns names each feature column <name>__<variant>, which can't clash
+// since names and variants can't hold a double underscore.
+func spoolColumns(schema *pb.TrainingDataSchema) []provider.ParquetColumn {
+	columns := make([]provider.ParquetColumn, len(schema.Features)+1)
+	for i, feature := range schema.Features {
+		columns[i] = provider.ParquetColumn{
+			Name: fmt.Sprintf("%s__%s", feature.Name, feature.Version),
+			Type: provider.ValueType(feature.Type),
+		}
+	}
+	columns[len(schema.Features)] = provider.ParquetColumn{
+		Name: "label",
+		Type: provider.ValueType(schema.Label.Type),
+	}
+	return columns
+}
+
+// trainingDataSpool writes rows to a Parquet file on disk as they're added,
+// a row group at a time, and archives the file once it holds a file's worth
+// of rows.
+type trainingDataSpool struct {
+	spooler  *Spooler
+	prefix   string
+	columns  []provider.ParquetColumn
+	manifest *pb.TrainingDataManifest
+	file     *os.File
+	buffered *bufio.Writer
+	writer   *provider.ParquetWriter
+	rows     int64
+}
+
+func (spool *trainingDataSpool) add(ctx context.Context, row []interface{}) error {
+	if spool.file == nil {
+		if err := spool.open(); err != nil {
+			return err
+		}
+	}
+	if err := spool.writer.Write(row); err != nil {
+		return err
+	}
+	if spool.rows++; spool.rows < int64(spool.spooler.RowsPerFile) {
+		return nil
+	}
+	return spool.archive(ctx)
+}
+
+func (spool *trainingDataSpool) open() error {
+	file, err := os.CreateTemp("", "featureform-spool-*.parquet")
+	if err != nil {
+		return fmt.Errorf("create spool file: %w", err)
+	}
+	spool.file = file
+	spool.buffered = bufio.NewWriter(file)
+	if spool.writer, err = provider.NewParquetWriter(spool.buffered, spool.columns, 0); err != nil {
+		return err
+	}
+	spool.rows = 0
+	return nil
+}
+
+// finish archives the last file, if it has any rows.
+func (spool *trainingDataSpool) finish(ctx context.Context) error {
+	if spool.file == nil {
+		return nil
+	}
+	return spool.archive(ctx)
+}
+
+// archive finishes the current file, writes it to the archiver and adds it
+// to the manifest.
+func (spool *trainingDataSpool) archive(ctx context.Context) error {
+	defer spool.abort()
+	if err := spool.writer.Close(); err != nil {
+		return fmt.Errorf("finish parquet file: %w", err)
+	}
+	if err := spool.buffered.Flush(); err != nil {
+		return fmt.Errorf("write spool file: %w", err)
+	}
+	info, err := spool.file.Stat()
+	if err != nil {
+		return fmt.Errorf("stat spool file: %w", err)
+	}
+	key := path.Join(spool.prefix, fmt.Sprintf("part-%05d.parquet", len(spool.manifest.Files)))
+	if err := provider.ArchiveFile(ctx, spool.spooler.Archiver, key, spool.file); err != nil {
+		return fmt.Errorf("write %s: %w", key, err)
+	}
+	signer, ok := spool.spooler.Archiver.(provider.URLSigner)
+	if !ok {
+		return fmt.Errorf("cannot sign a url for %s", key)
+	}
+	url, err := signer.SignURL(ctx, key, spool.spooler.URLExpiry)
+	if err != nil {
+		return fmt.Errorf("sign url for %s: %w", key, err)
+	}
+	spool.manifest.Files = append(spool.manifest.Files, &pb.TrainingDataFile{
+		Url:       url,
+		NumRows:   spool.rows,
+		SizeBytes: info.Size(),
+	})
+	spool.manifest.NumRows += spool.rows
+	return nil
+}
+
+// abort removes the current file, if there is one.
+func (spool *trainingDataSpool) abort() {
+	if spool.file == nil {
+		return
+	}
+	spool.file.Close()
+	os.Remove(spool.file.Name())
+	spool.file = nil
+}
@@ -0,0 +1,172 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package newserving
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/featureform/proto"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestLimits bounds the size of serving requests. A zero limit isn't
+// enforced.
+type RequestLimits struct {
+	// MaxFeatures is how many features a request can serve.
+	MaxFeatures int
+	// MaxEntities is how many entities a request can have, or how many rows
+	// a multi-entity request can.
+	MaxEntities int
+	// MaxEntityValueLength is the longest an entity's value can be, in bytes.
+	MaxEntityValueLength int
+}
+
+// DefaultRequestLimits are the limits that the serving server enforces
+// unless it's configured with its own.
+var DefaultRequestLimits = RequestLimits{
+	MaxFeatures:          1000,
+	MaxEntities:          1000,
+	MaxEntityValueLength: 1024,
+}
+
+// requestViolations collects the problems with a request's fields.
+type requestViolations []*errdetails.BadRequest_FieldViolation
+
+func (v *requestViolations) add(field, format string, args ...interface{}) {
+	*v = append(*v, &errdetails.BadRequest_FieldViolation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+// err returns an InvalidArgument error with a BadRequest that lists each
+// violation, or nil if there are none.
+func (v requestViolations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+	descriptions := make([]string, len(v))
+	for i, violation := range v {
+		descriptions[i] = fmt.Sprintf("%s: %s", violation.Field, violation.Description)
+	}
+	st := status.New(codes.InvalidArgument, fmt.Sprintf("invalid request: %s", strings.Join(descriptions, "; ")))
+	if withDetail, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: v}); err == nil {
+		st = withDetail
+	}
+	return st.Err()
+}
+
+func (limits RequestLimits) validateFeatures(v *requestViolations, features []*pb.FeatureID, required bool) {
+	if required && len(features) == 0 {
+		v.add("features", "at least one feature is required")
+	}
+	if limits.MaxFeatures > 0 && len(features) > limits.MaxFeatures {
+		v.add("features", "%d features requested, the limit is %d", len(features), limits.MaxFeatures)
+		return
+	}
+	for i, feature := range features {
+		if feature.GetName() == "" {
+			v.add(fmt.Sprintf("features[%d].name", i), "feature name is required")
+		}
+	}
+}
+
+func (limits RequestLimits) validateEntities(v *requestViolations, field string, entities []*pb.Entity) {
+	if len(entities) == 0 {
+		v.add(field, "at least one entity is required")
+	}
+	if limits.MaxEntities > 0 && len(entities) > limits.MaxEntities {
+		v.add(field, "%d entities requested, the limit is %d", len(entities), limits.MaxEntities)
+		return
+	}
+	for i, entity := range entities {
+		if entity.GetName() == "" {
+			v.add(fmt.Sprintf("%s[%d].name", field, i), "entity name is required")
+		}
+		if limits.MaxEntityValueLength > 0 && len(entity.GetValue()) > limits.MaxEntityValueLength {
+			v.add(fmt.Sprintf("%s[%d].value", field, i), "value is %d bytes, the limit is %d", len(entity.GetValue()), limits.MaxEntityValueLength)
+		}
+	}
+}
+
+// Validate returns an InvalidArgument error if req is malformed or over the
+// limits. Requests of types it doesn't know of are valid.
+func (limits RequestLimits) Validate(req interface{}) error {
+	var v requestViolations
+	switch casted := req.(type) {
+	case *pb.FeatureServeRequest:
+		limits.validateFeatures(&v, casted.GetFeatures(), true)
+		limits.validateEntities(&v, "entities", casted.GetEntities())
+	case *pb.MultiEntityFeatureServeRequest:
+		limits.validateFeatures(&v, casted.GetFeatures(), true)
+		rows := casted.GetRows()
+		if len(rows) == 0 {
+			v.add("rows", "at least one row is required")
+		}
+		if limits.MaxEntities > 0 && len(rows) > limits.MaxEntities {
+			v.add("rows", "%d rows requested, the limit is %d", len(rows), limits.MaxEntities)
+			break
+		}
+		for i, row := range rows {
+			limits.validateEntities(&v, fmt.Sprintf("rows[%d].entities", i), row.GetEntities())
+		}
+	case *pb.ValueLineageRequest:
+		if casted.GetFeature().GetName() == "" {
+			v.add("feature.name", "feature name is required")
+		}
+		if casted.GetEntity() == nil {
+			v.add("entity", "an entity is required")
+			break
+		}
+		limits.validateEntities(&v, "entity", []*pb.Entity{casted.GetEntity()})
+	case *pb.TrainingDataRequest:
+		if casted.GetId().GetName() == "" {
+			v.add("id.name", "training set name is required")
+		}
+		limits.validateFeatures(&v, casted.GetFeatures(), false)
+	}
+	return v.err()
+}
+
+// UnaryValidationInterceptor rejects serving requests that are malformed or
+// over limits before they reach the server.
+func (serv *FeatureServer) UnaryValidationInterceptor(limits RequestLimits) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := limits.Validate(req); err != nil {
+			serv.Logger.Infow("Rejected invalid request", "Method", info.FullMethod, "Err", err)
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamValidationInterceptor validates the requests that streaming calls
+// receive, like TrainingData's.
+func (serv *FeatureServer) StreamValidationInterceptor(limits RequestLimits) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validatedStream{ServerStream: stream, serv: serv, limits: limits, method: info.FullMethod})
+	}
+}
+
+type validatedStream struct {
+	grpc.ServerStream
+	serv   *FeatureServer
+	limits RequestLimits
+	method string
+}
+
+func (stream *validatedStream) RecvMsg(m interface{}) error {
+	if err := stream.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if err := stream.limits.Validate(m); err != nil {
+		stream.serv.Logger.Infow("Rejected invalid request", "Method", stream.method, "Err", err)
+		return err
+	}
+	return nil
+}
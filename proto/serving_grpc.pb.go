@@ -19,7 +19,11 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type FeatureClient interface {
 	TrainingData(ctx context.Context, in *TrainingDataRequest, opts ...grpc.CallOption) (Feature_TrainingDataClient, error)
+	SpoolTrainingData(ctx context.Context, in *TrainingDataRequest, opts ...grpc.CallOption) (*TrainingDataManifest, error)
 	FeatureServe(ctx context.Context, in *FeatureServeRequest, opts ...grpc.CallOption) (*FeatureRow, error)
+	LabelServe(ctx context.Context, in *LabelServeRequest, opts ...grpc.CallOption) (*LabelRow, error)
+	MultiEntityFeatureServe(ctx context.Context, in *MultiEntityFeatureServeRequest, opts ...grpc.CallOption) (*MultiEntityFeatureRows, error)
+	ValueLineage(ctx context.Context, in *ValueLineageRequest, opts ...grpc.CallOption) (*ValueLineage, error)
 }
 
 type featureClient struct {
@@ -62,6 +66,15 @@ func (x *featureTrainingDataClient) Recv() (*TrainingDataRow, error) {
 	return m, nil
 }
 
+func (c *featureClient) SpoolTrainingData(ctx context.Context, in *TrainingDataRequest, opts ...grpc.CallOption) (*TrainingDataManifest, error) {
+	out := new(TrainingDataManifest)
+	err := c.cc.Invoke(ctx, "/featureform.serving.proto.Feature/SpoolTrainingData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *featureClient) FeatureServe(ctx context.Context, in *FeatureServeRequest, opts ...grpc.CallOption) (*FeatureRow, error) {
 	out := new(FeatureRow)
 	err := c.cc.Invoke(ctx, "/featureform.serving.proto.Feature/FeatureServe", in, out, opts...)
@@ -71,12 +84,43 @@ func (c *featureClient) FeatureServe(ctx context.Context, in *FeatureServeReques
 	return out, nil
 }
 
+func (c *featureClient) LabelServe(ctx context.Context, in *LabelServeRequest, opts ...grpc.CallOption) (*LabelRow, error) {
+	out := new(LabelRow)
+	err := c.cc.Invoke(ctx, "/featureform.serving.proto.Feature/LabelServe", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureClient) MultiEntityFeatureServe(ctx context.Context, in *MultiEntityFeatureServeRequest, opts ...grpc.CallOption) (*MultiEntityFeatureRows, error) {
+	out := new(MultiEntityFeatureRows)
+	err := c.cc.Invoke(ctx, "/featureform.serving.proto.Feature/MultiEntityFeatureServe", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureClient) ValueLineage(ctx context.Context, in *ValueLineageRequest, opts ...grpc.CallOption) (*ValueLineage, error) {
+	out := new(ValueLineage)
+	err := c.cc.Invoke(ctx, "/featureform.serving.proto.Feature/ValueLineage", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // FeatureServer is the server API for Feature service.
 // All implementations must embed UnimplementedFeatureServer
 // for forward compatibility
 type FeatureServer interface {
 	TrainingData(*TrainingDataRequest, Feature_TrainingDataServer) error
+	SpoolTrainingData(context.Context, *TrainingDataRequest) (*TrainingDataManifest, error)
 	FeatureServe(context.Context, *FeatureServeRequest) (*FeatureRow, error)
+	LabelServe(context.Context, *LabelServeRequest) (*LabelRow, error)
+	MultiEntityFeatureServe(context.Context, *MultiEntityFeatureServeRequest) (*MultiEntityFeatureRows, error)
+	ValueLineage(context.Context, *ValueLineageRequest) (*ValueLineage, error)
 	mustEmbedUnimplementedFeatureServer()
 }
 
@@ -87,9 +131,21 @@ type UnimplementedFeatureServer struct {
 func (UnimplementedFeatureServer) TrainingData(*TrainingDataRequest, Feature_TrainingDataServer) error {
 	return status.Errorf(codes.Unimplemented, "method TrainingData not implemented")
 }
+func (UnimplementedFeatureServer) SpoolTrainingData(context.Context, *TrainingDataRequest) (*TrainingDataManifest, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SpoolTrainingData not implemented")
+}
 func (UnimplementedFeatureServer) FeatureServe(context.Context, *FeatureServeRequest) (*FeatureRow, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FeatureServe not implemented")
 }
+func (UnimplementedFeatureServer) LabelServe(context.Context, *LabelServeRequest) (*LabelRow, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LabelServe not implemented")
+}
+func (UnimplementedFeatureServer) MultiEntityFeatureServe(context.Context, *MultiEntityFeatureServeRequest) (*MultiEntityFeatureRows, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MultiEntityFeatureServe not implemented")
+}
+func (UnimplementedFeatureServer) ValueLineage(context.Context, *ValueLineageRequest) (*ValueLineage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ValueLineage not implemented")
+}
 func (UnimplementedFeatureServer) mustEmbedUnimplementedFeatureServer() {}
 
 // UnsafeFeatureServer may be embedded to opt out of forward compatibility for this service.
@@ -124,6 +180,24 @@ func (x *featureTrainingDataServer) Send(m *TrainingDataRow) error {
 	return x.ServerStream.SendMsg(m)
 }
 
+func _Feature_SpoolTrainingData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrainingDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureServer).SpoolTrainingData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.serving.proto.Feature/SpoolTrainingData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureServer).SpoolTrainingData(ctx, req.(*TrainingDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Feature_FeatureServe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(FeatureServeRequest)
 	if err := dec(in); err != nil {
@@ -142,6 +216,60 @@ func _Feature_FeatureServe_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Feature_LabelServe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LabelServeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureServer).LabelServe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.serving.proto.Feature/LabelServe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureServer).LabelServe(ctx, req.(*LabelServeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Feature_MultiEntityFeatureServe_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MultiEntityFeatureServeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureServer).MultiEntityFeatureServe(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.serving.proto.Feature/MultiEntityFeatureServe",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureServer).MultiEntityFeatureServe(ctx, req.(*MultiEntityFeatureServeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Feature_ValueLineage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValueLineageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureServer).ValueLineage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.serving.proto.Feature/ValueLineage",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureServer).ValueLineage(ctx, req.(*ValueLineageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Feature_ServiceDesc is the grpc.ServiceDesc for Feature service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -149,10 +277,26 @@ var Feature_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "featureform.serving.proto.Feature",
 	HandlerType: (*FeatureServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SpoolTrainingData",
+			Handler:    _Feature_SpoolTrainingData_Handler,
+		},
 		{
 			MethodName: "FeatureServe",
 			Handler:    _Feature_FeatureServe_Handler,
 		},
+		{
+			MethodName: "LabelServe",
+			Handler:    _Feature_LabelServe_Handler,
+		},
+		{
+			MethodName: "MultiEntityFeatureServe",
+			Handler:    _Feature_MultiEntityFeatureServe_Handler,
+		},
+		{
+			MethodName: "ValueLineage",
+			Handler:    _Feature_ValueLineage_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
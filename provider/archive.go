@@ -0,0 +1,381 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// Archiver writes objects to cheaper storage, such as an object store, so
+// that data can be removed from the warehouse without being lost.
+type Archiver interface {
+	Archive(ctx context.Context, key string, data []byte) error
+}
+
+// URLSigner is implemented by archivers that can hand out a URL that reads an
+// archived object directly, without going through featureform.
+type URLSigner interface {
+	SignURL(ctx context.Context, key string, expires time.Duration) (string, error)
+}
+
+// ArchiveReader is implemented by archivers that can read back the objects
+// they've written. The object is streamed rather than read into memory.
+type ArchiveReader interface {
+	OpenArchive(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// FileArchiver is implemented by archivers that can write an object from a
+// file without reading all of it into memory.
+type FileArchiver interface {
+	ArchiveFile(ctx context.Context, key string, file *os.File) error
+}
+
+// ArchiveFile writes the contents of file to archiver under key, from the
+// start of the file.
+func ArchiveFile(ctx context.Context, archiver Archiver, key string, file *os.File) error {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if fileArchiver, ok := archiver.(FileArchiver); ok {
+		return fileArchiver.ArchiveFile(ctx, key, file)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	return archiver.Archive(ctx, key, data)
+}
+
+type ArchiverFactory func(uri *url.URL) (Archiver, error)
+
+var archiverFactories = map[string]ArchiverFactory{
+	"s3":   newS3Archiver,
+	"gs":   newGCSArchiver,
+	"file": newFileArchiver,
+}
+
+func RegisterArchiver(scheme string, factory ArchiverFactory) error {
+	if _, has := archiverFactories[scheme]; has {
+		return fmt.Errorf("%s archiver already exists", scheme)
+	}
+	archiverFactories[scheme] = factory
+	return nil
+}
+
+// NewArchiver returns an archiver for a URI such as s3://bucket/prefix,
+// gs://bucket/prefix or file:///var/archive. Keys are written under the URI's path.
+func NewArchiver(rawURI string) (Archiver, error) {
+	uri, err := url.Parse(rawURI)
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive uri %s: %w", rawURI, err)
+	}
+	factory, has := archiverFactories[uri.Scheme]
+	if !has {
+		return nil, fmt.Errorf("no archiver for scheme %q", uri.Scheme)
+	}
+	return factory(uri)
+}
+
+// ArchiveMaterialization writes every row of mat to archiver as gzipped JSON
+// lines under key.
+func ArchiveMaterialization(ctx context.Context, archiver Archiver, key string, mat Materialization) error {
+	numRows, err := mat.NumRows()
+	if err != nil {
+		return fmt.Errorf("num rows: %w", err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(zw)
+	if numRows > 0 {
+		iter, err := mat.IterateSegment(0, numRows)
+		if err != nil {
+			return fmt.Errorf("iterate materialization: %w", err)
+		}
+		for iter.Next() {
+			if err := encoder.Encode(iter.Value()); err != nil {
+				return err
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return fmt.Errorf("read materialization: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return archiver.Archive(ctx, key, buf.Bytes())
+}
+
+type fileArchiver struct {
+	dir string
+}
+
+func newFileArchiver(uri *url.URL) (Archiver, error) {
+	if uri.Path == "" {
+		return nil, fmt.Errorf("file archive uri needs a path")
+	}
+	return &fileArchiver{dir: uri.Path}, nil
+}
+
+func (a *fileArchiver) Archive(ctx context.Context, key string, data []byte) error {
+	name := filepath.Join(a.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(name, data, 0644)
+}
+
+func (a *fileArchiver) ArchiveFile(ctx context.Context, key string, file *os.File) error {
+	name := filepath.Join(a.dir, filepath.FromSlash(key))
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	dest, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dest, file); err != nil {
+		dest.Close()
+		return err
+	}
+	return dest.Close()
+}
+
+func (a *fileArchiver) OpenArchive(ctx context.Context, key string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(a.dir, filepath.FromSlash(key)))
+}
+
+// SignURL returns a file URL, which is only useful to readers that share the
+// archive's filesystem. It doesn't expire.
+func (a *fileArchiver) SignURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(filepath.Join(a.dir, filepath.FromSlash(key)))}
+	return u.String(), nil
+}
+
+var awsS3Endpoint = func(bucket, region string) string {
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com", bucket, region)
+}
+
+// s3Archiver puts objects into s3://<bucket>/<prefix>. The region is taken
+// from the uri's region parameter, falling back to AWS_REGION.
+type s3Archiver struct {
+	bucket string
+	prefix string
+	region string
+}
+
+func newS3Archiver(uri *url.URL) (Archiver, error) {
+	region := uri.Query().Get("region")
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if uri.Host == "" || region == "" {
+		return nil, fmt.Errorf("s3 archive uri needs a bucket and region")
+	}
+	return &s3Archiver{
+		bucket: uri.Host,
+		prefix: strings.Trim(uri.Path, "/"),
+		region: region,
+	}, nil
+}
+
+func (a *s3Archiver) Archive(ctx context.Context, key string, data []byte) error {
+	hash := sha256.Sum256(data)
+	return a.put(ctx, key, bytes.NewReader(data), int64(len(data)), hex.EncodeToString(hash[:]))
+}
+
+// ArchiveFile reads the file twice, once to sign its hash and once to
+// upload it.
+func (a *s3Archiver) ArchiveFile(ctx context.Context, key string, file *os.File) error {
+	hash := sha256.New()
+	size, err := io.Copy(hash, file)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Seek(-size, io.SeekCurrent); err != nil {
+		return err
+	}
+	// The file is left open for the caller to close, where the request's
+	// body would be closed once it's sent.
+	return a.put(ctx, key, io.NopCloser(file), size, hex.EncodeToString(hash.Sum(nil)))
+}
+
+func (a *s3Archiver) put(ctx context.Context, key string, body io.Reader, size int64, payloadHash string) error {
+	objectURL := awsS3Endpoint(a.bucket, a.region) + "/" + path.Join(a.prefix, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	creds, err := awsCredentials.Retrieve(ctx)
+	if err != nil {
+		return fmt.Errorf("could not retrieve aws credentials: %w", err)
+	}
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, payloadHash, "s3", a.region, time.Now().UTC()); err != nil {
+		return err
+	}
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not put %s: %s", objectURL, resp.Status)
+	}
+	return nil
+}
+
+func (a *s3Archiver) OpenArchive(ctx context.Context, key string) (io.ReadCloser, error) {
+	objectURL := awsS3Endpoint(a.bucket, a.region) + "/" + path.Join(a.prefix, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	// The hash of an empty payload.
+	payloadHash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	creds, err := awsCredentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("could not retrieve aws credentials: %w", err)
+	}
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, payloadHash, "s3", a.region, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	return openObject(req, objectURL)
+}
+
+// SignURL presigns a GET of the object with the archiver's AWS credentials.
+func (a *s3Archiver) SignURL(ctx context.Context, key string, expires time.Duration) (string, error) {
+	objectURL := awsS3Endpoint(a.bucket, a.region) + "/" + path.Join(a.prefix, key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+	if err != nil {
+		return "", err
+	}
+	query := req.URL.Query()
+	query.Set("X-Amz-Expires", fmt.Sprint(int(expires.Seconds())))
+	req.URL.RawQuery = query.Encode()
+	creds, err := awsCredentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve aws credentials: %w", err)
+	}
+	signed, _, err := v4.NewSigner().PresignHTTP(ctx, creds, req, "UNSIGNED-PAYLOAD", "s3", a.region, time.Now().UTC())
+	if err != nil {
+		return "", fmt.Errorf("could not sign %s: %w", objectURL, err)
+	}
+	return signed, nil
+}
+
+var gcsEndpoint = "https://storage.googleapis.com"
+
+// gcsArchiver puts objects into gs://<bucket>/<prefix>, authenticated as the
+// workload's service account.
+type gcsArchiver struct {
+	bucket string
+	prefix string
+}
+
+func newGCSArchiver(uri *url.URL) (Archiver, error) {
+	if uri.Host == "" {
+		return nil, fmt.Errorf("gs archive uri needs a bucket")
+	}
+	return &gcsArchiver{
+		bucket: uri.Host,
+		prefix: strings.Trim(uri.Path, "/"),
+	}, nil
+}
+
+func (a *gcsArchiver) Archive(ctx context.Context, key string, data []byte) error {
+	return a.upload(ctx, key, bytes.NewReader(data), int64(len(data)))
+}
+
+func (a *gcsArchiver) ArchiveFile(ctx context.Context, key string, file *os.File) error {
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	offset, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	return a.upload(ctx, key, io.NopCloser(file), info.Size()-offset)
+}
+
+func (a *gcsArchiver) upload(ctx context.Context, key string, body io.Reader, size int64) error {
+	name := path.Join(a.prefix, key)
+	objectURL := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?uploadType=media&name=%s", gcsEndpoint, url.PathEscape(a.bucket), url.QueryEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, objectURL, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	token, err := gcpAccessToken(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not put gs://%s/%s: %s", a.bucket, name, resp.Status)
+	}
+	return nil
+}
+
+func (a *gcsArchiver) OpenArchive(ctx context.Context, key string) (io.ReadCloser, error) {
+	name := path.Join(a.prefix, key)
+	objectURL := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media", gcsEndpoint, url.PathEscape(a.bucket), url.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	token, err := gcpAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return openObject(req, fmt.Sprintf("gs://%s/%s", a.bucket, name))
+}
+
+// archiveReadClient only bounds the wait for a response, since an object's
+// body is streamed for as long as its reader takes.
+var archiveReadClient = &http.Client{Transport: &http.Transport{
+	Proxy:                 http.ProxyFromEnvironment,
+	ResponseHeaderTimeout: 30 * time.Second,
+}}
+
+// openObject sends a GET of an object and returns its body, which the
+// caller closes.
+func openObject(req *http.Request, name string) (io.ReadCloser, error) {
+	resp, err := archiveReadClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("could not get %s: %s", name, resp.Status)
+	}
+	return resp.Body, nil
+}
@@ -0,0 +1,263 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+// BulkLoader is implemented by online stores with a native bulk import. A
+// chunk of records is written to object storage in the store's own import
+// format, and the store loads the object in a single operation rather than
+// with a round trip per record. Stores that don't implement it are copied to
+// a record at a time.
+type BulkLoader interface {
+	// WriteBulkLoad encodes the rest of iter's records into w in the format
+	// BulkLoad imports into table, and returns how many there were.
+	WriteBulkLoad(w io.Writer, table OnlineStoreTable, iter FeatureIterator) (int64, error)
+	// BulkLoad imports the object that archive holds under key, which was
+	// written by WriteBulkLoad.
+	BulkLoad(ctx context.Context, archive ArchiveReader, key string) error
+}
+
+// WriteBulkLoad encodes the records as a stream of Redis commands, which is
+// what redis-cli --pipe mass inserts. Each record is set with the same
+// script as SetIfNewer, so that a bulk load never replaces a newer value
+// either.
+func (store *redisOnlineStore) WriteBulkLoad(w io.Writer, table OnlineStoreTable, iter FeatureIterator) (int64, error) {
+	redisTable, ok := table.(*redisOnlineTable)
+	if !ok {
+		return 0, fmt.Errorf("%T is not a redis table", table)
+	}
+	buf := bufio.NewWriter(w)
+	for _, script := range []string{redisSetIfNewerSource, redisDeleteIfNewerSource} {
+		if err := writeRESPCommand(buf, "SCRIPT", "LOAD", script); err != nil {
+			return 0, err
+		}
+	}
+	hashKey, asOfKey := redisTable.key.String(), redisTable.key.asOfKey()
+	sha := redisSetIfNewer.Hash()
+	var count int64
+	for iter.Next() {
+		record := iter.Value()
+		ts := strconv.FormatInt(record.TS.UnixMicro(), 10)
+		// A null value is an entity that no longer has one, so it's removed.
+		if record.Value == nil {
+			if err := writeRESPCommand(buf, "EVALSHA", redisDeleteIfNewer.Hash(), "2", hashKey, asOfKey, record.Entity, ts); err != nil {
+				return 0, err
+			}
+			count++
+			continue
+		}
+		value, err := redisArg(record.Value)
+		if err != nil {
+			return 0, fmt.Errorf("entity %s: %w", record.Entity, err)
+		}
+		if err := writeRESPCommand(buf, "EVALSHA", sha, "2", hashKey, asOfKey, record.Entity, value, ts); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		return 0, err
+	}
+	return count, buf.Flush()
+}
+
+// redisArg is value as the text go-redis would send it as.
+func redisArg(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case DecimalValue:
+		return string(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	default:
+		return "", fmt.Errorf("can't bulk load a value of type %T", value)
+	}
+}
+
+func writeRESPCommand(w *bufio.Writer, args ...string) error {
+	if _, err := fmt.Fprintf(w, "*%d\r\n", len(args)); err != nil {
+		return err
+	}
+	for _, arg := range args {
+		if _, err := fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BulkLoad streams the object to a connection of its own, the way
+// redis-cli --pipe does, and ends it with an ECHO of a random marker. Replies
+// are read as the commands are sent, and the load is done once the marker
+// is echoed back.
+func (store *redisOnlineStore) BulkLoad(ctx context.Context, archive ArchiveReader, key string) error {
+	object, err := archive.OpenArchive(ctx, key)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", key, err)
+	}
+	defer object.Close()
+	conn, err := store.dialPipe(ctx)
+	if err != nil {
+		return fmt.Errorf("connect for bulk load: %w", err)
+	}
+	defer conn.Close()
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-stop:
+		}
+	}()
+	marker := make([]byte, 20)
+	if _, err := rand.Read(marker); err != nil {
+		return err
+	}
+	echo := hex.EncodeToString(marker)
+	sent := make(chan error, 1)
+	go func() {
+		w := bufio.NewWriter(conn)
+		if err := store.writePipeAuth(ctx, w); err != nil {
+			sent <- err
+			return
+		}
+		if _, err := io.Copy(w, object); err != nil {
+			sent <- fmt.Errorf("send %s: %w", key, err)
+			return
+		}
+		if err := writeRESPCommand(w, "ECHO", echo); err != nil {
+			sent <- err
+			return
+		}
+		sent <- w.Flush()
+	}()
+	replies := bufio.NewReader(conn)
+	var firstErr error
+	for {
+		reply, isErr, err := readRESPReply(replies)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			conn.Close()
+			if sendErr := <-sent; sendErr != nil {
+				return sendErr
+			}
+			return fmt.Errorf("read bulk load replies: %w", err)
+		}
+		if isErr && firstErr == nil {
+			firstErr = fmt.Errorf("bulk load %s: %s", key, reply)
+		}
+		if !isErr && reply == echo {
+			break
+		}
+	}
+	if err := <-sent; err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// dialPipe opens a plain connection with the client's address and TLS
+// settings, since go-redis doesn't expose a connection's raw stream.
+func (store *redisOnlineStore) dialPipe(ctx context.Context) (net.Conn, error) {
+	options := store.client.Options()
+	dialer := &net.Dialer{Timeout: options.DialTimeout}
+	if options.TLSConfig != nil {
+		return (&tls.Dialer{NetDialer: dialer, Config: options.TLSConfig}).DialContext(ctx, "tcp", options.Addr)
+	}
+	return dialer.DialContext(ctx, "tcp", options.Addr)
+}
+
+// writePipeAuth authenticates a pipe connection the same way the client's
+// connections are on connect.
+func (store *redisOnlineStore) writePipeAuth(ctx context.Context, w *bufio.Writer) error {
+	if store.token == nil {
+		return nil
+	}
+	password, err := store.token(ctx)
+	if err != nil {
+		return err
+	}
+	if store.username == "" {
+		return writeRESPCommand(w, "AUTH", password)
+	}
+	return writeRESPCommand(w, "AUTH", store.username, password)
+}
+
+// readRESPReply reads a reply as text, flattening arrays, and whether it was
+// an error.
+func readRESPReply(r *bufio.Reader) (string, bool, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", false, err
+	}
+	if len(line) < 3 {
+		return "", false, fmt.Errorf("invalid reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+	switch kind {
+	case '+', ':':
+		return body, false, nil
+	case '-':
+		return body, true, nil
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid bulk reply length %q", body)
+		}
+		if n < 0 {
+			return "", false, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return "", false, err
+		}
+		return string(data[:n]), false, nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid array reply length %q", body)
+		}
+		for i := 0; i < n; i++ {
+			if _, _, err := readRESPReply(r); err != nil {
+				return "", false, err
+			}
+		}
+		return "", false, nil
+	default:
+		return "", false, fmt.Errorf("invalid reply %q", line)
+	}
+}
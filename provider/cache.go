@@ -0,0 +1,87 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultDrainTimeout is how long a replaced provider is kept open so that
+// in-flight requests can finish before its connections are closed.
+const DefaultDrainTimeout = 30 * time.Second
+
+type closer interface {
+	Close() error
+}
+
+// Validate creates a provider from config and checks that it can connect. It
+// is used to reject bad credentials before they replace working ones.
+func Validate(t Type, config SerializedConfig) error {
+	p, err := Get(t, config)
+	if err != nil {
+		return err
+	}
+	if c, ok := p.(closer); ok {
+		defer c.Close()
+	}
+	if err := p.CheckHealth(context.Background()); err != nil {
+		return fmt.Errorf("could not connect to %s provider: %w", t, err)
+	}
+	return nil
+}
+
+type cachedProvider struct {
+	config   SerializedConfig
+	provider Provider
+}
+
+// Cache keeps one open provider per provider name. When the config for a
+// name changes, for example after its credentials are rotated, a new
+// provider is created and the old one is closed once DrainTimeout passes.
+type Cache struct {
+	DrainTimeout time.Duration
+	mtx          *sync.Mutex
+	providers    map[string]cachedProvider
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		DrainTimeout: DefaultDrainTimeout,
+		mtx:          &sync.Mutex{},
+		providers:    make(map[string]cachedProvider),
+	}
+}
+
+func (cache *Cache) Get(name string, t Type, config SerializedConfig) (Provider, error) {
+	cache.mtx.Lock()
+	defer cache.mtx.Unlock()
+	old, has := cache.providers[name]
+	if has && old.provider.Type() == t && bytes.Equal(old.config, config) {
+		return old.provider, nil
+	}
+	p, err := Get(t, config)
+	if err != nil {
+		return nil, err
+	}
+	cache.providers[name] = cachedProvider{config: config, provider: p}
+	if has {
+		cache.drain(old.provider)
+	}
+	return p, nil
+}
+
+func (cache *Cache) drain(p Provider) {
+	c, ok := p.(closer)
+	if !ok {
+		return
+	}
+	time.AfterFunc(cache.DrainTimeout, func() {
+		c.Close()
+	})
+}
@@ -0,0 +1,229 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ContextOfflineStore is implemented by offline stores whose queries can be
+// tied to a job's context, so that they don't keep running in the warehouse
+// after the job has been cancelled or run out of time.
+type ContextOfflineStore interface {
+	OfflineStore
+	// WithContext returns a store whose statements are limited by a
+	// statement timeout ending at ctx's deadline, and are cancelled in the
+	// warehouse once ctx is done. The returned function releases the store's
+	// connections and must be called once the job is done with it.
+	WithContext(ctx context.Context) (OfflineStore, func(), error)
+}
+
+// OfflineStoreWithContext ties store's queries to ctx if it supports it, or
+// returns it as it is. Contexts that are never done are ignored.
+func OfflineStoreWithContext(ctx context.Context, store OfflineStore) (OfflineStore, func(), error) {
+	if ctx.Done() == nil {
+		return store, func() {}, nil
+	}
+	if ctxStore, ok := store.(ContextOfflineStore); ok {
+		return ctxStore.WithContext(ctx)
+	}
+	return store, func() {}, nil
+}
+
+// WithContext opens a separate pool of connections for the job, so that the
+// statement timeout set on them doesn't apply to other jobs. The warehouse
+// session of each connection is recorded, and once ctx is done the statements
+// running in them are cancelled from the store's own pool, since the job's
+// connections are busy running them.
+func (store *sqlOfflineStore) WithContext(ctx context.Context) (OfflineStore, func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	d := store.query.dialect()
+	var setup []string
+	if deadline, ok := ctx.Deadline(); ok {
+		if timeout := d.statementTimeout(time.Until(deadline)); timeout != "" {
+			setup = append(setup, timeout)
+		}
+	}
+	base, err := store.connector()
+	if err != nil {
+		return nil, nil, err
+	}
+	sessions := &jobSessions{ids: make(map[int64]bool)}
+	db := sql.OpenDB(sessionConnector{
+		Connector: base,
+		setup:     setup,
+		sessionID: d.sessionID(),
+		sessions:  sessions,
+	})
+	// Idle connections are kept until the pool is closed, so that every
+	// recorded session belongs to the job when it's cancelled.
+	db.SetMaxIdleConns(math.MaxInt32)
+	jobStore := *store
+	jobStore.db = db
+	released := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		select {
+		case <-ctx.Done():
+		case <-released:
+		}
+		// A job whose context is done may still have statements running,
+		// even if it's released the store.
+		if ctx.Err() != nil {
+			store.cancelSessions(sessions.list())
+		}
+		db.Close()
+	}()
+	var once sync.Once
+	release := func() {
+		once.Do(func() {
+			close(released)
+			<-done
+		})
+	}
+	return &jobStore, release, nil
+}
+
+// cancelSessions cancels the statements running in the given sessions. It
+// doesn't use the job's context, since that's already done.
+func (store *sqlOfflineStore) cancelSessions(ids []int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, id := range ids {
+		if query := store.query.dialect().cancelSession(id); query != "" {
+			// The job has already failed, and a session that couldn't be
+			// reached is still stopped by its statement timeout.
+			store.db.ExecContext(ctx, query)
+		}
+	}
+}
+
+// connector returns a connector for the store's database.
+func (store *sqlOfflineStore) connector() (driver.Connector, error) {
+	if store.parent.Connector != nil {
+		return store.parent.Connector, nil
+	}
+	drv := store.db.Driver()
+	if ctxDriver, ok := drv.(driver.DriverContext); ok {
+		return ctxDriver.OpenConnector(store.parent.ConnectionURL)
+	}
+	return dsnConnector{dsn: store.parent.ConnectionURL, driver: drv}, nil
+}
+
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type jobSessions struct {
+	mtx sync.Mutex
+	ids map[int64]bool
+}
+
+func (s *jobSessions) add(id int64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.ids[id] = true
+}
+
+func (s *jobSessions) list() []int64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	ids := make([]int64, 0, len(s.ids))
+	for id := range s.ids {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// sessionConnector runs setup statements on each new connection and records
+// its session ID.
+type sessionConnector struct {
+	driver.Connector
+	setup     []string
+	sessionID string
+	sessions  *jobSessions
+}
+
+func (c sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.prepare(ctx, conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (c sessionConnector) prepare(ctx context.Context, conn driver.Conn) error {
+	for _, stmt := range c.setup {
+		execer, ok := conn.(driver.ExecerContext)
+		if !ok {
+			return fmt.Errorf("connection can't execute %s", stmt)
+		}
+		if _, err := execer.ExecContext(ctx, stmt, nil); err != nil {
+			return fmt.Errorf("set up session: %w", err)
+		}
+	}
+	if c.sessionID == "" {
+		return nil
+	}
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return nil
+	}
+	rows, err := queryer.QueryContext(ctx, c.sessionID, nil)
+	if err != nil {
+		return fmt.Errorf("get session id: %w", err)
+	}
+	defer rows.Close()
+	values := make([]driver.Value, len(rows.Columns()))
+	if err := rows.Next(values); err != nil && err != io.EOF {
+		return fmt.Errorf("get session id: %w", err)
+	}
+	if len(values) == 0 || values[0] == nil {
+		return nil
+	}
+	id, err := sessionIDValue(values[0])
+	if err != nil {
+		return err
+	}
+	c.sessions.add(id)
+	return nil
+}
+
+func sessionIDValue(v driver.Value) (int64, error) {
+	switch id := v.(type) {
+	case int64:
+		return id, nil
+	case []byte:
+		return strconv.ParseInt(string(id), 10, 64)
+	case string:
+		return strconv.ParseInt(id, 10, 64)
+	default:
+		return 0, fmt.Errorf("unexpected session id %v of type %T", v, v)
+	}
+}
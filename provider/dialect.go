@@ -0,0 +1,556 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Dialect is the part of SQL generation that differs between warehouses. A
+// new SQL offline store picks or writes a Dialect, and the resource table,
+// materialization and training set logic of sqlOfflineStore is shared between
+// all of them.
+type Dialect interface {
+	// quote makes an identifier safe to use in a query.
+	quote(ident string) string
+	// quoteName quotes each part of a name that may be qualified, such as a
+	// user's schema.table, leaving parts that are already quoted alone.
+	quoteName(name string) string
+	// quoteChar is the character identifiers are quoted with.
+	quoteChar() byte
+	// backslashEscapes is whether a backslash escapes the character after
+	// it in a string literal.
+	backslashEscapes() bool
+	// latestValues selects the latest value of each entity in a resource
+	// table, along with a row_number column that materializations are
+	// iterated by. If since isn't zero, only values after it are selected,
+	// and if until isn't zero, values after it are ignored.
+	latestValues(resourceTable string, since, until time.Time) string
+	// asOfJoin selects every row of the label table, along with the latest
+	// value of each feature at or before the row's timestamp. There's a
+	// column for each feature, named by its column field, followed by a
+	// label column.
+	asOfJoin(labelTable string, features []asOfFeature) string
+	// columnType is the type of a column that holds values of valueType.
+	columnType(valueType ValueType) (string, error)
+	// timestampType is the type of resource tables' ts column.
+	timestampType() string
+	// epochTimestamp is the ts of every value of a resource that's registered
+	// without a timestamp column.
+	epochTimestamp() string
+	// uniqueConstraint declares columns unique in a CREATE TABLE, or is empty
+	// if the warehouse doesn't support it.
+	uniqueConstraint(columns ...string) string
+	// statementTimeout limits how long each statement run by a session may
+	// take, or is empty if the warehouse can't limit it.
+	statementTimeout(timeout time.Duration) string
+	// sessionID selects the warehouse's ID for the current session, or is
+	// empty if its statements can't be cancelled from another session.
+	sessionID() string
+	// cancelSession cancels the statement running in the session with id.
+	cancelSession(id int64) string
+	// materializedView is the statements that create a materialized view of
+	// query, replacing the one named name if replace is set, or is empty if
+	// the warehouse doesn't have materialized views.
+	materializedView(name, query string, replace bool) []string
+	// dependentViews lists the views built on the relation named by its
+	// parameter, directly or through other views, each after the ones it
+	// selects from. The columns are each view's quoted name, whether it's a
+	// materialized view, and its query. It's empty if the warehouse binds
+	// views to names, so that replacing a relation leaves its views alone.
+	dependentViews() string
+}
+
+// typeDialect is the Dialect of the SQL offline store of type t, or the ANSI
+// dialect if t isn't a SQL store.
+func typeDialect(t Type) Dialect {
+	switch t {
+	case PostgresOffline:
+		return postgresDialect{}
+	case RedshiftOffline:
+		return redshiftDialect{}
+	case SnowflakeOffline:
+		return snowflakeDialect{}
+	default:
+		return ansiDialect{}
+	}
+}
+
+type asOfFeature struct {
+	column string
+	table  string
+}
+
+// ansiDialect uses window functions and types that most warehouses support.
+// It quotes identifiers with double quotes unless identQuote is set.
+type ansiDialect struct {
+	identQuote byte
+}
+
+func (d ansiDialect) quoteChar() byte {
+	if d.identQuote == 0 {
+		return '"'
+	}
+	return d.identQuote
+}
+
+// backslashEscapes is false, since standard SQL strings only escape quotes,
+// by doubling them.
+func (d ansiDialect) backslashEscapes() bool {
+	return false
+}
+
+func (d ansiDialect) quote(ident string) string {
+	return quoteIdentifier(ident, d.quoteChar())
+}
+
+func (d ansiDialect) quoteName(name string) string {
+	return quoteName(name, d.quoteChar())
+}
+
+func (d ansiDialect) columnType(valueType ValueType) (string, error) {
+	switch valueType {
+	case Int, Int32, Int64:
+		return "BIGINT", nil
+	case Float32, Float64:
+		return "DOUBLE PRECISION", nil
+	case String, NilType:
+		return "VARCHAR", nil
+	case Bool:
+		return "BOOLEAN", nil
+	case Timestamp:
+		return "TIMESTAMP", nil
+	case Bytes:
+		return "VARBINARY", nil
+	case Decimal:
+		return "DECIMAL(38, 18)", nil
+	default:
+		return "", fmt.Errorf("cannot find column type for value type: %s", valueType)
+	}
+}
+
+func (d ansiDialect) timestampType() string {
+	return "TIMESTAMP"
+}
+
+func (d ansiDialect) epochTimestamp() string {
+	return "TIMESTAMP '1970-01-01 00:00:00'"
+}
+
+func (d ansiDialect) uniqueConstraint(columns ...string) string {
+	return fmt.Sprintf("UNIQUE (%s)", strings.Join(columns, ", "))
+}
+
+func (d ansiDialect) statementTimeout(timeout time.Duration) string {
+	return ""
+}
+
+func (d ansiDialect) sessionID() string {
+	return ""
+}
+
+func (d ansiDialect) cancelSession(id int64) string {
+	return ""
+}
+
+func (d ansiDialect) materializedView(name, query string, replace bool) []string {
+	return nil
+}
+
+func (d ansiDialect) dependentViews() string {
+	return ""
+}
+
+func (d ansiDialect) latestValues(resourceTable string, since, until time.Time) string {
+	filter := ""
+	if !since.IsZero() {
+		filter = fmt.Sprintf(" AND ts > %s", timestampLiteral(since))
+	}
+	return fmt.Sprintf("SELECT entity, value, ts, row_number() over(ORDER BY (SELECT NULL)) as row_number FROM "+
+		"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) "+
+		"AS rn FROM %s%s) t WHERE rn=1%s", d.quote(resourceTable), untilFilter(until), filter)
+}
+
+// untilFilter limits a resource table to the values at or before until, so
+// that the latest of them are the values as of until.
+func untilFilter(until time.Time) string {
+	if until.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" WHERE ts <= %s", timestampLiteral(until))
+}
+
+// asOfJoin joins every earlier feature value to each label row, then keeps
+// the first row for each label.
+func (d ansiDialect) asOfJoin(labelTable string, features []asOfFeature) string {
+	columns := make([]string, len(features))
+	joins := ""
+	for i, feature := range features {
+		column := d.quote(feature.column)
+		alias := fmt.Sprintf("t%d", i+1)
+		columns[i] = column
+		joins = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value as %s, ts FROM %s ORDER BY ts desc) as %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts)",
+			joins, column, d.quote(feature.table), alias, alias, alias)
+	}
+	columnStr := strings.Join(columns, ", ")
+	return fmt.Sprintf("SELECT %s, label FROM ("+
+		"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY time desc) as rn FROM ( "+
+		"SELECT t0.entity as e, t0.value as label, t0.ts as time, %s from %s as t0 %s )) WHERE rn=1",
+		columnStr, columnStr, d.quote(labelTable), joins)
+}
+
+// postgresDialect looks up each feature value with a lateral join, which
+// can use an index on the feature's entity and timestamp.
+type postgresDialect struct {
+	ansiDialect
+}
+
+func (d postgresDialect) columnType(valueType ValueType) (string, error) {
+	switch valueType {
+	case Int, Int32, Int64:
+		return "INT", nil
+	case Float32, Float64:
+		return "FLOAT8", nil
+	case Timestamp:
+		return "TIMESTAMPTZ", nil
+	case Bytes:
+		return "BYTEA", nil
+	case Decimal:
+		return "NUMERIC", nil
+	default:
+		return d.ansiDialect.columnType(valueType)
+	}
+}
+
+func (d postgresDialect) timestampType() string {
+	return "TIMESTAMPTZ"
+}
+
+func (d postgresDialect) epochTimestamp() string {
+	return postgresEpoch()
+}
+
+func (d postgresDialect) statementTimeout(timeout time.Duration) string {
+	return postgresStatementTimeout(timeout)
+}
+
+func (d postgresDialect) sessionID() string {
+	return "SELECT pg_backend_pid()"
+}
+
+func (d postgresDialect) cancelSession(id int64) string {
+	return fmt.Sprintf("SELECT pg_cancel_backend(%d)", id)
+}
+
+func (d postgresDialect) materializedView(name, query string, replace bool) []string {
+	return dropAndCreateMaterializedView(d, name, query, replace)
+}
+
+// dependentViews follows the rewrite rules that make up each view, since
+// Postgres binds a view to the relations it selects from rather than to
+// their names. A view is ordered by its longest path to the relation.
+func (d postgresDialect) dependentViews() string {
+	return "WITH RECURSIVE dependents(oid, depth) AS (" +
+		"SELECT r.ev_class, 1 FROM pg_depend d JOIN pg_rewrite r ON r.oid = d.objid " +
+		"WHERE d.refobjid = to_regclass(quote_ident($1)) AND r.ev_class <> d.refobjid " +
+		"UNION SELECT r.ev_class, dependents.depth + 1 FROM dependents JOIN pg_depend d ON d.refobjid = dependents.oid " +
+		"JOIN pg_rewrite r ON r.oid = d.objid WHERE r.ev_class <> d.refobjid) " +
+		"SELECT quote_ident(n.nspname) || '.' || quote_ident(c.relname), c.relkind = 'm', pg_get_viewdef(c.oid) " +
+		"FROM (SELECT oid, MAX(depth) AS depth FROM dependents GROUP BY oid) deps " +
+		"JOIN pg_class c ON c.oid = deps.oid JOIN pg_namespace n ON n.oid = c.relnamespace ORDER BY deps.depth"
+}
+
+// postgresStatementTimeout is shared with Redshift. The timeout is set in
+// milliseconds, where zero would disable it.
+func postgresStatementTimeout(timeout time.Duration) string {
+	ms := timeout.Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	return fmt.Sprintf("SET statement_timeout TO %d", ms)
+}
+
+// postgresEpoch is shared with Redshift, which parses timestamps the same way.
+func postgresEpoch() string {
+	return fmt.Sprintf("to_timestamp('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMPTZ", time.UnixMilli(0).UTC())
+}
+
+func (d postgresDialect) asOfJoin(labelTable string, features []asOfFeature) string {
+	columns := make([]string, len(features))
+	joins := ""
+	for i, feature := range features {
+		column := d.quote(feature.column)
+		alias := fmt.Sprintf("t%d", i)
+		columns[i] = column
+		joins = fmt.Sprintf("%s LEFT JOIN LATERAL (SELECT entity , value as %s, ts  FROM %s WHERE entity=l.entity and ts <= l.ts ORDER BY ts desc LIMIT 1) %s on %s.entity=l.entity ",
+			joins, column, d.quote(feature.table), alias, alias)
+	}
+	return fmt.Sprintf("SELECT %s, l.value as label FROM  (SELECT entity, value , ts from %s ) l %s",
+		strings.Join(columns, ", "), d.quote(labelTable), joins)
+}
+
+// redshiftDialect orders materializations by entity and breaks ties between
+// feature values with the same timestamp by rank.
+type redshiftDialect struct {
+	ansiDialect
+}
+
+func (d redshiftDialect) columnType(valueType ValueType) (string, error) {
+	switch valueType {
+	case Timestamp:
+		return "TIMESTAMPTZ", nil
+	case Bytes:
+		return "VARBYTE", nil
+	default:
+		// Redshift's default decimal scale is zero, which would drop the
+		// fraction, so the ANSI DECIMAL(38, 18) is kept.
+		return d.ansiDialect.columnType(valueType)
+	}
+}
+
+func (d redshiftDialect) timestampType() string {
+	return "TIMESTAMPTZ"
+}
+
+func (d redshiftDialect) epochTimestamp() string {
+	return postgresEpoch()
+}
+
+func (d redshiftDialect) statementTimeout(timeout time.Duration) string {
+	return postgresStatementTimeout(timeout)
+}
+
+func (d redshiftDialect) sessionID() string {
+	return "SELECT pg_backend_pid()"
+}
+
+func (d redshiftDialect) cancelSession(id int64) string {
+	return fmt.Sprintf("SELECT pg_cancel_backend(%d)", id)
+}
+
+func (d redshiftDialect) materializedView(name, query string, replace bool) []string {
+	return dropAndCreateMaterializedView(d, name, query, replace)
+}
+
+func (d redshiftDialect) latestValues(resourceTable string, since, until time.Time) string {
+	filter := ""
+	if !since.IsZero() {
+		filter = fmt.Sprintf(" AND ts > %s", timestampLiteral(since))
+	}
+	return fmt.Sprintf("SELECT entity, value, ts, row_number() over(ORDER BY (entity)) as row_number FROM ("+
+		"SELECT entity, value, ts, row_number() OVER (PARTITION BY entity ORDER BY entity, ts DESC) as rn "+
+		"FROM %s%s) WHERE rn=1%s ORDER BY entity", d.quote(resourceTable), untilFilter(until), filter)
+}
+
+func (d redshiftDialect) asOfJoin(labelTable string, features []asOfFeature) string {
+	columns := make([]string, len(features))
+	ranks := make([]string, len(features))
+	joins := ""
+	for i, feature := range features {
+		column := d.quote(feature.column)
+		alias := fmt.Sprintf("t%d", i+1)
+		columns[i] = column
+		ranks[i] = fmt.Sprintf("%s_rnk", alias)
+		joins = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value AS %s, ts, RANK() OVER (ORDER BY ts DESC) AS %s_rnk FROM %s ORDER BY ts desc) AS %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts)",
+			joins, column, alias, d.quote(feature.table), alias, alias, alias)
+	}
+	columnStr, rankStr := strings.Join(columns, ", "), strings.Join(ranks, ", ")
+	return fmt.Sprintf("SELECT %s, label FROM ("+
+		"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s DESC) AS rn FROM ( "+
+		"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM %s AS t0 %s )) WHERE rn=1",
+		columnStr, rankStr, columnStr, rankStr, d.quote(labelTable), joins)
+}
+
+// snowflakeDialect uses Snowflake's types. Snowflake tables are stored
+// without a time zone.
+type snowflakeDialect struct {
+	ansiDialect
+}
+
+func (d snowflakeDialect) columnType(valueType ValueType) (string, error) {
+	switch valueType {
+	case Int, Int32, Int64:
+		return "INT", nil
+	case Float32, Float64:
+		return "FLOAT8", nil
+	case Timestamp:
+		return "TIMESTAMP_NTZ", nil
+	case Bytes:
+		return "BINARY", nil
+	case Decimal:
+		// Snowflake's default scale is zero, which would drop the fraction.
+		return "NUMBER(38, 18)", nil
+	default:
+		return d.ansiDialect.columnType(valueType)
+	}
+}
+
+func (d snowflakeDialect) timestampType() string {
+	return "TIMESTAMP_NTZ"
+}
+
+func (d snowflakeDialect) epochTimestamp() string {
+	return fmt.Sprintf("to_timestamp_ntz('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMP_NTZ", time.UnixMilli(0).UTC())
+}
+
+func (d snowflakeDialect) backslashEscapes() bool {
+	return true
+}
+
+// statementTimeout is set in whole seconds, rounded up.
+func (d snowflakeDialect) statementTimeout(timeout time.Duration) string {
+	seconds := int64((timeout + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return fmt.Sprintf("ALTER SESSION SET STATEMENT_TIMEOUT_IN_SECONDS = %d", seconds)
+}
+
+func (d snowflakeDialect) sessionID() string {
+	return "SELECT CURRENT_SESSION()"
+}
+
+func (d snowflakeDialect) cancelSession(id int64) string {
+	return fmt.Sprintf("SELECT SYSTEM$CANCEL_ALL_QUERIES(%d)", id)
+}
+
+func (d snowflakeDialect) materializedView(name, query string, replace bool) []string {
+	if replace {
+		return []string{fmt.Sprintf("CREATE OR REPLACE MATERIALIZED VIEW %s AS %s", d.quote(name), query)}
+	}
+	return []string{fmt.Sprintf("CREATE MATERIALIZED VIEW %s AS %s", d.quote(name), query)}
+}
+
+// bigQueryDialect quotes identifiers with backticks, since BigQuery reads
+// double quotes as strings, and uses BigQuery's types.
+type bigQueryDialect struct {
+	ansiDialect
+}
+
+func newBigQueryDialect() bigQueryDialect {
+	return bigQueryDialect{ansiDialect{identQuote: '`'}}
+}
+
+func (d bigQueryDialect) backslashEscapes() bool {
+	return true
+}
+
+func (d bigQueryDialect) columnType(valueType ValueType) (string, error) {
+	switch valueType {
+	case Int, Int32, Int64:
+		return "INT64", nil
+	case Float32, Float64:
+		return "FLOAT64", nil
+	case String, NilType:
+		return "STRING", nil
+	case Bool:
+		return "BOOL", nil
+	case Timestamp:
+		return "TIMESTAMP", nil
+	case Bytes:
+		return "BYTES", nil
+	case Decimal:
+		return "BIGNUMERIC", nil
+	default:
+		return "", fmt.Errorf("cannot find column type for value type: %s", valueType)
+	}
+}
+
+func (d bigQueryDialect) epochTimestamp() string {
+	return "TIMESTAMP_MILLIS(0)"
+}
+
+// uniqueConstraint is empty since BigQuery doesn't enforce unique keys.
+func (d bigQueryDialect) uniqueConstraint(columns ...string) string {
+	return ""
+}
+
+// dropAndCreateMaterializedView is for warehouses that can't replace a
+// materialized view in place, whose view is dropped and created again. The
+// statements are run with replaceRelation, so that readers never find it
+// missing and the views built on it are kept.
+func dropAndCreateMaterializedView(d Dialect, name, query string, replace bool) []string {
+	create := fmt.Sprintf("CREATE MATERIALIZED VIEW %s AS %s", d.quote(name), query)
+	if !replace {
+		return []string{create}
+	}
+	return []string{fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s", d.quote(name)), create}
+}
+
+// transformationViewQuery registers a transformation's query as a view,
+// replacing the one named name if replace is set.
+func transformationViewQuery(d Dialect, name, query string, replace bool) string {
+	if replace {
+		return fmt.Sprintf("CREATE OR REPLACE VIEW %s AS %s", d.quote(name), query)
+	}
+	return fmt.Sprintf("CREATE VIEW %s AS %s", d.quote(name), query)
+}
+
+// resourceTableQuery creates a resource table, which holds the entity, value
+// and timestamp of each value of a feature or label.
+func resourceTableQuery(d Dialect, name string, valueType ValueType) (string, error) {
+	valueColumn, err := d.columnType(valueType)
+	if err != nil {
+		return "", err
+	}
+	entityColumn, err := d.columnType(String)
+	if err != nil {
+		return "", err
+	}
+	columns := []string{
+		"entity " + entityColumn,
+		"value " + valueColumn,
+		"ts " + d.timestampType(),
+	}
+	if unique := d.uniqueConstraint("entity", "ts"); unique != "" {
+		columns = append(columns, unique)
+	}
+	return fmt.Sprintf("CREATE TABLE %s (%s)", d.quote(name), strings.Join(columns, ", ")), nil
+}
+
+// resourceViewQuery registers a user's table as a resource table. The schema's
+// names must already be quoted. Without a timestamp column, every value is
+// given the epoch as its timestamp.
+func resourceViewQuery(d Dialect, tableName string, schema ResourceSchema) string {
+	if schema.ValidTo != "" {
+		return scd2ViewQuery(d, tableName, schema)
+	}
+	ts := schema.TS
+	if ts == "" {
+		ts = d.epochTimestamp()
+	}
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s",
+		d.quote(tableName), schema.Entity, schema.Value, ts, schema.SourceTable)
+}
+
+// scd2ViewQuery registers a slowly changing dimension table as a resource
+// table. Each row's value starts at its valid from time, and a row that
+// expires without another row for its entity starting at the same time ends
+// with a null value. So the latest value at a time is the row that was valid
+// then, for both training sets and materializations, and an entity whose
+// rows have all expired has no value.
+func scd2ViewQuery(d Dialect, tableName string, schema ResourceSchema) string {
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s "+
+		"UNION ALL SELECT expired.%s as entity, NULL as value, expired.%s as ts FROM %s expired "+
+		"WHERE expired.%s IS NOT NULL AND NOT EXISTS (SELECT 1 FROM %s next WHERE next.%s = expired.%s AND next.%s = expired.%s)",
+		d.quote(tableName), schema.Entity, schema.Value, schema.TS, schema.SourceTable,
+		schema.Entity, schema.ValidTo, schema.SourceTable,
+		schema.ValidTo, schema.SourceTable, schema.Entity, schema.Entity, schema.TS, schema.ValidTo)
+}
+
+// primaryTableQuery creates a primary table with schema's columns.
+func primaryTableQuery(d Dialect, name string, schema TableSchema) (string, error) {
+	columns := make([]string, len(schema.Columns))
+	for i, column := range schema.Columns {
+		columnType, err := d.columnType(column.ValueType)
+		if err != nil {
+			return "", err
+		}
+		columns[i] = fmt.Sprintf("%s %s", d.quote(column.Name), columnType)
+	}
+	return fmt.Sprintf("CREATE TABLE %s ( %s )", d.quote(name), strings.Join(columns, ", ")), nil
+}
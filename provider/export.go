@@ -0,0 +1,425 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/featureform/provider/tfexample"
+	"google.golang.org/protobuf/proto"
+)
+
+type ExportFormat string
+
+const (
+	ParquetExport  ExportFormat = "PARQUET"
+	CSVExport      ExportFormat = "CSV"
+	TFRecordExport ExportFormat = "TFRECORD"
+)
+
+const DefaultExportRowsPerFile = 1000000
+
+// TrainingSetExport is where a training set's rows are written as files, so
+// that ML pipelines can read them without going through the serving API.
+type TrainingSetExport struct {
+	// URI is an archive URI, such as s3://bucket/prefix, gs://bucket/prefix
+	// or file:///data/exports. Each training set is written under
+	// <name>/<variant> in it.
+	URI    string
+	Format ExportFormat
+	// RowsPerFile defaults to DefaultExportRowsPerFile.
+	RowsPerFile int `json:",omitempty"`
+}
+
+func (export TrainingSetExport) extension() (string, error) {
+	switch export.Format {
+	case ParquetExport:
+		return "parquet", nil
+	case CSVExport:
+		return "csv", nil
+	case TFRecordExport:
+		return "tfrecord", nil
+	default:
+		return "", fmt.Errorf("unknown export format %q", export.Format)
+	}
+}
+
+// exportTypeSampleRows is how many rows are read before column types are
+// taken from them, if some columns are still only nil by then.
+const exportTypeSampleRows = 1000
+
+// ExportTrainingSet writes every row of a training set to archiver as files
+// of export's format, and returns their keys. Feature columns are named
+// <name>__<variant>, like spooled training data, followed by a label column.
+// Column types are taken from the first rows. Rows are encoded into a
+// temporary file as they're read, so only one file's worth of rows is held
+// at a time, on disk.
+func ExportTrainingSet(ctx context.Context, archiver Archiver, export TrainingSetExport, def TrainingSetDef, iter TrainingSetIterator) ([]string, error) {
+	ext, err := export.extension()
+	if err != nil {
+		return nil, err
+	}
+	rowsPerFile := export.RowsPerFile
+	if rowsPerFile <= 0 {
+		rowsPerFile = DefaultExportRowsPerFile
+	}
+	columns := make([]ParquetColumn, len(def.Features)+1)
+	for i, feature := range def.Features {
+		columns[i] = ParquetColumn{Name: fmt.Sprintf("%s__%s", feature.Name, feature.Variant)}
+	}
+	columns[len(def.Features)] = ParquetColumn{Name: "label"}
+	exporter := &trainingSetExporter{
+		ctx:         ctx,
+		archiver:    archiver,
+		format:      export.Format,
+		ext:         ext,
+		dir:         path.Join(def.ID.Name, def.ID.Variant),
+		columns:     columns,
+		rowsPerFile: rowsPerFile,
+	}
+	defer exporter.abort()
+	// Rows are held back until the column types are known.
+	sample := make([][]interface{}, 0)
+	for iter.Next() {
+		features := iter.Features()
+		if len(features) != len(def.Features) {
+			return nil, fmt.Errorf("training set row has %d features, expected %d", len(features), len(def.Features))
+		}
+		row := make([]interface{}, 0, len(columns))
+		row = append(append(row, features...), iter.Label())
+		if sample == nil {
+			if err := exporter.write(row); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		sample = append(sample, row)
+		if len(sample) < exportTypeSampleRows && !allColumnsSet(columns, sample) {
+			continue
+		}
+		if err := exporter.writeSample(sample); err != nil {
+			return nil, err
+		}
+		sample = nil
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("read training set: %w", err)
+	}
+	if sample != nil {
+		if err := exporter.writeSample(sample); err != nil {
+			return nil, err
+		}
+	}
+	return exporter.finish()
+}
+
+// trainingSetExporter writes a training set's rows to files of at most
+// rowsPerFile rows, archiving each one once it's full.
+type trainingSetExporter struct {
+	ctx         context.Context
+	archiver    Archiver
+	format      ExportFormat
+	ext         string
+	dir         string
+	columns     []ParquetColumn
+	rowsPerFile int
+	keys        []string
+	file        *os.File
+	buffered    *bufio.Writer
+	writer      exportWriter
+	rows        int
+}
+
+func (e *trainingSetExporter) writeSample(sample [][]interface{}) error {
+	inferColumnTypes(e.columns, sample)
+	for _, row := range sample {
+		if err := e.write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *trainingSetExporter) write(row []interface{}) error {
+	if e.file == nil {
+		if err := e.open(); err != nil {
+			return err
+		}
+	}
+	if err := e.writer.Write(row); err != nil {
+		return fmt.Errorf("write %s row: %w", e.format, err)
+	}
+	if e.rows++; e.rows >= e.rowsPerFile {
+		return e.archive()
+	}
+	return nil
+}
+
+func (e *trainingSetExporter) open() error {
+	file, err := os.CreateTemp("", "featureform-export-*."+e.ext)
+	if err != nil {
+		return fmt.Errorf("create export file: %w", err)
+	}
+	e.file = file
+	e.buffered = bufio.NewWriter(file)
+	if e.writer, err = newExportWriter(e.buffered, e.format, e.columns); err != nil {
+		return err
+	}
+	e.rows = 0
+	return nil
+}
+
+// archive finishes the current file and writes it to the archiver.
+func (e *trainingSetExporter) archive() error {
+	defer e.abort()
+	if err := e.writer.Close(); err != nil {
+		return fmt.Errorf("finish %s file: %w", e.format, err)
+	}
+	if err := e.buffered.Flush(); err != nil {
+		return fmt.Errorf("write export file: %w", err)
+	}
+	key := path.Join(e.dir, fmt.Sprintf("part-%05d.%s", len(e.keys), e.ext))
+	if err := ArchiveFile(e.ctx, e.archiver, key, e.file); err != nil {
+		return fmt.Errorf("write %s: %w", key, err)
+	}
+	e.keys = append(e.keys, key)
+	return nil
+}
+
+// finish archives the last file. An empty training set is still written as
+// one file, so that readers find its columns.
+func (e *trainingSetExporter) finish() ([]string, error) {
+	if e.file == nil && len(e.keys) == 0 {
+		if err := e.open(); err != nil {
+			return nil, err
+		}
+	}
+	if e.file != nil {
+		if err := e.archive(); err != nil {
+			return nil, err
+		}
+	}
+	return e.keys, nil
+}
+
+// abort removes the current file, if there is one.
+func (e *trainingSetExporter) abort() {
+	if e.file == nil {
+		return
+	}
+	e.file.Close()
+	os.Remove(e.file.Name())
+	e.file = nil
+}
+
+func allColumnsSet(columns []ParquetColumn, rows [][]interface{}) bool {
+	for i := range columns {
+		set := false
+		for _, row := range rows {
+			if i < len(row) && row[i] != nil {
+				set = true
+				break
+			}
+		}
+		if !set {
+			return false
+		}
+	}
+	return true
+}
+
+// inferColumnTypes sets the type of each column from its first value that
+// isn't nil. Columns with only nil values are strings.
+func inferColumnTypes(columns []ParquetColumn, rows [][]interface{}) {
+	for i := range columns {
+		columns[i].Type = String
+		for _, row := range rows {
+			if i < len(row) && row[i] != nil {
+				columns[i].Type = valueTypeOf(row[i])
+				break
+			}
+		}
+	}
+}
+
+func valueTypeOf(value interface{}) ValueType {
+	switch value.(type) {
+	case int:
+		return Int
+	case int32:
+		return Int32
+	case int64:
+		return Int64
+	case float32:
+		return Float32
+	case float64:
+		return Float64
+	case bool:
+		return Bool
+	case time.Time:
+		return Timestamp
+	case []byte:
+		return Bytes
+	default:
+		return String
+	}
+}
+
+// exportWriter encodes rows in an export format as they're written.
+type exportWriter interface {
+	Write(row []interface{}) error
+	// Close finishes the encoding without closing the underlying writer.
+	Close() error
+}
+
+func newExportWriter(w io.Writer, format ExportFormat, columns []ParquetColumn) (exportWriter, error) {
+	switch format {
+	case ParquetExport:
+		return NewParquetWriter(w, columns, 0)
+	case CSVExport:
+		return newCSVWriter(w, columns)
+	case TFRecordExport:
+		return &tfRecordWriter{w: w, columns: columns}, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+// csvWriter writes rows as CSV with a header of column names. Nil values are
+// empty and timestamps are RFC 3339.
+type csvWriter struct {
+	writer *csv.Writer
+	record []string
+}
+
+func newCSVWriter(w io.Writer, columns []ParquetColumn) (*csvWriter, error) {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: cw, record: make([]string, len(columns))}, nil
+}
+
+func (c *csvWriter) Write(row []interface{}) error {
+	for i := range c.record {
+		c.record[i] = ""
+		if i >= len(row) || row[i] == nil {
+			continue
+		}
+		switch value := row[i].(type) {
+		case time.Time:
+			c.record[i] = value.UTC().Format(time.RFC3339Nano)
+		case []byte:
+			c.record[i] = string(value)
+		default:
+			c.record[i] = fmt.Sprint(value)
+		}
+	}
+	return c.writer.Write(c.record)
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+var crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+// maskedCRC is the checksum TFRecord files store, which is masked so that
+// checksums of data holding checksums stay robust.
+func maskedCRC(data []byte) uint32 {
+	crc := crc32.Checksum(data, crc32c)
+	return ((crc >> 15) | (crc << 17)) + 0xa282ead8
+}
+
+// tfRecordWriter writes each row as a tf.train.Example record of a TFRecord
+// file, with a feature for each column. Integers, booleans and timestamps, as
+// microseconds since the epoch, are int64 lists, floats are float lists, and
+// everything else is a bytes list. Nil values are left out of their row's
+// example.
+type tfRecordWriter struct {
+	w       io.Writer
+	columns []ParquetColumn
+}
+
+func (t *tfRecordWriter) Write(row []interface{}) error {
+	features := make(map[string]*tfexample.Feature, len(t.columns))
+	for i, col := range t.columns {
+		if i < len(row) && row[i] != nil {
+			features[col.Name] = tfFeature(row[i])
+		}
+	}
+	example, err := proto.MarshalOptions{Deterministic: true}.Marshal(&tfexample.Example{
+		Features: &tfexample.Features{Feature: features},
+	})
+	if err != nil {
+		return err
+	}
+	var header [12]byte
+	var footer [4]byte
+	binary.LittleEndian.PutUint64(header[:8], uint64(len(example)))
+	binary.LittleEndian.PutUint32(header[8:], maskedCRC(header[:8]))
+	binary.LittleEndian.PutUint32(footer[:], maskedCRC(example))
+	for _, part := range [][]byte{header[:], example, footer[:]} {
+		if _, err := t.w.Write(part); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *tfRecordWriter) Close() error {
+	return nil
+}
+
+// tfFeature returns a value as a tf.train.Feature holding a list of one
+// value.
+func tfFeature(value interface{}) *tfexample.Feature {
+	switch v := value.(type) {
+	case int, int32, int64, bool, time.Time:
+		return &tfexample.Feature{Kind: &tfexample.Feature_Int64List{Int64List: &tfexample.Int64List{Value: []int64{tfInt64(v)}}}}
+	case float32:
+		return &tfexample.Feature{Kind: &tfexample.Feature_FloatList{FloatList: &tfexample.FloatList{Value: []float32{v}}}}
+	case float64:
+		return &tfexample.Feature{Kind: &tfexample.Feature_FloatList{FloatList: &tfexample.FloatList{Value: []float32{float32(v)}}}}
+	case []byte:
+		return &tfexample.Feature{Kind: &tfexample.Feature_BytesList{BytesList: &tfexample.BytesList{Value: [][]byte{v}}}}
+	default:
+		return &tfexample.Feature{Kind: &tfexample.Feature_BytesList{BytesList: &tfexample.BytesList{Value: [][]byte{[]byte(fmt.Sprint(v))}}}}
+	}
+}
+
+func tfInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int64:
+		return v
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case time.Time:
+		return v.UnixMicro()
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,56 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"time"
+)
+
+// The time each feature was last written is kept in a table in the online
+// store itself, so it describes the values that store actually serves.
+const (
+	lastWrittenTableName    = "featureform_last_written"
+	lastWrittenTableVariant = "feature"
+)
+
+func lastWrittenKey(feature, variant string) string {
+	return fmt.Sprintf("%s__%s", feature, variant)
+}
+
+// SetLastWritten records that the online values of a feature were written at
+// t.
+func SetLastWritten(store OnlineStore, feature, variant string, t time.Time) error {
+	table, err := store.CreateTable(lastWrittenTableName, lastWrittenTableVariant, String)
+	if _, exists := err.(*TableAlreadyExists); exists {
+		table, err = store.GetTable(lastWrittenTableName, lastWrittenTableVariant)
+	}
+	if err != nil {
+		return fmt.Errorf("get last written table: %w", err)
+	}
+	return table.Set(lastWrittenKey(feature, variant), t.UTC().Format(time.RFC3339Nano))
+}
+
+// LastWritten returns when the online values of a feature were last written,
+// or the zero time if that was never recorded.
+func LastWritten(store OnlineStore, feature, variant string) (time.Time, error) {
+	table, err := store.GetTable(lastWrittenTableName, lastWrittenTableVariant)
+	if _, notFound := err.(*TableNotFound); notFound {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, fmt.Errorf("get last written table: %w", err)
+	}
+	value, err := table.Get(lastWrittenKey(feature, variant))
+	if _, notFound := err.(*EntityNotFound); notFound || value == nil {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, fmt.Errorf("get last written time: %w", err)
+	}
+	written, err := time.Parse(time.RFC3339Nano, fmt.Sprint(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid last written time %v: %w", value, err)
+	}
+	return written, nil
+}
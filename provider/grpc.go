@@ -0,0 +1,720 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	pb "github.com/featureform/provider/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	tspb "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GenericGRPC providers forward the store interfaces to a server that
+// implements the CustomProvider service in provider/proto, so that storage
+// backends can be added without changing this package. The server is usually
+// a sidecar; see NewCustomProviderServer to write one in Go.
+const GenericGRPC Type = "GENERIC_GRPC"
+
+func init() {
+	if err := RegisterFactory(GenericGRPC, genericGRPCProviderFactory); err != nil {
+		panic(err)
+	}
+}
+
+type GenericGRPCConfig struct {
+	Address string
+}
+
+func (c GenericGRPCConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(c)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (c *GenericGRPCConfig) Deserialize(config SerializedConfig) error {
+	return json.Unmarshal(config, c)
+}
+
+type genericGRPCProvider struct {
+	client       pb.CustomProviderClient
+	capabilities *pb.ProviderCapabilities
+	BaseProvider
+}
+
+func genericGRPCProviderFactory(serialized SerializedConfig) (Provider, error) {
+	config := &GenericGRPCConfig{}
+	if err := config.Deserialize(serialized); err != nil {
+		return nil, fmt.Errorf("invalid generic gRPC config: %w", err)
+	}
+	conn, err := grpc.Dial(config.Address, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("connect to custom provider at %s: %w", config.Address, err)
+	}
+	client := pb.NewCustomProviderClient(conn)
+	capabilities, err := client.Capabilities(context.Background(), &pb.Empty{})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("get custom provider capabilities: %w", err)
+	}
+	return &genericGRPCProvider{
+		client:       client,
+		capabilities: capabilities,
+		BaseProvider: BaseProvider{
+			ProviderType:   GenericGRPC,
+			ProviderConfig: serialized,
+		},
+	}, nil
+}
+
+// CheckHealth asks the custom provider for its capabilities, which the
+// server only answers once its own backing store is healthy.
+func (p *genericGRPCProvider) CheckHealth(ctx context.Context) error {
+	_, err := p.client.Capabilities(ctx, &pb.Empty{})
+	return err
+}
+
+func (p *genericGRPCProvider) AsOnlineStore() (OnlineStore, error) {
+	if !p.capabilities.Online {
+		return nil, fmt.Errorf("custom provider cannot be used as an OnlineStore")
+	}
+	return &grpcOnlineStore{p}, nil
+}
+
+func (p *genericGRPCProvider) AsOfflineStore() (OfflineStore, error) {
+	if !p.capabilities.Offline {
+		return nil, fmt.Errorf("custom provider cannot be used as an OfflineStore")
+	}
+	return &grpcOfflineStore{p}, nil
+}
+
+// fromStatus turns the status codes in the contract back into this package's
+// error types.
+func fromStatus(err error, notFound, exists error) error {
+	if err == nil {
+		return nil
+	}
+	switch status.Code(err) {
+	case codes.NotFound:
+		if notFound != nil {
+			return notFound
+		}
+	case codes.AlreadyExists:
+		if exists != nil {
+			return exists
+		}
+	}
+	return fmt.Errorf("custom provider: %s", status.Convert(err).Message())
+}
+
+type grpcOnlineStore struct {
+	*genericGRPCProvider
+}
+
+func (store *grpcOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
+	req := &pb.OnlineTableRequest{Name: feature, Variant: variant, ValueType: string(valueType)}
+	if _, err := store.client.CreateOnlineTable(context.Background(), req); err != nil {
+		return nil, fromStatus(err, nil, &TableAlreadyExists{feature, variant})
+	}
+	return &grpcOnlineTable{store.client, feature, variant}, nil
+}
+
+func (store *grpcOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
+	req := &pb.OnlineTableRequest{Name: feature, Variant: variant}
+	if _, err := store.client.GetOnlineTable(context.Background(), req); err != nil {
+		return nil, fromStatus(err, &TableNotFound{feature, variant}, nil)
+	}
+	return &grpcOnlineTable{store.client, feature, variant}, nil
+}
+
+func (store *grpcOnlineStore) DeleteTable(feature, variant string) error {
+	req := &pb.OnlineTableRequest{Name: feature, Variant: variant}
+	_, err := store.client.DeleteOnlineTable(context.Background(), req)
+	return fromStatus(err, &TableNotFound{feature, variant}, nil)
+}
+
+type grpcOnlineTable struct {
+	client           pb.CustomProviderClient
+	feature, variant string
+}
+
+func (table *grpcOnlineTable) Set(entity string, value interface{}) error {
+	serialized, err := serializeValue(value)
+	if err != nil {
+		return err
+	}
+	req := &pb.OnlineValueRequest{Name: table.feature, Variant: table.variant, Entity: entity, Value: serialized}
+	_, err = table.client.SetOnlineValue(context.Background(), req)
+	return fromStatus(err, &TableNotFound{table.feature, table.variant}, nil)
+}
+
+func (table *grpcOnlineTable) Get(entity string) (interface{}, error) {
+	req := &pb.OnlineValueRequest{Name: table.feature, Variant: table.variant, Entity: entity}
+	value, err := table.client.GetOnlineValue(context.Background(), req)
+	if err != nil {
+		return nil, fromStatus(err, &EntityNotFound{entity}, nil)
+	}
+	return deserializeValue(value), nil
+}
+
+type grpcOfflineStore struct {
+	*genericGRPCProvider
+}
+
+func (store *grpcOfflineStore) RegisterResourceFromSourceTable(id ResourceID, schema ResourceSchema) (OfflineTable, error) {
+	req := &pb.RegisterResourceRequest{
+		Id:          serializeResourceID(id),
+		Entity:      schema.Entity,
+		Value:       schema.Value,
+		Ts:          schema.TS,
+		SourceTable: schema.SourceTable,
+		ValidTo:     schema.ValidTo,
+	}
+	if _, err := store.client.RegisterResourceFromSourceTable(context.Background(), req); err != nil {
+		return nil, fromStatus(err, nil, &TableAlreadyExists{id.Name, id.Variant})
+	}
+	return &grpcOfflineTable{store.client, id}, nil
+}
+
+func (store *grpcOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, sourceName string) (PrimaryTable, error) {
+	req := &pb.RegisterPrimaryRequest{Id: serializeResourceID(id), SourceName: sourceName}
+	info, err := store.client.RegisterPrimaryFromSourceTable(context.Background(), req)
+	if err != nil {
+		return nil, fromStatus(err, nil, &TableAlreadyExists{id.Name, id.Variant})
+	}
+	return &grpcPrimaryTable{store.client, id, info.Name}, nil
+}
+
+func (store *grpcOfflineStore) CreateTransformation(config TransformationConfig) error {
+	_, err := store.client.CreateTransformation(context.Background(), serializeTransformation(config))
+	id := config.TargetTableID
+	return fromStatus(err, nil, &TableAlreadyExists{id.Name, id.Variant})
+}
+
+func (store *grpcOfflineStore) UpdateTransformation(config TransformationConfig) error {
+	_, err := store.client.UpdateTransformation(context.Background(), serializeTransformation(config))
+	id := config.TargetTableID
+	return fromStatus(err, &TableNotFound{id.Name, id.Variant}, nil)
+}
+
+func (store *grpcOfflineStore) GetTransformationTable(id ResourceID) (TransformationTable, error) {
+	info, err := store.client.GetTransformationTable(context.Background(), serializeResourceID(id))
+	if err != nil {
+		return nil, fromStatus(err, &TableNotFound{id.Name, id.Variant}, nil)
+	}
+	id.Type = Transformation
+	return &grpcPrimaryTable{store.client, id, info.Name}, nil
+}
+
+func (store *grpcOfflineStore) CreatePrimaryTable(id ResourceID, schema TableSchema) (PrimaryTable, error) {
+	req := &pb.CreateTableRequest{Id: serializeResourceID(id), Columns: serializeColumns(schema)}
+	info, err := store.client.CreatePrimaryTable(context.Background(), req)
+	if err != nil {
+		return nil, fromStatus(err, nil, &TableAlreadyExists{id.Name, id.Variant})
+	}
+	return &grpcPrimaryTable{store.client, id, info.Name}, nil
+}
+
+func (store *grpcOfflineStore) GetPrimaryTable(id ResourceID) (PrimaryTable, error) {
+	info, err := store.client.GetPrimaryTable(context.Background(), serializeResourceID(id))
+	if err != nil {
+		return nil, fromStatus(err, &TableNotFound{id.Name, id.Variant}, nil)
+	}
+	return &grpcPrimaryTable{store.client, id, info.Name}, nil
+}
+
+func (store *grpcOfflineStore) CreateResourceTable(id ResourceID, schema TableSchema) (OfflineTable, error) {
+	req := &pb.CreateTableRequest{Id: serializeResourceID(id), Columns: serializeColumns(schema)}
+	if _, err := store.client.CreateResourceTable(context.Background(), req); err != nil {
+		return nil, fromStatus(err, nil, &TableAlreadyExists{id.Name, id.Variant})
+	}
+	return &grpcOfflineTable{store.client, id}, nil
+}
+
+func (store *grpcOfflineStore) GetResourceTable(id ResourceID) (OfflineTable, error) {
+	if _, err := store.client.GetResourceTable(context.Background(), serializeResourceID(id)); err != nil {
+		return nil, fromStatus(err, &TableNotFound{id.Name, id.Variant}, nil)
+	}
+	return &grpcOfflineTable{store.client, id}, nil
+}
+
+func (store *grpcOfflineStore) CreateMaterialization(id ResourceID) (Materialization, error) {
+	return store.materialize(&pb.MaterializeRequest{Id: serializeResourceID(id)}, id)
+}
+
+func (store *grpcOfflineStore) CreateIncrementalMaterialization(id ResourceID, since time.Time) (Materialization, error) {
+	return store.materialize(&pb.MaterializeRequest{Id: serializeResourceID(id), Since: tspb.New(since)}, id)
+}
+
+func (store *grpcOfflineStore) materialize(req *pb.MaterializeRequest, id ResourceID) (Materialization, error) {
+	matID, err := store.client.CreateMaterialization(context.Background(), req)
+	if err != nil {
+		return nil, fromStatus(err, &TableNotFound{id.Name, id.Variant}, materializationExists(err))
+	}
+	return &grpcMaterialization{store.client, MaterializationID(matID.Id)}, nil
+}
+
+// materializationExists returns the MaterializationExists error that the
+// server sent the ID of, or nil if it didn't send one.
+func materializationExists(err error) error {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ResourceInfo); ok && info.ResourceType == "materialization" {
+			return &MaterializationExists{MaterializationID(info.ResourceName)}
+		}
+	}
+	return nil
+}
+
+func (store *grpcOfflineStore) GetMaterialization(id MaterializationID) (Materialization, error) {
+	if _, err := store.client.GetMaterialization(context.Background(), &pb.MaterializationID{Id: string(id)}); err != nil {
+		return nil, fromStatus(err, &MaterializationNotFound{id}, nil)
+	}
+	return &grpcMaterialization{store.client, id}, nil
+}
+
+func (store *grpcOfflineStore) UpdateMaterialization(id ResourceID) (Materialization, error) {
+	matID, err := store.client.UpdateMaterialization(context.Background(), serializeResourceID(id))
+	if err != nil {
+		return nil, fromStatus(err, &TableNotFound{id.Name, id.Variant}, nil)
+	}
+	return &grpcMaterialization{store.client, MaterializationID(matID.Id)}, nil
+}
+
+func (store *grpcOfflineStore) DeleteMaterialization(id MaterializationID) error {
+	_, err := store.client.DeleteMaterialization(context.Background(), &pb.MaterializationID{Id: string(id)})
+	return fromStatus(err, &MaterializationNotFound{id}, nil)
+}
+
+func (store *grpcOfflineStore) DeleteTable(id ResourceID) error {
+	_, err := store.client.DeleteTable(context.Background(), serializeResourceID(id))
+	var notFound error = &TableNotFound{id.Name, id.Variant}
+	if id.Type == TrainingSet {
+		notFound = &TrainingSetNotFound{id}
+	}
+	return fromStatus(err, notFound, nil)
+}
+
+func (store *grpcOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
+	_, err := store.client.CreateTrainingSet(context.Background(), serializeTrainingSetDef(def))
+	return fromStatus(err, nil, &TableAlreadyExists{def.ID.Name, def.ID.Variant})
+}
+
+func (store *grpcOfflineStore) UpdateTrainingSet(def TrainingSetDef) error {
+	_, err := store.client.UpdateTrainingSet(context.Background(), serializeTrainingSetDef(def))
+	return fromStatus(err, &TrainingSetNotFound{def.ID}, nil)
+}
+
+// GetTrainingSet waits for the first row, so that a missing training set is
+// reported here rather than by the iterator.
+func (store *grpcOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator, error) {
+	stream, err := store.client.GetTrainingSet(context.Background(), serializeResourceID(id))
+	if err != nil {
+		return nil, fromStatus(err, &TrainingSetNotFound{id}, nil)
+	}
+	first, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		return nil, fromStatus(err, &TrainingSetNotFound{id}, nil)
+	}
+	return &grpcTrainingSetIterator{stream: stream, next: first, done: err == io.EOF}, nil
+}
+
+type grpcOfflineTable struct {
+	client pb.CustomProviderClient
+	id     ResourceID
+}
+
+func (table *grpcOfflineTable) Write(rec ResourceRecord) error {
+	serialized, err := serializeResourceRecord(rec)
+	if err != nil {
+		return err
+	}
+	req := &pb.ResourceRecordRequest{Id: serializeResourceID(table.id), Record: serialized}
+	_, err = table.client.WriteResourceRecord(context.Background(), req)
+	return fromStatus(err, &TableNotFound{table.id.Name, table.id.Variant}, nil)
+}
+
+type grpcPrimaryTable struct {
+	client pb.CustomProviderClient
+	id     ResourceID
+	name   string
+}
+
+func (table *grpcPrimaryTable) GetName() string {
+	return table.name
+}
+
+func (table *grpcPrimaryTable) Write(rec GenericRecord) error {
+	values, err := serializeValues(rec)
+	if err != nil {
+		return err
+	}
+	req := &pb.PrimaryRecordRequest{Id: serializeResourceID(table.id), Values: values}
+	_, err = table.client.WritePrimaryRecord(context.Background(), req)
+	return fromStatus(err, &TableNotFound{table.id.Name, table.id.Variant}, nil)
+}
+
+func (table *grpcPrimaryTable) IterateSegment(n int64) (GenericTableIterator, error) {
+	req := &pb.IterateTableRequest{Id: serializeResourceID(table.id), Limit: n}
+	stream, err := table.client.IteratePrimaryTable(context.Background(), req)
+	if err != nil {
+		return nil, fromStatus(err, &TableNotFound{table.id.Name, table.id.Variant}, nil)
+	}
+	return &grpcGenericTableIterator{stream: stream}, nil
+}
+
+func (table *grpcPrimaryTable) NumRows() (int64, error) {
+	n, err := table.client.PrimaryTableNumRows(context.Background(), serializeResourceID(table.id))
+	if err != nil {
+		return 0, fromStatus(err, &TableNotFound{table.id.Name, table.id.Variant}, nil)
+	}
+	return n.Rows, nil
+}
+
+func (table *grpcPrimaryTable) Stats() (TableStats, error) {
+	stats, err := table.client.PrimaryTableStats(context.Background(), serializeResourceID(table.id))
+	if err != nil {
+		return TableStats{}, fromStatus(err, &TableNotFound{table.id.Name, table.id.Variant}, nil)
+	}
+	return deserializeTableStats(stats), nil
+}
+
+type grpcMaterialization struct {
+	client pb.CustomProviderClient
+	id     MaterializationID
+}
+
+func (mat *grpcMaterialization) ID() MaterializationID {
+	return mat.id
+}
+
+func (mat *grpcMaterialization) NumRows() (int64, error) {
+	n, err := mat.client.MaterializationNumRows(context.Background(), &pb.MaterializationID{Id: string(mat.id)})
+	if err != nil {
+		return 0, fromStatus(err, &MaterializationNotFound{mat.id}, nil)
+	}
+	return n.Rows, nil
+}
+
+func (mat *grpcMaterialization) IterateSegment(begin, end int64) (FeatureIterator, error) {
+	req := &pb.IterateMaterializationRequest{Id: string(mat.id), Begin: begin, End: end}
+	stream, err := mat.client.IterateMaterialization(context.Background(), req)
+	if err != nil {
+		return nil, fromStatus(err, &MaterializationNotFound{mat.id}, nil)
+	}
+	return &grpcFeatureIterator{stream: stream}, nil
+}
+
+func (mat *grpcMaterialization) Stats() (TableStats, error) {
+	stats, err := mat.client.MaterializationStats(context.Background(), &pb.MaterializationID{Id: string(mat.id)})
+	if err != nil {
+		return TableStats{}, fromStatus(err, &MaterializationNotFound{mat.id}, nil)
+	}
+	return deserializeTableStats(stats), nil
+}
+
+type grpcFeatureIterator struct {
+	stream pb.CustomProvider_IterateMaterializationClient
+	cur    ResourceRecord
+	err    error
+}
+
+func (it *grpcFeatureIterator) Next() bool {
+	rec, err := it.stream.Recv()
+	if err == io.EOF {
+		return false
+	} else if err != nil {
+		it.err = fromStatus(err, nil, nil)
+		return false
+	}
+	it.cur = deserializeResourceRecord(rec)
+	return true
+}
+
+func (it *grpcFeatureIterator) Value() ResourceRecord {
+	return it.cur
+}
+
+func (it *grpcFeatureIterator) Err() error {
+	return it.err
+}
+
+type grpcGenericTableIterator struct {
+	stream  pb.CustomProvider_IteratePrimaryTableClient
+	values  GenericRecord
+	columns []string
+	err     error
+}
+
+func (it *grpcGenericTableIterator) Next() bool {
+	row, err := it.stream.Recv()
+	if err == io.EOF {
+		return false
+	} else if err != nil {
+		it.err = fromStatus(err, nil, nil)
+		return false
+	}
+	it.columns = row.Columns
+	it.values = deserializeValues(row.Values)
+	return true
+}
+
+func (it *grpcGenericTableIterator) Values() GenericRecord {
+	return it.values
+}
+
+func (it *grpcGenericTableIterator) Columns() []string {
+	return it.columns
+}
+
+func (it *grpcGenericTableIterator) Err() error {
+	return it.err
+}
+
+type grpcTrainingSetIterator struct {
+	stream   pb.CustomProvider_GetTrainingSetClient
+	next     *pb.TrainingSetRow
+	done     bool
+	features []interface{}
+	label    interface{}
+	err      error
+}
+
+func (it *grpcTrainingSetIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	row := it.next
+	next, err := it.stream.Recv()
+	if err == io.EOF {
+		it.done = true
+	} else if err != nil {
+		it.err = fromStatus(err, nil, nil)
+		it.done = true
+		return false
+	}
+	it.next = next
+	it.features = deserializeValues(row.Features)
+	it.label = deserializeValue(row.Label)
+	return true
+}
+
+func (it *grpcTrainingSetIterator) Features() []interface{} {
+	return it.features
+}
+
+func (it *grpcTrainingSetIterator) Label() interface{} {
+	return it.label
+}
+
+func (it *grpcTrainingSetIterator) Err() error {
+	return it.err
+}
+
+func serializeValue(value interface{}) (*pb.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return &pb.Value{}, nil
+	case string:
+		return &pb.Value{Value: &pb.Value_StrValue{StrValue: v}}, nil
+	case int:
+		return &pb.Value{Value: &pb.Value_IntValue{IntValue: int64(v)}}, nil
+	case int32:
+		return &pb.Value{Value: &pb.Value_Int32Value{Int32Value: v}}, nil
+	case int64:
+		return &pb.Value{Value: &pb.Value_Int64Value{Int64Value: v}}, nil
+	case float32:
+		return &pb.Value{Value: &pb.Value_Float32Value{Float32Value: v}}, nil
+	case float64:
+		return &pb.Value{Value: &pb.Value_Float64Value{Float64Value: v}}, nil
+	case bool:
+		return &pb.Value{Value: &pb.Value_BoolValue{BoolValue: v}}, nil
+	case time.Time:
+		return &pb.Value{Value: &pb.Value_TimestampValue{TimestampValue: tspb.New(v)}}, nil
+	case []byte:
+		return &pb.Value{Value: &pb.Value_BytesValue{BytesValue: v}}, nil
+	case DecimalValue:
+		return &pb.Value{Value: &pb.Value_DecimalValue{DecimalValue: string(v)}}, nil
+	default:
+		return nil, fmt.Errorf("custom providers do not support values of type %T", value)
+	}
+}
+
+func deserializeValue(value *pb.Value) interface{} {
+	switch v := value.GetValue().(type) {
+	case *pb.Value_StrValue:
+		return v.StrValue
+	case *pb.Value_IntValue:
+		return int(v.IntValue)
+	case *pb.Value_Int32Value:
+		return v.Int32Value
+	case *pb.Value_Int64Value:
+		return v.Int64Value
+	case *pb.Value_Float32Value:
+		return v.Float32Value
+	case *pb.Value_Float64Value:
+		return v.Float64Value
+	case *pb.Value_BoolValue:
+		return v.BoolValue
+	case *pb.Value_TimestampValue:
+		return v.TimestampValue.AsTime()
+	case *pb.Value_BytesValue:
+		return v.BytesValue
+	case *pb.Value_DecimalValue:
+		return DecimalValue(v.DecimalValue)
+	default:
+		return nil
+	}
+}
+
+func serializeValues(values []interface{}) ([]*pb.Value, error) {
+	serialized := make([]*pb.Value, len(values))
+	for i, value := range values {
+		var err error
+		if serialized[i], err = serializeValue(value); err != nil {
+			return nil, err
+		}
+	}
+	return serialized, nil
+}
+
+func deserializeValues(values []*pb.Value) []interface{} {
+	deserialized := make([]interface{}, len(values))
+	for i, value := range values {
+		deserialized[i] = deserializeValue(value)
+	}
+	return deserialized
+}
+
+func serializeResourceID(id ResourceID) *pb.ResourceID {
+	return &pb.ResourceID{Name: id.Name, Variant: id.Variant, Type: int32(id.Type)}
+}
+
+func deserializeResourceID(id *pb.ResourceID) ResourceID {
+	return ResourceID{Name: id.GetName(), Variant: id.GetVariant(), Type: OfflineResourceType(id.GetType())}
+}
+
+func serializeColumns(schema TableSchema) []*pb.Column {
+	columns := make([]*pb.Column, len(schema.Columns))
+	for i, col := range schema.Columns {
+		columns[i] = &pb.Column{Name: col.Name, ValueType: string(col.ValueType)}
+	}
+	return columns
+}
+
+func deserializeColumns(columns []*pb.Column) TableSchema {
+	schema := TableSchema{Columns: make([]TableColumn, len(columns))}
+	for i, col := range columns {
+		schema.Columns[i] = TableColumn{Name: col.Name, ValueType: ValueType(col.ValueType)}
+	}
+	return schema
+}
+
+func serializeResourceRecord(rec ResourceRecord) (*pb.ResourceRecord, error) {
+	value, err := serializeValue(rec.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.ResourceRecord{Entity: rec.Entity, Value: value, Ts: tspb.New(rec.TS)}, nil
+}
+
+func deserializeResourceRecord(rec *pb.ResourceRecord) ResourceRecord {
+	return ResourceRecord{Entity: rec.Entity, Value: deserializeValue(rec.Value), TS: rec.Ts.AsTime()}
+}
+
+func serializeTransformation(config TransformationConfig) *pb.TransformationRequest {
+	mapping := make([]*pb.ColumnMapping, len(config.ColumnMapping))
+	for i, m := range config.ColumnMapping {
+		mapping[i] = &pb.ColumnMapping{SourceColumn: m.sourceColumn, ResourceColumn: string(m.resourceColumn)}
+	}
+	return &pb.TransformationRequest{
+		Target:        serializeResourceID(config.TargetTableID),
+		Query:         config.Query,
+		ColumnMapping: mapping,
+		Storage:       string(config.Storage),
+	}
+}
+
+func deserializeTransformation(req *pb.TransformationRequest) TransformationConfig {
+	mapping := make([]ColumnMapping, len(req.ColumnMapping))
+	for i, m := range req.ColumnMapping {
+		mapping[i] = ColumnMapping{sourceColumn: m.SourceColumn, resourceColumn: FeatureLabelColumnType(m.ResourceColumn)}
+	}
+	return TransformationConfig{
+		TargetTableID: deserializeResourceID(req.Target),
+		Query:         req.Query,
+		ColumnMapping: mapping,
+		Storage:       TransformationStorage(req.Storage),
+	}
+}
+
+func serializeTrainingSetDef(def TrainingSetDef) *pb.TrainingSetDef {
+	features := make([]*pb.ResourceID, len(def.Features))
+	for i, id := range def.Features {
+		features[i] = serializeResourceID(id)
+	}
+	serialized := &pb.TrainingSetDef{
+		Id:       serializeResourceID(def.ID),
+		Label:    serializeResourceID(def.Label),
+		Features: features,
+	}
+	if !def.CacheCycle.IsZero() {
+		serialized.CacheCycle = tspb.New(def.CacheCycle)
+	}
+	return serialized
+}
+
+func deserializeTrainingSetDef(def *pb.TrainingSetDef) TrainingSetDef {
+	features := make([]ResourceID, len(def.Features))
+	for i, id := range def.Features {
+		features[i] = deserializeResourceID(id)
+	}
+	deserialized := TrainingSetDef{
+		ID:       deserializeResourceID(def.Id),
+		Label:    deserializeResourceID(def.Label),
+		Features: features,
+	}
+	if def.CacheCycle != nil {
+		deserialized.CacheCycle = def.CacheCycle.AsTime()
+	}
+	return deserialized
+}
+
+func serializeTableStats(stats TableStats) *pb.TableStats {
+	nulls := make([]*pb.ColumnNulls, 0, len(stats.NullCounts))
+	for name, count := range stats.NullCounts {
+		nulls = append(nulls, &pb.ColumnNulls{Name: name, NullCount: count})
+	}
+	return &pb.TableStats{
+		NumRows:    stats.NumRows,
+		SizeBytes:  stats.SizeBytes,
+		MinTs:      tspb.New(stats.MinTS),
+		MaxTs:      tspb.New(stats.MaxTS),
+		NullCounts: nulls,
+	}
+}
+
+func deserializeTableStats(stats *pb.TableStats) TableStats {
+	nulls := make(map[string]int64, len(stats.NullCounts))
+	for _, col := range stats.NullCounts {
+		nulls[col.Name] = col.NullCount
+	}
+	return TableStats{
+		NumRows:    stats.NumRows,
+		SizeBytes:  stats.SizeBytes,
+		MinTS:      stats.MinTs.AsTime(),
+		MaxTS:      stats.MaxTs.AsTime(),
+		NullCounts: nulls,
+	}
+}
@@ -0,0 +1,512 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"errors"
+
+	pb "github.com/featureform/provider/proto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// CustomProviderServer serves a provider over the CustomProvider contract. It
+// lets a store written in Go against this package's interfaces run as the
+// server of a GENERIC_GRPC provider.
+type CustomProviderServer struct {
+	pb.UnimplementedCustomProviderServer
+	provider Provider
+	online   OnlineStore
+	offline  OfflineStore
+}
+
+// NewCustomProviderServer serves p as an online store, an offline store or
+// both, depending on which it can be used as.
+func NewCustomProviderServer(p Provider) *CustomProviderServer {
+	online, err := p.AsOnlineStore()
+	if err != nil {
+		online = nil
+	}
+	offline, err := p.AsOfflineStore()
+	if err != nil {
+		offline = nil
+	}
+	return &CustomProviderServer{provider: p, online: online, offline: offline}
+}
+
+// toStatus gives this package's error types the status codes the contract
+// specifies for them.
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	var tableNotFound *TableNotFound
+	var entityNotFound *EntityNotFound
+	var matNotFound *MaterializationNotFound
+	var trainingSetNotFound *TrainingSetNotFound
+	var exists *TableAlreadyExists
+	var matExists *MaterializationExists
+	switch {
+	case errors.As(err, &matExists):
+		// The ID is sent along so that the client can replace it.
+		st := status.New(codes.AlreadyExists, err.Error())
+		if withID, detailErr := st.WithDetails(&errdetails.ResourceInfo{ResourceType: "materialization", ResourceName: string(matExists.ID)}); detailErr == nil {
+			st = withID
+		}
+		return st.Err()
+	case errors.As(err, &tableNotFound), errors.As(err, &entityNotFound), errors.As(err, &matNotFound), errors.As(err, &trainingSetNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.As(err, &exists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	default:
+		return status.Error(codes.Unknown, err.Error())
+	}
+}
+
+func (serv *CustomProviderServer) onlineStore() (OnlineStore, error) {
+	if serv.online == nil {
+		return nil, status.Error(codes.Unimplemented, "provider is not an online store")
+	}
+	return serv.online, nil
+}
+
+func (serv *CustomProviderServer) offlineStore() (OfflineStore, error) {
+	if serv.offline == nil {
+		return nil, status.Error(codes.Unimplemented, "provider is not an offline store")
+	}
+	return serv.offline, nil
+}
+
+func (serv *CustomProviderServer) Capabilities(ctx context.Context, req *pb.Empty) (*pb.ProviderCapabilities, error) {
+	if err := serv.provider.CheckHealth(ctx); err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return &pb.ProviderCapabilities{Online: serv.online != nil, Offline: serv.offline != nil}, nil
+}
+
+func (serv *CustomProviderServer) CreateOnlineTable(ctx context.Context, req *pb.OnlineTableRequest) (*pb.Empty, error) {
+	store, err := serv.onlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.CreateTable(req.Name, req.Variant, ValueType(req.ValueType)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) GetOnlineTable(ctx context.Context, req *pb.OnlineTableRequest) (*pb.Empty, error) {
+	store, err := serv.onlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.GetTable(req.Name, req.Variant); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) DeleteOnlineTable(ctx context.Context, req *pb.OnlineTableRequest) (*pb.Empty, error) {
+	store, err := serv.onlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.DeleteTable(req.Name, req.Variant); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) SetOnlineValue(ctx context.Context, req *pb.OnlineValueRequest) (*pb.Empty, error) {
+	store, err := serv.onlineStore()
+	if err != nil {
+		return nil, err
+	}
+	table, err := store.GetTable(req.Name, req.Variant)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if err := table.Set(req.Entity, deserializeValue(req.Value)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) GetOnlineValue(ctx context.Context, req *pb.OnlineValueRequest) (*pb.Value, error) {
+	store, err := serv.onlineStore()
+	if err != nil {
+		return nil, err
+	}
+	table, err := store.GetTable(req.Name, req.Variant)
+	if err != nil {
+		// A missing table would be taken for a missing entity.
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	value, err := table.Get(req.Entity)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	serialized, err := serializeValue(value)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return serialized, nil
+}
+
+func (serv *CustomProviderServer) RegisterResourceFromSourceTable(ctx context.Context, req *pb.RegisterResourceRequest) (*pb.Empty, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	schema := ResourceSchema{Entity: req.Entity, Value: req.Value, TS: req.Ts, SourceTable: req.SourceTable, ValidTo: req.ValidTo}
+	if _, err := store.RegisterResourceFromSourceTable(deserializeResourceID(req.Id), schema); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) RegisterPrimaryFromSourceTable(ctx context.Context, req *pb.RegisterPrimaryRequest) (*pb.TableInfo, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	table, err := store.RegisterPrimaryFromSourceTable(deserializeResourceID(req.Id), req.SourceName)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.TableInfo{Name: table.GetName()}, nil
+}
+
+func (serv *CustomProviderServer) CreateTransformation(ctx context.Context, req *pb.TransformationRequest) (*pb.Empty, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.CreateTransformation(deserializeTransformation(req)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) UpdateTransformation(ctx context.Context, req *pb.TransformationRequest) (*pb.Empty, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.UpdateTransformation(deserializeTransformation(req)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) GetTransformationTable(ctx context.Context, req *pb.ResourceID) (*pb.TableInfo, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	table, err := store.GetTransformationTable(deserializeResourceID(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.TableInfo{Name: table.GetName()}, nil
+}
+
+func (serv *CustomProviderServer) CreatePrimaryTable(ctx context.Context, req *pb.CreateTableRequest) (*pb.TableInfo, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	table, err := store.CreatePrimaryTable(deserializeResourceID(req.Id), deserializeColumns(req.Columns))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.TableInfo{Name: table.GetName()}, nil
+}
+
+func (serv *CustomProviderServer) GetPrimaryTable(ctx context.Context, req *pb.ResourceID) (*pb.TableInfo, error) {
+	table, err := serv.primaryTable(req)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.TableInfo{Name: table.GetName()}, nil
+}
+
+// primaryTable looks up primary tables and transformations, which share the
+// table RPCs.
+func (serv *CustomProviderServer) primaryTable(req *pb.ResourceID) (PrimaryTable, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	id := deserializeResourceID(req)
+	var table PrimaryTable
+	if id.Type == Transformation {
+		table, err = store.GetTransformationTable(id)
+	} else {
+		table, err = store.GetPrimaryTable(id)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return table, nil
+}
+
+func (serv *CustomProviderServer) WritePrimaryRecord(ctx context.Context, req *pb.PrimaryRecordRequest) (*pb.Empty, error) {
+	table, err := serv.primaryTable(req.Id)
+	if err != nil {
+		return nil, err
+	}
+	if err := table.Write(deserializeValues(req.Values)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) IteratePrimaryTable(req *pb.IterateTableRequest, stream pb.CustomProvider_IteratePrimaryTableServer) error {
+	table, err := serv.primaryTable(req.Id)
+	if err != nil {
+		return err
+	}
+	iter, err := table.IterateSegment(req.Limit)
+	if err != nil {
+		return toStatus(err)
+	}
+	for iter.Next() {
+		values, err := serializeValues(iter.Values())
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err := stream.Send(&pb.PrimaryRow{Columns: iter.Columns(), Values: values}); err != nil {
+			return err
+		}
+	}
+	return toStatus(iter.Err())
+}
+
+func (serv *CustomProviderServer) PrimaryTableNumRows(ctx context.Context, req *pb.ResourceID) (*pb.NumRows, error) {
+	table, err := serv.primaryTable(req)
+	if err != nil {
+		return nil, err
+	}
+	n, err := table.NumRows()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.NumRows{Rows: n}, nil
+}
+
+func (serv *CustomProviderServer) PrimaryTableStats(ctx context.Context, req *pb.ResourceID) (*pb.TableStats, error) {
+	table, err := serv.primaryTable(req)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := table.Stats()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return serializeTableStats(stats), nil
+}
+
+func (serv *CustomProviderServer) CreateResourceTable(ctx context.Context, req *pb.CreateTableRequest) (*pb.Empty, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.CreateResourceTable(deserializeResourceID(req.Id), deserializeColumns(req.Columns)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) GetResourceTable(ctx context.Context, req *pb.ResourceID) (*pb.Empty, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.GetResourceTable(deserializeResourceID(req)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) WriteResourceRecord(ctx context.Context, req *pb.ResourceRecordRequest) (*pb.Empty, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	table, err := store.GetResourceTable(deserializeResourceID(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	if err := table.Write(deserializeResourceRecord(req.Record)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) CreateMaterialization(ctx context.Context, req *pb.MaterializeRequest) (*pb.MaterializationID, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	id := deserializeResourceID(req.Id)
+	var mat Materialization
+	if req.Since != nil {
+		mat, err = store.CreateIncrementalMaterialization(id, req.Since.AsTime())
+	} else {
+		mat, err = store.CreateMaterialization(id)
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.MaterializationID{Id: string(mat.ID())}, nil
+}
+
+func (serv *CustomProviderServer) UpdateMaterialization(ctx context.Context, req *pb.ResourceID) (*pb.MaterializationID, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	mat, err := store.UpdateMaterialization(deserializeResourceID(req))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.MaterializationID{Id: string(mat.ID())}, nil
+}
+
+func (serv *CustomProviderServer) materialization(req *pb.MaterializationID) (Materialization, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	mat, err := store.GetMaterialization(MaterializationID(req.Id))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return mat, nil
+}
+
+func (serv *CustomProviderServer) GetMaterialization(ctx context.Context, req *pb.MaterializationID) (*pb.Empty, error) {
+	if _, err := serv.materialization(req); err != nil {
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) DeleteMaterialization(ctx context.Context, req *pb.MaterializationID) (*pb.Empty, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.DeleteMaterialization(MaterializationID(req.Id)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) MaterializationNumRows(ctx context.Context, req *pb.MaterializationID) (*pb.NumRows, error) {
+	mat, err := serv.materialization(req)
+	if err != nil {
+		return nil, err
+	}
+	n, err := mat.NumRows()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.NumRows{Rows: n}, nil
+}
+
+func (serv *CustomProviderServer) IterateMaterialization(req *pb.IterateMaterializationRequest, stream pb.CustomProvider_IterateMaterializationServer) error {
+	mat, err := serv.materialization(&pb.MaterializationID{Id: req.Id})
+	if err != nil {
+		return err
+	}
+	iter, err := mat.IterateSegment(req.Begin, req.End)
+	if err != nil {
+		return toStatus(err)
+	}
+	for iter.Next() {
+		rec, err := serializeResourceRecord(iter.Value())
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err := stream.Send(rec); err != nil {
+			return err
+		}
+	}
+	return toStatus(iter.Err())
+}
+
+func (serv *CustomProviderServer) MaterializationStats(ctx context.Context, req *pb.MaterializationID) (*pb.TableStats, error) {
+	mat, err := serv.materialization(req)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := mat.Stats()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return serializeTableStats(stats), nil
+}
+
+func (serv *CustomProviderServer) DeleteTable(ctx context.Context, req *pb.ResourceID) (*pb.Empty, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.DeleteTable(deserializeResourceID(req)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) CreateTrainingSet(ctx context.Context, req *pb.TrainingSetDef) (*pb.Empty, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.CreateTrainingSet(deserializeTrainingSetDef(req)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) UpdateTrainingSet(ctx context.Context, req *pb.TrainingSetDef) (*pb.Empty, error) {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.UpdateTrainingSet(deserializeTrainingSetDef(req)); err != nil {
+		return nil, toStatus(err)
+	}
+	return &pb.Empty{}, nil
+}
+
+func (serv *CustomProviderServer) GetTrainingSet(req *pb.ResourceID, stream pb.CustomProvider_GetTrainingSetServer) error {
+	store, err := serv.offlineStore()
+	if err != nil {
+		return err
+	}
+	iter, err := store.GetTrainingSet(deserializeResourceID(req))
+	if err != nil {
+		return toStatus(err)
+	}
+	for iter.Next() {
+		features, err := serializeValues(iter.Features())
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		label, err := serializeValue(iter.Label())
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if err := stream.Send(&pb.TrainingSetRow{Features: features, Label: label}); err != nil {
+			return err
+		}
+	}
+	return toStatus(iter.Err())
+}
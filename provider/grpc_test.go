@@ -0,0 +1,72 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	pb "github.com/featureform/provider/proto"
+	"google.golang.org/grpc"
+)
+
+// serveCustomProvider serves p over the CustomProvider contract for the rest
+// of the test and returns a GENERIC_GRPC config that connects to it.
+func serveCustomProvider(t *testing.T, p Provider) SerializedConfig {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %s", err)
+	}
+	server := grpc.NewServer()
+	pb.RegisterCustomProviderServer(server, NewCustomProviderServer(p))
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+	return GenericGRPCConfig{Address: lis.Addr().String()}.Serialized()
+}
+
+func TestGenericGRPCCapabilities(t *testing.T) {
+	p, err := Get(GenericGRPC, serveCustomProvider(t, NewLocalOnlineStore()))
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	if _, err := p.AsOnlineStore(); err != nil {
+		t.Fatalf("Failed to use provider as an online store: %s", err)
+	}
+	if _, err := p.AsOfflineStore(); err == nil {
+		t.Fatalf("Online store was used as an offline store")
+	}
+	if _, err := Get(GenericGRPC, GenericGRPCConfig{Address: "127.0.0.1:1"}.Serialized()); err == nil {
+		t.Fatalf("Got a provider with no server")
+	}
+}
+
+type flakyProvider struct {
+	healthy *bool
+	BaseProvider
+}
+
+func (p flakyProvider) CheckHealth(ctx context.Context) error {
+	if !*p.healthy {
+		return errors.New("store is unreachable")
+	}
+	return nil
+}
+
+func TestGenericGRPCCheckHealth(t *testing.T) {
+	healthy := true
+	p, err := Get(GenericGRPC, serveCustomProvider(t, flakyProvider{healthy: &healthy}))
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	if err := p.CheckHealth(context.Background()); err != nil {
+		t.Fatalf("Healthy provider failed its health check: %s", err)
+	}
+	healthy = false
+	if err := p.CheckHealth(context.Background()); err == nil {
+		t.Fatalf("Unhealthy provider passed its health check")
+	}
+}
@@ -0,0 +1,246 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	"github.com/lib/pq"
+)
+
+// AuthMethod selects how a provider authenticates. Password auth uses the
+// credentials embedded in the config; the IAM methods fetch short-lived
+// tokens from the instance role or workload identity at connect time.
+type AuthMethod string
+
+const (
+	PasswordAuth AuthMethod = "password"
+	AWSIAMAuth   AuthMethod = "aws-iam"
+	GCPIAMAuth   AuthMethod = "gcp-iam"
+)
+
+// The token lifetime AWS allows for RDS and ElastiCache auth tokens.
+const awsAuthTokenExpiry = 15 * time.Minute
+
+// Sha256 of an empty payload, used when presigning auth token requests.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+var (
+	awsMetadataEndpoint = "http://169.254.169.254"
+	gcpMetadataEndpoint = "http://metadata.google.internal"
+	cloudHTTPClient     = &http.Client{Timeout: 5 * time.Second}
+	awsCredentials      = aws.NewCredentialsCache(aws.CredentialsProviderFunc(defaultAWSCredentials))
+)
+
+// IAMConfig holds the cloud IAM options shared by provider configs. Region is
+// only used with AWSIAMAuth.
+type IAMConfig struct {
+	AuthMethod AuthMethod `json:"AuthMethod,omitempty"`
+	Region     string     `json:"Region,omitempty"`
+}
+
+func (c IAMConfig) usesIAM() bool {
+	return c.AuthMethod == AWSIAMAuth || c.AuthMethod == GCPIAMAuth
+}
+
+func (c IAMConfig) validateIAM() error {
+	switch c.AuthMethod {
+	case "", PasswordAuth, GCPIAMAuth:
+	case AWSIAMAuth:
+		if c.Region == "" {
+			return fmt.Errorf("region required for %s auth", AWSIAMAuth)
+		}
+	default:
+		return fmt.Errorf("unknown auth method: %s", c.AuthMethod)
+	}
+	return nil
+}
+
+type tokenFn func(ctx context.Context) (string, error)
+
+// rdsToken returns a token generator for RDS IAM auth against the database
+// at endpoint (host:port) as user.
+func (c IAMConfig) rdsToken(endpoint, user string) tokenFn {
+	if c.AuthMethod == GCPIAMAuth {
+		return gcpAccessToken
+	}
+	return func(ctx context.Context) (string, error) {
+		values := url.Values{}
+		values.Set("Action", "connect")
+		values.Set("DBUser", user)
+		return presignAWSToken(ctx, "https://"+endpoint+"/?"+values.Encode(), "rds-db", c.Region)
+	}
+}
+
+// elastiCacheToken returns a token generator for ElastiCache IAM auth against
+// the replication group cacheName as user.
+func (c IAMConfig) elastiCacheToken(cacheName, user string) tokenFn {
+	if c.AuthMethod == GCPIAMAuth {
+		return gcpAccessToken
+	}
+	return func(ctx context.Context) (string, error) {
+		values := url.Values{}
+		values.Set("Action", "connect")
+		values.Set("User", user)
+		return presignAWSToken(ctx, "http://"+cacheName+"/?"+values.Encode(), "elasticache", c.Region)
+	}
+}
+
+func presignAWSToken(ctx context.Context, rawURL, service, region string) (string, error) {
+	creds, err := awsCredentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve aws credentials: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", err
+	}
+	query := req.URL.Query()
+	query.Set("X-Amz-Expires", fmt.Sprint(int(awsAuthTokenExpiry.Seconds())))
+	req.URL.RawQuery = query.Encode()
+	signed, _, err := v4.NewSigner().PresignHTTP(ctx, creds, req, emptyPayloadHash, service, region, time.Now().UTC())
+	if err != nil {
+		return "", fmt.Errorf("could not sign %s auth token: %w", service, err)
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(signed, "https://"), "http://"), nil
+}
+
+// AWSCredentials returns what requests to AWS are signed with, outside of
+// providers too: the environment's credentials, or the instance role's.
+func AWSCredentials() aws.CredentialsProvider {
+	return awsCredentials
+}
+
+// defaultAWSCredentials reads credentials from the environment, falling back
+// to the instance role from the EC2 instance metadata service.
+func defaultAWSCredentials(ctx context.Context) (aws.Credentials, error) {
+	if key := os.Getenv("AWS_ACCESS_KEY_ID"); key != "" {
+		return aws.Credentials{
+			AccessKeyID:     key,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			Source:          "Environment",
+		}, nil
+	}
+	return instanceRoleCredentials(ctx)
+}
+
+func cloudRequest(ctx context.Context, method, rawURL string, headers map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metadata request %s failed: %s", rawURL, resp.Status)
+	}
+	return body, nil
+}
+
+func instanceRoleCredentials(ctx context.Context) (aws.Credentials, error) {
+	token, err := cloudRequest(ctx, http.MethodPut, awsMetadataEndpoint+"/latest/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": "21600",
+	})
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("could not get instance metadata token: %w", err)
+	}
+	headers := map[string]string{"X-aws-ec2-metadata-token": string(token)}
+	credsPath := awsMetadataEndpoint + "/latest/meta-data/iam/security-credentials/"
+	role, err := cloudRequest(ctx, http.MethodGet, credsPath, headers)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("could not get instance role: %w", err)
+	}
+	body, err := cloudRequest(ctx, http.MethodGet, credsPath+strings.TrimSpace(string(role)), headers)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("could not get instance role credentials: %w", err)
+	}
+	var creds struct {
+		AccessKeyId     string
+		SecretAccessKey string
+		Token           string
+		Expiration      time.Time
+	}
+	if err := json.Unmarshal(body, &creds); err != nil {
+		return aws.Credentials{}, fmt.Errorf("invalid instance role credentials: %w", err)
+	}
+	return aws.Credentials{
+		AccessKeyID:     creds.AccessKeyId,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.Token,
+		Source:          "EC2RoleProvider",
+		CanExpire:       true,
+		Expires:         creds.Expiration,
+	}, nil
+}
+
+// GCPAccessToken returns an access token for the workload's service account,
+// for calling Google Cloud APIs outside of providers.
+func GCPAccessToken(ctx context.Context) (string, error) {
+	return gcpAccessToken(ctx)
+}
+
+// gcpAccessToken fetches an OAuth access token for the workload's service
+// account from the GCE metadata server. Cloud SQL and Memorystore accept it
+// as a password when IAM auth is enabled.
+func gcpAccessToken(ctx context.Context) (string, error) {
+	body, err := cloudRequest(ctx, http.MethodGet, gcpMetadataEndpoint+"/computeMetadata/v1/instance/service-accounts/default/token", map[string]string{
+		"Metadata-Flavor": "Google",
+	})
+	if err != nil {
+		return "", fmt.Errorf("could not get gcp access token: %w", err)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("invalid gcp access token: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+// iamConnector opens postgres connections with a freshly generated token as
+// the password, since IAM tokens expire long before pooled connections do.
+type iamConnector struct {
+	dsn   func(password string) string
+	token tokenFn
+}
+
+func (c iamConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := pq.NewConnector(c.dsn(token))
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c iamConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
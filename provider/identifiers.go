@@ -0,0 +1,127 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// quoteIdentifier quotes a single identifier with quote, which is a double
+// quote for most warehouses and a backtick for BigQuery. Quoted identifiers
+// are used exactly as written, so reserved words and mixed case names work.
+func quoteIdentifier(ident string, quote byte) string {
+	q := string(quote)
+	ident = strings.ReplaceAll(ident, "\x00", "")
+	return q + strings.ReplaceAll(ident, q, q+q) + q
+}
+
+// QuoteIdentifier quotes an identifier, such as a resource's table name,
+// the way the offline store of type t does, so that queries written outside
+// of the store, like a transformation's, refer to it correctly.
+func QuoteIdentifier(t Type, ident string) string {
+	return typeDialect(t).quote(ident)
+}
+
+// quoteName quotes each part of a name that may be qualified, such as
+// schema.table. Parts that are already quoted are left as they are, so a
+// name that's been quoted once can be passed through again.
+func quoteName(name string, quote byte) string {
+	parts := splitName(name, quote)
+	for i, part := range parts {
+		if !isQuoted(part, quote) {
+			parts[i] = quoteIdentifier(part, quote)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// splitName splits a name on the dots that aren't inside quotes.
+func splitName(name string, quote byte) []string {
+	parts := make([]string, 0, 1)
+	quoted := false
+	start := 0
+	for i := 0; i < len(name); i++ {
+		switch name[i] {
+		case quote:
+			quoted = !quoted
+		case '.':
+			if !quoted {
+				parts = append(parts, name[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, name[start:])
+}
+
+func isQuoted(ident string, quote byte) bool {
+	return len(ident) >= 2 && ident[0] == quote && ident[len(ident)-1] == quote
+}
+
+// nameCandidates returns the spellings a user may have meant by name, most
+// exact first. Warehouses fold unquoted names to lower case (Postgres and
+// Redshift) or upper case (Snowflake), so a table created without quotes
+// can be found by its folded name whatever case it's registered with. Names
+// that are already quoted are only tried as written.
+func nameCandidates(name string, quote byte) []string {
+	candidates := []string{name}
+	if strings.IndexByte(name, quote) >= 0 {
+		return candidates
+	}
+	for _, folded := range []string{strings.ToLower(name), strings.ToUpper(name)} {
+		if folded != name && folded != candidates[len(candidates)-1] {
+			candidates = append(candidates, folded)
+		}
+	}
+	return candidates
+}
+
+// resolveIdentifier finds the column that name refers to. An exact match is
+// used if there is one, then a single column that matches ignoring case.
+// Otherwise name is returned unchanged and the warehouse reports the error.
+func resolveIdentifier(columns []string, name string) string {
+	var match string
+	matches := 0
+	for _, column := range columns {
+		if column == name {
+			return column
+		}
+		if strings.EqualFold(column, name) {
+			match = column
+			matches++
+		}
+	}
+	if matches == 1 {
+		return match
+	}
+	return name
+}
+
+// resolveSourceTable finds the table a user registered as name, trying its
+// case folded spellings if it doesn't exist as written, and returns it quoted
+// along with its column names.
+func resolveSourceTable(db *sql.DB, d Dialect, name string) (string, []string, error) {
+	var lastErr error
+	for _, candidate := range nameCandidates(name, d.quoteChar()) {
+		quoted := d.quoteName(candidate)
+		columns, err := queryColumns(db, quoted)
+		if err == nil {
+			return quoted, columns, nil
+		}
+		lastErr = err
+	}
+	return "", nil, fmt.Errorf("find source table %s: %w", name, lastErr)
+}
+
+func queryColumns(db *sql.DB, quotedTable string) ([]string, error) {
+	rows, err := db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT 0", quotedTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return rows.Columns()
+}
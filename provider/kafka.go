@@ -0,0 +1,215 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+const KafkaStream Type = "KAFKA_STREAM"
+
+// KafkaConfig configures a Kafka cluster that feature values are streamed
+// from. Each record's key is the entity and its value is the feature value
+// as JSON, and records are timestamped with their Kafka timestamp.
+type KafkaConfig struct {
+	Brokers []string
+	// Username and Password authenticate with SASL/PLAIN if they're set.
+	Username string `json:",omitempty"`
+	Password string `json:",omitempty"`
+	TLSConfig
+}
+
+func (k KafkaConfig) Serialized() SerializedConfig {
+	config, err := json.Marshal(k)
+	if err != nil {
+		panic(err)
+	}
+	return config
+}
+
+func (k *KafkaConfig) Deserialize(config SerializedConfig) error {
+	return json.Unmarshal(config, k)
+}
+
+// KafkaStreamingSource reads feature values from Kafka topics. Offsets are
+// kept by the caller rather than committed to a consumer group, so that
+// they're only moved on once the values read are stored.
+type KafkaStreamingSource struct {
+	BaseProvider
+	brokers []string
+	dialer  *kafka.Dialer
+}
+
+func kafkaStreamingSourceFactory(serialized SerializedConfig) (Provider, error) {
+	config := &KafkaConfig{}
+	if err := config.Deserialize(serialized); err != nil {
+		return nil, fmt.Errorf("invalid kafka config: %w", err)
+	}
+	if len(config.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka config has no brokers")
+	}
+	dialer := &kafka.Dialer{Timeout: 10 * time.Second, DualStack: true}
+	host, _, err := net.SplitHostPort(config.Brokers[0])
+	if err != nil {
+		host = config.Brokers[0]
+	}
+	if dialer.TLS, err = config.tlsConfig(host); err != nil {
+		return nil, err
+	}
+	if config.Username != "" {
+		dialer.SASLMechanism = plain.Mechanism{Username: config.Username, Password: config.Password}
+	}
+	return &KafkaStreamingSource{
+		BaseProvider: BaseProvider{
+			ProviderType:   KafkaStream,
+			ProviderConfig: serialized,
+		},
+		brokers: config.Brokers,
+		dialer:  dialer,
+	}, nil
+}
+
+// partitions returns the IDs of topic's partitions.
+func (k *KafkaStreamingSource) partitions(ctx context.Context, topic string) ([]int, error) {
+	var lastErr error
+	for _, broker := range k.brokers {
+		conn, err := k.dialer.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		partitions, err := conn.ReadPartitions(topic)
+		conn.Close()
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]int, len(partitions))
+		for i, partition := range partitions {
+			ids[i] = partition.ID
+		}
+		return ids, nil
+	}
+	return nil, fmt.Errorf("could not connect to any kafka broker: %w", lastErr)
+}
+
+func (k *KafkaStreamingSource) Subscribe(topic string, offsets StreamOffsets) (StreamReader, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	partitions, err := k.partitions(ctx, topic)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("list partitions of %s: %w", topic, err)
+	}
+	stream := &kafkaStreamReader{
+		topic:   topic,
+		records: make(chan StreamRecord),
+		errs:    make(chan error, len(partitions)),
+		cancel:  cancel,
+	}
+	for _, partition := range partitions {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:   k.brokers,
+			Topic:     topic,
+			Partition: partition,
+			Dialer:    k.dialer,
+		})
+		next := kafka.FirstOffset
+		if offset, has := offsets[strconv.Itoa(partition)]; has {
+			last, err := strconv.ParseInt(offset, 10, 64)
+			if err != nil || last < 0 {
+				reader.Close()
+				stream.Close()
+				return nil, fmt.Errorf("invalid offset %q for partition %d of %s", offset, partition, topic)
+			}
+			next = last + 1
+		}
+		if err := reader.SetOffset(next); err != nil {
+			reader.Close()
+			stream.Close()
+			return nil, fmt.Errorf("seek partition %d of %s: %w", partition, topic, err)
+		}
+		stream.readers = append(stream.readers, reader)
+		stream.wg.Add(1)
+		go stream.read(ctx, reader)
+	}
+	return stream, nil
+}
+
+// kafkaStreamReader reads each of a topic's partitions in a goroutine of its
+// own, so records are in order within a partition but not across them.
+type kafkaStreamReader struct {
+	topic   string
+	readers []*kafka.Reader
+	records chan StreamRecord
+	errs    chan error
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+func (r *kafkaStreamReader) read(ctx context.Context, reader *kafka.Reader) {
+	defer r.wg.Done()
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err == nil {
+			var record StreamRecord
+			record, err = kafkaRecord(msg)
+			if err == nil {
+				select {
+				case r.records <- record:
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		if ctx.Err() == nil {
+			r.errs <- err
+		}
+		return
+	}
+}
+
+func kafkaRecord(msg kafka.Message) (StreamRecord, error) {
+	var value interface{}
+	if err := json.Unmarshal(msg.Value, &value); err != nil {
+		return StreamRecord{}, fmt.Errorf("invalid value at offset %d of partition %d: %w", msg.Offset, msg.Partition, err)
+	}
+	return StreamRecord{
+		ResourceRecord: ResourceRecord{Entity: string(msg.Key), Value: value, TS: msg.Time},
+		Partition:      strconv.Itoa(msg.Partition),
+		Offset:         strconv.FormatInt(msg.Offset, 10),
+	}, nil
+}
+
+func (r *kafkaStreamReader) Next(ctx context.Context) (StreamRecord, error) {
+	select {
+	case <-ctx.Done():
+		return StreamRecord{}, ctx.Err()
+	case err := <-r.errs:
+		return StreamRecord{}, err
+	case record := <-r.records:
+		return record, nil
+	}
+}
+
+func (r *kafkaStreamReader) Close() error {
+	r.cancel()
+	r.wg.Wait()
+	var closeErr error
+	for _, reader := range r.readers {
+		if err := reader.Close(); err != nil {
+			closeErr = err
+		}
+	}
+	return closeErr
+}
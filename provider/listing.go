@@ -0,0 +1,116 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ResourceLister is implemented by offline stores that can list the resources
+// they hold tables for. Materializations, caches and staging tables aren't
+// included, since they're cleaned up along with their resource.
+type ResourceLister interface {
+	ListResources() ([]ResourceID, error)
+}
+
+// OnlineTableLister is implemented by online stores that can list the feature
+// and label tables they hold. Tables the store keeps for its own bookkeeping, like
+// watermarks, aren't included.
+type OnlineTableLister interface {
+	ListTables() ([]ResourceID, error)
+}
+
+// internalTablePrefix starts the names of online tables that don't belong to
+// a feature or label.
+const internalTablePrefix = "featureform_"
+
+var tableNamePrefixes = []struct {
+	prefix string
+	typ    OfflineResourceType
+}{
+	{"featureform_resource_feature__", Feature},
+	{"featureform_resource_label__", Label},
+	{"featureform_trainingset__", TrainingSet},
+	{"featureform_primary_", Primary},
+}
+
+// parseTableName returns the resource an offline table was created for, if
+// its name follows the naming scheme of sqlOfflineStore. Transformations
+// share the naming of primary tables and are returned as Primary.
+func parseTableName(name string) (ResourceID, bool) {
+	for _, naming := range tableNamePrefixes {
+		if !strings.HasPrefix(name, naming.prefix) {
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(name, naming.prefix), "__")
+		if len(parts) != 2 || parts[0] == "" {
+			return ResourceID{}, false
+		}
+		return ResourceID{Name: parts[0], Variant: parts[1], Type: naming.typ}, true
+	}
+	return ResourceID{}, false
+}
+
+func (store *sqlOfflineStore) ListResources() ([]ResourceID, error) {
+	rows, err := store.db.Query(store.query.tablesLike(), likePrefix("featureform_"))
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	defer rows.Close()
+	ids := make([]ResourceID, 0)
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			return nil, err
+		}
+		if id, ok := parseTableName(tableName); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+func (store *memoryOfflineStore) ListResources() ([]ResourceID, error) {
+	ids := make([]ResourceID, 0, len(store.tables)+len(store.trainingSets))
+	for id := range store.tables {
+		ids = append(ids, id)
+	}
+	for id := range store.trainingSets {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (store *localOnlineStore) ListTables() ([]ResourceID, error) {
+	ids := make([]ResourceID, 0, len(store.tables))
+	for key := range store.tables {
+		if strings.HasPrefix(key.feature, internalTablePrefix) {
+			continue
+		}
+		ids = append(ids, MaterializedResource(key.feature, key.variant))
+	}
+	return ids, nil
+}
+
+func (store *redisOnlineStore) ListTables() ([]ResourceID, error) {
+	keys, err := store.client.HKeys(ctx, fmt.Sprintf("%s__tables", store.prefix)).Result()
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]ResourceID, 0, len(keys))
+	for _, serialized := range keys {
+		var key redisTableKey
+		if err := json.Unmarshal([]byte(serialized), &key); err != nil {
+			return nil, fmt.Errorf("parse table key %s: %w", serialized, err)
+		}
+		if strings.HasPrefix(key.Feature, internalTablePrefix) {
+			continue
+		}
+		ids = append(ids, MaterializedResource(key.Feature, key.Variant))
+	}
+	return ids, nil
+}
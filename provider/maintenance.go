@@ -0,0 +1,183 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaintenanceTask is a kind of upkeep a provider runs on the tables it holds
+// for Featureform, so that a long-running deployment doesn't slow down as
+// they churn.
+type MaintenanceTask string
+
+const (
+	// VacuumAnalyze reclaims the space of rows that refreshes replaced and
+	// updates the statistics the query planner uses.
+	VacuumAnalyze MaintenanceTask = "VACUUM_ANALYZE"
+	// Defragment has an online store release memory that's been freed but
+	// is still held by its allocator.
+	Defragment MaintenanceTask = "DEFRAGMENT"
+	// Optimize reclusters or compacts tables in warehouses that organize
+	// their storage.
+	Optimize MaintenanceTask = "OPTIMIZE"
+)
+
+// MaintainableStore is implemented by providers that can run maintenance.
+type MaintainableStore interface {
+	MaintenanceTasks() []MaintenanceTask
+	RunMaintenance(ctx context.Context, task MaintenanceTask) error
+}
+
+// UnsupportedMaintenance is returned when a provider is asked to run a task
+// it doesn't support.
+type UnsupportedMaintenance struct {
+	Provider Type
+	Task     MaintenanceTask
+}
+
+func (err *UnsupportedMaintenance) Error() string {
+	return fmt.Sprintf("%s does not support %s maintenance", err.Provider, err.Task)
+}
+
+// maintenanceQueries is implemented by the queries of warehouses that
+// support maintenance.
+type maintenanceQueries interface {
+	maintenanceTasks() []MaintenanceTask
+	// maintenanceTables selects the names of the materialization tables
+	// like a pattern escaped with likeEscape, which maintenance is run on.
+	maintenanceTables() string
+	// maintenance returns the statements that run task on a table, in
+	// order.
+	maintenance(task MaintenanceTask, tableName string) []string
+}
+
+func supportsMaintenance(tasks []MaintenanceTask, task MaintenanceTask) bool {
+	for _, supported := range tasks {
+		if supported == task {
+			return true
+		}
+	}
+	return false
+}
+
+func (store *sqlOfflineStore) MaintenanceTasks() []MaintenanceTask {
+	queries, ok := store.query.(maintenanceQueries)
+	if !ok {
+		return nil
+	}
+	return queries.maintenanceTasks()
+}
+
+// RunMaintenance runs task on each materialization table, one at a time so
+// that the warehouse isn't loaded more than a refresh would load it.
+func (store *sqlOfflineStore) RunMaintenance(ctx context.Context, task MaintenanceTask) error {
+	queries, ok := store.query.(maintenanceQueries)
+	if !ok || !supportsMaintenance(queries.maintenanceTasks(), task) {
+		return &UnsupportedMaintenance{store.Type(), task}
+	}
+	rows, err := store.db.QueryContext(ctx, queries.maintenanceTables(), likePrefix(store.getMaterializationTableName("")))
+	if err != nil {
+		return fmt.Errorf("list materialization tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return err
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, table := range tables {
+		for _, stmt := range queries.maintenance(task, table) {
+			if _, err := store.db.ExecContext(ctx, stmt); err != nil {
+				return fmt.Errorf("%s %s: %w", task, table, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (q postgresSQLQueries) maintenanceTasks() []MaintenanceTask {
+	return []MaintenanceTask{VacuumAnalyze}
+}
+
+// Postgres materializations are materialized views, which aren't listed in
+// information_schema.tables.
+func (q postgresSQLQueries) maintenanceTables() string {
+	return fmt.Sprintf("SELECT matviewname FROM pg_matviews WHERE matviewname LIKE $1 ESCAPE '%c'", likeEscape)
+}
+
+func (q postgresSQLQueries) maintenance(task MaintenanceTask, tableName string) []string {
+	switch task {
+	case VacuumAnalyze:
+		// Refreshing a materialization concurrently leaves behind a dead
+		// row for every row it replaces.
+		return []string{fmt.Sprintf("VACUUM (ANALYZE) %s", sanitize(tableName))}
+	}
+	return nil
+}
+
+func (q redshiftSQLQueries) maintenanceTasks() []MaintenanceTask {
+	return []MaintenanceTask{VacuumAnalyze}
+}
+
+func (q redshiftSQLQueries) maintenanceTables() string {
+	return q.tablesLike()
+}
+
+func (q redshiftSQLQueries) maintenance(task MaintenanceTask, tableName string) []string {
+	switch task {
+	case VacuumAnalyze:
+		// Redshift doesn't vacuum and analyze in one statement.
+		return []string{
+			fmt.Sprintf("VACUUM %s", sanitize(tableName)),
+			fmt.Sprintf("ANALYZE %s", sanitize(tableName)),
+		}
+	}
+	return nil
+}
+
+func (q snowflakeSQLQueries) maintenanceTasks() []MaintenanceTask {
+	return []MaintenanceTask{Optimize}
+}
+
+func (q snowflakeSQLQueries) maintenanceTables() string {
+	return q.tablesLike()
+}
+
+func (q snowflakeSQLQueries) maintenance(task MaintenanceTask, tableName string) []string {
+	switch task {
+	case Optimize:
+		// Clustering materializations by entity keeps their micro-partitions
+		// pruned as they're rebuilt. Snowflake reclusters them in the
+		// background once a key is set, so setting it again is a no-op.
+		return []string{fmt.Sprintf("ALTER TABLE %s CLUSTER BY (entity)", sanitize(tableName))}
+	}
+	return nil
+}
+
+func (store *redisOnlineStore) MaintenanceTasks() []MaintenanceTask {
+	return []MaintenanceTask{Defragment}
+}
+
+// RunMaintenance asks Redis to return the memory its allocator holds to the
+// operating system. Feature tables are rewritten on every materialization,
+// which leaves a lot of it behind.
+func (store *redisOnlineStore) RunMaintenance(ctx context.Context, task MaintenanceTask) error {
+	if task != Defragment {
+		return &UnsupportedMaintenance{store.Type(), task}
+	}
+	if err := store.client.Do(ctx, "MEMORY", "PURGE").Err(); err != nil {
+		return fmt.Errorf("purge memory: %w", err)
+	}
+	return nil
+}
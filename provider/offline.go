@@ -8,6 +8,8 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/featureform/metadata"
@@ -33,8 +35,32 @@ const (
 	String              = "string"
 	Bool                = "bool"
 	Timestamp           = "time.Time"
+	Bytes               = "bytes"
+	Decimal             = "decimal"
 )
 
+// DecimalValue is the value of a Decimal feature or label: its exact decimal
+// text, such as "1234.5600". Decimals are kept as text from the offline store
+// through to serving so that no precision is lost to floating point.
+type DecimalValue string
+
+// decimalValue converts a decimal column scanned by a database driver, which
+// may come back as text or bytes, to a DecimalValue.
+func decimalValue(v interface{}) DecimalValue {
+	switch v := v.(type) {
+	case DecimalValue:
+		return v
+	case string:
+		return DecimalValue(v)
+	case []byte:
+		return DecimalValue(v)
+	case float64:
+		return DecimalValue(strconv.FormatFloat(v, 'f', -1, 64))
+	default:
+		return DecimalValue(fmt.Sprint(v))
+	}
+}
+
 type OfflineResourceType int
 
 const (
@@ -51,6 +77,7 @@ var ProviderToMetadataResourceType = map[OfflineResourceType]metadata.ResourceTy
 	TrainingSet:    metadata.TRAINING_SET_VARIANT,
 	Primary:        metadata.SOURCE_VARIANT,
 	Transformation: metadata.SOURCE_VARIANT,
+	Label:          metadata.LABEL_VARIANT,
 }
 
 type FeatureLabelColumnType string
@@ -84,10 +111,52 @@ func (id *ResourceID) check(expectedType OfflineResourceType, otherTypes ...Offl
 	return fmt.Errorf("Unexpected ResourceID Type")
 }
 
+// MaterializedName is the name that a feature or label's materializations
+// and online table are stored under. Labels are prefixed so that they don't
+// clash with a feature of the same name.
+func MaterializedName(id ResourceID) string {
+	if id.Type == Label {
+		return labelMaterializedPrefix + id.Name
+	}
+	return id.Name
+}
+
+const labelMaterializedPrefix = "label__"
+
+// MaterializedResource returns the feature or label that an online table
+// with the given name and variant holds, undoing MaterializedName.
+func MaterializedResource(name, variant string) ResourceID {
+	if strings.HasPrefix(name, labelMaterializedPrefix) {
+		return ResourceID{Name: strings.TrimPrefix(name, labelMaterializedPrefix), Variant: variant, Type: Label}
+	}
+	return ResourceID{Name: name, Variant: variant, Type: Feature}
+}
+
+// ResourceMaterializationID is the ID of the full materialization of a
+// feature or label variant. Variants of the same resource each get their
+// own, since they can come from different sources.
+func ResourceMaterializationID(id ResourceID) MaterializationID {
+	return MaterializationID(fmt.Sprintf("%s__%s", MaterializedName(id), id.Variant))
+}
+
+func checkMaterializable(id ResourceID) error {
+	if id.Type != Feature && id.Type != Label {
+		return errors.New("only features and labels can be materialized")
+	}
+	return nil
+}
+
 type TrainingSetDef struct {
 	ID       ResourceID
 	Label    ResourceID
 	Features []ResourceID
+	// CacheCycle is the start of the scheduling cycle the training set is
+	// built in, if any. Stores that support it let training sets built in
+	// the same cycle share copies of their feature tables.
+	CacheCycle time.Time
+	// Export, if it's set, is where the training set's rows are written as
+	// files each time it's created or updated.
+	Export *TrainingSetExport `json:",omitempty"`
 }
 
 func (def *TrainingSetDef) check() error {
@@ -119,8 +188,25 @@ type TransformationConfig struct {
 	TargetTableID ResourceID
 	Query         string
 	ColumnMapping []ColumnMapping
+	// Storage is how the result is stored. It's copied into a table unless
+	// it's set to a view.
+	Storage TransformationStorage
 }
 
+// TransformationStorage is how a SQL store keeps a transformation's result.
+type TransformationStorage string
+
+const (
+	// TableStorage copies the result into a table.
+	TableStorage TransformationStorage = ""
+	// ViewStorage registers the query as a view, so that the result
+	// isn't copied and reflects its sources whenever it's read.
+	ViewStorage TransformationStorage = "VIEW"
+	// MaterializedViewStorage registers the query as a materialized
+	// view, for stores that have them, which each update refreshes.
+	MaterializedViewStorage TransformationStorage = "MATERIALIZED_VIEW"
+)
+
 type OfflineStore interface {
 	RegisterResourceFromSourceTable(id ResourceID, schema ResourceSchema) (OfflineTable, error)
 	RegisterPrimaryFromSourceTable(id ResourceID, sourceName string) (PrimaryTable, error)
@@ -132,9 +218,17 @@ type OfflineStore interface {
 	CreateResourceTable(id ResourceID, schema TableSchema) (OfflineTable, error)
 	GetResourceTable(id ResourceID) (OfflineTable, error)
 	CreateMaterialization(id ResourceID) (Materialization, error)
+	// CreateIncrementalMaterialization materializes the latest value of each
+	// entity that has a row with a timestamp after since. Entities without
+	// newer rows are left out, so the result only holds what changed.
+	CreateIncrementalMaterialization(id ResourceID, since time.Time) (Materialization, error)
 	GetMaterialization(id MaterializationID) (Materialization, error)
 	UpdateMaterialization(id ResourceID) (Materialization, error)
 	DeleteMaterialization(id MaterializationID) error
+	// DeleteTable drops the table or view that backs a resource: a feature
+	// or label's resource table, a primary table, a transformation or a
+	// training set.
+	DeleteTable(id ResourceID) error
 	CreateTrainingSet(TrainingSetDef) error
 	UpdateTrainingSet(TrainingSetDef) error
 	GetTrainingSet(id ResourceID) (TrainingSetIterator, error)
@@ -143,6 +237,55 @@ type OfflineStore interface {
 
 type MaterializationID string
 
+// MaterializationGeneration is a past version of a materialization that was
+// kept after it was replaced, for rollback and time travel. It can be read
+// and deleted like any other materialization.
+type MaterializationGeneration struct {
+	ID      MaterializationID
+	Created time.Time
+}
+
+// GenerationStore is implemented by offline stores that keep past
+// generations of materializations when they're updated.
+type GenerationStore interface {
+	MaterializationGenerations(id ResourceID) ([]MaterializationGeneration, error)
+}
+
+// WindowedMaterializationStore is implemented by offline stores that can
+// materialize a window of a feature's history, for backfilling past runs. The
+// materialization holds the value of each entity as of until, for entities
+// that have a row with a timestamp after since.
+type WindowedMaterializationStore interface {
+	CreateWindowedMaterialization(id ResourceID, since, until time.Time) (Materialization, error)
+}
+
+// TrainingSetCacheStore is implemented by stores that cache feature tables
+// for training sets built in a scheduling cycle. The training set stops
+// using the caches of cycles that started before the given time, and the
+// caches no training set uses any more are dropped.
+type TrainingSetCacheStore interface {
+	PruneTrainingSetCache(id ResourceID, before time.Time) error
+}
+
+// FeatureSelectionStore is implemented by stores that can read a subset of a
+// training set's features without reading the rest. The rows have the given
+// features in the given order.
+type FeatureSelectionStore interface {
+	GetTrainingSetFeatures(id ResourceID, features []ResourceID) (TrainingSetIterator, error)
+}
+
+// TrainingSetFreshness is the latest timestamp of the values that a training
+// set's rows were built from, for its label and each of its features. Feature
+// values are only joined to later labels, so a feature's can be older than
+// the latest value in its table. Inputs that no row used are left out.
+type TrainingSetFreshness map[ResourceID]time.Time
+
+// TrainingSetFreshnessStore is implemented by stores that can tell how
+// recent the values used by a training set are.
+type TrainingSetFreshnessStore interface {
+	TrainingSetFreshness(def TrainingSetDef) (TrainingSetFreshness, error)
+}
+
 type TrainingSetIterator interface {
 	Next() bool
 	Features() []interface{}
@@ -161,6 +304,26 @@ type Materialization interface {
 	ID() MaterializationID
 	NumRows() (int64, error)
 	IterateSegment(begin, end int64) (FeatureIterator, error)
+	Stats() (TableStats, error)
+}
+
+// TableStats summarize the contents of an offline table. SizeBytes is -1 if
+// the store can't tell, and MinTS and MaxTS are zero if the table has no
+// timestamp column or no rows.
+type TableStats struct {
+	NumRows    int64
+	SizeBytes  int64
+	MinTS      time.Time
+	MaxTS      time.Time
+	NullCounts map[string]int64
+}
+
+// TimestampedMaterialization is implemented by materializations that can
+// report the latest timestamp among their rows. It's the zero time if the
+// materialization is empty.
+type TimestampedMaterialization interface {
+	Materialization
+	MaxTimestamp() (time.Time, error)
 }
 
 type FeatureIterator interface {
@@ -211,17 +374,28 @@ type PrimaryTable interface {
 	GetName() string
 	IterateSegment(n int64) (GenericTableIterator, error)
 	NumRows() (int64, error)
+	Stats() (TableStats, error)
 }
 
 type TransformationTable interface {
 	PrimaryTable
 }
 
+// SchemaTable is implemented by tables that can report the types of their
+// columns. Columns with a type that has no ValueType equivalent are NilType.
+type SchemaTable interface {
+	Schema() (TableSchema, error)
+}
+
 type ResourceSchema struct {
 	Entity      string
 	Value       string
 	TS          string
 	SourceTable string
+	// ValidTo is set for slowly changing dimension (SCD2) tables, where TS is
+	// when each row became valid and ValidTo is when it stopped being valid,
+	// or NULL if it's the entity's current row.
+	ValidTo string
 }
 
 type TableSchema struct {
@@ -236,6 +410,7 @@ type TableColumn struct {
 type memoryOfflineStore struct {
 	tables           map[ResourceID]*memoryOfflineTable
 	materializations map[MaterializationID]*memoryMaterialization
+	generations      map[ResourceID][]MaterializationID
 	trainingSets     map[ResourceID]trainingRows
 	BaseProvider
 }
@@ -248,6 +423,7 @@ func NewMemoryOfflineStore() *memoryOfflineStore {
 	return &memoryOfflineStore{
 		tables:           make(map[ResourceID]*memoryOfflineTable),
 		materializations: make(map[MaterializationID]*memoryMaterialization),
+		generations:      make(map[ResourceID][]MaterializationID),
 		trainingSets:     make(map[ResourceID]trainingRows),
 		BaseProvider: BaseProvider{
 			ProviderType:   MemoryOffline,
@@ -300,6 +476,24 @@ func (store *memoryOfflineStore) CreateResourceTable(id ResourceID, schema Table
 	return table, nil
 }
 
+func (store *memoryOfflineStore) DeleteTable(id ResourceID) error {
+	switch id.Type {
+	case Feature, Label:
+		if _, has := store.tables[id]; !has {
+			return &TableNotFound{id.Name, id.Variant}
+		}
+		delete(store.tables, id)
+	case TrainingSet:
+		if _, has := store.trainingSets[id]; !has {
+			return &TrainingSetNotFound{id}
+		}
+		delete(store.trainingSets, id)
+	default:
+		return errors.New("only feature, label and training set tables can be deleted from this provider")
+	}
+	return nil
+}
+
 func (store *memoryOfflineStore) GetResourceTable(id ResourceID) (OfflineTable, error) {
 	return store.getMemoryResourceTable(id)
 }
@@ -328,23 +522,67 @@ func (recs materializedRecords) Swap(i, j int) {
 }
 
 func (store *memoryOfflineStore) CreateMaterialization(id ResourceID) (Materialization, error) {
-	if id.Type != Feature {
-		return nil, errors.New("only features can be materialized")
+	if err := checkMaterializable(id); err != nil {
+		return nil, err
+	}
+	if ids := store.generations[id]; len(ids) > 0 {
+		if latest := ids[len(ids)-1]; store.materializations[latest] != nil {
+			return nil, &MaterializationExists{latest}
+		}
+	}
+	return store.newGeneration(id)
+}
+
+// newGeneration materializes id anew, keeping its previous materializations
+// as generations.
+func (store *memoryOfflineStore) newGeneration(id ResourceID) (Materialization, error) {
+	mat, err := store.createMaterialization(id, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
 	}
+	store.generations[id] = append(store.generations[id], mat.ID())
+	return mat, nil
+}
+
+func (store *memoryOfflineStore) CreateIncrementalMaterialization(id ResourceID, since time.Time) (Materialization, error) {
+	if err := checkMaterializable(id); err != nil {
+		return nil, err
+	}
+	return store.createMaterialization(id, since, time.Time{})
+}
+
+func (store *memoryOfflineStore) CreateWindowedMaterialization(id ResourceID, since, until time.Time) (Materialization, error) {
+	if err := checkMaterializable(id); err != nil {
+		return nil, err
+	}
+	return store.createMaterialization(id, since, until)
+}
+
+func (store *memoryOfflineStore) createMaterialization(id ResourceID, since, until time.Time) (Materialization, error) {
 	table, err := store.getMemoryResourceTable(id)
 	if err != nil {
 		return nil, err
 	}
 	matData := make(materializedRecords, 0, len(table.entityMap))
 	for _, records := range table.entityMap {
+		if !until.IsZero() {
+			records = recordsUntil(records, until)
+			if len(records) == 0 {
+				continue
+			}
+		}
 		matRec := latestRecord(records)
+		if !since.IsZero() && !matRec.TS.After(since) {
+			continue
+		}
 		matData = append(matData, matRec)
 	}
 	sort.Sort(matData)
 	matId := MaterializationID(uuid.NewString())
 	mat := &memoryMaterialization{
-		id:   matId,
-		data: matData,
+		id:      matId,
+		data:    matData,
+		created: time.Now().UTC(),
 	}
 	store.materializations[matId] = mat
 	return mat, nil
@@ -358,6 +596,17 @@ func (err *MaterializationNotFound) Error() string {
 	return fmt.Sprintf("Materialization %s not found", err.id)
 }
 
+// MaterializationExists is returned when creating a materialization whose ID
+// is already taken, rather than returning what's there, which could be from
+// an earlier definition of the resource.
+type MaterializationExists struct {
+	ID MaterializationID
+}
+
+func (err *MaterializationExists) Error() string {
+	return fmt.Sprintf("Materialization %s already exists", err.ID)
+}
+
 func (store *memoryOfflineStore) GetMaterialization(id MaterializationID) (Materialization, error) {
 	mat, has := store.materializations[id]
 	if !has {
@@ -367,7 +616,10 @@ func (store *memoryOfflineStore) GetMaterialization(id MaterializationID) (Mater
 }
 
 func (store *memoryOfflineStore) UpdateMaterialization(id ResourceID) (Materialization, error) {
-	return store.CreateMaterialization(id)
+	if err := checkMaterializable(id); err != nil {
+		return nil, err
+	}
+	return store.newGeneration(id)
 }
 
 func (store *memoryOfflineStore) DeleteMaterialization(id MaterializationID) error {
@@ -378,6 +630,31 @@ func (store *memoryOfflineStore) DeleteMaterialization(id MaterializationID) err
 	return nil
 }
 
+// MaterializationGenerations returns every materialization of id except the
+// latest, since the memory store never replaces materializations in place.
+func (store *memoryOfflineStore) MaterializationGenerations(id ResourceID) ([]MaterializationGeneration, error) {
+	ids := store.generations[id]
+	gens := make([]MaterializationGeneration, 0, len(ids))
+	for i, matID := range ids {
+		mat, has := store.materializations[matID]
+		if !has || i == len(ids)-1 {
+			continue
+		}
+		gens = append(gens, MaterializationGeneration{ID: matID, Created: mat.created})
+	}
+	return gens, nil
+}
+
+func recordsUntil(recs []ResourceRecord, until time.Time) []ResourceRecord {
+	kept := make([]ResourceRecord, 0, len(recs))
+	for _, rec := range recs {
+		if !rec.TS.After(until) {
+			kept = append(kept, rec)
+		}
+	}
+	return kept
+}
+
 func latestRecord(recs []ResourceRecord) ResourceRecord {
 	latest := recs[0]
 	for _, rec := range recs {
@@ -421,6 +698,40 @@ func (store *memoryOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 	return nil
 }
 
+func (store *memoryOfflineStore) TrainingSetFreshness(def TrainingSetDef) (TrainingSetFreshness, error) {
+	if err := def.check(); err != nil {
+		return nil, err
+	}
+	label, err := store.getMemoryResourceTable(def.Label)
+	if err != nil {
+		return nil, err
+	}
+	freshness := make(TrainingSetFreshness)
+	labelRecs := label.records()
+	for _, rec := range labelRecs {
+		if latest, has := freshness[def.Label]; !has || latest.Before(rec.TS) {
+			freshness[def.Label] = rec.TS
+		}
+	}
+	for _, id := range def.Features {
+		feature, err := store.getMemoryResourceTable(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, labelRec := range labelRecs {
+			for _, rec := range feature.entityMap[labelRec.Entity] {
+				if rec.TS.After(labelRec.TS) {
+					continue
+				}
+				if latest, has := freshness[id]; !has || latest.Before(rec.TS) {
+					freshness[id] = rec.TS
+				}
+			}
+		}
+	}
+	return freshness, nil
+}
+
 func (store *memoryOfflineStore) UpdateTrainingSet(def TrainingSetDef) error {
 	return store.CreateTrainingSet(def)
 }
@@ -552,8 +863,9 @@ func (table *memoryOfflineTable) Write(rec ResourceRecord) error {
 }
 
 type memoryMaterialization struct {
-	id   MaterializationID
-	data []ResourceRecord
+	id      MaterializationID
+	data    []ResourceRecord
+	created time.Time
 }
 
 func (mat *memoryMaterialization) ID() MaterializationID {
@@ -564,6 +876,43 @@ func (mat *memoryMaterialization) NumRows() (int64, error) {
 	return int64(len(mat.data)), nil
 }
 
+func (mat *memoryMaterialization) MaxTimestamp() (time.Time, error) {
+	var max time.Time
+	for _, rec := range mat.data {
+		if rec.TS.After(max) {
+			max = rec.TS
+		}
+	}
+	return max, nil
+}
+
+func (mat *memoryMaterialization) Stats() (TableStats, error) {
+	stats := TableStats{
+		NumRows:    int64(len(mat.data)),
+		SizeBytes:  -1,
+		NullCounts: map[string]int64{"entity": 0, "value": 0, "ts": 0},
+	}
+	for _, rec := range mat.data {
+		if rec.Entity == "" {
+			stats.NullCounts["entity"]++
+		}
+		if rec.Value == nil {
+			stats.NullCounts["value"]++
+		}
+		if rec.TS.IsZero() {
+			stats.NullCounts["ts"]++
+			continue
+		}
+		if stats.MinTS.IsZero() || rec.TS.Before(stats.MinTS) {
+			stats.MinTS = rec.TS
+		}
+		if rec.TS.After(stats.MaxTS) {
+			stats.MaxTS = rec.TS
+		}
+	}
+	return stats, nil
+}
+
 func (mat *memoryMaterialization) IterateSegment(start, end int64) (FeatureIterator, error) {
 	segment := mat.data[start:end]
 	return newMemoryFeatureIterator(segment), nil
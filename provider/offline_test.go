@@ -63,13 +63,20 @@ func TestOfflineStores(t *testing.T) {
 		"CreateGetTable":          testCreateGetOfflineTable,
 		"TableAlreadyExists":      testOfflineTableAlreadyExists,
 		"TableNotFound":           testOfflineTableNotFound,
+		"DeleteTable":             testDeleteOfflineTable,
 		"InvalidResourceIDs":      testInvalidResourceIDs,
 		"Materializations":        testMaterializations,
 		"MaterializationUpdate":   testMaterializationUpdate,
+		"MaterializeTwice":        testMaterializeTwice,
+		"MaterializationVariants": testMaterializationVariants,
+		"IncrementalMaterialize":  testIncrementalMaterialization,
+		"WindowedMaterialize":     testWindowedMaterialization,
 		"InvalidResourceRecord":   testWriteInvalidResourceRecord,
 		"InvalidMaterialization":  testInvalidMaterialization,
+		"LabelMaterialization":    testLabelMaterialization,
 		"MaterializeUnknown":      testMaterializeUnknown,
 		"MaterializationNotFound": testMaterializationNotFound,
+		"MaterializationStats":    testMaterializationStats,
 		"TrainingSets":            testTrainingSet,
 		"TrainingSetUpdate":       testTrainingSetUpdate,
 		"TrainingSetInvalidID":    testGetTrainingSetInvalidResourceID,
@@ -78,14 +85,22 @@ func TestOfflineStores(t *testing.T) {
 		"LabelTableNotFound":      testLabelTableNotFound,
 		"FeatureTableNotFound":    testFeatureTableNotFound,
 		"TrainingDefShorthand":    testTrainingSetDefShorthand,
+		"TrainingSetFreshness":    testTrainingSetFreshness,
 	}
 	testSQLFns := map[string]func(*testing.T, OfflineStore){
 		"PrimaryTableCreate":          testPrimaryCreateTable,
 		"PrimaryTableWrite":           testPrimaryTableWrite,
+		"PrimaryTableIterateBatches":  testPrimaryTableIterateBatches,
+		"PrimaryTableStats":           testPrimaryTableStats,
+		"TrainingSetCache":            testTrainingSetCache,
+		"TrainingSetFeatureSelection": testTrainingSetFeatureSelection,
 		"Transformation":              testTransform,
 		"TransformationUpdate":        testTransformUpdate,
+		"ViewOverUpdatedTable":        testViewOverUpdatedTransformation,
 		"CreateDuplicatePrimaryTable": testCreateDuplicatePrimaryTable,
 		"ChainTransformations":        testChainTransform,
+		"SlowlyChangingDimension":     testSlowlyChangingDimension,
+		"StaleStagingMaterialize":     testStaleStagingMaterialization,
 	}
 	testList := []struct {
 		t               Type
@@ -93,6 +108,7 @@ func TestOfflineStores(t *testing.T) {
 		integrationTest bool
 	}{
 		{MemoryOffline, []byte{}, false},
+		{GenericGRPC, serveCustomProvider(t, NewMemoryOfflineStore()), false},
 		{PostgresOffline, serialPGConfig, true},
 		{SnowflakeOffline, serialSFConfig, true},
 		{RedshiftOffline, serialRSConfig, true},
@@ -244,6 +260,31 @@ func testOfflineTableNotFound(t *testing.T, store OfflineStore) {
 	}
 }
 
+func testDeleteOfflineTable(t *testing.T, store OfflineStore) {
+	id := randomID(Feature, Label)
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	if _, err := store.CreateResourceTable(id, schema); err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := store.DeleteTable(id); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	if _, err := store.GetResourceTable(id); err == nil {
+		t.Fatalf("Succeeded in getting deleted table")
+	}
+	if err := store.DeleteTable(id); err == nil {
+		t.Fatalf("Succeeded in deleting table twice")
+	} else if _, valid := err.(*TableNotFound); !valid {
+		t.Fatalf("Wrong error for deleting missing table: %T", err)
+	}
+}
+
 func testMaterializations(t *testing.T, store OfflineStore) {
 	type TestCase struct {
 		WriteRecords             []ResourceRecord
@@ -450,6 +491,221 @@ func testMaterializations(t *testing.T, store OfflineStore) {
 
 }
 
+func testMaterializeTwice(t *testing.T, store OfflineStore) {
+	id := randomID(Feature)
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	table, err := store.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	for _, rec := range []ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}} {
+		if err := table.Write(rec); err != nil {
+			t.Fatalf("Failed to write record %v: %s", rec, err)
+		}
+	}
+	mat, err := store.CreateMaterialization(id)
+	if err != nil {
+		t.Fatalf("Failed to create materialization: %s", err)
+	}
+	if num, err := mat.NumRows(); err != nil {
+		t.Fatalf("Failed to get num rows: %s", err)
+	} else if num != 2 {
+		t.Fatalf("Materialization has %d rows, expected 2", num)
+	}
+	_, err = store.CreateMaterialization(id)
+	var exists *MaterializationExists
+	if !errors.As(err, &exists) || exists.ID != mat.ID() {
+		t.Fatalf("Expected creating the materialization again to fail with MaterializationExists, got %v", err)
+	}
+}
+
+// testStaleStagingMaterialization checks that a staging table left behind by
+// a failed attempt is dropped when the materialization is created again.
+func testStaleStagingMaterialization(t *testing.T, store OfflineStore) {
+	sqlStore, ok := store.(*sqlOfflineStore)
+	if !ok {
+		t.Skip("Not a SQL offline store")
+	}
+	id := randomID(Feature)
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	table, err := store.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.Write(ResourceRecord{Entity: "a", Value: 1}); err != nil {
+		t.Fatalf("Failed to write record: %s", err)
+	}
+	resTable, err := sqlStore.getsqlResourceTable(id)
+	if err != nil {
+		t.Fatalf("Failed to get resource table: %s", err)
+	}
+	stagingName := fmt.Sprintf("staging_%s", sqlStore.getMaterializationTableName(ResourceMaterializationID(id)))
+	create := sqlStore.query.materializationCreate(stagingName, sqlStore.query.dialect().latestValues(resTable.name, time.Time{}, time.Time{}))
+	if _, err := sqlStore.db.Exec(create); err != nil {
+		t.Fatalf("Failed to leave a stale staging table: %s", err)
+	}
+	mat, err := store.CreateMaterialization(id)
+	if err != nil {
+		t.Fatalf("Failed to create materialization over a stale staging table: %s", err)
+	}
+	if num, err := mat.NumRows(); err != nil || num != 1 {
+		t.Fatalf("Materialization has %d rows, expected 1: %v", num, err)
+	}
+}
+
+// testMaterializationVariants checks that each variant of a feature gets its
+// own materialization, rather than sharing the first one's.
+func testMaterializationVariants(t *testing.T, store OfflineStore) {
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	first := randomID(Feature)
+	second := ResourceID{Name: first.Name, Variant: uuid.NewString(), Type: Feature}
+	mats := make([]Materialization, 2)
+	for i, id := range []ResourceID{first, second} {
+		table, err := store.CreateResourceTable(id, schema)
+		if err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		if err := table.Write(ResourceRecord{Entity: "a", Value: i}); err != nil {
+			t.Fatalf("Failed to write record: %s", err)
+		}
+		if mats[i], err = store.CreateMaterialization(id); err != nil {
+			t.Fatalf("Failed to create materialization of %v: %s", id, err)
+		}
+	}
+	if mats[0].ID() == mats[1].ID() {
+		t.Fatalf("Variants share materialization %s", mats[0].ID())
+	}
+	for i, mat := range mats {
+		it, err := mat.IterateSegment(0, 1)
+		if err != nil {
+			t.Fatalf("Failed to iterate materialization: %s", err)
+		}
+		if !it.Next() {
+			t.Fatalf("Materialization %s is empty: %v", mat.ID(), it.Err())
+		}
+		if value := it.Value().Value; fmt.Sprint(value) != fmt.Sprint(i) {
+			t.Fatalf("Materialization %s has value %v, expected %d", mat.ID(), value, i)
+		}
+	}
+}
+
+func testIncrementalMaterialization(t *testing.T, store OfflineStore) {
+	id := randomID(Feature)
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	table, err := store.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	since := time.Unix(1000, 0).UTC()
+	records := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: since.Add(-time.Second)},
+		{Entity: "b", Value: 2, TS: since.Add(-time.Second)},
+		{Entity: "a", Value: 3, TS: since.Add(time.Second)},
+	}
+	for _, rec := range records {
+		if err := table.Write(rec); err != nil {
+			t.Fatalf("Failed to write record %v: %s", rec, err)
+		}
+	}
+	mat, err := store.CreateIncrementalMaterialization(id, since)
+	if err != nil {
+		t.Fatalf("Failed to create incremental materialization: %s", err)
+	}
+	defer store.DeleteMaterialization(mat.ID())
+	if num, err := mat.NumRows(); err != nil {
+		t.Fatalf("Failed to get num rows: %s", err)
+	} else if num != 1 {
+		t.Fatalf("Incremental materialization has %d rows, expected 1", num)
+	}
+	iter, err := mat.IterateSegment(0, 1)
+	if err != nil {
+		t.Fatalf("Failed to iterate materialization: %s", err)
+	}
+	if !iter.Next() {
+		t.Fatalf("Materialization is empty: %v", iter.Err())
+	}
+	if rec := iter.Value(); rec.Entity != "a" || fmt.Sprint(rec.Value) != "3" {
+		t.Fatalf("Incremental materialization has %v, expected a=3", rec)
+	}
+}
+
+func testWindowedMaterialization(t *testing.T, store OfflineStore) {
+	windowed, ok := store.(WindowedMaterializationStore)
+	if !ok {
+		t.Skip("Store doesn't support windowed materializations")
+	}
+	id := randomID(Feature)
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	table, err := store.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	since := time.Unix(1000, 0).UTC()
+	until := since.Add(5 * time.Second)
+	records := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: since.Add(-time.Second)},
+		{Entity: "a", Value: 3, TS: since.Add(time.Second)},
+		{Entity: "a", Value: 5, TS: until.Add(time.Second)},
+		{Entity: "b", Value: 2, TS: since.Add(-time.Second)},
+		{Entity: "c", Value: 4, TS: until.Add(time.Second)},
+	}
+	for _, rec := range records {
+		if err := table.Write(rec); err != nil {
+			t.Fatalf("Failed to write record %v: %s", rec, err)
+		}
+	}
+	mat, err := windowed.CreateWindowedMaterialization(id, since, until)
+	if err != nil {
+		t.Fatalf("Failed to create windowed materialization: %s", err)
+	}
+	defer store.DeleteMaterialization(mat.ID())
+	if num, err := mat.NumRows(); err != nil {
+		t.Fatalf("Failed to get num rows: %s", err)
+	} else if num != 1 {
+		t.Fatalf("Windowed materialization has %d rows, expected 1", num)
+	}
+	iter, err := mat.IterateSegment(0, 1)
+	if err != nil {
+		t.Fatalf("Failed to iterate materialization: %s", err)
+	}
+	if !iter.Next() {
+		t.Fatalf("Materialization is empty: %v", iter.Err())
+	}
+	if rec := iter.Value(); rec.Entity != "a" || fmt.Sprint(rec.Value) != "3" {
+		t.Fatalf("Windowed materialization has %v, expected a=3", rec)
+	}
+}
+
 func testMaterializationUpdate(t *testing.T, store OfflineStore) {
 	type TestCase struct {
 		WriteRecords                           []ResourceRecord
@@ -774,7 +1030,13 @@ func testWriteInvalidResourceRecord(t *testing.T, store OfflineStore) {
 }
 
 func testInvalidMaterialization(t *testing.T, store OfflineStore) {
-	id := randomID(Label)
+	id := randomID(TrainingSet)
+	if _, err := store.CreateMaterialization(id); err == nil {
+		t.Fatalf("Succeeded in materializing training set")
+	}
+}
+
+func testLabelMaterialization(t *testing.T, store OfflineStore) {
 	schema := TableSchema{
 		Columns: []TableColumn{
 			{Name: "entity", ValueType: String},
@@ -782,11 +1044,35 @@ func testInvalidMaterialization(t *testing.T, store OfflineStore) {
 			{Name: "ts", ValueType: Timestamp},
 		},
 	}
-	if _, err := store.CreateResourceTable(id, schema); err != nil {
-		t.Fatalf("Failed to create table: %s", err)
+	labelID := randomID(Label)
+	featureID := ResourceID{Name: labelID.Name, Variant: labelID.Variant, Type: Feature}
+	records := map[ResourceID][]ResourceRecord{
+		labelID:   {{Entity: "a", Value: 1}, {Entity: "b", Value: 2}},
+		featureID: {{Entity: "a", Value: 3}},
 	}
-	if _, err := store.CreateMaterialization(id); err == nil {
-		t.Fatalf("Succeeded in materializing label")
+	for id, recs := range records {
+		table, err := store.CreateResourceTable(id, schema)
+		if err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		for _, rec := range recs {
+			if err := table.Write(rec); err != nil {
+				t.Fatalf("Failed to write record %v: %s", rec, err)
+			}
+		}
+	}
+	// A label and a feature with the same name and variant are materialized
+	// separately.
+	for id, recs := range records {
+		mat, err := store.CreateMaterialization(id)
+		if err != nil {
+			t.Fatalf("Failed to materialize %v: %s", id.Type, err)
+		}
+		if rows, err := mat.NumRows(); err != nil {
+			t.Fatalf("Failed to get num rows: %s", err)
+		} else if rows != int64(len(recs)) {
+			t.Fatalf("%v materialization has %d rows, expected %d", id.Type, rows, len(recs))
+		}
 	}
 }
 
@@ -797,6 +1083,49 @@ func testMaterializeUnknown(t *testing.T, store OfflineStore) {
 	}
 }
 
+func testMaterializationStats(t *testing.T, store OfflineStore) {
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	id := randomID(Feature)
+	table, err := store.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	records := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: time.UnixMilli(1000)},
+		{Entity: "a", Value: 2, TS: time.UnixMilli(3000)},
+		{Entity: "b", Value: 3, TS: time.UnixMilli(2000)},
+	}
+	for _, rec := range records {
+		if err := table.Write(rec); err != nil {
+			t.Fatalf("Failed to write record %v: %s", rec, err)
+		}
+	}
+	mat, err := store.CreateMaterialization(id)
+	if err != nil {
+		t.Fatalf("Failed to create materialization: %s", err)
+	}
+	stats, err := mat.Stats()
+	if err != nil {
+		t.Fatalf("Failed to get materialization stats: %s", err)
+	}
+	if stats.NumRows != 2 {
+		t.Fatalf("Materialization has %d rows, expected 2", stats.NumRows)
+	}
+	if !stats.MinTS.Equal(time.UnixMilli(2000)) || !stats.MaxTS.Equal(time.UnixMilli(3000)) {
+		t.Fatalf("Timestamp range %s to %s, expected %s to %s", stats.MinTS, stats.MaxTS, time.UnixMilli(2000), time.UnixMilli(3000))
+	}
+	expectedNulls := map[string]int64{"entity": 0, "value": 0, "ts": 0}
+	if !reflect.DeepEqual(stats.NullCounts, expectedNulls) {
+		t.Fatalf("Null counts %v, expected %v", stats.NullCounts, expectedNulls)
+	}
+}
+
 func testMaterializationNotFound(t *testing.T, store OfflineStore) {
 	id := MaterializationID(uuid.NewString())
 	_, err := store.GetMaterialization(id)
@@ -1737,6 +2066,317 @@ func testPrimaryCreateTable(t *testing.T, store OfflineStore) {
 	}
 }
 
+func testPrimaryTableIterateBatches(t *testing.T, store OfflineStore) {
+	defer func(prev int) { postgresCursorBatchSize = prev }(postgresCursorBatchSize)
+	postgresCursorBatchSize = 2
+	id := ResourceID{Name: uuid.NewString(), Type: Primary}
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+		},
+	}
+	table, err := store.CreatePrimaryTable(id, schema)
+	if err != nil {
+		t.Fatalf("Could not create table: %v", err)
+	}
+	numRecords := 5
+	for i := 0; i < numRecords; i++ {
+		if err := table.Write(GenericRecord{fmt.Sprintf("e%d", i), i}); err != nil {
+			t.Fatalf("Could not write record: %v", err)
+		}
+	}
+	for _, limit := range []int64{4, 5, 10} {
+		iter, err := table.IterateSegment(limit)
+		if err != nil {
+			t.Fatalf("Could not iterate table: %v", err)
+		}
+		read := 0
+		for iter.Next() {
+			read++
+		}
+		if err := iter.Err(); err != nil {
+			t.Fatalf("Iteration failed: %v", err)
+		}
+		expected := int(limit)
+		if expected > numRecords {
+			expected = numRecords
+		}
+		if read != expected {
+			t.Fatalf("Read %d rows with limit %d, expected %d", read, limit, expected)
+		}
+	}
+}
+
+func testPrimaryTableStats(t *testing.T, store OfflineStore) {
+	id := ResourceID{Name: uuid.NewString(), Type: Primary}
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	table, err := store.CreatePrimaryTable(id, schema)
+	if err != nil {
+		t.Fatalf("Could not create table: %v", err)
+	}
+	records := []GenericRecord{
+		{"a", 1, time.UnixMilli(0).UTC()},
+		{"b", nil, time.UnixMilli(2000).UTC()},
+		{"c", nil, time.UnixMilli(1000).UTC()},
+	}
+	for _, rec := range records {
+		if err := table.Write(rec); err != nil {
+			t.Fatalf("Could not write record: %v", err)
+		}
+	}
+	stats, err := table.Stats()
+	if err != nil {
+		t.Fatalf("Could not get table stats: %v", err)
+	}
+	if stats.NumRows != 3 {
+		t.Fatalf("Table has %d rows, expected 3", stats.NumRows)
+	}
+	expectedNulls := map[string]int64{"entity": 0, "value": 2, "ts": 0}
+	if !reflect.DeepEqual(stats.NullCounts, expectedNulls) {
+		t.Fatalf("Null counts %v, expected %v", stats.NullCounts, expectedNulls)
+	}
+	if !stats.MinTS.Equal(time.UnixMilli(0)) || !stats.MaxTS.Equal(time.UnixMilli(2000)) {
+		t.Fatalf("Timestamp range %s to %s, expected %s to %s", stats.MinTS, stats.MaxTS, time.UnixMilli(0), time.UnixMilli(2000))
+	}
+}
+
+func testTrainingSetCache(t *testing.T, store OfflineStore) {
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	featureID := randomID(Feature)
+	labelID := randomID(Label)
+	featureTable, err := store.CreateResourceTable(featureID, schema)
+	if err != nil {
+		t.Fatalf("Failed to create feature table: %s", err)
+	}
+	labelTable, err := store.CreateResourceTable(labelID, schema)
+	if err != nil {
+		t.Fatalf("Failed to create label table: %s", err)
+	}
+	if err := featureTable.Write(ResourceRecord{Entity: "a", Value: 1, TS: time.UnixMilli(0)}); err != nil {
+		t.Fatalf("Failed to write feature: %s", err)
+	}
+	if err := labelTable.Write(ResourceRecord{Entity: "a", Value: 2, TS: time.UnixMilli(1)}); err != nil {
+		t.Fatalf("Failed to write label: %s", err)
+	}
+	cycle := time.Now().Truncate(time.Second)
+	// Two training sets built in the same cycle share the feature's cache,
+	// until the feature's value is replaced in place.
+	defs := make([]TrainingSetDef, 3)
+	for i := range defs {
+		if i == 2 {
+			if err := featureTable.Write(ResourceRecord{Entity: "a", Value: 3, TS: time.UnixMilli(0)}); err != nil {
+				t.Fatalf("Failed to update feature: %s", err)
+			}
+		}
+		defs[i] = TrainingSetDef{
+			ID:         randomID(TrainingSet),
+			Label:      labelID,
+			Features:   []ResourceID{featureID},
+			CacheCycle: cycle,
+		}
+		if err := store.CreateTrainingSet(defs[i]); err != nil {
+			t.Fatalf("Failed to create training set: %s", err)
+		}
+		iter, err := store.GetTrainingSet(defs[i].ID)
+		if err != nil {
+			t.Fatalf("Failed to get training set: %s", err)
+		}
+		expected := 1
+		if i == 2 {
+			expected = 3
+		}
+		rows := 0
+		for iter.Next() {
+			rows++
+			if feature := iter.Features()[0]; !reflect.DeepEqual(feature, expected) {
+				t.Fatalf("Expected feature value %d, got %v", expected, feature)
+			}
+		}
+		if rows != 1 {
+			t.Fatalf("Expected 1 training set row, got %d", rows)
+		}
+	}
+	cache, ok := store.(TrainingSetCacheStore)
+	if !ok {
+		t.Fatalf("%s store does not cache training set features", store.Type())
+	}
+	for _, def := range defs {
+		if err := cache.PruneTrainingSetCache(def.ID, cycle.Add(time.Second)); err != nil {
+			t.Fatalf("Failed to prune training set cache: %s", err)
+		}
+	}
+}
+
+func testTrainingSetFeatureSelection(t *testing.T, store OfflineStore) {
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	featureIDs := []ResourceID{randomID(Feature), randomID(Feature)}
+	labelID := randomID(Label)
+	for i, id := range append(featureIDs, labelID) {
+		table, err := store.CreateResourceTable(id, schema)
+		if err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		if err := table.Write(ResourceRecord{Entity: "a", Value: i + 1, TS: time.UnixMilli(0)}); err != nil {
+			t.Fatalf("Failed to write record: %s", err)
+		}
+	}
+	def := TrainingSetDef{
+		ID:       randomID(TrainingSet),
+		Label:    labelID,
+		Features: featureIDs,
+	}
+	if err := store.CreateTrainingSet(def); err != nil {
+		t.Fatalf("Failed to create training set: %s", err)
+	}
+	selector, ok := store.(FeatureSelectionStore)
+	if !ok {
+		t.Fatalf("%s store can't select training set features", store.Type())
+	}
+	iter, err := selector.GetTrainingSetFeatures(def.ID, []ResourceID{featureIDs[1]})
+	if err != nil {
+		t.Fatalf("Failed to get training set features: %s", err)
+	}
+	rows := 0
+	for iter.Next() {
+		rows++
+		if features := iter.Features(); len(features) != 1 || !reflect.DeepEqual(features[0], 2) {
+			t.Fatalf("Expected only the second feature, got %v", features)
+		}
+		if label := iter.Label(); !reflect.DeepEqual(label, 3) {
+			t.Fatalf("Expected label 3, got %v", label)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Iteration failed: %s", err)
+	}
+	if rows != 1 {
+		t.Fatalf("Expected 1 training set row, got %d", rows)
+	}
+	if _, err := selector.GetTrainingSetFeatures(def.ID, []ResourceID{randomID(Feature)}); err == nil {
+		t.Fatalf("Succeeded in selecting a feature that isn't in the training set")
+	}
+}
+
+func testSlowlyChangingDimension(t *testing.T, store OfflineStore) {
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "customer", ValueType: String},
+			{Name: "tier", ValueType: Int},
+			{Name: "valid_from", ValueType: Timestamp},
+			{Name: "valid_to", ValueType: Timestamp},
+		},
+	}
+	table, err := store.CreatePrimaryTable(ResourceID{Name: uuid.NewString(), Type: Primary}, schema)
+	if err != nil {
+		t.Fatalf("Could not create primary table: %v", err)
+	}
+	// a moves from tier 1 to tier 2 at 10, and b's only row expires at 5.
+	records := []GenericRecord{
+		{"a", 1, time.UnixMilli(0), time.UnixMilli(10)},
+		{"a", 2, time.UnixMilli(10), nil},
+		{"b", 3, time.UnixMilli(0), time.UnixMilli(5)},
+	}
+	for _, record := range records {
+		if err := table.Write(record); err != nil {
+			t.Fatalf("Could not write record: %v", err)
+		}
+	}
+	featureID := randomID(Feature)
+	recSchema := ResourceSchema{
+		Entity:      "customer",
+		Value:       "tier",
+		TS:          "valid_from",
+		ValidTo:     "valid_to",
+		SourceTable: table.GetName(),
+	}
+	if _, err := store.RegisterResourceFromSourceTable(featureID, recSchema); err != nil {
+		t.Fatalf("Could not register slowly changing dimension: %v", err)
+	}
+	labelID := randomID(Label)
+	labels, err := store.CreateResourceTable(labelID, TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Could not create label table: %v", err)
+	}
+	// Each label is the tier its customer should have at its time, if any.
+	expected := map[interface{}]interface{}{1: 1, 2: 2, 3: 3, 4: nil}
+	labelRecords := []ResourceRecord{
+		{Entity: "a", Value: 1, TS: time.UnixMilli(7)},
+		{Entity: "a", Value: 2, TS: time.UnixMilli(12)},
+		{Entity: "b", Value: 3, TS: time.UnixMilli(3)},
+		{Entity: "b", Value: 4, TS: time.UnixMilli(8)},
+	}
+	for _, record := range labelRecords {
+		if err := labels.Write(record); err != nil {
+			t.Fatalf("Could not write label: %v", err)
+		}
+	}
+	def := TrainingSetDef{ID: randomID(TrainingSet), Label: labelID, Features: []ResourceID{featureID}}
+	if err := store.CreateTrainingSet(def); err != nil {
+		t.Fatalf("Could not create training set: %v", err)
+	}
+	iter, err := store.GetTrainingSet(def.ID)
+	if err != nil {
+		t.Fatalf("Could not get training set: %v", err)
+	}
+	rows := 0
+	for iter.Next() {
+		rows++
+		label, features := iter.Label(), iter.Features()
+		if !reflect.DeepEqual(features[0], expected[label]) {
+			t.Fatalf("Label %v has tier %v, expected the tier valid at its time, %v", label, features[0], expected[label])
+		}
+	}
+	if err := iter.Err(); err != nil {
+		t.Fatalf("Iteration failed: %v", err)
+	}
+	if rows != len(labelRecords) {
+		t.Fatalf("Expected %d training set rows, got %d", len(labelRecords), rows)
+	}
+	mat, err := store.CreateMaterialization(featureID)
+	if err != nil {
+		t.Fatalf("Could not materialize slowly changing dimension: %v", err)
+	}
+	featureIter, err := mat.IterateSegment(0, 10)
+	if err != nil {
+		t.Fatalf("Could not iterate materialization: %v", err)
+	}
+	latest := map[string]interface{}{}
+	for featureIter.Next() {
+		latest[featureIter.Value().Entity] = featureIter.Value().Value
+	}
+	if err := featureIter.Err(); err != nil {
+		t.Fatalf("Materialization iteration failed: %v", err)
+	}
+	if !reflect.DeepEqual(latest, map[string]interface{}{"a": 2, "b": nil}) {
+		t.Fatalf("Expected a's current tier and no tier for b, got %v", latest)
+	}
+}
+
 func testPrimaryTableWrite(t *testing.T, store OfflineStore) {
 	type TestCase struct {
 		Rec         ResourceID
@@ -2170,6 +2810,66 @@ func testTransformUpdate(t *testing.T, store OfflineStore) {
 
 }
 
+func testViewOverUpdatedTransformation(t *testing.T, store OfflineStore) {
+	primaryID := ResourceID{Name: uuid.NewString(), Type: Primary}
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "int", ValueType: Int},
+		},
+	}
+	primary, err := store.CreatePrimaryTable(primaryID, schema)
+	if err != nil {
+		t.Fatalf("Could not initialize table: %v", err)
+	}
+	for _, rec := range []GenericRecord{{"a", 1}, {"b", 2}} {
+		if err := primary.Write(rec); err != nil {
+			t.Fatalf("Could not write value: %v: %v", err, rec)
+		}
+	}
+	base := TransformationConfig{
+		TargetTableID: ResourceID{Name: uuid.NewString(), Type: Transformation},
+		Query:         fmt.Sprintf("SELECT * FROM %s", sanitize(primary.GetName())),
+	}
+	if err := store.CreateTransformation(base); err != nil {
+		t.Fatalf("Could not create transformation: %v", err)
+	}
+	baseTable, err := store.GetTransformationTable(base.TargetTableID)
+	if err != nil {
+		t.Fatalf("Could not get transformation table: %v", err)
+	}
+	view := TransformationConfig{
+		TargetTableID: ResourceID{Name: uuid.NewString(), Type: Transformation},
+		Query:         fmt.Sprintf("SELECT COUNT(*) FROM %s", sanitize(baseTable.GetName())),
+		Storage:       ViewStorage,
+	}
+	if err := store.CreateTransformation(view); err != nil {
+		t.Fatalf("Could not create view: %v", err)
+	}
+	if err := primary.Write(GenericRecord{"c", 3}); err != nil {
+		t.Fatalf("Could not write value: %v", err)
+	}
+	// Replacing the table the view selects from must keep the view, and have
+	// it select from the new table.
+	if err := store.UpdateTransformation(base); err != nil {
+		t.Fatalf("Could not update transformation with a view over it: %v", err)
+	}
+	viewTable, err := store.GetTransformationTable(view.TargetTableID)
+	if err != nil {
+		t.Fatalf("Could not get view: %v", err)
+	}
+	iterator, err := viewTable.IterateSegment(10)
+	if err != nil {
+		t.Fatalf("Could not iterate view: %v", err)
+	}
+	if !iterator.Next() {
+		t.Fatalf("View has no rows: %v", iterator.Err())
+	}
+	if count := iterator.Values(); !reflect.DeepEqual(count, GenericRecord{3}) {
+		t.Fatalf("Expected view to count 3 rows of the updated table, got %v", count)
+	}
+}
+
 func testTransformCreateFeature(t *testing.T, store OfflineStore) {
 	type TransformTest struct {
 		PrimaryTable ResourceID
@@ -2954,3 +3654,63 @@ func Test_snowflakeOfflineTable_checkTimestamp(t *testing.T) {
 		})
 	}
 }
+
+func testTrainingSetFreshness(t *testing.T, store OfflineStore) {
+	freshnessStore, ok := store.(TrainingSetFreshnessStore)
+	if !ok {
+		t.Skip("Store doesn't support training set freshness")
+	}
+	schema := TableSchema{
+		Columns: []TableColumn{
+			{Name: "entity", ValueType: String},
+			{Name: "value", ValueType: Int},
+			{Name: "ts", ValueType: Timestamp},
+		},
+	}
+	start := time.Unix(1000, 0).UTC()
+	fId, lId, unusedId := randomID(Feature), randomID(Label), randomID(Feature)
+	tables := map[ResourceID][]ResourceRecord{
+		fId: {
+			{Entity: "a", Value: 1, TS: start},
+			{Entity: "a", Value: 2, TS: start.Add(2 * time.Second)},
+			// Newer than every label, so no row uses it.
+			{Entity: "a", Value: 3, TS: start.Add(time.Hour)},
+		},
+		lId: {
+			{Entity: "a", Value: 1, TS: start.Add(time.Second)},
+			{Entity: "a", Value: 0, TS: start.Add(3 * time.Second)},
+		},
+		unusedId: {
+			{Entity: "b", Value: 1, TS: start},
+		},
+	}
+	for id, records := range tables {
+		table, err := store.CreateResourceTable(id, schema)
+		if err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		for _, rec := range records {
+			if err := table.Write(rec); err != nil {
+				t.Fatalf("Failed to write record %v: %s", rec, err)
+			}
+		}
+	}
+	def := TrainingSetDef{
+		ID:       randomID(TrainingSet),
+		Label:    lId,
+		Features: []ResourceID{fId, unusedId},
+	}
+	freshness, err := freshnessStore.TrainingSetFreshness(def)
+	if err != nil {
+		t.Fatalf("Failed to get training set freshness: %s", err)
+	}
+	if latest := freshness[lId]; !latest.Equal(start.Add(3 * time.Second)) {
+		t.Fatalf("Label freshness is %v, expected %v", latest, start.Add(3*time.Second))
+	}
+	if latest := freshness[fId]; !latest.Equal(start.Add(2 * time.Second)) {
+		t.Fatalf("Feature freshness is %v, expected %v", latest, start.Add(2*time.Second))
+	}
+	if latest, has := freshness[unusedId]; has {
+		t.Fatalf("Unused feature has freshness %v", latest)
+	}
+}
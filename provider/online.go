@@ -6,12 +6,15 @@ package provider
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net"
+	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/gocql/gocql"
-	sn "github.com/mrz1836/go-sanitize"
 )
 
 const (
@@ -29,11 +32,15 @@ var cassandraTypeMap = map[string]string{
 	"float32": "float",
 	"float64": "double",
 	"bool":    "boolean",
+	"bytes":   "blob",
+	// Decimals are stored as their exact text.
+	"decimal": "text",
 }
 
 type OnlineStore interface {
 	GetTable(feature, variant string) (OnlineStoreTable, error)
 	CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error)
+	DeleteTable(feature, variant string) error
 	Provider
 }
 
@@ -42,6 +49,71 @@ type OnlineStoreTable interface {
 	Get(entity string) (interface{}, error)
 }
 
+// IterableOnlineStoreTable is implemented by tables that can list the
+// entities they hold, which is needed to copy a table to another store.
+type IterableOnlineStoreTable interface {
+	OnlineStoreTable
+	Entities() ([]string, error)
+}
+
+// TimestampedOnlineStoreTable is implemented by tables that keep the time
+// each of their values is as of, so that a value is never replaced by one
+// that's older, like when a backfill copies historical values.
+type TimestampedOnlineStoreTable interface {
+	OnlineStoreTable
+	// SetIfNewer sets entity's value unless the one it has is as of a later
+	// time than ts, and returns whether it was set.
+	SetIfNewer(entity string, value interface{}, ts time.Time) (bool, error)
+	// DeleteIfNewer removes entity's value as of ts, unless the one it has
+	// is as of a later time, and returns whether it was removed. The time is
+	// kept, so that an older value isn't set again afterwards.
+	DeleteIfNewer(entity string, ts time.Time) (bool, error)
+}
+
+// DeletableOnlineStoreTable is implemented by tables that can remove an
+// entity's value, like when the row for it in a slowly changing dimension
+// expires, so that the entity isn't found rather than served a stale value.
+type DeletableOnlineStoreTable interface {
+	OnlineStoreTable
+	Delete(entity string) error
+}
+
+// BatchOnlineStoreTable is implemented by tables that can read many entities
+// in a single round trip. Entities that aren't found have a nil value.
+type BatchOnlineStoreTable interface {
+	OnlineStoreTable
+	BatchGet(c context.Context, entities []string) ([]interface{}, error)
+}
+
+// BatchGet reads entities from table in a batch if it supports it, and one at
+// a time otherwise. Values and errors are in the order of entities; an entity
+// that isn't found has an EntityNotFound error. The returned error is only
+// set if the batch couldn't be read at all.
+func BatchGet(c context.Context, table OnlineStoreTable, entities []string) ([]interface{}, []error, error) {
+	errs := make([]error, len(entities))
+	batchTable, ok := table.(BatchOnlineStoreTable)
+	if !ok {
+		vals := make([]interface{}, len(entities))
+		for i, entity := range entities {
+			vals[i], errs[i] = GetWithContext(c, table, entity)
+		}
+		return vals, errs, nil
+	}
+	vals, err := batchTable.BatchGet(c, entities)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(vals) != len(entities) {
+		return nil, nil, fmt.Errorf("read %d values for %d entities", len(vals), len(entities))
+	}
+	for i, val := range vals {
+		if val == nil {
+			errs[i] = &EntityNotFound{entities[i]}
+		}
+	}
+	return vals, errs, nil
+}
+
 type TableNotFound struct {
 	Feature, Variant string
 }
@@ -83,6 +155,17 @@ func (t redisTableKey) String() string {
 	return string(marshalled)
 }
 
+// tableName is the Cassandra table that holds a variant's values. Each
+// variant gets its own, so that deleting one leaves the others' values. It's
+// named after a hash of the feature and variant, since Cassandra table names
+// are limited to 48 alphanumeric characters, and the variant it belongs to is
+// recorded in tableMetadata.
+func (t cassandraTableKey) tableName() string {
+	nameVariant, _ := json.Marshal([]string{t.Feature, t.Variant})
+	hash := sha256.Sum256(nameVariant)
+	return fmt.Sprintf("%s.table_%s", t.Keyspace, hex.EncodeToString(hash[:16]))
+}
+
 func (t cassandraTableKey) String() string {
 	marshalled, err := json.Marshal(t)
 	if err != nil {
@@ -103,15 +186,30 @@ type localOnlineStore struct {
 type redisOnlineStore struct {
 	client *redis.Client
 	prefix string
+	// username and token authenticate connections when using IAM auth.
+	username string
+	token    tokenFn
 	BaseProvider
 }
 
+func (store *redisOnlineStore) CheckHealth(ctx context.Context) error {
+	return store.client.Ping(ctx).Err()
+}
+
+func (store *redisOnlineStore) Close() error {
+	return store.client.Close()
+}
+
 type cassandraOnlineStore struct {
 	session  *gocql.Session
 	keyspace string
 	BaseProvider
 }
 
+func (store *cassandraOnlineStore) CheckHealth(ctx context.Context) error {
+	return store.session.Query("SELECT now() FROM system.local").WithContext(ctx).Exec()
+}
+
 func NewLocalOnlineStore() *localOnlineStore {
 	return &localOnlineStore{
 		make(map[tableKey]localOnlineTable),
@@ -130,7 +228,7 @@ func redisOnlineStoreFactory(serialized SerializedConfig) (Provider, error) {
 	if redisConfig.Prefix == "" {
 		redisConfig.Prefix = "Featureform_table__"
 	}
-	return NewRedisOnlineStore(redisConfig), nil
+	return NewRedisOnlineStore(redisConfig)
 }
 
 func cassandraOnlineStoreFactory(serialized SerializedConfig) (Provider, error) {
@@ -145,15 +243,60 @@ func cassandraOnlineStoreFactory(serialized SerializedConfig) (Provider, error)
 	return NewCassandraOnlineStore(cassandraConfig)
 }
 
-func NewRedisOnlineStore(options *RedisConfig) *redisOnlineStore {
+func NewRedisOnlineStore(options *RedisConfig) (*redisOnlineStore, error) {
+	host, _, err := net.SplitHostPort(options.Addr)
+	if err != nil {
+		host = options.Addr
+	}
+	if err := options.validateIAM(); err != nil {
+		return nil, fmt.Errorf("invalid redis auth config: %w", err)
+	}
+	tlsOptions := options.TLSConfig
+	if options.usesIAM() && tlsOptions.SSLMode == "" {
+		// IAM auth tokens are only accepted over TLS.
+		tlsOptions.SSLMode = SSLVerifyFull
+	}
+	tlsConfig, err := tlsOptions.tlsConfig(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis tls config: %w", err)
+	}
 	redisOptions := &redis.Options{
-		Addr: options.Addr,
+		Addr:      options.Addr,
+		TLSConfig: tlsConfig,
+	}
+	var token tokenFn
+	if options.usesIAM() {
+		if options.AuthMethod == AWSIAMAuth && (options.Username == "" || options.CacheName == "") {
+			return nil, fmt.Errorf("invalid redis auth config: username and cache name required for %s auth", AWSIAMAuth)
+		}
+		token = options.elastiCacheToken(options.CacheName, options.Username)
+		redisOptions.OnConnect = redisIAMAuth(options.Username, token)
 	}
 	redisClient := redis.NewClient(redisOptions)
-	return &redisOnlineStore{redisClient, options.Prefix, BaseProvider{
-		ProviderType:   RedisOnline,
-		ProviderConfig: options.Serialized(),
-	},
+	return &redisOnlineStore{
+		client:   redisClient,
+		prefix:   options.Prefix,
+		username: options.Username,
+		token:    token,
+		BaseProvider: BaseProvider{
+			ProviderType:   RedisOnline,
+			ProviderConfig: options.Serialized(),
+		},
+	}, nil
+}
+
+// redisIAMAuth authenticates each new connection with a fresh token, since
+// tokens expire while pooled connections stay open.
+func redisIAMAuth(username string, token tokenFn) func(context.Context, *redis.Conn) error {
+	return func(ctx context.Context, cn *redis.Conn) error {
+		password, err := token(ctx)
+		if err != nil {
+			return err
+		}
+		if username == "" {
+			return cn.Auth(ctx, password).Err()
+		}
+		return cn.AuthACL(ctx, username, password).Err()
 	}
 }
 
@@ -161,6 +304,15 @@ func NewCassandraOnlineStore(options *CassandraConfig) (*cassandraOnlineStore, e
 
 	cassandraCluster := gocql.NewCluster(options.Addr)
 	cassandraCluster.Consistency = options.Consistency
+	// The server name is left unset so that each node's certificate is
+	// verified against its own host name.
+	tlsConfig, err := options.tlsConfig("")
+	if err != nil {
+		return nil, fmt.Errorf("invalid cassandra tls config: %w", err)
+	}
+	if tlsConfig != nil {
+		cassandraCluster.SslOpts = &gocql.SslOptions{Config: tlsConfig, EnableHostVerification: !tlsConfig.InsecureSkipVerify}
+	}
 	newSession, err := cassandraCluster.CreateSession()
 	if err != nil {
 		return nil, err
@@ -173,11 +325,14 @@ func NewCassandraOnlineStore(options *CassandraConfig) (*cassandraOnlineStore, e
 		return nil, err
 	}
 
-	query = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (tableName text PRIMARY KEY, tableType text)", fmt.Sprintf("%s.tableMetadata", options.keyspace))
+	query = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (tableName text PRIMARY KEY, tableType text, feature text, variant text)", fmt.Sprintf("%s.tableMetadata", options.keyspace))
 	err = newSession.Query(query).WithContext(ctx).Exec()
 	if err != nil {
 		return nil, err
 	}
+	if err := addCassandraMetadataColumns(newSession, options.keyspace); err != nil {
+		return nil, err
+	}
 
 	return &cassandraOnlineStore{newSession, options.keyspace, BaseProvider{
 		ProviderType:   CassandraOnline,
@@ -186,6 +341,22 @@ func NewCassandraOnlineStore(options *CassandraConfig) (*cassandraOnlineStore, e
 	}, nil
 }
 
+// addCassandraMetadataColumns adds the feature and variant columns to a
+// tableMetadata table made before they were recorded.
+func addCassandraMetadataColumns(session *gocql.Session, keyspace string) error {
+	var column string
+	query := "SELECT column_name FROM system_schema.columns WHERE keyspace_name = ? AND table_name = 'tablemetadata' AND column_name = 'feature'"
+	err := session.Query(query, keyspace).WithContext(ctx).Scan(&column)
+	if err == nil {
+		return nil
+	}
+	if err != gocql.ErrNotFound {
+		return err
+	}
+	query = fmt.Sprintf("ALTER TABLE %s.tableMetadata ADD (feature text, variant text)", keyspace)
+	return session.Query(query).WithContext(ctx).Exec()
+}
+
 func (store *localOnlineStore) AsOnlineStore() (OnlineStore, error) {
 	return store, nil
 }
@@ -211,11 +382,20 @@ func (store *localOnlineStore) CreateTable(feature, variant string, valueType Va
 	if _, has := store.tables[key]; has {
 		return nil, &TableAlreadyExists{feature, variant}
 	}
-	table := make(localOnlineTable)
+	table := localOnlineTable{values: make(map[string]interface{}), asOf: make(map[string]time.Time)}
 	store.tables[key] = table
 	return table, nil
 }
 
+func (store *localOnlineStore) DeleteTable(feature, variant string) error {
+	key := tableKey{feature, variant}
+	if _, has := store.tables[key]; !has {
+		return &TableNotFound{feature, variant}
+	}
+	delete(store.tables, key)
+	return nil
+}
+
 func (store *redisOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
 	key := redisTableKey{store.prefix, feature, variant}
 	vType, err := store.client.HGet(ctx, fmt.Sprintf("%s__tables", store.prefix), key.String()).Result()
@@ -244,19 +424,35 @@ func (store *redisOnlineStore) CreateTable(feature, variant string, valueType Va
 
 }
 
+func (store *redisOnlineStore) DeleteTable(feature, variant string) error {
+	key := redisTableKey{store.prefix, feature, variant}
+	tablesKey := fmt.Sprintf("%s__tables", store.prefix)
+	exists, err := store.client.HExists(ctx, tablesKey, key.String()).Result()
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return &TableNotFound{feature, variant}
+	}
+	if err := store.client.Del(ctx, key.String(), key.asOfKey()).Err(); err != nil {
+		return err
+	}
+	return store.client.HDel(ctx, tablesKey, key.String()).Err()
+}
+
 func (store *cassandraOnlineStore) CreateTable(feature, variant string, valueType ValueType) (OnlineStoreTable, error) {
 
-	tableName := fmt.Sprintf("%s.table%s", store.keyspace, sn.Custom(feature, "[^a-zA-Z0-9_]"))
-	vType := cassandraTypeMap[string(valueType)]
 	key := cassandraTableKey{store.keyspace, feature, variant}
+	tableName := key.tableName()
+	vType := cassandraTypeMap[string(valueType)]
 	getTable, _ := store.GetTable(feature, variant)
 	if getTable != nil {
 		return nil, &TableAlreadyExists{feature, variant}
 	}
 
 	metadataTableName := fmt.Sprintf("%s.tableMetadata", store.keyspace)
-	query := fmt.Sprintf("INSERT INTO %s (tableName, tableType) VALUES (?, ?)", metadataTableName)
-	err := store.session.Query(query, tableName, string(valueType)).WithContext(ctx).Exec()
+	query := fmt.Sprintf("INSERT INTO %s (tableName, tableType, feature, variant) VALUES (?, ?, ?, ?)", metadataTableName)
+	err := store.session.Query(query, tableName, string(valueType), feature, variant).WithContext(ctx).Exec()
 	if err != nil {
 		return nil, err
 	}
@@ -279,13 +475,13 @@ func (store *cassandraOnlineStore) CreateTable(feature, variant string, valueTyp
 
 func (store *cassandraOnlineStore) GetTable(feature, variant string) (OnlineStoreTable, error) {
 
-	tableName := fmt.Sprintf("%s.table%s", store.keyspace, sn.Custom(feature, "[^a-zA-Z0-9_]"))
 	key := cassandraTableKey{store.keyspace, feature, variant}
+	tableName := key.tableName()
 
 	var vType string
 	metadataTableName := fmt.Sprintf("%s.tableMetadata", store.keyspace)
-	query := fmt.Sprintf("SELECT tableType FROM %s WHERE tableName = '%s'", metadataTableName, tableName)
-	err := store.session.Query(query).WithContext(ctx).Scan(&vType)
+	query := fmt.Sprintf("SELECT tableType FROM %s WHERE tableName = ?", metadataTableName)
+	err := store.session.Query(query, tableName).WithContext(ctx).Scan(&vType)
 	if err == gocql.ErrNotFound {
 		return nil, &TableNotFound{feature, variant}
 	}
@@ -302,7 +498,25 @@ func (store *cassandraOnlineStore) GetTable(feature, variant string) (OnlineStor
 	return table, nil
 }
 
-type localOnlineTable map[string]interface{}
+func (store *cassandraOnlineStore) DeleteTable(feature, variant string) error {
+	tableName := cassandraTableKey{store.keyspace, feature, variant}.tableName()
+	if _, err := store.GetTable(feature, variant); err != nil {
+		return err
+	}
+	query := fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)
+	if err := store.session.Query(query).WithContext(ctx).Exec(); err != nil {
+		return err
+	}
+	metadataTableName := fmt.Sprintf("%s.tableMetadata", store.keyspace)
+	query = fmt.Sprintf("DELETE FROM %s WHERE tableName = ?", metadataTableName)
+	return store.session.Query(query, tableName).WithContext(ctx).Exec()
+}
+
+type localOnlineTable struct {
+	values map[string]interface{}
+	// asOf is the time of each value that was set with SetIfNewer.
+	asOf map[string]time.Time
+}
 
 type redisOnlineTable struct {
 	client    *redis.Client
@@ -317,19 +531,53 @@ type cassandraOnlineTable struct {
 }
 
 func (table localOnlineTable) Set(entity string, value interface{}) error {
-	table[entity] = value
+	table.values[entity] = value
+	return nil
+}
+
+func (table localOnlineTable) SetIfNewer(entity string, value interface{}, ts time.Time) (bool, error) {
+	if current, has := table.asOf[entity]; has && current.After(ts) {
+		return false, nil
+	}
+	table.values[entity] = value
+	table.asOf[entity] = ts
+	return true, nil
+}
+
+func (table localOnlineTable) DeleteIfNewer(entity string, ts time.Time) (bool, error) {
+	if current, has := table.asOf[entity]; has && current.After(ts) {
+		return false, nil
+	}
+	delete(table.values, entity)
+	table.asOf[entity] = ts
+	return true, nil
+}
+
+func (table localOnlineTable) Delete(entity string) error {
+	delete(table.values, entity)
 	return nil
 }
 
 func (table localOnlineTable) Get(entity string) (interface{}, error) {
-	val, has := table[entity]
+	val, has := table.values[entity]
 	if !has {
 		return nil, &EntityNotFound{entity}
 	}
 	return val, nil
 }
 
+func (table localOnlineTable) BatchGet(c context.Context, entities []string) ([]interface{}, error) {
+	vals := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		vals[i] = table.values[entity]
+	}
+	return vals, nil
+}
+
 func (table redisOnlineTable) Set(entity string, value interface{}) error {
+	if decimal, ok := value.(DecimalValue); ok {
+		value = string(decimal)
+	}
 	val := table.client.HSet(ctx, table.key.String(), entity, value)
 	if val.Err() != nil {
 		return val.Err()
@@ -337,11 +585,149 @@ func (table redisOnlineTable) Set(entity string, value interface{}) error {
 	return nil
 }
 
+// redisSetIfNewer sets a value in the hash KEYS[1], and its time in
+// microseconds in the hash KEYS[2], unless the time there is later.
+var redisSetIfNewer = redis.NewScript(redisSetIfNewerSource)
+
+const redisSetIfNewerSource = `
+local current = redis.call("HGET", KEYS[2], ARGV[1])
+if current and tonumber(current) > tonumber(ARGV[3]) then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+redis.call("HSET", KEYS[2], ARGV[1], ARGV[3])
+return 1
+`
+
+// redisDeleteIfNewer removes a value from the hash KEYS[1], and sets the time
+// it was removed as of in microseconds in the hash KEYS[2], unless the time
+// there is later.
+var redisDeleteIfNewer = redis.NewScript(redisDeleteIfNewerSource)
+
+const redisDeleteIfNewerSource = `
+local current = redis.call("HGET", KEYS[2], ARGV[1])
+if current and tonumber(current) > tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("HDEL", KEYS[1], ARGV[1])
+redis.call("HSET", KEYS[2], ARGV[1], ARGV[2])
+return 1
+`
+
+// asOfKey is the hash that the time of each value set with SetIfNewer is
+// kept in.
+func (t redisTableKey) asOfKey() string {
+	return fmt.Sprintf("%s__as_of", t)
+}
+
+func (table redisOnlineTable) SetIfNewer(entity string, value interface{}, ts time.Time) (bool, error) {
+	if decimal, ok := value.(DecimalValue); ok {
+		value = string(decimal)
+	}
+	set, err := redisSetIfNewer.Run(ctx, table.client, []string{table.key.String(), table.key.asOfKey()}, entity, value, ts.UnixMicro()).Int()
+	if err != nil {
+		return false, err
+	}
+	return set == 1, nil
+}
+
+func (table redisOnlineTable) DeleteIfNewer(entity string, ts time.Time) (bool, error) {
+	deleted, err := redisDeleteIfNewer.Run(ctx, table.client, []string{table.key.String(), table.key.asOfKey()}, entity, ts.UnixMicro()).Int()
+	if err != nil {
+		return false, err
+	}
+	return deleted == 1, nil
+}
+
+func (table redisOnlineTable) Delete(entity string) error {
+	return table.client.HDel(ctx, table.key.String(), entity).Err()
+}
+
+func (table localOnlineTable) Entities() ([]string, error) {
+	entities := make([]string, 0, len(table.values))
+	for entity := range table.values {
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+func (table redisOnlineTable) Entities() ([]string, error) {
+	return table.client.HKeys(ctx, table.key.String()).Result()
+}
+
+func (table cassandraOnlineTable) Entities() ([]string, error) {
+	key := table.key
+	tableName := key.tableName()
+	iter := table.session.Query(fmt.Sprintf("SELECT entity FROM %s", tableName)).WithContext(ctx).Iter()
+	entities := make([]string, 0)
+	var entity string
+	for iter.Scan(&entity) {
+		entities = append(entities, entity)
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
 func (table redisOnlineTable) Get(entity string) (interface{}, error) {
-	val := table.client.HGet(ctx, table.key.String(), entity)
+	return table.GetWithContext(ctx, entity)
+}
+
+// GetWithContext names the connection after the request ID for the duration
+// of the read, so it shows up in SLOWLOG and CLIENT LIST.
+func (table redisOnlineTable) GetWithContext(c context.Context, entity string) (interface{}, error) {
+	var val *redis.StringCmd
+	if id, ok := RequestID(c); ok {
+		pipe := table.client.Pipeline()
+		pipe.ClientSetName(c, "featureform:"+id)
+		val = pipe.HGet(c, table.key.String(), entity)
+		pipe.ClientSetName(c, "")
+		pipe.Exec(c)
+	} else {
+		val = table.client.HGet(c, table.key.String(), entity)
+	}
 	if val.Err() != nil {
 		return nil, &EntityNotFound{entity}
 	}
+	return table.parseValue(val)
+}
+
+// BatchGet reads every entity with a single HMGET.
+func (table redisOnlineTable) BatchGet(c context.Context, entities []string) ([]interface{}, error) {
+	if len(entities) == 0 {
+		return []interface{}{}, nil
+	}
+	var cmd *redis.SliceCmd
+	if id, ok := RequestID(c); ok {
+		pipe := table.client.Pipeline()
+		pipe.ClientSetName(c, "featureform:"+id)
+		cmd = pipe.HMGet(c, table.key.String(), entities...)
+		pipe.ClientSetName(c, "")
+		pipe.Exec(c)
+	} else {
+		cmd = table.client.HMGet(c, table.key.String(), entities...)
+	}
+	raw, err := cmd.Result()
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]interface{}, len(raw))
+	for i, r := range raw {
+		str, ok := r.(string)
+		if !ok {
+			continue
+		}
+		vals[i], err = table.parseValue(redis.NewStringResult(str, nil))
+		if err != nil {
+			return nil, fmt.Errorf("entity %s: %w", entities[i], err)
+		}
+	}
+	return vals, nil
+}
+
+// parseValue converts a hash field read from Redis to the table's type.
+func (table redisOnlineTable) parseValue(val *redis.StringCmd) (interface{}, error) {
 	var result interface{}
 	var err error
 	switch table.valueType {
@@ -357,6 +743,12 @@ func (table redisOnlineTable) Get(entity string) (interface{}, error) {
 		result, err = val.Float64()
 	case Bool:
 		result, err = val.Bool()
+	case Bytes:
+		result, err = val.Bytes()
+	case Decimal:
+		var str string
+		str, err = val.Result()
+		result = DecimalValue(str)
 	}
 	if err != nil {
 		return nil, err
@@ -367,7 +759,10 @@ func (table redisOnlineTable) Get(entity string) (interface{}, error) {
 func (table cassandraOnlineTable) Set(entity string, value interface{}) error {
 
 	key := table.key
-	tableName := fmt.Sprintf("%s.table%s", key.Keyspace, sn.Custom(key.Feature, "[^a-zA-Z0-9_]"))
+	tableName := key.tableName()
+	if decimal, ok := value.(DecimalValue); ok {
+		value = string(decimal)
+	}
 	query := fmt.Sprintf("INSERT INTO %s (entity, value) VALUES (?, ?)", tableName)
 	err := table.session.Query(query, entity, value).WithContext(ctx).Exec()
 	if err != nil {
@@ -377,10 +772,19 @@ func (table cassandraOnlineTable) Set(entity string, value interface{}) error {
 	return nil
 }
 
+func (table cassandraOnlineTable) Delete(entity string) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE entity = ?", table.key.tableName())
+	return table.session.Query(query, entity).WithContext(ctx).Exec()
+}
+
 func (table cassandraOnlineTable) Get(entity string) (interface{}, error) {
+	return table.GetWithContext(ctx, entity)
+}
+
+func (table cassandraOnlineTable) GetWithContext(c context.Context, entity string) (interface{}, error) {
 
 	key := table.key
-	tableName := fmt.Sprintf("%s.table%s", key.Keyspace, sn.Custom(key.Feature, "[^a-zA-Z0-9_]"))
+	tableName := key.tableName()
 
 	var ptr interface{}
 	switch table.valueType {
@@ -394,14 +798,16 @@ func (table cassandraOnlineTable) Get(entity string) (interface{}, error) {
 		ptr = new(float64)
 	case Bool:
 		ptr = new(bool)
-	case String, NilType:
+	case String, NilType, Decimal:
 		ptr = new(string)
+	case Bytes:
+		ptr = new([]byte)
 	default:
 		return nil, fmt.Errorf("Data type not recognized")
 	}
 
-	query := fmt.Sprintf("SELECT value FROM %s WHERE entity = '%s'", tableName, entity)
-	err := table.session.Query(query).WithContext(ctx).Scan(ptr)
+	query := fmt.Sprintf("%sSELECT value FROM %s WHERE entity = '%s'", requestComment(c), tableName, entity)
+	err := table.session.Query(query).WithContext(c).Scan(ptr)
 	if err == gocql.ErrNotFound {
 		return nil, &EntityNotFound{entity}
 	}
@@ -423,6 +829,11 @@ func (table cassandraOnlineTable) Get(entity string) (interface{}, error) {
 		val = *casted
 	case *string:
 		val = *casted
+		if table.valueType == Decimal {
+			val = DecimalValue(*casted)
+		}
+	case *[]byte:
+		val = *casted
 	default:
 		return nil, fmt.Errorf("Data type not recognized")
 	}
@@ -5,10 +5,15 @@
 package provider
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"reflect"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/alicebob/miniredis"
 	"github.com/gocql/gocql"
@@ -34,9 +39,12 @@ func TestOnlineStores(t *testing.T) {
 		"CreateGetTable":     testCreateGetTable,
 		"TableAlreadyExists": testTableAlreadyExists,
 		"TableNotFound":      testTableNotFound,
+		"DeleteTable":        testDeleteTable,
+		"DeleteTableVariant": testDeleteTableVariant,
 		"SetGetEntity":       testSetGetEntity,
 		"EntityNotFound":     testEntityNotFound,
 		"TypeCasting":        testTypeCasting,
+		"BatchGet":           testBatchGet,
 	}
 
 	miniRedis := mockRedis()
@@ -63,6 +71,7 @@ func TestOnlineStores(t *testing.T) {
 		integrationTest bool
 	}{
 		{LocalOnline, []byte{}, false},
+		{GenericGRPC, serveCustomProvider(t, NewLocalOnlineStore()), false},
 		{RedisOnline, redisMockConfig.Serialized(), false},
 		{RedisOnline, redisLiveConfig.Serialized(), true},
 		{CassandraOnline, cassandraConfig.Serialized(), true},
@@ -134,6 +143,49 @@ func testTableNotFound(t *testing.T, store OnlineStore) {
 	}
 }
 
+func testDeleteTable(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	if _, err := store.CreateTable(mockFeature, mockVariant, String); err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := store.DeleteTable(mockFeature, mockVariant); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	if _, err := store.GetTable(mockFeature, mockVariant); err == nil {
+		t.Fatalf("Succeeded in getting deleted table")
+	}
+	if err := store.DeleteTable(mockFeature, mockVariant); err == nil {
+		t.Fatalf("Succeeded in deleting table twice")
+	} else if _, valid := err.(*TableNotFound); !valid {
+		t.Fatalf("Wrong error for deleting missing table: %T", err)
+	}
+}
+
+func testDeleteTableVariant(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	otherVariant := mockVariant + "_other"
+	if _, err := store.CreateTable(mockFeature, mockVariant, String); err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	other, err := store.CreateTable(mockFeature, otherVariant, String)
+	if err != nil {
+		t.Fatalf("Failed to create table of other variant: %s", err)
+	}
+	if err := other.Set("e", "val"); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	if err := store.DeleteTable(mockFeature, mockVariant); err != nil {
+		t.Fatalf("Failed to delete table: %s", err)
+	}
+	other, err = store.GetTable(mockFeature, otherVariant)
+	if err != nil {
+		t.Fatalf("Deleting a variant's table deleted another variant's: %s", err)
+	}
+	if val, err := other.Get("e"); err != nil || val != "val" {
+		t.Fatalf("Deleting a variant's table lost another variant's values: %v %s", val, err)
+	}
+}
+
 func testSetGetEntity(t *testing.T, store OnlineStore) {
 	mockFeature, mockVariant := randomFeatureVariant()
 	entity, val := "e", "val"
@@ -169,6 +221,32 @@ func testEntityNotFound(t *testing.T, store OnlineStore) {
 	}
 }
 
+func testBatchGet(t *testing.T, store OnlineStore) {
+	mockFeature, mockVariant := randomFeatureVariant()
+	tab, err := store.CreateTable(mockFeature, mockVariant, Float64)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	for entity, val := range map[string]float64{"a": 1.5, "c": 3.5} {
+		if err := tab.Set(entity, val); err != nil {
+			t.Fatalf("Failed to set entity: %s", err)
+		}
+	}
+	vals, errs, err := BatchGet(context.Background(), tab, []string{"c", "b", "a"})
+	if err != nil {
+		t.Fatalf("Failed to batch get entities: %s", err)
+	}
+	if !reflect.DeepEqual(vals[0], 3.5) || !reflect.DeepEqual(vals[2], 1.5) {
+		t.Fatalf("Values not in order of entities: %v", vals)
+	}
+	if errs[0] != nil || errs[2] != nil {
+		t.Fatalf("Found entities have errors: %v", errs)
+	}
+	if _, ok := errs[1].(*EntityNotFound); !ok {
+		t.Fatalf("Wrong error for entity not found: %T", errs[1])
+	}
+}
+
 func testTypeCasting(t *testing.T, store OnlineStore) {
 	onlineResources := []OnlineResource{
 		{
@@ -220,3 +298,279 @@ func testTypeCasting(t *testing.T, store OnlineStore) {
 		}
 	}
 }
+
+func TestRedisGetWithRequestID(t *testing.T) {
+	miniRedis := mockRedis()
+	defer miniRedis.Close()
+	store, err := NewRedisOnlineStore(&RedisConfig{Addr: miniRedis.Addr(), Prefix: "Featureform_table__"})
+	if err != nil {
+		t.Fatalf("Failed to create redis store: %s", err)
+	}
+	table, err := store.CreateTable("feature", "variant", String)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.Set("a", "value"); err != nil {
+		t.Fatalf("Failed to set entity: %s", err)
+	}
+	ctx := WithRequestID(context.Background(), "abc-123 */ DROP")
+	if id, _ := RequestID(ctx); id != "abc-123DROP" {
+		t.Fatalf("Request ID not sanitized: %s", id)
+	}
+	val, err := GetWithContext(ctx, table, "a")
+	if err != nil {
+		t.Fatalf("Failed to get entity: %s", err)
+	}
+	if val != "value" {
+		t.Fatalf("Unexpected value: %v", val)
+	}
+	if _, err := GetWithContext(ctx, table, "b"); err == nil {
+		t.Fatalf("Succeeded in getting missing entity")
+	}
+}
+
+func TestRedisBytesAndDecimals(t *testing.T) {
+	miniRedis := mockRedis()
+	defer miniRedis.Close()
+	store, err := NewRedisOnlineStore(&RedisConfig{Addr: miniRedis.Addr(), Prefix: "Featureform_table__"})
+	if err != nil {
+		t.Fatalf("Failed to create redis store: %s", err)
+	}
+	tests := map[ValueType]interface{}{
+		Bytes:   []byte{0, 1, 255},
+		Decimal: DecimalValue("12345678901234567890.000000000000000001"),
+	}
+	for valueType, value := range tests {
+		table, err := store.CreateTable("feature", string(valueType), valueType)
+		if err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		if err := table.Set("a", value); err != nil {
+			t.Fatalf("Failed to set %s entity: %s", valueType, err)
+		}
+		table, err = store.GetTable("feature", string(valueType))
+		if err != nil {
+			t.Fatalf("Failed to get table: %s", err)
+		}
+		val, err := table.Get("a")
+		if err != nil {
+			t.Fatalf("Failed to get %s entity: %s", valueType, err)
+		}
+		if !reflect.DeepEqual(val, value) {
+			t.Fatalf("%s value not equal: %#v != %#v", valueType, val, value)
+		}
+	}
+}
+
+func TestLastWritten(t *testing.T) {
+	store := NewLocalOnlineStore()
+	if written, err := LastWritten(store, "feature", "variant"); err != nil {
+		t.Fatalf("Failed to get missing last written time: %v", err)
+	} else if !written.IsZero() {
+		t.Fatalf("Expected no last written time, got %v", written)
+	}
+	expected := time.Date(2022, 5, 10, 12, 30, 20, 5, time.UTC)
+	for i := 0; i < 2; i++ {
+		if err := SetLastWritten(store, "feature", "variant", expected); err != nil {
+			t.Fatalf("Failed to set last written time: %v", err)
+		}
+	}
+	if written, err := LastWritten(store, "feature", "variant"); err != nil {
+		t.Fatalf("Failed to get last written time: %v", err)
+	} else if !written.Equal(expected) {
+		t.Fatalf("Expected last written time %v, got %v", expected, written)
+	}
+	if written, err := LastWritten(store, "feature", "other"); err != nil || !written.IsZero() {
+		t.Fatalf("Expected no last written time for another variant, got %v: %v", written, err)
+	}
+}
+
+func TestCassandraTableName(t *testing.T) {
+	long := strings.Repeat("feature", 20)
+	keys := []cassandraTableKey{
+		{"keyspace", "a_b", "c"},
+		{"keyspace", "a", "b_c"},
+		{"keyspace", "a-b", "c"},
+		{"keyspace", long, long},
+	}
+	names := make(map[string]cassandraTableKey)
+	for _, key := range keys {
+		name := strings.TrimPrefix(key.tableName(), "keyspace.")
+		if len(name) > 48 {
+			t.Fatalf("Table name of %v is longer than 48 characters: %s", key, name)
+		}
+		if other, has := names[name]; has {
+			t.Fatalf("Table names of %v and %v collide: %s", key, other, name)
+		}
+		names[name] = key
+	}
+}
+
+func TestLocalListTables(t *testing.T) {
+	store := NewLocalOnlineStore()
+	if _, err := store.CreateTable("feature", "variant", String); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	label := ResourceID{Name: "label", Variant: "variant", Type: Label}
+	if _, err := store.CreateTable(MaterializedName(label), label.Variant, String); err != nil {
+		t.Fatalf("Failed to create label table: %v", err)
+	}
+	if err := SetLastWritten(store, "feature", "variant", time.Now()); err != nil {
+		t.Fatalf("Failed to set last written time: %v", err)
+	}
+	tables, err := store.ListTables()
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	expected := []ResourceID{{Name: "feature", Variant: "variant", Type: Feature}, label}
+	if !reflect.DeepEqual(tables, expected) {
+		t.Fatalf("Expected tables %v, got %v", expected, tables)
+	}
+}
+
+func TestOnlineSetIfNewer(t *testing.T) {
+	miniRedis := mockRedis()
+	defer miniRedis.Close()
+	redisConfig := &RedisConfig{Addr: miniRedis.Addr()}
+	stores := map[Type]SerializedConfig{
+		LocalOnline: []byte{},
+		RedisOnline: redisConfig.Serialized(),
+	}
+	for typ, config := range stores {
+		t.Run(string(typ), func(t *testing.T) {
+			provider, err := Get(typ, config)
+			if err != nil {
+				t.Fatalf("Failed to get provider %s: %s", typ, err)
+			}
+			store, err := provider.AsOnlineStore()
+			if err != nil {
+				t.Fatalf("Failed to use provider %s as OnlineStore: %s", typ, err)
+			}
+			table, err := store.CreateTable("feature", "variant", Int)
+			if err != nil {
+				t.Fatalf("Failed to create table: %s", err)
+			}
+			timestamped, ok := table.(TimestampedOnlineStoreTable)
+			if !ok {
+				t.Fatalf("%s tables don't support timestamped writes", typ)
+			}
+			now := time.Now().UTC()
+			writes := []struct {
+				Value   int
+				TS      time.Time
+				Written bool
+				Expect  int
+			}{
+				{1, now, true, 1},
+				{2, now.Add(-time.Hour), false, 1},
+				{3, now.Add(time.Hour), true, 3},
+				{4, now.Add(time.Hour), true, 4},
+			}
+			for _, write := range writes {
+				written, err := timestamped.SetIfNewer("entity", write.Value, write.TS)
+				if err != nil {
+					t.Fatalf("Failed to set value: %s", err)
+				}
+				if written != write.Written {
+					t.Fatalf("Expected write of %d to be %v, got %v", write.Value, write.Written, written)
+				}
+				value, err := table.Get("entity")
+				if err != nil {
+					t.Fatalf("Failed to get value: %s", err)
+				}
+				if value != write.Expect {
+					t.Fatalf("Expected %d after writing %d, got %v", write.Expect, write.Value, value)
+				}
+			}
+			if err := store.DeleteTable("feature", "variant"); err != nil {
+				t.Fatalf("Failed to delete table: %s", err)
+			}
+			if redisStore, ok := store.(*redisOnlineStore); ok {
+				asOf := redisTableKey{redisStore.prefix, "feature", "variant"}.asOfKey()
+				if miniRedis.Exists(asOf) {
+					t.Fatalf("Expected %s to be deleted with its table", asOf)
+				}
+			}
+		})
+	}
+}
+
+func TestRedisBulkLoad(t *testing.T) {
+	miniRedis := mockRedis()
+	defer miniRedis.Close()
+	store, err := NewRedisOnlineStore(&RedisConfig{Addr: miniRedis.Addr(), Prefix: "Featureform_table__"})
+	if err != nil {
+		t.Fatalf("Failed to create redis store: %s", err)
+	}
+	archiver, err := NewArchiver("file://" + t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create archiver: %s", err)
+	}
+	table, err := store.CreateTable("feature", "variant", Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	now := time.Now().UTC()
+	if _, err := table.(TimestampedOnlineStoreTable).SetIfNewer("b", 20, now.Add(time.Hour)); err != nil {
+		t.Fatalf("Failed to set newer value: %s", err)
+	}
+	if _, err := table.(TimestampedOnlineStoreTable).SetIfNewer("d", 4, now.Add(-time.Hour)); err != nil {
+		t.Fatalf("Failed to set older value: %s", err)
+	}
+	// d's null value is an expired entity, which is removed.
+	records := []ResourceRecord{{Entity: "a", Value: 1, TS: now}, {Entity: "b", Value: 2, TS: now}, {Entity: "c", Value: int64(3), TS: now}, {Entity: "d", Value: nil, TS: now}}
+	file, err := os.CreateTemp(t.TempDir(), "chunk")
+	if err != nil {
+		t.Fatalf("Failed to create file: %s", err)
+	}
+	defer file.Close()
+	count, err := store.WriteBulkLoad(file, table, &sliceFeatureIterator{records: records})
+	if err != nil {
+		t.Fatalf("Failed to write bulk load: %s", err)
+	}
+	if count != int64(len(records)) {
+		t.Fatalf("Wrote %d records, expected %d", count, len(records))
+	}
+	ctx := context.Background()
+	if err := ArchiveFile(ctx, archiver, "chunk-0", file); err != nil {
+		t.Fatalf("Failed to archive chunk: %s", err)
+	}
+	if err := store.BulkLoad(ctx, archiver.(ArchiveReader), "chunk-0"); err != nil {
+		t.Fatalf("Failed to bulk load: %s", err)
+	}
+	for entity, expected := range map[string]int{"a": 1, "b": 20, "c": 3} {
+		value, err := table.Get(entity)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %s", entity, err)
+		}
+		if value != expected {
+			t.Fatalf("Expected %s to be %d after bulk load, got %v", entity, expected, value)
+		}
+	}
+	var notFound *EntityNotFound
+	if value, err := table.Get("d"); !errors.As(err, &notFound) {
+		t.Fatalf("Expected expired entity d not to be found after bulk load, got %v %v", value, err)
+	}
+	if err := store.BulkLoad(ctx, archiver.(ArchiveReader), "missing"); err == nil {
+		t.Fatalf("Expected bulk loading a missing object to fail")
+	}
+}
+
+type sliceFeatureIterator struct {
+	records []ResourceRecord
+	i       int
+}
+
+func (it *sliceFeatureIterator) Next() bool {
+	it.i++
+	return it.i <= len(it.records)
+}
+
+func (it *sliceFeatureIterator) Value() ResourceRecord {
+	return it.records[it.i-1]
+}
+
+func (it *sliceFeatureIterator) Err() error {
+	return nil
+}
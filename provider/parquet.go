@@ -0,0 +1,181 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+// ParquetColumn is a column of a Parquet file written by a ParquetWriter.
+type ParquetColumn struct {
+	Name string
+	Type ValueType
+}
+
+// DefaultParquetRowGroupSize is about how many bytes of rows a ParquetWriter
+// holds before writing them out as a row group.
+const DefaultParquetRowGroupSize = 64 * 1024 * 1024
+
+// ParquetWriter writes rows to a Parquet file as they're written, a row group
+// at a time, so that only the rows of the current row group are held in
+// memory. Every column is optional so that nil values can be written. Values
+// of a type with no Parquet equivalent are written as strings.
+type ParquetWriter struct {
+	columns []ParquetColumn
+	writer  *writer.CSVWriter
+}
+
+// NewParquetWriter starts a Parquet file of columns on w, with row groups of
+// about rowGroupSize bytes, or DefaultParquetRowGroupSize if it's zero.
+func NewParquetWriter(w io.Writer, columns []ParquetColumn, rowGroupSize int64) (*ParquetWriter, error) {
+	schema := make([]string, len(columns))
+	for i, col := range columns {
+		schema[i] = fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", col.Name, parquetType(col.Type))
+	}
+	pw, err := writer.NewCSVWriterFromWriter(schema, w, 1)
+	if err != nil {
+		return nil, fmt.Errorf("create parquet writer: %w", err)
+	}
+	if rowGroupSize > 0 {
+		pw.RowGroupSize = rowGroupSize
+	} else {
+		pw.RowGroupSize = DefaultParquetRowGroupSize
+	}
+	return &ParquetWriter{columns: columns, writer: pw}, nil
+}
+
+// Write adds a row to the file, whose values are in the order of its
+// columns.
+func (w *ParquetWriter) Write(row []interface{}) error {
+	// The writer holds on to the rows of the current row group, so each
+	// needs a slice of its own.
+	values := make([]interface{}, len(w.columns))
+	for i, col := range w.columns {
+		if i >= len(row) || row[i] == nil {
+			continue
+		}
+		value, err := parquetValue(col.Type, row[i])
+		if err != nil {
+			return fmt.Errorf("write parquet column %s: %w", col.Name, err)
+		}
+		values[i] = value
+	}
+	return w.writer.Write(values)
+}
+
+// Close writes the last row group and the file's footer. It doesn't close
+// the underlying writer.
+func (w *ParquetWriter) Close() error {
+	return w.writer.WriteStop()
+}
+
+// WriteParquet writes rows as a Parquet file.
+func WriteParquet(w io.Writer, columns []ParquetColumn, rows [][]interface{}) error {
+	pw, err := NewParquetWriter(w, columns, 0)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := pw.Write(row); err != nil {
+			return err
+		}
+	}
+	return pw.Close()
+}
+
+// parquetType returns the schema tags of a column's physical and converted
+// types.
+func parquetType(t ValueType) string {
+	switch t {
+	case Int32:
+		return "type=INT32"
+	case Int, Int64:
+		return "type=INT64"
+	case Float32:
+		return "type=FLOAT"
+	case Float64:
+		return "type=DOUBLE"
+	case Bool:
+		return "type=BOOLEAN"
+	case Timestamp:
+		return "type=INT64, convertedtype=TIMESTAMP_MICROS"
+	case Bytes:
+		return "type=BYTE_ARRAY"
+	default:
+		return "type=BYTE_ARRAY, convertedtype=UTF8"
+	}
+}
+
+// parquetValue converts a value to the Go type that the writer takes for a
+// column of type t.
+func parquetValue(t ValueType, value interface{}) (interface{}, error) {
+	switch t {
+	case Int32:
+		v, err := parquetInt(value)
+		return int32(v), err
+	case Int, Int64:
+		return parquetInt(value)
+	case Float32:
+		v, err := parquetFloat64(value)
+		return float32(v), err
+	case Float64:
+		return parquetFloat64(value)
+	case Bool:
+		v, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%v is a %T, not a bool", value, value)
+		}
+		return v, nil
+	case Timestamp:
+		v, ok := value.(time.Time)
+		if !ok {
+			return nil, fmt.Errorf("%v is a %T, not a timestamp", value, value)
+		}
+		return v.UnixNano() / int64(time.Microsecond), nil
+	default:
+		switch typed := value.(type) {
+		case string:
+			return typed, nil
+		case []byte:
+			return string(typed), nil
+		default:
+			return fmt.Sprint(value), nil
+		}
+	}
+}
+
+func parquetInt(value interface{}) (int64, error) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), nil
+	case int32:
+		return int64(v), nil
+	case int64:
+		return v, nil
+	default:
+		return 0, fmt.Errorf("%v is a %T, not an int", value, value)
+	}
+}
+
+func parquetFloat64(value interface{}) (float64, error) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), nil
+	case float64:
+		return v, nil
+	case int:
+		return float64(v), nil
+	case int32:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	default:
+		return 0, fmt.Errorf("%v is a %T, not a float", value, value)
+	}
+}
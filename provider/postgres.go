@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/url"
 	"strings"
 	"time"
 
@@ -19,6 +21,8 @@ const (
 	pgString                       = "varchar"
 	pgBool                         = "boolean"
 	pgTimestamp                    = "timestamp with time zone"
+	pgBytes                        = "bytea"
+	pgDecimal                      = "numeric"
 )
 
 type PostgresConfig struct {
@@ -27,6 +31,8 @@ type PostgresConfig struct {
 	Username string `json:"Username"`
 	Password string `json:"Password"`
 	Database string `json:"Database"`
+	TLSConfig
+	IAMConfig
 }
 
 func (pg *PostgresConfig) Deserialize(config SerializedConfig) error {
@@ -45,20 +51,48 @@ func (pg *PostgresConfig) Serialize() []byte {
 	return conf
 }
 
+func (pg *PostgresConfig) connectionURL(password string) string {
+	// IAM auth tokens are only accepted over TLS.
+	fallback := SSLDisable
+	if pg.usesIAM() {
+		fallback = SSLRequire
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(pg.Username, password),
+		Host:     net.JoinHostPort(pg.Host, pg.Port),
+		Path:     pg.Database,
+		RawQuery: pg.connectionParams(fallback).Encode(),
+	}
+	return u.String()
+}
+
 func postgresOfflineStoreFactory(config SerializedConfig) (Provider, error) {
 	sc := PostgresConfig{}
 	if err := sc.Deserialize(config); err != nil {
 		return nil, fmt.Errorf("invalid postgres config: %v", config)
 	}
+	if err := sc.validate(); err != nil {
+		return nil, fmt.Errorf("invalid postgres tls config: %w", err)
+	}
+	if err := sc.validateIAM(); err != nil {
+		return nil, fmt.Errorf("invalid postgres auth config: %w", err)
+	}
 	queries := postgresSQLQueries{}
 	queries.setVariableBinding(PostgresBindingStyle)
 	sgConfig := SQLOfflineStoreConfig{
 		Config:        config,
-		ConnectionURL: fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable", sc.Username, sc.Password, sc.Host, sc.Port, sc.Database),
+		ConnectionURL: sc.connectionURL(sc.Password),
 		Driver:        "postgres",
 		ProviderType:  PostgresOffline,
 		QueryImpl:     &queries,
 	}
+	if sc.usesIAM() {
+		sgConfig.Connector = iamConnector{
+			dsn:   sc.connectionURL,
+			token: sc.rdsToken(net.JoinHostPort(sc.Host, sc.Port), sc.Username),
+		}
+	}
 
 	store, err := NewSQLOfflineStore(sgConfig)
 	if err != nil {
@@ -75,35 +109,22 @@ func (q postgresSQLQueries) tableExists() string {
 	return "SELECT COUNT(*) FROM pg_tables WHERE  tablename  = $1"
 }
 
+// viewExists counts materialized views too, since Postgres lists them apart
+// from other views.
 func (q postgresSQLQueries) viewExists() string {
-	return "select count(*) from pg_views where viewname = $1"
-}
-
-func (q postgresSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
-	var query string
-	if timestamp {
-		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
-			sanitize(schema.Entity), sanitize(schema.Value), sanitize(schema.TS), sanitize(schema.SourceTable))
-	} else {
-		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, to_timestamp('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMPTZ as ts FROM %s", sanitize(tableName),
-			sanitize(schema.Entity), sanitize(schema.Value), time.UnixMilli(0).UTC(), sanitize(schema.SourceTable))
-	}
-	fmt.Printf("Resource creation query: %s", query)
-	if _, err := db.Exec(query); err != nil {
-		return err
-	}
-	return nil
+	return "SELECT (SELECT COUNT(*) FROM pg_views WHERE viewname = $1) + (SELECT COUNT(*) FROM pg_matviews WHERE matviewname = $1)"
 }
 
 func (q postgresSQLQueries) primaryTableRegister(tableName string, sourceName string) string {
 	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sourceName)
 }
 
-func (q postgresSQLQueries) materializationCreate(tableName string, sourceName string) string {
-	return fmt.Sprintf(
-		"CREATE MATERIALIZED VIEW IF NOT EXISTS %s AS (SELECT entity, value, ts, row_number() over(ORDER BY (SELECT NULL)) as row_number FROM "+
-			"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) "+
-			"AS rn FROM %s) t WHERE rn=1);  CREATE UNIQUE INDEX ON %s (entity);", sanitize(tableName), sanitize(sourceName), sanitize(tableName))
+func (q postgresSQLQueries) dialect() Dialect {
+	return postgresDialect{}
+}
+
+func (q postgresSQLQueries) materializationCreate(tableName string, query string) string {
+	return fmt.Sprintf("CREATE MATERIALIZED VIEW IF NOT EXISTS %s AS (%s);  CREATE UNIQUE INDEX ON %s (entity);", sanitize(tableName), query, sanitize(tableName))
 }
 
 func (q postgresSQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
@@ -111,82 +132,133 @@ func (q postgresSQLQueries) materializationUpdate(db *sql.DB, tableName string,
 	return err
 }
 
+func (q postgresSQLQueries) materializationSwap(db *sql.DB, stagingName string, tableName string) error {
+	_, err := db.Exec(fmt.Sprintf("ALTER MATERIALIZED VIEW %s RENAME TO %s", sanitize(stagingName), sanitize(tableName)))
+	return err
+}
+
+// stagingDrop drops a staging materialization, which is a materialized view
+// like the materializations themselves.
+func (q postgresSQLQueries) stagingDrop(stagingName string) string {
+	return fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s", sanitize(stagingName))
+}
+
 func (q postgresSQLQueries) materializationExists() string {
 	return "SELECT * FROM pg_matviews WHERE matviewname = $1"
 }
 
-func (q postgresSQLQueries) determineColumnType(valueType ValueType) (string, error) {
-	switch valueType {
-	case Int, Int32, Int64:
-		return "INT", nil
-	case Float32, Float64:
-		return "FLOAT8", nil
-	case String:
-		return "VARCHAR", nil
-	case Bool:
-		return "BOOLEAN", nil
-	case Timestamp:
-		return "TIMESTAMPTZ", nil
-	case NilType:
-		return "VARCHAR", nil
-	default:
-		return "", fmt.Errorf("cannot find column type for value type: %s", valueType)
+// postgresCursorBatchSize is how many rows are fetched from a cursor at a
+// time, which bounds how much of a result is held in memory.
+var postgresCursorBatchSize = 10000
+
+// iterateRows runs query behind a server-side cursor, so primary tables and
+// materializations too large to hold in memory can still be iterated.
+func (q postgresSQLQueries) iterateRows(db *sql.DB, query string, args ...interface{}) (sqlRows, error) {
+	return openPostgresCursor(db, postgresCursorBatchSize, query, args...)
+}
+
+const postgresCursorName = "featureform_iterator"
+
+// postgresCursor reads a query's results in batches from a cursor. A cursor
+// only lives as long as its transaction, which is held open until the
+// results have been read or the cursor is closed.
+type postgresCursor struct {
+	tx        *sql.Tx
+	batchSize int
+	batch     *sql.Rows
+	// batchRows is how many rows have been read from the current batch. A
+	// batch that's shorter than batchSize is the last one.
+	batchRows int
+	err       error
+	closed    bool
+}
+
+func openPostgresCursor(db *sql.DB, batchSize int, query string, args ...interface{}) (*postgresCursor, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
 	}
+	declare := fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", postgresCursorName, query)
+	if _, err := tx.Exec(declare, args...); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("declare cursor: %w", err)
+	}
+	cursor := &postgresCursor{tx: tx, batchSize: batchSize}
+	if err := cursor.fetch(); err != nil {
+		cursor.Close()
+		return nil, err
+	}
+	return cursor, nil
 }
 
-func (q postgresSQLQueries) newSQLOfflineTable(name string, columnType string) string {
-	return fmt.Sprintf("CREATE TABLE %s (entity VARCHAR, value %s, ts TIMESTAMPTZ, UNIQUE (entity, ts))", sanitize(name), columnType)
+func (c *postgresCursor) fetch() error {
+	rows, err := c.tx.Query(fmt.Sprintf("FETCH FORWARD %d FROM %s", c.batchSize, postgresCursorName))
+	if err != nil {
+		return fmt.Errorf("fetch from cursor: %w", err)
+	}
+	c.batch = rows
+	c.batchRows = 0
+	return nil
 }
 
-func (q postgresSQLQueries) createValuePlaceholderString(columns []TableColumn) string {
-	placeholders := make([]string, 0)
-	for i := range columns {
-		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+func (c *postgresCursor) Next() bool {
+	if c.closed {
+		return false
+	}
+	for {
+		if c.batch.Next() {
+			c.batchRows++
+			return true
+		}
+		if err := c.batch.Err(); err != nil {
+			c.err = err
+			return false
+		}
+		c.batch.Close()
+		if c.batchRows < c.batchSize {
+			return false
+		}
+		if err := c.fetch(); err != nil {
+			c.err = err
+			return false
+		}
 	}
-	return strings.Join(placeholders, ", ")
 }
 
-func (q postgresSQLQueries) trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
-	return q.trainingSetQuery(store, def, tableName, labelName, false)
+func (c *postgresCursor) Scan(dest ...interface{}) error {
+	return c.batch.Scan(dest...)
 }
 
-func (q postgresSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
-	return q.trainingSetQuery(store, def, tableName, labelName, true)
+func (c *postgresCursor) Columns() ([]string, error) {
+	return c.batch.Columns()
 }
 
-func (q postgresSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
-	columns := make([]string, 0)
-	query := fmt.Sprintf(" (SELECT entity, value , ts from %s ) l ", sanitize(labelName))
-	for i, feature := range def.Features {
-		tableName, err := store.getResourceTableName(feature)
-		if err != nil {
-			return err
-		}
-		santizedName := sanitize(tableName)
-		tableJoinAlias := fmt.Sprintf("t%d", i)
-		columns = append(columns, santizedName)
-		query = fmt.Sprintf("%s LEFT JOIN LATERAL (SELECT entity , value as %s, ts  FROM %s WHERE entity=l.entity and ts <= l.ts ORDER BY ts desc LIMIT 1) %s on %s.entity=l.entity ",
-			query, santizedName, santizedName, tableJoinAlias, tableJoinAlias)
-		if i == len(def.Features)-1 {
-			query = fmt.Sprintf("%s )", query)
-		}
+func (c *postgresCursor) ColumnTypes() ([]*sql.ColumnType, error) {
+	return c.batch.ColumnTypes()
+}
+
+func (c *postgresCursor) Err() error {
+	return c.err
+}
+
+// Close ends the transaction, which closes the cursor along with it.
+func (c *postgresCursor) Close() error {
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	if c.batch != nil {
+		c.batch.Close()
 	}
-	columnStr := strings.Join(columns, ", ")
+	return c.tx.Rollback()
+}
 
-	if !isUpdate {
-		fullQuery := fmt.Sprintf("CREATE TABLE %s AS (SELECT %s, l.value as label FROM %s ", sanitize(tableName), columnStr, query)
-		if _, err := store.db.Exec(fullQuery); err != nil {
-			return err
-		}
-	} else {
-		tempName := sanitize(fmt.Sprintf("tmp_%s", tableName))
-		fullQuery := fmt.Sprintf("CREATE TABLE %s AS (SELECT %s, l.value as label FROM %s ", tempName, columnStr, query)
-		err := q.atomicUpdate(store.db, tableName, tempName, fullQuery)
-		if err != nil {
-			return err
-		}
+func (q postgresSQLQueries) createValuePlaceholderString(columns []TableColumn) string {
+	placeholders := make([]string, 0)
+	for i := range columns {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
 	}
-	return nil
+	return strings.Join(placeholders, ", ")
 }
 
 func (q postgresSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
@@ -206,12 +278,23 @@ func (q postgresSQLQueries) castTableItemType(v interface{}, t interface{}) inte
 		return v.(bool)
 	case pgTimestamp:
 		return v.(time.Time).UTC()
+	case pgBytes:
+		return v.([]byte)
+	case pgDecimal:
+		return decimalValue(v)
 	default:
 		return v
 	}
 }
 
 func (q postgresSQLQueries) getValueColumnType(t *sql.ColumnType) interface{} {
+	// The driver scans both of these as bytes.
+	switch t.DatabaseTypeName() {
+	case "NUMERIC":
+		return pgDecimal
+	case "BYTEA":
+		return pgBytes
+	}
 	switch t.ScanType().String() {
 	case "string":
 		return pgString
@@ -233,6 +316,15 @@ func (q postgresSQLQueries) numRows(n interface{}) (int64, error) {
 	return n.(int64), nil
 }
 
+func (q postgresSQLQueries) tableSize(db *sql.DB, tableName string) (int64, error) {
+	qry := "SELECT CASE WHEN relkind = 'v' THEN -1 ELSE pg_total_relation_size(oid) END FROM pg_class WHERE oid = to_regclass($1)"
+	var size int64
+	if err := db.QueryRow(qry, sanitize(tableName)).Scan(&size); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
 func (q postgresSQLQueries) transformationCreate(name string, query string) string {
 	return fmt.Sprintf("CREATE TABLE  %s AS %s", sanitize(name), query)
 }
@@ -246,14 +338,12 @@ func (q postgresSQLQueries) transformationUpdate(db *sql.DB, tableName string, q
 func (q postgresSQLQueries) atomicUpdate(db *sql.DB, tableName string, tempName string, query string) error {
 	santizedName := sanitize(tableName)
 	oldName := sanitize(fmt.Sprintf("old_%s", tableName))
-	transaction := fmt.Sprintf("BEGIN;"+
-		"%s;"+
-		"ALTER TABLE %s RENAME TO %s;"+
-		"ALTER TABLE %s RENAME TO %s;"+
-		"DROP TABLE %s;"+
-		"COMMIT;", query, santizedName, oldName, tempName, santizedName, oldName)
-	_, err := db.Exec(transaction)
-	return err
+	return replaceRelation(db, q.dialect(), tableName,
+		query,
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", santizedName, oldName),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tempName, santizedName),
+		fmt.Sprintf("DROP TABLE %s", oldName),
+	)
 }
 
 func (q postgresSQLQueries) transformationExists() string {
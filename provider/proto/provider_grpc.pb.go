@@ -0,0 +1,1263 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// CustomProviderClient is the client API for CustomProvider service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type CustomProviderClient interface {
+	Capabilities(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ProviderCapabilities, error)
+	CreateOnlineTable(ctx context.Context, in *OnlineTableRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetOnlineTable(ctx context.Context, in *OnlineTableRequest, opts ...grpc.CallOption) (*Empty, error)
+	DeleteOnlineTable(ctx context.Context, in *OnlineTableRequest, opts ...grpc.CallOption) (*Empty, error)
+	SetOnlineValue(ctx context.Context, in *OnlineValueRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetOnlineValue(ctx context.Context, in *OnlineValueRequest, opts ...grpc.CallOption) (*Value, error)
+	RegisterResourceFromSourceTable(ctx context.Context, in *RegisterResourceRequest, opts ...grpc.CallOption) (*Empty, error)
+	RegisterPrimaryFromSourceTable(ctx context.Context, in *RegisterPrimaryRequest, opts ...grpc.CallOption) (*TableInfo, error)
+	CreateTransformation(ctx context.Context, in *TransformationRequest, opts ...grpc.CallOption) (*Empty, error)
+	UpdateTransformation(ctx context.Context, in *TransformationRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetTransformationTable(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*TableInfo, error)
+	CreatePrimaryTable(ctx context.Context, in *CreateTableRequest, opts ...grpc.CallOption) (*TableInfo, error)
+	GetPrimaryTable(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*TableInfo, error)
+	WritePrimaryRecord(ctx context.Context, in *PrimaryRecordRequest, opts ...grpc.CallOption) (*Empty, error)
+	IteratePrimaryTable(ctx context.Context, in *IterateTableRequest, opts ...grpc.CallOption) (CustomProvider_IteratePrimaryTableClient, error)
+	PrimaryTableNumRows(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*NumRows, error)
+	PrimaryTableStats(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*TableStats, error)
+	CreateResourceTable(ctx context.Context, in *CreateTableRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetResourceTable(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*Empty, error)
+	WriteResourceRecord(ctx context.Context, in *ResourceRecordRequest, opts ...grpc.CallOption) (*Empty, error)
+	CreateMaterialization(ctx context.Context, in *MaterializeRequest, opts ...grpc.CallOption) (*MaterializationID, error)
+	UpdateMaterialization(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*MaterializationID, error)
+	GetMaterialization(ctx context.Context, in *MaterializationID, opts ...grpc.CallOption) (*Empty, error)
+	DeleteMaterialization(ctx context.Context, in *MaterializationID, opts ...grpc.CallOption) (*Empty, error)
+	MaterializationNumRows(ctx context.Context, in *MaterializationID, opts ...grpc.CallOption) (*NumRows, error)
+	IterateMaterialization(ctx context.Context, in *IterateMaterializationRequest, opts ...grpc.CallOption) (CustomProvider_IterateMaterializationClient, error)
+	MaterializationStats(ctx context.Context, in *MaterializationID, opts ...grpc.CallOption) (*TableStats, error)
+	DeleteTable(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*Empty, error)
+	CreateTrainingSet(ctx context.Context, in *TrainingSetDef, opts ...grpc.CallOption) (*Empty, error)
+	UpdateTrainingSet(ctx context.Context, in *TrainingSetDef, opts ...grpc.CallOption) (*Empty, error)
+	GetTrainingSet(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (CustomProvider_GetTrainingSetClient, error)
+}
+
+type customProviderClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewCustomProviderClient(cc grpc.ClientConnInterface) CustomProviderClient {
+	return &customProviderClient{cc}
+}
+
+func (c *customProviderClient) Capabilities(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*ProviderCapabilities, error) {
+	out := new(ProviderCapabilities)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/Capabilities", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) CreateOnlineTable(ctx context.Context, in *OnlineTableRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/CreateOnlineTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) GetOnlineTable(ctx context.Context, in *OnlineTableRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/GetOnlineTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) DeleteOnlineTable(ctx context.Context, in *OnlineTableRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/DeleteOnlineTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) SetOnlineValue(ctx context.Context, in *OnlineValueRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/SetOnlineValue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) GetOnlineValue(ctx context.Context, in *OnlineValueRequest, opts ...grpc.CallOption) (*Value, error) {
+	out := new(Value)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/GetOnlineValue", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) RegisterResourceFromSourceTable(ctx context.Context, in *RegisterResourceRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/RegisterResourceFromSourceTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) RegisterPrimaryFromSourceTable(ctx context.Context, in *RegisterPrimaryRequest, opts ...grpc.CallOption) (*TableInfo, error) {
+	out := new(TableInfo)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/RegisterPrimaryFromSourceTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) CreateTransformation(ctx context.Context, in *TransformationRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/CreateTransformation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) UpdateTransformation(ctx context.Context, in *TransformationRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/UpdateTransformation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) GetTransformationTable(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*TableInfo, error) {
+	out := new(TableInfo)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/GetTransformationTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) CreatePrimaryTable(ctx context.Context, in *CreateTableRequest, opts ...grpc.CallOption) (*TableInfo, error) {
+	out := new(TableInfo)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/CreatePrimaryTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) GetPrimaryTable(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*TableInfo, error) {
+	out := new(TableInfo)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/GetPrimaryTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) WritePrimaryRecord(ctx context.Context, in *PrimaryRecordRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/WritePrimaryRecord", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) IteratePrimaryTable(ctx context.Context, in *IterateTableRequest, opts ...grpc.CallOption) (CustomProvider_IteratePrimaryTableClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CustomProvider_ServiceDesc.Streams[0], "/featureform.provider.proto.CustomProvider/IteratePrimaryTable", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &customProviderIteratePrimaryTableClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CustomProvider_IteratePrimaryTableClient interface {
+	Recv() (*PrimaryRow, error)
+	grpc.ClientStream
+}
+
+type customProviderIteratePrimaryTableClient struct {
+	grpc.ClientStream
+}
+
+func (x *customProviderIteratePrimaryTableClient) Recv() (*PrimaryRow, error) {
+	m := new(PrimaryRow)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *customProviderClient) PrimaryTableNumRows(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*NumRows, error) {
+	out := new(NumRows)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/PrimaryTableNumRows", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) PrimaryTableStats(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*TableStats, error) {
+	out := new(TableStats)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/PrimaryTableStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) CreateResourceTable(ctx context.Context, in *CreateTableRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/CreateResourceTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) GetResourceTable(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/GetResourceTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) WriteResourceRecord(ctx context.Context, in *ResourceRecordRequest, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/WriteResourceRecord", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) CreateMaterialization(ctx context.Context, in *MaterializeRequest, opts ...grpc.CallOption) (*MaterializationID, error) {
+	out := new(MaterializationID)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/CreateMaterialization", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) UpdateMaterialization(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*MaterializationID, error) {
+	out := new(MaterializationID)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/UpdateMaterialization", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) GetMaterialization(ctx context.Context, in *MaterializationID, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/GetMaterialization", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) DeleteMaterialization(ctx context.Context, in *MaterializationID, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/DeleteMaterialization", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) MaterializationNumRows(ctx context.Context, in *MaterializationID, opts ...grpc.CallOption) (*NumRows, error) {
+	out := new(NumRows)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/MaterializationNumRows", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) IterateMaterialization(ctx context.Context, in *IterateMaterializationRequest, opts ...grpc.CallOption) (CustomProvider_IterateMaterializationClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CustomProvider_ServiceDesc.Streams[1], "/featureform.provider.proto.CustomProvider/IterateMaterialization", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &customProviderIterateMaterializationClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CustomProvider_IterateMaterializationClient interface {
+	Recv() (*ResourceRecord, error)
+	grpc.ClientStream
+}
+
+type customProviderIterateMaterializationClient struct {
+	grpc.ClientStream
+}
+
+func (x *customProviderIterateMaterializationClient) Recv() (*ResourceRecord, error) {
+	m := new(ResourceRecord)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *customProviderClient) MaterializationStats(ctx context.Context, in *MaterializationID, opts ...grpc.CallOption) (*TableStats, error) {
+	out := new(TableStats)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/MaterializationStats", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) DeleteTable(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/DeleteTable", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) CreateTrainingSet(ctx context.Context, in *TrainingSetDef, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/CreateTrainingSet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) UpdateTrainingSet(ctx context.Context, in *TrainingSetDef, opts ...grpc.CallOption) (*Empty, error) {
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, "/featureform.provider.proto.CustomProvider/UpdateTrainingSet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *customProviderClient) GetTrainingSet(ctx context.Context, in *ResourceID, opts ...grpc.CallOption) (CustomProvider_GetTrainingSetClient, error) {
+	stream, err := c.cc.NewStream(ctx, &CustomProvider_ServiceDesc.Streams[2], "/featureform.provider.proto.CustomProvider/GetTrainingSet", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &customProviderGetTrainingSetClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type CustomProvider_GetTrainingSetClient interface {
+	Recv() (*TrainingSetRow, error)
+	grpc.ClientStream
+}
+
+type customProviderGetTrainingSetClient struct {
+	grpc.ClientStream
+}
+
+func (x *customProviderGetTrainingSetClient) Recv() (*TrainingSetRow, error) {
+	m := new(TrainingSetRow)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CustomProviderServer is the server API for CustomProvider service.
+// All implementations must embed UnimplementedCustomProviderServer
+// for forward compatibility
+type CustomProviderServer interface {
+	Capabilities(context.Context, *Empty) (*ProviderCapabilities, error)
+	CreateOnlineTable(context.Context, *OnlineTableRequest) (*Empty, error)
+	GetOnlineTable(context.Context, *OnlineTableRequest) (*Empty, error)
+	DeleteOnlineTable(context.Context, *OnlineTableRequest) (*Empty, error)
+	SetOnlineValue(context.Context, *OnlineValueRequest) (*Empty, error)
+	GetOnlineValue(context.Context, *OnlineValueRequest) (*Value, error)
+	RegisterResourceFromSourceTable(context.Context, *RegisterResourceRequest) (*Empty, error)
+	RegisterPrimaryFromSourceTable(context.Context, *RegisterPrimaryRequest) (*TableInfo, error)
+	CreateTransformation(context.Context, *TransformationRequest) (*Empty, error)
+	UpdateTransformation(context.Context, *TransformationRequest) (*Empty, error)
+	GetTransformationTable(context.Context, *ResourceID) (*TableInfo, error)
+	CreatePrimaryTable(context.Context, *CreateTableRequest) (*TableInfo, error)
+	GetPrimaryTable(context.Context, *ResourceID) (*TableInfo, error)
+	WritePrimaryRecord(context.Context, *PrimaryRecordRequest) (*Empty, error)
+	IteratePrimaryTable(*IterateTableRequest, CustomProvider_IteratePrimaryTableServer) error
+	PrimaryTableNumRows(context.Context, *ResourceID) (*NumRows, error)
+	PrimaryTableStats(context.Context, *ResourceID) (*TableStats, error)
+	CreateResourceTable(context.Context, *CreateTableRequest) (*Empty, error)
+	GetResourceTable(context.Context, *ResourceID) (*Empty, error)
+	WriteResourceRecord(context.Context, *ResourceRecordRequest) (*Empty, error)
+	CreateMaterialization(context.Context, *MaterializeRequest) (*MaterializationID, error)
+	UpdateMaterialization(context.Context, *ResourceID) (*MaterializationID, error)
+	GetMaterialization(context.Context, *MaterializationID) (*Empty, error)
+	DeleteMaterialization(context.Context, *MaterializationID) (*Empty, error)
+	MaterializationNumRows(context.Context, *MaterializationID) (*NumRows, error)
+	IterateMaterialization(*IterateMaterializationRequest, CustomProvider_IterateMaterializationServer) error
+	MaterializationStats(context.Context, *MaterializationID) (*TableStats, error)
+	DeleteTable(context.Context, *ResourceID) (*Empty, error)
+	CreateTrainingSet(context.Context, *TrainingSetDef) (*Empty, error)
+	UpdateTrainingSet(context.Context, *TrainingSetDef) (*Empty, error)
+	GetTrainingSet(*ResourceID, CustomProvider_GetTrainingSetServer) error
+	mustEmbedUnimplementedCustomProviderServer()
+}
+
+// UnimplementedCustomProviderServer must be embedded to have forward compatible implementations.
+type UnimplementedCustomProviderServer struct {
+}
+
+func (UnimplementedCustomProviderServer) Capabilities(context.Context, *Empty) (*ProviderCapabilities, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Capabilities not implemented")
+}
+func (UnimplementedCustomProviderServer) CreateOnlineTable(context.Context, *OnlineTableRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOnlineTable not implemented")
+}
+func (UnimplementedCustomProviderServer) GetOnlineTable(context.Context, *OnlineTableRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOnlineTable not implemented")
+}
+func (UnimplementedCustomProviderServer) DeleteOnlineTable(context.Context, *OnlineTableRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteOnlineTable not implemented")
+}
+func (UnimplementedCustomProviderServer) SetOnlineValue(context.Context, *OnlineValueRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetOnlineValue not implemented")
+}
+func (UnimplementedCustomProviderServer) GetOnlineValue(context.Context, *OnlineValueRequest) (*Value, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOnlineValue not implemented")
+}
+func (UnimplementedCustomProviderServer) RegisterResourceFromSourceTable(context.Context, *RegisterResourceRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterResourceFromSourceTable not implemented")
+}
+func (UnimplementedCustomProviderServer) RegisterPrimaryFromSourceTable(context.Context, *RegisterPrimaryRequest) (*TableInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterPrimaryFromSourceTable not implemented")
+}
+func (UnimplementedCustomProviderServer) CreateTransformation(context.Context, *TransformationRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTransformation not implemented")
+}
+func (UnimplementedCustomProviderServer) UpdateTransformation(context.Context, *TransformationRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTransformation not implemented")
+}
+func (UnimplementedCustomProviderServer) GetTransformationTable(context.Context, *ResourceID) (*TableInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTransformationTable not implemented")
+}
+func (UnimplementedCustomProviderServer) CreatePrimaryTable(context.Context, *CreateTableRequest) (*TableInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreatePrimaryTable not implemented")
+}
+func (UnimplementedCustomProviderServer) GetPrimaryTable(context.Context, *ResourceID) (*TableInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPrimaryTable not implemented")
+}
+func (UnimplementedCustomProviderServer) WritePrimaryRecord(context.Context, *PrimaryRecordRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WritePrimaryRecord not implemented")
+}
+func (UnimplementedCustomProviderServer) IteratePrimaryTable(*IterateTableRequest, CustomProvider_IteratePrimaryTableServer) error {
+	return status.Errorf(codes.Unimplemented, "method IteratePrimaryTable not implemented")
+}
+func (UnimplementedCustomProviderServer) PrimaryTableNumRows(context.Context, *ResourceID) (*NumRows, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PrimaryTableNumRows not implemented")
+}
+func (UnimplementedCustomProviderServer) PrimaryTableStats(context.Context, *ResourceID) (*TableStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PrimaryTableStats not implemented")
+}
+func (UnimplementedCustomProviderServer) CreateResourceTable(context.Context, *CreateTableRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateResourceTable not implemented")
+}
+func (UnimplementedCustomProviderServer) GetResourceTable(context.Context, *ResourceID) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetResourceTable not implemented")
+}
+func (UnimplementedCustomProviderServer) WriteResourceRecord(context.Context, *ResourceRecordRequest) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WriteResourceRecord not implemented")
+}
+func (UnimplementedCustomProviderServer) CreateMaterialization(context.Context, *MaterializeRequest) (*MaterializationID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateMaterialization not implemented")
+}
+func (UnimplementedCustomProviderServer) UpdateMaterialization(context.Context, *ResourceID) (*MaterializationID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateMaterialization not implemented")
+}
+func (UnimplementedCustomProviderServer) GetMaterialization(context.Context, *MaterializationID) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMaterialization not implemented")
+}
+func (UnimplementedCustomProviderServer) DeleteMaterialization(context.Context, *MaterializationID) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteMaterialization not implemented")
+}
+func (UnimplementedCustomProviderServer) MaterializationNumRows(context.Context, *MaterializationID) (*NumRows, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MaterializationNumRows not implemented")
+}
+func (UnimplementedCustomProviderServer) IterateMaterialization(*IterateMaterializationRequest, CustomProvider_IterateMaterializationServer) error {
+	return status.Errorf(codes.Unimplemented, "method IterateMaterialization not implemented")
+}
+func (UnimplementedCustomProviderServer) MaterializationStats(context.Context, *MaterializationID) (*TableStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MaterializationStats not implemented")
+}
+func (UnimplementedCustomProviderServer) DeleteTable(context.Context, *ResourceID) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTable not implemented")
+}
+func (UnimplementedCustomProviderServer) CreateTrainingSet(context.Context, *TrainingSetDef) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTrainingSet not implemented")
+}
+func (UnimplementedCustomProviderServer) UpdateTrainingSet(context.Context, *TrainingSetDef) (*Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTrainingSet not implemented")
+}
+func (UnimplementedCustomProviderServer) GetTrainingSet(*ResourceID, CustomProvider_GetTrainingSetServer) error {
+	return status.Errorf(codes.Unimplemented, "method GetTrainingSet not implemented")
+}
+func (UnimplementedCustomProviderServer) mustEmbedUnimplementedCustomProviderServer() {}
+
+// UnsafeCustomProviderServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to CustomProviderServer will
+// result in compilation errors.
+type UnsafeCustomProviderServer interface {
+	mustEmbedUnimplementedCustomProviderServer()
+}
+
+func RegisterCustomProviderServer(s grpc.ServiceRegistrar, srv CustomProviderServer) {
+	s.RegisterService(&CustomProvider_ServiceDesc, srv)
+}
+
+func _CustomProvider_Capabilities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).Capabilities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/Capabilities",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).Capabilities(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_CreateOnlineTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnlineTableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).CreateOnlineTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/CreateOnlineTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).CreateOnlineTable(ctx, req.(*OnlineTableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_GetOnlineTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnlineTableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).GetOnlineTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/GetOnlineTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).GetOnlineTable(ctx, req.(*OnlineTableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_DeleteOnlineTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnlineTableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).DeleteOnlineTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/DeleteOnlineTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).DeleteOnlineTable(ctx, req.(*OnlineTableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_SetOnlineValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnlineValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).SetOnlineValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/SetOnlineValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).SetOnlineValue(ctx, req.(*OnlineValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_GetOnlineValue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(OnlineValueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).GetOnlineValue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/GetOnlineValue",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).GetOnlineValue(ctx, req.(*OnlineValueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_RegisterResourceFromSourceTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterResourceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).RegisterResourceFromSourceTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/RegisterResourceFromSourceTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).RegisterResourceFromSourceTable(ctx, req.(*RegisterResourceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_RegisterPrimaryFromSourceTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterPrimaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).RegisterPrimaryFromSourceTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/RegisterPrimaryFromSourceTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).RegisterPrimaryFromSourceTable(ctx, req.(*RegisterPrimaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_CreateTransformation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransformationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).CreateTransformation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/CreateTransformation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).CreateTransformation(ctx, req.(*TransformationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_UpdateTransformation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransformationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).UpdateTransformation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/UpdateTransformation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).UpdateTransformation(ctx, req.(*TransformationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_GetTransformationTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).GetTransformationTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/GetTransformationTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).GetTransformationTable(ctx, req.(*ResourceID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_CreatePrimaryTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).CreatePrimaryTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/CreatePrimaryTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).CreatePrimaryTable(ctx, req.(*CreateTableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_GetPrimaryTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).GetPrimaryTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/GetPrimaryTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).GetPrimaryTable(ctx, req.(*ResourceID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_WritePrimaryRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PrimaryRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).WritePrimaryRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/WritePrimaryRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).WritePrimaryRecord(ctx, req.(*PrimaryRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_IteratePrimaryTable_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(IterateTableRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CustomProviderServer).IteratePrimaryTable(m, &customProviderIteratePrimaryTableServer{stream})
+}
+
+type CustomProvider_IteratePrimaryTableServer interface {
+	Send(*PrimaryRow) error
+	grpc.ServerStream
+}
+
+type customProviderIteratePrimaryTableServer struct {
+	grpc.ServerStream
+}
+
+func (x *customProviderIteratePrimaryTableServer) Send(m *PrimaryRow) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CustomProvider_PrimaryTableNumRows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).PrimaryTableNumRows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/PrimaryTableNumRows",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).PrimaryTableNumRows(ctx, req.(*ResourceID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_PrimaryTableStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).PrimaryTableStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/PrimaryTableStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).PrimaryTableStats(ctx, req.(*ResourceID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_CreateResourceTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTableRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).CreateResourceTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/CreateResourceTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).CreateResourceTable(ctx, req.(*CreateTableRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_GetResourceTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).GetResourceTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/GetResourceTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).GetResourceTable(ctx, req.(*ResourceID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_WriteResourceRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).WriteResourceRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/WriteResourceRecord",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).WriteResourceRecord(ctx, req.(*ResourceRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_CreateMaterialization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaterializeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).CreateMaterialization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/CreateMaterialization",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).CreateMaterialization(ctx, req.(*MaterializeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_UpdateMaterialization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).UpdateMaterialization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/UpdateMaterialization",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).UpdateMaterialization(ctx, req.(*ResourceID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_GetMaterialization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaterializationID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).GetMaterialization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/GetMaterialization",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).GetMaterialization(ctx, req.(*MaterializationID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_DeleteMaterialization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaterializationID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).DeleteMaterialization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/DeleteMaterialization",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).DeleteMaterialization(ctx, req.(*MaterializationID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_MaterializationNumRows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaterializationID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).MaterializationNumRows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/MaterializationNumRows",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).MaterializationNumRows(ctx, req.(*MaterializationID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_IterateMaterialization_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(IterateMaterializationRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CustomProviderServer).IterateMaterialization(m, &customProviderIterateMaterializationServer{stream})
+}
+
+type CustomProvider_IterateMaterializationServer interface {
+	Send(*ResourceRecord) error
+	grpc.ServerStream
+}
+
+type customProviderIterateMaterializationServer struct {
+	grpc.ServerStream
+}
+
+func (x *customProviderIterateMaterializationServer) Send(m *ResourceRecord) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _CustomProvider_MaterializationStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MaterializationID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).MaterializationStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/MaterializationStats",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).MaterializationStats(ctx, req.(*MaterializationID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_DeleteTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResourceID)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).DeleteTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/DeleteTable",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).DeleteTable(ctx, req.(*ResourceID))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_CreateTrainingSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrainingSetDef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).CreateTrainingSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/CreateTrainingSet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).CreateTrainingSet(ctx, req.(*TrainingSetDef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_UpdateTrainingSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrainingSetDef)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CustomProviderServer).UpdateTrainingSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/featureform.provider.proto.CustomProvider/UpdateTrainingSet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CustomProviderServer).UpdateTrainingSet(ctx, req.(*TrainingSetDef))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CustomProvider_GetTrainingSet_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ResourceID)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CustomProviderServer).GetTrainingSet(m, &customProviderGetTrainingSetServer{stream})
+}
+
+type CustomProvider_GetTrainingSetServer interface {
+	Send(*TrainingSetRow) error
+	grpc.ServerStream
+}
+
+type customProviderGetTrainingSetServer struct {
+	grpc.ServerStream
+}
+
+func (x *customProviderGetTrainingSetServer) Send(m *TrainingSetRow) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// CustomProvider_ServiceDesc is the grpc.ServiceDesc for CustomProvider service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var CustomProvider_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "featureform.provider.proto.CustomProvider",
+	HandlerType: (*CustomProviderServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Capabilities",
+			Handler:    _CustomProvider_Capabilities_Handler,
+		},
+		{
+			MethodName: "CreateOnlineTable",
+			Handler:    _CustomProvider_CreateOnlineTable_Handler,
+		},
+		{
+			MethodName: "GetOnlineTable",
+			Handler:    _CustomProvider_GetOnlineTable_Handler,
+		},
+		{
+			MethodName: "DeleteOnlineTable",
+			Handler:    _CustomProvider_DeleteOnlineTable_Handler,
+		},
+		{
+			MethodName: "SetOnlineValue",
+			Handler:    _CustomProvider_SetOnlineValue_Handler,
+		},
+		{
+			MethodName: "GetOnlineValue",
+			Handler:    _CustomProvider_GetOnlineValue_Handler,
+		},
+		{
+			MethodName: "RegisterResourceFromSourceTable",
+			Handler:    _CustomProvider_RegisterResourceFromSourceTable_Handler,
+		},
+		{
+			MethodName: "RegisterPrimaryFromSourceTable",
+			Handler:    _CustomProvider_RegisterPrimaryFromSourceTable_Handler,
+		},
+		{
+			MethodName: "CreateTransformation",
+			Handler:    _CustomProvider_CreateTransformation_Handler,
+		},
+		{
+			MethodName: "UpdateTransformation",
+			Handler:    _CustomProvider_UpdateTransformation_Handler,
+		},
+		{
+			MethodName: "GetTransformationTable",
+			Handler:    _CustomProvider_GetTransformationTable_Handler,
+		},
+		{
+			MethodName: "CreatePrimaryTable",
+			Handler:    _CustomProvider_CreatePrimaryTable_Handler,
+		},
+		{
+			MethodName: "GetPrimaryTable",
+			Handler:    _CustomProvider_GetPrimaryTable_Handler,
+		},
+		{
+			MethodName: "WritePrimaryRecord",
+			Handler:    _CustomProvider_WritePrimaryRecord_Handler,
+		},
+		{
+			MethodName: "PrimaryTableNumRows",
+			Handler:    _CustomProvider_PrimaryTableNumRows_Handler,
+		},
+		{
+			MethodName: "PrimaryTableStats",
+			Handler:    _CustomProvider_PrimaryTableStats_Handler,
+		},
+		{
+			MethodName: "CreateResourceTable",
+			Handler:    _CustomProvider_CreateResourceTable_Handler,
+		},
+		{
+			MethodName: "GetResourceTable",
+			Handler:    _CustomProvider_GetResourceTable_Handler,
+		},
+		{
+			MethodName: "WriteResourceRecord",
+			Handler:    _CustomProvider_WriteResourceRecord_Handler,
+		},
+		{
+			MethodName: "CreateMaterialization",
+			Handler:    _CustomProvider_CreateMaterialization_Handler,
+		},
+		{
+			MethodName: "UpdateMaterialization",
+			Handler:    _CustomProvider_UpdateMaterialization_Handler,
+		},
+		{
+			MethodName: "GetMaterialization",
+			Handler:    _CustomProvider_GetMaterialization_Handler,
+		},
+		{
+			MethodName: "DeleteMaterialization",
+			Handler:    _CustomProvider_DeleteMaterialization_Handler,
+		},
+		{
+			MethodName: "MaterializationNumRows",
+			Handler:    _CustomProvider_MaterializationNumRows_Handler,
+		},
+		{
+			MethodName: "MaterializationStats",
+			Handler:    _CustomProvider_MaterializationStats_Handler,
+		},
+		{
+			MethodName: "DeleteTable",
+			Handler:    _CustomProvider_DeleteTable_Handler,
+		},
+		{
+			MethodName: "CreateTrainingSet",
+			Handler:    _CustomProvider_CreateTrainingSet_Handler,
+		},
+		{
+			MethodName: "UpdateTrainingSet",
+			Handler:    _CustomProvider_UpdateTrainingSet_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "IteratePrimaryTable",
+			Handler:       _CustomProvider_IteratePrimaryTable_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "IterateMaterialization",
+			Handler:       _CustomProvider_IterateMaterialization_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "GetTrainingSet",
+			Handler:       _CustomProvider_GetTrainingSet_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "provider/proto/provider.proto",
+}
@@ -5,6 +5,7 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -20,6 +21,7 @@ func init() {
 		PostgresOffline:  postgresOfflineStoreFactory,
 		SnowflakeOffline: snowflakeOfflineStoreFactory,
 		RedshiftOffline:  redshiftOfflineStoreFactory,
+		KafkaStream:      kafkaStreamingSourceFactory,
 	}
 	for name, factory := range unregisteredFactories {
 		if err := RegisterFactory(name, factory); err != nil {
@@ -37,6 +39,12 @@ type RedisConfig struct {
 	Addr     string
 	Password string
 	DB       int
+	TLSConfig
+	IAMConfig
+	// Username and CacheName identify the ElastiCache user and replication
+	// group when using AWS IAM auth.
+	Username  string
+	CacheName string
 }
 
 func (r RedisConfig) Serialized() SerializedConfig {
@@ -60,6 +68,7 @@ type CassandraConfig struct {
 	Addr        string
 	session     *gocql.Session
 	Consistency gocql.Consistency
+	TLSConfig
 }
 
 func (r CassandraConfig) Serialized() SerializedConfig {
@@ -83,6 +92,9 @@ type Provider interface {
 	AsOfflineStore() (OfflineStore, error)
 	Type() Type
 	Config() SerializedConfig
+	// CheckHealth makes a cheap round trip to the backing store to check
+	// that it is reachable and that its credentials are still valid.
+	CheckHealth(ctx context.Context) error
 }
 
 type BaseProvider struct {
@@ -106,6 +118,19 @@ func (provider BaseProvider) Config() SerializedConfig {
 	return provider.ProviderConfig
 }
 
+// CheckHealth reports providers that have no connection to check as healthy.
+func (provider BaseProvider) CheckHealth(ctx context.Context) error {
+	return nil
+}
+
+func (provider *BaseProvider) setConfig(config SerializedConfig) {
+	provider.ProviderConfig = config
+}
+
+type configSetter interface {
+	setConfig(config SerializedConfig)
+}
+
 type Factory func(SerializedConfig) (Provider, error)
 
 type Type string
@@ -125,5 +150,18 @@ func Get(t Type, config SerializedConfig) (Provider, error) {
 	if !has {
 		return nil, fmt.Errorf("no provider of type: %s", t)
 	}
-	return f(config)
+	resolved, err := ResolveSecrets(config)
+	if err != nil {
+		return nil, fmt.Errorf("could not resolve secrets for %s provider: %w", t, err)
+	}
+	p, err := f(resolved)
+	if err != nil {
+		return nil, err
+	}
+	// Keep the secret URIs in the provider's config so that it can be passed
+	// on to workers without exposing the secrets themselves.
+	if setter, ok := p.(configSetter); ok {
+		setter.setConfig(config)
+	}
+	return p, nil
 }
@@ -5,9 +5,30 @@
 package provider
 
 import (
+	"bytes"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/featureform/provider/tfexample"
+	"github.com/segmentio/kafka-go"
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/reader"
+	"google.golang.org/protobuf/proto"
 )
 
 var mockConfig SerializedConfig = SerializedConfig("abc")
@@ -70,3 +91,1042 @@ func TestBaseProvider(t *testing.T) {
 		t.Fatalf("Config not passed down to provider")
 	}
 }
+
+func TestTLSConfigSerialization(t *testing.T) {
+	config := PostgresConfig{
+		Host: "localhost",
+		TLSConfig: TLSConfig{
+			SSLMode:    SSLVerifyFull,
+			CACert:     "/certs/ca.pem",
+			ClientCert: "/certs/client.pem",
+			ClientKey:  "/certs/client.key",
+		},
+	}
+	parsed := PostgresConfig{}
+	if err := parsed.Deserialize(config.Serialize()); err != nil {
+		t.Fatalf("Failed to deserialize config: %s", err)
+	}
+	if !reflect.DeepEqual(config, parsed) {
+		t.Fatalf("TLS options not preserved: %v != %v", config, parsed)
+	}
+	expected := "sslcert=%2Fcerts%2Fclient.pem&sslkey=%2Fcerts%2Fclient.key&sslmode=verify-full&sslrootcert=%2Fcerts%2Fca.pem"
+	if params := parsed.connectionParams(SSLDisable).Encode(); params != expected {
+		t.Fatalf("Unexpected connection params: %s", params)
+	}
+}
+
+func TestTLSConfigDefaults(t *testing.T) {
+	config := RedisConfig{Addr: "localhost:6379"}
+	tlsConfig, err := config.tlsConfig("localhost")
+	if err != nil {
+		t.Fatalf("Failed to build tls config: %s", err)
+	}
+	if tlsConfig != nil {
+		t.Fatalf("TLS enabled without an ssl mode")
+	}
+	if params := config.dsnParams(SSLRequire); params != "sslmode=require" {
+		t.Fatalf("Unexpected default dsn params: %s", params)
+	}
+}
+
+func TestTLSConfigDSNQuoting(t *testing.T) {
+	config := TLSConfig{SSLMode: SSLVerifyCA, CACert: `/my certs/o'brien\ca.pem`}
+	expected := `sslmode=verify-ca sslrootcert='/my certs/o\'brien\\ca.pem'`
+	if params := config.dsnParams(SSLRequire); params != expected {
+		t.Fatalf("Expected dsn params %s, got %s", expected, params)
+	}
+	if value := dsnValue(""); value != "''" {
+		t.Fatalf("Empty dsn value not quoted: %s", value)
+	}
+}
+
+func TestSnowflakeTLSConnector(t *testing.T) {
+	dsn := "user:password@org-account/db/PUBLIC"
+	disabled := SnowflakeConfig{TLSConfig: TLSConfig{SSLMode: SSLDisable}}
+	if _, err := disabled.tlsConnector(dsn); err == nil {
+		t.Fatalf("Succeeded with tls disabled")
+	}
+	config := SnowflakeConfig{TLSConfig: TLSConfig{SSLMode: SSLRequire}}
+	if _, err := config.tlsConnector(dsn); err != nil {
+		t.Fatalf("Failed to create tls connector: %s", err)
+	}
+}
+
+func TestTLSConfigInvalid(t *testing.T) {
+	invalid := []TLSConfig{
+		{SSLMode: "sometimes"},
+		{SSLMode: SSLRequire, ClientCert: "/certs/client.pem"},
+	}
+	for _, config := range invalid {
+		if _, err := config.tlsConfig("localhost"); err == nil {
+			t.Fatalf("Succeeded with invalid tls config: %v", config)
+		}
+	}
+}
+
+func TestProviderCache(t *testing.T) {
+	mockType := Type("cache mock")
+	created := 0
+	factory := func(c SerializedConfig) (Provider, error) {
+		created++
+		return &BaseProvider{ProviderType: mockType, ProviderConfig: c}, nil
+	}
+	if err := RegisterFactory(mockType, factory); err != nil {
+		t.Fatalf("Failed to register factory: %s", err)
+	}
+	cache := NewCache()
+	first, err := cache.Get("mock", mockType, SerializedConfig("old"))
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	if second, err := cache.Get("mock", mockType, SerializedConfig("old")); err != nil || second != first {
+		t.Fatalf("Provider not reused for same config: %v %s", second, err)
+	}
+	rotated, err := cache.Get("mock", mockType, SerializedConfig("new"))
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	if rotated == first || !reflect.DeepEqual(rotated.Config(), SerializedConfig("new")) {
+		t.Fatalf("Provider not recreated after config change")
+	}
+	if created != 2 {
+		t.Fatalf("Expected 2 providers to be created, got %d", created)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	if err := Validate(LocalOnline, []byte{}); err != nil {
+		t.Fatalf("Failed to validate local provider: %s", err)
+	}
+	if err := Validate(Type("Doesnt exist"), mockConfig); err == nil {
+		t.Fatalf("Succeeded in validating unregistered provider")
+	}
+	config := RedisConfig{Addr: "localhost:1"}
+	if err := Validate(RedisOnline, config.Serialized()); err == nil {
+		t.Fatalf("Succeeded in validating unreachable redis")
+	}
+}
+
+func TestRDSAuthToken(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	config := IAMConfig{AuthMethod: AWSIAMAuth, Region: "us-east-1"}
+	token, err := config.rdsToken("db.example.com:5432", "featureform")(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to generate token: %s", err)
+	}
+	if !strings.HasPrefix(token, "db.example.com:5432/?") {
+		t.Fatalf("Token not for endpoint: %s", token)
+	}
+	for _, param := range []string{"Action=connect", "DBUser=featureform", "X-Amz-Signature=", "X-Amz-Expires=900", "us-east-1%2Frds-db"} {
+		if !strings.Contains(token, param) {
+			t.Fatalf("Token missing %s: %s", param, token)
+		}
+	}
+}
+
+func TestGCPAccessToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"access_token": "gcp-token", "expires_in": 3599}`)
+	}))
+	defer server.Close()
+	endpoint := gcpMetadataEndpoint
+	gcpMetadataEndpoint = server.URL
+	defer func() { gcpMetadataEndpoint = endpoint }()
+	config := IAMConfig{AuthMethod: GCPIAMAuth}
+	token, err := config.rdsToken("db:5432", "featureform")(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to get token: %s", err)
+	}
+	if token != "gcp-token" {
+		t.Fatalf("Unexpected token: %s", token)
+	}
+}
+
+func TestIAMConfigInvalid(t *testing.T) {
+	invalid := []IAMConfig{
+		{AuthMethod: "kerberos"},
+		{AuthMethod: AWSIAMAuth},
+	}
+	for _, config := range invalid {
+		if err := config.validateIAM(); err == nil {
+			t.Fatalf("Succeeded with invalid iam config: %v", config)
+		}
+	}
+	if _, err := NewRedisOnlineStore(&RedisConfig{Addr: "localhost:6379", IAMConfig: IAMConfig{AuthMethod: AWSIAMAuth, Region: "us-east-1"}}); err == nil {
+		t.Fatalf("Succeeded in creating elasticache store without a user")
+	}
+}
+
+func TestResolveVaultSecrets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vault-token" || r.URL.Path != "/v1/secret/data/postgres" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"data": {"data": {"username": "featureform", "password": "hunter2"}}}`)
+	}))
+	defer server.Close()
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "vault-token")
+	config := SerializedConfig(`{"Host": "localhost", "Username": "vault://secret/data/postgres#username", "Password": "vault://secret/data/postgres#password", "Port": 5432}`)
+	resolved, err := ResolveSecrets(config)
+	if err != nil {
+		t.Fatalf("Failed to resolve secrets: %s", err)
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(resolved, &fields); err != nil {
+		t.Fatalf("Resolved config is not JSON: %s", err)
+	}
+	if fields["Username"] != "featureform" || fields["Password"] != "hunter2" || fields["Host"] != "localhost" {
+		t.Fatalf("Secrets not resolved: %v", fields)
+	}
+	missing := SerializedConfig(`{"Password": "vault://secret/data/postgres#token"}`)
+	if _, err := ResolveSecrets(missing); err == nil {
+		t.Fatalf("Succeeded in resolving missing secret key")
+	}
+}
+
+func TestResolveSecretsPassThrough(t *testing.T) {
+	configs := []SerializedConfig{
+		SerializedConfig("abc"),
+		SerializedConfig(`{"Addr": "localhost:6379"}`),
+		SerializedConfig(`{"Url": "https://example.com"}`),
+	}
+	for _, config := range configs {
+		resolved, err := ResolveSecrets(config)
+		if err != nil {
+			t.Fatalf("Failed to resolve %s: %s", config, err)
+		}
+		if !reflect.DeepEqual(resolved, config) {
+			t.Fatalf("Config changed without secrets: %s != %s", resolved, config)
+		}
+	}
+}
+
+func TestGetKeepsSecretURIs(t *testing.T) {
+	resolver := SecretResolverFunc(func(ctx context.Context, uri *url.URL) (string, error) {
+		return "resolved-" + uri.Host, nil
+	})
+	if err := RegisterSecretResolver("mocksecret", resolver); err != nil {
+		t.Fatalf("Failed to register resolver: %s", err)
+	}
+	if err := RegisterSecretResolver("mocksecret", resolver); err == nil {
+		t.Fatalf("Succeeded in registering resolver twice")
+	}
+	var factoryConfig SerializedConfig
+	mockType := Type("secretMock")
+	factory := func(c SerializedConfig) (Provider, error) {
+		factoryConfig = c
+		return &localOnlineStore{BaseProvider: BaseProvider{ProviderType: mockType, ProviderConfig: c}}, nil
+	}
+	if err := RegisterFactory(mockType, factory); err != nil {
+		t.Fatalf("Failed to register factory: %s", err)
+	}
+	config := SerializedConfig(`{"Password":"mocksecret://password"}`)
+	p, err := Get(mockType, config)
+	if err != nil {
+		t.Fatalf("Failed to get provider: %s", err)
+	}
+	if string(factoryConfig) != `{"Password":"resolved-password"}` {
+		t.Fatalf("Factory did not get resolved config: %s", factoryConfig)
+	}
+	if !reflect.DeepEqual(p.Config(), config) {
+		t.Fatalf("Provider config exposes secrets: %s", p.Config())
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	columns := []ParquetColumn{
+		{Name: "entity", Type: String},
+		{Name: "value", Type: Int},
+		{Name: "score", Type: Float32},
+		{Name: "flag", Type: Bool},
+		{Name: "ts", Type: Timestamp},
+	}
+	rows := [][]interface{}{
+		{"a", 1, float32(0.5), true, time.UnixMilli(0)},
+		{"b", nil, 1.5, false, time.UnixMilli(1)},
+		{"c", int64(3), nil, nil, nil},
+	}
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, columns, rows); err != nil {
+		t.Fatalf("Failed to write parquet: %s", err)
+	}
+	// The reader names columns as Go fields, so their first letters are
+	// upper case.
+	expected := `[{"Entity":"a","Value":1,"Score":0.5,"Flag":true,"Ts":0},` +
+		`{"Entity":"b","Value":null,"Score":1.5,"Flag":false,"Ts":1000},` +
+		`{"Entity":"c","Value":3,"Score":null,"Flag":null,"Ts":null}]`
+	if got := readParquet(t, buf.Bytes()); got != expected {
+		t.Fatalf("Expected to read back %s, got %s", expected, got)
+	}
+	if err := WriteParquet(&buf, columns, [][]interface{}{{"a", "not an int"}}); err == nil {
+		t.Fatalf("Wrote a string to an int column")
+	}
+}
+
+// readParquet reads the rows of a Parquet file back as JSON.
+func readParquet(t *testing.T, data []byte) string {
+	pr, err := reader.NewParquetReader(buffer.NewBufferFileFromBytes(data), nil, 1)
+	if err != nil {
+		t.Fatalf("Failed to open parquet file: %s", err)
+	}
+	defer pr.ReadStop()
+	rows, err := pr.ReadByNumber(int(pr.GetNumRows()))
+	if err != nil {
+		t.Fatalf("Failed to read parquet rows: %s", err)
+	}
+	serialized, err := json.Marshal(rows)
+	if err != nil {
+		t.Fatalf("Failed to serialize parquet rows: %s", err)
+	}
+	return string(serialized)
+}
+
+type sliceTrainingSetIterator struct {
+	rows [][]interface{}
+	i    int
+}
+
+func (it *sliceTrainingSetIterator) Next() bool {
+	it.i++
+	return it.i <= len(it.rows)
+}
+
+func (it *sliceTrainingSetIterator) Features() []interface{} {
+	row := it.rows[it.i-1]
+	return row[:len(row)-1]
+}
+
+func (it *sliceTrainingSetIterator) Label() interface{} {
+	row := it.rows[it.i-1]
+	return row[len(row)-1]
+}
+
+func (it *sliceTrainingSetIterator) Err() error {
+	return nil
+}
+
+type recordingArchiver struct {
+	objects map[string][]byte
+}
+
+func (a *recordingArchiver) Archive(ctx context.Context, key string, data []byte) error {
+	a.objects[key] = data
+	return nil
+}
+
+func TestExportTrainingSet(t *testing.T) {
+	def := TrainingSetDef{
+		ID:       ResourceID{Name: "fraud", Variant: "v1", Type: TrainingSet},
+		Label:    ResourceID{Name: "is_fraud", Variant: "v1", Type: Label},
+		Features: []ResourceID{{Name: "amount", Variant: "v1", Type: Feature}, {Name: "country", Variant: "v1", Type: Feature}},
+	}
+	rows := [][]interface{}{
+		{12.5, "US", true},
+		{nil, "CA", false},
+		{3.0, "US", false},
+	}
+	archiver := &recordingArchiver{objects: make(map[string][]byte)}
+	export := TrainingSetExport{URI: "file:///exports", Format: CSVExport, RowsPerFile: 2}
+	keys, err := ExportTrainingSet(context.Background(), archiver, export, def, &sliceTrainingSetIterator{rows: rows})
+	if err != nil {
+		t.Fatalf("Failed to export training set: %s", err)
+	}
+	if len(keys) != 2 || keys[0] != "fraud/v1/part-00000.csv" || keys[1] != "fraud/v1/part-00001.csv" {
+		t.Fatalf("Unexpected export files: %v", keys)
+	}
+	if got := string(archiver.objects[keys[0]]); got != "amount__v1,country__v1,label\n12.5,US,true\n,CA,false\n" {
+		t.Fatalf("Unexpected CSV: %q", got)
+	}
+	export.Format = TFRecordExport
+	if keys, err = ExportTrainingSet(context.Background(), archiver, export, def, &sliceTrainingSetIterator{rows: rows[:1]}); err != nil {
+		t.Fatalf("Failed to export training set: %s", err)
+	}
+	record := archiver.objects[keys[0]]
+	length := binary.LittleEndian.Uint64(record[:8])
+	if binary.LittleEndian.Uint32(record[8:12]) != maskedCRC(record[:8]) || uint64(len(record)) != 16+length {
+		t.Fatalf("Invalid TFRecord framing: %x", record)
+	}
+	example := record[12 : 12+length]
+	if binary.LittleEndian.Uint32(record[12+length:]) != maskedCRC(example) {
+		t.Fatalf("Invalid TFRecord checksum")
+	}
+	decoded := &tfexample.Example{}
+	if err := proto.Unmarshal(example, decoded); err != nil {
+		t.Fatalf("Failed to decode example: %s", err)
+	}
+	features := decoded.GetFeatures().GetFeature()
+	if amount := features["amount__v1"].GetFloatList().GetValue(); len(amount) != 1 || amount[0] != 12.5 {
+		t.Fatalf("Unexpected amount feature: %v", features["amount__v1"])
+	}
+	if country := features["country__v1"].GetBytesList().GetValue(); len(country) != 1 || string(country[0]) != "US" {
+		t.Fatalf("Unexpected country feature: %v", features["country__v1"])
+	}
+	if label := features["label"].GetInt64List().GetValue(); len(label) != 1 || label[0] != 1 {
+		t.Fatalf("Unexpected label feature: %v", features["label"])
+	}
+	export.Format = ParquetExport
+	export.RowsPerFile = 0
+	if keys, err = ExportTrainingSet(context.Background(), archiver, export, def, &sliceTrainingSetIterator{rows: rows}); err != nil {
+		t.Fatalf("Failed to export training set: %s", err)
+	}
+	expected := `[{"Amount__v1":12.5,"Country__v1":"US","Label":true},{"Amount__v1":null,"Country__v1":"CA","Label":false},{"Amount__v1":3,"Country__v1":"US","Label":false}]`
+	if len(keys) != 1 || keys[0] != "fraud/v1/part-00000.parquet" {
+		t.Fatalf("Unexpected export files: %v", keys)
+	}
+	if got := readParquet(t, archiver.objects[keys[0]]); got != expected {
+		t.Fatalf("Expected to read back %s, got %s", expected, got)
+	}
+	export.Format = "XLSX"
+	if _, err := ExportTrainingSet(context.Background(), archiver, export, def, &sliceTrainingSetIterator{rows: rows}); err == nil {
+		t.Fatalf("Exported training set in an unknown format")
+	}
+}
+
+func TestGCSArchiver(t *testing.T) {
+	var uploaded string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/computeMetadata/v1/instance/service-accounts/default/token" {
+			fmt.Fprint(w, `{"access_token": "gcp-token"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer gcp-token" || r.URL.Path != "/upload/storage/v1/b/bucket/o" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		uploaded = r.URL.Query().Get("name") + "=" + string(body)
+	}))
+	defer server.Close()
+	metadataEndpoint, storageEndpoint := gcpMetadataEndpoint, gcsEndpoint
+	gcpMetadataEndpoint, gcsEndpoint = server.URL, server.URL
+	defer func() { gcpMetadataEndpoint, gcsEndpoint = metadataEndpoint, storageEndpoint }()
+	archiver, err := NewArchiver("gs://bucket/exports")
+	if err != nil {
+		t.Fatalf("Failed to create archiver: %s", err)
+	}
+	if err := archiver.Archive(context.Background(), "fraud/v1/part-00000.csv", []byte("data")); err != nil {
+		t.Fatalf("Failed to archive: %s", err)
+	}
+	if uploaded != "exports/fraud/v1/part-00000.csv=data" {
+		t.Fatalf("Unexpected upload: %s", uploaded)
+	}
+	if _, err := NewArchiver("gs:///exports"); err == nil {
+		t.Fatalf("Created gcs archiver without a bucket")
+	}
+}
+
+func TestDialects(t *testing.T) {
+	features := []asOfFeature{
+		{column: "feature_a", table: "featureform_cache_a"},
+		{column: "feature_b", table: "featureform_cache_b"},
+	}
+	dialects := map[string]Dialect{
+		"ansi":      ansiDialect{},
+		"postgres":  postgresDialect{},
+		"redshift":  redshiftDialect{},
+		"snowflake": snowflakeDialect{},
+	}
+	since := time.Unix(0, 0)
+	for name, d := range dialects {
+		join := d.asOfJoin("label", features)
+		for _, want := range []string{`"feature_a"`, `"featureform_cache_b"`, `"label"`} {
+			if !strings.Contains(join, want) {
+				t.Fatalf("%s join doesn't contain %s: %s", name, want, join)
+			}
+		}
+		if strings.Contains(d.latestValues("source", time.Time{}, time.Time{}), "ts >") {
+			t.Fatalf("%s latest values is filtered without a since time", name)
+		}
+		if filtered := d.latestValues("source", since, time.Time{}); !strings.Contains(filtered, "ts > "+timestampLiteral(since)) {
+			t.Fatalf("%s latest values isn't filtered by since time: %s", name, filtered)
+		}
+	}
+	if !strings.Contains(postgresDialect{}.asOfJoin("label", features), "LATERAL") {
+		t.Fatalf("Postgres doesn't use a lateral join")
+	}
+	if !strings.Contains(redshiftDialect{}.asOfJoin("label", features), "t2_rnk") {
+		t.Fatalf("Redshift doesn't rank feature values")
+	}
+	if got := (ansiDialect{}).quote(`a"b`); got != `"a""b"` {
+		t.Fatalf("Quoted identifier is %s", got)
+	}
+	tables := map[Dialect]string{
+		postgresDialect{}:    `CREATE TABLE "res" (entity VARCHAR, value FLOAT8, ts TIMESTAMPTZ, UNIQUE (entity, ts))`,
+		redshiftDialect{}:    `CREATE TABLE "res" (entity VARCHAR, value DOUBLE PRECISION, ts TIMESTAMPTZ, UNIQUE (entity, ts))`,
+		snowflakeDialect{}:   `CREATE TABLE "res" (entity VARCHAR, value FLOAT8, ts TIMESTAMP_NTZ, UNIQUE (entity, ts))`,
+		newBigQueryDialect(): "CREATE TABLE `res` (entity STRING, value FLOAT64, ts TIMESTAMP)",
+	}
+	for d, want := range tables {
+		if got, err := resourceTableQuery(d, "res", Float64); err != nil || got != want {
+			t.Fatalf("Resource table query is %s (%v), expected %s", got, err, want)
+		}
+	}
+	schema := ResourceSchema{Entity: `"user"`, Value: `"amount"`, SourceTable: `"txns"`}
+	view := resourceViewQuery(postgresDialect{}, "res", schema)
+	if !strings.Contains(view, `"user" as entity`) || !strings.Contains(view, "::TIMESTAMPTZ as ts") {
+		t.Fatalf("Resource view without a timestamp column is %s", view)
+	}
+	schema.TS = `"ts"`
+	if view := resourceViewQuery(postgresDialect{}, "res", schema); !strings.Contains(view, `"ts" as ts`) {
+		t.Fatalf("Resource view is %s", view)
+	}
+	schema.TS, schema.ValidTo = `"valid_from"`, `"valid_to"`
+	view = resourceViewQuery(postgresDialect{}, "res", schema)
+	for _, part := range []string{
+		`"valid_from" as ts FROM "txns"`,
+		`NULL as value, expired."valid_to" as ts`,
+		`next."user" = expired."user" AND next."valid_from" = expired."valid_to"`,
+	} {
+		if !strings.Contains(view, part) {
+			t.Fatalf("Slowly changing dimension view %s doesn't contain %s", view, part)
+		}
+	}
+}
+
+func TestTransformationViews(t *testing.T) {
+	if got := transformationViewQuery(postgresDialect{}, "t", "SELECT 1", false); got != `CREATE VIEW "t" AS SELECT 1` {
+		t.Fatalf("View query is %s", got)
+	}
+	if got := transformationViewQuery(newBigQueryDialect(), "t", "SELECT 1", true); got != "CREATE OR REPLACE VIEW `t` AS SELECT 1" {
+		t.Fatalf("Replacing view query is %s", got)
+	}
+	if got := (ansiDialect{}).materializedView("t", "SELECT 1", false); got != nil {
+		t.Fatalf("ANSI dialect has materialized views: %v", got)
+	}
+	views := map[Dialect][]string{
+		postgresDialect{}:  {`DROP MATERIALIZED VIEW IF EXISTS "t"`, `CREATE MATERIALIZED VIEW "t" AS SELECT 1`},
+		redshiftDialect{}:  {`DROP MATERIALIZED VIEW IF EXISTS "t"`, `CREATE MATERIALIZED VIEW "t" AS SELECT 1`},
+		snowflakeDialect{}: {`CREATE OR REPLACE MATERIALIZED VIEW "t" AS SELECT 1`},
+	}
+	for d, want := range views {
+		if got := d.materializedView("t", "SELECT 1", true); !reflect.DeepEqual(got, want) {
+			t.Fatalf("Replacing materialized view statements are %v, expected %v", got, want)
+		}
+		if got := d.materializedView("t", "SELECT 1", false); !reflect.DeepEqual(got, []string{`CREATE MATERIALIZED VIEW "t" AS SELECT 1`}) {
+			t.Fatalf("Materialized view statements are %v", got)
+		}
+	}
+	config := TransformationConfig{TargetTableID: ResourceID{Name: "t", Variant: "v", Type: Transformation}, Query: "SELECT 1", Storage: MaterializedViewStorage}
+	if got := deserializeTransformation(serializeTransformation(config)); got.Storage != MaterializedViewStorage {
+		t.Fatalf("Transformation storage wasn't serialized, got %q", got.Storage)
+	}
+}
+
+// viewConn is a driver connection that records the statements run on it,
+// in transactions or not, and lists views as the dependents of any relation.
+type viewConn struct {
+	views   [][]driver.Value
+	queries *[]string
+}
+
+func (c viewConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported")
+}
+
+func (c viewConn) Close() error {
+	return nil
+}
+
+func (c viewConn) Begin() (driver.Tx, error) {
+	*c.queries = append(*c.queries, "BEGIN")
+	return c, nil
+}
+
+func (c viewConn) Commit() error {
+	*c.queries = append(*c.queries, "COMMIT")
+	return nil
+}
+
+func (c viewConn) Rollback() error {
+	*c.queries = append(*c.queries, "ROLLBACK")
+	return nil
+}
+
+func (c viewConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.queries = append(*c.queries, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c viewConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	*c.queries = append(*c.queries, "LIST VIEWS")
+	return &valueRows{columns: []string{"name", "materialized", "query"}, values: c.views}, nil
+}
+
+type valueRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *valueRows) Columns() []string {
+	return r.columns
+}
+
+func (r *valueRows) Close() error {
+	return nil
+}
+
+func (r *valueRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+type viewConnector struct {
+	conn viewConn
+}
+
+func (c viewConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c viewConnector) Driver() driver.Driver {
+	return nil
+}
+
+func TestReplaceRelationKeepsViews(t *testing.T) {
+	var queries []string
+	views := [][]driver.Value{
+		{"public.v", false, " SELECT t.x FROM t;"},
+		{"public.mv", true, " SELECT v.x FROM v;"},
+	}
+	db := sql.OpenDB(viewConnector{viewConn{views: views, queries: &queries}})
+	if err := (postgresSQLQueries{}).atomicUpdate(db, "t", `"tmp_t"`, `CREATE TABLE "tmp_t" AS SELECT 1`); err != nil {
+		t.Fatalf("Could not replace table: %v", err)
+	}
+	want := []string{
+		"BEGIN",
+		"LIST VIEWS",
+		"DROP MATERIALIZED VIEW public.mv",
+		"DROP VIEW public.v",
+		`CREATE TABLE "tmp_t" AS SELECT 1`,
+		`ALTER TABLE "t" RENAME TO "old_t"`,
+		`ALTER TABLE "tmp_t" RENAME TO "t"`,
+		`DROP TABLE "old_t"`,
+		"CREATE VIEW public.v AS SELECT t.x FROM t",
+		"CREATE MATERIALIZED VIEW public.mv AS SELECT v.x FROM v",
+		"COMMIT",
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Fatalf("Queries run are %v, expected %v", queries, want)
+	}
+	queries = nil
+	if err := replaceRelation(db, snowflakeDialect{}, "t", `CREATE OR REPLACE MATERIALIZED VIEW "t" AS SELECT 1`); err != nil {
+		t.Fatalf("Could not replace materialized view: %v", err)
+	}
+	if want := []string{`CREATE OR REPLACE MATERIALIZED VIEW "t" AS SELECT 1`}; !reflect.DeepEqual(queries, want) {
+		t.Fatalf("Queries run where views are bound to names are %v, expected %v", queries, want)
+	}
+}
+
+func TestTransformationStatements(t *testing.T) {
+	query := "CREATE TABLE helper AS SELECT ';' AS s; -- a ; comment\n" +
+		"CREATE FUNCTION f() RETURNS text AS $body$ SELECT 'a;b' $body$ LANGUAGE sql;\n" +
+		"SELECT E'\\';', f() FROM helper; -- done"
+	setup, selectQuery, err := transformationStatements(postgresDialect{}, query)
+	if err != nil {
+		t.Fatalf("Could not split transformation: %v", err)
+	}
+	expected := []string{
+		"CREATE TABLE helper AS SELECT ';' AS s",
+		"-- a ; comment\nCREATE FUNCTION f() RETURNS text AS $body$ SELECT 'a;b' $body$ LANGUAGE sql",
+	}
+	if !reflect.DeepEqual(setup, expected) {
+		t.Fatalf("Expected setup statements %q, got %q", expected, setup)
+	}
+	if selectQuery != "SELECT E'\\';', f() FROM helper" {
+		t.Fatalf("Unexpected transformation query %q", selectQuery)
+	}
+	// A trailing comment is ended, so that the SELECT can be wrapped.
+	if _, selectQuery, err = transformationStatements(postgresDialect{}, "SELECT 1 -- done"); err != nil || selectQuery != "SELECT 1 -- done\n" {
+		t.Fatalf("Unexpected transformation query %q: %v", selectQuery, err)
+	}
+	if _, selectQuery, err = transformationStatements(snowflakeDialect{}, "SELECT 'it\\'s;' AS a;"); err != nil || selectQuery != "SELECT 'it\\'s;' AS a" {
+		t.Fatalf("Unexpected snowflake transformation query %q: %v", selectQuery, err)
+	}
+	for _, query := range []string{"", "-- nothing", "SELECT 1; DROP TABLE helper", "SELECT $x$ unclosed"} {
+		if _, _, err := transformationStatements(postgresDialect{}, query); err == nil {
+			t.Fatalf("Expected transformation %q to be invalid", query)
+		}
+	}
+}
+
+func TestCheckSetupStatement(t *testing.T) {
+	temporary := []string{
+		"CREATE TEMP TABLE helper AS SELECT 1",
+		"create or replace temporary table helper (a int)",
+		"/* first */ CREATE LOCAL TEMPORARY TABLE helper (a int)",
+		"CREATE TABLE #helper AS SELECT 1",
+		"SET search_path TO analytics",
+		"INSERT INTO helper VALUES (1)",
+	}
+	for _, statement := range temporary {
+		if err := checkSetupStatement(postgresDialect{}, statement); err != nil {
+			t.Fatalf("Expected %q to be allowed: %v", statement, err)
+		}
+	}
+	persistent := []string{
+		"CREATE TABLE helper AS SELECT 1",
+		"CREATE OR REPLACE FUNCTION f() RETURNS int AS 'SELECT 1' LANGUAGE sql",
+		"-- temp\nCREATE VIEW temp AS SELECT 1",
+	}
+	for _, statement := range persistent {
+		if err := checkSetupStatement(postgresDialect{}, statement); err == nil {
+			t.Fatalf("Expected %q to be rejected", statement)
+		}
+	}
+}
+
+// closingViewConn is a viewConn that records when it's closed.
+type closingViewConn struct {
+	viewConn
+}
+
+func (c closingViewConn) Close() error {
+	*c.queries = append(*c.queries, "CLOSE")
+	return nil
+}
+
+type closingViewConnector struct {
+	conn closingViewConn
+}
+
+func (c closingViewConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c closingViewConnector) Driver() driver.Driver {
+	return nil
+}
+
+func TestTransformationSetupSession(t *testing.T) {
+	var queries []string
+	db := sql.OpenDB(closingViewConnector{closingViewConn{viewConn{queries: &queries}}})
+	store := &sqlOfflineStore{db: db, query: &postgresSQLQueries{}}
+	config := TransformationConfig{
+		TargetTableID: ResourceID{Name: "t", Variant: "v", Type: Transformation},
+		Query:         "CREATE TEMP TABLE helper AS SELECT 1 AS a; SELECT a FROM helper",
+	}
+	if err := store.UpdateTransformation(config); err != nil {
+		t.Fatalf("Could not update transformation: %v", err)
+	}
+	// The setup and the table's replacement run in one transaction, and the
+	// session is ended so that the temporary table doesn't outlive it.
+	want := []string{
+		"BEGIN",
+		"CREATE TEMP TABLE helper AS SELECT 1 AS a",
+		"LIST VIEWS",
+		`CREATE TABLE  "tmp_featureform_primary_t__v" AS SELECT a FROM helper`,
+		`ALTER TABLE "featureform_primary_t__v" RENAME TO "old_featureform_primary_t__v"`,
+		`ALTER TABLE "tmp_featureform_primary_t__v" RENAME TO "featureform_primary_t__v"`,
+		`DROP TABLE "old_featureform_primary_t__v"`,
+		"COMMIT",
+		"CLOSE",
+	}
+	if len(queries) < len(want) || !reflect.DeepEqual(queries[:len(want)], want) {
+		t.Fatalf("Queries run are %v, expected them to start with %v", queries, want)
+	}
+	config.Query = "CREATE TABLE helper AS SELECT 1 AS a; SELECT a FROM helper"
+	if err := store.UpdateTransformation(config); err == nil {
+		t.Fatalf("Expected setup that creates a table to be rejected")
+	}
+	config.Query = "CREATE TEMP TABLE helper AS SELECT 1 AS a; SELECT a FROM helper"
+	config.Storage = ViewStorage
+	if err := store.UpdateTransformation(config); err == nil {
+		t.Fatalf("Expected a view with setup to be rejected")
+	}
+}
+
+func TestIdentifierQuoting(t *testing.T) {
+	quoted := map[string]string{
+		"order":               `"order"`,
+		"userId":              `"userId"`,
+		"public.transactions": `"public"."transactions"`,
+		`"My.Schema".table`:   `"My.Schema"."table"`,
+		`"already"`:           `"already"`,
+		`a"b`:                 `"a""b"`,
+	}
+	for name, want := range quoted {
+		if got := (ansiDialect{}).quoteName(name); got != want {
+			t.Fatalf("%s quoted as %s, expected %s", name, got, want)
+		}
+	}
+	if got := newBigQueryDialect().quoteName("project.dataset.select"); got != "`project`.`dataset`.`select`" {
+		t.Fatalf("BigQuery name quoted as %s", got)
+	}
+	if got := newBigQueryDialect().quote("a`b"); got != "`a``b`" {
+		t.Fatalf("BigQuery identifier quoted as %s", got)
+	}
+	columns := []string{"USER_ID", "Amount", "amount_usd", "ts", "TS"}
+	resolved := map[string]string{
+		"user_id":    "USER_ID",
+		"amount":     "Amount",
+		"AMOUNT_USD": "amount_usd",
+		"ts":         "ts",
+		// Ambiguous and missing columns are left for the warehouse to reject.
+		"Ts":      "Ts",
+		"missing": "missing",
+	}
+	for name, want := range resolved {
+		if got := resolveIdentifier(columns, name); got != want {
+			t.Fatalf("%s resolved to %s, expected %s", name, got, want)
+		}
+	}
+	if got := nameCandidates("Transactions", '"'); !reflect.DeepEqual(got, []string{"Transactions", "transactions", "TRANSACTIONS"}) {
+		t.Fatalf("Unexpected candidates: %v", got)
+	}
+	if got := nameCandidates(`"Transactions"`, '"'); len(got) != 1 {
+		t.Fatalf("Quoted name has other candidates: %v", got)
+	}
+}
+
+func TestParseTableName(t *testing.T) {
+	resources := []ResourceID{
+		{Name: "feature", Variant: "variant", Type: Feature},
+		{Name: "label", Variant: "variant", Type: Label},
+		{Name: "training_set", Variant: "variant", Type: TrainingSet},
+		{Name: "source", Variant: "variant", Type: Primary},
+	}
+	store := &sqlOfflineStore{}
+	for _, id := range resources {
+		name, err := store.getTableName(id)
+		if err != nil {
+			t.Fatalf("Failed to name %v table: %v", id, err)
+		}
+		if parsed, ok := parseTableName(name); !ok || parsed != id {
+			t.Fatalf("Parsed %s as %v, expected %v", name, parsed, id)
+		}
+	}
+	for _, name := range []string{"featureform_materialization_feature", "featureform_cache_abc", "featureform_primary_bad", "users"} {
+		if id, ok := parseTableName(name); ok {
+			t.Fatalf("Parsed %s as resource %v", name, id)
+		}
+	}
+}
+
+// recordingConn is a driver connection that records the statements run on
+// it, and reports its session ID as id.
+type recordingConn struct {
+	id      int64
+	mtx     *sync.Mutex
+	queries *[]string
+}
+
+func (c recordingConn) record(query string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	*c.queries = append(*c.queries, query)
+}
+
+func (c recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare not supported")
+}
+
+func (c recordingConn) Close() error {
+	return nil
+}
+
+func (c recordingConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions not supported")
+}
+
+func (c recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query)
+	return &sessionIDRows{id: c.id}, nil
+}
+
+type sessionIDRows struct {
+	id   int64
+	done bool
+}
+
+func (r *sessionIDRows) Columns() []string {
+	return []string{"id"}
+}
+
+func (r *sessionIDRows) Close() error {
+	return nil
+}
+
+func (r *sessionIDRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = []byte(strconv.FormatInt(r.id, 10))
+	return nil
+}
+
+type recordingConnector struct {
+	conn recordingConn
+}
+
+func (c recordingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c recordingConnector) Driver() driver.Driver {
+	return nil
+}
+
+func TestStatementCancellation(t *testing.T) {
+	var mtx sync.Mutex
+	var queries []string
+	connector := recordingConnector{recordingConn{id: 42, mtx: &mtx, queries: &queries}}
+	store := &sqlOfflineStore{
+		db:     sql.OpenDB(connector),
+		parent: SQLOfflineStoreConfig{Connector: connector},
+		query:  &defaultOfflineSQLQueries{},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	jobStore, release, err := store.WithContext(ctx)
+	if err != nil {
+		t.Fatalf("Could not tie store to context: %v", err)
+	}
+	if _, err := jobStore.(*sqlOfflineStore).db.Exec("SELECT 1"); err != nil {
+		t.Fatalf("Could not run job query: %v", err)
+	}
+	cancel()
+	release()
+	want := []string{
+		"ALTER SESSION SET STATEMENT_TIMEOUT_IN_SECONDS = 60",
+		"SELECT CURRENT_SESSION()",
+		"SELECT 1",
+		"SELECT SYSTEM$CANCEL_ALL_QUERIES(42)",
+	}
+	if !reflect.DeepEqual(queries, want) {
+		t.Fatalf("Queries run are %v, expected %v", queries, want)
+	}
+	if got := (postgresDialect{}).cancelSession(42); got != "SELECT pg_cancel_backend(42)" {
+		t.Fatalf("Postgres cancel query is %s", got)
+	}
+	if got := postgresStatementTimeout(1500 * time.Millisecond); got != "SET statement_timeout TO 1500" {
+		t.Fatalf("Postgres statement timeout is %s", got)
+	}
+	if (ansiDialect{}).statementTimeout(time.Minute) != "" {
+		t.Fatalf("ANSI dialect sets a statement timeout")
+	}
+}
+
+func TestMaintenanceStatements(t *testing.T) {
+	type testCase struct {
+		Queries  maintenanceQueries
+		Task     MaintenanceTask
+		Expected []string
+	}
+	tests := map[string]testCase{
+		"Postgres":  {postgresSQLQueries{}, VacuumAnalyze, []string{`VACUUM (ANALYZE) "mat"`}},
+		"Redshift":  {redshiftSQLQueries{}, VacuumAnalyze, []string{`VACUUM "mat"`, `ANALYZE "mat"`}},
+		"Snowflake": {snowflakeSQLQueries{}, Optimize, []string{`ALTER TABLE "mat" CLUSTER BY (entity)`}},
+	}
+	for name, test := range tests {
+		if !supportsMaintenance(test.Queries.maintenanceTasks(), test.Task) {
+			t.Fatalf("%s doesn't support %s", name, test.Task)
+		}
+		if got := test.Queries.maintenance(test.Task, "mat"); !reflect.DeepEqual(got, test.Expected) {
+			t.Fatalf("%s %s statements are %v, expected %v", name, test.Task, got, test.Expected)
+		}
+		if got := test.Queries.maintenance(Defragment, "mat"); got != nil {
+			t.Fatalf("%s has statements for unsupported maintenance: %v", name, got)
+		}
+	}
+	store := &sqlOfflineStore{query: &defaultOfflineSQLQueries{}}
+	if store.MaintenanceTasks() != nil {
+		t.Fatalf("Store without maintenance queries has maintenance tasks")
+	}
+	var unsupported *UnsupportedMaintenance
+	if err := store.RunMaintenance(context.Background(), VacuumAnalyze); !errors.As(err, &unsupported) {
+		t.Fatalf("Expected unsupported maintenance, got %v", err)
+	}
+}
+
+func TestLikePrefix(t *testing.T) {
+	tests := map[string]string{
+		"featureform_materialization_a__b_gen_": "featureform!_materialization!_a!_!_b!_gen!_%",
+		"100%!":                                 "100!%!!%",
+		"":                                      "%",
+	}
+	for prefix, expected := range tests {
+		if got := likePrefix(prefix); got != expected {
+			t.Fatalf("Pattern of prefix %q is %q, expected %q", prefix, got, expected)
+		}
+	}
+}
+
+func TestMemoryStreamingSource(t *testing.T) {
+	stream := NewMemoryStreamingSource()
+	if _, err := AsStreamingSource(stream); err != nil {
+		t.Fatalf("Memory stream isn't a streaming source: %v", err)
+	}
+	if _, err := AsStreamingSource(NewLocalOnlineStore()); err == nil {
+		t.Fatalf("Expected an online store not to be a streaming source")
+	}
+	stream.Publish("topic", ResourceRecord{Entity: "a", Value: 1}, ResourceRecord{Entity: "b", Value: 2})
+	reader, err := stream.Subscribe("topic", StreamOffsets{"0": "0"})
+	if err != nil {
+		t.Fatalf("Failed to subscribe: %v", err)
+	}
+	record, err := reader.Next(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to read record: %v", err)
+	}
+	if record.Entity != "b" || record.Offset != "1" {
+		t.Fatalf("Expected to read b at offset 1 after the offset, got %+v", record)
+	}
+	read := make(chan StreamRecord)
+	go func() {
+		record, _ := reader.Next(context.Background())
+		read <- record
+	}()
+	stream.Publish("topic", ResourceRecord{Entity: "c", Value: 3})
+	if record := <-read; record.Entity != "c" {
+		t.Fatalf("Expected a waiting reader to read c once it's published, got %+v", record)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := reader.Next(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected reading past the end to wait for ctx, got %v", err)
+	}
+	if _, err := stream.Subscribe("topic", StreamOffsets{"0": "x"}); err == nil {
+		t.Fatalf("Expected an invalid offset to fail")
+	}
+}
+
+func TestKafkaStreamingSource(t *testing.T) {
+	if _, err := Get(KafkaStream, KafkaConfig{}.Serialized()); err == nil {
+		t.Fatalf("Expected a kafka config without brokers to fail")
+	}
+	p, err := Get(KafkaStream, KafkaConfig{Brokers: []string{"localhost:9092"}, Username: "user", Password: "pass"}.Serialized())
+	if err != nil {
+		t.Fatalf("Failed to create kafka source: %v", err)
+	}
+	if _, err := AsStreamingSource(p); err != nil {
+		t.Fatalf("Kafka isn't a streaming source: %v", err)
+	}
+	ts := time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC)
+	record, err := kafkaRecord(kafka.Message{Partition: 2, Offset: 41, Key: []byte("a"), Value: []byte(`"home"`), Time: ts})
+	if err != nil {
+		t.Fatalf("Failed to decode record: %v", err)
+	}
+	expected := StreamRecord{ResourceRecord: ResourceRecord{Entity: "a", Value: "home", TS: ts}, Partition: "2", Offset: "41"}
+	if !reflect.DeepEqual(record, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, record)
+	}
+	if _, err := kafkaRecord(kafka.Message{Key: []byte("a"), Value: []byte("home")}); err == nil {
+		t.Fatalf("Expected a value that isn't JSON to fail")
+	}
+}
@@ -0,0 +1,357 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// QuerySyntax is how an offline store quotes identifiers and lexes strings,
+// which is what's needed to fill in the templates of a transformation's
+// query and split it into statements without looking inside its strings and
+// comments.
+type QuerySyntax struct {
+	// IdentQuote is the character identifiers are quoted with.
+	IdentQuote byte
+	// BackslashEscapes is whether a backslash escapes the character after it
+	// in a quoted string. Strings written E'...' always take escapes.
+	BackslashEscapes bool
+}
+
+// TypeQuerySyntax is the QuerySyntax of the offline store of type t, or of
+// the ANSI dialect if t isn't a SQL store.
+func TypeQuerySyntax(t Type) QuerySyntax {
+	return dialectSyntax(typeDialect(t))
+}
+
+func dialectSyntax(d Dialect) QuerySyntax {
+	return QuerySyntax{IdentQuote: d.quoteChar(), BackslashEscapes: d.backslashEscapes()}
+}
+
+// Quote quotes an identifier, such as a resource's table name.
+func (s QuerySyntax) Quote(ident string) string {
+	return quoteIdentifier(ident, s.IdentQuote)
+}
+
+type SQLTokenKind int
+
+const (
+	// SQLCode is anything that isn't one of the other kinds, up to the
+	// start of the next token.
+	SQLCode SQLTokenKind = iota
+	SQLLineComment
+	SQLBlockComment
+	// SQLQuoted is a quoted string or identifier, including a dollar-quoted
+	// string like $body$...$body$.
+	SQLQuoted
+	// SQLTemplate is a {{ ... }} template of a transformation's query.
+	SQLTemplate
+)
+
+// SQLToken is a piece of a query. Joining the Text of each of a query's
+// tokens gives the query back.
+type SQLToken struct {
+	Kind SQLTokenKind
+	Text string
+	// Offset is where the token starts in the query.
+	Offset int
+}
+
+// Tokens splits a query into tokens the way SQL is lexed, so that braces and
+// semicolons in comments and quoted strings aren't taken for templates or
+// the ends of statements. A template inside another, or a comment, string or
+// template that's never closed, is an error.
+func (s QuerySyntax) Tokens(query string) ([]SQLToken, error) {
+	tokens := make([]SQLToken, 0)
+	code := -1
+	endCode := func(i int) {
+		if code != -1 {
+			tokens = append(tokens, SQLToken{Kind: SQLCode, Text: query[code:i], Offset: code})
+			code = -1
+		}
+	}
+	for i := 0; i < len(query); {
+		kind, n, err := s.token(query, i)
+		if err != nil {
+			return nil, err
+		}
+		if kind == SQLCode {
+			if code == -1 {
+				code = i
+			}
+			i += n
+			continue
+		}
+		endCode(i)
+		tokens = append(tokens, SQLToken{Kind: kind, Text: query[i : i+n], Offset: i})
+		i += n
+	}
+	endCode(len(query))
+	return tokens, nil
+}
+
+// token returns the kind and length of the token at offset i of query. Code
+// is returned a byte at a time.
+func (s QuerySyntax) token(query string, i int) (SQLTokenKind, int, error) {
+	rest := query[i:]
+	switch {
+	case strings.HasPrefix(rest, "--"):
+		end := strings.IndexByte(rest, '\n')
+		if end == -1 {
+			end = len(rest)
+		}
+		return SQLLineComment, end, nil
+	case strings.HasPrefix(rest, "/*"):
+		end := strings.Index(rest[2:], "*/")
+		if end == -1 {
+			return 0, 0, fmt.Errorf("comment at offset %d is never closed", i)
+		}
+		return SQLBlockComment, end + 4, nil
+	case strings.HasPrefix(rest, "{{"):
+		end := strings.Index(rest[2:], "}}")
+		if end == -1 {
+			return 0, 0, fmt.Errorf("template at offset %d is never closed", i)
+		}
+		if nested := strings.Index(rest[2:end+2], "{{"); nested != -1 {
+			return 0, 0, fmt.Errorf("template at offset %d is nested in another", i+2+nested)
+		}
+		return SQLTemplate, end + 4, nil
+	case rest[0] == '\'' || rest[0] == '"' || rest[0] == '`':
+		escapes := s.BackslashEscapes && rest[0] != s.IdentQuote
+		end := quotedEnd(rest, escapes)
+		if end == -1 {
+			return 0, 0, fmt.Errorf("%c quote at offset %d is never closed", rest[0], i)
+		}
+		return SQLQuoted, end, nil
+	case (rest[0] == 'E' || rest[0] == 'e') && strings.HasPrefix(rest[1:], "'") && !identByteBefore(query, i):
+		end := quotedEnd(rest[1:], true)
+		if end == -1 {
+			return 0, 0, fmt.Errorf("' quote at offset %d is never closed", i+1)
+		}
+		return SQLQuoted, end + 1, nil
+	case rest[0] == '$' && !identByteBefore(query, i):
+		tag := dollarTag(rest)
+		if tag == "" {
+			return SQLCode, 1, nil
+		}
+		end := strings.Index(rest[len(tag):], tag)
+		if end == -1 {
+			return 0, 0, fmt.Errorf("%s quote at offset %d is never closed", tag, i)
+		}
+		return SQLQuoted, len(tag) + end + len(tag), nil
+	default:
+		return SQLCode, 1, nil
+	}
+}
+
+// quotedEnd returns the length of the quoted string or identifier at the
+// start of s, where a doubled quote is an escaped one, as is a quote after a
+// backslash if escapes is set, or -1 if it isn't closed.
+func quotedEnd(s string, escapes bool) int {
+	q := s[0]
+	for i := 1; i < len(s); i++ {
+		if escapes && s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] != q {
+			continue
+		}
+		if i+1 < len(s) && s[i+1] == q {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return -1
+}
+
+// dollarTag returns the opening $tag$ of a dollar-quoted string at the start
+// of s, or an empty string if there isn't one, such as for a $1 parameter.
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		c := rune(s[i])
+		switch {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || unicode.IsLetter(c) || (i > 1 && unicode.IsDigit(c)):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// identByteBefore is whether the byte before offset i of query is part of an
+// identifier, so that i is in the middle of a word rather than at the start
+// of a string.
+func identByteBefore(query string, i int) bool {
+	if i == 0 {
+		return false
+	}
+	c := rune(query[i-1])
+	return c == '_' || c == '$' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+// SplitStatements splits a query on the semicolons between its statements,
+// leaving out those that are only whitespace and comments. A statement that
+// ends in a line comment is ended with a newline, so that a statement
+// wrapped around it isn't commented out.
+func (s QuerySyntax) SplitStatements(query string) ([]string, error) {
+	tokens, err := s.Tokens(query)
+	if err != nil {
+		return nil, err
+	}
+	statements := make([]string, 0, 1)
+	var current strings.Builder
+	hasCode, endsInComment := false, false
+	end := func() {
+		if hasCode {
+			statement := strings.TrimSpace(current.String())
+			if endsInComment {
+				statement += "\n"
+			}
+			statements = append(statements, statement)
+		}
+		current.Reset()
+		hasCode, endsInComment = false, false
+	}
+	for _, token := range tokens {
+		if token.Kind != SQLCode {
+			current.WriteString(token.Text)
+			if token.Kind != SQLLineComment && token.Kind != SQLBlockComment {
+				hasCode = true
+			}
+			endsInComment = token.Kind == SQLLineComment
+			continue
+		}
+		text := token.Text
+		for {
+			semicolon := strings.IndexByte(text, ';')
+			part := text
+			if semicolon != -1 {
+				part = text[:semicolon]
+			}
+			current.WriteString(part)
+			if strings.TrimSpace(part) != "" {
+				hasCode, endsInComment = true, false
+			}
+			if semicolon == -1 {
+				break
+			}
+			end()
+			text = text[semicolon+1:]
+		}
+	}
+	end()
+	return statements, nil
+}
+
+// transformationStatements splits a transformation's query into the
+// statements that are run before it, such as to create a table its SELECT
+// reads from, and the SELECT, whose result is the transformation's.
+func transformationStatements(d Dialect, query string) ([]string, string, error) {
+	statements, err := dialectSyntax(d).SplitStatements(query)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid transformation query: %w", err)
+	}
+	if len(statements) == 0 {
+		return nil, "", fmt.Errorf("transformation query is empty")
+	}
+	last := statements[len(statements)-1]
+	if !isQueryStatement(d, last) {
+		return nil, "", fmt.Errorf("the last statement of a transformation must be a query: %s", last)
+	}
+	return statements[:len(statements)-1], last, nil
+}
+
+// isQueryStatement is whether a statement's first word, after any comments,
+// starts a query that returns rows.
+func isQueryStatement(d Dialect, statement string) bool {
+	tokens, err := dialectSyntax(d).Tokens(statement)
+	if err != nil {
+		return false
+	}
+	for _, token := range tokens {
+		if token.Kind == SQLLineComment || token.Kind == SQLBlockComment {
+			continue
+		}
+		if token.Kind != SQLCode {
+			return false
+		}
+		code := strings.TrimSpace(token.Text)
+		if code == "" {
+			continue
+		}
+		if code[0] == '(' {
+			return true
+		}
+		n := strings.IndexFunc(code, func(r rune) bool { return !unicode.IsLetter(r) })
+		if n == -1 {
+			n = len(code)
+		}
+		word := strings.ToUpper(code[:n])
+		return word == "SELECT" || word == "WITH" || word == "VALUES" || word == "TABLE"
+	}
+	return false
+}
+
+// checkSetupStatement checks that a statement run before a transformation's
+// SELECT only creates what lasts as long as its session. The session ends
+// once the transformation is created, and the statements run again with each
+// update, so anything else they created would be left behind, and would
+// already exist the next time.
+func checkSetupStatement(d Dialect, statement string) error {
+	words := leadingWords(d, statement, 5)
+	if len(words) == 0 || words[0] != "CREATE" {
+		return nil
+	}
+	for i := 1; i < len(words); i++ {
+		switch words[i] {
+		case "OR", "REPLACE", "LOCAL", "GLOBAL":
+			continue
+		case "TEMP", "TEMPORARY", "VOLATILE":
+			return nil
+		case "TABLE":
+			// Redshift's temporary tables can be named with a leading #.
+			if i+1 < len(words) && strings.HasPrefix(words[i+1], "#") {
+				return nil
+			}
+		}
+		break
+	}
+	return fmt.Errorf("a transformation can only create temporary objects before its query: %s", statement)
+}
+
+// leadingWords returns up to n of the first words of a statement's code, in
+// upper case, skipping comments and stopping at the first quoted string or
+// identifier.
+func leadingWords(d Dialect, statement string, n int) []string {
+	tokens, err := dialectSyntax(d).Tokens(statement)
+	if err != nil {
+		return nil
+	}
+	words := make([]string, 0, n)
+	for _, token := range tokens {
+		if token.Kind == SQLLineComment || token.Kind == SQLBlockComment {
+			continue
+		}
+		if token.Kind != SQLCode {
+			break
+		}
+		fields := strings.FieldsFunc(token.Text, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '#'
+		})
+		for _, field := range fields {
+			if len(words) == n {
+				return words
+			}
+			words = append(words, strings.ToUpper(field))
+		}
+	}
+	return words
+}
@@ -20,6 +20,8 @@ const (
 	rsString                       = "varchar"
 	rsBool                         = "boolean"
 	rsTimestamp                    = "timestamp with time zone"
+	rsBytes                        = "varbyte"
+	rsDecimal                      = "numeric"
 )
 
 type RedshiftConfig struct {
@@ -28,6 +30,7 @@ type RedshiftConfig struct {
 	Database string
 	Username string
 	Password string
+	TLSConfig
 }
 
 func (rs *RedshiftConfig) Deserialize(config SerializedConfig) error {
@@ -51,11 +54,14 @@ func redshiftOfflineStoreFactory(config SerializedConfig) (Provider, error) {
 	if err := sc.Deserialize(config); err != nil {
 		return nil, errors.New("invalid redshift config")
 	}
+	if err := sc.validate(); err != nil {
+		return nil, fmt.Errorf("invalid redshift tls config: %w", err)
+	}
 	queries := redshiftSQLQueries{}
 	queries.setVariableBinding(PostgresBindingStyle)
 	sgConfig := SQLOfflineStoreConfig{
 		Config:        config,
-		ConnectionURL: fmt.Sprintf("sslmode=require user=%v password=%s host=%v port=%v dbname=%v", sc.Username, sc.Password, sc.Endpoint, sc.Port, sc.Database),
+		ConnectionURL: fmt.Sprintf("%s user=%s password=%s host=%s port=%s dbname=%s", sc.dsnParams(SSLRequire), dsnValue(sc.Username), dsnValue(sc.Password), dsnValue(sc.Endpoint), dsnValue(sc.Port), dsnValue(sc.Database)),
 		Driver:        "postgres",
 		ProviderType:  RedshiftOffline,
 		QueryImpl:     &queries,
@@ -80,50 +86,32 @@ func (q redshiftSQLQueries) viewExists() string {
 	return "SELECT COUNT(*) FROM svv_tables WHERE table_schema='public' AND table_type='VIEW' AND table_name=$1"
 }
 
-func (q redshiftSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
-	var query string
-	if timestamp {
-		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, %s as ts FROM %s", sanitize(tableName),
-			sanitize(schema.Entity), sanitize(schema.Value), sanitize(schema.TS), sanitize(schema.SourceTable))
-	} else {
-		query = fmt.Sprintf("CREATE VIEW %s AS SELECT %s as entity, %s as value, to_timestamp('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMPTZ as ts FROM %s", sanitize(tableName),
-			sanitize(schema.Entity), sanitize(schema.Value), time.UnixMilli(0).UTC(), sanitize(schema.SourceTable))
-	}
-	if _, err := db.Exec(query); err != nil {
-		return err
-	}
-	return nil
-}
-
 func (q redshiftSQLQueries) primaryTableRegister(tableName string, sourceName string) string {
-	query := fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sanitize(sourceName))
+	query := fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sourceName)
 	fmt.Println(query)
 	return query
 }
 
-func (q redshiftSQLQueries) materializationCreate(tableName string, resultName string) string {
-	query := fmt.Sprintf(
-		"CREATE TABLE %s AS (SELECT entity, value, ts, row_number() over(ORDER BY (entity)) as row_number FROM ("+
-			"SELECT entity, value, ts, row_number() OVER (PARTITION BY entity ORDER BY entity, ts DESC) as rn "+
-			"FROM %s) WHERE rn=1 ORDER BY entity)", sanitize(tableName), sanitize(resultName))
+func (q redshiftSQLQueries) dialect() Dialect {
+	return redshiftDialect{}
+}
 
-	return query
+func (q redshiftSQLQueries) materializationCreate(tableName string, query string) string {
+	return fmt.Sprintf("CREATE TABLE %s AS (%s)", sanitize(tableName), query)
 }
 
 func (q redshiftSQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
 	sanitizedTable := sanitize(tableName)
 	tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
-	oldTable := sanitize(fmt.Sprintf("old_%s", tableName))
+	generation := sanitize(generationTableName(tableName, time.Now()))
 	query := fmt.Sprintf(
 		"BEGIN TRANSACTION;"+
-			"CREATE TABLE %s AS (SELECT entity, value, ts, row_number() over(ORDER BY (SELECT NULL)) as row_number FROM "+
-			"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) "+
-			"AS rn FROM %s) t WHERE rn=1);"+
+			"DROP TABLE IF EXISTS %s;"+
+			"CREATE TABLE %s AS (%s);"+
 			"ALTER TABLE %s RENAME TO %s;"+
 			"ALTER TABLE %s RENAME TO %s;"+
-			"DROP TABLE %s;"+
 			"COMMIT;"+
-			"", tempTable, sanitize(sourceName), sanitizedTable, oldTable, tempTable, sanitizedTable, oldTable)
+			"", tempTable, tempTable, q.dialect().latestValues(sourceName, time.Time{}, time.Time{}), sanitizedTable, generation, tempTable, sanitizedTable)
 
 	_, err := db.Exec(query)
 	return err
@@ -133,29 +121,6 @@ func (q redshiftSQLQueries) materializationDrop(tableName string) string {
 	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
 }
 
-func (q redshiftSQLQueries) determineColumnType(valueType ValueType) (string, error) {
-	switch valueType {
-	case Int, Int32, Int64:
-		return "BIGINT", nil
-	case Float32, Float64:
-		return "DOUBLE PRECISION", nil
-	case String:
-		return "VARCHAR", nil
-	case Bool:
-		return "BOOLEAN", nil
-	case Timestamp:
-		return "TIMESTAMPTZ", nil
-	case NilType:
-		return "VARCHAR", nil
-	default:
-		return "", fmt.Errorf("cannot find column type for value type: %s", valueType)
-	}
-}
-
-func (q redshiftSQLQueries) newSQLOfflineTable(name string, columnType string) string {
-	return fmt.Sprintf("CREATE TABLE %s (entity VARCHAR, value %s, ts TIMESTAMPTZ, UNIQUE (entity, ts))", sanitize(name), columnType)
-}
-
 func (q redshiftSQLQueries) createValuePlaceholderString(columns []TableColumn) string {
 	placeholders := make([]string, 0)
 	for i := range columns {
@@ -164,59 +129,6 @@ func (q redshiftSQLQueries) createValuePlaceholderString(columns []TableColumn)
 	return strings.Join(placeholders, ", ")
 }
 
-func (q redshiftSQLQueries) trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
-	return q.trainingSetQuery(store, def, tableName, labelName, false)
-}
-
-func (q redshiftSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
-	return q.trainingSetQuery(store, def, tableName, labelName, true)
-}
-
-func (q redshiftSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
-	columns := make([]string, 0)
-	selectColumns := make([]string, 0)
-	query := ""
-	for i, feature := range def.Features {
-		tableName, err := store.getResourceTableName(feature)
-		santizedName := sanitize(tableName)
-		if err != nil {
-			return err
-		}
-		tableJoinAlias := fmt.Sprintf("t%d", i+1)
-		selectColumns = append(selectColumns, fmt.Sprintf("%s_rnk", tableJoinAlias))
-		columns = append(columns, santizedName)
-		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value AS %s, ts, RANK() OVER (ORDER BY ts DESC) AS %s_rnk FROM %s ORDER BY ts desc) AS %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts)",
-			query, santizedName, tableJoinAlias, santizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
-		if i == len(def.Features)-1 {
-			query = fmt.Sprintf("%s )) WHERE rn=1", query)
-		}
-	}
-	columnStr := strings.Join(columns, ", ")
-	selectColumnStr := strings.Join(selectColumns, ", ")
-
-	if !isUpdate {
-		fullQuery := fmt.Sprintf(
-			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
-				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s DESC) AS rn FROM ( "+
-				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM %s AS t0 %s )",
-			sanitize(tableName), columnStr, selectColumnStr, columnStr, selectColumnStr, sanitize(labelName), query)
-		if _, err := store.db.Exec(fullQuery); err != nil {
-			return err
-		}
-	} else {
-		tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
-		fullQuery := fmt.Sprintf(
-			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
-				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY \"time\", %s desc) AS rn FROM ( "+
-				"SELECT t0.entity AS e, t0.value AS label, t0.ts AS time, %s, %s FROM %s AS t0 %s )",
-			tempTable, columnStr, selectColumnStr, columnStr, selectColumnStr, sanitize(labelName), query)
-
-		err := q.atomicUpdate(store.db, tableName, tempTable, fullQuery)
-		return err
-	}
-	return nil
-}
-
 func (q redshiftSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
 	if v == nil {
 		return v
@@ -234,12 +146,22 @@ func (q redshiftSQLQueries) castTableItemType(v interface{}, t interface{}) inte
 		return v.(bool)
 	case rsTimestamp:
 		return v.(time.Time).UTC()
+	case rsBytes:
+		return v.([]byte)
+	case rsDecimal:
+		return decimalValue(v)
 	default:
 		return v
 	}
 }
 
 func (q redshiftSQLQueries) getValueColumnType(t *sql.ColumnType) interface{} {
+	switch t.DatabaseTypeName() {
+	case "NUMERIC":
+		return rsDecimal
+	case "VARBYTE", "BYTEA":
+		return rsBytes
+	}
 	switch t.ScanType().String() {
 	case "string":
 		return rsString
@@ -261,6 +183,16 @@ func (q redshiftSQLQueries) numRows(n interface{}) (int64, error) {
 	return n.(int64), nil
 }
 
+// tableSize converts svv_table_info's size, which is in 1MB blocks. Views
+// aren't listed there, so they have no rows.
+func (q redshiftSQLQueries) tableSize(db *sql.DB, tableName string) (int64, error) {
+	var blocks int64
+	if err := db.QueryRow(`SELECT size FROM svv_table_info WHERE "table" = $1`, tableName).Scan(&blocks); err != nil {
+		return 0, err
+	}
+	return blocks * 1024 * 1024, nil
+}
+
 func (q redshiftSQLQueries) transformationCreate(name string, query string) string {
 	que := fmt.Sprintf("CREATE TABLE %s AS %s", sanitize(name), query)
 	return que
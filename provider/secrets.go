@@ -0,0 +1,222 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// SecretResolver fetches the secret a URI refers to. The URI's fragment, if
+// any, selects a key from a secret that holds a JSON object.
+type SecretResolver interface {
+	Resolve(ctx context.Context, uri *url.URL) (string, error)
+}
+
+type SecretResolverFunc func(ctx context.Context, uri *url.URL) (string, error)
+
+func (fn SecretResolverFunc) Resolve(ctx context.Context, uri *url.URL) (string, error) {
+	return fn(ctx, uri)
+}
+
+var secretResolvers = map[string]SecretResolver{
+	"vault": SecretResolverFunc(resolveVaultSecret),
+	"awssm": SecretResolverFunc(resolveAWSSecret),
+	"gcpsm": SecretResolverFunc(resolveGCPSecret),
+}
+
+func RegisterSecretResolver(scheme string, resolver SecretResolver) error {
+	if _, has := secretResolvers[scheme]; has {
+		return fmt.Errorf("%s secret resolver already exists", scheme)
+	}
+	secretResolvers[scheme] = resolver
+	return nil
+}
+
+// ResolveSecrets replaces every string in a JSON config that is a secret URI
+// with the secret it refers to. Configs that aren't JSON objects are returned
+// unchanged.
+func ResolveSecrets(config SerializedConfig) (SerializedConfig, error) {
+	if !bytes.Contains(config, []byte("://")) {
+		return config, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(config, &fields); err != nil {
+		return config, nil
+	}
+	resolved := false
+	for key, value := range fields {
+		str, ok := value.(string)
+		if !ok {
+			continue
+		}
+		secret, isSecret, err := resolveSecret(context.Background(), str)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve secret for %s: %w", key, err)
+		}
+		if isSecret {
+			fields[key] = secret
+			resolved = true
+		}
+	}
+	if !resolved {
+		return config, nil
+	}
+	return json.Marshal(fields)
+}
+
+func resolveSecret(ctx context.Context, value string) (string, bool, error) {
+	uri, err := url.Parse(value)
+	if err != nil {
+		return "", false, nil
+	}
+	resolver, has := secretResolvers[uri.Scheme]
+	if !has {
+		return "", false, nil
+	}
+	secret, err := resolver.Resolve(ctx, uri)
+	if err != nil {
+		return "", true, err
+	}
+	if uri.Fragment == "" {
+		return secret, true, nil
+	}
+	secret, err = secretKey(secret, uri.Fragment)
+	return secret, true, err
+}
+
+func secretKey(secret, key string) (string, error) {
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &values); err != nil {
+		return "", fmt.Errorf("secret is not a JSON object, cannot select key %s", key)
+	}
+	value, has := values[key]
+	if !has {
+		return "", fmt.Errorf("secret has no key %s", key)
+	}
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// resolveVaultSecret reads vault://<mount>/<path>#<key> from the Vault server
+// at VAULT_ADDR using VAULT_TOKEN. KV version 2 responses are unwrapped.
+func resolveVaultSecret(ctx context.Context, uri *url.URL) (string, error) {
+	addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+	if addr == "" {
+		return "", fmt.Errorf("VAULT_ADDR not set")
+	}
+	path := strings.TrimPrefix(uri.Host+uri.Path, "/")
+	body, err := cloudRequest(ctx, http.MethodGet, strings.TrimSuffix(addr, "/")+"/v1/"+path, map[string]string{
+		"X-Vault-Token": token,
+	})
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("invalid vault response: %w", err)
+	}
+	data := resp.Data
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		data = nested
+	}
+	serialized, err := json.Marshal(data)
+	return string(serialized), err
+}
+
+var awsSecretsEndpoint = func(region string) string {
+	return fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+}
+
+// resolveAWSSecret reads awssm://<region>/<secret id>#<key> from AWS Secrets
+// Manager, authenticating the same way as AWS IAM provider auth.
+func resolveAWSSecret(ctx context.Context, uri *url.URL) (string, error) {
+	region, secretID := uri.Host, strings.TrimPrefix(uri.Path, "/")
+	payload, err := json.Marshal(map[string]string{"SecretId": secretID})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, awsSecretsEndpoint(region), bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	creds, err := awsCredentials.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("could not retrieve aws credentials: %w", err)
+	}
+	hash := sha256.Sum256(payload)
+	if err := v4.NewSigner().SignHTTP(ctx, creds, req, hex.EncodeToString(hash[:]), "secretsmanager", region, time.Now().UTC()); err != nil {
+		return "", err
+	}
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("could not get secret %s: %s", secretID, resp.Status)
+	}
+	var secret struct {
+		SecretString string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("invalid secrets manager response: %w", err)
+	}
+	return secret.SecretString, nil
+}
+
+var gcpSecretsEndpoint = "https://secretmanager.googleapis.com"
+
+// resolveGCPSecret reads gcpsm://<project>/<secret>[/<version>]#<key> from
+// GCP Secret Manager using the workload's service account.
+func resolveGCPSecret(ctx context.Context, uri *url.URL) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(uri.Path, "/"), "/")
+	if uri.Host == "" || len(parts) == 0 || parts[0] == "" || len(parts) > 2 {
+		return "", fmt.Errorf("invalid secret uri %s, expected gcpsm://<project>/<secret>[/<version>]", uri.String())
+	}
+	version := "latest"
+	if len(parts) == 2 {
+		version = parts[1]
+	}
+	token, err := gcpAccessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	path := fmt.Sprintf("%s/v1/projects/%s/secrets/%s/versions/%s:access", gcpSecretsEndpoint, uri.Host, parts[0], version)
+	body, err := cloudRequest(ctx, http.MethodGet, path, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("invalid secret manager response: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	return string(data), err
+}
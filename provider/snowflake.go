@@ -1,10 +1,13 @@
 package provider
 
 import (
+	"database/sql/driver"
 	"encoding/json"
 	"errors"
 	"fmt"
-	_ "github.com/snowflakedb/gosnowflake"
+	"net/http"
+
+	"github.com/snowflakedb/gosnowflake"
 )
 
 // sqlColumnType is used to specify the column type of a resource value.
@@ -17,6 +20,8 @@ const (
 	sfString                        = "varchar"
 	sfBool                          = "BOOLEAN"
 	sfTimestamp                     = "TIMESTAMP_NTZ"
+	sfBinary                        = "BINARY"
+	sfDecimal                       = "DECIMAL"
 )
 
 type SnowflakeConfig struct {
@@ -25,6 +30,7 @@ type SnowflakeConfig struct {
 	Organization string
 	Account      string
 	Database     string
+	TLSConfig
 }
 
 func (sf *SnowflakeConfig) Deserialize(config SerializedConfig) error {
@@ -61,6 +67,13 @@ func snowflakeOfflineStoreFactory(config SerializedConfig) (Provider, error) {
 		ProviderType:  SnowflakeOffline,
 		QueryImpl:     &queries,
 	}
+	if sc.hasOptions() {
+		connector, err := sc.tlsConnector(sgConfig.ConnectionURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid snowflake tls config: %w", err)
+		}
+		sgConfig.Connector = connector
+	}
 
 	store, err := NewSQLOfflineStore(sgConfig)
 	if err != nil {
@@ -69,6 +82,33 @@ func snowflakeOfflineStoreFactory(config SerializedConfig) (Provider, error) {
 	return store, nil
 }
 
+// tlsConnector connects with the config's TLS options rather than the
+// driver's default transport. Snowflake is only reached over TLS, so it
+// can't be disabled, and certificates are fully verified unless SSLMode says
+// otherwise.
+func (sf *SnowflakeConfig) tlsConnector(dsn string) (driver.Connector, error) {
+	if sf.SSLMode == SSLDisable {
+		return nil, fmt.Errorf("snowflake connections can't disable tls")
+	}
+	tlsOptions := sf.TLSConfig
+	if tlsOptions.SSLMode == "" {
+		tlsOptions.SSLMode = SSLVerifyFull
+	}
+	cfg, err := gosnowflake.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := tlsOptions.tlsConfig(cfg.Host)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Transporter = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+	}
+	return gosnowflake.NewConnector(gosnowflake.SnowflakeDriver{}, *cfg), nil
+}
+
 func (q snowflakeSQLQueries) materializationDrop(tableName string) string {
 	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
 }
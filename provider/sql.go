@@ -7,18 +7,21 @@ package provider
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
 
-	db "github.com/jackc/pgx/v4"
 	sf "github.com/snowflakedb/gosnowflake"
 )
 
+// sanitize quotes the name of a table or column that Featureform created.
+// Names that users give are quoted by their store's Dialect.
 func sanitize(ident string) string {
-	return db.Identifier{ident}.Sanitize()
+	return quoteIdentifier(ident, '"')
 }
 
 type SQLOfflineStoreConfig struct {
@@ -27,6 +30,9 @@ type SQLOfflineStoreConfig struct {
 	Driver        string
 	ProviderType  Type
 	QueryImpl     OfflineTableQueries
+	// Connector is used instead of ConnectionURL when set, for connections
+	// that need fresh credentials each time they are opened.
+	Connector driver.Connector
 }
 
 type OfflineTableQueries interface {
@@ -34,30 +40,40 @@ type OfflineTableQueries interface {
 	tableExists() string
 	viewExists() string
 	resourceExists(tableName string) string
-	registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error
+	// primaryTableRegister is given a source table name that's already been
+	// quoted.
 	primaryTableRegister(tableName string, sourceName string) string
-	primaryTableCreate(name string, columnString string) string
 	getColumns(db *sql.DB, tableName string) ([]TableColumn, error)
 	getValueColumnTypes(tableName string) string
-	determineColumnType(valueType ValueType) (string, error)
-	materializationCreate(tableName string, sourceName string) string
+	dialect() Dialect
+	// materializationCreate creates tableName from a query built by
+	// dialect().latestValues.
+	materializationCreate(tableName string, query string) string
 	materializationUpdate(db *sql.DB, tableName string, sourceName string) error
 	materializationExists() string
+	tablesLike() string
+	trainingSetCacheCreate(cacheName string, tableName string) string
+	trainingSetCacheQueries() trainingSetCacheQueries
 	materializationDrop(tableName string) string
+	materializationSwap(db *sql.DB, stagingName string, tableName string) error
+	// stagingDrop drops a staging table that materializationCreate made, if
+	// it exists.
+	stagingDrop(stagingName string) string
 	getTable() string
 	dropTable(tableName string) string
+	dropView(tableName string) string
 	materializationIterateSegment(tableName string) string
-	newSQLOfflineTable(name string, columnType string) string
+	iterateRows(db *sql.DB, query string, args ...interface{}) (sqlRows, error)
 	writeUpdate(table string) string
 	writeInserts(table string) string
 	writeExists(table string) string
 	createValuePlaceholderString(columns []TableColumn) string
-	trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error
-	trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error
+	atomicUpdate(db *sql.DB, tableName string, tempName string, query string) error
 	trainingRowSelect(columns string, trainingSetName string) string
 	castTableItemType(v interface{}, t interface{}) interface{}
 	getValueColumnType(t *sql.ColumnType) interface{}
 	numRows(n interface{}) (int64, error)
+	tableSize(db *sql.DB, tableName string) (int64, error)
 	transformationCreate(name string, query string) string
 	transformationUpdate(db *sql.DB, tableName string, query string) error
 	transformationExists() string
@@ -73,10 +89,15 @@ type sqlOfflineStore struct {
 // NewPostgresOfflineStore creates a connection to a postgres database
 // and initializes a table to track currently active Resource tables.
 func NewSQLOfflineStore(config SQLOfflineStoreConfig) (*sqlOfflineStore, error) {
-	url := config.ConnectionURL
-	db, err := sql.Open(config.Driver, url)
-	if err != nil {
-		return nil, err
+	var db *sql.DB
+	if config.Connector != nil {
+		db = sql.OpenDB(config.Connector)
+	} else {
+		var err error
+		db, err = sql.Open(config.Driver, config.ConnectionURL)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return &sqlOfflineStore{
@@ -90,6 +111,14 @@ func NewSQLOfflineStore(config SQLOfflineStoreConfig) (*sqlOfflineStore, error)
 	}, nil
 }
 
+func (store *sqlOfflineStore) CheckHealth(ctx context.Context) error {
+	return store.db.PingContext(ctx)
+}
+
+func (store *sqlOfflineStore) Close() error {
+	return store.db.Close()
+}
+
 func checkName(id ResourceID) error {
 	if strings.Contains(id.Name, "__") || strings.Contains(id.Variant, "__") {
 		return fmt.Errorf("names cannot contain double underscores '__': %s", id.Name)
@@ -136,19 +165,22 @@ func GetPrimaryTableName(id ResourceID) (string, error) {
 	return fmt.Sprintf("featureform_primary_%s__%s", id.Name, id.Variant), nil
 }
 
-func (store *sqlOfflineStore) tableExists(id ResourceID) (bool, error) {
-	n := -1
-	var tableName string
-	var err error
+func (store *sqlOfflineStore) getTableName(id ResourceID) (string, error) {
 	if id.check(Feature, Label) == nil {
-		tableName, err = store.getResourceTableName(id)
+		return store.getResourceTableName(id)
 	} else if id.check(TrainingSet) == nil {
-		tableName, err = store.getTrainingSetName(id)
+		return store.getTrainingSetName(id)
 	} else if id.check(Primary) == nil {
-		tableName, err = GetPrimaryTableName(id)
+		return GetPrimaryTableName(id)
 	} else if id.check(Transformation) == nil {
-		tableName, err = GetTransformationName(id)
+		return GetTransformationName(id)
 	}
+	return "", fmt.Errorf("unknown resource type: %v", id.Type)
+}
+
+func (store *sqlOfflineStore) tableExists(id ResourceID) (bool, error) {
+	n := -1
+	tableName, err := store.getTableName(id)
 	if err != nil {
 		return false, err
 	}
@@ -169,6 +201,41 @@ func (store *sqlOfflineStore) tableExists(id ResourceID) (bool, error) {
 	return false, nil
 }
 
+// DeleteTable drops a resource's table, or its view if it was registered
+// from a source table or as a view of a transformation.
+func (store *sqlOfflineStore) DeleteTable(id ResourceID) error {
+	tableName, err := store.getTableName(id)
+	if err != nil {
+		return err
+	}
+	if exists, err := store.tableExists(id); err != nil {
+		return err
+	} else if !exists {
+		return &TableNotFound{id.Name, id.Variant}
+	}
+	if err := store.untrackTable(tableName); err != nil {
+		return fmt.Errorf("untrack %s: %w", tableName, err)
+	}
+	if id.Type == TrainingSet {
+		if err := store.forgetTrainingSetCache(tableName); err != nil {
+			return fmt.Errorf("forget cache use of %s: %w", tableName, err)
+		}
+	}
+	// Some stores can't tell tables and views apart by name, so a table is
+	// tried first.
+	if _, err := store.db.Exec(store.query.dropTable(tableName)); err == nil {
+		return nil
+	}
+	if _, err := store.db.Exec(store.query.dropView(tableName)); err == nil {
+		return nil
+	}
+	dropMaterialized := fmt.Sprintf("DROP MATERIALIZED VIEW %s", store.query.dialect().quote(tableName))
+	if _, err := store.db.Exec(dropMaterialized); err != nil {
+		return fmt.Errorf("drop %s: %w", tableName, err)
+	}
+	return nil
+}
+
 func (store *sqlOfflineStore) AsOfflineStore() (OfflineStore, error) {
 	return store, nil
 }
@@ -185,27 +252,55 @@ func (store *sqlOfflineStore) RegisterResourceFromSourceTable(id ResourceID, sch
 	if schema.Entity == "" || schema.Value == "" {
 		return nil, fmt.Errorf("non-empty entity and value columns required")
 	}
+	if schema.ValidTo != "" && schema.TS == "" {
+		return nil, fmt.Errorf("slowly changing dimension tables need a valid from column")
+	}
 	tableName, err := store.getResourceTableName(id)
 	if err != nil {
 		return nil, fmt.Errorf("get name: %w", err)
 	}
-	if schema.TS == "" {
-		if err := store.query.registerResources(store.db, tableName, schema, false); err != nil {
-			return nil, fmt.Errorf("register no ts: %w", err)
-		}
-	} else {
-		if err := store.query.registerResources(store.db, tableName, schema, true); err != nil {
-			return nil, fmt.Errorf("register ts: %w", err)
-		}
+	quoted, err := store.quoteSourceSchema(schema)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.db.Exec(resourceViewQuery(store.query.dialect(), tableName, quoted)); err != nil {
+		return nil, fmt.Errorf("register resource: %w", err)
+	}
+	if err := store.setTableVersion(tableName, schema.SourceTable); err != nil {
+		return nil, fmt.Errorf("track %s: %w", tableName, err)
 	}
 
 	return &sqlOfflineTable{
 		db:    store.db,
 		name:  tableName,
 		query: store.query,
+		store: store,
 	}, nil
 }
 
+// quoteSourceSchema resolves a user's source table and columns to the names
+// they have in the warehouse, so that they can be registered in any case, and
+// quotes them so that reserved words can be used.
+func (store *sqlOfflineStore) quoteSourceSchema(schema ResourceSchema) (ResourceSchema, error) {
+	d := store.query.dialect()
+	source, columns, err := resolveSourceTable(store.db, d, schema.SourceTable)
+	if err != nil {
+		return ResourceSchema{}, err
+	}
+	quoted := ResourceSchema{
+		Entity:      d.quote(resolveIdentifier(columns, schema.Entity)),
+		Value:       d.quote(resolveIdentifier(columns, schema.Value)),
+		SourceTable: source,
+	}
+	if schema.TS != "" {
+		quoted.TS = d.quote(resolveIdentifier(columns, schema.TS))
+	}
+	if schema.ValidTo != "" {
+		quoted.ValidTo = d.quote(resolveIdentifier(columns, schema.ValidTo))
+	}
+	return quoted, nil
+}
+
 func (store *sqlOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, sourceName string) (PrimaryTable, error) {
 	if err := id.check(Primary); err != nil {
 		return nil, fmt.Errorf("check fail: %w", err)
@@ -219,7 +314,11 @@ func (store *sqlOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, sour
 	if err != nil {
 		return nil, fmt.Errorf("get name: %w", err)
 	}
-	query := store.query.primaryTableRegister(tableName, sourceName)
+	source, _, err := resolveSourceTable(store.db, store.query.dialect(), sourceName)
+	if err != nil {
+		return nil, err
+	}
+	query := store.query.primaryTableRegister(tableName, source)
 	if _, err := store.db.Exec(query); err != nil {
 		return nil, fmt.Errorf("register table: %w", err)
 	}
@@ -231,6 +330,7 @@ func (store *sqlOfflineStore) RegisterPrimaryFromSourceTable(id ResourceID, sour
 		name:   tableName,
 		schema: TableSchema{Columns: columnNames},
 		query:  store.query,
+		store:  store,
 	}, nil
 }
 
@@ -254,6 +354,9 @@ func (store *sqlOfflineStore) CreatePrimaryTable(id ResourceID, schema TableSche
 	if err != nil {
 		return nil, err
 	}
+	if err := store.setTableVersion(tableName, ""); err != nil {
+		return nil, fmt.Errorf("track %s: %w", tableName, err)
+	}
 	return table, nil
 }
 
@@ -271,6 +374,7 @@ func (store *sqlOfflineStore) newsqlPrimaryTable(db *sql.DB, name string, schema
 		name:   name,
 		schema: schema,
 		query:  store.query,
+		store:  store,
 	}, nil
 }
 
@@ -278,16 +382,7 @@ func (store *sqlOfflineStore) newsqlPrimaryTable(db *sql.DB, name string, schema
 // specified TableSchema. Returns the query if successful. Returns an error
 // if there is an invalid column type.
 func (store *sqlOfflineStore) createsqlPrimaryTableQuery(name string, schema TableSchema) (string, error) {
-	columns := make([]string, 0)
-	for _, column := range schema.Columns {
-		columnType, err := store.query.determineColumnType(column.ValueType)
-		if err != nil {
-			return "", err
-		}
-		columns = append(columns, fmt.Sprintf("%s %s", column.Name, columnType))
-	}
-	columnString := strings.Join(columns, ", ")
-	return store.query.primaryTableCreate(name, columnString), nil
+	return primaryTableQuery(store.query.dialect(), name, schema)
 }
 
 func (store *sqlOfflineStore) GetPrimaryTable(id ResourceID) (PrimaryTable, error) {
@@ -307,6 +402,7 @@ func (store *sqlOfflineStore) GetPrimaryTable(id ResourceID) (PrimaryTable, erro
 		name:   name,
 		schema: TableSchema{Columns: columnNames},
 		query:  store.query,
+		store:  store,
 	}, nil
 }
 
@@ -331,6 +427,7 @@ func (store *sqlOfflineStore) GetTransformationTable(id ResourceID) (Transformat
 		name:   name,
 		schema: TableSchema{Columns: columnNames},
 		query:  store.query,
+		store:  store,
 	}, nil
 }
 
@@ -362,6 +459,9 @@ func (store *sqlOfflineStore) CreateResourceTable(id ResourceID, schema TableSch
 	if err != nil {
 		return nil, err
 	}
+	if err := store.setTableVersion(tableName, ""); err != nil {
+		return nil, fmt.Errorf("track %s: %w", tableName, err)
+	}
 	return table, nil
 }
 
@@ -413,10 +513,23 @@ func (mat *sqlMaterialization) NumRows() (int64, error) {
 
 }
 
+func (mat *sqlMaterialization) MaxTimestamp() (time.Time, error) {
+	var ts sql.NullTime
+	query := fmt.Sprintf("SELECT MAX(ts) FROM %s", sanitize(mat.tableName))
+	if err := mat.db.QueryRow(query).Scan(&ts); err != nil {
+		return time.Time{}, err
+	}
+	return ts.Time, nil
+}
+
+func (mat *sqlMaterialization) Stats() (TableStats, error) {
+	return sqlTableStats(mat.db, mat.query, mat.tableName, []string{"entity", "value", "ts"}, "ts")
+}
+
 func (mat *sqlMaterialization) IterateSegment(start, end int64) (FeatureIterator, error) {
 	query := mat.query.materializationIterateSegment(mat.tableName)
 
-	rows, err := mat.db.Query(query, start, end)
+	rows, err := mat.query.iterateRows(mat.db, query, start, end)
 	if err != nil {
 		return nil, err
 	}
@@ -431,15 +544,26 @@ func (mat *sqlMaterialization) IterateSegment(start, end int64) (FeatureIterator
 	return newsqlFeatureIterator(rows, colType, mat.query), nil
 }
 
+// sqlRows is the part of *sql.Rows that iterators use, so that stores can
+// stream large results in other ways.
+type sqlRows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Columns() ([]string, error)
+	ColumnTypes() ([]*sql.ColumnType, error)
+	Err() error
+	Close() error
+}
+
 type sqlFeatureIterator struct {
-	rows         *sql.Rows
+	rows         sqlRows
 	err          error
 	currentValue ResourceRecord
 	columnType   interface{}
 	query        OfflineTableQueries
 }
 
-func newsqlFeatureIterator(rows *sql.Rows, columnType interface{}, query OfflineTableQueries) FeatureIterator {
+func newsqlFeatureIterator(rows sqlRows, columnType interface{}, query OfflineTableQueries) FeatureIterator {
 	return &sqlFeatureIterator{
 		rows:         rows,
 		err:          nil,
@@ -451,6 +575,7 @@ func newsqlFeatureIterator(rows *sql.Rows, columnType interface{}, query Offline
 
 func (iter *sqlFeatureIterator) Next() bool {
 	if !iter.rows.Next() {
+		iter.err = iter.rows.Err()
 		iter.rows.Close()
 		return false
 	}
@@ -473,32 +598,88 @@ func (iter *sqlFeatureIterator) Value() ResourceRecord {
 }
 
 func (iter *sqlFeatureIterator) Err() error {
-	return nil
+	return iter.err
 }
 
 func (store *sqlOfflineStore) CreateMaterialization(id ResourceID) (Materialization, error) {
-	if id.Type != Feature {
-		return nil, errors.New("only features can be materialized")
+	if err := checkMaterializable(id); err != nil {
+		return nil, err
 	}
 	resTable, err := store.getsqlResourceTable(id)
 	if err != nil {
 		return nil, err
 	}
+	matID := ResourceMaterializationID(id)
+	return store.createMaterialization(matID, func(tableName string) string {
+		return store.query.materializationCreate(tableName, store.query.dialect().latestValues(resTable.name, time.Time{}, time.Time{}))
+	})
+}
 
-	matID := MaterializationID(id.Name)
-	matTableName := store.getMaterializationTableName(matID)
-	materializeQry := store.query.materializationCreate(matTableName, resTable.name)
+// CreateIncrementalMaterialization builds a separate materialization for each
+// watermark, so a full materialization of the same feature is left untouched.
+func (store *sqlOfflineStore) CreateIncrementalMaterialization(id ResourceID, since time.Time) (Materialization, error) {
+	if err := checkMaterializable(id); err != nil {
+		return nil, err
+	}
+	resTable, err := store.getsqlResourceTable(id)
+	if err != nil {
+		return nil, err
+	}
+	matID := MaterializationID(fmt.Sprintf("%s_since_%d", ResourceMaterializationID(id), since.Unix()))
+	return store.createMaterialization(matID, func(tableName string) string {
+		return store.query.materializationCreate(tableName, store.query.dialect().latestValues(resTable.name, since, time.Time{}))
+	})
+}
 
-	_, err = store.db.Exec(materializeQry)
+// CreateWindowedMaterialization is named by both ends of its window, so each
+// backfilled run of a feature gets its own materialization.
+func (store *sqlOfflineStore) CreateWindowedMaterialization(id ResourceID, since, until time.Time) (Materialization, error) {
+	if err := checkMaterializable(id); err != nil {
+		return nil, err
+	}
+	resTable, err := store.getsqlResourceTable(id)
 	if err != nil {
 		return nil, err
 	}
-	return &sqlMaterialization{
+	matID := MaterializationID(fmt.Sprintf("%s_window_%d_%d", ResourceMaterializationID(id), since.Unix(), until.Unix()))
+	return store.createMaterialization(matID, func(tableName string) string {
+		return store.query.materializationCreate(tableName, store.query.dialect().latestValues(resTable.name, since, until))
+	})
+}
+
+func (store *sqlOfflineStore) createMaterialization(matID MaterializationID, createQuery func(tableName string) string) (Materialization, error) {
+	matTableName := store.getMaterializationTableName(matID)
+	mat := &sqlMaterialization{
 		id:        matID,
 		db:        store.db,
 		tableName: matTableName,
 		query:     store.query,
-	}, nil
+	}
+	if exists, err := store.materializationExists(matID); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, &MaterializationExists{matID}
+	}
+	// The materialization is built in a staging table and renamed into place
+	// so that readers never see it partially written.
+	stagingName := fmt.Sprintf("staging_%s", matTableName)
+	if exists, err := store.materializationTableExists(stagingName); err != nil {
+		return nil, err
+	} else if exists {
+		if _, err := store.db.Exec(store.query.stagingDrop(stagingName)); err != nil {
+			return nil, fmt.Errorf("drop stale staging table: %w", err)
+		}
+	}
+	if _, err := store.db.Exec(createQuery(stagingName)); err != nil {
+		return nil, err
+	}
+	if err := store.query.materializationSwap(store.db, stagingName, matTableName); err != nil {
+		if _, dropErr := store.db.Exec(store.query.stagingDrop(stagingName)); dropErr != nil {
+			return nil, fmt.Errorf("swap materialization: %w (drop staging table: %v)", err, dropErr)
+		}
+		return nil, fmt.Errorf("swap materialization: %w", err)
+	}
+	return mat, nil
 }
 
 func (store *sqlOfflineStore) GetMaterialization(id MaterializationID) (Materialization, error) {
@@ -529,7 +710,7 @@ func (store *sqlOfflineStore) GetMaterialization(id MaterializationID) (Material
 }
 
 func (store *sqlOfflineStore) UpdateMaterialization(id ResourceID) (Materialization, error) {
-	matID := MaterializationID(id.Name)
+	matID := ResourceMaterializationID(id)
 	tableName := store.getMaterializationTableName(matID)
 	getMatQry := store.query.materializationExists()
 	resTable, err := store.getsqlResourceTable(id)
@@ -572,8 +753,39 @@ func (store *sqlOfflineStore) DeleteMaterialization(id MaterializationID) error
 	return nil
 }
 
+// MaterializationGenerations lists the tables that updates have replaced.
+// Stores that refresh materializations in place, like Postgres, have none.
+func (store *sqlOfflineStore) MaterializationGenerations(id ResourceID) ([]MaterializationGeneration, error) {
+	prefix := store.getMaterializationTableName(ResourceMaterializationID(id)) + generationSuffix
+	rows, err := store.db.Query(store.query.tablesLike(), likePrefix(prefix))
+	if err != nil {
+		return nil, fmt.Errorf("list generations: %w", err)
+	}
+	defer rows.Close()
+	gens := make([]MaterializationGeneration, 0)
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			return nil, err
+		}
+		nanos, err := strconv.ParseInt(tableName[len(prefix):], 10, 64)
+		if err != nil {
+			// Another feature's generation that shares the prefix.
+			continue
+		}
+		gens = append(gens, MaterializationGeneration{
+			ID:      MaterializationID(fmt.Sprintf("%s%s%d", ResourceMaterializationID(id), generationSuffix, nanos)),
+			Created: time.Unix(0, nanos).UTC(),
+		})
+	}
+	return gens, rows.Err()
+}
+
 func (store *sqlOfflineStore) materializationExists(id MaterializationID) (bool, error) {
-	tableName := store.getMaterializationTableName(id)
+	return store.materializationTableExists(store.getMaterializationTableName(id))
+}
+
+func (store *sqlOfflineStore) materializationTableExists(tableName string) (bool, error) {
 	getMatQry := store.query.materializationExists()
 	rows, err := store.db.Query(getMatQry, tableName)
 	defer rows.Close()
@@ -602,7 +814,7 @@ func (store *sqlOfflineStore) CreateTrainingSet(def TrainingSetDef) error {
 	if err != nil {
 		return err
 	}
-	if err := store.query.trainingSetCreate(store, def, tableName, label.name); err != nil {
+	if err := store.trainingSetQuery(def, tableName, label.name, false); err != nil {
 		return err
 	}
 
@@ -621,13 +833,76 @@ func (store *sqlOfflineStore) UpdateTrainingSet(def TrainingSetDef) error {
 	if err != nil {
 		return err
 	}
-	if err := store.query.trainingSetUpdate(store, def, tableName, label.name); err != nil {
+	if err := store.trainingSetQuery(def, tableName, label.name, true); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+func (store *sqlOfflineStore) trainingSetQuery(def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
+	features := make([]asOfFeature, len(def.Features))
+	for i, feature := range def.Features {
+		columnName, err := store.getResourceTableName(feature)
+		if err != nil {
+			return err
+		}
+		sourceName, err := store.trainingSetFeatureTable(def, feature)
+		if err != nil {
+			return err
+		}
+		features[i] = asOfFeature{column: columnName, table: sourceName}
+	}
+	query := store.query.dialect().asOfJoin(labelName, features)
+	if !isUpdate {
+		_, err := store.db.Exec(fmt.Sprintf("CREATE TABLE %s AS (%s)", sanitize(tableName), query))
+		return err
+	}
+	tempName := sanitize(fmt.Sprintf("tmp_%s", tableName))
+	return store.query.atomicUpdate(store.db, tableName, tempName, fmt.Sprintf("CREATE TABLE %s AS (%s)", tempName, query))
+}
+
+// TrainingSetFreshness reads the latest timestamps from the label and
+// feature tables of def, rather than the training set, which doesn't keep
+// them. Only the feature values that the as of join would pick for some
+// label are counted.
+func (store *sqlOfflineStore) TrainingSetFreshness(def TrainingSetDef) (TrainingSetFreshness, error) {
+	if err := def.check(); err != nil {
+		return nil, err
+	}
+	label, err := store.getsqlResourceTable(def.Label)
+	if err != nil {
+		return nil, err
+	}
+	freshness := make(TrainingSetFreshness)
+	labelQuery := fmt.Sprintf("SELECT MAX(ts) FROM %s", sanitize(label.name))
+	if err := store.latestTimestamp(freshness, def.Label, labelQuery); err != nil {
+		return nil, err
+	}
+	for _, feature := range def.Features {
+		tableName, err := store.getResourceTableName(feature)
+		if err != nil {
+			return nil, err
+		}
+		query := fmt.Sprintf("SELECT MAX(f.ts) FROM %s AS f JOIN %s AS l ON f.entity = l.entity AND f.ts <= l.ts", sanitize(tableName), sanitize(label.name))
+		if err := store.latestTimestamp(freshness, feature, query); err != nil {
+			return nil, err
+		}
+	}
+	return freshness, nil
+}
+
+func (store *sqlOfflineStore) latestTimestamp(freshness TrainingSetFreshness, id ResourceID, query string) error {
+	var latest sql.NullTime
+	if err := store.db.QueryRow(query).Scan(&latest); err != nil {
+		return fmt.Errorf("get latest timestamp of %s (%s): %w", id.Name, id.Variant, err)
+	}
+	if latest.Valid {
+		freshness[id] = latest.Time.UTC()
+	}
+	return nil
+}
+
 func (store *sqlOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator, error) {
 	fmt.Printf("Getting Training Set: %v\n", id)
 	if err := id.check(TrainingSet); err != nil {
@@ -665,6 +940,62 @@ func (store *sqlOfflineStore) GetTrainingSet(id ResourceID) (TrainingSetIterator
 	return store.newsqlTrainingSetIterator(rows, colTypes), nil
 }
 
+// GetTrainingSetFeatures only selects the columns of the given features, so
+// the rest of the training set isn't read.
+func (store *sqlOfflineStore) GetTrainingSetFeatures(id ResourceID, features []ResourceID) (TrainingSetIterator, error) {
+	if err := id.check(TrainingSet); err != nil {
+		return nil, err
+	}
+	if exists, err := store.tableExists(id); err != nil {
+		return nil, err
+	} else if !exists {
+		return nil, &TrainingSetNotFound{id}
+	}
+	trainingSetName, err := store.getTrainingSetName(id)
+	if err != nil {
+		return nil, err
+	}
+	columnNames, err := store.query.getColumns(store.db, trainingSetName)
+	if err != nil {
+		return nil, err
+	}
+	allTypes, err := store.getValueColumnTypes(trainingSetName)
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[string]int, len(columnNames))
+	for i, col := range columnNames {
+		positions[col.Name] = i
+	}
+	selected := make([]string, 0, len(features)+1)
+	colTypes := make([]interface{}, 0, len(features)+1)
+	for _, feature := range features {
+		feature.Type = Feature
+		name, err := store.getResourceTableName(feature)
+		if err != nil {
+			return nil, err
+		}
+		i, has := positions[name]
+		if !has {
+			return nil, fmt.Errorf("feature %s (%s) is not in training set %s (%s)", feature.Name, feature.Variant, id.Name, id.Variant)
+		}
+		selected = append(selected, sanitize(name))
+		if i < len(allTypes) {
+			colTypes = append(colTypes, allTypes[i])
+		}
+	}
+	// The label is always the last column.
+	if len(allTypes) > 0 {
+		colTypes = append(colTypes, allTypes[len(allTypes)-1])
+	}
+	selected = append(selected, "label")
+	rows, err := store.db.Query(store.query.trainingRowSelect(strings.Join(selected, ", "), trainingSetName))
+	if err != nil {
+		return nil, err
+	}
+	return store.newsqlTrainingSetIterator(rows, colTypes), nil
+}
+
 // getValueColumnTypes returns a list of column types. Columns consist of feature and label values
 // within a training set.
 func (store *sqlOfflineStore) getValueColumnTypes(table string) ([]interface{}, error) {
@@ -782,6 +1113,7 @@ func (store *sqlOfflineStore) getsqlResourceTable(id ResourceID) (*sqlOfflineTab
 		db:    store.db,
 		name:  table,
 		query: store.query,
+		store: store,
 	}, nil
 }
 
@@ -789,6 +1121,8 @@ type sqlOfflineTable struct {
 	db    *sql.DB
 	query OfflineTableQueries
 	name  string
+	// store, if it's set, is told when the table's rows change.
+	store *sqlOfflineStore
 }
 
 type sqlPrimaryTable struct {
@@ -796,6 +1130,8 @@ type sqlPrimaryTable struct {
 	name   string
 	query  OfflineTableQueries
 	schema TableSchema
+	// store, if it's set, is told when the table's rows change.
+	store *sqlOfflineStore
 }
 
 func (table *sqlPrimaryTable) GetName() string {
@@ -812,6 +1148,9 @@ func (table *sqlPrimaryTable) Write(rec GenericRecord) error {
 	if _, err := table.db.Exec(upsertQuery, rec...); err != nil {
 		return err
 	}
+	if table.store != nil {
+		return table.store.bumpTableVersion(table.name)
+	}
 	return nil
 }
 
@@ -831,7 +1170,7 @@ func (pt *sqlPrimaryTable) IterateSegment(n int64) (GenericTableIterator, error)
 	}
 	names := strings.Join(columnNames[:], ", ")
 	query := fmt.Sprintf("SELECT %s FROM %s LIMIT %d", names, sanitize(pt.name), n)
-	rows, err := pt.db.Query(query)
+	rows, err := pt.query.iterateRows(pt.db, query)
 	if err != nil {
 		return nil, err
 	}
@@ -842,6 +1181,77 @@ func (pt *sqlPrimaryTable) IterateSegment(n int64) (GenericTableIterator, error)
 	return newsqlGenericTableIterator(rows, colTypes, columnNames, pt.query), nil
 }
 
+// Stats reads the timestamp range from the first timestamp column, if the
+// table has one.
+func (pt *sqlPrimaryTable) Stats() (TableStats, error) {
+	columns, err := pt.query.getColumns(pt.db, pt.name)
+	if err != nil {
+		return TableStats{}, err
+	}
+	names := make([]string, len(columns))
+	for i, col := range columns {
+		names[i] = col.Name
+	}
+	schema, err := pt.Schema()
+	if err != nil {
+		return TableStats{}, err
+	}
+	tsColumn := ""
+	for _, col := range schema.Columns {
+		if col.ValueType == Timestamp {
+			tsColumn = col.Name
+			break
+		}
+	}
+	return sqlTableStats(pt.db, pt.query, pt.name, names, tsColumn)
+}
+
+// sqlTableStats gets the row and null counts, and the range of tsColumn if
+// it's set, in a single scan of the table.
+func sqlTableStats(db *sql.DB, query OfflineTableQueries, tableName string, columns []string, tsColumn string) (TableStats, error) {
+	selects := []string{"COUNT(*)"}
+	for _, col := range columns {
+		selects = append(selects, fmt.Sprintf("COUNT(*) - COUNT(%s)", sanitize(col)))
+	}
+	counts := make([]interface{}, len(selects))
+	dest := make([]interface{}, len(selects))
+	for i := range counts {
+		dest[i] = &counts[i]
+	}
+	var minTS, maxTS sql.NullTime
+	if tsColumn != "" {
+		selects = append(selects, fmt.Sprintf("MIN(%s)", sanitize(tsColumn)), fmt.Sprintf("MAX(%s)", sanitize(tsColumn)))
+		dest = append(dest, &minTS, &maxTS)
+	}
+	qry := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), sanitize(tableName))
+	if err := db.QueryRow(qry).Scan(dest...); err != nil {
+		return TableStats{}, fmt.Errorf("query stats of %s: %w", tableName, err)
+	}
+	n, err := query.numRows(counts[0])
+	if err != nil {
+		return TableStats{}, err
+	}
+	stats := TableStats{
+		NumRows:    n,
+		MinTS:      minTS.Time,
+		MaxTS:      maxTS.Time,
+		NullCounts: make(map[string]int64, len(columns)),
+	}
+	for i, col := range columns {
+		if stats.NullCounts[col], err = query.numRows(counts[i+1]); err != nil {
+			return TableStats{}, err
+		}
+	}
+	size, err := query.tableSize(db, tableName)
+	if errors.Is(err, sql.ErrNoRows) {
+		size = -1
+	} else if err != nil {
+		return TableStats{}, fmt.Errorf("get size of %s: %w", tableName, err)
+	}
+	stats.SizeBytes = size
+	return stats, nil
+}
+
 func (pt *sqlPrimaryTable) getValueColumnTypes(table string) ([]interface{}, error) {
 	query := pt.query.getValueColumnTypes(table)
 	rows, err := pt.db.Query(query)
@@ -864,6 +1274,60 @@ func (pt *sqlPrimaryTable) getValueColumnTypes(table string) ([]interface{}, err
 	return colTypes, nil
 }
 
+func (pt *sqlPrimaryTable) Schema() (TableSchema, error) {
+	rows, err := pt.db.Query(fmt.Sprintf("SELECT * FROM %s LIMIT 0", sanitize(pt.name)))
+	if err != nil {
+		return TableSchema{}, err
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return TableSchema{}, err
+	}
+	columns := make([]TableColumn, len(types))
+	for i, t := range types {
+		columns[i] = TableColumn{Name: t.Name(), ValueType: columnValueType(t)}
+	}
+	return TableSchema{Columns: columns}, nil
+}
+
+// columnValueType is the ValueType of a column. Drivers scan decimals as
+// strings or bytes, so they're recognized by their database type instead.
+func columnValueType(t *sql.ColumnType) ValueType {
+	switch t.DatabaseTypeName() {
+	case "NUMERIC", "DECIMAL":
+		return Decimal
+	}
+	return scanValueType(t.ScanType())
+}
+
+func scanValueType(t reflect.Type) ValueType {
+	if t == nil {
+		return NilType
+	}
+	switch t.String() {
+	case "int", "int8", "int16", "uint8", "uint16", "uint32", "sql.NullInt16":
+		return Int
+	case "int32", "sql.NullInt32":
+		return Int32
+	case "int64", "uint64", "sql.NullInt64":
+		return Int64
+	case "float32":
+		return Float32
+	case "float64", "sql.NullFloat64":
+		return Float64
+	case "string", "sql.NullString":
+		return String
+	case "bool", "sql.NullBool":
+		return Bool
+	case "time.Time", "sql.NullTime":
+		return Timestamp
+	case "[]uint8", "sql.RawBytes":
+		return Bytes
+	}
+	return NilType
+}
+
 func (pt *sqlPrimaryTable) NumRows() (int64, error) {
 	n := int64(0)
 	query := fmt.Sprintf("SELECT COUNT(*) FROM %s", sanitize(pt.name))
@@ -876,31 +1340,11 @@ func (pt *sqlPrimaryTable) NumRows() (int64, error) {
 	return n, nil
 }
 
-func determineColumnType(valueType ValueType) (string, error) {
-	switch valueType {
-	case Int, Int32, Int64:
-		return "INT", nil
-	case Float32, Float64:
-		return "FLOAT8", nil
-	case String:
-		return "VARCHAR", nil
-	case Bool:
-		return "BOOLEAN", nil
-	case Timestamp:
-		return "TIMESTAMPTZ", nil
-	case NilType:
-		return "VARCHAR", nil
-	default:
-		return "", fmt.Errorf("cannot find column type for value type: %s", valueType)
-	}
-}
-
 func (store *sqlOfflineStore) newsqlOfflineTable(db *sql.DB, name string, valueType ValueType) (*sqlOfflineTable, error) {
-	columnType, err := determineColumnType(valueType)
+	tableCreateQry, err := resourceTableQuery(store.query.dialect(), name, valueType)
 	if err != nil {
 		return nil, err
 	}
-	tableCreateQry := store.query.newSQLOfflineTable(name, columnType)
 	_, err = db.Exec(tableCreateQry)
 	if err != nil {
 		return nil, err
@@ -909,6 +1353,7 @@ func (store *sqlOfflineStore) newsqlOfflineTable(db *sql.DB, name string, valueT
 		db:    db,
 		name:  name,
 		query: store.query,
+		store: store,
 	}, nil
 }
 
@@ -936,6 +1381,9 @@ func (table *sqlOfflineTable) Write(rec ResourceRecord) error {
 			return err
 		}
 	}
+	if table.store != nil {
+		return table.store.bumpTableVersion(table.name)
+	}
 	return nil
 }
 
@@ -959,28 +1407,199 @@ func (table *sqlOfflineTable) resourceExists(rec ResourceRecord) (bool, error) {
 }
 
 func (store *sqlOfflineStore) CreateTransformation(config TransformationConfig) error {
+	return store.runTransformation(config, false)
+}
+
+func (store *sqlOfflineStore) UpdateTransformation(config TransformationConfig) error {
+	return store.runTransformation(config, true)
+}
+
+func (store *sqlOfflineStore) runTransformation(config TransformationConfig, replace bool) error {
 	name, err := store.createTransformationName(config.TargetTableID)
 	if err != nil {
 		return err
 	}
-	query := store.query.transformationCreate(name, config.Query)
-	if _, err := store.db.Exec(query); err != nil {
+	d := store.query.dialect()
+	setup, query, err := transformationStatements(d, config.Query)
+	if err != nil {
 		return err
 	}
-
-	return nil
+	config.Query = query
+	if config.Storage != TableStorage {
+		// A view is read in other sessions, where what its statements
+		// created wouldn't exist.
+		if len(setup) > 0 {
+			return fmt.Errorf("a transformation stored as a view can only have a query")
+		}
+		if err := store.createTransformationView(name, config, replace); err != nil {
+			return err
+		}
+		return store.untrackTable(name)
+	}
+	if len(setup) > 0 {
+		err = store.runTransformationWithSetup(name, setup, query, replace)
+	} else if replace {
+		err = store.query.transformationUpdate(store.db, name, query)
+	} else {
+		_, err = store.db.Exec(store.query.transformationCreate(name, query))
+	}
+	if err != nil {
+		return err
+	}
+	return store.setTableVersion(name, "")
 }
 
-func (store *sqlOfflineStore) UpdateTransformation(config TransformationConfig) error {
-	name, err := store.createTransformationName(config.TargetTableID)
+// runTransformationWithSetup creates or replaces the table of a
+// transformation whose query has statements before its SELECT. They run with
+// the SELECT on one connection and in one transaction, so that the SELECT
+// can read the temporary tables they create. The connection is closed rather
+// than returned to the pool afterwards, so that none of what they created
+// is left for the next update to run into.
+func (store *sqlOfflineStore) runTransformationWithSetup(name string, setup []string, query string, replace bool) error {
+	d := store.query.dialect()
+	for _, statement := range setup {
+		if err := checkSetupStatement(d, statement); err != nil {
+			return err
+		}
+	}
+	ctx := context.Background()
+	conn, err := store.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer discardConn(conn)
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	err = store.query.transformationUpdate(store.db, name, config.Query)
+	defer tx.Rollback()
+	for _, statement := range setup {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("run transformation statement %q: %w", statement, err)
+		}
+	}
+	if !replace {
+		if _, err := tx.Exec(store.query.transformationCreate(name, query)); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+	tempName := fmt.Sprintf("tmp_%s", name)
+	oldName := fmt.Sprintf("old_%s", name)
+	err = replaceRelationTx(tx, d, name,
+		store.query.transformationCreate(tempName, query),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", sanitize(name), sanitize(oldName)),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", sanitize(tempName), sanitize(name)),
+		fmt.Sprintf("DROP TABLE %s", sanitize(oldName)),
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// discardConn closes conn's session instead of returning it to the pool.
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+	conn.Close()
+}
+
+// createTransformationView registers a transformation's query as a view or
+// materialized view rather than copying its result into a table, so that
+// transformations chained on each other don't duplicate their data.
+func (store *sqlOfflineStore) createTransformationView(name string, config TransformationConfig, replace bool) error {
+	d := store.query.dialect()
+	var statements []string
+	switch config.Storage {
+	case ViewStorage:
+		statements = []string{transformationViewQuery(d, name, config.Query, replace)}
+	case MaterializedViewStorage:
+		if statements = d.materializedView(name, config.Query, replace); len(statements) == 0 {
+			return fmt.Errorf("%s does not support materialized views", store.Type())
+		}
+	default:
+		return fmt.Errorf("unknown transformation storage %s", config.Storage)
+	}
+	return replaceRelation(store.db, d, name, statements...)
+}
+
+// dependentView is a view that replaceRelation recreates over the relation
+// that replaced the one it was built on.
+type dependentView struct {
+	name         string
+	materialized bool
+	query        string
+}
+
+func (v dependentView) kind() string {
+	if v.materialized {
+		return "MATERIALIZED VIEW"
+	}
+	return "VIEW"
+}
+
+// replaceRelation runs the statements that replace the table or
+// materialized view named name in one transaction. Where views are bound to
+// relations rather than names, the views built on it would keep selecting
+// from the old relation, which couldn't be dropped while they do. They're
+// dropped first and created again over its replacement instead.
+func replaceRelation(db *sql.DB, d Dialect, name string, statements ...string) error {
+	if d.dependentViews() == "" && len(statements) == 1 {
+		_, err := db.Exec(statements[0])
+		return err
+	}
+	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+	if err := replaceRelationTx(tx, d, name, statements...); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
 
+// replaceRelationTx is replaceRelation in a transaction that's already been
+// started.
+func replaceRelationTx(tx *sql.Tx, d Dialect, name string, statements ...string) error {
+	listViews := d.dependentViews()
+	var views []dependentView
+	if listViews != "" {
+		rows, err := tx.Query(listViews, name)
+		if err != nil {
+			return fmt.Errorf("list views of %s: %w", name, err)
+		}
+		for rows.Next() {
+			var view dependentView
+			if err := rows.Scan(&view.name, &view.materialized, &view.query); err != nil {
+				rows.Close()
+				return err
+			}
+			view.query = strings.TrimSuffix(strings.TrimSpace(view.query), ";")
+			views = append(views, view)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	for i := len(views) - 1; i >= 0; i-- {
+		if _, err := tx.Exec(fmt.Sprintf("DROP %s %s", views[i].kind(), views[i].name)); err != nil {
+			return fmt.Errorf("drop %s: %w", views[i].name, err)
+		}
+	}
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+	for _, view := range views {
+		if _, err := tx.Exec(fmt.Sprintf("CREATE %s %s AS %s", view.kind(), view.name, view.query)); err != nil {
+			return fmt.Errorf("recreate %s: %w", view.name, err)
+		}
+	}
 	return nil
 }
 
@@ -1002,7 +1621,7 @@ func (store *sqlOfflineStore) createTransformationName(id ResourceID) (string, e
 }
 
 type sqlGenericTableIterator struct {
-	rows          *sql.Rows
+	rows          sqlRows
 	currentValues GenericRecord
 	err           error
 	columnTypes   []interface{}
@@ -1010,7 +1629,7 @@ type sqlGenericTableIterator struct {
 	query         OfflineTableQueries
 }
 
-func newsqlGenericTableIterator(rows *sql.Rows, columnTypes []interface{}, columnNames []string, query OfflineTableQueries) GenericTableIterator {
+func newsqlGenericTableIterator(rows sqlRows, columnTypes []interface{}, columnNames []string, query OfflineTableQueries) GenericTableIterator {
 	return &sqlGenericTableIterator{
 		rows:          rows,
 		currentValues: nil,
@@ -1023,6 +1642,7 @@ func newsqlGenericTableIterator(rows *sql.Rows, columnTypes []interface{}, colum
 
 func (it *sqlGenericTableIterator) Next() bool {
 	if !it.rows.Next() {
+		it.err = it.rows.Err()
 		it.rows.Close()
 		return false
 	}
@@ -1117,23 +1737,8 @@ func (q defaultOfflineSQLQueries) viewExists() string {
 	return genericExists
 }
 
-func (q defaultOfflineSQLQueries) registerResources(db *sql.DB, tableName string, schema ResourceSchema, timestamp bool) error {
-	var query string
-	if timestamp {
-		query = fmt.Sprintf("CREATE VIEW %s AS SELECT IDENTIFIER('%s') as entity,  IDENTIFIER('%s') as value,  IDENTIFIER('%s') as ts FROM TABLE('%s')", sanitize(tableName),
-			schema.Entity, schema.Value, schema.TS, sanitize(schema.SourceTable))
-	} else {
-		query = fmt.Sprintf("CREATE VIEW %s AS SELECT IDENTIFIER('%s') as entity, IDENTIFIER('%s') as value, to_timestamp_ntz('%s', 'YYYY-DD-MM HH24:MI:SS +0000 UTC')::TIMESTAMP_NTZ as ts FROM TABLE('%s')", sanitize(tableName),
-			schema.Entity, schema.Value, time.UnixMilli(0).UTC(), sanitize(schema.SourceTable))
-	}
-	if _, err := db.Exec(query); err != nil {
-		return err
-	}
-	return nil
-}
-
 func (q defaultOfflineSQLQueries) primaryTableRegister(tableName string, sourceName string) string {
-	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM TABLE('%s')", sanitize(tableName), sourceName)
+	return fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", sanitize(tableName), sourceName)
 }
 func (q defaultOfflineSQLQueries) getColumns(db *sql.DB, name string) ([]TableColumn, error) {
 	bind := q.newVariableBindingIterator()
@@ -1154,30 +1759,29 @@ func (q defaultOfflineSQLQueries) getColumns(db *sql.DB, name string) ([]TableCo
 	}
 	return columnNames, nil
 }
-func (q defaultOfflineSQLQueries) primaryTableCreate(name string, columnString string) string {
-	return fmt.Sprintf("CREATE TABLE %s ( %s )", sanitize(name), columnString)
+func (q defaultOfflineSQLQueries) dialect() Dialect {
+	return snowflakeDialect{}
 }
-func (q defaultOfflineSQLQueries) materializationCreate(tableName string, sourceName string) string {
-	return fmt.Sprintf(
-		"CREATE TABLE IF NOT EXISTS %s AS (SELECT entity, value, ts, row_number() over(ORDER BY (SELECT NULL)) as row_number FROM "+
-			"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) "+
-			"AS rn FROM %s) t WHERE rn=1)", sanitize(tableName), sanitize(sourceName))
+
+func (q defaultOfflineSQLQueries) materializationCreate(tableName string, query string) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s AS (%s)", sanitize(tableName), query)
 }
 
 func (q defaultOfflineSQLQueries) materializationUpdate(db *sql.DB, tableName string, sourceName string) error {
 	sanitizedTable := sanitize(tableName)
 	tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
-	oldTable := sanitize(fmt.Sprintf("old_%s", tableName))
+	generation := sanitize(generationTableName(tableName, time.Now()))
+	// A staging table left behind by a failed update is dropped rather than
+	// reused, since it may hold stale or partial data. The replaced table is
+	// kept as a generation until it's compacted.
 	query := fmt.Sprintf(
 		"BEGIN TRANSACTION;"+
-			"CREATE TABLE IF NOT EXISTS %s AS (SELECT entity, value, ts, row_number() over(ORDER BY (SELECT NULL)) as row_number FROM "+
-			"(SELECT entity, ts, value, row_number() OVER (PARTITION BY entity ORDER BY ts desc) "+
-			"AS rn FROM %s) t WHERE rn=1);"+
+			"DROP TABLE IF EXISTS %s;"+
+			"CREATE TABLE %s AS (%s);"+
 			"ALTER TABLE %s RENAME TO %s;"+
 			"ALTER TABLE %s RENAME TO %s;"+
-			"DROP TABLE %s;"+
 			"COMMIT;"+
-			"", tempTable, sanitize(sourceName), sanitizedTable, oldTable, tempTable, sanitizedTable, oldTable)
+			"", tempTable, tempTable, q.dialect().latestValues(sourceName, time.Time{}, time.Time{}), sanitizedTable, generation, tempTable, sanitizedTable)
 	var numStatements = 6
 	ctx = context.Background()
 	stmt, _ := sf.WithMultiStatement(ctx, numStatements)
@@ -1186,6 +1790,45 @@ func (q defaultOfflineSQLQueries) materializationUpdate(db *sql.DB, tableName st
 	return err
 }
 
+// generationTableName names the table that a materialization is moved to
+// when an update replaces it.
+func generationTableName(tableName string, replaced time.Time) string {
+	return fmt.Sprintf("%s%s%d", tableName, generationSuffix, replaced.UnixNano())
+}
+
+const generationSuffix = "_gen_"
+
+func (q defaultOfflineSQLQueries) tablesLike() string {
+	bind := q.newVariableBindingIterator()
+	return fmt.Sprintf("SELECT DISTINCT (table_name) FROM information_schema.tables WHERE table_name LIKE %s ESCAPE '%c'", bind.Next(), likeEscape)
+}
+
+// likeEscape escapes the wildcards of LIKE patterns. It isn't a backslash,
+// which is itself an escape in the string literals of some warehouses.
+const likeEscape = '!'
+
+// likePrefix returns a LIKE pattern, escaped with likeEscape, that matches
+// names starting with prefix. The underscores of table names would
+// otherwise match any character.
+func likePrefix(prefix string) string {
+	var pattern strings.Builder
+	for i := 0; i < len(prefix); i++ {
+		switch prefix[i] {
+		case '_', '%', likeEscape:
+			pattern.WriteByte(likeEscape)
+		}
+		pattern.WriteByte(prefix[i])
+	}
+	pattern.WriteByte('%')
+	return pattern.String()
+}
+
+// timestampLiteral formats t as a quoted SQL literal. It's used where a bind
+// parameter can't be, such as in the body of a materialized view.
+func timestampLiteral(t time.Time) string {
+	return fmt.Sprintf("'%s'", t.UTC().Format("2006-01-02 15:04:05.999999Z07:00"))
+}
+
 func (q defaultOfflineSQLQueries) getTable() string {
 	bind := q.newVariableBindingIterator()
 	return fmt.Sprintf("SELECT DISTINCT (table_name) FROM information_schema.tables WHERE table_name=%s", bind.Next())
@@ -1196,14 +1839,27 @@ func (q defaultOfflineSQLQueries) materializationExists() string {
 	return fmt.Sprintf("SELECT DISTINCT (table_name) FROM information_schema.tables WHERE table_name=%s", bind.Next())
 }
 
+func (q defaultOfflineSQLQueries) materializationSwap(db *sql.DB, stagingName string, tableName string) error {
+	_, err := db.Exec(fmt.Sprintf("ALTER TABLE %s RENAME TO %s", sanitize(stagingName), sanitize(tableName)))
+	return err
+}
+
 func (q defaultOfflineSQLQueries) materializationDrop(tableName string) string {
 	return fmt.Sprintf("DROP MATERIALIZED VIEW %s", sanitize(tableName))
 }
 
+func (q defaultOfflineSQLQueries) stagingDrop(stagingName string) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s", sanitize(stagingName))
+}
+
 func (q defaultOfflineSQLQueries) dropTable(tableName string) string {
 	return fmt.Sprintf("DROP TABLE %s", sanitize(tableName))
 }
 
+func (q defaultOfflineSQLQueries) dropView(tableName string) string {
+	return fmt.Sprintf("DROP VIEW %s", sanitize(tableName))
+}
+
 func (q defaultOfflineSQLQueries) trainingRowSelect(columns string, trainingSetName string) string {
 	return fmt.Sprintf("SELECT %s FROM %s", columns, sanitize(trainingSetName))
 }
@@ -1212,29 +1868,6 @@ func (q defaultOfflineSQLQueries) getValueColumnTypes(tableName string) string {
 	return fmt.Sprintf("SELECT * FROM %s", sanitize(tableName))
 }
 
-func (q defaultOfflineSQLQueries) determineColumnType(valueType ValueType) (string, error) {
-	switch valueType {
-	case Int, Int32, Int64:
-		return "INT", nil
-	case Float32, Float64:
-		return "FLOAT8", nil
-	case String:
-		return "VARCHAR", nil
-	case Bool:
-		return "BOOLEAN", nil
-	case Timestamp:
-		return "TIMESTAMP_NTZ", nil
-	case NilType:
-		return "VARCHAR", nil
-	default:
-		return "", fmt.Errorf("cannot find column type for value type: %s", valueType)
-	}
-}
-
-func (q defaultOfflineSQLQueries) newSQLOfflineTable(name string, columnType string) string {
-	return fmt.Sprintf("CREATE TABLE %s (entity VARCHAR, value %s, ts TIMESTAMP_NTZ, UNIQUE (entity, ts))", sanitize(name), columnType)
-}
-
 func (q defaultOfflineSQLQueries) resourceExists(tableName string) string {
 	bind := q.newVariableBindingIterator()
 	return fmt.Sprintf("SELECT entity, value, ts FROM %s WHERE entity=%s AND ts=%s ", sanitize(tableName), bind.Next(), bind.Next())
@@ -1252,6 +1885,10 @@ func (q defaultOfflineSQLQueries) writeExists(table string) string {
 	return fmt.Sprintf("SELECT COUNT (*) FROM %s WHERE entity=%s AND ts=%s", table, bind.Next(), bind.Next())
 }
 
+func (q defaultOfflineSQLQueries) iterateRows(db *sql.DB, query string, args ...interface{}) (sqlRows, error) {
+	return db.Query(query, args...)
+}
+
 func (q defaultOfflineSQLQueries) materializationIterateSegment(tableName string) string {
 	bind := q.newVariableBindingIterator()
 	return fmt.Sprintf("SELECT entity, value, ts FROM ( SELECT * FROM %s WHERE row_number>%s AND row_number<=%s)t1", sanitize(tableName), bind.Next(), bind.Next())
@@ -1265,47 +1902,6 @@ func (q defaultOfflineSQLQueries) createValuePlaceholderString(columns []TableCo
 	return strings.Join(placeholders, ", ")
 }
 
-func (q defaultOfflineSQLQueries) trainingSetQuery(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string, isUpdate bool) error {
-	columns := make([]string, 0)
-	query := ""
-	for i, feature := range def.Features {
-
-		tableName, err := store.getResourceTableName(feature)
-		santizedName := sanitize(tableName)
-		if err != nil {
-			return err
-		}
-		tableJoinAlias := fmt.Sprintf("t%d", i+1)
-		columns = append(columns, santizedName)
-		query = fmt.Sprintf("%s LEFT OUTER JOIN (SELECT entity, value as %s, ts FROM %s ORDER BY ts desc) as %s ON (%s.entity=t0.entity AND %s.ts <= t0.ts)",
-			query, santizedName, santizedName, tableJoinAlias, tableJoinAlias, tableJoinAlias)
-		if i == len(def.Features)-1 {
-			query = fmt.Sprintf("%s )) WHERE rn=1", query)
-		}
-	}
-	columnStr := strings.Join(columns, ", ")
-	if !isUpdate {
-		fullQuery := fmt.Sprintf(
-			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
-				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY time desc) as rn FROM ( "+
-				"SELECT t0.entity as e, t0.value as label, t0.ts as time, %s from %s as t0 %s )",
-			sanitize(tableName), columnStr, columnStr, sanitize(labelName), query)
-		if _, err := store.db.Exec(fullQuery); err != nil {
-			return err
-		}
-	} else {
-		tempTable := sanitize(fmt.Sprintf("tmp_%s", tableName))
-		fullQuery := fmt.Sprintf(
-			"CREATE TABLE %s AS (SELECT %s, label FROM ("+
-				"SELECT *, row_number() over(PARTITION BY e, label, time ORDER BY time desc) as rn FROM ( "+
-				"SELECT t0.entity as e, t0.value as label, t0.ts as time, %s from %s as t0 %s )",
-			tempTable, columnStr, columnStr, sanitize(labelName), query)
-		err := q.atomicUpdate(store.db, tableName, tempTable, fullQuery)
-		return err
-	}
-	return nil
-}
-
 func (q defaultOfflineSQLQueries) atomicUpdate(db *sql.DB, tableName string, tempName string, query string) error {
 	sanitizedTable := sanitize(tableName)
 	oldTable := sanitize(fmt.Sprintf("old_%s", tableName))
@@ -1324,14 +1920,6 @@ func (q defaultOfflineSQLQueries) atomicUpdate(db *sql.DB, tableName string, tem
 	return err
 }
 
-func (q defaultOfflineSQLQueries) trainingSetCreate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
-	return q.trainingSetQuery(store, def, tableName, labelName, false)
-}
-
-func (q defaultOfflineSQLQueries) trainingSetUpdate(store *sqlOfflineStore, def TrainingSetDef, tableName string, labelName string) error {
-	return q.trainingSetQuery(store, def, tableName, labelName, true)
-}
-
 func (q defaultOfflineSQLQueries) castTableItemType(v interface{}, t interface{}) interface{} {
 	switch t {
 	case sfInt, sfNumber:
@@ -1353,13 +1941,24 @@ func (q defaultOfflineSQLQueries) castTableItemType(v interface{}, t interface{}
 	case sfTimestamp:
 		ts := v.(time.Time).UTC()
 		return ts
+	case sfBinary:
+		return v.([]byte)
+	case sfDecimal:
+		return decimalValue(v)
 	default:
 		return v
 	}
 }
 
 func (q defaultOfflineSQLQueries) getValueColumnType(t *sql.ColumnType) interface{} {
+	// Only the precision and scale that Decimal columns are created with are
+	// read as decimals, so that other fractional NUMBER columns stay floats.
+	if precision, scale, ok := t.DecimalSize(); ok && t.DatabaseTypeName() == "FIXED" && precision == 38 && scale == 18 {
+		return sfDecimal
+	}
 	switch t.ScanType().String() {
+	case "[]uint8":
+		return sfBinary
 	case "string":
 		return sfString
 	case "int64":
@@ -1382,6 +1981,20 @@ func (q defaultOfflineSQLQueries) numRows(n interface{}) (int64, error) {
 	}
 }
 
+// tableSize returns -1 for views, which have no storage of their own.
+func (q defaultOfflineSQLQueries) tableSize(db *sql.DB, tableName string) (int64, error) {
+	bind := q.newVariableBindingIterator()
+	qry := fmt.Sprintf("SELECT BYTES FROM information_schema.tables WHERE table_name = %s", bind.Next())
+	var size sql.NullInt64
+	if err := db.QueryRow(qry, tableName).Scan(&size); err != nil {
+		return 0, err
+	}
+	if !size.Valid {
+		return -1, nil
+	}
+	return size.Int64, nil
+}
+
 func (q defaultOfflineSQLQueries) transformationCreate(name string, query string) string {
 	return fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM ( %s )", sanitize(name), query)
 }
@@ -0,0 +1,121 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+const MemoryStream Type = "MEMORY_STREAM"
+
+// StreamOffsets are how far a stream has been read: the offset of the last
+// record read from each of its partitions, by partition.
+type StreamOffsets map[string]string
+
+// StreamRecord is a feature value read from a stream, along with where in
+// the stream it was read from.
+type StreamRecord struct {
+	ResourceRecord
+	Partition string
+	Offset    string
+}
+
+// StreamReader reads a stream's records in the order they are in each of
+// its partitions.
+type StreamReader interface {
+	// Next blocks until a record is read, returning ctx's error if it's done
+	// first.
+	Next(ctx context.Context) (StreamRecord, error)
+	Close() error
+}
+
+// StreamingSource is implemented by providers whose feature values are
+// consumed as they arrive, such as Kafka or Kinesis.
+type StreamingSource interface {
+	Provider
+	// Subscribe reads topic from just after offsets. Partitions that have no
+	// offset are read from their start.
+	Subscribe(topic string, offsets StreamOffsets) (StreamReader, error)
+}
+
+// AsStreamingSource returns p as a StreamingSource if it is one.
+func AsStreamingSource(p Provider) (StreamingSource, error) {
+	source, ok := p.(StreamingSource)
+	if !ok {
+		return nil, fmt.Errorf("%T cannot be used as a StreamingSource", p)
+	}
+	return source, nil
+}
+
+// MemoryStreamingSource is a StreamingSource held in memory, whose topics
+// have a single partition "0" and records are offset by their index.
+type MemoryStreamingSource struct {
+	BaseProvider
+	mu     sync.Mutex
+	topics map[string][]ResourceRecord
+	// published is closed, and replaced, whenever records are published, to
+	// wake readers that are waiting for them.
+	published chan struct{}
+}
+
+func NewMemoryStreamingSource() *MemoryStreamingSource {
+	return &MemoryStreamingSource{
+		BaseProvider: BaseProvider{ProviderType: MemoryStream},
+		topics:       make(map[string][]ResourceRecord),
+		published:    make(chan struct{}),
+	}
+}
+
+// Publish appends records to topic.
+func (s *MemoryStreamingSource) Publish(topic string, records ...ResourceRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.topics[topic] = append(s.topics[topic], records...)
+	close(s.published)
+	s.published = make(chan struct{})
+}
+
+func (s *MemoryStreamingSource) Subscribe(topic string, offsets StreamOffsets) (StreamReader, error) {
+	next := 0
+	if offset, has := offsets["0"]; has {
+		last, err := strconv.Atoi(offset)
+		if err != nil || last < 0 {
+			return nil, fmt.Errorf("invalid offset %q for topic %s", offset, topic)
+		}
+		next = last + 1
+	}
+	return &memoryStreamReader{source: s, topic: topic, next: next}, nil
+}
+
+type memoryStreamReader struct {
+	source *MemoryStreamingSource
+	topic  string
+	next   int
+}
+
+func (r *memoryStreamReader) Next(ctx context.Context) (StreamRecord, error) {
+	for {
+		r.source.mu.Lock()
+		records, published := r.source.topics[r.topic], r.source.published
+		r.source.mu.Unlock()
+		if r.next < len(records) {
+			record := StreamRecord{ResourceRecord: records[r.next], Partition: "0", Offset: strconv.Itoa(r.next)}
+			r.next++
+			return record, nil
+		}
+		select {
+		case <-ctx.Done():
+			return StreamRecord{}, ctx.Err()
+		case <-published:
+		}
+	}
+}
+
+func (r *memoryStreamReader) Close() error {
+	return nil
+}
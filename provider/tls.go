@@ -0,0 +1,166 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// SSLMode mirrors the libpq sslmode values so the same setting can be used
+// for every provider that supports TLS.
+type SSLMode string
+
+const (
+	SSLDisable    SSLMode = "disable"
+	SSLRequire    SSLMode = "require"
+	SSLVerifyCA   SSLMode = "verify-ca"
+	SSLVerifyFull SSLMode = "verify-full"
+)
+
+// TLSConfig holds the TLS and mutual-TLS options shared by provider configs.
+// CACert, ClientCert and ClientKey are paths to PEM encoded files. Setting
+// ClientCert and ClientKey enables mutual TLS.
+type TLSConfig struct {
+	SSLMode    SSLMode `json:"SSLMode,omitempty"`
+	CACert     string  `json:"CACert,omitempty"`
+	ClientCert string  `json:"ClientCert,omitempty"`
+	ClientKey  string  `json:"ClientKey,omitempty"`
+}
+
+func (t TLSConfig) mode(fallback SSLMode) SSLMode {
+	if t.SSLMode == "" {
+		return fallback
+	}
+	return t.SSLMode
+}
+
+func (t TLSConfig) validate() error {
+	switch t.SSLMode {
+	case "", SSLDisable, SSLRequire, SSLVerifyCA, SSLVerifyFull:
+	default:
+		return fmt.Errorf("unknown ssl mode: %s", t.SSLMode)
+	}
+	if (t.ClientCert == "") != (t.ClientKey == "") {
+		return fmt.Errorf("client cert and client key must be set together")
+	}
+	return nil
+}
+
+// connectionParams returns the libpq connection parameters for the config,
+// using fallback when SSLMode is unset.
+func (t TLSConfig) connectionParams(fallback SSLMode) url.Values {
+	params := url.Values{}
+	params.Set("sslmode", string(t.mode(fallback)))
+	if t.CACert != "" {
+		params.Set("sslrootcert", t.CACert)
+	}
+	if t.ClientCert != "" {
+		params.Set("sslcert", t.ClientCert)
+		params.Set("sslkey", t.ClientKey)
+	}
+	return params
+}
+
+// dsnParams returns the connection parameters formatted as libpq keyword/value
+// pairs.
+func (t TLSConfig) dsnParams(fallback SSLMode) string {
+	params := t.connectionParams(fallback)
+	pairs := make([]string, 0, len(params))
+	for _, key := range []string{"sslmode", "sslrootcert", "sslcert", "sslkey"} {
+		if value := params.Get(key); value != "" {
+			pairs = append(pairs, fmt.Sprintf("%s=%s", key, dsnValue(value)))
+		}
+	}
+	return strings.Join(pairs, " ")
+}
+
+// dsnValue formats a value of a libpq keyword/value connection string. Values
+// that are empty or have spaces, quotes or backslashes in them are quoted,
+// with their quotes and backslashes escaped.
+func dsnValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\r\v\f'\\") {
+		return value
+	}
+	escaped := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return fmt.Sprintf("'%s'", escaped)
+}
+
+// hasOptions returns whether any TLS option is set.
+func (t TLSConfig) hasOptions() bool {
+	return t != TLSConfig{}
+}
+
+// tlsConfig builds a crypto/tls config for the given server name. It returns
+// nil if TLS is disabled.
+func (t TLSConfig) tlsConfig(serverName string) (*tls.Config, error) {
+	if err := t.validate(); err != nil {
+		return nil, err
+	}
+	mode := t.mode(SSLDisable)
+	if mode == SSLDisable {
+		return nil, nil
+	}
+	config := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if t.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(t.ClientCert, t.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("could not load client key pair: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if t.CACert != "" {
+		pem, err := os.ReadFile(t.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("could not read ca cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", t.CACert)
+		}
+		config.RootCAs = pool
+	}
+	switch mode {
+	case SSLRequire:
+		config.InsecureSkipVerify = true
+	case SSLVerifyCA:
+		// Verify the chain against the CA but skip hostname verification.
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = verifyChain(config.RootCAs)
+	}
+	return config, nil
+}
+
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("server presented no certificates")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs[i] = cert
+		}
+		opts := x509.VerifyOptions{
+			Roots:         roots,
+			Intermediates: x509.NewCertPool(),
+		}
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(opts)
+		return err
+	}
+}
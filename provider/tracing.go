@@ -0,0 +1,55 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	sn "github.com/mrz1836/go-sanitize"
+)
+
+// Request IDs are embedded in store commands, so they are limited to
+// characters that are safe in a Redis client name and a query comment.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context that tags the store operations it is passed
+// to with id, so a slow request can be matched to the store's slow log.
+func WithRequestID(parent context.Context, id string) context.Context {
+	id = sn.Custom(id, "[^a-zA-Z0-9_.:-]")
+	if len(id) > maxRequestIDLength {
+		id = id[:maxRequestIDLength]
+	}
+	return context.WithValue(parent, requestIDKey{}, id)
+}
+
+func RequestID(c context.Context) (string, bool) {
+	id, ok := c.Value(requestIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// ContextOnlineStoreTable is implemented by tables that can tag their reads
+// with the request ID carried by the context.
+type ContextOnlineStoreTable interface {
+	GetWithContext(c context.Context, entity string) (interface{}, error)
+}
+
+// GetWithContext reads entity from table, passing c through if the table
+// supports it.
+func GetWithContext(c context.Context, table OnlineStoreTable, entity string) (interface{}, error) {
+	if casted, ok := table.(ContextOnlineStoreTable); ok {
+		return casted.GetWithContext(c, entity)
+	}
+	return table.Get(entity)
+}
+
+func requestComment(c context.Context) string {
+	if id, ok := RequestID(c); ok {
+		return fmt.Sprintf("/* request_id=%s */ ", id)
+	}
+	return ""
+}
@@ -0,0 +1,315 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package provider
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	trainingSetCachePrefix = "featureform_cache_"
+	// tableVersionsTable records a version for each table that featureform
+	// writes, which changes whenever the table's rows do. Tables registered
+	// from a source table record it too, and share its version.
+	tableVersionsTable = "featureform_table_versions"
+	// trainingSetCacheTable records which training sets use each cached
+	// feature table, and in which cycle.
+	trainingSetCacheTable = "featureform_training_set_cache"
+	// maxVersionDepth bounds how many source tables are followed to find a
+	// table's version.
+	maxVersionDepth = 8
+)
+
+// trainingSetCacheQueries are the statements that keep the table versions
+// and the cache usage up to date.
+type trainingSetCacheQueries struct {
+	createVersions string
+	createUsage    string
+	deleteVersion  string
+	insertVersion  string
+	bumpVersion    string
+	getVersion     string
+	deleteUsage    string
+	insertUsage    string
+	pruneUsage     string
+	forgetUsage    string
+	listUsed       string
+}
+
+func (q defaultOfflineSQLQueries) trainingSetCacheQueries() trainingSetCacheQueries {
+	return trainingSetCacheQueries{
+		createVersions: fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (table_name VARCHAR(1024), source_table VARCHAR(1024), version BIGINT)", tableVersionsTable),
+		createUsage:    fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (cache_table VARCHAR(1024), training_set VARCHAR(1024), cycle BIGINT)", trainingSetCacheTable),
+		deleteVersion:  q.bindQuery("DELETE FROM "+tableVersionsTable+" WHERE table_name = %s", 1),
+		insertVersion:  q.bindQuery("INSERT INTO "+tableVersionsTable+" (table_name, source_table, version) VALUES (%s, %s, %s)", 3),
+		bumpVersion:    q.bindQuery("UPDATE "+tableVersionsTable+" SET version = %s WHERE table_name = %s", 2),
+		getVersion:     q.bindQuery("SELECT source_table, version FROM "+tableVersionsTable+" WHERE table_name = %s", 1),
+		deleteUsage:    q.bindQuery("DELETE FROM "+trainingSetCacheTable+" WHERE cache_table = %s AND training_set = %s", 2),
+		insertUsage:    q.bindQuery("INSERT INTO "+trainingSetCacheTable+" (cache_table, training_set, cycle) VALUES (%s, %s, %s)", 3),
+		pruneUsage:     q.bindQuery("DELETE FROM "+trainingSetCacheTable+" WHERE training_set = %s AND cycle < %s", 2),
+		forgetUsage:    q.bindQuery("DELETE FROM "+trainingSetCacheTable+" WHERE training_set = %s", 1),
+		listUsed:       fmt.Sprintf("SELECT DISTINCT cache_table FROM %s", trainingSetCacheTable),
+	}
+}
+
+// bindQuery fills format's n verbs with the store's variable bindings.
+func (q defaultOfflineSQLQueries) bindQuery(format string, n int) string {
+	bind := q.newVariableBindingIterator()
+	bindings := make([]interface{}, n)
+	for i := range bindings {
+		bindings[i] = bind.Next()
+	}
+	return fmt.Sprintf(format, bindings...)
+}
+
+func (q defaultOfflineSQLQueries) trainingSetCacheCreate(cacheName string, tableName string) string {
+	return fmt.Sprintf("CREATE TABLE %s AS SELECT entity, value, ts FROM %s", sanitize(cacheName), sanitize(tableName))
+}
+
+// withCacheTables runs fn, and runs it again once the version and usage
+// tables are created if it fails, since stores made before they were added
+// don't have them.
+func (store *sqlOfflineStore) withCacheTables(fn func(queries trainingSetCacheQueries) error) error {
+	queries := store.query.trainingSetCacheQueries()
+	if err := fn(queries); err == nil {
+		return nil
+	}
+	for _, create := range []string{queries.createVersions, queries.createUsage} {
+		if _, err := store.db.Exec(create); err != nil {
+			return fmt.Errorf("create training set cache tables: %w", err)
+		}
+	}
+	return fn(queries)
+}
+
+// setTableVersion starts tracking the rows of tableName with a new version,
+// or, if sourceTable is set, with the version of the table it's read from.
+func (store *sqlOfflineStore) setTableVersion(tableName string, sourceTable string) error {
+	return store.withCacheTables(func(queries trainingSetCacheQueries) error {
+		tx, err := store.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if _, err := tx.Exec(queries.deleteVersion, tableName); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(queries.insertVersion, tableName, sourceTable, time.Now().UnixNano()); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+}
+
+// bumpTableVersion gives tableName a new version after its rows change. It's
+// done after the change, so that a cache made from the old version may hold
+// newer rows but never older ones.
+func (store *sqlOfflineStore) bumpTableVersion(tableName string) error {
+	return store.withCacheTables(func(queries trainingSetCacheQueries) error {
+		_, err := store.db.Exec(queries.bumpVersion, time.Now().UnixNano(), tableName)
+		return err
+	})
+}
+
+// untrackTable stops tracking tableName's version, for tables whose rows
+// can change without featureform writing them, such as views.
+func (store *sqlOfflineStore) untrackTable(tableName string) error {
+	return store.withCacheTables(func(queries trainingSetCacheQueries) error {
+		_, err := store.db.Exec(queries.deleteVersion, tableName)
+		return err
+	})
+}
+
+// tableVersion returns the version of tableName's rows, made up of its own
+// version and those of the tables it's read from. It returns false if any
+// of them isn't tracked, in which case the table can't be cached.
+func (store *sqlOfflineStore) tableVersion(tableName string) (string, bool, error) {
+	var version string
+	var tracked bool
+	err := store.withCacheTables(func(queries trainingSetCacheQueries) error {
+		versions := make([]string, 0)
+		name := tableName
+		for depth := 0; depth < maxVersionDepth; depth++ {
+			var source string
+			var v int64
+			err := store.db.QueryRow(queries.getVersion, name).Scan(&source, &v)
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil
+			} else if err != nil {
+				return err
+			}
+			versions = append(versions, strconv.FormatInt(v, 10))
+			if source == "" {
+				version, tracked = strings.Join(versions, "."), true
+				return nil
+			}
+			name = source
+		}
+		return nil
+	})
+	return version, tracked, err
+}
+
+// trainingSetFeatureTable returns the table a training set reads a feature's
+// values from. Within a cache cycle that's a copy of the feature's table,
+// shared by every training set built in the cycle. Copies are named after a
+// hash of the table's name and the version of its rows, so a feature that
+// gets new values mid-cycle, even in place, is copied again. Features whose
+// version isn't tracked are read directly.
+func (store *sqlOfflineStore) trainingSetFeatureTable(def TrainingSetDef, feature ResourceID) (string, error) {
+	tableName, err := store.getResourceTableName(feature)
+	if err != nil {
+		return "", err
+	}
+	if def.CacheCycle.IsZero() {
+		return tableName, nil
+	}
+	version, tracked, err := store.tableVersion(tableName)
+	if err != nil {
+		return "", fmt.Errorf("version of %s: %w", tableName, err)
+	} else if !tracked {
+		return tableName, nil
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s", tableName, version)))
+	cacheName := fmt.Sprintf("%s%d_%s", trainingSetCachePrefix, def.CacheCycle.Unix(), hex.EncodeToString(sum[:8]))
+	// The use is recorded before the copy is made, so that another training
+	// set's prune never sees the copy unused.
+	if err := store.useTrainingSetCache(cacheName, def); err != nil {
+		return "", err
+	}
+	if exists, err := store.namedTableExists(cacheName); err != nil {
+		return "", err
+	} else if exists {
+		return cacheName, nil
+	}
+	if _, err := store.db.Exec(store.query.trainingSetCacheCreate(cacheName, tableName)); err != nil {
+		// Another training set in the cycle may have made the copy first.
+		if exists, existsErr := store.namedTableExists(cacheName); existsErr == nil && exists {
+			return cacheName, nil
+		}
+		return "", fmt.Errorf("cache %s: %w", tableName, err)
+	}
+	return cacheName, nil
+}
+
+func (store *sqlOfflineStore) useTrainingSetCache(cacheName string, def TrainingSetDef) error {
+	trainingSet, err := store.getTrainingSetName(def.ID)
+	if err != nil {
+		return err
+	}
+	err = store.withCacheTables(func(queries trainingSetCacheQueries) error {
+		tx, err := store.db.Begin()
+		if err != nil {
+			return err
+		}
+		defer tx.Rollback()
+		if _, err := tx.Exec(queries.deleteUsage, cacheName, trainingSet); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(queries.insertUsage, cacheName, trainingSet, def.CacheCycle.Unix()); err != nil {
+			return err
+		}
+		return tx.Commit()
+	})
+	if err != nil {
+		return fmt.Errorf("record use of %s: %w", cacheName, err)
+	}
+	return nil
+}
+
+func (store *sqlOfflineStore) namedTableExists(tableName string) (bool, error) {
+	n := -1
+	if err := store.db.QueryRow(store.query.tableExists(), tableName).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// PruneTrainingSetCache stops id using the feature table copies of cycles
+// that started before the given time, and drops the copies from those
+// cycles that no training set uses any more. Copies that other training sets
+// still use are kept until they're pruned too.
+func (store *sqlOfflineStore) PruneTrainingSetCache(id ResourceID, before time.Time) error {
+	trainingSet, err := store.getTrainingSetName(id)
+	if err != nil {
+		return err
+	}
+	err = store.withCacheTables(func(queries trainingSetCacheQueries) error {
+		_, err := store.db.Exec(queries.pruneUsage, trainingSet, before.Unix())
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("prune cache use of %s: %w", trainingSet, err)
+	}
+	return store.dropUnusedTrainingSetCaches(before)
+}
+
+// forgetTrainingSetCache stops a deleted training set using any copies, so
+// that they can be dropped once the training sets that share them are
+// pruned.
+func (store *sqlOfflineStore) forgetTrainingSetCache(trainingSet string) error {
+	return store.withCacheTables(func(queries trainingSetCacheQueries) error {
+		_, err := store.db.Exec(queries.forgetUsage, trainingSet)
+		return err
+	})
+}
+
+func (store *sqlOfflineStore) dropUnusedTrainingSetCaches(before time.Time) error {
+	used := make(map[string]bool)
+	err := store.withCacheTables(func(queries trainingSetCacheQueries) error {
+		rows, err := store.db.Query(queries.listUsed)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var cacheName string
+			if err := rows.Scan(&cacheName); err != nil {
+				return err
+			}
+			used[cacheName] = true
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return fmt.Errorf("list used caches: %w", err)
+	}
+	rows, err := store.db.Query(store.query.tablesLike(), likePrefix(trainingSetCachePrefix))
+	if err != nil {
+		return fmt.Errorf("list cached tables: %w", err)
+	}
+	expired := make([]string, 0)
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			rows.Close()
+			return err
+		}
+		if used[tableName] {
+			continue
+		}
+		cycle := strings.SplitN(strings.TrimPrefix(tableName, trainingSetCachePrefix), "_", 2)[0]
+		if unix, err := strconv.ParseInt(cycle, 10, 64); err == nil && unix < before.Unix() {
+			expired = append(expired, tableName)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, tableName := range expired {
+		if _, err := store.db.Exec(store.query.dropTable(tableName)); err != nil {
+			return fmt.Errorf("drop %s: %w", tableName, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,422 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/google/uuid"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	rest "k8s.io/client-go/rest"
+)
+
+const argoAPIVersion = "argoproj.io/v1alpha1"
+
+var (
+	workflowResource     = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "workflows"}
+	cronWorkflowResource = schema.GroupVersionResource{Group: "argoproj.io", Version: "v1alpha1", Resource: "cronworkflows"}
+)
+
+// ArgoPollInterval is how often a workflow's status is checked while waiting
+// for it.
+var ArgoPollInterval = 5 * time.Second
+
+// The phases of an Argo workflow.
+const (
+	WorkflowPending   = "Pending"
+	WorkflowRunning   = "Running"
+	WorkflowSucceeded = "Succeeded"
+	WorkflowFailed    = "Failed"
+	WorkflowError     = "Error"
+)
+
+// Workflow is the part of an Argo Workflow that runners submit and read.
+type Workflow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              WorkflowSpec   `json:"spec"`
+	Status            WorkflowStatus `json:"status,omitempty"`
+}
+
+type WorkflowSpec struct {
+	Entrypoint         string             `json:"entrypoint"`
+	Templates          []WorkflowTemplate `json:"templates"`
+	Volumes            []v1.Volume        `json:"volumes,omitempty"`
+	ServiceAccountName string             `json:"serviceAccountName,omitempty"`
+	PodMetadata        *WorkflowMetadata  `json:"podMetadata,omitempty"`
+}
+
+type WorkflowMetadata struct {
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type WorkflowTemplate struct {
+	Name            string                   `json:"name"`
+	Inputs          *WorkflowInputs          `json:"inputs,omitempty"`
+	Container       *v1.Container            `json:"container,omitempty"`
+	Steps           [][]WorkflowStep         `json:"steps,omitempty"`
+	RetryStrategy   *WorkflowRetryStrategy   `json:"retryStrategy,omitempty"`
+	ArchiveLocation *WorkflowArchiveLocation `json:"archiveLocation,omitempty"`
+}
+
+type WorkflowInputs struct {
+	Parameters []WorkflowParameter `json:"parameters,omitempty"`
+}
+
+type WorkflowParameter struct {
+	Name  string `json:"name"`
+	Value string `json:"value,omitempty"`
+}
+
+type WorkflowStep struct {
+	Name         string            `json:"name"`
+	Template     string            `json:"template"`
+	Arguments    *WorkflowInputs   `json:"arguments,omitempty"`
+	WithSequence *WorkflowSequence `json:"withSequence,omitempty"`
+}
+
+type WorkflowSequence struct {
+	Count string `json:"count"`
+}
+
+type WorkflowRetryStrategy struct {
+	Limit       string `json:"limit"`
+	RetryPolicy string `json:"retryPolicy,omitempty"`
+}
+
+// WorkflowArchiveLocation has Argo keep a step's logs as an artifact in its
+// artifact repository.
+type WorkflowArchiveLocation struct {
+	ArchiveLogs *bool `json:"archiveLogs,omitempty"`
+}
+
+type WorkflowStatus struct {
+	Phase    string `json:"phase,omitempty"`
+	Message  string `json:"message,omitempty"`
+	Progress string `json:"progress,omitempty"`
+}
+
+// Finished returns true once the workflow has stopped running, whether or
+// not it succeeded.
+func (s WorkflowStatus) Finished() bool {
+	return s.Phase == WorkflowSucceeded || s.Phase == WorkflowFailed || s.Phase == WorkflowError
+}
+
+// CronWorkflow is the part of an Argo CronWorkflow that runners submit.
+type CronWorkflow struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              CronWorkflowSpec `json:"spec"`
+}
+
+type CronWorkflowSpec struct {
+	Schedule          string       `json:"schedule"`
+	ConcurrencyPolicy string       `json:"concurrencyPolicy,omitempty"`
+	WorkflowSpec      WorkflowSpec `json:"workflowSpec"`
+}
+
+type ArgoRunnerConfig struct {
+	EnvVars  map[string]string
+	Resource metadata.ResourceID
+	Image    string
+	NumTasks int32
+	// Retries is how many times Argo retries a failed task before it fails
+	// the workflow.
+	Retries int32
+	// ServiceAccount is the service account workflow pods run as. Argo's
+	// executor needs one allowed to patch pods.
+	ServiceAccount string
+	// ArchiveLogs keeps each task's logs in Argo's artifact repository.
+	ArchiveLogs bool
+}
+
+// WorkflowClient submits and reads the workflows of a single job.
+type WorkflowClient interface {
+	Create(workflow *Workflow) (*Workflow, error)
+	Get(name string) (*Workflow, error)
+	Delete(name string) error
+	SetSchedule(cronWorkflow *CronWorkflow) error
+}
+
+// ArgoRunner runs a job as an Argo workflow. A job of more than one task
+// runs a step per task, which Argo runs in parallel, so chunks show up in
+// the workflow's graph and are retried separately.
+type ArgoRunner struct {
+	client   WorkflowClient
+	jobName  string
+	resource metadata.ResourceID
+	spec     WorkflowSpec
+}
+
+const (
+	argoEntrypoint = "main"
+	argoTask       = "task"
+	argoIndexParam = "index"
+)
+
+func newWorkflowSpec(jobName string, config ArgoRunnerConfig) WorkflowSpec {
+	envVars := generateKubernetesEnvVars(config.EnvVars)
+	task := WorkflowTemplate{
+		Name: argoTask,
+	}
+	var steps []WorkflowStep
+	if config.NumTasks > 1 {
+		// Each step is given its index the way an indexed Kubernetes job
+		// would, so the worker knows which chunk to copy.
+		envVars = append(envVars, v1.EnvVar{Name: "JOB_COMPLETION_INDEX", Value: fmt.Sprintf("{{inputs.parameters.%s}}", argoIndexParam)})
+		task.Inputs = &WorkflowInputs{Parameters: []WorkflowParameter{{Name: argoIndexParam}}}
+		steps = []WorkflowStep{{
+			Name:         "chunk",
+			Template:     argoTask,
+			Arguments:    &WorkflowInputs{Parameters: []WorkflowParameter{{Name: argoIndexParam, Value: "{{item}}"}}},
+			WithSequence: &WorkflowSequence{Count: strconv.Itoa(int(config.NumTasks))},
+		}}
+	} else {
+		steps = []WorkflowStep{{Name: "run", Template: argoTask}}
+	}
+	volumes, mounts := credentialsVolume()
+	task.Container = &v1.Container{
+		Name:         uuid.New().String(),
+		Image:        config.Image,
+		Env:          envVars,
+		VolumeMounts: mounts,
+	}
+	if config.Retries > 0 {
+		task.RetryStrategy = &WorkflowRetryStrategy{Limit: strconv.Itoa(int(config.Retries)), RetryPolicy: "Always"}
+	}
+	if config.ArchiveLogs {
+		archiveLogs := true
+		task.ArchiveLocation = &WorkflowArchiveLocation{ArchiveLogs: &archiveLogs}
+	}
+	return WorkflowSpec{
+		Entrypoint: argoEntrypoint,
+		Templates: []WorkflowTemplate{
+			{Name: argoEntrypoint, Steps: [][]WorkflowStep{steps}},
+			task,
+		},
+		Volumes:            volumes,
+		ServiceAccountName: config.ServiceAccount,
+		PodMetadata:        &WorkflowMetadata{Labels: map[string]string{jobLabel: jobLabelValue(jobName)}},
+	}
+}
+
+func (a ArgoRunner) Resource() metadata.ResourceID {
+	return a.resource
+}
+
+func (a ArgoRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (a ArgoRunner) Run() (CompletionWatcher, error) {
+	// Every run is a workflow of its own, so that Argo keeps the history of
+	// a job's runs.
+	workflow := &Workflow{
+		TypeMeta:   metav1.TypeMeta{APIVersion: argoAPIVersion, Kind: "Workflow"},
+		ObjectMeta: metav1.ObjectMeta{GenerateName: a.jobName + "-"},
+		Spec:       a.spec,
+	}
+	created, err := a.client.Create(workflow)
+	if err != nil {
+		return nil, fmt.Errorf("create workflow: %w", err)
+	}
+	return ArgoCompletionWatcher{client: a.client, name: created.Name}, nil
+}
+
+func (a ArgoRunner) ScheduleJob(schedule CronSchedule) error {
+	cronWorkflow := &CronWorkflow{
+		TypeMeta:   metav1.TypeMeta{APIVersion: argoAPIVersion, Kind: "CronWorkflow"},
+		ObjectMeta: metav1.ObjectMeta{Name: a.jobName},
+		Spec: CronWorkflowSpec{
+			Schedule: string(schedule),
+			// A run isn't started while the last one is still running, like
+			// the coordinator's own scheduler.
+			ConcurrencyPolicy: "Forbid",
+			WorkflowSpec:      a.spec,
+		},
+	}
+	if err := a.client.SetSchedule(cronWorkflow); err != nil {
+		return fmt.Errorf("schedule workflow: %w", err)
+	}
+	return nil
+}
+
+func NewArgoRunner(config ArgoRunnerConfig) (CronRunner, error) {
+	jobName := GetJobName(config.Resource)
+	client, err := NewArgoWorkflowClient(Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return newArgoRunner(client, jobName, config), nil
+}
+
+func newArgoRunner(client WorkflowClient, jobName string, config ArgoRunnerConfig) ArgoRunner {
+	return ArgoRunner{
+		client:   client,
+		jobName:  jobName,
+		resource: config.Resource,
+		spec:     newWorkflowSpec(jobName, config),
+	}
+}
+
+// ArgoCompletionWatcher watches a submitted workflow.
+type ArgoCompletionWatcher struct {
+	client WorkflowClient
+	name   string
+}
+
+func (a ArgoCompletionWatcher) Complete() bool {
+	workflow, err := a.client.Get(a.name)
+	if err != nil {
+		return false
+	}
+	return workflow.Status.Finished()
+}
+
+func (a ArgoCompletionWatcher) String() string {
+	workflow, err := a.client.Get(a.name)
+	if err != nil {
+		return "Could not fetch workflow."
+	}
+	return fmt.Sprintf("Workflow %s %s. %s tasks done", a.name, workflow.Status.Phase, workflow.Status.Progress)
+}
+
+// Progress is the number of the workflow's tasks that are done, going by
+// the done/total progress Argo reports.
+func (a ArgoCompletionWatcher) Progress() Progress {
+	workflow, err := a.client.Get(a.name)
+	if err != nil {
+		return Progress{}
+	}
+	var done, total int
+	if _, err := fmt.Sscanf(workflow.Status.Progress, "%d/%d", &done, &total); err != nil {
+		return Progress{}
+	}
+	return taskProgress(done, total, workflow.CreationTimestamp.Time)
+}
+
+func (a ArgoCompletionWatcher) Wait() error {
+	for {
+		workflow, err := a.client.Get(a.name)
+		if err != nil {
+			return err
+		}
+		if workflow.Status.Finished() {
+			return workflowErr(workflow)
+		}
+		time.Sleep(ArgoPollInterval)
+	}
+}
+
+func (a ArgoCompletionWatcher) Err() error {
+	workflow, err := a.client.Get(a.name)
+	if err != nil {
+		return err
+	}
+	return workflowErr(workflow)
+}
+
+// Cancel deletes the workflow, which stops its pods if it's still running.
+func (a ArgoCompletionWatcher) Cancel() error {
+	return a.client.Delete(a.name)
+}
+
+func workflowErr(workflow *Workflow) error {
+	if workflow.Status.Phase == WorkflowFailed || workflow.Status.Phase == WorkflowError {
+		return fmt.Errorf("workflow %s %s: %s", workflow.Name, workflow.Status.Phase, workflow.Status.Message)
+	}
+	return nil
+}
+
+// ArgoWorkflowClient submits workflows to the Argo controller through the
+// Kubernetes API.
+type ArgoWorkflowClient struct {
+	Client    dynamic.Interface
+	Namespace string
+}
+
+func NewArgoWorkflowClient(namespace string) (*ArgoWorkflowClient, error) {
+	kubeConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &ArgoWorkflowClient{Client: client, Namespace: namespace}, nil
+}
+
+func (a ArgoWorkflowClient) Create(workflow *Workflow) (*Workflow, error) {
+	obj, err := toUnstructured(workflow)
+	if err != nil {
+		return nil, err
+	}
+	created, err := a.Client.Resource(workflowResource).Namespace(a.Namespace).Create(context.TODO(), obj, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return fromUnstructured(created)
+}
+
+func (a ArgoWorkflowClient) Get(name string) (*Workflow, error) {
+	obj, err := a.Client.Resource(workflowResource).Namespace(a.Namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return fromUnstructured(obj)
+}
+
+func (a ArgoWorkflowClient) Delete(name string) error {
+	propagation := metav1.DeletePropagationBackground
+	return a.Client.Resource(workflowResource).Namespace(a.Namespace).Delete(context.TODO(), name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
+// SetSchedule creates the cron workflow, or replaces the schedule and spec
+// of the one that already exists.
+func (a ArgoWorkflowClient) SetSchedule(cronWorkflow *CronWorkflow) error {
+	obj, err := toUnstructured(cronWorkflow)
+	if err != nil {
+		return err
+	}
+	cronWorkflows := a.Client.Resource(cronWorkflowResource).Namespace(a.Namespace)
+	_, err = cronWorkflows.Create(context.TODO(), obj, metav1.CreateOptions{})
+	if !errors.IsAlreadyExists(err) {
+		return err
+	}
+	existing, err := cronWorkflows.Get(context.TODO(), cronWorkflow.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	_, err = cronWorkflows.Update(context.TODO(), obj, metav1.UpdateOptions{})
+	return err
+}
+
+func toUnstructured(obj interface{}) (*unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return nil, fmt.Errorf("convert %T: %w", obj, err)
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
+
+func fromUnstructured(obj *unstructured.Unstructured) (*Workflow, error) {
+	workflow := &Workflow{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, workflow); err != nil {
+		return nil, fmt.Errorf("convert workflow: %w", err)
+	}
+	return workflow, nil
+}
@@ -0,0 +1,231 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/featureform/metadata"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+// mockWorkflowClient moves each workflow it's given through phases, one per
+// Get.
+type mockWorkflowClient struct {
+	mtx       sync.Mutex
+	phases    []string
+	workflows map[string]*Workflow
+	gets      map[string]int
+	deleted   []string
+	cron      *CronWorkflow
+	createErr error
+}
+
+func newMockWorkflowClient(phases ...string) *mockWorkflowClient {
+	return &mockWorkflowClient{phases: phases, workflows: make(map[string]*Workflow), gets: make(map[string]int)}
+}
+
+func (m *mockWorkflowClient) Create(workflow *Workflow) (*Workflow, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	if m.createErr != nil {
+		return nil, m.createErr
+	}
+	created := *workflow
+	created.Name = fmt.Sprintf("%s%d", workflow.GenerateName, len(m.workflows))
+	m.workflows[created.Name] = &created
+	return &created, nil
+}
+
+func (m *mockWorkflowClient) Get(name string) (*Workflow, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	workflow, has := m.workflows[name]
+	if !has {
+		return nil, fmt.Errorf("workflow %s not found", name)
+	}
+	i := m.gets[name]
+	if i >= len(m.phases) {
+		i = len(m.phases) - 1
+	}
+	m.gets[name]++
+	workflow.Status = WorkflowStatus{Phase: m.phases[i], Message: "child failed", Progress: "0/1"}
+	return workflow, nil
+}
+
+func (m *mockWorkflowClient) Delete(name string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	delete(m.workflows, name)
+	m.deleted = append(m.deleted, name)
+	return nil
+}
+
+func (m *mockWorkflowClient) SetSchedule(cronWorkflow *CronWorkflow) error {
+	m.cron = cronWorkflow
+	return nil
+}
+
+func setArgoPollInterval(t *testing.T) {
+	interval := ArgoPollInterval
+	ArgoPollInterval = time.Millisecond
+	t.Cleanup(func() { ArgoPollInterval = interval })
+}
+
+func TestArgoRunner(t *testing.T) {
+	setArgoPollInterval(t)
+	client := newMockWorkflowClient(WorkflowPending, WorkflowRunning, WorkflowSucceeded)
+	id := metadata.ResourceID{Name: "avg_txn", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	runner := newArgoRunner(client, GetJobName(id), ArgoRunnerConfig{Resource: id, Image: "worker", NumTasks: 1})
+	if runner.Resource() != id {
+		t.Fatalf("Runner has resource %v, expected %v", runner.Resource(), id)
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run workflow: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Workflow failed: %v", err)
+	}
+	if !watcher.Complete() {
+		t.Fatalf("Succeeded workflow isn't complete")
+	}
+	if err := watcher.Err(); err != nil {
+		t.Fatalf("Succeeded workflow has error: %v", err)
+	}
+	// A second run doesn't clash with the first.
+	if _, err := runner.Run(); err != nil {
+		t.Fatalf("Failed to run workflow again: %v", err)
+	}
+	if len(client.workflows) != 2 {
+		t.Fatalf("Expected a workflow per run, got %d", len(client.workflows))
+	}
+}
+
+func TestArgoRunnerFailure(t *testing.T) {
+	setArgoPollInterval(t)
+	for _, phase := range []string{WorkflowFailed, WorkflowError} {
+		t.Run(phase, func(t *testing.T) {
+			client := newMockWorkflowClient(WorkflowRunning, phase)
+			runner := newArgoRunner(client, "job", ArgoRunnerConfig{NumTasks: 1})
+			watcher, err := runner.Run()
+			if err != nil {
+				t.Fatalf("Failed to run workflow: %v", err)
+			}
+			if err := watcher.Wait(); err == nil {
+				t.Fatalf("Expected %s workflow to fail", phase)
+			}
+			if err := watcher.Err(); err == nil {
+				t.Fatalf("Expected %s workflow to have an error", phase)
+			}
+			if !watcher.Complete() {
+				t.Fatalf("%s workflow isn't complete", phase)
+			}
+		})
+	}
+}
+
+func TestArgoRunnerCreateFail(t *testing.T) {
+	client := newMockWorkflowClient(WorkflowSucceeded)
+	client.createErr = errors.New("admission denied")
+	runner := newArgoRunner(client, "job", ArgoRunnerConfig{NumTasks: 1})
+	if _, err := runner.Run(); err == nil {
+		t.Fatalf("Expected a failed create to fail the run")
+	}
+}
+
+func TestArgoRunnerCancel(t *testing.T) {
+	setArgoPollInterval(t)
+	client := newMockWorkflowClient(WorkflowRunning)
+	runner := newArgoRunner(client, "job", ArgoRunnerConfig{NumTasks: 1})
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run workflow: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WaitWithContext(ctx, watcher); !errors.Is(err, ErrJobCancelled) {
+		t.Fatalf("Expected cancelled wait, got %v", err)
+	}
+	if len(client.deleted) != 1 || len(client.workflows) != 0 {
+		t.Fatalf("Cancelled workflow wasn't deleted")
+	}
+}
+
+func TestArgoWorkflowSpec(t *testing.T) {
+	envVars := map[string]string{"NAME": string(COPY_TO_ONLINE)}
+	spec := newWorkflowSpec("job", ArgoRunnerConfig{EnvVars: envVars, Image: "worker", NumTasks: 4, Retries: 3, ServiceAccount: "argo", ArchiveLogs: true})
+	if spec.Entrypoint != argoEntrypoint || len(spec.Templates) != 2 {
+		t.Fatalf("Unexpected templates: %+v", spec.Templates)
+	}
+	steps := spec.Templates[0].Steps
+	if len(steps) != 1 || len(steps[0]) != 1 || steps[0][0].WithSequence == nil || steps[0][0].WithSequence.Count != "4" {
+		t.Fatalf("Expected a step per chunk, got %+v", steps)
+	}
+	task := spec.Templates[1]
+	if task.RetryStrategy == nil || task.RetryStrategy.Limit != "3" {
+		t.Fatalf("Expected 3 retries, got %+v", task.RetryStrategy)
+	}
+	if task.ArchiveLocation == nil || !*task.ArchiveLocation.ArchiveLogs {
+		t.Fatalf("Logs aren't archived")
+	}
+	hasIndex := false
+	for _, env := range task.Container.Env {
+		if env.Name == "JOB_COMPLETION_INDEX" {
+			hasIndex = true
+		}
+	}
+	if !hasIndex {
+		t.Fatalf("Chunk task isn't given its index")
+	}
+	if spec.ServiceAccountName != "argo" || len(spec.Volumes) != 1 {
+		t.Fatalf("Unexpected workflow spec: %+v", spec)
+	}
+	single := newWorkflowSpec("job", ArgoRunnerConfig{Image: "worker", NumTasks: 1})
+	if single.Templates[0].Steps[0][0].WithSequence != nil || single.Templates[1].RetryStrategy != nil || single.Templates[1].Inputs != nil {
+		t.Fatalf("Single task workflow has chunk steps or retries: %+v", single)
+	}
+}
+
+func TestArgoWorkflowClientSchedule(t *testing.T) {
+	listKinds := map[schema.GroupVersionResource]string{
+		workflowResource:     "WorkflowList",
+		cronWorkflowResource: "CronWorkflowList",
+	}
+	client := ArgoWorkflowClient{
+		Client:    dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds),
+		Namespace: "default",
+	}
+	runner := newArgoRunner(client, "job", ArgoRunnerConfig{Image: "worker", NumTasks: 1})
+	if err := runner.ScheduleJob("0 * * * *"); err != nil {
+		t.Fatalf("Failed to schedule workflow: %v", err)
+	}
+	// Scheduling again replaces the schedule.
+	if err := runner.ScheduleJob("*/5 * * * *"); err != nil {
+		t.Fatalf("Failed to reschedule workflow: %v", err)
+	}
+	obj, err := client.Client.Resource(cronWorkflowResource).Namespace("default").Get(context.Background(), "job", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to get cron workflow: %v", err)
+	}
+	cronWorkflow := &CronWorkflow{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, cronWorkflow); err != nil {
+		t.Fatalf("Failed to convert cron workflow: %v", err)
+	}
+	if cronWorkflow.Spec.Schedule != "*/5 * * * *" {
+		t.Fatalf("Cron workflow scheduled on %q, expected %q", cronWorkflow.Spec.Schedule, "*/5 * * * *")
+	}
+	if cronWorkflow.Spec.WorkflowSpec.Entrypoint != argoEntrypoint {
+		t.Fatalf("Cron workflow has no workflow spec: %+v", cronWorkflow.Spec)
+	}
+}
@@ -0,0 +1,430 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchtypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// AWSPollInterval is how often the status of a job's containers is checked
+// while waiting for it.
+var AWSPollInterval = 10 * time.Second
+
+// AWSTask is the status of one of the containers a job runs on AWS.
+type AWSTask struct {
+	ID       string
+	Finished bool
+	Failed   bool
+	Reason   string
+}
+
+// AWSJobClient launches the containers of a job on an AWS compute service.
+type AWSJobClient interface {
+	// Submit starts numTasks containers, each with envVars, and returns the
+	// IDs to describe and stop them by.
+	Submit(ctx context.Context, name string, envVars map[string]string, numTasks int32) ([]string, error)
+	Describe(ctx context.Context, ids []string) ([]AWSTask, error)
+	Stop(ctx context.Context, ids []string) error
+}
+
+type AWSRunnerConfig struct {
+	EnvVars  map[string]string
+	Resource metadata.ResourceID
+	NumTasks int32
+}
+
+// AWSRunner runs a job's worker containers on AWS Batch or ECS, for
+// deployments without Kubernetes. The worker image is set in the job or task
+// definition, which AWS doesn't let a run override. Neither service has cron
+// jobs, so scheduled runs are left to the coordinator's scheduler.
+type AWSRunner struct {
+	client   AWSJobClient
+	jobName  string
+	resource metadata.ResourceID
+	envVars  map[string]string
+	numTasks int32
+}
+
+func NewAWSRunner(client AWSJobClient, config AWSRunnerConfig) AWSRunner {
+	return AWSRunner{
+		client:   client,
+		jobName:  awsJobName(GetJobName(config.Resource)),
+		resource: config.Resource,
+		envVars:  config.EnvVars,
+		numTasks: config.NumTasks,
+	}
+}
+
+var awsInvalidNameChars = regexp.MustCompile("[^a-zA-Z0-9_-]")
+
+// awsJobName makes a job name valid for Batch job names and ECS startedBy
+// tags, which only allow letters, numbers, hyphens and underscores.
+func awsJobName(jobName string) string {
+	const maxLength = 128
+	name := awsInvalidNameChars.ReplaceAllString(jobName, "_")
+	if len(name) > maxLength {
+		name = name[:maxLength]
+	}
+	return name
+}
+
+func (a AWSRunner) Resource() metadata.ResourceID {
+	return a.resource
+}
+
+func (a AWSRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (a AWSRunner) Run() (CompletionWatcher, error) {
+	numTasks := a.numTasks
+	if numTasks < 1 {
+		numTasks = 1
+	}
+	ids, err := a.client.Submit(context.Background(), a.jobName, a.envVars, numTasks)
+	if err != nil {
+		return nil, fmt.Errorf("submit job: %w", err)
+	}
+	return AWSCompletionWatcher{client: a.client, ids: ids}, nil
+}
+
+// AWSCompletionWatcher watches the containers of a submitted job, which is
+// complete once all of them have stopped.
+type AWSCompletionWatcher struct {
+	client AWSJobClient
+	ids    []string
+}
+
+func (a AWSCompletionWatcher) describe() ([]AWSTask, error) {
+	return a.client.Describe(context.Background(), a.ids)
+}
+
+func (a AWSCompletionWatcher) Complete() bool {
+	tasks, err := a.describe()
+	if err != nil {
+		return false
+	}
+	return awsTasksFinished(tasks)
+}
+
+func (a AWSCompletionWatcher) String() string {
+	tasks, err := a.describe()
+	if err != nil {
+		return "Could not fetch job status."
+	}
+	finished := 0
+	for _, task := range tasks {
+		if task.Finished {
+			finished++
+		}
+	}
+	return fmt.Sprintf("%d of %d tasks finished", finished, len(tasks))
+}
+
+// Progress is the number of the job's containers that have stopped.
+func (a AWSCompletionWatcher) Progress() Progress {
+	tasks, err := a.describe()
+	if err != nil {
+		return Progress{}
+	}
+	finished := 0
+	for _, task := range tasks {
+		if task.Finished {
+			finished++
+		}
+	}
+	return taskProgress(finished, len(tasks), time.Time{})
+}
+
+func (a AWSCompletionWatcher) Wait() error {
+	for {
+		tasks, err := a.describe()
+		if err != nil {
+			return err
+		}
+		if awsTasksFinished(tasks) {
+			return awsTasksErr(tasks)
+		}
+		time.Sleep(AWSPollInterval)
+	}
+}
+
+func (a AWSCompletionWatcher) Err() error {
+	tasks, err := a.describe()
+	if err != nil {
+		return err
+	}
+	return awsTasksErr(tasks)
+}
+
+// Cancel stops the job's containers, which AWS sends a SIGTERM to first.
+func (a AWSCompletionWatcher) Cancel() error {
+	return a.client.Stop(context.Background(), a.ids)
+}
+
+func awsTasksFinished(tasks []AWSTask) bool {
+	for _, task := range tasks {
+		if !task.Finished {
+			return false
+		}
+	}
+	return len(tasks) > 0
+}
+
+func awsTasksErr(tasks []AWSTask) error {
+	for _, task := range tasks {
+		if task.Failed {
+			return fmt.Errorf("task %s failed: %s", task.ID, task.Reason)
+		}
+	}
+	return nil
+}
+
+type containerEnvVar struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// containerEnvironment returns env vars as the name and value pairs that
+// Batch, ECS and Cloud Run take, sorted so submissions are reproducible.
+func containerEnvironment(envVars map[string]string) []containerEnvVar {
+	environment := make([]containerEnvVar, 0, len(envVars))
+	for name, value := range envVars {
+		environment = append(environment, containerEnvVar{Name: name, Value: value})
+	}
+	sort.Slice(environment, func(i, j int) bool { return environment[i].Name < environment[j].Name })
+	return environment
+}
+
+// awsConfig is what the Batch and ECS clients are made from, with the
+// credentials requests to AWS are signed with outside of providers.
+func awsConfig(region string) aws.Config {
+	return aws.Config{Region: region, Credentials: provider.AWSCredentials()}
+}
+
+// BatchJobClient submits jobs to an AWS Batch job queue. A job of more than
+// one task is an array job, whose children Batch gives their index in
+// AWS_BATCH_JOB_ARRAY_INDEX.
+type BatchJobClient struct {
+	Region        string
+	JobQueue      string
+	JobDefinition string
+	// Retries is how many more attempts Batch makes at a failed container.
+	Retries int32
+	// Endpoint overrides the regional Batch endpoint.
+	Endpoint string
+}
+
+func (b BatchJobClient) client() *batch.Client {
+	return batch.NewFromConfig(awsConfig(b.Region), func(o *batch.Options) {
+		if b.Endpoint != "" {
+			o.EndpointResolver = batch.EndpointResolverFromURL(b.Endpoint)
+		}
+	})
+}
+
+func (b BatchJobClient) Submit(ctx context.Context, name string, envVars map[string]string, numTasks int32) ([]string, error) {
+	environment := containerEnvironment(envVars)
+	overrides := &batchtypes.ContainerOverrides{Environment: make([]batchtypes.KeyValuePair, len(environment))}
+	for i, env := range environment {
+		overrides.Environment[i] = batchtypes.KeyValuePair{Name: aws.String(env.Name), Value: aws.String(env.Value)}
+	}
+	submit := &batch.SubmitJobInput{
+		JobName:            aws.String(name),
+		JobQueue:           aws.String(b.JobQueue),
+		JobDefinition:      aws.String(b.JobDefinition),
+		ContainerOverrides: overrides,
+	}
+	if numTasks > 1 {
+		submit.ArrayProperties = &batchtypes.ArrayProperties{Size: aws.Int32(numTasks)}
+	}
+	if b.Retries > 0 {
+		submit.RetryStrategy = &batchtypes.RetryStrategy{Attempts: aws.Int32(b.Retries + 1)}
+	}
+	job, err := b.client().SubmitJob(ctx, submit)
+	if err != nil {
+		return nil, fmt.Errorf("submit batch job: %w", err)
+	}
+	return []string{aws.ToString(job.JobId)}, nil
+}
+
+// Describe reads the status of the jobs. An array job's status is only
+// final once each of its children has finished.
+func (b BatchJobClient) Describe(ctx context.Context, ids []string) ([]AWSTask, error) {
+	resp, err := b.client().DescribeJobs(ctx, &batch.DescribeJobsInput{Jobs: ids})
+	if err != nil {
+		return nil, fmt.Errorf("describe batch jobs: %w", err)
+	}
+	if len(resp.Jobs) != len(ids) {
+		return nil, fmt.Errorf("described %d of %d batch jobs", len(resp.Jobs), len(ids))
+	}
+	tasks := make([]AWSTask, len(resp.Jobs))
+	for i, job := range resp.Jobs {
+		tasks[i] = AWSTask{
+			ID:       aws.ToString(job.JobId),
+			Finished: job.Status == batchtypes.JobStatusSucceeded || job.Status == batchtypes.JobStatusFailed,
+			Failed:   job.Status == batchtypes.JobStatusFailed,
+			Reason:   aws.ToString(job.StatusReason),
+		}
+	}
+	return tasks, nil
+}
+
+func (b BatchJobClient) Stop(ctx context.Context, ids []string) error {
+	client := b.client()
+	for _, id := range ids {
+		terminate := &batch.TerminateJobInput{JobId: aws.String(id), Reason: aws.String("Cancelled by Featureform")}
+		if _, err := client.TerminateJob(ctx, terminate); err != nil {
+			return fmt.Errorf("terminate batch job %s: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// ECSJobClient runs jobs as ECS Fargate tasks. Each task of a job is run
+// separately, so that it can be given its index in JOB_COMPLETION_INDEX.
+type ECSJobClient struct {
+	Region         string
+	Cluster        string
+	TaskDefinition string
+	// Container is the name of the worker container in the task definition.
+	Container      string
+	Subnets        []string
+	SecurityGroups []string
+	// AssignPublicIP is needed by tasks in public subnets without a NAT
+	// gateway to pull the worker image.
+	AssignPublicIP bool
+	// Endpoint overrides the regional ECS endpoint.
+	Endpoint string
+}
+
+func (e ECSJobClient) client() *ecs.Client {
+	return ecs.NewFromConfig(awsConfig(e.Region), func(o *ecs.Options) {
+		if e.Endpoint != "" {
+			o.EndpointResolver = ecs.EndpointResolverFromURL(e.Endpoint)
+		}
+	})
+}
+
+func (e ECSJobClient) Submit(ctx context.Context, name string, envVars map[string]string, numTasks int32) ([]string, error) {
+	client := e.client()
+	assignPublicIP := ecstypes.AssignPublicIpDisabled
+	if e.AssignPublicIP {
+		assignPublicIP = ecstypes.AssignPublicIpEnabled
+	}
+	var ids []string
+	for i := int32(0); i < numTasks; i++ {
+		taskEnv := envVars
+		if numTasks > 1 {
+			taskEnv = make(map[string]string, len(envVars)+1)
+			for k, v := range envVars {
+				taskEnv[k] = v
+			}
+			taskEnv["JOB_COMPLETION_INDEX"] = strconv.Itoa(int(i))
+		}
+		environment := containerEnvironment(taskEnv)
+		override := ecstypes.ContainerOverride{Name: aws.String(e.Container), Environment: make([]ecstypes.KeyValuePair, len(environment))}
+		for j, env := range environment {
+			override.Environment[j] = ecstypes.KeyValuePair{Name: aws.String(env.Name), Value: aws.String(env.Value)}
+		}
+		run := &ecs.RunTaskInput{
+			Cluster:        aws.String(e.Cluster),
+			TaskDefinition: aws.String(e.TaskDefinition),
+			LaunchType:     ecstypes.LaunchTypeFargate,
+			Count:          aws.Int32(1),
+			StartedBy:      aws.String(name),
+			NetworkConfiguration: &ecstypes.NetworkConfiguration{AwsvpcConfiguration: &ecstypes.AwsVpcConfiguration{
+				Subnets:        e.Subnets,
+				SecurityGroups: e.SecurityGroups,
+				AssignPublicIp: assignPublicIP,
+			}},
+			Overrides: &ecstypes.TaskOverride{ContainerOverrides: []ecstypes.ContainerOverride{override}},
+		}
+		resp, err := client.RunTask(ctx, run)
+		if err != nil {
+			return nil, e.stopStarted(ctx, ids, fmt.Errorf("run ecs task %d: %w", i, err))
+		}
+		if len(resp.Failures) > 0 || len(resp.Tasks) != 1 {
+			err := fmt.Errorf("ecs could not run task %d: %s", i, ecsFailureReason(resp.Failures))
+			return nil, e.stopStarted(ctx, ids, err)
+		}
+		ids = append(ids, aws.ToString(resp.Tasks[0].TaskArn))
+	}
+	return ids, nil
+}
+
+// stopStarted stops the tasks of a job that couldn't be started in full, so
+// that a retry doesn't run them twice.
+func (e ECSJobClient) stopStarted(ctx context.Context, ids []string, err error) error {
+	if stopErr := e.Stop(ctx, ids); stopErr != nil {
+		return fmt.Errorf("%w, and stopping the tasks already started failed: %v", err, stopErr)
+	}
+	return err
+}
+
+func ecsFailureReason(failures []ecstypes.Failure) string {
+	reasons := make([]string, len(failures))
+	for i, failure := range failures {
+		reasons[i] = strings.TrimSpace(aws.ToString(failure.Reason) + " " + aws.ToString(failure.Detail))
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// Describe reads the status of the tasks. A stopped task failed unless the
+// worker container exited cleanly.
+func (e ECSJobClient) Describe(ctx context.Context, ids []string) ([]AWSTask, error) {
+	resp, err := e.client().DescribeTasks(ctx, &ecs.DescribeTasksInput{Cluster: aws.String(e.Cluster), Tasks: ids})
+	if err != nil {
+		return nil, fmt.Errorf("describe ecs tasks: %w", err)
+	}
+	if len(resp.Failures) > 0 {
+		return nil, fmt.Errorf("could not describe ecs tasks: %s", ecsFailureReason(resp.Failures))
+	}
+	tasks := make([]AWSTask, len(resp.Tasks))
+	for i, task := range resp.Tasks {
+		tasks[i] = AWSTask{ID: aws.ToString(task.TaskArn), Finished: aws.ToString(task.LastStatus) == "STOPPED"}
+		if !tasks[i].Finished {
+			continue
+		}
+		stoppedReason := aws.ToString(task.StoppedReason)
+		tasks[i].Failed, tasks[i].Reason = true, stoppedReason
+		for _, container := range task.Containers {
+			if aws.ToString(container.Name) != e.Container {
+				continue
+			}
+			if container.ExitCode != nil && *container.ExitCode == 0 {
+				tasks[i].Failed, tasks[i].Reason = false, ""
+			} else if container.ExitCode != nil {
+				tasks[i].Reason = fmt.Sprintf("%s: exit code %d", stoppedReason, *container.ExitCode)
+			}
+		}
+	}
+	return tasks, nil
+}
+
+func (e ECSJobClient) Stop(ctx context.Context, ids []string) error {
+	client := e.client()
+	for _, id := range ids {
+		stop := &ecs.StopTaskInput{Cluster: aws.String(e.Cluster), Task: aws.String(id), Reason: aws.String("Cancelled by Featureform")}
+		if _, err := client.StopTask(ctx, stop); err != nil {
+			return fmt.Errorf("stop ecs task %s: %w", id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// mockAWSJobClient finishes each task it submits after one describe.
+type mockAWSJobClient struct {
+	mtx       sync.Mutex
+	submitted map[string]map[string]string
+	describes int
+	failed    bool
+	stopped   []string
+}
+
+func (m *mockAWSJobClient) Submit(ctx context.Context, name string, envVars map[string]string, numTasks int32) ([]string, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.submitted = map[string]map[string]string{name: envVars}
+	return []string{name + "-0"}, nil
+}
+
+func (m *mockAWSJobClient) Describe(ctx context.Context, ids []string) ([]AWSTask, error) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.describes++
+	finished := m.describes > 1
+	return []AWSTask{{ID: ids[0], Finished: finished, Failed: finished && m.failed, Reason: "Essential container exited"}}, nil
+}
+
+func (m *mockAWSJobClient) Stop(ctx context.Context, ids []string) error {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+	m.stopped = append(m.stopped, ids...)
+	return nil
+}
+
+func setAWSPollInterval(t *testing.T) {
+	interval := AWSPollInterval
+	AWSPollInterval = time.Millisecond
+	t.Cleanup(func() { AWSPollInterval = interval })
+}
+
+func TestAWSRunner(t *testing.T) {
+	setAWSPollInterval(t)
+	client := &mockAWSJobClient{}
+	id := metadata.ResourceID{Name: "avg_txn", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	runner := NewAWSRunner(client, AWSRunnerConfig{EnvVars: map[string]string{"NAME": string(MATERIALIZE)}, Resource: id, NumTasks: 1})
+	if runner.Resource() != id {
+		t.Fatalf("Runner has resource %v, expected %v", runner.Resource(), id)
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+	if !watcher.Complete() {
+		t.Fatalf("Succeeded job isn't complete")
+	}
+	if _, has := client.submitted["avg_txn-v1-4"]; !has {
+		t.Fatalf("Job submitted with an invalid name: %v", client.submitted)
+	}
+	client.describes, client.failed = 0, true
+	watcher, err = runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	if err := watcher.Wait(); err == nil || !strings.Contains(err.Error(), "Essential container exited") {
+		t.Fatalf("Expected job to fail with its reason, got %v", err)
+	}
+}
+
+func TestAWSRunnerCancel(t *testing.T) {
+	setAWSPollInterval(t)
+	client := &mockAWSJobClient{}
+	watcher, err := NewAWSRunner(client, AWSRunnerConfig{NumTasks: 1}).Run()
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WaitWithContext(ctx, watcher); !errors.Is(err, ErrJobCancelled) {
+		t.Fatalf("Expected cancelled wait, got %v", err)
+	}
+	if len(client.stopped) != 1 {
+		t.Fatalf("Cancelled job wasn't stopped")
+	}
+}
+
+// awsTestServer records the requests it's sent, by the path or target that
+// selects their action, and answers them from responses.
+func awsTestServer(t *testing.T, responses map[string]string) (*httptest.Server, map[string][]map[string]interface{}) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	var mtx sync.Mutex
+	requests := make(map[string][]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		action := r.URL.Path
+		if target := r.Header.Get("X-Amz-Target"); target != "" {
+			action = strings.TrimPrefix(target, "AmazonEC2ContainerServiceV20141113.")
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mtx.Lock()
+		requests[action] = append(requests[action], body)
+		mtx.Unlock()
+		resp, has := responses[action]
+		if !has {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(resp))
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func TestBatchJobClient(t *testing.T) {
+	server, requests := awsTestServer(t, map[string]string{
+		"/v1/submitjob":    `{"jobId": "job-1", "jobName": "job"}`,
+		"/v1/describejobs": `{"jobs": [{"jobId": "job-1", "status": "FAILED", "statusReason": "Array Child Job failed"}]}`,
+		"/v1/terminatejob": `{}`,
+	})
+	client := BatchJobClient{Region: "us-east-1", JobQueue: "queue", JobDefinition: "worker:3", Retries: 2, Endpoint: server.URL}
+	ctx := context.Background()
+	ids, err := client.Submit(ctx, "job", map[string]string{"NAME": "job"}, 4)
+	if err != nil {
+		t.Fatalf("Failed to submit job: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "job-1" {
+		t.Fatalf("Submitted job has IDs %v", ids)
+	}
+	submit := requests["/v1/submitjob"][0]
+	if submit["jobQueue"] != "queue" || submit["jobDefinition"] != "worker:3" {
+		t.Fatalf("Job submitted to the wrong queue or definition: %v", submit)
+	}
+	if submit["arrayProperties"].(map[string]interface{})["size"] != 4.0 {
+		t.Fatalf("Job of 4 tasks isn't an array job: %v", submit)
+	}
+	if submit["retryStrategy"].(map[string]interface{})["attempts"] != 3.0 {
+		t.Fatalf("Job isn't retried twice: %v", submit)
+	}
+	tasks, err := client.Describe(ctx, ids)
+	if err != nil {
+		t.Fatalf("Failed to describe job: %v", err)
+	}
+	if len(tasks) != 1 || !tasks[0].Finished || !tasks[0].Failed || tasks[0].Reason != "Array Child Job failed" {
+		t.Fatalf("Unexpected job status: %+v", tasks)
+	}
+	if err := client.Stop(ctx, ids); err != nil {
+		t.Fatalf("Failed to stop job: %v", err)
+	}
+	if requests["/v1/terminatejob"][0]["jobId"] != "job-1" {
+		t.Fatalf("Wrong job terminated: %v", requests["/v1/terminatejob"])
+	}
+}
+
+func TestECSJobClient(t *testing.T) {
+	server, requests := awsTestServer(t, map[string]string{
+		"RunTask": `{"tasks": [{"taskArn": "arn:task"}]}`,
+		"DescribeTasks": `{"tasks": [
+			{"taskArn": "arn:task", "lastStatus": "STOPPED", "stoppedReason": "Essential container in task exited", "containers": [{"name": "worker", "exitCode": 0}]},
+			{"taskArn": "arn:task", "lastStatus": "STOPPED", "stoppedReason": "Essential container in task exited", "containers": [{"name": "worker", "exitCode": 1}]},
+			{"taskArn": "arn:task", "lastStatus": "RUNNING", "containers": [{"name": "worker"}]}
+		]}`,
+	})
+	client := ECSJobClient{
+		Region:         "us-east-1",
+		Cluster:        "featureform",
+		TaskDefinition: "worker:3",
+		Container:      "worker",
+		Subnets:        []string{"subnet-1"},
+		Endpoint:       server.URL,
+	}
+	ctx := context.Background()
+	ids, err := client.Submit(ctx, "job", map[string]string{"NAME": "job"}, 2)
+	if err != nil {
+		t.Fatalf("Failed to run tasks: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("Expected a task per chunk, got %v", ids)
+	}
+	for i, run := range requests["RunTask"] {
+		if run["launchType"] != "FARGATE" || run["cluster"] != "featureform" {
+			t.Fatalf("Task run with unexpected settings: %v", run)
+		}
+		override := run["overrides"].(map[string]interface{})["containerOverrides"].([]interface{})[0].(map[string]interface{})
+		hasIndex := false
+		for _, env := range override["environment"].([]interface{}) {
+			env := env.(map[string]interface{})
+			if env["name"] == "JOB_COMPLETION_INDEX" && env["value"] == []string{"0", "1"}[i] {
+				hasIndex = true
+			}
+		}
+		if override["name"] != "worker" || !hasIndex {
+			t.Fatalf("Task %d isn't given its index: %v", i, override)
+		}
+	}
+	tasks, err := client.Describe(ctx, []string{"arn:task", "arn:task", "arn:task"})
+	if err != nil {
+		t.Fatalf("Failed to describe tasks: %v", err)
+	}
+	if !tasks[0].Finished || tasks[0].Failed {
+		t.Fatalf("Task that exited cleanly failed: %+v", tasks[0])
+	}
+	if !tasks[1].Finished || !tasks[1].Failed || !strings.Contains(tasks[1].Reason, "exit code 1") {
+		t.Fatalf("Task that exited with an error didn't fail: %+v", tasks[1])
+	}
+	if tasks[2].Finished {
+		t.Fatalf("Running task is finished: %+v", tasks[2])
+	}
+}
+
+func TestECSJobClientRunFailure(t *testing.T) {
+	server, requests := awsTestServer(t, map[string]string{
+		"RunTask":  `{"failures": [{"arn": "arn:cluster", "reason": "RESOURCE:MEMORY"}]}`,
+		"StopTask": `{}`,
+	})
+	client := ECSJobClient{Region: "us-east-1", Cluster: "featureform", Container: "worker", Subnets: []string{"subnet-1"}, Endpoint: server.URL}
+	_, err := client.Submit(context.Background(), "job", nil, 1)
+	if err == nil || !strings.Contains(err.Error(), "RESOURCE:MEMORY") {
+		t.Fatalf("Expected the failure reason, got %v", err)
+	}
+	if len(requests["StopTask"]) != 0 {
+		t.Fatalf("Stopped tasks that weren't started")
+	}
+}
+
+func TestAWSJobName(t *testing.T) {
+	if got := awsJobName("avg.txn-v1-1"); got != "avg_txn-v1-1" {
+		t.Fatalf("Job name is %s", got)
+	}
+	if got := awsJobName(strings.Repeat("a", 200)); len(got) != 128 {
+		t.Fatalf("Job name is %d characters long", len(got))
+	}
+}
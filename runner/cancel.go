@@ -0,0 +1,60 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrJobCancelled is returned when a job is stopped by its context before it
+// completes.
+var ErrJobCancelled = errors.New("job cancelled")
+
+// ContextRunner is implemented by runners that stop their work when the
+// context they're run with is cancelled.
+type ContextRunner interface {
+	Runner
+	RunWithContext(ctx context.Context) (CompletionWatcher, error)
+}
+
+// CancellableWatcher is implemented by completion watchers that can stop the
+// job they're watching, such as a job running outside of this process.
+type CancellableWatcher interface {
+	CompletionWatcher
+	Cancel() error
+}
+
+// RunWithContext runs r, passing it ctx if it supports one.
+func RunWithContext(ctx context.Context, r Runner) (CompletionWatcher, error) {
+	if ctxRunner, ok := r.(ContextRunner); ok {
+		return ctxRunner.RunWithContext(ctx)
+	}
+	return r.Run()
+}
+
+// WaitWithContext waits for a job to complete. If ctx is cancelled first, the
+// job is cancelled if its watcher supports it and ErrJobCancelled is returned.
+func WaitWithContext(ctx context.Context, watcher CompletionWatcher) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- watcher.Wait()
+	}()
+	select {
+	case err := <-done:
+		if err != nil && ctx.Err() != nil {
+			return fmt.Errorf("%w: %v", ErrJobCancelled, err)
+		}
+		return err
+	case <-ctx.Done():
+		if cancellable, ok := watcher.(CancellableWatcher); ok {
+			if err := cancellable.Cancel(); err != nil {
+				return fmt.Errorf("%w, but stopping it failed: %v", ErrJobCancelled, err)
+			}
+		}
+		return ErrJobCancelled
+	}
+}
@@ -0,0 +1,68 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/featureform/provider"
+)
+
+type blockingWatcher struct {
+	*SyncWatcher
+	cancelled bool
+}
+
+func newBlockingWatcher() *blockingWatcher {
+	return &blockingWatcher{
+		SyncWatcher: &SyncWatcher{
+			ResultSync:  &ResultSync{},
+			DoneChannel: make(chan interface{}),
+		},
+	}
+}
+
+func (w *blockingWatcher) Cancel() error {
+	w.cancelled = true
+	w.EndWatch(errors.New("stopped"))
+	return nil
+}
+
+func TestWaitWithContext(t *testing.T) {
+	watcher := newBlockingWatcher()
+	watcher.EndWatch(nil)
+	if err := WaitWithContext(context.Background(), watcher); err != nil {
+		t.Fatalf("Wait on completed job failed: %v", err)
+	}
+	watcher = newBlockingWatcher()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WaitWithContext(ctx, watcher); !errors.Is(err, ErrJobCancelled) {
+		t.Fatalf("Expected job to be cancelled, got %v", err)
+	}
+	if !watcher.cancelled {
+		t.Fatalf("Cancelled job wasn't stopped")
+	}
+}
+
+func TestChunkRunnerCancelled(t *testing.T) {
+	rows := []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}}
+	runner := &MaterializedChunkRunner{
+		Materialized: &MockMaterializedFeatures{Rows: rows},
+		Table:        &MockOnlineTable{DataTable: map[string]interface{}{}},
+		ChunkSize:    2,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	watcher, err := RunWithContext(ctx, runner)
+	if err != nil {
+		t.Fatalf("Failed to run chunk runner: %v", err)
+	}
+	if err := watcher.Wait(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected cancelled chunk copy to fail, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/featureform/provider"
+	"github.com/google/uuid"
+)
+
+// Chunk checkpoints are kept in the online store next to the watermarks, so
+// a chunk job that's restarted after it was killed can tell whether the rows
+// it copies are already there.
+const (
+	checkpointTableName    = "featureform_checkpoints"
+	checkpointTableVariant = "chunks"
+)
+
+// checkpointKey is the same for every run that copies a chunk of a
+// materialization, so the table holds one checkpoint per chunk rather than
+// one per run. The value is the ID of the run that last finished the chunk.
+func checkpointKey(id provider.MaterializationID, chunk int64) string {
+	return fmt.Sprintf("%s__%d", id, chunk)
+}
+
+// chunkCompleted returns whether the run with runID has already copied the
+// chunk of the materialization.
+func chunkCompleted(store provider.OnlineStore, id provider.MaterializationID, chunk int64, runID string) (bool, error) {
+	table, err := store.GetTable(checkpointTableName, checkpointTableVariant)
+	if _, notFound := err.(*provider.TableNotFound); notFound {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("get checkpoint table: %w", err)
+	}
+	value, err := table.Get(checkpointKey(id, chunk))
+	if _, notFound := err.(*provider.EntityNotFound); notFound || value == nil {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("get checkpoint: %w", err)
+	}
+	return fmt.Sprint(value) == runID, nil
+}
+
+func setChunkCompleted(store provider.OnlineStore, id provider.MaterializationID, chunk int64, runID string) error {
+	table, err := store.CreateTable(checkpointTableName, checkpointTableVariant, provider.String)
+	if _, exists := err.(*provider.TableAlreadyExists); exists {
+		table, err = store.GetTable(checkpointTableName, checkpointTableVariant)
+	}
+	if err != nil {
+		return fmt.Errorf("get checkpoint table: %w", err)
+	}
+	return table.Set(checkpointKey(id, chunk), runID)
+}
+
+// chunkRunID is the ID that the chunk jobs of a materialization checkpoint
+// with. It's derived from the materialization and the lineage of the job
+// that made it, so that every attempt at the job shares it. Runs without a
+// lineage get an ID of their own.
+func chunkRunID(id provider.MaterializationID, lineage string) string {
+	if lineage == "" {
+		return uuid.New().String()
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s", id, lineage)))
+	return hex.EncodeToString(sum[:16])
+}
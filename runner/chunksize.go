@@ -0,0 +1,157 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/featureform/provider"
+)
+
+const (
+	DefaultChunkTargetBytes    int64 = 32 << 20
+	DefaultChunkTargetDuration       = 5 * time.Minute
+	DefaultChunkMinRows        int64 = 1024
+	DefaultChunkMaxRows        int64 = 1 << 20
+)
+
+// chunkSampleRows is how many rows of a materialization are read to
+// estimate how wide its rows are.
+const chunkSampleRows int64 = 100
+
+// ChunkSizing decides how many rows each chunk job of a materialization
+// copies. Unless Rows fixes it, a chunk is TargetBytes of rows, going by a
+// sample of the materialization, and no more rows than the online store was
+// last measured writing in TargetDuration. Narrow tables are copied in a few
+// large chunks instead of many small ones, which saves starting a job for
+// every thousand rows.
+type ChunkSizing struct {
+	Rows           int64         `json:",omitempty"`
+	TargetBytes    int64         `json:",omitempty"`
+	TargetDuration time.Duration `json:",omitempty"`
+	MinRows        int64         `json:",omitempty"`
+	MaxRows        int64         `json:",omitempty"`
+}
+
+func (s *ChunkSizing) withDefaults() ChunkSizing {
+	sizing := ChunkSizing{}
+	if s != nil {
+		sizing = *s
+	}
+	if sizing.TargetBytes <= 0 {
+		sizing.TargetBytes = DefaultChunkTargetBytes
+	}
+	if sizing.TargetDuration <= 0 {
+		sizing.TargetDuration = DefaultChunkTargetDuration
+	}
+	if sizing.MinRows <= 0 {
+		sizing.MinRows = DefaultChunkMinRows
+	}
+	if sizing.MaxRows <= 0 {
+		sizing.MaxRows = DefaultChunkMaxRows
+	}
+	if sizing.MaxRows < sizing.MinRows {
+		sizing.MaxRows = sizing.MinRows
+	}
+	return sizing
+}
+
+// chunkRows returns the number of rows in a chunk, for rows that are
+// rowBytes wide on average and a store that writes rowsPerSecond. Either
+// can be zero if it isn't known.
+func (s *ChunkSizing) chunkRows(rowBytes int64, rowsPerSecond float64) int64 {
+	if s != nil && s.Rows > 0 {
+		return s.Rows
+	}
+	sizing := s.withDefaults()
+	rows := sizing.MaxRows
+	if rowBytes > 0 && sizing.TargetBytes/rowBytes < rows {
+		rows = sizing.TargetBytes / rowBytes
+	}
+	if rowsPerSecond > 0 {
+		if measured := int64(rowsPerSecond * sizing.TargetDuration.Seconds()); measured < rows {
+			rows = measured
+		}
+	}
+	if rows < sizing.MinRows {
+		rows = sizing.MinRows
+	}
+	return rows
+}
+
+// estimateRowBytes returns the average width of the first rows of a
+// materialization, as the length of their entities and printed values.
+func estimateRowBytes(materialization provider.Materialization, numRows int64) (int64, error) {
+	sample := chunkSampleRows
+	if numRows < sample {
+		sample = numRows
+	}
+	if sample == 0 {
+		return 0, nil
+	}
+	it, err := materialization.IterateSegment(0, sample)
+	if err != nil {
+		return 0, err
+	}
+	var rows, bytes int64
+	for it.Next() {
+		rec := it.Value()
+		rows++
+		bytes += int64(len(rec.Entity) + len(fmt.Sprint(rec.Value)))
+	}
+	if err := it.Err(); err != nil {
+		return 0, err
+	}
+	if rows == 0 {
+		return 0, nil
+	}
+	return bytes / rows, nil
+}
+
+// Write throughput is kept in the online store next to the watermarks, by
+// the resource that was copied, since it depends on both the store and the
+// width of the resource's values.
+const (
+	throughputTableName    = "featureform_throughput"
+	throughputTableVariant = "chunks"
+)
+
+// getThroughput returns the rows per second that the last chunk of id was
+// copied at, or zero if nothing has been recorded.
+func getThroughput(store provider.OnlineStore, id provider.ResourceID) (float64, error) {
+	table, err := store.GetTable(throughputTableName, throughputTableVariant)
+	if _, notFound := err.(*provider.TableNotFound); notFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("get throughput table: %w", err)
+	}
+	value, err := table.Get(watermarkKey(id))
+	if _, notFound := err.(*provider.EntityNotFound); notFound || value == nil {
+		return 0, nil
+	} else if err != nil {
+		return 0, fmt.Errorf("get throughput: %w", err)
+	}
+	throughput, err := strconv.ParseFloat(fmt.Sprint(value), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid throughput %v: %w", value, err)
+	}
+	return throughput, nil
+}
+
+func setThroughput(store provider.OnlineStore, id provider.ResourceID, rows int64, elapsed time.Duration) error {
+	if rows == 0 || elapsed <= 0 {
+		return nil
+	}
+	table, err := store.CreateTable(throughputTableName, throughputTableVariant, provider.String)
+	if _, exists := err.(*provider.TableAlreadyExists); exists {
+		table, err = store.GetTable(throughputTableName, throughputTableVariant)
+	}
+	if err != nil {
+		return fmt.Errorf("get throughput table: %w", err)
+	}
+	return table.Set(watermarkKey(id), strconv.FormatFloat(float64(rows)/elapsed.Seconds(), 'f', -1, 64))
+}
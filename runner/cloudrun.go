@@ -0,0 +1,394 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// CloudRunPollInterval is how often an execution's status is checked while
+// waiting for it.
+var CloudRunPollInterval = 10 * time.Second
+
+var (
+	cloudRunEndpoint   = "https://run.googleapis.com"
+	cloudRunToken      = provider.GCPAccessToken
+	cloudRunHTTPClient = &http.Client{Timeout: 30 * time.Second}
+)
+
+// CloudRunResources are the resource limits of a job's containers, in
+// Kubernetes quantities such as "2" CPUs and "4Gi" of memory. Cloud Run's
+// defaults are used for limits that aren't set.
+type CloudRunResources struct {
+	CPU    string
+	Memory string
+}
+
+type CloudRunRunnerConfig struct {
+	EnvVars   map[string]string
+	Resource  metadata.ResourceID
+	Image     string
+	NumTasks  int32
+	Resources CloudRunResources
+	// Retries is how many times Cloud Run retries a failed task.
+	Retries        int32
+	ServiceAccount string
+	// Timeout is how long each task can run for. Cloud Run stops tasks
+	// after 10 minutes if it isn't set.
+	Timeout time.Duration
+}
+
+// CloudRunClient manages Cloud Run jobs in a project's region.
+type CloudRunClient struct {
+	Project string
+	Region  string
+}
+
+// CloudRunRunner runs a job as a Cloud Run job. Each Featureform job gets
+// a Cloud Run job of its own, which is updated to the current config before
+// every run, so Cloud Run keeps the history of the job's executions. Cloud
+// Run jobs can't be scheduled without Cloud Scheduler, so scheduled runs are
+// left to the coordinator's scheduler.
+type CloudRunRunner struct {
+	client   CloudRunClient
+	jobID    string
+	resource metadata.ResourceID
+	job      cloudRunJob
+}
+
+func NewCloudRunRunner(client CloudRunClient, config CloudRunRunnerConfig) CloudRunRunner {
+	jobName := GetJobName(config.Resource)
+	return CloudRunRunner{
+		client:   client,
+		jobID:    cloudRunJobID(jobName),
+		resource: config.Resource,
+		job:      newCloudRunJob(jobName, config),
+	}
+}
+
+var cloudRunInvalidIDChars = regexp.MustCompile("[^a-z0-9-]+")
+
+// cloudRunJobID makes a job name valid as a Cloud Run job ID, which has to
+// start with a letter and be at most 63 lowercase letters, numbers and
+// hyphens long. Long names are shortened with a hash, so they don't clash.
+func cloudRunJobID(jobName string) string {
+	const maxLength = 63
+	id := "ff-" + strings.Trim(cloudRunInvalidIDChars.ReplaceAllString(strings.ToLower(jobName), "-"), "-")
+	if len(id) <= maxLength {
+		return id
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(jobName))
+	return fmt.Sprintf("%s-%08x", strings.TrimRight(id[:maxLength-9], "-"), hash.Sum32())
+}
+
+type cloudRunJob struct {
+	Labels   map[string]string         `json:"labels,omitempty"`
+	Template cloudRunExecutionTemplate `json:"template"`
+}
+
+type cloudRunExecutionTemplate struct {
+	TaskCount int32                `json:"taskCount"`
+	Template  cloudRunTaskTemplate `json:"template"`
+}
+
+type cloudRunTaskTemplate struct {
+	Containers     []cloudRunContainer `json:"containers"`
+	MaxRetries     int32               `json:"maxRetries"`
+	Timeout        string              `json:"timeout,omitempty"`
+	ServiceAccount string              `json:"serviceAccount,omitempty"`
+}
+
+type cloudRunContainer struct {
+	Image     string                        `json:"image"`
+	Env       []containerEnvVar             `json:"env,omitempty"`
+	Resources *cloudRunResourceRequirements `json:"resources,omitempty"`
+}
+
+type cloudRunResourceRequirements struct {
+	Limits map[string]string `json:"limits"`
+}
+
+func newCloudRunJob(jobName string, config CloudRunRunnerConfig) cloudRunJob {
+	numTasks := config.NumTasks
+	if numTasks < 1 {
+		numTasks = 1
+	}
+	container := cloudRunContainer{
+		Image: config.Image,
+		// Cloud Run gives each task its index in CLOUD_RUN_TASK_INDEX, which
+		// the worker reads for jobs of more than one task.
+		Env: containerEnvironment(config.EnvVars),
+	}
+	limits := make(map[string]string)
+	if config.Resources.CPU != "" {
+		limits["cpu"] = config.Resources.CPU
+	}
+	if config.Resources.Memory != "" {
+		limits["memory"] = config.Resources.Memory
+	}
+	if len(limits) > 0 {
+		container.Resources = &cloudRunResourceRequirements{Limits: limits}
+	}
+	task := cloudRunTaskTemplate{
+		Containers:     []cloudRunContainer{container},
+		MaxRetries:     config.Retries,
+		ServiceAccount: config.ServiceAccount,
+	}
+	if config.Timeout > 0 {
+		task.Timeout = fmt.Sprintf("%ds", int64(config.Timeout.Seconds()))
+	}
+	return cloudRunJob{
+		Labels:   map[string]string{jobLabel: cloudRunJobID(jobName)},
+		Template: cloudRunExecutionTemplate{TaskCount: numTasks, Template: task},
+	}
+}
+
+func (c CloudRunRunner) Resource() metadata.ResourceID {
+	return c.resource
+}
+
+func (c CloudRunRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (c CloudRunRunner) Run() (CompletionWatcher, error) {
+	ctx := context.Background()
+	if err := c.client.setJob(ctx, c.jobID, c.job); err != nil {
+		return nil, fmt.Errorf("set cloud run job: %w", err)
+	}
+	execution, err := c.client.runJob(ctx, c.jobID)
+	if err != nil {
+		return nil, fmt.Errorf("run cloud run job: %w", err)
+	}
+	return CloudRunCompletionWatcher{client: c.client, name: execution}, nil
+}
+
+// CloudRunCompletionWatcher watches an execution of a Cloud Run job.
+type CloudRunCompletionWatcher struct {
+	client CloudRunClient
+	name   string
+}
+
+func (c CloudRunCompletionWatcher) Complete() bool {
+	execution, err := c.client.getExecution(context.Background(), c.name)
+	if err != nil {
+		return false
+	}
+	return execution.finished()
+}
+
+func (c CloudRunCompletionWatcher) String() string {
+	execution, err := c.client.getExecution(context.Background(), c.name)
+	if err != nil {
+		return "Could not fetch execution."
+	}
+	return fmt.Sprintf("Execution %s: %d of %d tasks succeeded, %d failed", c.name, execution.SucceededCount, execution.TaskCount, execution.FailedCount)
+}
+
+// Progress is the number of the execution's tasks that have succeeded.
+func (c CloudRunCompletionWatcher) Progress() Progress {
+	execution, err := c.client.getExecution(context.Background(), c.name)
+	if err != nil {
+		return Progress{}
+	}
+	return taskProgress(int(execution.SucceededCount), int(execution.TaskCount), time.Time{})
+}
+
+func (c CloudRunCompletionWatcher) Wait() error {
+	for {
+		execution, err := c.client.getExecution(context.Background(), c.name)
+		if err != nil {
+			return err
+		}
+		if execution.finished() {
+			return execution.err()
+		}
+		time.Sleep(CloudRunPollInterval)
+	}
+}
+
+func (c CloudRunCompletionWatcher) Err() error {
+	execution, err := c.client.getExecution(context.Background(), c.name)
+	if err != nil {
+		return err
+	}
+	return execution.err()
+}
+
+// Cancel cancels the execution, which stops its running tasks.
+func (c CloudRunCompletionWatcher) Cancel() error {
+	return c.client.cancelExecution(context.Background(), c.name)
+}
+
+type cloudRunExecution struct {
+	Name           string              `json:"name"`
+	TaskCount      int32               `json:"taskCount"`
+	SucceededCount int32               `json:"succeededCount"`
+	FailedCount    int32               `json:"failedCount"`
+	CancelledCount int32               `json:"cancelledCount"`
+	CompletionTime string              `json:"completionTime"`
+	Conditions     []cloudRunCondition `json:"conditions"`
+}
+
+type cloudRunCondition struct {
+	Type    string `json:"type"`
+	State   string `json:"state"`
+	Message string `json:"message"`
+}
+
+func (e *cloudRunExecution) finished() bool {
+	return e.CompletionTime != ""
+}
+
+func (e *cloudRunExecution) err() error {
+	if e.FailedCount == 0 && e.CancelledCount == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("%d of %d tasks failed", e.FailedCount+e.CancelledCount, e.TaskCount)
+	for _, condition := range e.Conditions {
+		if condition.Type == "Completed" && condition.Message != "" {
+			message = condition.Message
+		}
+	}
+	return fmt.Errorf("execution %s failed: %s", e.Name, message)
+}
+
+type cloudRunOperation struct {
+	Name     string          `json:"name"`
+	Done     bool            `json:"done"`
+	Error    *cloudRunStatus `json:"error"`
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+type cloudRunStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// cloudRunError is an error response from the Cloud Run API.
+type cloudRunError struct {
+	StatusCode int
+	Message    string
+}
+
+func (err *cloudRunError) Error() string {
+	return fmt.Sprintf("cloud run request failed: %d: %s", err.StatusCode, err.Message)
+}
+
+func (c CloudRunClient) url(path string) string {
+	return fmt.Sprintf("%s/v2/%s", cloudRunEndpoint, path)
+}
+
+func (c CloudRunClient) jobsPath() string {
+	return fmt.Sprintf("projects/%s/locations/%s/jobs", c.Project, c.Region)
+}
+
+func (c CloudRunClient) request(ctx context.Context, method, url string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	token, err := cloudRunToken(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := cloudRunHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &cloudRunError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(respBody))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("invalid cloud run response: %w", err)
+	}
+	return nil
+}
+
+// setJob creates the job, or replaces the config of the one that already
+// exists, and waits for Cloud Run to finish doing so.
+func (c CloudRunClient) setJob(ctx context.Context, jobID string, job cloudRunJob) error {
+	op := &cloudRunOperation{}
+	err := c.request(ctx, http.MethodPost, c.url(c.jobsPath())+"?jobId="+jobID, job, op)
+	var apiErr *cloudRunError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+		err = c.request(ctx, http.MethodPatch, c.url(c.jobsPath()+"/"+jobID), job, op)
+	}
+	if err != nil {
+		return err
+	}
+	return c.waitOperation(ctx, op)
+}
+
+func (c CloudRunClient) waitOperation(ctx context.Context, op *cloudRunOperation) error {
+	for !op.Done {
+		time.Sleep(CloudRunPollInterval)
+		if err := c.request(ctx, http.MethodGet, c.url(op.Name), nil, op); err != nil {
+			return err
+		}
+	}
+	if op.Error != nil {
+		return fmt.Errorf("cloud run operation %s failed: %s", op.Name, op.Error.Message)
+	}
+	return nil
+}
+
+// runJob starts an execution of the job and returns its name. The operation
+// it starts is only done once the execution is, so it isn't waited for.
+func (c CloudRunClient) runJob(ctx context.Context, jobID string) (string, error) {
+	op := &cloudRunOperation{}
+	if err := c.request(ctx, http.MethodPost, c.url(c.jobsPath()+"/"+jobID+":run"), struct{}{}, op); err != nil {
+		return "", err
+	}
+	var execution cloudRunExecution
+	if err := json.Unmarshal(op.Metadata, &execution); err != nil || execution.Name == "" {
+		return "", fmt.Errorf("cloud run operation %s has no execution", op.Name)
+	}
+	return execution.Name, nil
+}
+
+func (c CloudRunClient) getExecution(ctx context.Context, name string) (*cloudRunExecution, error) {
+	execution := &cloudRunExecution{}
+	if err := c.request(ctx, http.MethodGet, c.url(name), nil, execution); err != nil {
+		return nil, err
+	}
+	return execution, nil
+}
+
+func (c CloudRunClient) cancelExecution(ctx context.Context, name string) error {
+	return c.request(ctx, http.MethodPost, c.url(name+":cancel"), struct{}{}, nil)
+}
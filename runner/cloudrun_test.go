@@ -0,0 +1,178 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// cloudRunTestServer is a Cloud Run API with one job, whose executions
+// finish after being read a couple of times.
+type cloudRunTestServer struct {
+	mtx       sync.Mutex
+	job       *cloudRunJob
+	patched   bool
+	reads     int
+	failed    bool
+	cancelled []string
+}
+
+func (s *cloudRunTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	jobs := "/v2/projects/project/locations/us-central1/jobs"
+	execution := jobs + "/job/executions/job-1"
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == jobs:
+		if s.job != nil {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.job = &cloudRunJob{}
+		json.NewDecoder(r.Body).Decode(s.job)
+		fmt.Fprint(w, `{"name": "operations/create", "done": false}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/v2/operations/create":
+		fmt.Fprint(w, `{"name": "operations/create", "done": true}`)
+	case r.Method == http.MethodPatch && r.URL.Path == jobs+"/job":
+		s.patched = true
+		json.NewDecoder(r.Body).Decode(s.job)
+		fmt.Fprint(w, `{"name": "operations/update", "done": true}`)
+	case r.Method == http.MethodPost && r.URL.Path == jobs+"/job:run":
+		s.reads = 0
+		fmt.Fprintf(w, `{"name": "operations/run", "metadata": {"name": %q}}`, strings.TrimPrefix(execution, "/v2/"))
+	case r.Method == http.MethodGet && r.URL.Path == execution:
+		s.reads++
+		if s.reads < 2 {
+			fmt.Fprint(w, `{"name": "job-1", "taskCount": 1, "runningCount": 1}`)
+		} else if s.failed {
+			fmt.Fprint(w, `{"name": "job-1", "taskCount": 1, "failedCount": 1, "completionTime": "2022-01-01T00:00:00Z",
+				"conditions": [{"type": "Completed", "state": "CONDITION_FAILED", "message": "Task job-1-0 failed with exit code 1"}]}`)
+		} else {
+			fmt.Fprint(w, `{"name": "job-1", "taskCount": 1, "succeededCount": 1, "completionTime": "2022-01-01T00:00:00Z"}`)
+		}
+	case r.Method == http.MethodPost && r.URL.Path == execution+":cancel":
+		s.cancelled = append(s.cancelled, execution)
+		fmt.Fprint(w, `{"name": "operations/cancel"}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func setupCloudRun(t *testing.T) *cloudRunTestServer {
+	api := &cloudRunTestServer{}
+	server := httptest.NewServer(api)
+	endpoint, token, interval := cloudRunEndpoint, cloudRunToken, CloudRunPollInterval
+	cloudRunEndpoint = server.URL
+	cloudRunToken = func(ctx context.Context) (string, error) { return "token", nil }
+	CloudRunPollInterval = time.Millisecond
+	t.Cleanup(func() {
+		server.Close()
+		cloudRunEndpoint, cloudRunToken, CloudRunPollInterval = endpoint, token, interval
+	})
+	return api
+}
+
+func newTestCloudRunRunner(config CloudRunRunnerConfig) CloudRunRunner {
+	runner := NewCloudRunRunner(CloudRunClient{Project: "project", Region: "us-central1"}, config)
+	runner.jobID = "job"
+	return runner
+}
+
+func TestCloudRunRunner(t *testing.T) {
+	api := setupCloudRun(t)
+	id := metadata.ResourceID{Name: "avg_txn", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	runner := newTestCloudRunRunner(CloudRunRunnerConfig{
+		EnvVars:   map[string]string{"NAME": string(MATERIALIZE)},
+		Resource:  id,
+		Image:     "worker",
+		NumTasks:  1,
+		Resources: CloudRunResources{CPU: "4", Memory: "16Gi"},
+	})
+	if runner.Resource() != id {
+		t.Fatalf("Runner has resource %v, expected %v", runner.Resource(), id)
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run job: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Job failed: %v", err)
+	}
+	if !watcher.Complete() {
+		t.Fatalf("Succeeded job isn't complete")
+	}
+	limits := api.job.Template.Template.Containers[0].Resources.Limits
+	if limits["cpu"] != "4" || limits["memory"] != "16Gi" {
+		t.Fatalf("Job created with limits %v", limits)
+	}
+	// Running the job again updates the job that already exists.
+	api.failed = true
+	watcher, err = runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run job again: %v", err)
+	}
+	if !api.patched {
+		t.Fatalf("Existing job wasn't updated")
+	}
+	if err := watcher.Wait(); err == nil || !strings.Contains(err.Error(), "exit code 1") {
+		t.Fatalf("Expected job to fail with its condition, got %v", err)
+	}
+}
+
+func TestCloudRunRunnerCancel(t *testing.T) {
+	api := setupCloudRun(t)
+	watcher, err := newTestCloudRunRunner(CloudRunRunnerConfig{Image: "worker"}).Run()
+	if err != nil {
+		t.Fatalf("Failed to run job: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WaitWithContext(ctx, watcher); !errors.Is(err, ErrJobCancelled) {
+		t.Fatalf("Expected cancelled wait, got %v", err)
+	}
+	if len(api.cancelled) != 1 {
+		t.Fatalf("Cancelled execution wasn't cancelled in Cloud Run")
+	}
+}
+
+func TestCloudRunJob(t *testing.T) {
+	job := newCloudRunJob("job", CloudRunRunnerConfig{Image: "worker", NumTasks: 4, Retries: 2, ServiceAccount: "worker@project", Timeout: time.Hour})
+	if job.Template.TaskCount != 4 {
+		t.Fatalf("Job has %d tasks, expected 4", job.Template.TaskCount)
+	}
+	task := job.Template.Template
+	if task.MaxRetries != 2 || task.Timeout != "3600s" || task.ServiceAccount != "worker@project" {
+		t.Fatalf("Unexpected task template: %+v", task)
+	}
+	if task.Containers[0].Resources != nil {
+		t.Fatalf("Job without limits has resources: %+v", task.Containers[0].Resources)
+	}
+	valid := regexp.MustCompile("^[a-z]([-a-z0-9]*[a-z0-9])?$")
+	for _, name := range []string{"avg.txn-v1-4", "1_Feature-v1-4", strings.Repeat("long.name", 20)} {
+		id := cloudRunJobID(name)
+		if len(id) > 63 || !valid.MatchString(id) {
+			t.Fatalf("Job ID %s of %s is invalid", id, name)
+		}
+	}
+	if cloudRunJobID(strings.Repeat("a", 100)+"1") == cloudRunJobID(strings.Repeat("a", 100)+"2") {
+		t.Fatalf("Long job names clash")
+	}
+}
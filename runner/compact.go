@@ -0,0 +1,152 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// RetentionPolicy decides which past generations of a materialization stay
+// in the warehouse. A generation is compacted once it's no longer among the
+// newest KeepGenerations, or once it's older than MaxAge if MaxAge is set.
+type RetentionPolicy struct {
+	KeepGenerations int
+	MaxAge          time.Duration
+}
+
+func (p RetentionPolicy) expired(gens []provider.MaterializationGeneration, now time.Time) []provider.MaterializationGeneration {
+	sorted := make([]provider.MaterializationGeneration, len(gens))
+	copy(sorted, gens)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Created.After(sorted[j].Created)
+	})
+	expired := make([]provider.MaterializationGeneration, 0)
+	for i, gen := range sorted {
+		tooOld := p.MaxAge > 0 && now.Sub(gen.Created) > p.MaxAge
+		if i >= p.KeepGenerations || tooOld {
+			expired = append(expired, gen)
+		}
+	}
+	return expired
+}
+
+// CompactionRunner removes expired generations of materializations from an
+// offline store. If it has an Archiver, each generation is exported to it
+// before it's dropped.
+type CompactionRunner struct {
+	Offline  provider.OfflineStore
+	Features []provider.ResourceID
+	Policy   RetentionPolicy
+	Archiver provider.Archiver
+}
+
+func (c *CompactionRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (c *CompactionRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (c *CompactionRunner) Run() (CompletionWatcher, error) {
+	store, ok := c.Offline.(provider.GenerationStore)
+	if !ok {
+		return nil, fmt.Errorf("%s offline store does not keep materialization generations", c.Offline.Type())
+	}
+	done := make(chan interface{})
+	jobWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		for _, id := range c.Features {
+			if err := c.compact(store, id); err != nil {
+				jobWatcher.EndWatch(fmt.Errorf("compact %s (%s): %w", id.Name, id.Variant, err))
+				return
+			}
+		}
+		jobWatcher.EndWatch(nil)
+	}()
+	return jobWatcher, nil
+}
+
+func (c *CompactionRunner) compact(store provider.GenerationStore, id provider.ResourceID) error {
+	gens, err := store.MaterializationGenerations(id)
+	if err != nil {
+		return fmt.Errorf("list generations: %w", err)
+	}
+	for _, gen := range c.Policy.expired(gens, time.Now()) {
+		if c.Archiver != nil {
+			mat, err := c.Offline.GetMaterialization(gen.ID)
+			if err != nil {
+				return fmt.Errorf("get generation %s: %w", gen.ID, err)
+			}
+			key := fmt.Sprintf("%s/%s/%s.jsonl.gz", id.Name, id.Variant, gen.ID)
+			if err := provider.ArchiveMaterialization(context.Background(), c.Archiver, key, mat); err != nil {
+				return fmt.Errorf("archive generation %s: %w", gen.ID, err)
+			}
+		}
+		if err := c.Offline.DeleteMaterialization(gen.ID); err != nil {
+			return fmt.Errorf("delete generation %s: %w", gen.ID, err)
+		}
+	}
+	return nil
+}
+
+type CompactionRunnerConfig struct {
+	OfflineType   provider.Type
+	OfflineConfig provider.SerializedConfig
+	Features      []provider.ResourceID
+	Policy        RetentionPolicy
+	// ArchiveURI is where generations are exported before they're dropped,
+	// such as s3://bucket/prefix. If it's empty they're dropped outright.
+	ArchiveURI string
+}
+
+func (c *CompactionRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *CompactionRunnerConfig) Deserialize(config Config) error {
+	return json.Unmarshal(config, c)
+}
+
+func CompactionRunnerFactory(config Config) (Runner, error) {
+	runnerConfig := &CompactionRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize compaction runner config: %v", err)
+	}
+	offlineProvider, err := getProvider(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure offline provider: %v", err)
+	}
+	offlineStore, err := offlineProvider.AsOfflineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to offline store: %v", err)
+	}
+	var archiver provider.Archiver
+	if runnerConfig.ArchiveURI != "" {
+		if archiver, err = provider.NewArchiver(runnerConfig.ArchiveURI); err != nil {
+			return nil, fmt.Errorf("failed to configure archiver: %v", err)
+		}
+	}
+	return &CompactionRunner{
+		Offline:  offlineStore,
+		Features: runnerConfig.Features,
+		Policy:   runnerConfig.Policy,
+		Archiver: archiver,
+	}, nil
+}
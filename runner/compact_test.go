@@ -0,0 +1,113 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/featureform/provider"
+)
+
+func TestRetentionPolicyExpired(t *testing.T) {
+	now := time.Now()
+	gens := []provider.MaterializationGeneration{
+		{ID: "old", Created: now.Add(-3 * time.Hour)},
+		{ID: "newest", Created: now.Add(-time.Minute)},
+		{ID: "middle", Created: now.Add(-2 * time.Hour)},
+	}
+	tests := map[string]struct {
+		Policy   RetentionPolicy
+		Expected []provider.MaterializationID
+	}{
+		"KeepAll":   {RetentionPolicy{KeepGenerations: 3}, []provider.MaterializationID{}},
+		"KeepOne":   {RetentionPolicy{KeepGenerations: 1}, []provider.MaterializationID{"middle", "old"}},
+		"KeepNone":  {RetentionPolicy{}, []provider.MaterializationID{"newest", "middle", "old"}},
+		"MaxAge":    {RetentionPolicy{KeepGenerations: 3, MaxAge: 90 * time.Minute}, []provider.MaterializationID{"middle", "old"}},
+		"CountWins": {RetentionPolicy{KeepGenerations: 1, MaxAge: 24 * time.Hour}, []provider.MaterializationID{"middle", "old"}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			expired := test.Policy.expired(gens, now)
+			if len(expired) != len(test.Expected) {
+				t.Fatalf("Expected %d expired generations, got %v", len(test.Expected), expired)
+			}
+			for i, gen := range expired {
+				if gen.ID != test.Expected[i] {
+					t.Fatalf("Expected generation %s at %d, got %s", test.Expected[i], i, gen.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestCompactionRunner(t *testing.T) {
+	store := provider.NewMemoryOfflineStore()
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	schema := provider.TableSchema{
+		Columns: []provider.TableColumn{
+			{Name: "entity", ValueType: provider.String},
+			{Name: "value", ValueType: provider.Int},
+			{Name: "ts", ValueType: provider.Timestamp},
+		},
+	}
+	table, err := store.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	mats := make([]provider.Materialization, 3)
+	for i := range mats {
+		if err := table.Write(provider.ResourceRecord{Entity: "a", Value: i}); err != nil {
+			t.Fatalf("Failed to write record: %s", err)
+		}
+		if mats[i], err = store.UpdateMaterialization(id); err != nil {
+			t.Fatalf("Failed to update materialization: %s", err)
+		}
+	}
+	dir := t.TempDir()
+	archiver, err := provider.NewArchiver("file://" + dir)
+	if err != nil {
+		t.Fatalf("Failed to create archiver: %s", err)
+	}
+	compaction := &CompactionRunner{
+		Offline:  store,
+		Features: []provider.ResourceID{id},
+		Policy:   RetentionPolicy{KeepGenerations: 1},
+		Archiver: archiver,
+	}
+	watcher, err := compaction.Run()
+	if err != nil {
+		t.Fatalf("Failed to run compaction: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Compaction failed: %s", err)
+	}
+	for i, mat := range mats {
+		_, err := store.GetMaterialization(mat.ID())
+		if kept := i > 0; kept != (err == nil) {
+			t.Fatalf("Materialization %d kept is %v, expected %v", i, err == nil, kept)
+		}
+	}
+	file, err := os.Open(filepath.Join(dir, id.Name, id.Variant, string(mats[0].ID())+".jsonl.gz"))
+	if err != nil {
+		t.Fatalf("Generation was not archived: %s", err)
+	}
+	defer file.Close()
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatalf("Archive is not gzipped: %s", err)
+	}
+	var rec provider.ResourceRecord
+	if err := json.NewDecoder(zr).Decode(&rec); err != nil {
+		t.Fatalf("Failed to decode archived record: %s", err)
+	}
+	if rec.Entity != "a" {
+		t.Fatalf("Archived record has entity %s, expected a", rec.Entity)
+	}
+}
@@ -5,11 +5,15 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
+	"os"
+	"path"
 	"sync"
+	"time"
 )
 
 type Runner interface {
@@ -28,6 +32,20 @@ type MaterializedChunkRunner struct {
 	Table        provider.OnlineStoreTable
 	ChunkSize    int64
 	ChunkIdx     int64
+	// Online and RunID checkpoint the chunk once it's copied, so that a
+	// worker that's restarted skips chunks its run already finished. Chunks
+	// aren't checkpointed if RunID is empty.
+	Online provider.OnlineStore
+	RunID  string
+	// ID is the resource whose rows are copied. If it and Online are set,
+	// the chunk's write throughput is recorded, for sizing the chunks of
+	// later runs.
+	ID provider.ResourceID
+	// BulkLoad, if it's set and Online has a native bulk import, is where
+	// the chunk is written in the store's import format to be loaded all at
+	// once rather than a row at a time. The objects aren't deleted once
+	// they're loaded, which is best left to the bucket's lifecycle rules.
+	BulkLoad provider.Archiver
 }
 
 type CompletionWatcher interface {
@@ -35,12 +53,38 @@ type CompletionWatcher interface {
 	String() string
 	Wait() error
 	Err() error
+	// Progress is how far along the job is, as far as the watcher can
+	// tell.
+	Progress() Progress
 }
 
 type ResultSync struct {
-	err  error
-	done bool
-	mu   sync.RWMutex
+	err      error
+	done     bool
+	progress Progress
+	mu       sync.RWMutex
+}
+
+// set writes a value to the online table. Tables that keep when their
+// values are as of only take it if it isn't older than what they have, so
+// that a backfill or a retried chunk never replaces a newer value. A null
+// value, like the one a slowly changing dimension's expired row ends with,
+// removes the entity's value from tables that can remove it.
+func (m *MaterializedChunkRunner) set(entity string, value interface{}, ts time.Time) error {
+	if value == nil {
+		if timestamped, ok := m.Table.(provider.TimestampedOnlineStoreTable); ok {
+			_, err := timestamped.DeleteIfNewer(entity, ts)
+			return err
+		}
+		if deletable, ok := m.Table.(provider.DeletableOnlineStoreTable); ok {
+			return deletable.Delete(entity)
+		}
+	}
+	if timestamped, ok := m.Table.(provider.TimestampedOnlineStoreTable); ok {
+		_, err := timestamped.SetIfNewer(entity, value, ts)
+		return err
+	}
+	return m.Table.Set(entity, value)
 }
 
 func (m *MaterializedChunkRunner) Resource() metadata.ResourceID {
@@ -52,6 +96,12 @@ func (m *MaterializedChunkRunner) IsUpdateJob() bool {
 }
 
 func (m *MaterializedChunkRunner) Run() (CompletionWatcher, error) {
+	return m.RunWithContext(context.Background())
+}
+
+// RunWithContext copies the chunk, stopping between rows once ctx is
+// cancelled.
+func (m *MaterializedChunkRunner) RunWithContext(ctx context.Context) (CompletionWatcher, error) {
 	done := make(chan interface{})
 	jobWatcher := &SyncWatcher{
 		ResultSync:  &ResultSync{},
@@ -71,6 +121,18 @@ func (m *MaterializedChunkRunner) Run() (CompletionWatcher, error) {
 			jobWatcher.EndWatch(nil)
 			return
 		}
+		if m.checkpointed() {
+			done, err := chunkCompleted(m.Online, m.Materialized.ID(), m.ChunkIdx, m.RunID)
+			if err != nil {
+				jobWatcher.EndWatch(err)
+				return
+			}
+			if done {
+				Logger(ctx).Infow("Chunk was already copied", "chunk", m.ChunkIdx, "materialization", m.Materialized.ID())
+				jobWatcher.EndWatch(nil)
+				return
+			}
+		}
 
 		rowStart := m.ChunkIdx * m.ChunkSize
 		rowEnd := rowStart + m.ChunkSize
@@ -82,25 +144,112 @@ func (m *MaterializedChunkRunner) Run() (CompletionWatcher, error) {
 			jobWatcher.EndWatch(err)
 			return
 		}
-		for it.Next() {
-			value := it.Value().Value
-			entity := it.Value().Entity
-			err := m.Table.Set(entity, value)
-			if err != nil {
+		start := time.Now()
+		var copied int64
+		progress := Progress{TotalRows: rowEnd - rowStart, TotalChunks: 1}
+		jobWatcher.ResultSync.SetProgress(progress)
+		if loader, archive, ok := m.bulkLoader(); ok {
+			if copied, err = m.bulkLoad(ctx, loader, archive, it); err != nil {
+				jobWatcher.EndWatch(err)
+				return
+			}
+			progress.RowsCopied = copied
+		} else {
+			for it.Next() {
+				if err := ctx.Err(); err != nil {
+					jobWatcher.EndWatch(err)
+					return
+				}
+				value := it.Value().Value
+				entity := it.Value().Entity
+				err := m.set(entity, value, it.Value().TS)
+				if err != nil {
+					jobWatcher.EndWatch(err)
+					return
+				}
+				copied++
+				progress.RowsCopied = copied
+				jobWatcher.ResultSync.SetProgress(progress.withETA(time.Since(start)))
+			}
+			if err = it.Err(); err != nil {
 				jobWatcher.EndWatch(err)
 				return
 			}
 		}
-		if err = it.Err(); err != nil {
-			jobWatcher.EndWatch(err)
-			return
+		progress.ChunksDone = 1
+		jobWatcher.ResultSync.SetProgress(progress)
+		if m.Online != nil && m.ID.Name != "" {
+			// Throughput only informs later runs, so failing to record it
+			// doesn't fail the chunk.
+			if err := setThroughput(m.Online, m.ID, copied, time.Since(start)); err != nil {
+				Logger(ctx).Warnw("Could not record throughput", "resource", m.ID, "error", err)
+			}
+		}
+		if m.checkpointed() {
+			if err := setChunkCompleted(m.Online, m.Materialized.ID(), m.ChunkIdx, m.RunID); err != nil {
+				jobWatcher.EndWatch(fmt.Errorf("checkpoint chunk: %w", err))
+				return
+			}
 		}
 		jobWatcher.EndWatch(nil)
 	}()
 	return jobWatcher, nil
 }
 
+// bulkLoader returns the online store's bulk import and the archive it loads
+// from, if the chunk can be loaded in bulk.
+func (m *MaterializedChunkRunner) bulkLoader() (provider.BulkLoader, provider.ArchiveReader, bool) {
+	if m.BulkLoad == nil {
+		return nil, nil, false
+	}
+	loader, ok := m.Online.(provider.BulkLoader)
+	if !ok {
+		return nil, nil, false
+	}
+	archive, ok := m.BulkLoad.(provider.ArchiveReader)
+	return loader, archive, ok
+}
+
+// bulkLoad writes the chunk's rows in the store's import format to the
+// BulkLoad archive, through a temporary file so that the chunk isn't held in
+// memory, and has the store import it from there. Each run writes its own
+// objects, so that a run can't load a chunk that another is still writing.
+func (m *MaterializedChunkRunner) bulkLoad(ctx context.Context, loader provider.BulkLoader, archive provider.ArchiveReader, it provider.FeatureIterator) (int64, error) {
+	file, err := os.CreateTemp("", "bulkload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+	count, err := loader.WriteBulkLoad(file, m.Table, it)
+	if err != nil {
+		return 0, fmt.Errorf("write chunk for bulk load: %w", err)
+	}
+	key := path.Join("bulkload", string(m.Materialized.ID()), m.RunID, fmt.Sprintf("chunk-%d", m.ChunkIdx))
+	if err := provider.ArchiveFile(ctx, m.BulkLoad, key, file); err != nil {
+		return 0, fmt.Errorf("archive chunk for bulk load: %w", err)
+	}
+	if err := loader.BulkLoad(ctx, archive, key); err != nil {
+		return 0, fmt.Errorf("bulk load chunk: %w", err)
+	}
+	return count, nil
+}
+
+func (m *MaterializedChunkRunner) checkpointed() bool {
+	return m.Online != nil && m.RunID != ""
+}
+
 func (m *MaterializedChunkRunner) SetIndex(index int) error {
+	if index < 0 {
+		return fmt.Errorf("chunk index %d is negative", index)
+	}
+	numRows, err := m.Materialized.NumRows()
+	if err != nil {
+		return fmt.Errorf("cannot get materialization num rows: %v", err)
+	}
+	if m.ChunkSize*int64(index) > numRows {
+		return fmt.Errorf("chunk %d starts after end of materialization rows", index)
+	}
 	m.ChunkIdx = int64(index)
 	return nil
 }
@@ -122,6 +271,19 @@ func (r *ResultSync) Err() error {
 	return r.err
 }
 
+func (r *ResultSync) Progress() Progress {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.progress
+}
+
+// SetProgress records how far along the job is, for its watcher to report.
+func (r *ResultSync) SetProgress(progress Progress) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress = progress
+}
+
 func (r *ResultSync) DoneWithError(err error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -143,6 +305,10 @@ func (m *SyncWatcher) Wait() error {
 	return m.ResultSync.Err()
 }
 
+func (m *SyncWatcher) Progress() Progress {
+	return m.ResultSync.Progress()
+}
+
 func (m *SyncWatcher) Complete() bool {
 	return m.ResultSync.Done()
 }
@@ -167,8 +333,17 @@ type MaterializedChunkRunnerConfig struct {
 	MaterializedID provider.MaterializationID
 	ResourceID     provider.ResourceID
 	ChunkSize      int64
-	ChunkIdx       int64
-	IsUpdate       bool
+	// ChunkIdx is the chunk a local run copies. Chunk jobs share one config
+	// across all their tasks, and each task replaces it with its completion
+	// index through SetIndex.
+	ChunkIdx int64
+	IsUpdate bool
+	// RunID identifies the materialize run that the chunk is part of, and
+	// is what its checkpoint is recorded under.
+	RunID string `json:",omitempty"`
+	// BulkLoadURI is the object storage the chunk is written to, to be
+	// loaded in bulk by online stores that can.
+	BulkLoadURI string `json:",omitempty"`
 }
 
 func (m *MaterializedChunkRunnerConfig) Serialize() (Config, error) {
@@ -188,17 +363,16 @@ func (m *MaterializedChunkRunnerConfig) Deserialize(config Config) error {
 }
 
 func MaterializedChunkRunnerFactory(config Config) (Runner, error) {
-	fmt.Println("Starting Chunk Factory")
 	runnerConfig := &MaterializedChunkRunnerConfig{}
 	if err := runnerConfig.Deserialize(config); err != nil {
 		return nil, fmt.Errorf("failed to deserialize materialize chunk runner config: %v", err)
 	}
 
-	onlineProvider, err := provider.Get(runnerConfig.OnlineType, runnerConfig.OnlineConfig)
+	onlineProvider, err := getProvider(runnerConfig.OnlineType, runnerConfig.OnlineConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure online provider: %v", err)
 	}
-	offlineProvider, err := provider.Get(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
+	offlineProvider, err := getProvider(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure offline provider: %v", err)
 	}
@@ -221,14 +395,24 @@ func MaterializedChunkRunnerFactory(config Config) (Runner, error) {
 	if runnerConfig.ChunkSize*runnerConfig.ChunkIdx > numRows {
 		return nil, fmt.Errorf("chunk runner starts after end of materialization rows")
 	}
-	table, err := onlineStore.GetTable(runnerConfig.ResourceID.Name, runnerConfig.ResourceID.Variant)
+	table, err := onlineStore.GetTable(provider.MaterializedName(runnerConfig.ResourceID), runnerConfig.ResourceID.Variant)
 	if err != nil {
 		return nil, fmt.Errorf("error getting online table: %v", err)
 	}
+	var bulkLoad provider.Archiver
+	if runnerConfig.BulkLoadURI != "" {
+		if bulkLoad, err = provider.NewArchiver(runnerConfig.BulkLoadURI); err != nil {
+			return nil, fmt.Errorf("failed to configure bulk load archiver: %v", err)
+		}
+	}
 	return &MaterializedChunkRunner{
 		Materialized: materialization,
 		Table:        table,
 		ChunkSize:    runnerConfig.ChunkSize,
 		ChunkIdx:     runnerConfig.ChunkIdx,
+		Online:       onlineStore,
+		RunID:        runnerConfig.RunID,
+		ID:           runnerConfig.ResourceID,
+		BulkLoad:     bulkLoad,
 	}, nil
 }
@@ -5,13 +5,19 @@
 package runner
 
 import (
+	"bufio"
+	"context"
 	"errors"
 	"fmt"
+	"github.com/alicebob/miniredis"
 	"github.com/featureform/provider"
 	"github.com/google/uuid"
+	"io"
 	"reflect"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 type MockMaterializedFeatures struct {
@@ -34,6 +40,10 @@ func (m *MockMaterializedFeatures) IterateSegment(begin int64, end int64) (provi
 	}, nil
 }
 
+func (m *MockMaterializedFeatures) Stats() (provider.TableStats, error) {
+	return provider.TableStats{NumRows: int64(len(m.Rows)), SizeBytes: -1}, nil
+}
+
 type MaterializedFeaturesNumRowsBroken struct {
 	id provider.MaterializationID
 }
@@ -50,6 +60,10 @@ func (m *MaterializedFeaturesNumRowsBroken) IterateSegment(begin int64, end int6
 	return nil, nil
 }
 
+func (m *MaterializedFeaturesNumRowsBroken) Stats() (provider.TableStats, error) {
+	return provider.TableStats{}, fmt.Errorf("cannot fetch stats")
+}
+
 type MaterializedFeaturesIterateBroken struct {
 	id provider.MaterializationID
 }
@@ -66,6 +80,10 @@ func (m *MaterializedFeaturesIterateBroken) IterateSegment(begin int64, end int6
 	return nil, errors.New("cannot create feature iterator")
 }
 
+func (m *MaterializedFeaturesIterateBroken) Stats() (provider.TableStats, error) {
+	return provider.TableStats{NumRows: 1, SizeBytes: -1}, nil
+}
+
 type MaterializedFeaturesIterateRunBroken struct {
 	id provider.MaterializationID
 }
@@ -82,6 +100,10 @@ func (m *MaterializedFeaturesIterateRunBroken) IterateSegment(begin int64, end i
 	return &BrokenFeatureIterator{}, nil
 }
 
+func (m *MaterializedFeaturesIterateRunBroken) Stats() (provider.TableStats, error) {
+	return provider.TableStats{NumRows: 1, SizeBytes: -1}, nil
+}
+
 type MockOnlineTable struct {
 	DataTable map[string]interface{}
 }
@@ -347,6 +369,10 @@ func (b BrokenNumRowsOfflineStore) CreateMaterialization(id provider.ResourceID)
 	return nil, nil
 }
 
+func (b BrokenNumRowsOfflineStore) CreateIncrementalMaterialization(id provider.ResourceID, since time.Time) (provider.Materialization, error) {
+	return nil, nil
+}
+
 func (b BrokenNumRowsOfflineStore) UpdateMaterialization(id provider.ResourceID) (provider.Materialization, error) {
 	return nil, nil
 }
@@ -357,6 +383,9 @@ func (b BrokenNumRowsOfflineStore) GetMaterialization(id provider.Materializatio
 func (b BrokenNumRowsOfflineStore) DeleteMaterialization(id provider.MaterializationID) error {
 	return nil
 }
+func (b BrokenNumRowsOfflineStore) DeleteTable(id provider.ResourceID) error {
+	return nil
+}
 func (b BrokenNumRowsOfflineStore) CreateTrainingSet(provider.TrainingSetDef) error {
 	return nil
 }
@@ -383,6 +412,9 @@ func (b BrokenGetTableOnlineStore) GetTable(feature, variant string) (provider.O
 func (b BrokenGetTableOnlineStore) CreateTable(feature, variant string, valueType provider.ValueType) (provider.OnlineStoreTable, error) {
 	return nil, nil
 }
+func (b BrokenGetTableOnlineStore) DeleteTable(feature, variant string) error {
+	return nil
+}
 
 func TestMaterializeRunnerFactoryErrorCoverage(t *testing.T) {
 	err := provider.RegisterFactory("MOCK_OFFLINE_BROKEN_NUMROWS", brokenNumRowsOfflineFactory)
@@ -695,6 +727,10 @@ func (m MockOnlineStore) CreateTable(feature, variant string, valueType provider
 	return &MockOnlineStoreTable{}, nil
 }
 
+func (m MockOnlineStore) DeleteTable(feature, variant string) error {
+	return nil
+}
+
 func (m MockOnlineStoreTable) Set(entity string, value interface{}) error {
 	return nil
 }
@@ -724,6 +760,10 @@ func (m MockOfflineStore) CreateMaterialization(id provider.ResourceID) (provide
 	return MockMaterialization{}, nil
 }
 
+func (m MockOfflineStore) CreateIncrementalMaterialization(id provider.ResourceID, since time.Time) (provider.Materialization, error) {
+	return MockMaterialization{}, nil
+}
+
 func (m MockOfflineStore) GetMaterialization(id provider.MaterializationID) (provider.Materialization, error) {
 	return MockMaterialization{}, nil
 }
@@ -732,6 +772,10 @@ func (m MockOfflineStore) DeleteMaterialization(id provider.MaterializationID) e
 	return nil
 }
 
+func (m MockOfflineStore) DeleteTable(id provider.ResourceID) error {
+	return nil
+}
+
 func (m MockOfflineStore) CreateTrainingSet(provider.TrainingSetDef) error {
 	return nil
 }
@@ -760,6 +804,10 @@ func (m MockMaterialization) IterateSegment(begin, end int64) (provider.FeatureI
 	return MockIterator{}, nil
 }
 
+func (m MockMaterialization) Stats() (provider.TableStats, error) {
+	return provider.TableStats{SizeBytes: -1}, nil
+}
+
 type MockIterator struct{}
 
 func (m MockIterator) Next() bool {
@@ -838,6 +886,9 @@ func TestChunkRunnerFactory(t *testing.T) {
 	if err := indexRunner.SetIndex(0); err != nil {
 		t.Fatalf("Failed to set index: %v", err)
 	}
+	if err := indexRunner.SetIndex(-1); err == nil {
+		t.Fatalf("Set negative index")
+	}
 	watcher, err := indexRunner.Run()
 	if err != nil {
 		t.Fatalf("runner failed to run: %v", err)
@@ -854,3 +905,273 @@ func TestRunnerConfigDeserializeFails(t *testing.T) {
 		t.Fatalf("Failed to report error deserializing config")
 	}
 }
+
+type countingOnlineTable struct {
+	provider.OnlineStoreTable
+	sets int
+}
+
+func (t *countingOnlineTable) Set(entity string, value interface{}) error {
+	t.sets++
+	return t.OnlineStoreTable.Set(entity, value)
+}
+
+func TestChunkRunnerCheckpoints(t *testing.T) {
+	online := provider.NewLocalOnlineStore()
+	table, err := online.CreateTable("feature", "variant", provider.Int)
+	if err != nil {
+		t.Fatalf("Failed to create online table: %v", err)
+	}
+	counting := &countingOnlineTable{OnlineStoreTable: table}
+	materialized := &MockMaterializedFeatures{id: "mat", Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}}}
+	runChunk := func(runID string) {
+		chunk := &MaterializedChunkRunner{Materialized: materialized, Table: counting, ChunkSize: 2, Online: online, RunID: runID}
+		watcher, err := chunk.Run()
+		if err != nil {
+			t.Fatalf("Failed to run chunk: %v", err)
+		}
+		if err := watcher.Wait(); err != nil {
+			t.Fatalf("Chunk failed: %v", err)
+		}
+	}
+	runChunk("first")
+	if counting.sets != 2 {
+		t.Fatalf("Expected 2 rows to be copied, got %d", counting.sets)
+	}
+	if done, err := chunkCompleted(online, "mat", 0, "first"); err != nil || !done {
+		t.Fatalf("Expected chunk to be checkpointed: %v %v", done, err)
+	}
+	runChunk("first")
+	if counting.sets != 2 {
+		t.Fatalf("Expected a restarted chunk to be skipped, got %d rows copied", counting.sets)
+	}
+	runChunk("second")
+	if counting.sets != 4 {
+		t.Fatalf("Expected a new run to copy the chunk again, got %d rows copied", counting.sets)
+	}
+}
+
+func TestChunkRunID(t *testing.T) {
+	if chunkRunID("mat", "job") != chunkRunID("mat", "job") {
+		t.Fatalf("Expected attempts at a job to share a run ID")
+	}
+	if chunkRunID("mat", "job") == chunkRunID("mat", "other") || chunkRunID("mat", "job") == chunkRunID("other", "job") {
+		t.Fatalf("Expected other jobs and materializations to get their own run IDs")
+	}
+	if chunkRunID("mat", "") == chunkRunID("mat", "") {
+		t.Fatalf("Expected runs without a lineage to get their own run IDs")
+	}
+}
+
+func TestChunkRunnerIndexesCopyDistinctRanges(t *testing.T) {
+	online := provider.NewLocalOnlineStore()
+	table, err := online.CreateTable("feature", "variant", provider.Int)
+	if err != nil {
+		t.Fatalf("Failed to create online table: %v", err)
+	}
+	counting := &countingOnlineTable{OnlineStoreTable: table}
+	rows := []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}, {Entity: "c", Value: 3}, {Entity: "d", Value: 4}, {Entity: "e", Value: 5}}
+	materialized := &MockMaterializedFeatures{id: "mat", Rows: rows}
+	// Every pod of an indexed chunk job gets the same config, and only its
+	// completion index tells it which chunk to copy.
+	for i := 0; i < 3; i++ {
+		chunk := &MaterializedChunkRunner{Materialized: materialized, Table: counting, ChunkSize: 2}
+		if err := chunk.SetIndex(i); err != nil {
+			t.Fatalf("Failed to set chunk index %d: %v", i, err)
+		}
+		watcher, err := chunk.Run()
+		if err != nil {
+			t.Fatalf("Failed to run chunk %d: %v", i, err)
+		}
+		if err := watcher.Wait(); err != nil {
+			t.Fatalf("Chunk %d failed: %v", i, err)
+		}
+	}
+	if counting.sets != len(rows) {
+		t.Fatalf("Expected each row to be copied once, got %d copies of %d rows", counting.sets, len(rows))
+	}
+	for _, row := range rows {
+		value, err := table.Get(row.Entity)
+		if err != nil {
+			t.Fatalf("Row %s wasn't copied: %v", row.Entity, err)
+		}
+		if value != row.Value {
+			t.Fatalf("Expected %s to be %v, got %v", row.Entity, row.Value, value)
+		}
+	}
+}
+
+// bulkLoadOnlineStore imports chunks written as entity=value lines.
+type bulkLoadOnlineStore struct {
+	provider.OnlineStore
+	table provider.OnlineStoreTable
+	loads []string
+}
+
+func (s *bulkLoadOnlineStore) WriteBulkLoad(w io.Writer, table provider.OnlineStoreTable, iter provider.FeatureIterator) (int64, error) {
+	var count int64
+	for iter.Next() {
+		fmt.Fprintf(w, "%s=%v\n", iter.Value().Entity, iter.Value().Value)
+		count++
+	}
+	return count, iter.Err()
+}
+
+func (s *bulkLoadOnlineStore) BulkLoad(ctx context.Context, archive provider.ArchiveReader, key string) error {
+	s.loads = append(s.loads, key)
+	object, err := archive.OpenArchive(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer object.Close()
+	scanner := bufio.NewScanner(object)
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "=", 2)
+		if err := s.table.Set(parts[0], parts[1]); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func TestChunkRunnerBulkLoad(t *testing.T) {
+	archiver, err := provider.NewArchiver("file://" + t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create archiver: %v", err)
+	}
+	online := provider.NewLocalOnlineStore()
+	table, err := online.CreateTable("feature", "variant", provider.String)
+	if err != nil {
+		t.Fatalf("Failed to create online table: %v", err)
+	}
+	counting := &countingOnlineTable{OnlineStoreTable: table}
+	bulk := &bulkLoadOnlineStore{OnlineStore: online, table: table}
+	materialized := &MockMaterializedFeatures{id: "mat", Rows: []provider.ResourceRecord{{Entity: "a", Value: "1"}, {Entity: "b", Value: "2"}, {Entity: "c", Value: "3"}}}
+	chunk := &MaterializedChunkRunner{Materialized: materialized, Table: counting, ChunkSize: 2, ChunkIdx: 1, Online: bulk, RunID: "run", BulkLoad: archiver}
+	watcher, err := chunk.Run()
+	if err != nil {
+		t.Fatalf("Failed to run chunk: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Chunk failed: %v", err)
+	}
+	if counting.sets != 0 || len(bulk.loads) != 1 || bulk.loads[0] != "bulkload/mat/run/chunk-1" {
+		t.Fatalf("Expected the chunk to be loaded in bulk, got %d sets and loads %v", counting.sets, bulk.loads)
+	}
+	if value, err := table.Get("c"); err != nil || value != "3" {
+		t.Fatalf("Expected c to be loaded, got %v %v", value, err)
+	}
+	if progress := watcher.Progress(); progress.RowsCopied != 1 || progress.ChunksDone != 1 {
+		t.Fatalf("Unexpected progress after bulk load: %v", progress)
+	}
+
+	chunk = &MaterializedChunkRunner{Materialized: materialized, Table: counting, ChunkSize: 2, Online: online, BulkLoad: archiver}
+	if watcher, err = chunk.Run(); err != nil {
+		t.Fatalf("Failed to run chunk: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Chunk failed: %v", err)
+	}
+	if counting.sets != 2 {
+		t.Fatalf("Expected a store without a bulk import to be set row by row, got %d sets", counting.sets)
+	}
+}
+
+func TestChunkRunnerKeepsNewerValues(t *testing.T) {
+	online := provider.NewLocalOnlineStore()
+	table, err := online.CreateTable("feature", "variant", provider.Int)
+	if err != nil {
+		t.Fatalf("Failed to create online table: %v", err)
+	}
+	now := time.Now().UTC()
+	if _, err := table.(provider.TimestampedOnlineStoreTable).SetIfNewer("a", 10, now); err != nil {
+		t.Fatalf("Failed to set value: %v", err)
+	}
+	// A backfilled window only has values from before what's already online.
+	rows := []provider.ResourceRecord{{Entity: "a", Value: 1, TS: now.Add(-time.Hour)}, {Entity: "b", Value: 2, TS: now.Add(-time.Hour)}}
+	materialized := &MockMaterializedFeatures{id: "mat", Rows: rows}
+	chunk := &MaterializedChunkRunner{Materialized: materialized, Table: table, ChunkSize: 2}
+	watcher, err := chunk.Run()
+	if err != nil {
+		t.Fatalf("Failed to run chunk: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Chunk failed: %v", err)
+	}
+	expected := map[string]interface{}{"a": 10, "b": 2}
+	for entity, expect := range expected {
+		value, err := table.Get(entity)
+		if err != nil {
+			t.Fatalf("Failed to get %s: %v", entity, err)
+		}
+		if value != expect {
+			t.Fatalf("Expected %s to be %v, got %v", entity, expect, value)
+		}
+	}
+}
+
+func TestChunkRunnerDeletesExpiredEntities(t *testing.T) {
+	miniRedis, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("Failed to start redis: %v", err)
+	}
+	defer miniRedis.Close()
+	redisConfig := &provider.RedisConfig{Addr: miniRedis.Addr()}
+	stores := map[provider.Type]provider.SerializedConfig{
+		provider.LocalOnline: []byte{},
+		provider.RedisOnline: redisConfig.Serialized(),
+	}
+	for typ, config := range stores {
+		t.Run(string(typ), func(t *testing.T) {
+			p, err := provider.Get(typ, config)
+			if err != nil {
+				t.Fatalf("Failed to get provider %s: %v", typ, err)
+			}
+			online, err := p.AsOnlineStore()
+			if err != nil {
+				t.Fatalf("Failed to use provider %s as OnlineStore: %v", typ, err)
+			}
+			table, err := online.CreateTable(uuid.NewString(), "variant", provider.Int)
+			if err != nil {
+				t.Fatalf("Failed to create online table: %v", err)
+			}
+			now := time.Now().UTC()
+			if _, err := table.(provider.TimestampedOnlineStoreTable).SetIfNewer("b", 3, now.Add(-2*time.Hour)); err != nil {
+				t.Fatalf("Failed to set value: %v", err)
+			}
+			if _, err := table.(provider.TimestampedOnlineStoreTable).SetIfNewer("c", 30, now); err != nil {
+				t.Fatalf("Failed to set value: %v", err)
+			}
+			// The materialization of a slowly changing dimension ends with a
+			// null value for entities whose last row expired, like b here. c's
+			// row expired before the value it has online was set.
+			rows := []provider.ResourceRecord{
+				{Entity: "a", Value: 2, TS: now.Add(-time.Hour)},
+				{Entity: "b", Value: nil, TS: now.Add(-time.Hour)},
+				{Entity: "c", Value: nil, TS: now.Add(-time.Hour)},
+			}
+			materialized := &MockMaterializedFeatures{id: "mat", Rows: rows}
+			chunk := &MaterializedChunkRunner{Materialized: materialized, Table: table, ChunkSize: int64(len(rows))}
+			watcher, err := chunk.Run()
+			if err != nil {
+				t.Fatalf("Failed to run chunk: %v", err)
+			}
+			if err := watcher.Wait(); err != nil {
+				t.Fatalf("Chunk failed: %v", err)
+			}
+			for entity, expected := range map[string]interface{}{"a": 2, "c": 30} {
+				if value, err := table.Get(entity); err != nil || value != expected {
+					t.Fatalf("Expected %s to be %v, got %v %v", entity, expected, value, err)
+				}
+			}
+			var notFound *provider.EntityNotFound
+			if value, err := table.Get("b"); !errors.As(err, &notFound) {
+				t.Fatalf("Expected expired entity b not to be found, got %v %v", value, err)
+			}
+			// A value from before b expired doesn't come back.
+			if written, err := table.(provider.TimestampedOnlineStoreTable).SetIfNewer("b", 3, now.Add(-2*time.Hour)); err != nil || written {
+				t.Fatalf("Expected an older value of b not to be written, got %v %v", written, err)
+			}
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/featureform/provider"
 	"testing"
+	"time"
 )
 
 type MockOfflineCreateTransformationFail struct {
@@ -23,12 +24,18 @@ func (m MockOfflineCreateTransformationFail) GetResourceTable(id provider.Resour
 func (m MockOfflineCreateTransformationFail) CreateMaterialization(id provider.ResourceID) (provider.Materialization, error) {
 	return nil, nil
 }
+func (m MockOfflineCreateTransformationFail) CreateIncrementalMaterialization(id provider.ResourceID, since time.Time) (provider.Materialization, error) {
+	return nil, nil
+}
 func (m MockOfflineCreateTransformationFail) GetMaterialization(id provider.MaterializationID) (provider.Materialization, error) {
 	return nil, nil
 }
 func (m MockOfflineCreateTransformationFail) DeleteMaterialization(id provider.MaterializationID) error {
 	return nil
 }
+func (m MockOfflineCreateTransformationFail) DeleteTable(id provider.ResourceID) error {
+	return nil
+}
 func (m MockOfflineCreateTransformationFail) CreateTrainingSet(provider.TrainingSetDef) error {
 	return nil
 }
@@ -73,9 +80,9 @@ func (m MockOfflineCreateTransformationFail) UpdateTrainingSet(provider.Training
 
 func TestRun(t *testing.T) {
 	runner := CreateTransformationRunner{
-		MockOfflineStore{},
-		provider.TransformationConfig{},
-		false,
+		Offline:              MockOfflineStore{},
+		TransformationConfig: provider.TransformationConfig{},
+		IsUpdate:             false,
 	}
 	watcher, err := runner.Run()
 	if err != nil {
@@ -88,9 +95,9 @@ func TestRun(t *testing.T) {
 
 func TestFail(t *testing.T) {
 	runner := CreateTransformationRunner{
-		MockOfflineCreateTransformationFail{},
-		provider.TransformationConfig{},
-		false,
+		Offline:              MockOfflineCreateTransformationFail{},
+		TransformationConfig: provider.TransformationConfig{},
+		IsUpdate:             false,
 	}
 	watcher, err := runner.Run()
 	if err != nil {
@@ -180,3 +187,36 @@ func TestTransformationFactory(t *testing.T) {
 	}
 	delete(factoryMap, "TEST_CREATE_TRANSFORMATION")
 }
+
+func TestExpandRunTemplates(t *testing.T) {
+	syntax := provider.TypeQuerySyntax(provider.PostgresOffline)
+	now := time.Date(2022, 5, 10, 12, 30, 20, 0, time.UTC)
+	query := "SELECT * FROM t WHERE ts >= {{ schedule_start }} AND ts < {{schedule_end}} AND day = {{ run_date }}"
+	tests := map[string]struct {
+		Schedule string
+		Backfill *BackfillWindow
+		Expected string
+	}{
+		"Unscheduled": {"", nil, "SELECT * FROM t WHERE ts >= '1970-01-01 00:00:00' AND ts < '2022-05-10 12:30:20' AND day = '2022-05-10'"},
+		"Hourly":      {"0 * * * *", nil, "SELECT * FROM t WHERE ts >= '2022-05-10 11:00:00' AND ts < '2022-05-10 12:00:00' AND day = '2022-05-10'"},
+		"Backfill": {"0 * * * *", &BackfillWindow{Since: time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC)},
+			"SELECT * FROM t WHERE ts >= '2022-05-01 00:00:00' AND ts < '2022-05-02 00:00:00' AND day = '2022-05-02'"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			actual, err := expandRunTemplates(syntax, query, test.Schedule, test.Backfill, now)
+			if err != nil {
+				t.Fatalf("Could not expand run templates: %v", err)
+			}
+			if actual != test.Expected {
+				t.Fatalf("Expected %q, got %q", test.Expected, actual)
+			}
+		})
+	}
+	if _, err := expandRunTemplates(syntax, "SELECT {{ unknown }}", "", nil, now); err == nil {
+		t.Fatalf("Expected unknown run time template to fail")
+	}
+	if actual, err := expandRunTemplates(syntax, "SELECT '{{ not a template'", "", nil, now); err != nil || actual != "SELECT '{{ not a template'" {
+		t.Fatalf("Expected braces in a string to be left alone, got %q: %v", actual, err)
+	}
+}
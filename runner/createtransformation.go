@@ -5,26 +5,45 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
+
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
 )
 
 func (c *CreateTransformationRunner) Run() (CompletionWatcher, error) {
+	return c.RunWithContext(context.Background())
+}
+
+// RunWithContext runs the transformation, cancelling its query in the
+// warehouse if ctx is done first.
+func (c *CreateTransformationRunner) RunWithContext(ctx context.Context) (CompletionWatcher, error) {
+	offline, release, err := provider.OfflineStoreWithContext(ctx, c.Offline)
+	if err != nil {
+		return nil, err
+	}
 	done := make(chan interface{})
 	transformationWatcher := &SyncWatcher{
 		ResultSync:  &ResultSync{},
 		DoneChannel: done,
 	}
+	config := c.TransformationConfig
+	if config.Query, err = expandRunTemplates(provider.TypeQuerySyntax(c.Offline.Type()), config.Query, c.Schedule, c.Backfill, time.Now()); err != nil {
+		release()
+		return nil, err
+	}
 	go func() {
+		defer release()
 		if !c.IsUpdate {
-			if err := c.Offline.CreateTransformation(c.TransformationConfig); err != nil {
+			if err := offline.CreateTransformation(config); err != nil {
 				transformationWatcher.EndWatch(err)
 				return
 			}
 		} else {
-			if err := c.Offline.UpdateTransformation(c.TransformationConfig); err != nil {
+			if err := offline.UpdateTransformation(config); err != nil {
 				transformationWatcher.EndWatch(err)
 				return
 			}
@@ -39,12 +58,20 @@ type CreateTransformationConfig struct {
 	OfflineConfig        provider.SerializedConfig
 	TransformationConfig provider.TransformationConfig
 	IsUpdate             bool
+	// Schedule is the cron schedule that update jobs run on. Each run's
+	// window for the query's run-time templates ends at its scheduled time.
+	Schedule string
+	// Backfill is set for runs that backfill a window, which is used for the
+	// query's run-time templates instead of the schedule's.
+	Backfill *BackfillWindow
 }
 
 type CreateTransformationRunner struct {
 	Offline              provider.OfflineStore
 	TransformationConfig provider.TransformationConfig
 	IsUpdate             bool
+	Schedule             string
+	Backfill             *BackfillWindow
 }
 
 func (c CreateTransformationRunner) Resource() metadata.ResourceID {
@@ -80,7 +107,7 @@ func CreateTransformationRunnerFactory(config Config) (Runner, error) {
 	if err := transformationConfig.Deserialize(config); err != nil {
 		return nil, fmt.Errorf("failed to deserialize create transformation config")
 	}
-	offlineProvider, err := provider.Get(transformationConfig.OfflineType, transformationConfig.OfflineConfig)
+	offlineProvider, err := getProvider(transformationConfig.OfflineType, transformationConfig.OfflineConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure offline provider: %v", err)
 	}
@@ -92,6 +119,8 @@ func CreateTransformationRunnerFactory(config Config) (Runner, error) {
 		Offline:              offlineStore,
 		TransformationConfig: transformationConfig.TransformationConfig,
 		IsUpdate:             transformationConfig.IsUpdate,
+		Schedule:             transformationConfig.Schedule,
+		Backfill:             transformationConfig.Backfill,
 	}, nil
 
 }
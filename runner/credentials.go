@@ -0,0 +1,72 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/featureform/provider"
+)
+
+// Provider configs hold credentials, so workers on Kubernetes don't get them
+// in their job config. The coordinator stores each config under a key in the
+// credentials secret, which is projected into every worker pod, and puts a
+// reference to the key in the job config in its place.
+const (
+	CredentialsSecretName = "featureform-provider-credentials"
+	credentialRefPrefix   = "secretref:"
+)
+
+// CredentialsDir is where the credentials secret is mounted in worker pods.
+var CredentialsDir = "/etc/featureform/credentials"
+
+var invalidCredentialKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]`)
+
+// CredentialKey is the key a provider's config is stored under in the
+// credentials secret. Secret keys only allow alphanumerics, '-', '_' and '.'.
+func CredentialKey(providerName string) string {
+	return invalidCredentialKeyChars.ReplaceAllString(providerName, "_")
+}
+
+// ProviderConfigRef returns a config that can be put in a runner config in
+// place of a provider's serialized config. The runner reads the real config
+// from the mounted credentials secret when it's created.
+func ProviderConfigRef(providerName string) provider.SerializedConfig {
+	return provider.SerializedConfig(credentialRefPrefix + CredentialKey(providerName))
+}
+
+func isProviderConfigRef(config provider.SerializedConfig) bool {
+	return bytes.HasPrefix(config, []byte(credentialRefPrefix))
+}
+
+// resolveProviderConfig returns config itself, or the config it refers to if
+// it's a reference.
+func resolveProviderConfig(config provider.SerializedConfig) (provider.SerializedConfig, error) {
+	if !isProviderConfigRef(config) {
+		return config, nil
+	}
+	key := string(bytes.TrimPrefix(config, []byte(credentialRefPrefix)))
+	if key != CredentialKey(key) {
+		return nil, fmt.Errorf("invalid provider config reference %q", config)
+	}
+	resolved, err := os.ReadFile(filepath.Join(CredentialsDir, key))
+	if err != nil {
+		return nil, fmt.Errorf("read provider credentials %s: %w", key, err)
+	}
+	return resolved, nil
+}
+
+// getProvider is provider.Get for configs that may be references.
+func getProvider(t provider.Type, config provider.SerializedConfig) (provider.Provider, error) {
+	resolved, err := resolveProviderConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Get(t, resolved)
+}
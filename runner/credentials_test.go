@@ -0,0 +1,64 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+func TestResolveProviderConfig(t *testing.T) {
+	dir := t.TempDir()
+	defer func(prev string) { CredentialsDir = prev }(CredentialsDir)
+	CredentialsDir = dir
+	stored := provider.SerializedConfig(`{"Password": "secret"}`)
+	if err := os.WriteFile(filepath.Join(dir, CredentialKey("my provider")), stored, 0600); err != nil {
+		t.Fatalf("Failed to write credentials: %s", err)
+	}
+	ref := ProviderConfigRef("my provider")
+	if string(ref) != "secretref:my_provider" {
+		t.Fatalf("Unexpected reference %s", ref)
+	}
+	resolved, err := resolveProviderConfig(ref)
+	if err != nil {
+		t.Fatalf("Failed to resolve reference: %s", err)
+	}
+	if string(resolved) != string(stored) {
+		t.Fatalf("Resolved %s, expected %s", resolved, stored)
+	}
+	inline := provider.SerializedConfig(`{"Addr": "localhost"}`)
+	if resolved, err := resolveProviderConfig(inline); err != nil || string(resolved) != string(inline) {
+		t.Fatalf("Inline config was changed to %s: %v", resolved, err)
+	}
+	if _, err := resolveProviderConfig(ProviderConfigRef("missing")); err == nil {
+		t.Fatalf("Resolved a reference with no credentials")
+	}
+	if _, err := resolveProviderConfig(provider.SerializedConfig("secretref:../escape")); err == nil {
+		t.Fatalf("Resolved a reference outside of the credentials directory")
+	}
+}
+
+func TestJobSpecMountsCredentials(t *testing.T) {
+	spec := newJobSpec("job", KubernetesRunnerConfig{
+		Resource: metadata.ResourceID{Name: "a", Variant: "b", Type: metadata.FEATURE_VARIANT},
+		NumTasks: 1,
+	})
+	pod := spec.Template.Spec
+	if len(pod.Volumes) != 1 || pod.Volumes[0].Projected == nil {
+		t.Fatalf("Expected a projected credentials volume, got %v", pod.Volumes)
+	}
+	secret := pod.Volumes[0].Projected.Sources[0].Secret
+	if secret == nil || secret.Name != CredentialsSecretName {
+		t.Fatalf("Credentials volume does not project %s", CredentialsSecretName)
+	}
+	mounts := pod.Containers[0].VolumeMounts
+	if len(mounts) != 1 || mounts[0].MountPath != CredentialsDir || !mounts[0].ReadOnly {
+		t.Fatalf("Credentials are not mounted read only at %s: %v", CredentialsDir, mounts)
+	}
+}
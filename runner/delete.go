@@ -0,0 +1,149 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// DeleteResourceRunner removes the data that a resource's jobs created in its
+// providers. A feature's online table, its materializations and its resource
+// table are dropped, and so is the table of a label, transformation, primary
+// source or training set. Data that's already gone is skipped, so a deletion
+// that failed part way through can be run again.
+type DeleteResourceRunner struct {
+	Offline provider.OfflineStore
+	// Online is the feature's online store. Resources without one leave it
+	// nil.
+	Online provider.OnlineStore
+	ID     provider.ResourceID
+}
+
+func (d *DeleteResourceRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (d *DeleteResourceRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (d *DeleteResourceRunner) Run() (CompletionWatcher, error) {
+	done := make(chan interface{})
+	jobWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		if err := d.delete(); err != nil {
+			jobWatcher.EndWatch(fmt.Errorf("delete %s (%s): %w", d.ID.Name, d.ID.Variant, err))
+			return
+		}
+		jobWatcher.EndWatch(nil)
+	}()
+	return jobWatcher, nil
+}
+
+func (d *DeleteResourceRunner) delete() error {
+	if d.ID.Type == provider.Feature {
+		if d.Online != nil {
+			err := d.Online.DeleteTable(d.ID.Name, d.ID.Variant)
+			var notFound *provider.TableNotFound
+			if err != nil && !errors.As(err, &notFound) {
+				return fmt.Errorf("delete online table: %w", err)
+			}
+		}
+		if err := d.deleteMaterializations(); err != nil {
+			return err
+		}
+	}
+	err := d.Offline.DeleteTable(d.ID)
+	var tableNotFound *provider.TableNotFound
+	var trainingSetNotFound *provider.TrainingSetNotFound
+	if err != nil && !errors.As(err, &tableNotFound) && !errors.As(err, &trainingSetNotFound) {
+		return fmt.Errorf("delete %v table: %w", d.ID.Type, err)
+	}
+	return nil
+}
+
+// deleteMaterializations drops a feature's materialization and the
+// generations its updates replaced. Like the coordinator's garbage
+// collection, it finds the materialization by the name the SQL stores give
+// it.
+func (d *DeleteResourceRunner) deleteMaterializations() error {
+	matIDs := []provider.MaterializationID{provider.ResourceMaterializationID(d.ID)}
+	if genStore, ok := d.Offline.(provider.GenerationStore); ok {
+		gens, err := genStore.MaterializationGenerations(d.ID)
+		if err != nil {
+			return fmt.Errorf("list materialization generations: %w", err)
+		}
+		for _, gen := range gens {
+			matIDs = append(matIDs, gen.ID)
+		}
+	}
+	for _, matID := range matIDs {
+		err := d.Offline.DeleteMaterialization(matID)
+		var notFound *provider.MaterializationNotFound
+		if err != nil && !errors.As(err, &notFound) {
+			return fmt.Errorf("delete materialization %s: %w", matID, err)
+		}
+	}
+	return nil
+}
+
+type DeleteResourceRunnerConfig struct {
+	OfflineType   provider.Type
+	OfflineConfig provider.SerializedConfig
+	// OnlineType is empty for resources without an online store.
+	OnlineType   provider.Type
+	OnlineConfig provider.SerializedConfig
+	ResourceID   provider.ResourceID
+}
+
+func (c *DeleteResourceRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *DeleteResourceRunnerConfig) Deserialize(config Config) error {
+	return json.Unmarshal(config, c)
+}
+
+func DeleteResourceRunnerFactory(config Config) (Runner, error) {
+	runnerConfig := &DeleteResourceRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize delete resource runner config: %v", err)
+	}
+	offlineProvider, err := getProvider(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure offline provider: %v", err)
+	}
+	offlineStore, err := offlineProvider.AsOfflineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to offline store: %v", err)
+	}
+	var onlineStore provider.OnlineStore
+	if runnerConfig.OnlineType != "" {
+		onlineProvider, err := getProvider(runnerConfig.OnlineType, runnerConfig.OnlineConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure online provider: %v", err)
+		}
+		if onlineStore, err = onlineProvider.AsOnlineStore(); err != nil {
+			return nil, fmt.Errorf("failed to convert provider to online store: %v", err)
+		}
+	}
+	return &DeleteResourceRunner{
+		Offline: offlineStore,
+		Online:  onlineStore,
+		ID:      runnerConfig.ResourceID,
+	}, nil
+}
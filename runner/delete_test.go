@@ -0,0 +1,79 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"testing"
+
+	"github.com/featureform/provider"
+)
+
+func TestDeleteResourceRunner(t *testing.T) {
+	offline := provider.NewMemoryOfflineStore()
+	online := provider.NewLocalOnlineStore()
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	schema := provider.TableSchema{
+		Columns: []provider.TableColumn{
+			{Name: "entity", ValueType: provider.String},
+			{Name: "value", ValueType: provider.Int},
+			{Name: "ts", ValueType: provider.Timestamp},
+		},
+	}
+	table, err := offline.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	if err := table.Write(provider.ResourceRecord{Entity: "a", Value: 1}); err != nil {
+		t.Fatalf("Failed to write record: %s", err)
+	}
+	first, err := offline.CreateMaterialization(id)
+	if err != nil {
+		t.Fatalf("Failed to create materialization: %s", err)
+	}
+	if _, err := offline.UpdateMaterialization(id); err != nil {
+		t.Fatalf("Failed to update materialization: %s", err)
+	}
+	if _, err := online.CreateTable(id.Name, id.Variant, provider.Int); err != nil {
+		t.Fatalf("Failed to create online table: %s", err)
+	}
+	deletion := &DeleteResourceRunner{Offline: offline, Online: online, ID: id}
+	// The second run finds everything already deleted, which isn't an error.
+	for i := 0; i < 2; i++ {
+		watcher, err := deletion.Run()
+		if err != nil {
+			t.Fatalf("Failed to run deletion: %s", err)
+		}
+		if err := watcher.Wait(); err != nil {
+			t.Fatalf("Deletion %d failed: %s", i, err)
+		}
+	}
+	if _, err := online.GetTable(id.Name, id.Variant); err == nil {
+		t.Fatalf("Online table was not deleted")
+	}
+	if _, err := offline.GetResourceTable(id); err == nil {
+		t.Fatalf("Offline table was not deleted")
+	}
+	if _, err := offline.GetMaterialization(first.ID()); err == nil {
+		t.Fatalf("Replaced materialization was not deleted")
+	}
+}
+
+func TestDeleteResourceRunnerFactory(t *testing.T) {
+	config := DeleteResourceRunnerConfig{
+		OfflineType:   "INVALID",
+		OfflineConfig: []byte{},
+		ResourceID:    provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature},
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	if _, err := DeleteResourceRunnerFactory(serialized); err == nil {
+		t.Fatalf("Created deletion runner with an invalid offline provider")
+	}
+	if _, err := DeleteResourceRunnerFactory([]byte("{")); err == nil {
+		t.Fatalf("Created deletion runner from an invalid config")
+	}
+}
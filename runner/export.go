@@ -0,0 +1,113 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// ExportTrainingSetRunner writes a training set that's already been created
+// to files, in the format and at the URI of its Def's Export.
+type ExportTrainingSetRunner struct {
+	Offline  provider.OfflineStore
+	Def      provider.TrainingSetDef
+	Archiver provider.Archiver
+}
+
+func (e *ExportTrainingSetRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{
+		Name:    e.Def.ID.Name,
+		Variant: e.Def.ID.Variant,
+		Type:    provider.ProviderToMetadataResourceType[e.Def.ID.Type],
+	}
+}
+
+func (e *ExportTrainingSetRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (e *ExportTrainingSetRunner) Run() (CompletionWatcher, error) {
+	return e.RunWithContext(context.Background())
+}
+
+func (e *ExportTrainingSetRunner) RunWithContext(ctx context.Context) (CompletionWatcher, error) {
+	if e.Def.Export == nil {
+		return nil, fmt.Errorf("training set %s (%s) has no export", e.Def.ID.Name, e.Def.ID.Variant)
+	}
+	done := make(chan interface{})
+	jobWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		jobWatcher.EndWatch(exportTrainingSet(ctx, e.Offline, e.Archiver, e.Def))
+	}()
+	return jobWatcher, nil
+}
+
+// exportTrainingSet writes a training set's rows to archiver. If archiver is
+// nil, one is opened for the URI of the Def's Export.
+func exportTrainingSet(ctx context.Context, offline provider.OfflineStore, archiver provider.Archiver, def provider.TrainingSetDef) error {
+	if archiver == nil {
+		var err error
+		if archiver, err = provider.NewArchiver(def.Export.URI); err != nil {
+			return fmt.Errorf("configure export archiver: %w", err)
+		}
+	}
+	iter, err := offline.GetTrainingSet(def.ID)
+	if err != nil {
+		return fmt.Errorf("get training set: %w", err)
+	}
+	if _, err := provider.ExportTrainingSet(ctx, archiver, *def.Export, def, iter); err != nil {
+		return fmt.Errorf("export training set: %w", err)
+	}
+	return nil
+}
+
+type ExportTrainingSetRunnerConfig struct {
+	OfflineType   provider.Type
+	OfflineConfig provider.SerializedConfig
+	Def           provider.TrainingSetDef
+}
+
+func (c *ExportTrainingSetRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *ExportTrainingSetRunnerConfig) Deserialize(config Config) error {
+	return json.Unmarshal(config, c)
+}
+
+func ExportTrainingSetRunnerFactory(config Config) (Runner, error) {
+	runnerConfig := &ExportTrainingSetRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize export training set runner config: %v", err)
+	}
+	if runnerConfig.Def.Export == nil {
+		return nil, fmt.Errorf("training set %s (%s) has no export", runnerConfig.Def.ID.Name, runnerConfig.Def.ID.Variant)
+	}
+	offlineStore, err := getOfflineStore(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure offline provider: %v", err)
+	}
+	archiver, err := provider.NewArchiver(runnerConfig.Def.Export.URI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure export archiver: %v", err)
+	}
+	return &ExportTrainingSetRunner{
+		Offline:  offlineStore,
+		Def:      runnerConfig.Def,
+		Archiver: archiver,
+	}, nil
+}
@@ -0,0 +1,87 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/featureform/provider"
+)
+
+func exportTestStore(t *testing.T) (provider.OfflineStore, provider.TrainingSetDef) {
+	store := provider.NewMemoryOfflineStore()
+	featureID := provider.ResourceID{Name: "avg_purchase", Variant: "default", Type: provider.Feature}
+	labelID := provider.ResourceID{Name: "fraud", Variant: "default", Type: provider.Label}
+	ts := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	for id, value := range map[provider.ResourceID]interface{}{featureID: 12, labelID: true} {
+		table, err := store.CreateResourceTable(id, provider.TableSchema{})
+		if err != nil {
+			t.Fatalf("Failed to create table: %s", err)
+		}
+		if err := table.Write(provider.ResourceRecord{Entity: "a", Value: value, TS: ts}); err != nil {
+			t.Fatalf("Failed to write record: %s", err)
+		}
+	}
+	def := provider.TrainingSetDef{
+		ID:       provider.ResourceID{Name: "fraud_training", Variant: "default", Type: provider.TrainingSet},
+		Label:    labelID,
+		Features: []provider.ResourceID{featureID},
+	}
+	return store, def
+}
+
+func TestTrainingSetRunnerUpdateExports(t *testing.T) {
+	dir := t.TempDir()
+	store, def := exportTestStore(t)
+	def.Export = &provider.TrainingSetExport{URI: "file://" + dir, Format: provider.CSVExport}
+	path := filepath.Join(dir, "fraud_training", "default", "part-00000.csv")
+	for _, isUpdate := range []bool{false, true} {
+		runner := TrainingSetRunner{Offline: store, Def: def, IsUpdate: isUpdate}
+		watcher, err := runner.Run()
+		if err != nil {
+			t.Fatalf("Failed to run training set runner: %s", err)
+		}
+		if err := watcher.Wait(); err != nil {
+			t.Fatalf("Training set runner failed: %s", err)
+		}
+		// Created training sets are exported by a job of their own.
+		if _, err := os.Stat(path); !isUpdate && !os.IsNotExist(err) {
+			t.Fatalf("Expected creating training set not to export, got %v", err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read export: %s", err)
+	}
+	if expected := "avg_purchase__default,label\n12,true\n"; string(data) != expected {
+		t.Fatalf("Expected export %q, got %q", expected, string(data))
+	}
+}
+
+func TestExportTrainingSetRunner(t *testing.T) {
+	store, def := exportTestStore(t)
+	if err := store.CreateTrainingSet(def); err != nil {
+		t.Fatalf("Failed to create training set: %s", err)
+	}
+	export := &ExportTrainingSetRunner{Offline: store, Def: def}
+	if _, err := export.Run(); err == nil {
+		t.Fatalf("Expected a training set without an export to fail")
+	}
+	dir := t.TempDir()
+	export.Def.Export = &provider.TrainingSetExport{URI: "file://" + dir, Format: provider.TFRecordExport}
+	watcher, err := export.Run()
+	if err != nil {
+		t.Fatalf("Failed to run export: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Export failed: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "fraud_training", "default", "part-00000.tfrecord")); err != nil {
+		t.Fatalf("Expected export to be written: %s", err)
+	}
+}
@@ -0,0 +1,130 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var imageDigestPattern = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// PinImage returns image pinned to digest, so that the image's tag being
+// moved, or left implicitly at latest, doesn't change what workers run. An
+// image that's already pinned must be pinned to the same digest. The image
+// is returned as is if digest is empty.
+func PinImage(image, digest string) (string, error) {
+	if digest == "" {
+		return image, nil
+	}
+	if !imageDigestPattern.MatchString(digest) {
+		return "", fmt.Errorf("invalid image digest %q, expected sha256:<64 hex characters>", digest)
+	}
+	if i := strings.LastIndex(image, "@"); i >= 0 {
+		if image[i+1:] != digest {
+			return "", fmt.Errorf("image %s is already pinned to a different digest than %s", image, digest)
+		}
+		return image, nil
+	}
+	return fmt.Sprintf("%s@%s", image, digest), nil
+}
+
+// ImageVerifier checks that an image can be trusted before workers are
+// started with it. It returns the image pinned to the digest it verified, so
+// that workers run what was checked even if its tag is moved afterwards.
+type ImageVerifier interface {
+	Verify(ctx context.Context, image string) (string, error)
+}
+
+// CosignVerifier verifies that images are signed by Key with the cosign CLI.
+// Images that are pinned to a digest are only verified once, since their
+// contents can't change. Those that aren't are verified every time, since
+// their tag could have been moved, and are pinned to the digest cosign
+// verified.
+type CosignVerifier struct {
+	// Key is the public key, or a KMS or Kubernetes secret reference to one,
+	// that images must be signed with.
+	Key string
+	// Path is the cosign binary. It's "cosign" on the PATH if it isn't set.
+	Path string
+
+	mu       sync.Mutex
+	verified map[string]bool
+}
+
+func (c *CosignVerifier) Verify(ctx context.Context, image string) (string, error) {
+	pinned := strings.Contains(image, "@")
+	if pinned && c.isVerified(image) {
+		return image, nil
+	}
+	path := c.Path
+	if path == "" {
+		path = "cosign"
+	}
+	var output, errOutput bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "verify", "--key", c.Key, "--output", "json", image)
+	cmd.Stdout = &output
+	cmd.Stderr = &errOutput
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("cosign verify %s: %w: %s", image, err, strings.TrimSpace(errOutput.String()))
+	}
+	if !pinned {
+		digest, err := verifiedDigest(output.Bytes())
+		if err != nil {
+			return "", fmt.Errorf("cosign verify %s: %w", image, err)
+		}
+		if image, err = PinImage(image, digest); err != nil {
+			return "", err
+		}
+	}
+	c.setVerified(image)
+	return image, nil
+}
+
+// verifiedDigest reads the digest of the image that cosign verified from the
+// signature payloads it outputs.
+func verifiedDigest(output []byte) (string, error) {
+	var payloads []struct {
+		Critical struct {
+			Image struct {
+				Digest string `json:"docker-manifest-digest"`
+			} `json:"image"`
+		} `json:"critical"`
+	}
+	if err := json.Unmarshal(output, &payloads); err != nil {
+		return "", fmt.Errorf("read verified signatures: %w", err)
+	}
+	if len(payloads) == 0 {
+		return "", fmt.Errorf("no verified signatures")
+	}
+	digest := payloads[0].Critical.Image.Digest
+	for _, payload := range payloads[1:] {
+		if payload.Critical.Image.Digest != digest {
+			return "", fmt.Errorf("signatures are of different digests")
+		}
+	}
+	return digest, nil
+}
+
+func (c *CosignVerifier) isVerified(image string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.verified[image]
+}
+
+func (c *CosignVerifier) setVerified(image string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.verified == nil {
+		c.verified = make(map[string]bool)
+	}
+	c.verified[image] = true
+}
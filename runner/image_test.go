@@ -0,0 +1,82 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPinImage(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	tests := []struct {
+		name     string
+		image    string
+		digest   string
+		expected string
+		fails    bool
+	}{
+		{"NoDigest", "featureformcom/worker:v1", "", "featureformcom/worker:v1", false},
+		{"Tag", "featureformcom/worker:v1", digest, "featureformcom/worker:v1@" + digest, false},
+		{"Untagged", "registry:5000/worker", digest, "registry:5000/worker@" + digest, false},
+		{"AlreadyPinned", "featureformcom/worker@" + digest, digest, "featureformcom/worker@" + digest, false},
+		{"OtherDigest", "featureformcom/worker@sha256:" + strings.Repeat("b", 64), digest, "", true},
+		{"InvalidDigest", "featureformcom/worker", "latest", "", true},
+	}
+	for _, test := range tests {
+		image, err := PinImage(test.image, test.digest)
+		if test.fails {
+			if err == nil {
+				t.Fatalf("%s: expected pinning to fail, got %s", test.name, image)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: failed to pin image: %v", test.name, err)
+		}
+		if image != test.expected {
+			t.Fatalf("%s: expected %s, got %s", test.name, test.expected, image)
+		}
+	}
+}
+
+func TestCosignVerifier(t *testing.T) {
+	ctx := context.Background()
+	pinned := "featureformcom/worker@sha256:" + strings.Repeat("a", 64)
+	verifier := &CosignVerifier{Key: "cosign.pub", Path: "true"}
+	if image, err := verifier.Verify(ctx, pinned); err != nil || image != pinned {
+		t.Fatalf("Failed to verify image: %s, %v", image, err)
+	}
+	verifier.Path = "false"
+	if _, err := verifier.Verify(ctx, pinned); err != nil {
+		t.Fatalf("Expected a verified pinned image not to be verified again: %v", err)
+	}
+	if _, err := verifier.Verify(ctx, "featureformcom/worker:v1"); err == nil {
+		t.Fatalf("Expected an unsigned image to fail verification")
+	}
+}
+
+func TestCosignVerifierPinsTag(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("b", 64)
+	cosign := filepath.Join(t.TempDir(), "cosign")
+	script := fmt.Sprintf("#!/bin/sh\necho '[{\"critical\":{\"image\":{\"docker-manifest-digest\":\"%s\"}}}]'\n", digest)
+	if err := os.WriteFile(cosign, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write cosign script: %v", err)
+	}
+	verifier := &CosignVerifier{Key: "cosign.pub", Path: cosign}
+	image, err := verifier.Verify(context.Background(), "featureformcom/worker:v1")
+	if err != nil {
+		t.Fatalf("Failed to verify image: %v", err)
+	}
+	// The tag is run by the digest that was verified, whatever it points
+	// to by the time the job starts.
+	if expected := "featureformcom/worker:v1@" + digest; image != expected {
+		t.Fatalf("Expected verified image %s, got %s", expected, image)
+	}
+}
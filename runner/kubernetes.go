@@ -5,15 +5,18 @@
 package runner
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
 	"github.com/google/uuid"
-	"github.com/gorhill/cronexpr"
 	batchv1 "k8s.io/api/batch/v1"
 	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	watch "k8s.io/apimachinery/pkg/watch"
 	kubernetes "k8s.io/client-go/kubernetes"
@@ -22,6 +25,19 @@ import (
 
 var Namespace string = "default"
 
+// CronStartingDeadline is how late a cron job's run can start before
+// Kubernetes skips it, so runs that were missed while the cluster was down
+// aren't all started at once when it's back. There's no deadline if it's zero.
+var CronStartingDeadline time.Duration
+
+func cronStartingDeadlineSeconds() *int64 {
+	if CronStartingDeadline <= 0 {
+		return nil
+	}
+	seconds := int64(CronStartingDeadline.Seconds())
+	return &seconds
+}
+
 type CronSchedule string
 
 func GetJobName(id metadata.ResourceID) string {
@@ -34,10 +50,11 @@ func GetCronJobName(id metadata.ResourceID) string {
 }
 
 func makeCronSchedule(schedule string) (*CronSchedule, error) {
-	if _, err := cronexpr.Parse(schedule); err != nil {
-		return nil, fmt.Errorf("invalid cron expression: %v", err)
+	parsed, err := metadata.ParseSchedule(schedule)
+	if err != nil {
+		return nil, err
 	}
-	cronSchedule := CronSchedule(schedule)
+	cronSchedule := CronSchedule(parsed.Cron())
 	return &cronSchedule, nil
 }
 
@@ -89,41 +106,168 @@ func generateKubernetesEnvVars(envVars map[string]string) []v1.EnvVar {
 	return kubeEnvVars
 }
 
-func newJobSpec(config KubernetesRunnerConfig) batchv1.JobSpec {
+// jobLabel is set on every job, cron job and pod so that a job's pods can be
+// spread out, and so that everything made for a resource can be found to be
+// cleaned up once it's deleted.
+const jobLabel = "featureform.com/job"
+
+func jobLabels(jobName string) map[string]string {
+	return map[string]string{jobLabel: jobLabelValue(jobName)}
+}
+
+// podLabels are the labels configured for a job's pods along with its
+// jobLabel, which they can't replace.
+func podLabels(jobName string, pod KubernetesPodConfig) map[string]string {
+	labels := make(map[string]string, len(pod.Labels)+1)
+	for key, value := range pod.Labels {
+		labels[key] = value
+	}
+	labels[jobLabel] = jobLabelValue(jobName)
+	return labels
+}
+
+// jobObjectMeta is the metadata of the job or cron job that runs jobSpec,
+// which is labeled and annotated like the job's pods.
+func jobObjectMeta(jobName, namespace string, jobSpec *batchv1.JobSpec) metav1.ObjectMeta {
+	labels := jobLabels(jobName)
+	for key, value := range jobSpec.Template.Labels {
+		if key != jobLabel {
+			labels[key] = value
+		}
+	}
+	return metav1.ObjectMeta{
+		Name:        jobName,
+		Namespace:   namespace,
+		Labels:      labels,
+		Annotations: jobSpec.Template.Annotations,
+	}
+}
+
+func newJobSpec(jobName string, config KubernetesRunnerConfig) batchv1.JobSpec {
 	containerID := uuid.New().String()
 	envVars := generateKubernetesEnvVars(config.EnvVars)
-	//only indexed completion if copyRunner
+	// Chunk jobs are indexed so that pod i always copies chunk i. A retried
+	// pod keeps its index, so no chunk is copied twice or skipped.
 	var completionMode batchv1.CompletionMode
-	if config.EnvVars["Name"] == "Copy to online" {
+	if config.EnvVars["NAME"] == string(COPY_TO_ONLINE) {
 		completionMode = batchv1.IndexedCompletion
 	} else {
 		completionMode = batchv1.NonIndexedCompletion
 	}
+	volumes, mounts := credentialsVolume()
 	return batchv1.JobSpec{
-		Completions:    &config.NumTasks,
-		Parallelism:    &config.NumTasks,
-		CompletionMode: &completionMode,
+		Completions:             &config.NumTasks,
+		Parallelism:             &config.NumTasks,
+		CompletionMode:          &completionMode,
+		TTLSecondsAfterFinished: config.Pod.TTLSecondsAfterFinished,
 		Template: v1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels:      podLabels(jobName, config.Pod),
+				Annotations: config.Pod.Annotations,
+			},
 			Spec: v1.PodSpec{
 				Containers: []v1.Container{
 					{
-						Name:  containerID,
-						Image: config.Image,
-						Env:   envVars,
+						Name:         containerID,
+						Image:        config.Image,
+						Env:          envVars,
+						VolumeMounts: mounts,
+						Resources:    config.Pod.Resources,
 					},
 				},
-				RestartPolicy: v1.RestartPolicyNever,
+				Volumes:                   volumes,
+				RestartPolicy:             v1.RestartPolicyNever,
+				TopologySpreadConstraints: spreadConstraints(jobLabels(jobName)),
+				NodeSelector:              config.Pod.NodeSelector,
+				Tolerations:               config.Pod.Tolerations,
+				ServiceAccountName:        config.Pod.ServiceAccount,
 			},
 		},
 	}
 
 }
 
+// credentialsVolume projects the provider credentials secret into the pod at
+// CredentialsDir. It's optional so that jobs whose configs hold no references
+// still start before any credentials have been stored.
+func credentialsVolume() ([]v1.Volume, []v1.VolumeMount) {
+	const volumeName = "provider-credentials"
+	optional := true
+	volumes := []v1.Volume{
+		{
+			Name: volumeName,
+			VolumeSource: v1.VolumeSource{
+				Projected: &v1.ProjectedVolumeSource{
+					Sources: []v1.VolumeProjection{
+						{
+							Secret: &v1.SecretProjection{
+								LocalObjectReference: v1.LocalObjectReference{Name: CredentialsSecretName},
+								Optional:             &optional,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	mounts := []v1.VolumeMount{
+		{Name: volumeName, MountPath: CredentialsDir, ReadOnly: true},
+	}
+	return volumes, mounts
+}
+
+// spreadConstraints spread a job's pods across nodes and zones as evenly as
+// the scheduler can without leaving any of them pending.
+func spreadConstraints(labels map[string]string) []v1.TopologySpreadConstraint {
+	topologies := []string{"kubernetes.io/hostname", "topology.kubernetes.io/zone"}
+	constraints := make([]v1.TopologySpreadConstraint, len(topologies))
+	for i, topology := range topologies {
+		constraints[i] = v1.TopologySpreadConstraint{
+			MaxSkew:           1,
+			TopologyKey:       topology,
+			WhenUnsatisfiable: v1.ScheduleAnyway,
+			LabelSelector:     &metav1.LabelSelector{MatchLabels: labels},
+		}
+	}
+	return constraints
+}
+
+// jobLabelValue shortens a job name to the 63 characters allowed in a label
+// value.
+func jobLabelValue(jobName string) string {
+	const maxLength = 63
+	if len(jobName) <= maxLength {
+		return jobName
+	}
+	return strings.Trim(jobName[:maxLength], ".-_")
+}
+
 type KubernetesRunnerConfig struct {
 	EnvVars  map[string]string
 	Resource metadata.ResourceID
 	Image    string
-	NumTasks int32
+	// ImageDigest pins Image to a digest, such as "sha256:...", so the job
+	// runs that image whatever its tag points to.
+	ImageDigest string
+	NumTasks    int32
+	Pod         KubernetesPodConfig
+}
+
+// KubernetesPodConfig is what a job's pods are given and where they're
+// scheduled. Kubernetes' defaults are used for what isn't set.
+type KubernetesPodConfig struct {
+	Resources      v1.ResourceRequirements
+	NodeSelector   map[string]string
+	Tolerations    []v1.Toleration
+	ServiceAccount string
+	// Labels and Annotations are set on the job as well as its pods.
+	Labels      map[string]string
+	Annotations map[string]string
+	// TTLSecondsAfterFinished is how long Kubernetes keeps a job and its
+	// pods after it finishes. It should leave the coordinator time to see
+	// the job finish. Jobs are kept until their resource is deleted if it's
+	// nil.
+	TTLSecondsAfterFinished *int32
 }
 
 type JobClient interface {
@@ -164,6 +308,24 @@ func (k KubernetesCompletionWatcher) String() string {
 	return fmt.Sprintf("%d jobs succeeded. %d jobs active. %d jobs failed", job.Status.Succeeded, job.Status.Active, job.Status.Failed)
 }
 
+// Progress is the number of the job's completions that have succeeded, with
+// an ETA from when the job started.
+func (k KubernetesCompletionWatcher) Progress() Progress {
+	job, err := k.jobClient.Get()
+	if err != nil {
+		return Progress{}
+	}
+	total := 1
+	if job.Spec.Completions != nil {
+		total = int(*job.Spec.Completions)
+	}
+	var started time.Time
+	if job.Status.StartTime != nil {
+		started = job.Status.StartTime.Time
+	}
+	return taskProgress(int(job.Status.Succeeded), total, started)
+}
+
 func (k KubernetesCompletionWatcher) Wait() error {
 	watcher, err := k.jobClient.Watch()
 	if err != nil {
@@ -184,6 +346,20 @@ func (k KubernetesCompletionWatcher) Wait() error {
 	return nil
 }
 
+type jobDeleter interface {
+	Delete() error
+}
+
+// Cancel deletes the job along with its pods, which stops the job if it's
+// still running.
+func (k KubernetesCompletionWatcher) Cancel() error {
+	deleter, ok := k.jobClient.(jobDeleter)
+	if !ok {
+		return fmt.Errorf("job client %T can't delete jobs", k.jobClient)
+	}
+	return deleter.Delete()
+}
+
 func (k KubernetesCompletionWatcher) Err() error {
 	job, err := k.jobClient.Get()
 	if err != nil {
@@ -218,8 +394,13 @@ func (k KubernetesRunner) ScheduleJob(schedule CronSchedule) error {
 }
 
 func NewKubernetesRunner(config KubernetesRunnerConfig) (CronRunner, error) {
-	jobSpec := newJobSpec(config)
+	image, err := PinImage(config.Image, config.ImageDigest)
+	if err != nil {
+		return nil, err
+	}
+	config.Image = image
 	jobName := GetJobName(config.Resource)
+	jobSpec := newJobSpec(jobName, config)
 	jobClient, err := NewKubernetesJobClient(jobName, Namespace)
 	if err != nil {
 		return nil, err
@@ -248,24 +429,71 @@ func (k KubernetesJobClient) UpdateCronJob(cronJob *batchv1.CronJob) (*batchv1.C
 	return k.Clientset.BatchV1().CronJobs(k.Namespace).Update(context.TODO(), cronJob, metav1.UpdateOptions{})
 }
 
+func (k KubernetesJobClient) Delete() error {
+	propagation := metav1.DeletePropagationBackground
+	return k.Clientset.BatchV1().Jobs(k.Namespace).Delete(context.TODO(), k.JobName, metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
+// DeleteAll deletes the cron job, jobs and pods that were made for the job,
+// including the jobs its cron job started, so none are left running against
+// a resource that's gone.
+func (k KubernetesJobClient) DeleteAll() error {
+	return deleteLabeledJobs(context.TODO(), k.Clientset, k.Namespace, k.JobName)
+}
+
+// deleteLabeledJobs deletes the objects labeled with jobName. Pods are
+// deleted along with their jobs, but are also deleted on their own in case
+// their job was already removed without them.
+func deleteLabeledJobs(ctx context.Context, clientset kubernetes.Interface, namespace, jobName string) error {
+	propagation := metav1.DeletePropagationBackground
+	deleteOpts := metav1.DeleteOptions{PropagationPolicy: &propagation}
+	listOpts := metav1.ListOptions{LabelSelector: fmt.Sprintf("%s=%s", jobLabel, jobLabelValue(jobName))}
+	cronJobs := clientset.BatchV1().CronJobs(namespace)
+	if err := cronJobs.Delete(ctx, jobName, deleteOpts); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("delete cron job %s: %w", jobName, err)
+	}
+	jobs := clientset.BatchV1().Jobs(namespace)
+	jobList, err := jobs.List(ctx, listOpts)
+	if err != nil {
+		return fmt.Errorf("list jobs of %s: %w", jobName, err)
+	}
+	for _, job := range jobList.Items {
+		if err := jobs.Delete(ctx, job.Name, deleteOpts); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete job %s: %w", job.Name, err)
+		}
+	}
+	pods := clientset.CoreV1().Pods(namespace)
+	podList, err := pods.List(ctx, listOpts)
+	if err != nil {
+		return fmt.Errorf("list pods of %s: %w", jobName, err)
+	}
+	for _, pod := range podList.Items {
+		if err := pods.Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete pod %s: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
+
 func (k KubernetesJobClient) Watch() (watch.Interface, error) {
 	return k.Clientset.BatchV1().Jobs(k.Namespace).Watch(context.TODO(), metav1.ListOptions{FieldSelector: fmt.Sprintf("metadata.name=%s", k.JobName)})
 }
 
 func (k KubernetesJobClient) Create(jobSpec *batchv1.JobSpec) (*batchv1.Job, error) {
-	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: k.JobName, Namespace: k.Namespace}, Spec: *jobSpec}
+	job := &batchv1.Job{ObjectMeta: jobObjectMeta(k.JobName, k.Namespace, jobSpec), Spec: *jobSpec}
 	return k.Clientset.BatchV1().Jobs(k.Namespace).Create(context.TODO(), job, metav1.CreateOptions{})
 }
 
 func (k KubernetesJobClient) SetJobSchedule(schedule CronSchedule, jobSpec *batchv1.JobSpec) error {
+	meta := jobObjectMeta(k.JobName, k.Namespace, jobSpec)
 	cronJob := &batchv1.CronJob{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      k.JobName,
-			Namespace: k.Namespace},
+		ObjectMeta: meta,
 		Spec: batchv1.CronJobSpec{
-			Schedule: string(schedule),
+			Schedule:                string(schedule),
+			StartingDeadlineSeconds: cronStartingDeadlineSeconds(),
 			JobTemplate: batchv1.JobTemplateSpec{
-				Spec: *jobSpec,
+				ObjectMeta: metav1.ObjectMeta{Labels: meta.Labels, Annotations: meta.Annotations},
+				Spec:       *jobSpec,
 			},
 		},
 	}
@@ -276,14 +504,15 @@ func (k KubernetesJobClient) SetJobSchedule(schedule CronSchedule, jobSpec *batc
 }
 
 func (k KubernetesJobClient) UpdateJobSchedule(schedule CronSchedule, jobSpec *batchv1.JobSpec) error {
+	meta := jobObjectMeta(k.JobName, k.Namespace, jobSpec)
 	cronJob := &batchv1.CronJob{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      k.JobName,
-			Namespace: k.Namespace},
+		ObjectMeta: meta,
 		Spec: batchv1.CronJobSpec{
-			Schedule: string(schedule),
+			Schedule:                string(schedule),
+			StartingDeadlineSeconds: cronStartingDeadlineSeconds(),
 			JobTemplate: batchv1.JobTemplateSpec{
-				Spec: *jobSpec,
+				ObjectMeta: metav1.ObjectMeta{Labels: meta.Labels, Annotations: meta.Annotations},
+				Spec:       *jobSpec,
 			},
 		},
 	}
@@ -302,6 +531,41 @@ func (k KubernetesJobClient) GetJobSchedule(jobName string) (CronSchedule, error
 	return CronSchedule(job.Spec.Schedule), nil
 }
 
+// StoreProviderCredentials saves a provider's config in the credentials
+// secret so that workers can resolve references to it.
+func StoreProviderCredentials(providerName string, config provider.SerializedConfig) error {
+	kubeConfig, err := rest.InClusterConfig()
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(kubeConfig)
+	if err != nil {
+		return err
+	}
+	secrets := clientset.CoreV1().Secrets(Namespace)
+	key := CredentialKey(providerName)
+	secret, err := secrets.Get(context.TODO(), CredentialsSecretName, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		secret = &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: CredentialsSecretName, Namespace: Namespace},
+			Data:       map[string][]byte{key: config},
+		}
+		_, err = secrets.Create(context.TODO(), secret, metav1.CreateOptions{})
+		return err
+	} else if err != nil {
+		return err
+	}
+	if bytes.Equal(secret.Data[key], config) {
+		return nil
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[key] = config
+	_, err = secrets.Update(context.TODO(), secret, metav1.UpdateOptions{})
+	return err
+}
+
 func NewKubernetesJobClient(name string, namespace string) (*KubernetesJobClient, error) {
 	kubeConfig, err := rest.InClusterConfig()
 	if err != nil {
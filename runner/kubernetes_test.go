@@ -5,16 +5,22 @@
 package runner
 
 import (
+	"context"
 	"errors"
 	"github.com/google/uuid"
 	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	watch "k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	"strings"
 	"testing"
 )
 
 func NewMockKubernetesRunner(config KubernetesRunnerConfig) (CronRunner, error) {
-	jobSpec := newJobSpec(config)
 	jobName := uuid.New().String()
+	jobSpec := newJobSpec(jobName, config)
 	namespace := "default"
 	jobClient := MockJobClient{
 		JobName:   jobName,
@@ -80,6 +86,117 @@ func TestKubernetesRunnerCreate(t *testing.T) {
 	completionWatcher.String()
 }
 
+func TestChunkJobSpec(t *testing.T) {
+	config := KubernetesRunnerConfig{EnvVars: map[string]string{"NAME": string(COPY_TO_ONLINE)}, Image: "test", NumTasks: 4}
+	jobSpec := newJobSpec("feature-variant-2", config)
+	if *jobSpec.CompletionMode != batchv1.IndexedCompletion {
+		t.Fatalf("Chunk job has completion mode %s, expected %s", *jobSpec.CompletionMode, batchv1.IndexedCompletion)
+	}
+	if *jobSpec.Completions != 4 {
+		t.Fatalf("Chunk job has %d completions, expected 4", *jobSpec.Completions)
+	}
+	labels := jobSpec.Template.ObjectMeta.Labels
+	if labels[jobLabel] != "feature-variant-2" {
+		t.Fatalf("Pods have labels %v, expected %s=feature-variant-2", labels, jobLabel)
+	}
+	constraints := jobSpec.Template.Spec.TopologySpreadConstraints
+	if len(constraints) == 0 {
+		t.Fatalf("Chunk job has no spread constraints")
+	}
+	for _, constraint := range constraints {
+		if constraint.LabelSelector.MatchLabels[jobLabel] != labels[jobLabel] {
+			t.Fatalf("Spread constraint %s doesn't select the job's pods", constraint.TopologyKey)
+		}
+	}
+	other := newJobSpec("feature-variant-2", KubernetesRunnerConfig{EnvVars: map[string]string{"NAME": MATERIALIZE}, NumTasks: 1})
+	if *other.CompletionMode != batchv1.NonIndexedCompletion {
+		t.Fatalf("Materialize job has completion mode %s, expected %s", *other.CompletionMode, batchv1.NonIndexedCompletion)
+	}
+}
+
+func TestPodJobSpec(t *testing.T) {
+	config := KubernetesRunnerConfig{
+		EnvVars:  map[string]string{"NAME": MATERIALIZE},
+		Image:    "spark-worker",
+		NumTasks: 1,
+		Pod: KubernetesPodConfig{
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{v1.ResourceMemory: resource.MustParse("4Gi")},
+				Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("8Gi")},
+			},
+			NodeSelector:   map[string]string{"pool": "workers"},
+			Tolerations:    []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpEqual, Value: "workers", Effect: v1.TaintEffectNoSchedule}},
+			ServiceAccount: "featureform-worker",
+		},
+	}
+	pod := newJobSpec("feature-variant-2", config).Template.Spec
+	container := pod.Containers[0]
+	if container.Image != "spark-worker" {
+		t.Fatalf("Container has image %s, expected spark-worker", container.Image)
+	}
+	if memory := container.Resources.Limits[v1.ResourceMemory]; memory.String() != "8Gi" {
+		t.Fatalf("Container has memory limit %s, expected 8Gi", memory.String())
+	}
+	if memory := container.Resources.Requests[v1.ResourceMemory]; memory.String() != "4Gi" {
+		t.Fatalf("Container has memory request %s, expected 4Gi", memory.String())
+	}
+	if pod.NodeSelector["pool"] != "workers" || len(pod.Tolerations) != 1 || pod.ServiceAccountName != "featureform-worker" {
+		t.Fatalf("Pod isn't scheduled as configured: %+v", pod)
+	}
+}
+
+func TestPodLabelsAndTTL(t *testing.T) {
+	ttl := int32(3600)
+	config := KubernetesRunnerConfig{
+		EnvVars:  map[string]string{"NAME": MATERIALIZE},
+		NumTasks: 1,
+		Pod: KubernetesPodConfig{
+			Labels:                  map[string]string{"team": "ml", jobLabel: "other"},
+			Annotations:             map[string]string{"cost-center": "42"},
+			TTLSecondsAfterFinished: &ttl,
+		},
+	}
+	jobSpec := newJobSpec("feature-variant-2", config)
+	if jobSpec.TTLSecondsAfterFinished == nil || *jobSpec.TTLSecondsAfterFinished != ttl {
+		t.Fatalf("Job has TTL %v, expected %d", jobSpec.TTLSecondsAfterFinished, ttl)
+	}
+	pod := jobSpec.Template.ObjectMeta
+	if pod.Labels["team"] != "ml" || pod.Labels[jobLabel] != "feature-variant-2" {
+		t.Fatalf("Pods have labels %v, expected team=ml and %s=feature-variant-2", pod.Labels, jobLabel)
+	}
+	if pod.Annotations["cost-center"] != "42" {
+		t.Fatalf("Pods have annotations %v, expected cost-center=42", pod.Annotations)
+	}
+	job := jobObjectMeta("feature-variant-2", "default", &jobSpec)
+	if job.Name != "feature-variant-2" || job.Labels["team"] != "ml" || job.Labels[jobLabel] != "feature-variant-2" || job.Annotations["cost-center"] != "42" {
+		t.Fatalf("Job isn't labeled like its pods: %+v", job)
+	}
+	if spec := newJobSpec("feature-variant-2", KubernetesRunnerConfig{NumTasks: 1}); spec.TTLSecondsAfterFinished != nil {
+		t.Fatalf("Job has TTL %d, expected none", *spec.TTLSecondsAfterFinished)
+	}
+}
+
+func TestConfigProviderTypes(t *testing.T) {
+	config, err := (&MaterializedRunnerConfig{OnlineType: "REDIS_ONLINE", OfflineType: "SPARK_OFFLINE"}).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %v", err)
+	}
+	types := ConfigProviderTypes(config)
+	if len(types) != 2 || types[0] != "SPARK_OFFLINE" || types[1] != "REDIS_ONLINE" {
+		t.Fatalf("Config has provider types %v, expected the offline type first", types)
+	}
+	if types := ConfigProviderTypes(Config("not json")); len(types) != 0 {
+		t.Fatalf("Invalid config has provider types %v", types)
+	}
+}
+
+func TestJobLabelValue(t *testing.T) {
+	long := strings.Repeat("a", 62) + ".b"
+	if value := jobLabelValue(long); len(value) > 63 || strings.HasSuffix(value, ".") {
+		t.Fatalf("Invalid label value %s", value)
+	}
+}
+
 type MockJobClientBroken struct{}
 
 func (m MockJobClientBroken) Create(jobSpec *batchv1.JobSpec) (*batchv1.Job, error) {
@@ -110,6 +227,36 @@ func (m MockJobClientBroken) GetJobSchedule(jobName string) (CronSchedule, error
 	return CronSchedule(""), errors.New("cannot get job schedule")
 }
 
+func TestDeleteLabeledJobs(t *testing.T) {
+	jobName := "avg.amount-v1-4"
+	labeled := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "default", Labels: jobLabels(jobName)}
+	}
+	other := metav1.ObjectMeta{Name: "other-v1-4", Namespace: "default", Labels: jobLabels("other-v1-4")}
+	clientset := fake.NewSimpleClientset(
+		&batchv1.CronJob{ObjectMeta: labeled(jobName)},
+		&batchv1.Job{ObjectMeta: labeled(jobName + "-27800000")},
+		&v1.Pod{ObjectMeta: labeled(jobName + "-27800000-abcde")},
+		&batchv1.Job{ObjectMeta: other},
+		&v1.Pod{ObjectMeta: other},
+	)
+	if err := deleteLabeledJobs(context.Background(), clientset, "default", jobName); err != nil {
+		t.Fatalf("Failed to delete jobs: %s", err)
+	}
+	cronJobs, _ := clientset.BatchV1().CronJobs("default").List(context.Background(), metav1.ListOptions{})
+	jobs, _ := clientset.BatchV1().Jobs("default").List(context.Background(), metav1.ListOptions{})
+	pods, _ := clientset.CoreV1().Pods("default").List(context.Background(), metav1.ListOptions{})
+	if len(cronJobs.Items) != 0 {
+		t.Fatalf("Expected cron job to be deleted, got %v", cronJobs.Items)
+	}
+	if len(jobs.Items) != 1 || jobs.Items[0].Name != other.Name || len(pods.Items) != 1 || pods.Items[0].Name != other.Name {
+		t.Fatalf("Expected only other resource's job and pod to be left, got %v, %v", jobs.Items, pods.Items)
+	}
+	if err := deleteLabeledJobs(context.Background(), clientset, "default", jobName); err != nil {
+		t.Fatalf("Expected deleting jobs again to be a no-op: %s", err)
+	}
+}
+
 func TestJobClientCreateFail(t *testing.T) {
 	runner := KubernetesRunner{
 		jobClient: MockJobClientBroken{},
@@ -5,21 +5,55 @@
 package runner
 
 import (
+	"encoding/json"
 	"fmt"
+
+	"github.com/featureform/provider"
 )
 
 type RunnerName string
 
 const (
-	COPY_TO_ONLINE        RunnerName = "Copy to online"
-	CREATE_TRAINING_SET              = "Create training set"
-	REGISTER_SOURCE                  = "Register source"
-	CREATE_TRANSFORMATION            = "Create transformation"
-	MATERIALIZE                      = "Materialize"
+	COPY_TO_ONLINE           RunnerName = "Copy to online"
+	CREATE_TRAINING_SET                 = "Create training set"
+	REGISTER_SOURCE                     = "Register source"
+	CREATE_TRANSFORMATION               = "Create transformation"
+	MATERIALIZE                         = "Materialize"
+	MIGRATE_ONLINE                      = "Migrate online store"
+	COMPACT_MATERIALIZATIONS            = "Compact materializations"
+	MAINTAIN_PROVIDER                   = "Maintain provider"
+	DELETE_RESOURCE                     = "Delete resource"
+	COPY_OFFLINE                        = "Copy offline table"
+	VALIDATE                            = "Validate"
+	EXPORT_TRAINING_SET                 = "Export training set"
+	STREAM_TO_ONLINE                    = "Stream to online"
 )
 
 type Config []byte
 
+// ConfigProviderTypes returns the types of the providers that a runner's
+// config runs against. The offline provider, which does the heavy lifting
+// in most jobs, comes first. Configs that aren't JSON have none.
+func ConfigProviderTypes(config Config) []provider.Type {
+	var fields struct {
+		OfflineType     provider.Type
+		ProviderType    provider.Type
+		SourceType      provider.Type
+		DestinationType provider.Type
+		OnlineType      provider.Type
+	}
+	if err := json.Unmarshal(config, &fields); err != nil {
+		return nil
+	}
+	var types []provider.Type
+	for _, t := range []provider.Type{fields.OfflineType, fields.ProviderType, fields.SourceType, fields.DestinationType, fields.OnlineType} {
+		if t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
 type RunnerConfig interface {
 	Serialize() (Config, error)
 	Deserialize(config Config) error
@@ -42,6 +42,10 @@ func (m *MockCompletionWatcher) Err() error {
 	return nil
 }
 
+func (m *MockCompletionWatcher) Progress() Progress {
+	return Progress{}
+}
+
 func TestRegisterAndCreate(t *testing.T) {
 	mockRunner := &MockRunner{}
 	mockConfig := []byte{}
@@ -0,0 +1,65 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultLogger is what runners log to when they aren't given a logger.
+var defaultLogger = newDefaultLogger()
+
+func newDefaultLogger() *zap.SugaredLogger {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		return zap.NewNop().Sugar()
+	}
+	return logger.Sugar()
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx that runners started with it log to.
+func WithLogger(ctx context.Context, logger *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the logger that was set on ctx with WithLogger, or the
+// default logger if there isn't one.
+func Logger(ctx context.Context) *zap.SugaredLogger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.SugaredLogger); ok {
+		return logger
+	}
+	return defaultLogger
+}
+
+// LogCapture holds the end of what was logged to a logger returned by
+// CaptureLogs, such as to be kept with a job's run.
+type LogCapture struct {
+	tail *tailBuffer
+}
+
+// CaptureLogs returns a logger that logs to logger, and to the returned
+// capture, which keeps the last limit bytes of what was logged at info level
+// or above.
+func CaptureLogs(logger *zap.SugaredLogger, limit int) (*zap.SugaredLogger, *LogCapture) {
+	capture := &LogCapture{tail: &tailBuffer{limit: limit}}
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.AddSync(capture.tail), zap.InfoLevel)
+	captured := logger.Desugar().WithOptions(zap.WrapCore(func(base zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(base, core)
+	}))
+	return captured.Sugar(), capture
+}
+
+// String returns what was captured, one entry per line. The first line can
+// be cut short once more than the limit was logged.
+func (c *LogCapture) String() string {
+	return c.tail.String()
+}
@@ -0,0 +1,49 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestContextLogger(t *testing.T) {
+	if Logger(context.Background()) != defaultLogger {
+		t.Fatalf("Expected runners to log to the default logger without one set")
+	}
+	logger := zap.NewNop().Sugar()
+	if Logger(WithLogger(context.Background(), logger)) != logger {
+		t.Fatalf("Expected runners to log to the logger set on their context")
+	}
+}
+
+func TestCaptureLogs(t *testing.T) {
+	logger, logs := CaptureLogs(zap.NewNop().Sugar(), 1024)
+	logger.Infow("Copying rows", "rows", 10)
+	logger.Debugw("Getting number of rows")
+	logger.Warnw("Could not record throughput", "error", "unavailable")
+	captured := logs.String()
+	if !strings.Contains(captured, "Copying rows") || !strings.Contains(captured, `"rows": 10`) {
+		t.Fatalf("Info entry wasn't captured: %s", captured)
+	}
+	if !strings.Contains(captured, "warn") || !strings.Contains(captured, "unavailable") {
+		t.Fatalf("Warning wasn't captured: %s", captured)
+	}
+	if strings.Contains(captured, "Getting number of rows") {
+		t.Fatalf("Debug entry was captured: %s", captured)
+	}
+	if lines := strings.Split(captured, "\n"); len(lines) != 2 {
+		t.Fatalf("Expected an entry per line, got %d lines: %s", len(lines), captured)
+	}
+	for i := 0; i < 100; i++ {
+		logger.Infow("Copying rows", "rows", i)
+	}
+	if captured := logs.String(); len(captured) > 1024 || !strings.HasSuffix(captured, `{"rows": 99}`) {
+		t.Fatalf("Expected the last 1024 bytes of logs to be kept, got %d bytes: %s", len(captured), captured)
+	}
+}
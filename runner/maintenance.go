@@ -0,0 +1,83 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// MaintenanceRunner runs a maintenance task on a provider.
+type MaintenanceRunner struct {
+	Provider provider.MaintainableStore
+	Task     provider.MaintenanceTask
+}
+
+func (m *MaintenanceRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (m *MaintenanceRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (m *MaintenanceRunner) Run() (CompletionWatcher, error) {
+	return m.RunWithContext(context.Background())
+}
+
+// RunWithContext stops the task's statements once ctx is cancelled, which
+// matters for vacuums of large tables.
+func (m *MaintenanceRunner) RunWithContext(ctx context.Context) (CompletionWatcher, error) {
+	done := make(chan interface{})
+	jobWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		jobWatcher.EndWatch(m.Provider.RunMaintenance(ctx, m.Task))
+	}()
+	return jobWatcher, nil
+}
+
+type MaintenanceRunnerConfig struct {
+	ProviderType   provider.Type
+	ProviderConfig provider.SerializedConfig
+	Task           provider.MaintenanceTask
+}
+
+func (m *MaintenanceRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (m *MaintenanceRunnerConfig) Deserialize(config Config) error {
+	return json.Unmarshal(config, m)
+}
+
+func MaintenanceRunnerFactory(config Config) (Runner, error) {
+	runnerConfig := &MaintenanceRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize maintenance runner config: %v", err)
+	}
+	p, err := getProvider(runnerConfig.ProviderType, runnerConfig.ProviderConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure provider: %v", err)
+	}
+	store, ok := p.(provider.MaintainableStore)
+	if !ok {
+		return nil, &provider.UnsupportedMaintenance{Provider: runnerConfig.ProviderType, Task: runnerConfig.Task}
+	}
+	return &MaintenanceRunner{
+		Provider: store,
+		Task:     runnerConfig.Task,
+	}, nil
+}
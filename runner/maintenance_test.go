@@ -0,0 +1,69 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/featureform/provider"
+)
+
+type mockMaintainableStore struct {
+	ran []provider.MaintenanceTask
+	err error
+}
+
+func (m *mockMaintainableStore) MaintenanceTasks() []provider.MaintenanceTask {
+	return []provider.MaintenanceTask{provider.VacuumAnalyze}
+}
+
+func (m *mockMaintainableStore) RunMaintenance(ctx context.Context, task provider.MaintenanceTask) error {
+	m.ran = append(m.ran, task)
+	return m.err
+}
+
+func TestMaintenanceRunner(t *testing.T) {
+	store := &mockMaintainableStore{}
+	runner := &MaintenanceRunner{Provider: store, Task: provider.VacuumAnalyze}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run maintenance: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Maintenance failed: %s", err)
+	}
+	if len(store.ran) != 1 || store.ran[0] != provider.VacuumAnalyze {
+		t.Fatalf("Ran %v, expected %s", store.ran, provider.VacuumAnalyze)
+	}
+	store.err = errors.New("vacuum failed")
+	watcher, err = runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run maintenance: %s", err)
+	}
+	if err := watcher.Wait(); err == nil {
+		t.Fatalf("Expected failed maintenance to fail the job")
+	}
+}
+
+func TestMaintenanceRunnerFactoryUnsupported(t *testing.T) {
+	config := &MaintenanceRunnerConfig{
+		ProviderType:   provider.LocalOnline,
+		ProviderConfig: []byte(""),
+		Task:           provider.VacuumAnalyze,
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	var unsupported *provider.UnsupportedMaintenance
+	if _, err := MaintenanceRunnerFactory(serialized); !errors.As(err, &unsupported) {
+		t.Fatalf("Expected unsupported maintenance, got %v", err)
+	}
+	if _, err := MaintenanceRunnerFactory([]byte("{")); err == nil {
+		t.Fatalf("Expected invalid config to fail")
+	}
+}
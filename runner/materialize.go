@@ -5,15 +5,22 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"time"
+
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
 )
 
-const MAXIMUM_CHUNK_ROWS int64 = 1024
 const WORKER_IMAGE string = "featureformcom/worker"
 
+// DefaultLocalParallelism is how many chunks a local materialization copies
+// at a time, unless its runner sets Parallelism.
+const DefaultLocalParallelism = 4
+
 type JobCloud string
 
 const (
@@ -28,6 +35,48 @@ type MaterializeRunner struct {
 	VType    provider.ValueType
 	IsUpdate bool
 	Cloud    JobCloud
+	// Schedule is the cron schedule that update jobs run on. Updates only
+	// materialize rows written after the recorded high-water mark, or after
+	// the previous scheduled run if no mark has been recorded yet.
+	Schedule string
+	// Backfill is set for a backfilled run, which materializes a window of
+	// the feature's history instead of what's changed since the last run.
+	Backfill *BackfillWindow
+	// ChunkSizing decides how many rows each chunk job copies. If it's nil,
+	// chunks are sized by the defaults.
+	ChunkSizing *ChunkSizing
+	// Parallelism is how many chunks a local materialization copies at a
+	// time. It defaults to DefaultLocalParallelism.
+	Parallelism int
+	// BulkLoadURI, if it's set, is the object storage that chunks are
+	// written to for online stores with a native bulk import. Other stores
+	// are copied to row by row.
+	BulkLoadURI string
+	// Image is the image chunk jobs run with on Kubernetes, which the
+	// coordinator resolves like the materialize job's own, and pins to the
+	// digest it verified. It's WORKER_IMAGE for configs written before it
+	// was set.
+	Image string
+	// Pod is what chunk jobs' pods are given and where they're scheduled on
+	// Kubernetes.
+	Pod KubernetesPodConfig
+	// Lineage identifies the coordinator job the run is part of, across its
+	// attempts. Runs of a job with the same lineage share their chunk
+	// checkpoints, so a retried job skips the chunks it already copied.
+	Lineage string
+	// onlineConfig and offlineConfig are the configs the runner was created
+	// from, which may be credential references. They're passed on to chunk
+	// jobs so that resolved credentials never end up in a job config.
+	onlineConfig  provider.SerializedConfig
+	offlineConfig provider.SerializedConfig
+}
+
+// BackfillWindow is the part of a feature's history that a backfilled run
+// materializes: the latest value of each entity as of Until, for entities
+// with a value after Since.
+type BackfillWindow struct {
+	Since time.Time
+	Until time.Time
 }
 
 func (m MaterializeRunner) Resource() metadata.ResourceID {
@@ -62,40 +111,215 @@ func (w WatcherMultiplex) String() string {
 	}
 	return fmt.Sprintf("%v complete out of %v", complete, len(w.CompletionList))
 }
+
+// Wait waits for every chunk, and returns the errors of those that failed.
 func (w WatcherMultiplex) Wait() error {
-	for _, completion := range w.CompletionList {
+	var errs ChunkErrors
+	for i, completion := range w.CompletionList {
 		if err := completion.Wait(); err != nil {
-			return err
+			errs = append(errs, ChunkError{Index: i, Err: err})
 		}
 	}
-	return nil
+	return errs.orNil(len(w.CompletionList))
 }
-func (w WatcherMultiplex) Err() error {
+
+// Progress adds up the progress of the chunks. A chunk that's complete is
+// done, whether or not it reports its progress.
+func (w WatcherMultiplex) Progress() Progress {
+	progress := Progress{TotalChunks: len(w.CompletionList)}
 	for _, completion := range w.CompletionList {
+		chunk := completion.Progress()
+		progress.RowsCopied += chunk.RowsCopied
+		progress.TotalRows += chunk.TotalRows
+		if completion.Complete() {
+			progress.ChunksDone++
+		}
+	}
+	return progress
+}
+
+func (w WatcherMultiplex) Err() error {
+	var errs ChunkErrors
+	for i, completion := range w.CompletionList {
 		if err := completion.Err(); err != nil {
-			return err
+			errs = append(errs, ChunkError{Index: i, Err: err})
 		}
 	}
-	return nil
+	return errs.orNil(len(w.CompletionList))
+}
+
+// ChunkError is the error of one chunk of a materialization.
+type ChunkError struct {
+	Index int
+	Err   error
+}
+
+// ChunkErrors are the errors of every chunk of a materialization that
+// failed, in chunk order.
+type ChunkErrors []ChunkError
+
+func (errs ChunkErrors) Error() string {
+	if len(errs) == 1 {
+		return fmt.Sprintf("chunk %d failed: %v", errs[0].Index, errs[0].Err)
+	}
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = fmt.Sprintf("chunk %d: %v", err.Index, err.Err)
+	}
+	return fmt.Sprintf("%d chunks failed: %s", len(errs), strings.Join(msgs, "; "))
+}
+
+// orNil returns nil if no chunks failed, so that a nil ChunkErrors isn't
+// returned as a non-nil error. The only error of a single chunk run is
+// returned as it is.
+func (errs ChunkErrors) orNil(numChunks int) error {
+	switch {
+	case len(errs) == 0:
+		return nil
+	case numChunks == 1:
+		return errs[0].Err
+	default:
+		return errs
+	}
+}
+
+// runLocalChunks runs the chunk jobs of a local materialization, at most
+// parallelism at a time, and ends each chunk's watcher with its job's result.
+// Every chunk runs even if others fail, since checkpoints let a retry skip
+// the chunks that succeeded. Chunks that haven't started when ctx is
+// cancelled end with its error.
+func runLocalChunks(ctx context.Context, config Config, chunks []*SyncWatcher, parallelism int) {
+	sem := make(chan struct{}, parallelism)
+	for i, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			chunk.EndWatch(err)
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			chunk.EndWatch(ctx.Err())
+			continue
+		}
+		go func(i int, chunk *SyncWatcher) {
+			defer func() { <-sem }()
+			chunk.EndWatch(runLocalChunk(ctx, config, i))
+		}(i, chunk)
+	}
+}
+
+func runLocalChunk(ctx context.Context, config Config, index int) error {
+	localRunner, err := Create(string(COPY_TO_ONLINE), config)
+	if err != nil {
+		return fmt.Errorf("local runner create: %w", err)
+	}
+	if indexRunner, ok := localRunner.(IndexRunner); ok {
+		if err := indexRunner.SetIndex(index); err != nil {
+			return fmt.Errorf("local runner set index: %w", err)
+		}
+	}
+	watcher, err := RunWithContext(ctx, localRunner)
+	if err != nil {
+		return fmt.Errorf("local runner run: %w", err)
+	}
+	return WaitWithContext(ctx, watcher)
+}
+
+// replaceStale creates a materialization that's only used by a single run,
+// replacing one that an earlier attempt at the run left behind.
+func replaceStale(store provider.OfflineStore, create func() (provider.Materialization, error)) (provider.Materialization, error) {
+	mat, err := create()
+	exists, ok := err.(*provider.MaterializationExists)
+	if !ok {
+		return mat, err
+	}
+	if err := store.DeleteMaterialization(exists.ID); err != nil {
+		return nil, fmt.Errorf("delete stale materialization: %w", err)
+	}
+	return create()
 }
 
 func (m MaterializeRunner) Run() (CompletionWatcher, error) {
-	fmt.Println("Starting Runner")
+	return m.RunWithContext(context.Background())
+}
+
+// RunWithContext materializes the feature. Cancelling ctx cancels its
+// warehouse queries and stops local chunk copies; Kubernetes jobs are stopped
+// through their completion watcher.
+func (m MaterializeRunner) RunWithContext(ctx context.Context) (CompletionWatcher, error) {
+	logger := Logger(ctx).With("resource", m.ID)
+	logger.Infow("Starting materialization")
 	var materialization provider.Materialization
-	var err error
+	offline, release, err := provider.OfflineStoreWithContext(ctx, m.Offline)
+	if err != nil {
+		return nil, err
+	}
+	watching := false
+	defer func() {
+		if !watching {
+			release()
+		}
+	}()
+	m.Offline = offline
 
-	if m.IsUpdate {
-		fmt.Println("Updating Materialization")
+	var incremental bool
+	var since time.Time
+	if m.IsUpdate && m.Backfill == nil {
+		// The high-water mark from the last run is preferred, since the
+		// schedule can't tell whether that run succeeded.
+		if since, err = getWatermark(m.Online, m.ID); err != nil {
+			return nil, err
+		}
+		if since.IsZero() {
+			since = previousRun(m.Schedule, time.Now())
+		}
+	}
+	if m.Backfill != nil {
+		windowed, ok := m.Offline.(provider.WindowedMaterializationStore)
+		if !ok {
+			return nil, fmt.Errorf("%s does not support backfills", m.Offline.Type())
+		}
+		// Backfilled values are as of the end of their window, so they'd
+		// replace newer values in a table that can't tell them apart.
+		table, err := m.Online.GetTable(provider.MaterializedName(m.ID), m.ID.Variant)
+		if _, missing := err.(*provider.TableNotFound); missing {
+			table, err = m.Online.CreateTable(provider.MaterializedName(m.ID), m.ID.Variant, m.VType)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get table: %w", err)
+		}
+		if _, ok := table.(provider.TimestampedOnlineStoreTable); !ok {
+			return nil, fmt.Errorf("%s does not support backfills: its tables can't keep newer values from being replaced", m.Online.Type())
+		}
+		logger.Infow("Creating backfill materialization", "since", m.Backfill.Since, "until", m.Backfill.Until)
+		incremental = true
+		materialization, err = replaceStale(m.Offline, func() (provider.Materialization, error) {
+			return windowed.CreateWindowedMaterialization(m.ID, m.Backfill.Since, m.Backfill.Until)
+		})
+	} else if m.IsUpdate && !since.IsZero() {
+		logger.Infow("Creating incremental materialization", "since", since)
+		incremental = true
+		materialization, err = replaceStale(m.Offline, func() (provider.Materialization, error) {
+			return m.Offline.CreateIncrementalMaterialization(m.ID, since)
+		})
+	} else if m.IsUpdate {
+		logger.Infow("Updating materialization")
 		materialization, err = m.Offline.UpdateMaterialization(m.ID)
 	} else {
-		fmt.Println("Creating Materialization")
+		logger.Infow("Creating materialization")
 		materialization, err = m.Offline.CreateMaterialization(m.ID)
+		if _, exists := err.(*provider.MaterializationExists); exists {
+			// An earlier attempt at this job created it, so it's rebuilt
+			// from the resource table rather than trusted to be complete.
+			logger.Infow("Rebuilding materialization left by an earlier attempt")
+			materialization, err = m.Offline.UpdateMaterialization(m.ID)
+		}
 	}
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println("Creating Table")
-	_, err = m.Online.CreateTable(m.ID.Name, m.ID.Variant, m.VType)
+	logger.Debugw("Creating online table")
+	_, err = m.Online.CreateTable(provider.MaterializedName(m.ID), m.ID.Variant, m.VType)
 	_, exists := err.(*provider.TableAlreadyExists)
 	if err != nil && !exists {
 		return nil, fmt.Errorf("create table: %w", err)
@@ -103,45 +327,64 @@ func (m MaterializeRunner) Run() (CompletionWatcher, error) {
 	if exists && !m.IsUpdate {
 		return nil, fmt.Errorf("table already exists despite being new job")
 	}
-	chunkSize := MAXIMUM_CHUNK_ROWS
 	var numChunks int64
-	fmt.Println("Getting Number of Rows")
+	logger.Debugw("Getting number of rows")
 	numRows, err := materialization.NumRows()
 	if err != nil {
 		return nil, fmt.Errorf("num rows: %w", err)
 	}
-	if numRows <= MAXIMUM_CHUNK_ROWS {
+	chunkSize, err := m.chunkSize(materialization, numRows)
+	if err != nil {
+		return nil, fmt.Errorf("chunk size: %w", err)
+	}
+	if numRows <= chunkSize {
 		chunkSize = numRows
 		numChunks = 1
-	} else if chunkSize == 0 {
-		numChunks = 0
-	} else if numRows > chunkSize {
+	} else {
 		numChunks = numRows / chunkSize
 		if chunkSize*numChunks < numRows {
 			numChunks += 1
 		}
 	}
+	logger.Infow("Copying rows", "rows", numRows, "chunks", numChunks, "chunk_size", chunkSize)
+	onlineConfig, offlineConfig := m.onlineConfig, m.offlineConfig
+	if onlineConfig == nil {
+		onlineConfig = m.Online.Config()
+	}
+	if offlineConfig == nil {
+		offlineConfig = m.Offline.Config()
+	}
+	// Chunk jobs that are retried, or run again by a retry of the job, skip
+	// what they already copied, but a new job copies everything again.
 	config := &MaterializedChunkRunnerConfig{
 		OnlineType:     m.Online.Type(),
 		OfflineType:    m.Offline.Type(),
-		OnlineConfig:   m.Online.Config(),
-		OfflineConfig:  m.Offline.Config(),
+		OnlineConfig:   onlineConfig,
+		OfflineConfig:  offlineConfig,
 		MaterializedID: materialization.ID(),
 		ResourceID:     m.ID,
 		ChunkSize:      chunkSize,
+		RunID:          chunkRunID(materialization.ID(), m.Lineage),
+		BulkLoadURI:    m.BulkLoadURI,
 	}
 	serializedConfig, err := config.Serialize()
 	if err != nil {
 		return nil, fmt.Errorf("serialize : %w", err)
 	}
+	started := time.Now()
 	var cloudWatcher CompletionWatcher
 	switch m.Cloud {
 	case KubernetesMaterializeRunner:
 		envVars := map[string]string{"NAME": string(COPY_TO_ONLINE), "CONFIG": string(serializedConfig)}
+		image := m.Image
+		if image == "" {
+			image = WORKER_IMAGE
+		}
 		kubernetesConfig := KubernetesRunnerConfig{
 			EnvVars:  envVars,
-			Image:    WORKER_IMAGE,
+			Image:    image,
 			NumTasks: int32(numChunks),
+			Pod:      m.Pod,
 		}
 		kubernetesRunner, err := NewKubernetesRunner(kubernetesConfig)
 		if err != nil {
@@ -152,20 +395,21 @@ func (m MaterializeRunner) Run() (CompletionWatcher, error) {
 			return nil, fmt.Errorf("kubernetes run: %w", err)
 		}
 	case LocalMaterializeRunner:
-		fmt.Println("Making Local Materialize Runner")
+		parallelism := m.Parallelism
+		if parallelism <= 0 {
+			parallelism = DefaultLocalParallelism
+		}
+		logger.Debugw("Copying chunks locally", "parallelism", parallelism)
+		chunks := make([]*SyncWatcher, int(numChunks))
 		completionList := make([]CompletionWatcher, int(numChunks))
-		for i := 0; i < int(numChunks); i++ {
-			fmt.Println("Getting Number of Rows")
-			localRunner, err := Create(string(COPY_TO_ONLINE), serializedConfig)
-			if err != nil {
-				return nil, fmt.Errorf("local runner create: %w", err)
-			}
-			watcher, err := localRunner.Run()
-			if err != nil {
-				return nil, fmt.Errorf("local runner run: %w", err)
+		for i := range chunks {
+			chunks[i] = &SyncWatcher{
+				ResultSync:  &ResultSync{},
+				DoneChannel: make(chan interface{}),
 			}
-			completionList[i] = watcher
+			completionList[i] = chunks[i]
 		}
+		go runLocalChunks(ctx, serializedConfig, chunks, parallelism)
 		cloudWatcher = WatcherMultiplex{completionList}
 	default:
 		return nil, fmt.Errorf("no valid job cloud set")
@@ -175,14 +419,106 @@ func (m MaterializeRunner) Run() (CompletionWatcher, error) {
 		ResultSync:  &ResultSync{},
 		DoneChannel: done,
 	}
+	watching = true
 	go func() {
-		if err := cloudWatcher.Wait(); err != nil {
+		defer release()
+		if err := WaitWithContext(ctx, cloudWatcher); err != nil {
 			materializeWatcher.EndWatch(fmt.Errorf("cloud watch: %w", err))
 			return
 		}
+		// A backfill rewrites past values, so it doesn't move the
+		// watermark or freshness of scheduled runs.
+		if m.Backfill == nil {
+			if err := m.recordWatermark(materialization); err != nil {
+				materializeWatcher.EndWatch(fmt.Errorf("record watermark: %w", err))
+				return
+			}
+			if err := provider.SetLastWritten(m.Online, provider.MaterializedName(m.ID), m.ID.Variant, time.Now()); err != nil {
+				materializeWatcher.EndWatch(fmt.Errorf("record last written time: %w", err))
+				return
+			}
+		}
+		// Incremental materializations are only needed for a single run.
+		if incremental {
+			if err := m.Offline.DeleteMaterialization(materialization.ID()); err != nil {
+				logger.Warnw("Could not delete incremental materialization", "materialization", materialization.ID(), "error", err)
+			}
+		}
 		materializeWatcher.EndWatch(nil)
 	}()
-	return materializeWatcher, nil
+	return materializationWatcher{
+		SyncWatcher: materializeWatcher,
+		chunks:      cloudWatcher,
+		numRows:     numRows,
+		started:     started,
+	}, nil
+}
+
+// chunkSize returns how many rows each chunk job copies, from the sizing's
+// fixed size, or from the width of the materialization's rows and the
+// throughput the online store was last measured at.
+func (m MaterializeRunner) chunkSize(materialization provider.Materialization, numRows int64) (int64, error) {
+	if m.ChunkSizing != nil && m.ChunkSizing.Rows > 0 {
+		return m.ChunkSizing.Rows, nil
+	}
+	rowBytes, err := estimateRowBytes(materialization, numRows)
+	if err != nil {
+		return 0, fmt.Errorf("estimate row width: %w", err)
+	}
+	throughput, err := getThroughput(m.Online, m.ID)
+	if err != nil {
+		return 0, err
+	}
+	return m.ChunkSizing.chunkRows(rowBytes, throughput), nil
+}
+
+// recordWatermark saves the latest timestamp copied by this run, so that the
+// next update only copies rows written after it. Nothing is recorded if the
+// run copied nothing or the materialization can't report its timestamps.
+func (m MaterializeRunner) recordWatermark(materialization provider.Materialization) error {
+	timestamped, ok := materialization.(provider.TimestampedMaterialization)
+	if !ok {
+		return nil
+	}
+	watermark, err := timestamped.MaxTimestamp()
+	if err != nil {
+		return err
+	}
+	if watermark.IsZero() {
+		return nil
+	}
+	return setWatermark(m.Online, m.ID, watermark)
+}
+
+// previousRun returns the time of the scheduled run before the most recent
+// one at or before now, which is when the run that preceded the current one
+// started. It returns the zero time if schedule is empty or invalid, or if
+// there haven't been two runs yet.
+func previousRun(schedule string, now time.Time) time.Time {
+	_, previous := scheduledRuns(schedule, now)
+	return previous
+}
+
+// scheduledRuns returns the most recent scheduled run at or before now and
+// the one before it, with the zero time for any that can't be found.
+func scheduledRuns(schedule string, now time.Time) (last, previous time.Time) {
+	if schedule == "" {
+		return time.Time{}, time.Time{}
+	}
+	parsed, err := metadata.ParseSchedule(schedule)
+	if err != nil {
+		return time.Time{}, time.Time{}
+	}
+	for window := time.Minute; window <= 5*366*24*time.Hour; window *= 2 {
+		last, previous = time.Time{}, time.Time{}
+		for run := parsed.Next(now.Add(-window)); !run.IsZero() && !run.After(now); run = parsed.Next(run) {
+			previous, last = last, run
+		}
+		if !previous.IsZero() {
+			return last, previous
+		}
+	}
+	return last, previous
 }
 
 type MaterializedRunnerConfig struct {
@@ -194,6 +530,14 @@ type MaterializedRunnerConfig struct {
 	VType         provider.ValueType
 	Cloud         JobCloud
 	IsUpdate      bool
+	Schedule      string
+	Backfill      *BackfillWindow      `json:",omitempty"`
+	ChunkSizing   *ChunkSizing         `json:",omitempty"`
+	Parallelism   int                  `json:",omitempty"`
+	BulkLoadURI   string               `json:",omitempty"`
+	Image         string               `json:",omitempty"`
+	Pod           *KubernetesPodConfig `json:",omitempty"`
+	Lineage       string               `json:",omitempty"`
 }
 
 func (m *MaterializedRunnerConfig) Serialize() (Config, error) {
@@ -217,11 +561,11 @@ func MaterializeRunnerFactory(config Config) (Runner, error) {
 	if err := runnerConfig.Deserialize(config); err != nil {
 		return nil, fmt.Errorf("failed to deserialize materialize runner config: %v", err)
 	}
-	onlineProvider, err := provider.Get(runnerConfig.OnlineType, runnerConfig.OnlineConfig)
+	onlineProvider, err := getProvider(runnerConfig.OnlineType, runnerConfig.OnlineConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure online provider: %v", err)
 	}
-	offlineProvider, err := provider.Get(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
+	offlineProvider, err := getProvider(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure offline provider: %v", err)
 	}
@@ -233,12 +577,27 @@ func MaterializeRunnerFactory(config Config) (Runner, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to convert provider to offline store: %v", err)
 	}
+	var pod KubernetesPodConfig
+	if runnerConfig.Pod != nil {
+		pod = *runnerConfig.Pod
+	}
 	return &MaterializeRunner{
-		Online:   onlineStore,
-		Offline:  offlineStore,
-		ID:       runnerConfig.ResourceID,
-		VType:    runnerConfig.VType,
-		IsUpdate: runnerConfig.IsUpdate,
-		Cloud:    runnerConfig.Cloud,
+		Online:      onlineStore,
+		Offline:     offlineStore,
+		ID:          runnerConfig.ResourceID,
+		VType:       runnerConfig.VType,
+		IsUpdate:    runnerConfig.IsUpdate,
+		Cloud:       runnerConfig.Cloud,
+		Schedule:    runnerConfig.Schedule,
+		Backfill:    runnerConfig.Backfill,
+		ChunkSizing: runnerConfig.ChunkSizing,
+		Parallelism: runnerConfig.Parallelism,
+		BulkLoadURI: runnerConfig.BulkLoadURI,
+		Image:       runnerConfig.Image,
+		Pod:         pod,
+		Lineage:     runnerConfig.Lineage,
+
+		onlineConfig:  runnerConfig.OnlineConfig,
+		offlineConfig: runnerConfig.OfflineConfig,
 	}, nil
 }
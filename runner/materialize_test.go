@@ -5,9 +5,13 @@
 package runner
 
 import (
+	"context"
+	"fmt"
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
+	"sync"
 	"testing"
+	"time"
 )
 
 type mockChunkRunner struct{}
@@ -42,6 +46,10 @@ func (m mockCompletionWatcher) Complete() bool {
 	return true
 }
 
+func (m mockCompletionWatcher) Progress() Progress {
+	return Progress{}
+}
+
 func mockChunkRunnerFactory(config Config) (Runner, error) {
 	return &mockChunkRunner{}, nil
 }
@@ -100,3 +108,283 @@ func TestWatcherMultiplex(t *testing.T) {
 		t.Fatalf("Failed to return multiplexer string")
 	}
 }
+
+func TestPreviousRun(t *testing.T) {
+	now := time.Date(2022, 5, 10, 12, 30, 20, 0, time.UTC)
+	tests := map[string]struct {
+		Schedule string
+		Expected time.Time
+	}{
+		"Unscheduled":   {"", time.Time{}},
+		"Invalid":       {"not a schedule", time.Time{}},
+		"EveryFiveMins": {"*/5 * * * *", time.Date(2022, 5, 10, 12, 25, 0, 0, time.UTC)},
+		"Hourly":        {"0 * * * *", time.Date(2022, 5, 10, 11, 0, 0, 0, time.UTC)},
+		"Daily":         {"0 6 * * *", time.Date(2022, 5, 9, 6, 0, 0, 0, time.UTC)},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if actual := previousRun(test.Schedule, now); !actual.Equal(test.Expected) {
+				t.Fatalf("Expected previous run %v, got %v", test.Expected, actual)
+			}
+		})
+	}
+}
+
+func TestScheduledRuns(t *testing.T) {
+	now := time.Date(2022, 5, 10, 12, 30, 20, 0, time.UTC)
+	last, previous := scheduledRuns("0 * * * *", now)
+	if expected := time.Date(2022, 5, 10, 12, 0, 0, 0, time.UTC); !last.Equal(expected) {
+		t.Fatalf("Expected last run %v, got %v", expected, last)
+	}
+	if expected := time.Date(2022, 5, 10, 11, 0, 0, 0, time.UTC); !previous.Equal(expected) {
+		t.Fatalf("Expected previous run %v, got %v", expected, previous)
+	}
+	if last, previous := scheduledRuns("", now); !last.IsZero() || !previous.IsZero() {
+		t.Fatalf("Expected no runs without a schedule, got %v and %v", last, previous)
+	}
+}
+
+func TestWatermark(t *testing.T) {
+	store := provider.NewLocalOnlineStore()
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	if watermark, err := getWatermark(store, id); err != nil {
+		t.Fatalf("Failed to get missing watermark: %v", err)
+	} else if !watermark.IsZero() {
+		t.Fatalf("Expected no watermark, got %v", watermark)
+	}
+	expected := time.Date(2022, 5, 10, 12, 30, 20, 5, time.UTC)
+	for i := 0; i < 2; i++ {
+		if err := setWatermark(store, id, expected); err != nil {
+			t.Fatalf("Failed to set watermark: %v", err)
+		}
+	}
+	if watermark, err := getWatermark(store, id); err != nil {
+		t.Fatalf("Failed to get watermark: %v", err)
+	} else if !watermark.Equal(expected) {
+		t.Fatalf("Expected watermark %v, got %v", expected, watermark)
+	}
+}
+
+type incrementalOfflineStore struct {
+	MockOfflineStore
+	since time.Time
+}
+
+func (m *incrementalOfflineStore) CreateIncrementalMaterialization(id provider.ResourceID, since time.Time) (provider.Materialization, error) {
+	m.since = since
+	return MockMaterialization{}, nil
+}
+
+func TestMaterializeUpdateUsesWatermark(t *testing.T) {
+	online := provider.NewLocalOnlineStore()
+	offline := &incrementalOfflineStore{}
+	id := provider.ResourceID{Name: "test", Variant: "test", Type: provider.Feature}
+	watermark := time.Date(2022, 5, 10, 0, 0, 0, 0, time.UTC)
+	if err := setWatermark(online, id, watermark); err != nil {
+		t.Fatalf("Failed to set watermark: %v", err)
+	}
+	materializeRunner := MaterializeRunner{
+		Online:   online,
+		Offline:  offline,
+		ID:       id,
+		VType:    provider.String,
+		IsUpdate: true,
+		Cloud:    LocalMaterializeRunner,
+	}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	if err := RegisterFactory(string(COPY_TO_ONLINE), mockChunkRunnerFactory); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	watcher, err := materializeRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to create materialize runner: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Failed to run materialize runner: %v", err)
+	}
+	if !offline.since.Equal(watermark) {
+		t.Fatalf("Update materialized rows since %v, expected %v", offline.since, watermark)
+	}
+}
+
+func TestRecordWatermark(t *testing.T) {
+	offline := provider.NewMemoryOfflineStore()
+	online := provider.NewLocalOnlineStore()
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	schema := provider.TableSchema{
+		Columns: []provider.TableColumn{
+			{Name: "entity", ValueType: provider.String},
+			{Name: "value", ValueType: provider.Int},
+			{Name: "ts", ValueType: provider.Timestamp},
+		},
+	}
+	table, err := offline.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	latest := time.Date(2022, 5, 10, 0, 0, 0, 0, time.UTC)
+	for i, entity := range []string{"a", "b"} {
+		rec := provider.ResourceRecord{Entity: entity, Value: i, TS: latest.Add(-time.Duration(i) * time.Hour)}
+		if err := table.Write(rec); err != nil {
+			t.Fatalf("Failed to write record: %v", err)
+		}
+	}
+	mat, err := offline.CreateMaterialization(id)
+	if err != nil {
+		t.Fatalf("Failed to create materialization: %v", err)
+	}
+	materializeRunner := MaterializeRunner{Online: online, Offline: offline, ID: id}
+	if err := materializeRunner.recordWatermark(mat); err != nil {
+		t.Fatalf("Failed to record watermark: %v", err)
+	}
+	if watermark, err := getWatermark(online, id); err != nil {
+		t.Fatalf("Failed to get watermark: %v", err)
+	} else if !watermark.Equal(latest) {
+		t.Fatalf("Expected watermark %v, got %v", latest, watermark)
+	}
+}
+
+type untimestampedOnlineStore struct {
+	provider.OnlineStore
+}
+
+func (store untimestampedOnlineStore) GetTable(feature, variant string) (provider.OnlineStoreTable, error) {
+	table, err := store.OnlineStore.GetTable(feature, variant)
+	if err != nil {
+		return nil, err
+	}
+	return struct{ provider.OnlineStoreTable }{table}, nil
+}
+
+func TestBackfillNeedsTimestampedTable(t *testing.T) {
+	online := untimestampedOnlineStore{provider.NewLocalOnlineStore()}
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	if _, err := online.CreateTable(provider.MaterializedName(id), id.Variant, provider.Int); err != nil {
+		t.Fatalf("Failed to create online table: %v", err)
+	}
+	materializeRunner := MaterializeRunner{
+		Online:   online,
+		Offline:  provider.NewMemoryOfflineStore(),
+		ID:       id,
+		VType:    provider.Int,
+		IsUpdate: true,
+		Backfill: &BackfillWindow{Since: time.Date(2022, 5, 1, 0, 0, 0, 0, time.UTC), Until: time.Date(2022, 5, 2, 0, 0, 0, 0, time.UTC)},
+		Cloud:    LocalMaterializeRunner,
+	}
+	if _, err := materializeRunner.Run(); err == nil {
+		t.Fatalf("Expected a backfill into a table that can't keep newer values to fail")
+	}
+}
+
+func TestChunkSizing(t *testing.T) {
+	tests := []struct {
+		name          string
+		sizing        *ChunkSizing
+		rowBytes      int64
+		rowsPerSecond float64
+		expected      int64
+	}{
+		{"Fixed", &ChunkSizing{Rows: 500}, 10, 1, 500},
+		{"Unknown", nil, 0, 0, DefaultChunkMaxRows},
+		{"Narrow", nil, 64, 0, DefaultChunkTargetBytes / 64},
+		{"Wide", nil, 1 << 20, 0, DefaultChunkMinRows},
+		{"Throughput", nil, 64, 1000, int64(1000 * DefaultChunkTargetDuration.Seconds())},
+		{"Bounds", &ChunkSizing{TargetBytes: 1000, MinRows: 50, MaxRows: 80}, 1, 0, 80},
+	}
+	for _, test := range tests {
+		if rows := test.sizing.chunkRows(test.rowBytes, test.rowsPerSecond); rows != test.expected {
+			t.Fatalf("%s: expected %d rows per chunk, got %d", test.name, test.expected, rows)
+		}
+	}
+}
+
+func TestChunkThroughput(t *testing.T) {
+	store := provider.NewLocalOnlineStore()
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	if throughput, err := getThroughput(store, id); err != nil || throughput != 0 {
+		t.Fatalf("Expected no throughput, got %v %v", throughput, err)
+	}
+	if err := setThroughput(store, id, 500, 2*time.Second); err != nil {
+		t.Fatalf("Failed to set throughput: %v", err)
+	}
+	if throughput, err := getThroughput(store, id); err != nil || throughput != 250 {
+		t.Fatalf("Expected a throughput of 250 rows per second, got %v %v", throughput, err)
+	}
+	materialized := &MockMaterializedFeatures{Rows: []provider.ResourceRecord{{Entity: "ab", Value: 1234}, {Entity: "cd", Value: 56}}}
+	if width, err := estimateRowBytes(materialized, 2); err != nil || width != 5 {
+		t.Fatalf("Expected rows to be 5 bytes wide, got %v %v", width, err)
+	}
+}
+
+// chunkConcurrency tracks how many chunks run at once.
+type chunkConcurrency struct {
+	mu      sync.Mutex
+	running int
+	max     int
+}
+
+type parallelChunkRunner struct {
+	mockChunkRunner
+	index       int
+	concurrency *chunkConcurrency
+}
+
+func (r *parallelChunkRunner) SetIndex(index int) error {
+	r.index = index
+	return nil
+}
+
+func (r *parallelChunkRunner) Run() (CompletionWatcher, error) {
+	r.concurrency.mu.Lock()
+	r.concurrency.running++
+	if r.concurrency.running > r.concurrency.max {
+		r.concurrency.max = r.concurrency.running
+	}
+	r.concurrency.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	r.concurrency.mu.Lock()
+	r.concurrency.running--
+	r.concurrency.mu.Unlock()
+	watcher := &SyncWatcher{ResultSync: &ResultSync{}, DoneChannel: make(chan interface{})}
+	if r.index%2 == 1 {
+		watcher.EndWatch(fmt.Errorf("chunk %d failed", r.index))
+	} else {
+		watcher.EndWatch(nil)
+	}
+	return watcher, nil
+}
+
+func TestRunLocalChunks(t *testing.T) {
+	concurrency := &chunkConcurrency{}
+	delete(factoryMap, string(COPY_TO_ONLINE))
+	if err := RegisterFactory(string(COPY_TO_ONLINE), func(Config) (Runner, error) {
+		return &parallelChunkRunner{concurrency: concurrency}, nil
+	}); err != nil {
+		t.Fatalf("Failed to register factory: %v", err)
+	}
+	defer delete(factoryMap, string(COPY_TO_ONLINE))
+	chunks := make([]*SyncWatcher, 5)
+	watchers := make([]CompletionWatcher, len(chunks))
+	for i := range chunks {
+		chunks[i] = &SyncWatcher{ResultSync: &ResultSync{}, DoneChannel: make(chan interface{})}
+		watchers[i] = chunks[i]
+	}
+	runLocalChunks(context.Background(), nil, chunks, 2)
+	err := WatcherMultiplex{watchers}.Wait()
+	errs, ok := err.(ChunkErrors)
+	if !ok || len(errs) != 2 || errs[0].Index != 1 || errs[1].Index != 3 {
+		t.Fatalf("Expected chunks 1 and 3 to fail, got %v", err)
+	}
+	if concurrency.max > 2 {
+		t.Fatalf("Expected at most 2 chunks to run at a time, got %d", concurrency.max)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	chunks = []*SyncWatcher{{ResultSync: &ResultSync{}, DoneChannel: make(chan interface{})}}
+	runLocalChunks(ctx, nil, chunks, 2)
+	if err := chunks[0].Wait(); err != context.Canceled {
+		t.Fatalf("Expected a cancelled run to skip its chunks, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+type MigrationFeature struct {
+	ID    provider.ResourceID
+	VType provider.ValueType
+}
+
+// OnlineMigrationRunner copies the values of features from one online store
+// to another and then reads every value back from the destination to verify
+// the copy. It doesn't change which store features are served from.
+type OnlineMigrationRunner struct {
+	Source      provider.OnlineStore
+	Destination provider.OnlineStore
+	Features    []MigrationFeature
+}
+
+func (m *OnlineMigrationRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (m *OnlineMigrationRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (m *OnlineMigrationRunner) Run() (CompletionWatcher, error) {
+	done := make(chan interface{})
+	jobWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		for _, feature := range m.Features {
+			if err := m.migrate(feature); err != nil {
+				jobWatcher.EndWatch(fmt.Errorf("migrate %s (%s): %w", feature.ID.Name, feature.ID.Variant, err))
+				return
+			}
+		}
+		jobWatcher.EndWatch(nil)
+	}()
+	return jobWatcher, nil
+}
+
+func (m *OnlineMigrationRunner) migrate(feature MigrationFeature) error {
+	name, variant := feature.ID.Name, feature.ID.Variant
+	table, err := m.Source.GetTable(name, variant)
+	if err != nil {
+		return fmt.Errorf("get source table: %w", err)
+	}
+	source, ok := table.(provider.IterableOnlineStoreTable)
+	if !ok {
+		return fmt.Errorf("source table %T cannot list its entities", table)
+	}
+	dest, err := m.Destination.CreateTable(name, variant, feature.VType)
+	if _, exists := err.(*provider.TableAlreadyExists); exists {
+		dest, err = m.Destination.GetTable(name, variant)
+	}
+	if err != nil {
+		return fmt.Errorf("create destination table: %w", err)
+	}
+	entities, err := source.Entities()
+	if err != nil {
+		return fmt.Errorf("list entities: %w", err)
+	}
+	values := make(map[string]interface{}, len(entities))
+	for _, entity := range entities {
+		value, err := source.Get(entity)
+		if err != nil {
+			return fmt.Errorf("get %s: %w", entity, err)
+		}
+		if err := dest.Set(entity, value); err != nil {
+			return fmt.Errorf("set %s: %w", entity, err)
+		}
+		values[entity] = value
+	}
+	for entity, expected := range values {
+		actual, err := dest.Get(entity)
+		if err != nil {
+			return fmt.Errorf("verify %s: %w", entity, err)
+		}
+		// Stores return numbers with different widths, so values are
+		// compared by their string form.
+		if fmt.Sprint(actual) != fmt.Sprint(expected) {
+			return fmt.Errorf("verify %s: destination has %v, expected %v", entity, actual, expected)
+		}
+	}
+	return nil
+}
+
+type OnlineMigrationRunnerConfig struct {
+	SourceType        provider.Type
+	SourceConfig      provider.SerializedConfig
+	DestinationType   provider.Type
+	DestinationConfig provider.SerializedConfig
+	Features          []MigrationFeature
+}
+
+func (m *OnlineMigrationRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (m *OnlineMigrationRunnerConfig) Deserialize(config Config) error {
+	return json.Unmarshal(config, m)
+}
+
+func OnlineMigrationRunnerFactory(config Config) (Runner, error) {
+	runnerConfig := &OnlineMigrationRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize online migration runner config: %v", err)
+	}
+	source, err := onlineStore(runnerConfig.SourceType, runnerConfig.SourceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure source online store: %v", err)
+	}
+	dest, err := onlineStore(runnerConfig.DestinationType, runnerConfig.DestinationConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure destination online store: %v", err)
+	}
+	return &OnlineMigrationRunner{
+		Source:      source,
+		Destination: dest,
+		Features:    runnerConfig.Features,
+	}, nil
+}
+
+func onlineStore(t provider.Type, config provider.SerializedConfig) (provider.OnlineStore, error) {
+	p, err := getProvider(t, config)
+	if err != nil {
+		return nil, err
+	}
+	return p.AsOnlineStore()
+}
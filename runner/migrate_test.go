@@ -0,0 +1,89 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"testing"
+
+	"github.com/featureform/provider"
+)
+
+func TestOnlineMigrationRunner(t *testing.T) {
+	source := provider.NewLocalOnlineStore()
+	dest := provider.NewLocalOnlineStore()
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	table, err := source.CreateTable(id.Name, id.Variant, provider.Int)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	values := map[string]interface{}{"a": 1, "b": 2, "c": 3}
+	for entity, value := range values {
+		if err := table.Set(entity, value); err != nil {
+			t.Fatalf("Failed to set %s: %s", entity, err)
+		}
+	}
+	migration := &OnlineMigrationRunner{
+		Source:      source,
+		Destination: dest,
+		Features:    []MigrationFeature{{ID: id, VType: provider.Int}},
+	}
+	watcher, err := migration.Run()
+	if err != nil {
+		t.Fatalf("Failed to run migration: %s", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Migration failed: %s", err)
+	}
+	migrated, err := dest.GetTable(id.Name, id.Variant)
+	if err != nil {
+		t.Fatalf("Failed to get migrated table: %s", err)
+	}
+	for entity, value := range values {
+		if actual, err := migrated.Get(entity); err != nil {
+			t.Fatalf("Failed to get %s: %s", entity, err)
+		} else if actual != value {
+			t.Fatalf("Wrong value for %s: %v, expected %v", entity, actual, value)
+		}
+	}
+}
+
+func TestOnlineMigrationRunnerMissingTable(t *testing.T) {
+	migration := &OnlineMigrationRunner{
+		Source:      provider.NewLocalOnlineStore(),
+		Destination: provider.NewLocalOnlineStore(),
+		Features:    []MigrationFeature{{ID: provider.ResourceID{Name: "missing", Variant: "variant"}, VType: provider.Int}},
+	}
+	watcher, err := migration.Run()
+	if err != nil {
+		t.Fatalf("Failed to run migration: %s", err)
+	}
+	if err := watcher.Wait(); err == nil {
+		t.Fatalf("Succeeded in migrating missing table")
+	}
+}
+
+func TestOnlineMigrationRunnerConfig(t *testing.T) {
+	config := &OnlineMigrationRunnerConfig{
+		SourceType:        provider.LocalOnline,
+		SourceConfig:      []byte{},
+		DestinationType:   provider.LocalOnline,
+		DestinationConfig: []byte{},
+		Features:          []MigrationFeature{{ID: provider.ResourceID{Name: "feature", Variant: "variant"}, VType: provider.Int}},
+	}
+	serialized, err := config.Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %s", err)
+	}
+	migration, err := OnlineMigrationRunnerFactory(serialized)
+	if err != nil {
+		t.Fatalf("Failed to create migration runner: %s", err)
+	}
+	if features := migration.(*OnlineMigrationRunner).Features; len(features) != 1 || features[0].VType != provider.Int {
+		t.Fatalf("Wrong features in migration runner: %v", features)
+	}
+	if _, err := OnlineMigrationRunnerFactory([]byte("invalid")); err == nil {
+		t.Fatalf("Succeeded in creating runner from invalid config")
+	}
+}
@@ -0,0 +1,424 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// NomadPollInterval is how often a dispatched job's status is checked while
+// waiting for it.
+var NomadPollInterval = 10 * time.Second
+
+var nomadHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// NomadResources are the resources each task of a job reserves, in MHz of
+// CPU and MB of memory. Nomad's defaults are used for those that aren't set.
+type NomadResources struct {
+	CPU      int
+	MemoryMB int
+}
+
+type NomadRunnerConfig struct {
+	EnvVars   map[string]string
+	Resource  metadata.ResourceID
+	Image     string
+	NumTasks  int32
+	Resources NomadResources
+	// Retries is how many times Nomad reschedules a failed task.
+	Retries int32
+}
+
+// NomadClient manages jobs through the HTTP API of a Nomad cluster.
+type NomadClient struct {
+	// Address is the URL of a Nomad server or agent, like
+	// http://127.0.0.1:4646.
+	Address     string
+	Token       string
+	Namespace   string
+	Region      string
+	Datacenters []string
+}
+
+// NomadRunner runs a job as a parameterized Nomad batch job. Each
+// Featureform job has a parameterized job of its own, which is registered
+// with the current config before every run and then dispatched, so Nomad
+// keeps the job's runs as its children. Scheduled runs are left to the
+// coordinator's scheduler, since parameterized jobs can't be periodic.
+type NomadRunner struct {
+	client   NomadClient
+	resource metadata.ResourceID
+	job      nomadJob
+}
+
+func NewNomadRunner(client NomadClient, config NomadRunnerConfig) NomadRunner {
+	return NomadRunner{
+		client:   client,
+		resource: config.Resource,
+		job:      newNomadJob(client, nomadJobID(GetJobName(config.Resource)), config),
+	}
+}
+
+var nomadInvalidIDChars = regexp.MustCompile("[^A-Za-z0-9_.-]+")
+
+// nomadJobID makes a job name usable as a Nomad job ID, which can't have
+// spaces or slashes, since dispatched jobs are named after their parent
+// with a slash.
+func nomadJobID(jobName string) string {
+	return "featureform-" + nomadInvalidIDChars.ReplaceAllString(jobName, "-")
+}
+
+type nomadJob struct {
+	ID               string            `json:"ID"`
+	Name             string            `json:"Name"`
+	Type             string            `json:"Type"`
+	Namespace        string            `json:"Namespace,omitempty"`
+	Region           string            `json:"Region,omitempty"`
+	Datacenters      []string          `json:"Datacenters"`
+	Meta             map[string]string `json:"Meta,omitempty"`
+	ParameterizedJob *struct{}         `json:"ParameterizedJob"`
+	TaskGroups       []nomadTaskGroup  `json:"TaskGroups"`
+}
+
+type nomadTaskGroup struct {
+	Name             string                `json:"Name"`
+	Count            int32                 `json:"Count"`
+	RestartPolicy    nomadRestartPolicy    `json:"RestartPolicy"`
+	ReschedulePolicy nomadReschedulePolicy `json:"ReschedulePolicy"`
+	Tasks            []nomadTask           `json:"Tasks"`
+}
+
+// nomadRestartPolicy and nomadReschedulePolicy take durations in
+// nanoseconds, like the rest of Nomad's API.
+type nomadRestartPolicy struct {
+	Attempts int32  `json:"Attempts"`
+	Interval int64  `json:"Interval"`
+	Mode     string `json:"Mode"`
+}
+
+type nomadReschedulePolicy struct {
+	Attempts      int32  `json:"Attempts"`
+	Interval      int64  `json:"Interval"`
+	Delay         int64  `json:"Delay"`
+	DelayFunction string `json:"DelayFunction"`
+	Unlimited     bool   `json:"Unlimited"`
+}
+
+type nomadTask struct {
+	Name      string                 `json:"Name"`
+	Driver    string                 `json:"Driver"`
+	Config    map[string]interface{} `json:"Config"`
+	Env       map[string]string      `json:"Env,omitempty"`
+	Resources *nomadTaskResources    `json:"Resources,omitempty"`
+}
+
+type nomadTaskResources struct {
+	CPU      int `json:"CPU,omitempty"`
+	MemoryMB int `json:"MemoryMB,omitempty"`
+}
+
+// nomadTaskGroupName is the name of the task group, and its one task, in
+// every job.
+const nomadTaskGroupName = "worker"
+
+func newNomadJob(client NomadClient, jobID string, config NomadRunnerConfig) nomadJob {
+	numTasks := config.NumTasks
+	if numTasks < 1 {
+		numTasks = 1
+	}
+	datacenters := client.Datacenters
+	if len(datacenters) == 0 {
+		datacenters = []string{"dc1"}
+	}
+	task := nomadTask{
+		Name:   nomadTaskGroupName,
+		Driver: "docker",
+		Config: map[string]interface{}{"image": config.Image},
+		// Nomad gives each task its index in NOMAD_ALLOC_INDEX, which the
+		// worker reads for jobs of more than one task.
+		Env: config.EnvVars,
+	}
+	if config.Resources != (NomadResources{}) {
+		task.Resources = &nomadTaskResources{CPU: config.Resources.CPU, MemoryMB: config.Resources.MemoryMB}
+	}
+	// Failed tasks are rescheduled, possibly onto another node, rather than
+	// restarted in place.
+	group := nomadTaskGroup{
+		Name:          nomadTaskGroupName,
+		Count:         numTasks,
+		RestartPolicy: nomadRestartPolicy{Attempts: 0, Interval: int64(time.Hour), Mode: "fail"},
+		ReschedulePolicy: nomadReschedulePolicy{
+			Attempts:      config.Retries,
+			Interval:      int64(24 * time.Hour),
+			Delay:         int64(5 * time.Second),
+			DelayFunction: "constant",
+		},
+		Tasks: []nomadTask{task},
+	}
+	return nomadJob{
+		ID:               jobID,
+		Name:             jobID,
+		Type:             "batch",
+		Namespace:        client.Namespace,
+		Region:           client.Region,
+		Datacenters:      datacenters,
+		Meta:             map[string]string{jobLabel: jobID},
+		ParameterizedJob: &struct{}{},
+		TaskGroups:       []nomadTaskGroup{group},
+	}
+}
+
+func (n NomadRunner) Resource() metadata.ResourceID {
+	return n.resource
+}
+
+func (n NomadRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (n NomadRunner) Run() (CompletionWatcher, error) {
+	ctx := context.Background()
+	if err := n.client.registerJob(ctx, n.job); err != nil {
+		return nil, fmt.Errorf("register nomad job: %w", err)
+	}
+	dispatched, err := n.client.dispatchJob(ctx, n.job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("dispatch nomad job: %w", err)
+	}
+	return NomadCompletionWatcher{client: n.client, jobID: dispatched}, nil
+}
+
+// NomadCompletionWatcher watches a dispatched Nomad job.
+type NomadCompletionWatcher struct {
+	client NomadClient
+	jobID  string
+}
+
+func (n NomadCompletionWatcher) Complete() bool {
+	status, err := n.client.jobStatus(context.Background(), n.jobID)
+	if err != nil {
+		return false
+	}
+	return status.finished
+}
+
+func (n NomadCompletionWatcher) String() string {
+	status, err := n.client.jobStatus(context.Background(), n.jobID)
+	if err != nil {
+		return "Could not fetch job."
+	}
+	return fmt.Sprintf("Job %s: %d of %d allocations complete, %d failed", n.jobID, status.complete, len(status.allocations), status.failed)
+}
+
+// Progress is the number of the job's allocations that are complete.
+func (n NomadCompletionWatcher) Progress() Progress {
+	status, err := n.client.jobStatus(context.Background(), n.jobID)
+	if err != nil {
+		return Progress{}
+	}
+	return taskProgress(status.complete, len(status.allocations), time.Time{})
+}
+
+func (n NomadCompletionWatcher) Wait() error {
+	for {
+		status, err := n.client.jobStatus(context.Background(), n.jobID)
+		if err != nil {
+			return err
+		}
+		if status.finished {
+			return status.err()
+		}
+		time.Sleep(NomadPollInterval)
+	}
+}
+
+func (n NomadCompletionWatcher) Err() error {
+	status, err := n.client.jobStatus(context.Background(), n.jobID)
+	if err != nil {
+		return err
+	}
+	return status.err()
+}
+
+// Cancel stops the dispatched job, which stops its running allocations.
+func (n NomadCompletionWatcher) Cancel() error {
+	return n.client.request(context.Background(), http.MethodDelete, n.client.url("job/"+url.PathEscape(n.jobID)), nil, nil)
+}
+
+type nomadAllocation struct {
+	ID             string                    `json:"ID"`
+	ClientStatus   string                    `json:"ClientStatus"`
+	NextAllocation string                    `json:"NextAllocation"`
+	TaskStates     map[string]nomadTaskState `json:"TaskStates"`
+}
+
+type nomadTaskState struct {
+	Failed bool             `json:"Failed"`
+	Events []nomadTaskEvent `json:"Events"`
+}
+
+type nomadTaskEvent struct {
+	Type           string `json:"Type"`
+	DisplayMessage string `json:"DisplayMessage"`
+}
+
+// nomadJobStatus is the state of a dispatched job and its allocations.
+type nomadJobStatus struct {
+	jobID       string
+	finished    bool
+	allocations []nomadAllocation
+	complete    int
+	failed      int
+	// reason describes why the last allocation that failed for good did.
+	reason string
+}
+
+func (s *nomadJobStatus) err() error {
+	if s.failed == 0 {
+		return nil
+	}
+	message := fmt.Sprintf("%d of %d allocations failed", s.failed, len(s.allocations))
+	if s.reason != "" {
+		message = s.reason
+	}
+	return fmt.Errorf("nomad job %s failed: %s", s.jobID, message)
+}
+
+// nomadError is an error response from the Nomad API.
+type nomadError struct {
+	StatusCode int
+	Message    string
+}
+
+func (err *nomadError) Error() string {
+	return fmt.Sprintf("nomad request failed: %d: %s", err.StatusCode, err.Message)
+}
+
+func (c NomadClient) url(path string) string {
+	query := url.Values{}
+	if c.Namespace != "" {
+		query.Set("namespace", c.Namespace)
+	}
+	if c.Region != "" {
+		query.Set("region", c.Region)
+	}
+	u := fmt.Sprintf("%s/v1/%s", strings.TrimRight(c.Address, "/"), path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+func (c NomadClient) request(ctx context.Context, method, url string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Nomad-Token", c.Token)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := nomadHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &nomadError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(respBody))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("invalid nomad response: %w", err)
+	}
+	return nil
+}
+
+// registerJob creates the job, or updates it if it already exists.
+func (c NomadClient) registerJob(ctx context.Context, job nomadJob) error {
+	return c.request(ctx, http.MethodPost, c.url("jobs"), map[string]interface{}{"Job": job}, nil)
+}
+
+// dispatchJob starts a run of the parameterized job and returns the ID of
+// the job it dispatches.
+func (c NomadClient) dispatchJob(ctx context.Context, jobID string) (string, error) {
+	var resp struct {
+		DispatchedJobID string `json:"DispatchedJobID"`
+	}
+	if err := c.request(ctx, http.MethodPost, c.url("job/"+url.PathEscape(jobID)+"/dispatch"), struct{}{}, &resp); err != nil {
+		return "", err
+	}
+	if resp.DispatchedJobID == "" {
+		return "", fmt.Errorf("nomad didn't dispatch job %s", jobID)
+	}
+	return resp.DispatchedJobID, nil
+}
+
+// jobStatus reads a dispatched job and its allocations. Allocations that
+// failed and were rescheduled don't count as failures, so a job fails only
+// if it's out of retries.
+func (c NomadClient) jobStatus(ctx context.Context, jobID string) (*nomadJobStatus, error) {
+	var job struct {
+		Status string `json:"Status"`
+	}
+	escaped := url.PathEscape(jobID)
+	if err := c.request(ctx, http.MethodGet, c.url("job/"+escaped), nil, &job); err != nil {
+		return nil, err
+	}
+	status := &nomadJobStatus{jobID: jobID, finished: job.Status == "dead"}
+	if err := c.request(ctx, http.MethodGet, c.url("job/"+escaped+"/allocations"), nil, &status.allocations); err != nil {
+		return nil, err
+	}
+	for _, alloc := range status.allocations {
+		switch alloc.ClientStatus {
+		case "complete":
+			status.complete++
+		case "failed", "lost":
+			if alloc.NextAllocation != "" {
+				continue
+			}
+			status.failed++
+			if reason := alloc.failureReason(); reason != "" {
+				status.reason = reason
+			}
+		}
+	}
+	return status, nil
+}
+
+// failureReason is the message of the last event of a task that failed.
+func (a nomadAllocation) failureReason() string {
+	for _, state := range a.TaskStates {
+		if state.Failed && len(state.Events) > 0 {
+			event := state.Events[len(state.Events)-1]
+			return fmt.Sprintf("allocation %s: %s", a.ID, event.DisplayMessage)
+		}
+	}
+	return ""
+}
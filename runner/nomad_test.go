@@ -0,0 +1,164 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// nomadTestServer is a Nomad API with one parameterized job, whose
+// dispatched jobs finish after being read a couple of times.
+type nomadTestServer struct {
+	mtx         sync.Mutex
+	registered  []nomadJob
+	reads       int
+	failed      bool
+	rescheduled bool
+	stopped     []string
+}
+
+func (s *nomadTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if r.Header.Get("X-Nomad-Token") != "token" || r.URL.Query().Get("namespace") != "featureform" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	job := "/v1/job/" + nomadJobID("avg.txn-v1-4")
+	dispatched := job + "/dispatch-1"
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/v1/jobs":
+		var body struct{ Job nomadJob }
+		json.NewDecoder(r.Body).Decode(&body)
+		s.registered = append(s.registered, body.Job)
+		fmt.Fprint(w, `{"EvalID": ""}`)
+	case r.Method == http.MethodPost && r.URL.Path == job+"/dispatch":
+		s.reads = 0
+		fmt.Fprintf(w, `{"DispatchedJobID": %q}`, strings.TrimPrefix(dispatched, "/v1/job/"))
+	case r.Method == http.MethodGet && r.URL.Path == dispatched:
+		s.reads++
+		if s.reads < 2 {
+			fmt.Fprint(w, `{"Status": "running"}`)
+		} else {
+			fmt.Fprint(w, `{"Status": "dead"}`)
+		}
+	case r.Method == http.MethodGet && r.URL.Path == dispatched+"/allocations":
+		allocs := []string{}
+		if s.rescheduled {
+			allocs = append(allocs, `{"ID": "alloc-0", "ClientStatus": "failed", "NextAllocation": "alloc-1"}`)
+		}
+		switch {
+		case s.reads < 2:
+			allocs = append(allocs, `{"ID": "alloc-1", "ClientStatus": "running"}`)
+		case s.failed:
+			allocs = append(allocs, `{"ID": "alloc-1", "ClientStatus": "failed",
+				"TaskStates": {"worker": {"Failed": true, "Events": [{"Type": "Terminated", "DisplayMessage": "Exit Code: 1"}]}}}`)
+		default:
+			allocs = append(allocs, `{"ID": "alloc-1", "ClientStatus": "complete"}`)
+		}
+		fmt.Fprintf(w, "[%s]", strings.Join(allocs, ","))
+	case r.Method == http.MethodDelete && r.URL.Path == dispatched:
+		s.stopped = append(s.stopped, dispatched)
+		fmt.Fprint(w, `{"EvalID": ""}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func setupNomad(t *testing.T) (*nomadTestServer, NomadClient) {
+	api := &nomadTestServer{}
+	server := httptest.NewServer(api)
+	interval := NomadPollInterval
+	NomadPollInterval = time.Millisecond
+	t.Cleanup(func() {
+		server.Close()
+		NomadPollInterval = interval
+	})
+	return api, NomadClient{Address: server.URL, Token: "token", Namespace: "featureform"}
+}
+
+func TestNomadRunner(t *testing.T) {
+	api, client := setupNomad(t)
+	id := metadata.ResourceID{Name: "avg_txn", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	runner := NewNomadRunner(client, NomadRunnerConfig{
+		EnvVars:   map[string]string{"NAME": string(MATERIALIZE)},
+		Resource:  id,
+		Image:     "worker",
+		NumTasks:  1,
+		Resources: NomadResources{CPU: 2000, MemoryMB: 4096},
+		Retries:   2,
+	})
+	if runner.Resource() != id {
+		t.Fatalf("Runner has resource %v, expected %v", runner.Resource(), id)
+	}
+	api.rescheduled = true
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run job: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Job with a rescheduled allocation failed: %v", err)
+	}
+	if !watcher.Complete() {
+		t.Fatalf("Succeeded job isn't complete")
+	}
+	job := api.registered[0]
+	if job.Type != "batch" || job.ParameterizedJob == nil {
+		t.Fatalf("Job isn't a parameterized batch job: %+v", job)
+	}
+	group := job.TaskGroups[0]
+	if group.ReschedulePolicy.Attempts != 2 {
+		t.Fatalf("Job isn't rescheduled twice: %+v", group.ReschedulePolicy)
+	}
+	task := group.Tasks[0]
+	if task.Config["image"] != "worker" || task.Env["NAME"] != string(MATERIALIZE) || task.Resources.CPU != 2000 || task.Resources.MemoryMB != 4096 {
+		t.Fatalf("Unexpected task: %+v", task)
+	}
+	api.failed, api.rescheduled = true, false
+	watcher, err = runner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run job again: %v", err)
+	}
+	if len(api.registered) != 2 {
+		t.Fatalf("Job wasn't registered again before running")
+	}
+	if err := watcher.Wait(); err == nil || !strings.Contains(err.Error(), "Exit Code: 1") {
+		t.Fatalf("Expected job to fail with its task's event, got %v", err)
+	}
+}
+
+func TestNomadRunnerCancel(t *testing.T) {
+	api, client := setupNomad(t)
+	id := metadata.ResourceID{Name: "avg_txn", Variant: "v1", Type: metadata.FEATURE_VARIANT}
+	watcher, err := NewNomadRunner(client, NomadRunnerConfig{Resource: id, Image: "worker"}).Run()
+	if err != nil {
+		t.Fatalf("Failed to run job: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := WaitWithContext(ctx, watcher); !errors.Is(err, ErrJobCancelled) {
+		t.Fatalf("Expected cancelled wait, got %v", err)
+	}
+	if len(api.stopped) != 1 {
+		t.Fatalf("Cancelled job wasn't stopped in Nomad")
+	}
+}
+
+func TestNomadJobID(t *testing.T) {
+	if got := nomadJobID("avg txn/v1-4"); got != "featureform-avg-txn-v1-4" {
+		t.Fatalf("Job ID is %s", got)
+	}
+}
@@ -0,0 +1,171 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// OfflineCopyRunner copies a primary table or a transformation's table from
+// one offline store to another, such as from a warehouse to a database next
+// to training infrastructure. The copy is a primary table in the destination,
+// which replaces any table an earlier copy left there, and its row count is
+// checked against the source once it's written.
+type OfflineCopyRunner struct {
+	Source      provider.OfflineStore
+	Destination provider.OfflineStore
+	ID          provider.ResourceID
+	// DestinationID names the copy. It defaults to a primary table with the
+	// same name and variant as ID.
+	DestinationID provider.ResourceID
+}
+
+func (c *OfflineCopyRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (c *OfflineCopyRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (c *OfflineCopyRunner) Run() (CompletionWatcher, error) {
+	done := make(chan interface{})
+	jobWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		if err := c.copy(); err != nil {
+			jobWatcher.EndWatch(fmt.Errorf("copy %s (%s): %w", c.ID.Name, c.ID.Variant, err))
+			return
+		}
+		jobWatcher.EndWatch(nil)
+	}()
+	return jobWatcher, nil
+}
+
+func (c *OfflineCopyRunner) sourceTable() (provider.PrimaryTable, error) {
+	switch c.ID.Type {
+	case provider.Primary:
+		return c.Source.GetPrimaryTable(c.ID)
+	case provider.Transformation:
+		return c.Source.GetTransformationTable(c.ID)
+	default:
+		return nil, fmt.Errorf("only primary tables and transformations can be copied, not %v", c.ID.Type)
+	}
+}
+
+func (c *OfflineCopyRunner) destinationID() provider.ResourceID {
+	if c.DestinationID.Name != "" {
+		return c.DestinationID
+	}
+	return provider.ResourceID{Name: c.ID.Name, Variant: c.ID.Variant, Type: provider.Primary}
+}
+
+func (c *OfflineCopyRunner) copy() error {
+	source, err := c.sourceTable()
+	if err != nil {
+		return fmt.Errorf("get source table: %w", err)
+	}
+	schemaTable, ok := source.(provider.SchemaTable)
+	if !ok {
+		return fmt.Errorf("source table %T cannot report its schema", source)
+	}
+	schema, err := schemaTable.Schema()
+	if err != nil {
+		return fmt.Errorf("get source schema: %w", err)
+	}
+	numRows, err := source.NumRows()
+	if err != nil {
+		return fmt.Errorf("count source rows: %w", err)
+	}
+	destID := c.destinationID()
+	err = c.Destination.DeleteTable(destID)
+	var notFound *provider.TableNotFound
+	if err != nil && !errors.As(err, &notFound) {
+		return fmt.Errorf("delete earlier copy: %w", err)
+	}
+	dest, err := c.Destination.CreatePrimaryTable(destID, schema)
+	if err != nil {
+		return fmt.Errorf("create destination table: %w", err)
+	}
+	it, err := source.IterateSegment(numRows)
+	if err != nil {
+		return fmt.Errorf("iterate source table: %w", err)
+	}
+	var written int64
+	for it.Next() {
+		if err := dest.Write(it.Values()); err != nil {
+			return fmt.Errorf("write row %d: %w", written, err)
+		}
+		written++
+	}
+	if err := it.Err(); err != nil {
+		return fmt.Errorf("read source table: %w", err)
+	}
+	copied, err := dest.NumRows()
+	if err != nil {
+		return fmt.Errorf("count destination rows: %w", err)
+	}
+	if copied != written {
+		return fmt.Errorf("wrote %d rows but destination has %d", written, copied)
+	}
+	return nil
+}
+
+type OfflineCopyRunnerConfig struct {
+	SourceType        provider.Type
+	SourceConfig      provider.SerializedConfig
+	DestinationType   provider.Type
+	DestinationConfig provider.SerializedConfig
+	ResourceID        provider.ResourceID
+	DestinationID     provider.ResourceID
+}
+
+func (c *OfflineCopyRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *OfflineCopyRunnerConfig) Deserialize(config Config) error {
+	return json.Unmarshal(config, c)
+}
+
+func OfflineCopyRunnerFactory(config Config) (Runner, error) {
+	runnerConfig := &OfflineCopyRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize offline copy runner config: %v", err)
+	}
+	source, err := getOfflineStore(runnerConfig.SourceType, runnerConfig.SourceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure source provider: %v", err)
+	}
+	destination, err := getOfflineStore(runnerConfig.DestinationType, runnerConfig.DestinationConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure destination provider: %v", err)
+	}
+	return &OfflineCopyRunner{
+		Source:        source,
+		Destination:   destination,
+		ID:            runnerConfig.ResourceID,
+		DestinationID: runnerConfig.DestinationID,
+	}, nil
+}
+
+func getOfflineStore(t provider.Type, config provider.SerializedConfig) (provider.OfflineStore, error) {
+	p, err := getProvider(t, config)
+	if err != nil {
+		return nil, err
+	}
+	return p.AsOfflineStore()
+}
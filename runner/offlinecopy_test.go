@@ -0,0 +1,142 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"testing"
+
+	"github.com/featureform/provider"
+)
+
+type copyTestTable struct {
+	schema provider.TableSchema
+	rows   []provider.GenericRecord
+}
+
+func (t *copyTestTable) Write(rec provider.GenericRecord) error {
+	t.rows = append(t.rows, rec)
+	return nil
+}
+
+func (t *copyTestTable) GetName() string {
+	return "copy_test"
+}
+
+func (t *copyTestTable) IterateSegment(n int64) (provider.GenericTableIterator, error) {
+	columns := make([]string, len(t.schema.Columns))
+	for i, col := range t.schema.Columns {
+		columns[i] = col.Name
+	}
+	return &copyTestIterator{rows: t.rows[:n], columns: columns, i: -1}, nil
+}
+
+func (t *copyTestTable) NumRows() (int64, error) {
+	return int64(len(t.rows)), nil
+}
+
+func (t *copyTestTable) Stats() (provider.TableStats, error) {
+	return provider.TableStats{NumRows: int64(len(t.rows))}, nil
+}
+
+func (t *copyTestTable) Schema() (provider.TableSchema, error) {
+	return t.schema, nil
+}
+
+type copyTestIterator struct {
+	rows    []provider.GenericRecord
+	columns []string
+	i       int
+}
+
+func (it *copyTestIterator) Next() bool {
+	it.i++
+	return it.i < len(it.rows)
+}
+
+func (it *copyTestIterator) Values() provider.GenericRecord {
+	return it.rows[it.i]
+}
+
+func (it *copyTestIterator) Columns() []string {
+	return it.columns
+}
+
+func (it *copyTestIterator) Err() error {
+	return nil
+}
+
+type copyTestStore struct {
+	MockOfflineStore
+	tables map[provider.ResourceID]*copyTestTable
+}
+
+func (s *copyTestStore) GetPrimaryTable(id provider.ResourceID) (provider.PrimaryTable, error) {
+	if table, has := s.tables[id]; has {
+		return table, nil
+	}
+	return nil, &provider.TableNotFound{Feature: id.Name, Variant: id.Variant}
+}
+
+func (s *copyTestStore) GetTransformationTable(id provider.ResourceID) (provider.TransformationTable, error) {
+	if table, has := s.tables[id]; has {
+		return table, nil
+	}
+	return nil, &provider.TableNotFound{Feature: id.Name, Variant: id.Variant}
+}
+
+func (s *copyTestStore) CreatePrimaryTable(id provider.ResourceID, schema provider.TableSchema) (provider.PrimaryTable, error) {
+	if _, has := s.tables[id]; has {
+		return nil, &provider.TableAlreadyExists{Feature: id.Name, Variant: id.Variant}
+	}
+	table := &copyTestTable{schema: schema}
+	s.tables[id] = table
+	return table, nil
+}
+
+func (s *copyTestStore) DeleteTable(id provider.ResourceID) error {
+	if _, has := s.tables[id]; !has {
+		return &provider.TableNotFound{Feature: id.Name, Variant: id.Variant}
+	}
+	delete(s.tables, id)
+	return nil
+}
+
+func TestOfflineCopyRunner(t *testing.T) {
+	schema := provider.TableSchema{Columns: []provider.TableColumn{
+		{Name: "entity", ValueType: provider.String},
+		{Name: "value", ValueType: provider.Int},
+	}}
+	id := provider.ResourceID{Name: "transactions", Variant: "default", Type: provider.Transformation}
+	source := &copyTestStore{tables: map[provider.ResourceID]*copyTestTable{
+		id: {schema: schema, rows: []provider.GenericRecord{{"a", 1}, {"b", 2}}},
+	}}
+	destination := &copyTestStore{tables: make(map[provider.ResourceID]*copyTestTable)}
+	copier := &OfflineCopyRunner{Source: source, Destination: destination, ID: id}
+	// The second copy replaces the first.
+	for i := 0; i < 2; i++ {
+		watcher, err := copier.Run()
+		if err != nil {
+			t.Fatalf("Failed to run copy: %s", err)
+		}
+		if err := watcher.Wait(); err != nil {
+			t.Fatalf("Copy %d failed: %s", i, err)
+		}
+	}
+	copied, has := destination.tables[provider.ResourceID{Name: id.Name, Variant: id.Variant, Type: provider.Primary}]
+	if !has {
+		t.Fatalf("Copy was not written as a primary table: %v", destination.tables)
+	}
+	if len(copied.rows) != 2 || copied.rows[1][0] != "b" || len(copied.schema.Columns) != 2 {
+		t.Fatalf("Unexpected copy: %+v", copied)
+	}
+	copier.ID = provider.ResourceID{Name: "feature", Variant: "default", Type: provider.Feature}
+	watcher, err := copier.Run()
+	if err != nil {
+		t.Fatalf("Failed to run copy: %s", err)
+	}
+	if err := watcher.Wait(); err == nil {
+		t.Fatalf("Copied a feature table")
+	}
+}
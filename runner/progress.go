@@ -0,0 +1,76 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"time"
+)
+
+// Progress is how far along a job is. Fields that a watcher can't tell are
+// zero, so a job that doesn't report its progress has none.
+type Progress struct {
+	RowsCopied  int64
+	TotalRows   int64
+	ChunksDone  int
+	TotalChunks int
+	// ETA is the estimated time left, or zero if it isn't known.
+	ETA time.Duration
+}
+
+// IsZero returns whether nothing is known about the job's progress.
+func (p Progress) IsZero() bool {
+	return p == Progress{}
+}
+
+func (p Progress) String() string {
+	if p.TotalRows > 0 {
+		return fmt.Sprintf("%d of %d rows copied, %d of %d chunks done, %v left", p.RowsCopied, p.TotalRows, p.ChunksDone, p.TotalChunks, p.ETA)
+	}
+	return fmt.Sprintf("%d of %d chunks done, %v left", p.ChunksDone, p.TotalChunks, p.ETA)
+}
+
+// withETA estimates how long a job that's been running for elapsed has left,
+// going by the rows it's copied, or by the chunks it's done if it doesn't
+// count rows. The ETA is zero until some of the job is done.
+func (p Progress) withETA(elapsed time.Duration) Progress {
+	var done, total float64
+	if p.RowsCopied > 0 && p.TotalRows > 0 {
+		done, total = float64(p.RowsCopied), float64(p.TotalRows)
+	} else {
+		done, total = float64(p.ChunksDone), float64(p.TotalChunks)
+	}
+	p.ETA = 0
+	if done > 0 && done < total {
+		p.ETA = time.Duration(float64(elapsed) * (total - done) / done).Round(time.Second)
+	}
+	return p
+}
+
+// taskProgress is the progress of a job that runs as count tasks, of which
+// done have finished.
+func taskProgress(done, count int, started time.Time) Progress {
+	progress := Progress{ChunksDone: done, TotalChunks: count}
+	if started.IsZero() {
+		return progress
+	}
+	return progress.withETA(time.Since(started))
+}
+
+// materializationWatcher watches a materialization's chunk jobs, and reports
+// their progress against the rows of the whole materialization, which chunks
+// that haven't started yet don't count.
+type materializationWatcher struct {
+	*SyncWatcher
+	chunks  CompletionWatcher
+	numRows int64
+	started time.Time
+}
+
+func (w materializationWatcher) Progress() Progress {
+	progress := w.chunks.Progress()
+	progress.TotalRows = w.numRows
+	return progress.withETA(time.Since(w.started))
+}
@@ -0,0 +1,58 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/featureform/provider"
+)
+
+func TestProgressETA(t *testing.T) {
+	tests := []struct {
+		name     string
+		progress Progress
+		expected time.Duration
+	}{
+		{"Rows", Progress{RowsCopied: 25, TotalRows: 100, ChunksDone: 3, TotalChunks: 4}, 3 * time.Minute},
+		{"Chunks", Progress{ChunksDone: 1, TotalChunks: 3}, 2 * time.Minute},
+		{"NotStarted", Progress{TotalRows: 100, TotalChunks: 4}, 0},
+		{"Done", Progress{RowsCopied: 100, TotalRows: 100}, 0},
+	}
+	for _, test := range tests {
+		if eta := test.progress.withETA(time.Minute).ETA; eta != test.expected {
+			t.Fatalf("%s: expected an ETA of %v, got %v", test.name, test.expected, eta)
+		}
+	}
+}
+
+func TestChunkRunnerProgress(t *testing.T) {
+	online := provider.NewLocalOnlineStore()
+	table, err := online.CreateTable("feature", "variant", provider.Int)
+	if err != nil {
+		t.Fatalf("Failed to create online table: %v", err)
+	}
+	materialized := &MockMaterializedFeatures{Rows: []provider.ResourceRecord{{Entity: "a", Value: 1}, {Entity: "b", Value: 2}, {Entity: "c", Value: 3}}}
+	chunks := make([]CompletionWatcher, 2)
+	for i := range chunks {
+		chunk := &MaterializedChunkRunner{Materialized: materialized, Table: table, ChunkSize: 2, ChunkIdx: int64(i)}
+		if chunks[i], err = chunk.Run(); err != nil {
+			t.Fatalf("Failed to run chunk: %v", err)
+		}
+	}
+	multiplex := WatcherMultiplex{chunks}
+	if err := multiplex.Wait(); err != nil {
+		t.Fatalf("Chunks failed: %v", err)
+	}
+	expected := Progress{RowsCopied: 3, TotalRows: 3, ChunksDone: 2, TotalChunks: 2}
+	if progress := multiplex.Progress(); progress != expected {
+		t.Fatalf("Expected progress %v, got %v", expected, progress)
+	}
+	watcher := materializationWatcher{chunks: multiplex, numRows: 3, started: time.Now()}
+	if progress := watcher.Progress(); progress != expected {
+		t.Fatalf("Expected materialization progress %v, got %v", expected, progress)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/featureform/provider"
 	"testing"
+	"time"
 )
 
 type MockOfflineRegisterSourceFail struct {
@@ -23,12 +24,18 @@ func (m MockOfflineRegisterSourceFail) GetResourceTable(id provider.ResourceID)
 func (m MockOfflineRegisterSourceFail) CreateMaterialization(id provider.ResourceID) (provider.Materialization, error) {
 	return nil, nil
 }
+func (m MockOfflineRegisterSourceFail) CreateIncrementalMaterialization(id provider.ResourceID, since time.Time) (provider.Materialization, error) {
+	return nil, nil
+}
 func (m MockOfflineRegisterSourceFail) GetMaterialization(id provider.MaterializationID) (provider.Materialization, error) {
 	return nil, nil
 }
 func (m MockOfflineRegisterSourceFail) DeleteMaterialization(id provider.MaterializationID) error {
 	return nil
 }
+func (m MockOfflineRegisterSourceFail) DeleteTable(id provider.ResourceID) error {
+	return nil
+}
 func (m MockOfflineRegisterSourceFail) CreateTrainingSet(provider.TrainingSetDef) error {
 	return nil
 }
@@ -59,7 +59,7 @@ func (r *RegisterFileRunner) Run() (CompletionWatcher, error) {
 			if err != nil {
 				jobWatcher.EndWatch(err)
 			}
-			fmt.Printf("%+v\n", rec)
+			defaultLogger.Debugw("Read record", "record", rec)
 		}
 		jobWatcher.EndWatch(nil)
 	}()
@@ -129,7 +129,7 @@ func RegisterFileRunnerFactory(config Config) (Runner, error) {
 	if err := runnerConfig.Deserialize(config); err != nil {
 		return nil, fmt.Errorf("failed to deserialize materialize chunk runner config: %v", err)
 	}
-	offlineProvider, err := provider.Get(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
+	offlineProvider, err := getProvider(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure offline provider: %v", err)
 	}
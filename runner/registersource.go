@@ -73,7 +73,7 @@ func RegisterSourceRunnerFactory(config Config) (Runner, error) {
 	if err := registerConfig.Deserialize(config); err != nil {
 		return nil, fmt.Errorf("failed to deserialize register file config")
 	}
-	offlineProvider, err := provider.Get(registerConfig.OfflineType, registerConfig.OfflineConfig)
+	offlineProvider, err := getProvider(registerConfig.OfflineType, registerConfig.OfflineConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure offline provider: %v", err)
 	}
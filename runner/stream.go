@@ -0,0 +1,253 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+// DefaultStreamCheckpointEvery is how many records a stream job upserts
+// between checkpoints, if its CheckpointEvery isn't set.
+const DefaultStreamCheckpointEvery = 1000
+
+// StreamToOnlineRunner upserts the feature values of a streaming source into
+// the feature's online table as they arrive, until it's cancelled or the
+// stream fails. How far it's read is checkpointed in the online store, so a
+// restarted job carries on from its last checkpoint. Records read since then
+// are upserted again, which leaves the table as it was.
+type StreamToOnlineRunner struct {
+	Stream provider.StreamingSource
+	Topic  string
+	Online provider.OnlineStore
+	Table  provider.OnlineStoreTable
+	ID     provider.ResourceID
+	// CheckpointEvery is how many records are upserted between checkpoints.
+	CheckpointEvery int
+}
+
+func (s *StreamToOnlineRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{
+		Name:    s.ID.Name,
+		Variant: s.ID.Variant,
+		Type:    metadata.FEATURE_VARIANT,
+	}
+}
+
+func (s *StreamToOnlineRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (s *StreamToOnlineRunner) Run() (CompletionWatcher, error) {
+	return s.RunWithContext(context.Background())
+}
+
+// RunWithContext streams until ctx is done or the returned watcher is
+// cancelled, which is how a stream job normally ends, so it ends without an
+// error.
+func (s *StreamToOnlineRunner) RunWithContext(ctx context.Context) (CompletionWatcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	jobWatcher := &streamWatcher{
+		SyncWatcher: &SyncWatcher{
+			ResultSync:  &ResultSync{},
+			DoneChannel: make(chan interface{}),
+		},
+		cancel: cancel,
+	}
+	go func() {
+		err := s.stream(ctx, jobWatcher.ResultSync)
+		if errors.Is(err, context.Canceled) && ctx.Err() != nil {
+			err = nil
+		}
+		jobWatcher.EndWatch(err)
+	}()
+	return jobWatcher, nil
+}
+
+func (s *StreamToOnlineRunner) checkpointEvery() int {
+	if s.CheckpointEvery > 0 {
+		return s.CheckpointEvery
+	}
+	return DefaultStreamCheckpointEvery
+}
+
+// stream upserts records until reading or upserting one fails, then
+// checkpoints the records that were upserted.
+func (s *StreamToOnlineRunner) stream(ctx context.Context, result *ResultSync) error {
+	offsets, err := getStreamOffsets(s.Online, s.ID, s.Topic)
+	if err != nil {
+		return err
+	}
+	reader, err := s.Stream.Subscribe(s.Topic, offsets)
+	if err != nil {
+		return fmt.Errorf("subscribe to %s: %w", s.Topic, err)
+	}
+	defer reader.Close()
+	var copied int64
+	pending := 0
+	for {
+		record, err := reader.Next(ctx)
+		if err == nil {
+			err = s.Table.Set(record.Entity, record.Value)
+			if err != nil {
+				err = fmt.Errorf("set entity %s: %w", record.Entity, err)
+			}
+		} else if ctx.Err() == nil {
+			err = fmt.Errorf("read %s: %w", s.Topic, err)
+		}
+		if err != nil {
+			if pending > 0 {
+				if checkpointErr := setStreamOffsets(s.Online, s.ID, s.Topic, offsets); checkpointErr != nil {
+					return fmt.Errorf("%w, and checkpointing failed: %v", err, checkpointErr)
+				}
+			}
+			return err
+		}
+		offsets[record.Partition] = record.Offset
+		copied++
+		pending++
+		result.SetProgress(Progress{RowsCopied: copied})
+		if pending >= s.checkpointEvery() {
+			if err := setStreamOffsets(s.Online, s.ID, s.Topic, offsets); err != nil {
+				return err
+			}
+			pending = 0
+		}
+	}
+}
+
+// streamWatcher watches a stream job, which only completes once it's
+// cancelled or fails.
+type streamWatcher struct {
+	*SyncWatcher
+	cancel context.CancelFunc
+}
+
+// Cancel stops the stream and waits for its last checkpoint to be written.
+func (w *streamWatcher) Cancel() error {
+	w.cancel()
+	<-w.DoneChannel
+	return nil
+}
+
+// Stream offsets are kept in the online store next to the watermarks, by the
+// feature and topic that was read.
+const (
+	streamOffsetsTableName    = "featureform_stream_offsets"
+	streamOffsetsTableVariant = "offsets"
+)
+
+func streamOffsetsKey(id provider.ResourceID, topic string) string {
+	return fmt.Sprintf("%s__%s", watermarkKey(id), topic)
+}
+
+// getStreamOffsets returns the offsets that topic was last checkpointed at
+// for id, which are empty if it hasn't been.
+func getStreamOffsets(store provider.OnlineStore, id provider.ResourceID, topic string) (provider.StreamOffsets, error) {
+	offsets := provider.StreamOffsets{}
+	table, err := store.GetTable(streamOffsetsTableName, streamOffsetsTableVariant)
+	if _, notFound := err.(*provider.TableNotFound); notFound {
+		return offsets, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("get stream offsets table: %w", err)
+	}
+	value, err := table.Get(streamOffsetsKey(id, topic))
+	if _, notFound := err.(*provider.EntityNotFound); notFound || value == nil {
+		return offsets, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("get stream offsets: %w", err)
+	}
+	if err := json.Unmarshal([]byte(fmt.Sprint(value)), &offsets); err != nil {
+		return nil, fmt.Errorf("invalid stream offsets %v: %w", value, err)
+	}
+	return offsets, nil
+}
+
+func setStreamOffsets(store provider.OnlineStore, id provider.ResourceID, topic string, offsets provider.StreamOffsets) error {
+	table, err := store.CreateTable(streamOffsetsTableName, streamOffsetsTableVariant, provider.String)
+	if _, exists := err.(*provider.TableAlreadyExists); exists {
+		table, err = store.GetTable(streamOffsetsTableName, streamOffsetsTableVariant)
+	}
+	if err != nil {
+		return fmt.Errorf("get stream offsets table: %w", err)
+	}
+	serialized, err := json.Marshal(offsets)
+	if err != nil {
+		return err
+	}
+	if err := table.Set(streamOffsetsKey(id, topic), string(serialized)); err != nil {
+		return fmt.Errorf("checkpoint stream offsets: %w", err)
+	}
+	return nil
+}
+
+type StreamToOnlineRunnerConfig struct {
+	SourceType      provider.Type
+	SourceConfig    provider.SerializedConfig
+	Topic           string
+	OnlineType      provider.Type
+	OnlineConfig    provider.SerializedConfig
+	ResourceID      provider.ResourceID
+	ValueType       provider.ValueType
+	CheckpointEvery int `json:",omitempty"`
+}
+
+func (c *StreamToOnlineRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *StreamToOnlineRunnerConfig) Deserialize(config Config) error {
+	return json.Unmarshal(config, c)
+}
+
+// StreamToOnlineRunnerFactory returns a runner that streams into the
+// feature's online table, which is created if it doesn't exist yet.
+func StreamToOnlineRunnerFactory(config Config) (Runner, error) {
+	runnerConfig := &StreamToOnlineRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize stream to online runner config: %v", err)
+	}
+	sourceProvider, err := getProvider(runnerConfig.SourceType, runnerConfig.SourceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure streaming provider: %v", err)
+	}
+	stream, err := provider.AsStreamingSource(sourceProvider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to streaming source: %v", err)
+	}
+	onlineProvider, err := getProvider(runnerConfig.OnlineType, runnerConfig.OnlineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure online provider: %v", err)
+	}
+	onlineStore, err := onlineProvider.AsOnlineStore()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert provider to online store: %v", err)
+	}
+	id := runnerConfig.ResourceID
+	table, err := onlineStore.GetTable(id.Name, id.Variant)
+	if _, notFound := err.(*provider.TableNotFound); notFound {
+		table, err = onlineStore.CreateTable(id.Name, id.Variant, runnerConfig.ValueType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get online table: %v", err)
+	}
+	return &StreamToOnlineRunner{
+		Stream:          stream,
+		Topic:           runnerConfig.Topic,
+		Online:          onlineStore,
+		Table:           table,
+		ID:              id,
+		CheckpointEvery: runnerConfig.CheckpointEvery,
+	}, nil
+}
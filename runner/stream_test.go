@@ -0,0 +1,136 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/featureform/provider"
+)
+
+// waitForRows waits for a stream job to have copied rows records.
+func waitForRows(t *testing.T, watcher CompletionWatcher, rows int64) {
+	deadline := time.Now().Add(5 * time.Second)
+	for watcher.Progress().RowsCopied < rows {
+		if watcher.Complete() {
+			t.Fatalf("Stream stopped after %d rows: %v", watcher.Progress().RowsCopied, watcher.Err())
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %d rows, copied %d", rows, watcher.Progress().RowsCopied)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestStreamToOnlineRunner(t *testing.T) {
+	stream := provider.NewMemoryStreamingSource()
+	online := provider.NewLocalOnlineStore()
+	table, err := online.CreateTable("feature", "variant", provider.Int)
+	if err != nil {
+		t.Fatalf("Failed to create online table: %v", err)
+	}
+	counting := &countingOnlineTable{OnlineStoreTable: table}
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	streamRunner := &StreamToOnlineRunner{Stream: stream, Topic: "clicks", Online: online, Table: counting, ID: id, CheckpointEvery: 2}
+	stream.Publish("clicks", provider.ResourceRecord{Entity: "a", Value: 1}, provider.ResourceRecord{Entity: "b", Value: 2})
+	watcher, err := streamRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run stream: %v", err)
+	}
+	waitForRows(t, watcher, 2)
+	stream.Publish("clicks", provider.ResourceRecord{Entity: "a", Value: 3})
+	waitForRows(t, watcher, 3)
+	if err := watcher.(CancellableWatcher).Cancel(); err != nil {
+		t.Fatalf("Failed to cancel stream: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Expected a cancelled stream to end without an error: %v", err)
+	}
+	if value, err := table.Get("a"); err != nil || value != 3 {
+		t.Fatalf("Expected a to be upserted to 3, got %v %v", value, err)
+	}
+	offsets, err := getStreamOffsets(online, id, "clicks")
+	if err != nil {
+		t.Fatalf("Failed to get stream offsets: %v", err)
+	}
+	if offsets["0"] != "2" {
+		t.Fatalf("Expected the stream to be checkpointed at its last record, got %v", offsets)
+	}
+
+	stream.Publish("clicks", provider.ResourceRecord{Entity: "c", Value: 4})
+	watcher, err = streamRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to restart stream: %v", err)
+	}
+	waitForRows(t, watcher, 1)
+	if err := watcher.(CancellableWatcher).Cancel(); err != nil {
+		t.Fatalf("Failed to cancel stream: %v", err)
+	}
+	if counting.sets != 4 {
+		t.Fatalf("Expected a restarted stream to carry on from its checkpoint, got %d upserts", counting.sets)
+	}
+	if value, err := table.Get("c"); err != nil || value != 4 {
+		t.Fatalf("Expected c to be upserted to 4, got %v %v", value, err)
+	}
+}
+
+type failingOnlineTable struct {
+	provider.OnlineStoreTable
+	failOn string
+}
+
+func (t *failingOnlineTable) Set(entity string, value interface{}) error {
+	if entity == t.failOn {
+		return errors.New("write failed")
+	}
+	return t.OnlineStoreTable.Set(entity, value)
+}
+
+func TestStreamToOnlineRunnerCheckpointsOnFailure(t *testing.T) {
+	stream := provider.NewMemoryStreamingSource()
+	online := provider.NewLocalOnlineStore()
+	table, err := online.CreateTable("feature", "variant", provider.Int)
+	if err != nil {
+		t.Fatalf("Failed to create online table: %v", err)
+	}
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	streamRunner := &StreamToOnlineRunner{Stream: stream, Topic: "clicks", Online: online, Table: &failingOnlineTable{table, "bad"}, ID: id}
+	stream.Publish("clicks", provider.ResourceRecord{Entity: "a", Value: 1}, provider.ResourceRecord{Entity: "bad", Value: 2})
+	watcher, err := streamRunner.Run()
+	if err != nil {
+		t.Fatalf("Failed to run stream: %v", err)
+	}
+	if err := watcher.Wait(); err == nil {
+		t.Fatalf("Expected the stream to fail")
+	}
+	offsets, err := getStreamOffsets(online, id, "clicks")
+	if err != nil {
+		t.Fatalf("Failed to get stream offsets: %v", err)
+	}
+	if offsets["0"] != "0" {
+		t.Fatalf("Expected the record before the failure to be checkpointed, got %v", offsets)
+	}
+}
+
+func TestStreamToOnlineRunnerFactoryNotStreaming(t *testing.T) {
+	config, err := (&StreamToOnlineRunnerConfig{
+		SourceType:   provider.LocalOnline,
+		SourceConfig: []byte("{}"),
+		OnlineType:   provider.LocalOnline,
+		OnlineConfig: []byte("{}"),
+		ResourceID:   provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature},
+	}).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to serialize config: %v", err)
+	}
+	if _, err := StreamToOnlineRunnerFactory(config); err == nil {
+		t.Fatalf("Expected a provider that isn't a streaming source to fail")
+	}
+	if _, err := StreamToOnlineRunnerFactory([]byte("not json")); err == nil {
+		t.Fatalf("Expected an invalid config to fail")
+	}
+}
@@ -0,0 +1,132 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/featureform/metadata"
+)
+
+// subprocessOutputTail is how much of a worker process's output is kept, to
+// explain why it failed.
+const subprocessOutputTail = 4 << 10
+
+type SubprocessRunnerConfig struct {
+	EnvVars map[string]string
+	// Binary is the worker binary that the job is run with.
+	Binary   string
+	Resource metadata.ResourceID
+	// Output is where the process's stdout and stderr are written, such as
+	// the coordinator's own. It's discarded if it's nil.
+	Output io.Writer
+}
+
+// SubprocessRunner runs a job in a process of its own, started from the
+// worker binary on the same machine. A runner that panics or runs out of
+// memory only takes down its own process.
+type SubprocessRunner struct {
+	config SubprocessRunnerConfig
+}
+
+func NewSubprocessRunner(config SubprocessRunnerConfig) SubprocessRunner {
+	return SubprocessRunner{config: config}
+}
+
+func (s SubprocessRunner) Resource() metadata.ResourceID {
+	return s.config.Resource
+}
+
+func (s SubprocessRunner) IsUpdateJob() bool {
+	return false
+}
+
+// Run starts the worker process. The process inherits the environment of
+// this one, with the job's env vars on top.
+func (s SubprocessRunner) Run() (CompletionWatcher, error) {
+	cmd := exec.Command(s.config.Binary)
+	cmd.Env = os.Environ()
+	for key, value := range s.config.EnvVars {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+	tail := &tailBuffer{limit: subprocessOutputTail}
+	var output io.Writer = tail
+	if s.config.Output != nil {
+		output = io.MultiWriter(s.config.Output, tail)
+	}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start worker %s: %w", s.config.Binary, err)
+	}
+	watcher := &SubprocessCompletionWatcher{
+		SyncWatcher: &SyncWatcher{
+			ResultSync:  &ResultSync{},
+			DoneChannel: make(chan interface{}),
+		},
+		process: cmd.Process,
+		started: time.Now(),
+	}
+	go func() {
+		err := cmd.Wait()
+		if err != nil {
+			err = fmt.Errorf("worker process failed: %w: %s", err, tail.String())
+		}
+		watcher.EndWatch(err)
+	}()
+	return watcher, nil
+}
+
+// SubprocessCompletionWatcher watches a worker process.
+type SubprocessCompletionWatcher struct {
+	*SyncWatcher
+	process *os.Process
+	started time.Time
+}
+
+func (s *SubprocessCompletionWatcher) String() string {
+	if s.Complete() {
+		return fmt.Sprintf("Worker process %d exited", s.process.Pid)
+	}
+	return fmt.Sprintf("Worker process %d running for %v", s.process.Pid, time.Since(s.started).Round(time.Second))
+}
+
+// Cancel kills the worker process and waits for it to exit.
+func (s *SubprocessCompletionWatcher) Cancel() error {
+	if err := s.process.Kill(); err != nil && !s.Complete() {
+		return fmt.Errorf("kill worker process %d: %w", s.process.Pid, err)
+	}
+	<-s.DoneChannel
+	return nil
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	data  []byte
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = append(b.data, p...)
+	if len(b.data) > b.limit {
+		b.data = b.data[len(b.data)-b.limit:]
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return strings.TrimSpace(string(b.data))
+}
@@ -0,0 +1,80 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// workerScript writes an executable script that stands in for the worker
+// binary.
+func workerScript(t *testing.T, script string) string {
+	path := filepath.Join(t.TempDir(), "worker")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatalf("Failed to write worker script: %v", err)
+	}
+	return path
+}
+
+func TestSubprocessRunner(t *testing.T) {
+	var output bytes.Buffer
+	subprocess := NewSubprocessRunner(SubprocessRunnerConfig{
+		EnvVars: map[string]string{"NAME": "Materialize"},
+		Binary:  workerScript(t, `echo "running $NAME"`),
+		Output:  &output,
+	})
+	watcher, err := subprocess.Run()
+	if err != nil {
+		t.Fatalf("Failed to run worker: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("Worker failed: %v", err)
+	}
+	if !watcher.Complete() || output.String() != "running Materialize\n" {
+		t.Fatalf("Expected the worker to run with the job's env vars, got %q", output.String())
+	}
+}
+
+func TestSubprocessRunnerFails(t *testing.T) {
+	subprocess := NewSubprocessRunner(SubprocessRunnerConfig{Binary: workerScript(t, "echo 'panic: runner broke' >&2; exit 2")})
+	watcher, err := subprocess.Run()
+	if err != nil {
+		t.Fatalf("Failed to run worker: %v", err)
+	}
+	err = watcher.Wait()
+	if err == nil || !strings.Contains(err.Error(), "exit status 2") || !strings.Contains(err.Error(), "panic: runner broke") {
+		t.Fatalf("Expected the worker's exit status and output in its error, got %v", err)
+	}
+	if _, err := NewSubprocessRunner(SubprocessRunnerConfig{Binary: filepath.Join(t.TempDir(), "missing")}).Run(); err == nil {
+		t.Fatalf("Expected a missing worker binary to fail")
+	}
+}
+
+func TestSubprocessRunnerCancel(t *testing.T) {
+	subprocess := NewSubprocessRunner(SubprocessRunnerConfig{Binary: workerScript(t, "exec sleep 60")})
+	watcher, err := subprocess.Run()
+	if err != nil {
+		t.Fatalf("Failed to run worker: %v", err)
+	}
+	if err := watcher.(CancellableWatcher).Cancel(); err != nil {
+		t.Fatalf("Failed to cancel worker: %v", err)
+	}
+	if !watcher.Complete() || watcher.Err() == nil {
+		t.Fatalf("Expected a killed worker to have failed")
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	tail := &tailBuffer{limit: 4}
+	tail.Write([]byte("abc"))
+	tail.Write([]byte("def"))
+	if tail.String() != "cdef" {
+		t.Fatalf("Expected the last 4 bytes, got %q", tail.String())
+	}
+}
@@ -0,0 +1,100 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/featureform/provider"
+)
+
+// The run-time templates of a transformation's query are filled in when
+// each of its runs starts, rather than when it's registered, so that each
+// scheduled run or backfill window transforms its own slice of time.
+const (
+	// RunDateTemplate is the date of the run, which is the day its window
+	// ends on.
+	RunDateTemplate = "run_date"
+	// ScheduleStartTemplate is when the run's window starts: the scheduled
+	// run before this one, or the start of a backfill's window. Runs that
+	// aren't scheduled start at the Unix epoch, so they see all of the data.
+	ScheduleStartTemplate = "schedule_start"
+	// ScheduleEndTemplate is when the run's window ends: when the run was
+	// scheduled, or the end of a backfill's window.
+	ScheduleEndTemplate = "schedule_end"
+)
+
+// IsRunTimeTemplate returns whether template is filled in when the
+// transformation runs.
+func IsRunTimeTemplate(template string) bool {
+	switch template {
+	case RunDateTemplate, ScheduleStartTemplate, ScheduleEndTemplate:
+		return true
+	default:
+		return false
+	}
+}
+
+// ExpandTemplates replaces each {{ ... }} template of a query with what
+// expand returns for its trimmed contents. The query is lexed with syntax,
+// the provider's, so braces in comments and quoted strings, including
+// dollar-quoted and backslash-escaped ones, aren't taken for templates, and
+// templates are filled in across every statement and CTE of the query. A
+// template inside another or one that's never closed is an error rather
+// than a mangled query.
+func ExpandTemplates(syntax provider.QuerySyntax, query string, expand func(template string) (string, error)) (string, error) {
+	tokens, err := syntax.Tokens(query)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for _, token := range tokens {
+		if token.Kind != provider.SQLTemplate {
+			out.WriteString(token.Text)
+			continue
+		}
+		expanded, err := expand(strings.TrimSpace(token.Text[2 : len(token.Text)-2]))
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(expanded)
+	}
+	return out.String(), nil
+}
+
+// runTemplates are the values of the run-time templates for a run at now,
+// as SQL literals.
+func runTemplates(schedule string, backfill *BackfillWindow, now time.Time) map[string]string {
+	start, end := time.Unix(0, 0), now
+	if backfill != nil {
+		start, end = backfill.Since, backfill.Until
+	} else if last, previous := scheduledRuns(schedule, now); !previous.IsZero() {
+		start, end = previous, last
+	}
+	const timestamp = "2006-01-02 15:04:05"
+	return map[string]string{
+		RunDateTemplate:       fmt.Sprintf("'%s'", end.UTC().Format("2006-01-02")),
+		ScheduleStartTemplate: fmt.Sprintf("'%s'", start.UTC().Format(timestamp)),
+		ScheduleEndTemplate:   fmt.Sprintf("'%s'", end.UTC().Format(timestamp)),
+	}
+}
+
+// expandRunTemplates fills in the run-time templates of a query that the
+// coordinator left for its runs.
+func expandRunTemplates(syntax provider.QuerySyntax, query, schedule string, backfill *BackfillWindow, now time.Time) (string, error) {
+	if !strings.Contains(query, "{{") {
+		return query, nil
+	}
+	values := runTemplates(schedule, backfill, now)
+	return ExpandTemplates(syntax, query, func(template string) (string, error) {
+		value, has := values[template]
+		if !has {
+			return "", fmt.Errorf("unknown run time template %s", template)
+		}
+		return value, nil
+	})
+}
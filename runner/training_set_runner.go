@@ -5,32 +5,70 @@
 package runner
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/featureform/metadata"
 	"github.com/featureform/provider"
+	"time"
 )
 
 type TrainingSetRunner struct {
 	Offline  provider.OfflineStore
 	Def      provider.TrainingSetDef
 	IsUpdate bool
+	// Schedule is the cron schedule that update jobs run on. Training sets
+	// updated on the same schedule share cached feature tables within each
+	// scheduled run.
+	Schedule string
 }
 
 func (m TrainingSetRunner) Run() (CompletionWatcher, error) {
+	return m.RunWithContext(context.Background())
+}
+
+// RunWithContext creates the training set, cancelling its queries in the
+// warehouse if ctx is done first.
+func (m TrainingSetRunner) RunWithContext(ctx context.Context) (CompletionWatcher, error) {
+	offline, release, err := provider.OfflineStoreWithContext(ctx, m.Offline)
+	if err != nil {
+		return nil, err
+	}
+	m.Offline = offline
 	done := make(chan interface{})
 	trainingSetWatcher := &SyncWatcher{
 		ResultSync:  &ResultSync{},
 		DoneChannel: done,
 	}
 	go func() {
+		defer release()
 		if !m.IsUpdate {
 			if err := m.Offline.CreateTrainingSet(m.Def); err != nil {
 				trainingSetWatcher.EndWatch(err)
 				return
 			}
 		} else {
-			if err := m.Offline.UpdateTrainingSet(m.Def); err != nil {
+			def := m.Def
+			cycle, previous := scheduledRuns(m.Schedule, time.Now())
+			def.CacheCycle = cycle
+			if err := m.Offline.UpdateTrainingSet(def); err != nil {
+				trainingSetWatcher.EndWatch(err)
+				return
+			}
+			// The caches this training set used in the previous run are kept
+			// for any of the run's jobs that are still going.
+			if cache, ok := m.Offline.(provider.TrainingSetCacheStore); ok && !previous.IsZero() {
+				if err := cache.PruneTrainingSetCache(def.ID, previous); err != nil {
+					trainingSetWatcher.EndWatch(fmt.Errorf("prune training set cache: %w", err))
+					return
+				}
+			}
+		}
+		// Exported files are rewritten with each scheduled update, so they
+		// always hold the training set's current rows. The coordinator
+		// exports a training set once it's first created.
+		if m.IsUpdate && m.Def.Export != nil {
+			if err := exportTrainingSet(ctx, m.Offline, nil, m.Def); err != nil {
 				trainingSetWatcher.EndWatch(err)
 				return
 			}
@@ -45,6 +83,7 @@ type TrainingSetRunnerConfig struct {
 	OfflineConfig provider.SerializedConfig
 	Def           provider.TrainingSetDef
 	IsUpdate      bool
+	Schedule      string
 }
 
 func (t TrainingSetRunner) Resource() metadata.ResourceID {
@@ -81,7 +120,7 @@ func TrainingSetRunnerFactory(config Config) (Runner, error) {
 	if err := runnerConfig.Deserialize(config); err != nil {
 		return nil, fmt.Errorf("failed to deserialize materialize chunk runner config: %v", err)
 	}
-	offlineProvider, err := provider.Get(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
+	offlineProvider, err := getProvider(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to configure offline provider: %v", err)
 	}
@@ -93,5 +132,6 @@ func TrainingSetRunnerFactory(config Config) (Runner, error) {
 		Offline:  offlineStore,
 		Def:      runnerConfig.Def,
 		IsUpdate: runnerConfig.IsUpdate,
+		Schedule: runnerConfig.Schedule,
 	}, nil
 }
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"github.com/featureform/provider"
 	"testing"
+	"time"
 )
 
 type MockOfflineCreateTrainingSetFail struct {
@@ -23,6 +24,9 @@ func (m MockOfflineCreateTrainingSetFail) GetResourceTable(id provider.ResourceI
 func (m MockOfflineCreateTrainingSetFail) CreateMaterialization(id provider.ResourceID) (provider.Materialization, error) {
 	return nil, nil
 }
+func (m MockOfflineCreateTrainingSetFail) CreateIncrementalMaterialization(id provider.ResourceID, since time.Time) (provider.Materialization, error) {
+	return nil, nil
+}
 func (m MockOfflineCreateTrainingSetFail) UpdateMaterialization(id provider.ResourceID) (provider.Materialization, error) {
 	return nil, nil
 }
@@ -32,6 +36,9 @@ func (m MockOfflineCreateTrainingSetFail) GetMaterialization(id provider.Materia
 func (m MockOfflineCreateTrainingSetFail) DeleteMaterialization(id provider.MaterializationID) error {
 	return nil
 }
+func (m MockOfflineCreateTrainingSetFail) DeleteTable(id provider.ResourceID) error {
+	return nil
+}
 func (m MockOfflineCreateTrainingSetFail) CreateTrainingSet(provider.TrainingSetDef) error {
 	return fmt.Errorf("could not create training set")
 }
@@ -71,6 +78,7 @@ func TestRunTrainingSet(t *testing.T) {
 		MockOfflineStore{},
 		provider.TrainingSetDef{},
 		false,
+		"",
 	}
 	watcher, err := runner.Run()
 	if err != nil {
@@ -81,11 +89,58 @@ func TestRunTrainingSet(t *testing.T) {
 	}
 }
 
+type cachingTrainingSetStore struct {
+	provider.OfflineStore
+	updatedDef   provider.TrainingSetDef
+	prunedID     provider.ResourceID
+	prunedBefore time.Time
+}
+
+func (m *cachingTrainingSetStore) UpdateTrainingSet(def provider.TrainingSetDef) error {
+	m.updatedDef = def
+	return nil
+}
+
+func (m *cachingTrainingSetStore) PruneTrainingSetCache(id provider.ResourceID, before time.Time) error {
+	m.prunedID = id
+	m.prunedBefore = before
+	return nil
+}
+
+func TestScheduledTrainingSetUsesCache(t *testing.T) {
+	store := &cachingTrainingSetStore{}
+	id := provider.ResourceID{Name: "training_set", Variant: "variant", Type: provider.TrainingSet}
+	runner := TrainingSetRunner{
+		Offline:  store,
+		Def:      provider.TrainingSetDef{ID: id},
+		IsUpdate: true,
+		Schedule: "*/5 * * * *",
+	}
+	watcher, err := runner.Run()
+	if err != nil {
+		t.Fatalf("failed to create training set runner: %v", err)
+	}
+	if err := watcher.Wait(); err != nil {
+		t.Fatalf("training set runner failed: %v", err)
+	}
+	cycle, previous := store.updatedDef.CacheCycle, store.prunedBefore
+	if cycle.IsZero() || cycle.After(time.Now()) || cycle.Minute()%5 != 0 {
+		t.Fatalf("Expected cache cycle to be the last scheduled run, got %v", cycle)
+	}
+	if !previous.Equal(cycle.Add(-5 * time.Minute)) {
+		t.Fatalf("Expected caches before %v to be pruned, got %v", cycle.Add(-5*time.Minute), previous)
+	}
+	if store.prunedID != id {
+		t.Fatalf("Expected the caches of %v to be pruned, got %v", id, store.prunedID)
+	}
+}
+
 func TestFailTrainingSet(t *testing.T) {
 	runner := TrainingSetRunner{
 		MockOfflineCreateTrainingSetFail{},
 		provider.TrainingSetDef{},
 		false,
+		"",
 	}
 	watcher, err := runner.Run()
 	if err != nil {
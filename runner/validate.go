@@ -0,0 +1,365 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/featureform/metadata"
+	"github.com/featureform/provider"
+)
+
+type ExpectationKind string
+
+const (
+	// ExpectNonNull checks that at least MinPercent of a column's values
+	// aren't null.
+	ExpectNonNull ExpectationKind = "NON_NULL"
+	// ExpectRange checks that a column's values that aren't null are numbers
+	// between Min and Max, inclusive. Either bound can be left out.
+	ExpectRange ExpectationKind = "RANGE"
+	// ExpectUnique checks that no two of a column's values that aren't null
+	// are the same.
+	ExpectUnique ExpectationKind = "UNIQUE"
+	// ExpectRowCountDelta checks that the number of rows is within
+	// MaxDeltaPercent of the table's previous row count.
+	ExpectRowCountDelta ExpectationKind = "ROW_COUNT_DELTA"
+)
+
+type ExpectationSeverity string
+
+const (
+	// SeverityFail fails the validation job if the expectation isn't met.
+	// It's the default.
+	SeverityFail ExpectationSeverity = "FAIL"
+	// SeverityWarn reports the expectation in the job's results without
+	// failing it.
+	SeverityWarn ExpectationSeverity = "WARN"
+)
+
+// Expectation is a check of the data in a source or materialization.
+type Expectation struct {
+	Kind ExpectationKind
+	// Column is the column that's checked. Row count expectations don't
+	// have one.
+	Column          string
+	MinPercent      float64  `json:",omitempty"`
+	Min             *float64 `json:",omitempty"`
+	Max             *float64 `json:",omitempty"`
+	MaxDeltaPercent float64  `json:",omitempty"`
+	Severity        ExpectationSeverity
+}
+
+func (e Expectation) fails() bool {
+	return e.Severity != SeverityWarn
+}
+
+func (e Expectation) String() string {
+	if e.Column == "" {
+		return string(e.Kind)
+	}
+	return fmt.Sprintf("%s(%s)", e.Kind, e.Column)
+}
+
+// ExpectationResult is how the data measured up to an expectation.
+type ExpectationResult struct {
+	Expectation Expectation
+	Passed      bool
+	Message     string
+}
+
+// ValidationFailed is the error of a validation job whose failing
+// expectations weren't met.
+type ValidationFailed struct {
+	Failures []ExpectationResult
+}
+
+func (err *ValidationFailed) Error() string {
+	msgs := make([]string, len(err.Failures))
+	for i, failure := range err.Failures {
+		msgs[i] = fmt.Sprintf("%s: %s", failure.Expectation, failure.Message)
+	}
+	return fmt.Sprintf("%d expectations failed: %s", len(err.Failures), strings.Join(msgs, "; "))
+}
+
+// ValidationRunner checks a source's table, or a feature's materialization,
+// against a set of expectations, so that bad data can stop a pipeline before
+// the features made from it are updated. Expectations with SeverityWarn are
+// reported in Results without failing the job.
+type ValidationRunner struct {
+	Offline provider.OfflineStore
+	// ID is the primary table or transformation that's validated, unless
+	// MaterializationID is set.
+	ID                provider.ResourceID
+	MaterializationID provider.MaterializationID
+	Expectations      []Expectation
+	// PreviousRowCount is what row count deltas are measured from. Row count
+	// expectations pass if it's zero, since there's nothing to compare to.
+	PreviousRowCount int64
+	// Results hold how each expectation went once the job is done.
+	Results []ExpectationResult
+}
+
+func (v *ValidationRunner) Resource() metadata.ResourceID {
+	return metadata.ResourceID{}
+}
+
+func (v *ValidationRunner) IsUpdateJob() bool {
+	return false
+}
+
+func (v *ValidationRunner) Run() (CompletionWatcher, error) {
+	done := make(chan interface{})
+	jobWatcher := &SyncWatcher{
+		ResultSync:  &ResultSync{},
+		DoneChannel: done,
+	}
+	go func() {
+		jobWatcher.EndWatch(v.validate())
+	}()
+	return jobWatcher, nil
+}
+
+func (v *ValidationRunner) validate() error {
+	rows, err := v.rows()
+	if err != nil {
+		return err
+	}
+	results, err := checkExpectations(rows, v.Expectations, v.PreviousRowCount)
+	if err != nil {
+		return err
+	}
+	v.Results = results
+	failed := &ValidationFailed{}
+	for _, result := range results {
+		if !result.Passed && result.Expectation.fails() {
+			failed.Failures = append(failed.Failures, result)
+		}
+	}
+	if len(failed.Failures) > 0 {
+		return failed
+	}
+	return nil
+}
+
+func (v *ValidationRunner) rows() (provider.GenericTableIterator, error) {
+	if v.MaterializationID != "" {
+		mat, err := v.Offline.GetMaterialization(v.MaterializationID)
+		if err != nil {
+			return nil, fmt.Errorf("get materialization: %w", err)
+		}
+		numRows, err := mat.NumRows()
+		if err != nil {
+			return nil, fmt.Errorf("count materialization rows: %w", err)
+		}
+		it, err := mat.IterateSegment(0, numRows)
+		if err != nil {
+			return nil, fmt.Errorf("iterate materialization: %w", err)
+		}
+		return &materializationRows{it}, nil
+	}
+	var table provider.PrimaryTable
+	var err error
+	switch v.ID.Type {
+	case provider.Primary:
+		table, err = v.Offline.GetPrimaryTable(v.ID)
+	case provider.Transformation:
+		table, err = v.Offline.GetTransformationTable(v.ID)
+	default:
+		return nil, fmt.Errorf("only sources and materializations can be validated, not %v", v.ID.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get table: %w", err)
+	}
+	numRows, err := table.NumRows()
+	if err != nil {
+		return nil, fmt.Errorf("count table rows: %w", err)
+	}
+	it, err := table.IterateSegment(numRows)
+	if err != nil {
+		return nil, fmt.Errorf("iterate table: %w", err)
+	}
+	return it, nil
+}
+
+// materializationColumns are the columns that a materialization's records
+// are validated as.
+var materializationColumns = []string{"entity", "value", "ts"}
+
+// materializationRows reads a materialization's records as rows of its
+// entity, value and timestamp.
+type materializationRows struct {
+	provider.FeatureIterator
+}
+
+func (it *materializationRows) Values() provider.GenericRecord {
+	rec := it.Value()
+	return provider.GenericRecord{rec.Entity, rec.Value, rec.TS}
+}
+
+func (it *materializationRows) Columns() []string {
+	return materializationColumns
+}
+
+// expectationCheck tallies an expectation's column over the rows it's read.
+type expectationCheck struct {
+	Expectation
+	col        int
+	nulls      int64
+	violations int64
+	seen       map[string]bool
+}
+
+func (check *expectationCheck) add(value interface{}) {
+	if value == nil {
+		check.nulls++
+		return
+	}
+	switch check.Kind {
+	case ExpectRange:
+		num, ok := toFloat(value)
+		if !ok || (check.Min != nil && num < *check.Min) || (check.Max != nil && num > *check.Max) {
+			check.violations++
+		}
+	case ExpectUnique:
+		key := fmt.Sprint(value)
+		if check.seen[key] {
+			check.violations++
+		}
+		check.seen[key] = true
+	}
+}
+
+func (check *expectationCheck) result(rows, previousRows int64) ExpectationResult {
+	result := ExpectationResult{Expectation: check.Expectation, Passed: true}
+	switch check.Kind {
+	case ExpectNonNull:
+		percent := 100.0
+		if rows > 0 {
+			percent = float64(rows-check.nulls) / float64(rows) * 100
+		}
+		result.Passed = percent >= check.MinPercent
+		result.Message = fmt.Sprintf("%.2f%% of %d values aren't null, expected at least %.2f%%", percent, rows, check.MinPercent)
+	case ExpectRange:
+		result.Passed = check.violations == 0
+		result.Message = fmt.Sprintf("%d of %d values are out of range", check.violations, rows)
+	case ExpectUnique:
+		result.Passed = check.violations == 0
+		result.Message = fmt.Sprintf("%d of %d values are duplicates", check.violations, rows)
+	case ExpectRowCountDelta:
+		if previousRows == 0 {
+			result.Message = fmt.Sprintf("%d rows, with no previous count to compare to", rows)
+			break
+		}
+		delta := math.Abs(float64(rows-previousRows)) / float64(previousRows) * 100
+		result.Passed = delta <= check.MaxDeltaPercent
+		result.Message = fmt.Sprintf("%d rows is a %.2f%% change from %d, expected at most %.2f%%", rows, delta, previousRows, check.MaxDeltaPercent)
+	}
+	return result
+}
+
+// checkExpectations reads every row and returns the result of each
+// expectation, in order. It fails if an expectation is invalid, like one of
+// a column that isn't in the rows.
+func checkExpectations(rows provider.GenericTableIterator, expectations []Expectation, previousRows int64) ([]ExpectationResult, error) {
+	columns := make(map[string]int)
+	for i, col := range rows.Columns() {
+		columns[col] = i
+	}
+	checks := make([]*expectationCheck, len(expectations))
+	for i, expectation := range expectations {
+		check := &expectationCheck{Expectation: expectation, col: -1}
+		switch expectation.Kind {
+		case ExpectRowCountDelta:
+		case ExpectNonNull, ExpectRange, ExpectUnique:
+			col, has := columns[expectation.Column]
+			if !has {
+				return nil, fmt.Errorf("%s: no column %q to check", expectation, expectation.Column)
+			}
+			check.col = col
+			check.seen = make(map[string]bool)
+		default:
+			return nil, fmt.Errorf("unknown expectation kind %q", expectation.Kind)
+		}
+		checks[i] = check
+	}
+	var numRows int64
+	for rows.Next() {
+		values := rows.Values()
+		numRows++
+		for _, check := range checks {
+			if check.col >= 0 && check.col < len(values) {
+				check.add(values[check.col])
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("read rows: %w", err)
+	}
+	results := make([]ExpectationResult, len(checks))
+	for i, check := range checks {
+		results[i] = check.result(numRows, previousRows)
+	}
+	return results, nil
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch num := value.(type) {
+	case int:
+		return float64(num), true
+	case int32:
+		return float64(num), true
+	case int64:
+		return float64(num), true
+	case float32:
+		return float64(num), true
+	case float64:
+		return num, true
+	default:
+		return 0, false
+	}
+}
+
+type ValidationRunnerConfig struct {
+	OfflineType       provider.Type
+	OfflineConfig     provider.SerializedConfig
+	ResourceID        provider.ResourceID
+	MaterializationID provider.MaterializationID
+	Expectations      []Expectation
+	PreviousRowCount  int64
+}
+
+func (c *ValidationRunnerConfig) Serialize() (Config, error) {
+	config, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *ValidationRunnerConfig) Deserialize(config Config) error {
+	return json.Unmarshal(config, c)
+}
+
+func ValidationRunnerFactory(config Config) (Runner, error) {
+	runnerConfig := &ValidationRunnerConfig{}
+	if err := runnerConfig.Deserialize(config); err != nil {
+		return nil, fmt.Errorf("failed to deserialize validation runner config: %v", err)
+	}
+	offlineStore, err := getOfflineStore(runnerConfig.OfflineType, runnerConfig.OfflineConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure offline provider: %v", err)
+	}
+	return &ValidationRunner{
+		Offline:           offlineStore,
+		ID:                runnerConfig.ResourceID,
+		MaterializationID: runnerConfig.MaterializationID,
+		Expectations:      runnerConfig.Expectations,
+		PreviousRowCount:  runnerConfig.PreviousRowCount,
+	}, nil
+}
@@ -0,0 +1,88 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/featureform/provider"
+)
+
+func TestValidationRunner(t *testing.T) {
+	id := provider.ResourceID{Name: "transactions", Variant: "default", Type: provider.Primary}
+	store := &copyTestStore{tables: map[provider.ResourceID]*copyTestTable{
+		id: {schema: provider.TableSchema{Columns: []provider.TableColumn{{Name: "user"}, {Name: "amount"}}}, rows: []provider.GenericRecord{{"a", 10}, {"b", nil}, {"c", 250}, {"c", 30}}},
+	}}
+	zero, hundred := 0.0, 100.0
+	expectations := []Expectation{
+		{Kind: ExpectNonNull, Column: "amount", MinPercent: 80},
+		{Kind: ExpectRange, Column: "amount", Min: &zero, Max: &hundred, Severity: SeverityWarn},
+		{Kind: ExpectUnique, Column: "user"},
+		{Kind: ExpectRowCountDelta, MaxDeltaPercent: 50},
+	}
+	validation := &ValidationRunner{Offline: store, ID: id, Expectations: expectations, PreviousRowCount: 3}
+	err := runValidation(t, validation)
+	failed := &ValidationFailed{}
+	if !errors.As(err, &failed) {
+		t.Fatalf("Expected validation to fail, got %v", err)
+	}
+	// The range expectation only warns, and the row count is within bounds.
+	if len(failed.Failures) != 2 || failed.Failures[0].Expectation.Kind != ExpectNonNull || failed.Failures[1].Expectation.Kind != ExpectUnique {
+		t.Fatalf("Unexpected failures: %v", failed.Failures)
+	}
+	if len(validation.Results) != 4 || validation.Results[1].Passed || !validation.Results[3].Passed {
+		t.Fatalf("Unexpected results: %v", validation.Results)
+	}
+	validation.Expectations = expectations[1:2]
+	if err := runValidation(t, validation); err != nil {
+		t.Fatalf("Expected warnings not to fail validation: %s", err)
+	}
+	validation.Expectations = []Expectation{{Kind: ExpectNonNull, Column: "missing"}}
+	if err := runValidation(t, validation); err == nil || errors.As(err, &failed) {
+		t.Fatalf("Expected an invalid expectation to fail the job, got %v", err)
+	}
+}
+
+func TestValidationRunnerMaterialization(t *testing.T) {
+	store := provider.NewMemoryOfflineStore()
+	id := provider.ResourceID{Name: "feature", Variant: "variant", Type: provider.Feature}
+	schema := provider.TableSchema{
+		Columns: []provider.TableColumn{
+			{Name: "entity", ValueType: provider.String},
+			{Name: "value", ValueType: provider.Int},
+			{Name: "ts", ValueType: provider.Timestamp},
+		},
+	}
+	table, err := store.CreateResourceTable(id, schema)
+	if err != nil {
+		t.Fatalf("Failed to create table: %s", err)
+	}
+	for i, entity := range []string{"a", "b"} {
+		if err := table.Write(provider.ResourceRecord{Entity: entity, Value: i}); err != nil {
+			t.Fatalf("Failed to write record: %s", err)
+		}
+	}
+	mat, err := store.CreateMaterialization(id)
+	if err != nil {
+		t.Fatalf("Failed to create materialization: %s", err)
+	}
+	validation := &ValidationRunner{
+		Offline:           store,
+		MaterializationID: mat.ID(),
+		Expectations:      []Expectation{{Kind: ExpectUnique, Column: "entity"}, {Kind: ExpectNonNull, Column: "value", MinPercent: 100}},
+	}
+	if err := runValidation(t, validation); err != nil {
+		t.Fatalf("Validation failed: %s", err)
+	}
+}
+
+func runValidation(t *testing.T, validation *ValidationRunner) error {
+	watcher, err := validation.Run()
+	if err != nil {
+		t.Fatalf("Failed to run validation: %s", err)
+	}
+	return watcher.Wait()
+}
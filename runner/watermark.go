@@ -0,0 +1,56 @@
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/featureform/provider"
+)
+
+// High-water marks are kept in a table in the online store itself, so they
+// always describe what that store holds.
+const (
+	watermarkTableName    = "featureform_watermarks"
+	watermarkTableVariant = "materialize"
+)
+
+func watermarkKey(id provider.ResourceID) string {
+	return fmt.Sprintf("%s__%s", id.Name, id.Variant)
+}
+
+// getWatermark returns the latest timestamp that has been copied to the
+// online store for id, or the zero time if nothing has been recorded.
+func getWatermark(store provider.OnlineStore, id provider.ResourceID) (time.Time, error) {
+	table, err := store.GetTable(watermarkTableName, watermarkTableVariant)
+	if _, notFound := err.(*provider.TableNotFound); notFound {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, fmt.Errorf("get watermark table: %w", err)
+	}
+	value, err := table.Get(watermarkKey(id))
+	if _, notFound := err.(*provider.EntityNotFound); notFound || value == nil {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, fmt.Errorf("get watermark: %w", err)
+	}
+	watermark, err := time.Parse(time.RFC3339Nano, fmt.Sprint(value))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid watermark %v: %w", value, err)
+	}
+	return watermark, nil
+}
+
+func setWatermark(store provider.OnlineStore, id provider.ResourceID, watermark time.Time) error {
+	table, err := store.CreateTable(watermarkTableName, watermarkTableVariant, provider.String)
+	if _, exists := err.(*provider.TableAlreadyExists); exists {
+		table, err = store.GetTable(watermarkTableName, watermarkTableVariant)
+	}
+	if err != nil {
+		return fmt.Errorf("get watermark table: %w", err)
+	}
+	return table.Set(watermarkKey(id), watermark.UTC().Format(time.RFC3339Nano))
+}
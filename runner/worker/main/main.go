@@ -23,6 +23,30 @@ func init() {
 	if err := runner.RegisterFactory(string(runner.CREATE_TRANSFORMATION), runner.CreateTransformationRunnerFactory); err != nil {
 		log.Fatalf("Failed to register create transformation runner factory: %v", err)
 	}
+	if err := runner.RegisterFactory(string(runner.MIGRATE_ONLINE), runner.OnlineMigrationRunnerFactory); err != nil {
+		log.Fatalf("Failed to register online migration runner factory: %v", err)
+	}
+	if err := runner.RegisterFactory(string(runner.COMPACT_MATERIALIZATIONS), runner.CompactionRunnerFactory); err != nil {
+		log.Fatalf("Failed to register compaction runner factory: %v", err)
+	}
+	if err := runner.RegisterFactory(string(runner.MAINTAIN_PROVIDER), runner.MaintenanceRunnerFactory); err != nil {
+		log.Fatalf("Failed to register maintenance runner factory: %v", err)
+	}
+	if err := runner.RegisterFactory(string(runner.DELETE_RESOURCE), runner.DeleteResourceRunnerFactory); err != nil {
+		log.Fatalf("Failed to register delete resource runner factory: %v", err)
+	}
+	if err := runner.RegisterFactory(string(runner.COPY_OFFLINE), runner.OfflineCopyRunnerFactory); err != nil {
+		log.Fatalf("Failed to register offline copy runner factory: %v", err)
+	}
+	if err := runner.RegisterFactory(string(runner.VALIDATE), runner.ValidationRunnerFactory); err != nil {
+		log.Fatalf("Failed to register validation runner factory: %v", err)
+	}
+	if err := runner.RegisterFactory(string(runner.EXPORT_TRAINING_SET), runner.ExportTrainingSetRunnerFactory); err != nil {
+		log.Fatalf("Failed to register export training set runner factory: %v", err)
+	}
+	if err := runner.RegisterFactory(string(runner.STREAM_TO_ONLINE), runner.StreamToOnlineRunnerFactory); err != nil {
+		log.Fatalf("Failed to register stream to online runner factory: %v", err)
+	}
 }
 
 func main() {
@@ -9,31 +9,37 @@ import (
 	"errors"
 	"fmt"
 	"github.com/featureform/coordinator"
+	"github.com/featureform/metadata"
 	"github.com/featureform/runner"
 	"github.com/google/uuid"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.uber.org/zap"
 	"os"
+	"os/signal"
 	"strconv"
+	"syscall"
 	"time"
 )
 
 type Config []byte
 
-func CreateAndRun() error {
+// workerLogBytes is how much of what a worker logs it records for the run
+// of its job.
+const workerLogBytes = 16 << 10
+
+func CreateAndRun() (err error) {
 	logger := zap.NewExample().Sugar()
 	config, ok := os.LookupEnv("CONFIG")
 
 	if !ok {
 		return errors.New("CONFIG not set")
 	}
-	fmt.Printf("Config: %v\n", config)
 	name, ok := os.LookupEnv("NAME")
 
 	if !ok {
 		return errors.New("NAME not set")
 	}
-	var etcdConf string
+	etcdConf, ok := os.LookupEnv("ETCD_CONFIG")
 	if !ok {
 		return errors.New("ETCD_CONFIG not set")
 	}
@@ -41,16 +47,41 @@ func CreateAndRun() error {
 	if err != nil {
 		return err
 	}
-	logger.Infow("Starting job for resource:", jobRunner.Resource())
+	logger, logs := runner.CaptureLogs(logger, workerLogBytes)
+	defer func() {
+		if err != nil {
+			logger.Errorw("Job failed", "resource", jobRunner.Resource(), "error", err)
+		}
+		if recordErr := recordLogs(etcdConf, name, jobRunner.Resource(), logs); recordErr != nil {
+			logger.Errorw("Could not record worker logs", "resource", jobRunner.Resource(), "error", recordErr)
+		}
+	}()
+	logger.Infow("Starting job", "job", name, "resource", jobRunner.Resource())
 	if jobRunner.IsUpdateJob() {
 		logger.Info("This is an update job")
-		etcdConf, ok = os.LookupEnv("ETCD_CONFIG")
-		if !ok {
-			return errors.New("ETCD_CONFIG not set")
+		lock, err := maintenanceLock(etcdConf, jobRunner.Resource())
+		if err != nil {
+			return err
+		}
+		if lock != nil {
+			logger.Infow("Skipping scheduled run of resource locked for maintenance", "resource", jobRunner.Resource(), "reason", lock.Reason)
+			return nil
+		}
+		// A run that started before its cron job was suspended is skipped.
+		pause, err := schedulePause(etcdConf, jobRunner.Resource())
+		if err != nil {
+			return err
+		}
+		if pause != nil {
+			logger.Infow("Skipping scheduled run of paused resource", "resource", jobRunner.Resource(), "reason", pause.Reason)
+			return nil
 		}
 	}
 	indexString, hasIndexEnv := os.LookupEnv("JOB_COMPLETION_INDEX")
 	indexRunner, isIndexRunner := jobRunner.(runner.IndexRunner)
+	if isIndexRunner && !hasIndexEnv {
+		indexString, hasIndexEnv = platformTaskIndex()
+	}
 	if isIndexRunner && !hasIndexEnv {
 		return errors.New("index runner needs index set")
 	}
@@ -67,11 +98,16 @@ func CreateAndRun() error {
 		}
 		jobRunner = indexRunner
 	}
-	watcher, err := jobRunner.Run()
+	// Kubernetes stops a cancelled job's pod with SIGTERM, which cancels the
+	// job's warehouse queries before the pod is killed.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
+	ctx = runner.WithLogger(ctx, logger.With("resource", jobRunner.Resource()))
+	watcher, err := runner.RunWithContext(ctx, jobRunner)
 	if err != nil {
 		return err
 	}
-	if err := watcher.Wait(); err != nil {
+	if err := runner.WaitWithContext(ctx, watcher); err != nil {
 		return err
 	}
 	logger.Infow("Completed job for resource %v", jobRunner.Resource())
@@ -108,3 +144,77 @@ func CreateAndRun() error {
 	}
 	return nil
 }
+
+// recordLogs records what the worker logged, so that it can be read with the
+// run of the job it ran.
+func recordLogs(etcdConf string, name string, id metadata.ResourceID, logs *runner.LogCapture) error {
+	etcdConfig := &coordinator.ETCDConfig{}
+	if err := etcdConfig.Deserialize(coordinator.Config(etcdConf)); err != nil {
+		return err
+	}
+	cli, err := clientv3.New(clientv3.Config{Endpoints: etcdConfig.Endpoints, Username: etcdConfig.Username, Password: etcdConfig.Password, DialTimeout: time.Second * 5})
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	workerLog := metadata.WorkerLog{Resource: id, Job: name, Written: time.Now().UTC(), Logs: logs.String()}
+	serialized, err := workerLog.Serialize()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	_, err = cli.Put(ctx, metadata.GetWorkerLogKey(workerLog, uuid.New().String()), string(serialized))
+	return err
+}
+
+func maintenanceLock(etcdConf string, id metadata.ResourceID) (*metadata.MaintenanceLock, error) {
+	etcdConfig := &coordinator.ETCDConfig{}
+	if err := etcdConfig.Deserialize(coordinator.Config(etcdConf)); err != nil {
+		return nil, err
+	}
+	cli, err := clientv3.New(clientv3.Config{Endpoints: etcdConfig.Endpoints, Username: etcdConfig.Username, Password: etcdConfig.Password, DialTimeout: time.Second * 5})
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	lock, err := metadata.GetMaintenanceLock(ctx, cli, id)
+	if err != nil {
+		return nil, fmt.Errorf("check maintenance lock: %w", err)
+	}
+	return lock, nil
+}
+
+func schedulePause(etcdConf string, id metadata.ResourceID) (*metadata.SchedulePause, error) {
+	etcdConfig := &coordinator.ETCDConfig{}
+	if err := etcdConfig.Deserialize(coordinator.Config(etcdConf)); err != nil {
+		return nil, err
+	}
+	cli, err := clientv3.New(clientv3.Config{Endpoints: etcdConfig.Endpoints, Username: etcdConfig.Username, Password: etcdConfig.Password, DialTimeout: time.Second * 5})
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	pause, err := metadata.GetSchedulePause(ctx, cli, id)
+	if err != nil {
+		return nil, fmt.Errorf("check schedule pause: %w", err)
+	}
+	return pause, nil
+}
+
+// platformTaskIndex reads the index that AWS Batch gives the children of
+// array jobs, or that Cloud Run and Nomad give each task of a job. Cloud Run
+// and Nomad set it for jobs of a single task too, so it's only read for index
+// runners.
+func platformTaskIndex() (string, bool) {
+	for _, env := range []string{"AWS_BATCH_JOB_ARRAY_INDEX", "CLOUD_RUN_TASK_INDEX", "NOMAD_ALLOC_INDEX"} {
+		if index, has := os.LookupEnv(env); has {
+			return index, true
+		}
+	}
+	return "", false
+}
@@ -87,6 +87,10 @@ func (m *MockCompletionWatcher) Err() error {
 	return nil
 }
 
+func (m *MockCompletionWatcher) Progress() runner.Progress {
+	return runner.Progress{}
+}
+
 type RunnerWithFailingWatcher struct{}
 
 func (r *RunnerWithFailingWatcher) Run() (runner.CompletionWatcher, error) {
@@ -115,6 +119,9 @@ func (f *FailingWatcher) Wait() error {
 func (f *FailingWatcher) Err() error {
 	return errors.New("Run failed")
 }
+func (f *FailingWatcher) Progress() runner.Progress {
+	return runner.Progress{}
+}
 
 type FailingRunner struct{}
 